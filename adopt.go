@@ -0,0 +1,195 @@
+// adopt.go - Adopting VMs created outside passgo (e.g. with the multipass CLI)
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// adoptPromptEnabled reads adopt-prompt from .config; prompting is on
+// unless it is set to false.
+func adoptPromptEnabled(lookup func(string) (string, bool)) bool {
+	if v, ok := lookup("adopt-prompt"); ok {
+		return parseConfigBool(v)
+	}
+	return true
+}
+
+// adoptCandidates returns, sorted, the VMs passgo has no metadata for and
+// that have not been ignored or skipped this session. VMs with an operation
+// in flight or that passgo changed itself recently are left out: they are
+// still being launched or cloned from the TUI.
+func adoptCandidates(vms []vmData, st appState, busy map[string]busyInfo, touched map[string]time.Time, skipped map[string]bool) []string {
+	var names []string
+	for _, vm := range vms {
+		name := vm.info.Name
+		if vm.info.State == "Deleted" || skipped[name] || slices.Contains(st.Ignored, name) {
+			continue
+		}
+		if _, ok := st.VMs[name]; ok {
+			continue
+		}
+		if _, ok := busy[name]; ok {
+			continue
+		}
+		if _, ok := touched[name]; ok {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseTags splits a comma-separated tag list, dropping blanks and repeats.
+func parseTags(s string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(t)
+		if t != "" && !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// adoption is what the user attaches to a VM when adopting it.
+type adoption struct {
+	tags          []string
+	notes         string
+	snapshotEvery time.Duration // 0: no snapshot schedule
+	snapshotKeep  int
+}
+
+// parseAdoptSchedule validates the snapshot fields of the adopt dialog.
+// An empty interval means no schedule.
+func parseAdoptSchedule(every, keep string) (time.Duration, int, error) {
+	if strings.TrimSpace(every) == "" {
+		return 0, 0, nil
+	}
+	d, err := parseTTL(every)
+	if err != nil || d <= 0 {
+		return 0, 0, fmt.Errorf("snapshot every: %q is not a duration like 24h or 7d", every)
+	}
+	n := 7
+	if strings.TrimSpace(keep) != "" {
+		n, err = strconv.Atoi(strings.TrimSpace(keep))
+		if err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("keep: must be at least 1")
+		}
+	}
+	return d, n, nil
+}
+
+// adopt records vm as known to passgo: its current resources (for quota
+// accounting) plus the tags, notes and snapshot schedule from a.
+func (s appState) adopt(vm VMInfo, a adoption, now time.Time) {
+	meta := s.VMs[vm.Name]
+	meta.Adopted = now
+	meta.Tags = a.tags
+	meta.Notes = a.notes
+	if n, err := strconv.Atoi(vm.CPUs); err == nil {
+		meta.CPUs = n
+		meta.MemoryMB = int(usageTotalMB(vm.MemoryUsage))
+		meta.DiskGB = int(usageTotalMB(vm.DiskUsage) / 1024)
+	}
+	if a.snapshotEvery > 0 {
+		meta.SnapshotEvery = a.snapshotEvery.String()
+		meta.SnapshotKeep = a.snapshotKeep
+	}
+	s.VMs[vm.Name] = meta
+}
+
+// ignore stops passgo from offering to adopt vmName.
+func (s *appState) ignore(vmName string) {
+	if !slices.Contains(s.Ignored, vmName) {
+		s.Ignored = append(s.Ignored, vmName)
+	}
+}
+
+// stateSchedules returns the snapshot schedules recorded on adoption, for
+// VMs without one in .config; .config wins.
+func stateSchedules(st appState, configured []snapshotSchedule) []snapshotSchedule {
+	var out []snapshotSchedule
+	for name, meta := range st.VMs {
+		if meta.SnapshotEvery == "" || slices.ContainsFunc(configured, func(s snapshotSchedule) bool { return s.vm == name }) {
+			continue
+		}
+		d, err := time.ParseDuration(meta.SnapshotEvery)
+		if err != nil || d <= 0 {
+			continue
+		}
+		out = append(out, snapshotSchedule{vm: name, every: d, keep: max(meta.SnapshotKeep, 1)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].vm < out[j].vm })
+	return out
+}
+
+// ─── TUI ───────────────────────────────────────────────────────────────────────
+
+// adoptDecisionMsg is sent when the user answers the adopt dialog.
+type adoptDecisionMsg struct {
+	vm        VMInfo
+	action    string // "adopt", "skip" or "ignore"
+	adoption  adoption
+	remaining []string // other candidates, for "ignore all"
+}
+
+// maybePromptAdopt opens the adopt dialog for the first unknown VM, if the
+// user is on the table and adoption prompts are enabled.
+func (m *rootModel) maybePromptAdopt() tea.Cmd {
+	if !m.adoptPrompt || m.readOnly || m.currentView != viewTable || m.state.VMs == nil {
+		return nil
+	}
+	names := adoptCandidates(m.table.vms, m.state, m.table.busyVMs, m.touched, m.adoptSkipped)
+	if len(names) == 0 {
+		return nil
+	}
+	for _, vm := range m.table.vms {
+		if vm.info.Name == names[0] {
+			m.adopt = newAdoptModel(vm.info, names[1:], m.width, m.height)
+			m.currentView = viewAdopt
+			return m.adopt.Init()
+		}
+	}
+	return nil
+}
+
+// handleAdoptDecision records the user's answer and moves on to the next
+// unknown VM, if any.
+func (m *rootModel) handleAdoptDecision(msg adoptDecisionMsg) tea.Cmd {
+	m.currentView = viewTable
+	var toast tea.Cmd
+	switch msg.action {
+	case "adopt":
+		m.state.adopt(msg.vm, msg.adoption, time.Now())
+		m.table.regroup()
+		if msg.adoption.snapshotEvery > 0 {
+			m.schedules = append(m.schedules, snapshotSchedule{vm: msg.vm.Name, every: msg.adoption.snapshotEvery, keep: msg.adoption.snapshotKeep})
+		}
+		m.persistState()
+		if appLogger != nil {
+			appLogger.Printf("adopted %s", msg.vm.Name)
+		}
+		toast = m.table.addToast("✓ Adopted "+msg.vm.Name, "success")
+	case "skip":
+		if m.adoptSkipped == nil {
+			m.adoptSkipped = make(map[string]bool)
+		}
+		m.adoptSkipped[msg.vm.Name] = true
+	case "ignore":
+		m.state.ignore(msg.vm.Name)
+		for _, name := range msg.remaining {
+			m.state.ignore(name)
+		}
+		m.persistState()
+		return m.table.addToast(fmt.Sprintf("Not asking about %d VM(s) again", len(msg.remaining)+1), "info")
+	}
+	return tea.Batch(toast, m.maybePromptAdopt())
+}
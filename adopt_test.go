@@ -0,0 +1,142 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAdoptCandidates(t *testing.T) {
+	vms := []vmData{
+		{info: VMInfo{Name: "web", State: "Running"}},
+		{info: VMInfo{Name: "known", State: "Running"}},
+		{info: VMInfo{Name: "cli-box", State: "Stopped"}},
+		{info: VMInfo{Name: "gone", State: "Deleted"}},
+		{info: VMInfo{Name: "launching", State: "Starting"}},
+		{info: VMInfo{Name: "cloned", State: "Stopped"}},
+		{info: VMInfo{Name: "ignored", State: "Stopped"}},
+		{info: VMInfo{Name: "skipped", State: "Stopped"}},
+	}
+	st := newAppState()
+	st.VMs["known"] = vmMeta{CPUs: 2}
+	st.Ignored = []string{"ignored"}
+	busy := map[string]busyInfo{"launching": {operation: "Creating", startTime: time.Now()}}
+	touched := map[string]time.Time{"cloned": time.Now()}
+	skipped := map[string]bool{"skipped": true}
+
+	got := adoptCandidates(vms, st, busy, touched, skipped)
+	if want := []string{"cli-box", "web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("adoptCandidates = %v, want %v", got, want)
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	got := parseTags(" team-a, ci,, team-a ")
+	if want := []string{"team-a", "ci"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTags = %v, want %v", got, want)
+	}
+	if got := parseTags(""); got != nil {
+		t.Errorf("parseTags(\"\") = %v, want nil", got)
+	}
+}
+
+func TestParseAdoptSchedule(t *testing.T) {
+	every, keep, err := parseAdoptSchedule("", "")
+	if err != nil || every != 0 || keep != 0 {
+		t.Errorf("empty = %v, %d, %v; want no schedule", every, keep, err)
+	}
+	every, keep, err = parseAdoptSchedule("1d", "")
+	if err != nil || every != 24*time.Hour || keep != 7 {
+		t.Errorf("1d = %v, %d, %v; want 24h, 7", every, keep, err)
+	}
+	if _, keep, _ = parseAdoptSchedule("6h", "3"); keep != 3 {
+		t.Errorf("keep = %d, want 3", keep)
+	}
+	for _, bad := range [][2]string{{"soon", ""}, {"6h", "0"}, {"6h", "x"}} {
+		if _, _, err := parseAdoptSchedule(bad[0], bad[1]); err == nil {
+			t.Errorf("parseAdoptSchedule(%q, %q) accepted", bad[0], bad[1])
+		}
+	}
+}
+
+func TestAppStateAdopt(t *testing.T) {
+	st := newAppState()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	vm := VMInfo{Name: "cli-box", CPUs: "2", MemoryUsage: "512.0MiB out of 3.8GiB", DiskUsage: "2.1GiB out of 19.3GiB"}
+	st.adopt(vm, adoption{tags: []string{"ci"}, notes: "build runner", snapshotEvery: 6 * time.Hour, snapshotKeep: 4}, now)
+
+	meta := st.VMs["cli-box"]
+	if !meta.Adopted.Equal(now) || meta.Notes != "build runner" || !reflect.DeepEqual(meta.Tags, []string{"ci"}) {
+		t.Errorf("meta = %+v", meta)
+	}
+	if meta.CPUs != 2 || meta.MemoryMB == 0 || meta.DiskGB != 19 {
+		t.Errorf("allocation = %d CPUs, %d MB, %d GB; want 2 CPUs and the reported totals", meta.CPUs, meta.MemoryMB, meta.DiskGB)
+	}
+	if meta.empty() {
+		t.Error("adopted VM meta reported empty")
+	}
+
+	// A stopped VM reports no resources but is still remembered.
+	st.adopt(VMInfo{Name: "stopped", CPUs: "--"}, adoption{}, now)
+	if m, ok := st.VMs["stopped"]; !ok || m.empty() || m.CPUs != 0 {
+		t.Errorf("stopped meta = %+v, %v", m, ok)
+	}
+
+	st.ignore("other")
+	st.ignore("other")
+	if !reflect.DeepEqual(st.Ignored, []string{"other"}) {
+		t.Errorf("Ignored = %v", st.Ignored)
+	}
+}
+
+func TestStateSchedules(t *testing.T) {
+	st := newAppState()
+	st.VMs["b"] = vmMeta{Adopted: time.Now(), SnapshotEvery: "6h0m0s", SnapshotKeep: 4}
+	st.VMs["a"] = vmMeta{Adopted: time.Now(), SnapshotEvery: "24h0m0s", SnapshotKeep: 7}
+	st.VMs["c"] = vmMeta{CPUs: 1}
+	configured := []snapshotSchedule{{vm: "b", every: time.Hour, keep: 2}}
+
+	got := stateSchedules(st, configured)
+	want := []snapshotSchedule{{vm: "a", every: 24 * time.Hour, keep: 7}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("stateSchedules = %+v, want %+v", got, want)
+	}
+}
+
+func TestAdoptPromptEnabled(t *testing.T) {
+	lookup := func(v string) func(string) (string, bool) {
+		return func(string) (string, bool) { return v, v != "" }
+	}
+	if !adoptPromptEnabled(lookup("")) {
+		t.Error("prompt should default to on")
+	}
+	if adoptPromptEnabled(lookup("false")) {
+		t.Error("adopt-prompt: false should turn the prompt off")
+	}
+}
+
+func TestAdoptModelSubmit(t *testing.T) {
+	m := newAdoptModel(VMInfo{Name: "cli-box"}, []string{"other"}, 100, 40)
+	m.tagsInput.SetValue("ci, team-a")
+	m.everyInput.SetValue("nope")
+	m.cursor = adoptFieldAdopt
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || m.errMsg == "" {
+		t.Fatalf("invalid schedule should show an error, got errMsg %q", m.errMsg)
+	}
+
+	m.everyInput.SetValue("")
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg, ok := cmd().(adoptDecisionMsg)
+	if !ok || msg.action != "adopt" || !reflect.DeepEqual(msg.adoption.tags, []string{"ci", "team-a"}) {
+		t.Errorf("decision = %+v", msg)
+	}
+
+	m.cursor = adoptFieldIgnore
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg := cmd().(adoptDecisionMsg); msg.action != "ignore" || !reflect.DeepEqual(msg.remaining, []string{"other"}) {
+		t.Errorf("ignore decision = %+v", msg)
+	}
+}
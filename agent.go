@@ -0,0 +1,638 @@
+// agent.go - `passgo agent`: schedules, TTLs and usage history without the TUI
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const agentUsage = `Usage: passgo agent [-interval 5s] [-listen 127.0.0.1:9477]
+
+Runs passgo's automations headless, for a host where VMs should be looked
+after whether or not anyone has passgo open: TTL teardown, power schedules,
+idle stops, cron jobs, scheduled snapshots and the usage history in
+~/.passgo/state.json. Every change it makes to a VM is printed with the
+time.
+
+The agent answers on ~/.passgo/agent.sock:
+
+  GET /v1/status   the agent's pid, version and last poll, as JSON
+  GET /v1/vms      the instances of the last poll (?fresh=1: poll now)
+  GET /v1/jobs     the changes it made to VMs, oldest first
+  GET /metrics     instance states and usage for Prometheus
+  POST /v1/run     run a multipass command (used by the TUI)
+
+-listen serves the API over TCP as well, to clients with one of the
+agent-token entries in .config as a bearer token. A token's scope is
+read-only (the GET endpoints, the default), operator (also start, stop,
+restart, suspend and snapshot through /v1/run) or admin (any command):
+
+  agent-token: {name: prometheus, token: 6f1d0c2e9a8b47d3}
+  agent-token: {name: ci, token: 9b2e77c4d1a05f83, scope: operator}
+
+A TUI started
+while the agent runs attaches to it: its multipass commands and VM list
+go through the agent, and it leaves the automations to it.
+
+Flags:
+`
+
+// agentSocketName is the agent's socket, in passgo's config directory.
+const agentSocketName = "agent.sock"
+
+// agentSocketPath returns the path of the agent's socket.
+func agentSocketPath() (string, error) {
+	path, err := stateFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), agentSocketName), nil
+}
+
+// agentRunning reports whether an agent answers on its socket.
+func agentRunning() bool {
+	path, err := agentSocketPath()
+	if err != nil {
+		return false
+	}
+	conn, err := net.DialTimeout("unix", path, 500*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// agent is the state of a running `passgo agent`.
+type agent struct {
+	statePath string
+	snapshots []snapshotSchedule // from .config; adopted VMs' come from the state file
+	power     []powerSchedule
+	cron      []cronJob
+	idle      idlePolicy
+	idleSince map[string]time.Time // when each running VM's load went under idle-load
+	stdout    io.Writer
+	stderr    io.Writer
+	started   time.Time
+
+	lastPower     time.Time
+	lastCron      time.Time
+	lastSnapshots time.Time
+
+	mu         sync.Mutex // guards what the HTTP handlers read
+	vms        []vmData
+	lastPoll   time.Time
+	pollErr    string
+	pollErrors int
+	jobs       []agentJob // oldest first, at most agentJobLimit
+}
+
+// agentJob is a change the agent made to a VM, on its own or for a TUI.
+type agentJob struct {
+	Time     time.Time     `json:"time"`
+	Command  string        `json:"command"` // multipass arguments, e.g. "stop web"
+	Reason   string        `json:"reason"`  // "TTL expired", "schedule …", "passgo" for a TUI, "token ci" over TCP
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// agentJobLimit is how many jobs the agent remembers.
+const agentJobLimit = 200
+
+func (a *agent) addJob(j agentJob) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.jobs = append(a.jobs, j)
+	if n := len(a.jobs) - agentJobLimit; n > 0 {
+		a.jobs = append([]agentJob(nil), a.jobs[n:]...)
+	}
+}
+
+// errorText is err's first line, or "" for nil.
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return shortError(err)
+}
+
+// expiredTTLs clears the TTLs of listed VMs that have run out and returns
+// what to do with them: stop or soft-delete, as checkTTLs does in the TUI.
+func expiredTTLs(st appState, vms []vmData, now time.Time) []scheduledOp {
+	states := make(map[string]string, len(vms))
+	for _, vm := range vms {
+		states[vm.info.Name] = vm.info.State
+	}
+	names := make([]string, 0, len(st.VMs))
+	for name := range st.VMs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var ops []scheduledOp
+	for _, name := range names {
+		meta := st.VMs[name]
+		state, listed := states[name]
+		if meta.Expires.IsZero() || !listed || meta.Expires.After(now) {
+			continue
+		}
+		st.clearTTL(name)
+		switch {
+		case meta.TTLAction == "stop" && state == "Running":
+			ops = append(ops, scheduledOp{vm: name, op: "stop", reason: "TTL expired"})
+		case meta.TTLAction != "stop" && state != "Deleted":
+			ops = append(ops, scheduledOp{vm: name, op: "soft-delete", reason: "TTL expired"})
+		}
+	}
+	return ops
+}
+
+// runScheduledOp runs op through multipass, traced like the TUI's operations.
+func runScheduledOp(op scheduledOp) (string, error) {
+	return traceVMOp(op.op, op.vm, func() (string, error) {
+		switch op.op {
+		case "start":
+			return StartVM(op.vm)
+		case "stop":
+			return StopVM(op.vm)
+		case "soft-delete":
+			return DeleteVM(op.vm, false)
+		}
+		return "", fmt.Errorf("unknown operation %q", op.op)
+	})
+}
+
+// poll fetches the instances and applies TTLs, power schedules, the idle
+// policy, cron jobs, usage sampling and scheduled snapshots. The state file
+// is updated under its lock around each step (see updateState), never held
+// across multipass commands, so a TUI can write it in between.
+func (a *agent) poll(now time.Time) {
+	vms, err := a.refresh(now)
+	if err != nil {
+		a.logErr(now, fmt.Errorf("list VMs: %w", err))
+		return
+	}
+
+	// A TTL is acted on only once its clearing is saved, so only once.
+	var ops []scheduledOp
+	if _, err := updateState(a.statePath, func(st *appState) bool {
+		ops = expiredTTLs(*st, vms, now)
+		return len(ops) > 0
+	}); err != nil {
+		a.logErr(now, err)
+		return
+	}
+	if a.lastPower.IsZero() {
+		a.lastPower, a.lastCron = now, now
+	}
+	states := make(map[string]string, len(vms))
+	for _, vm := range vms {
+		states[vm.info.Name] = vm.info.State
+	}
+	ops = append(ops, powerOps(a.power, states, a.lastPower, now)...)
+	a.lastPower = now
+	ops = append(ops, a.idleOps(vms, ops, now)...)
+
+	type result struct {
+		op    scheduledOp
+		trace string
+		err   error
+	}
+	var results []result
+	for _, op := range ops {
+		start := time.Now()
+		trace, err := runScheduledOp(op)
+		results = append(results, result{op, trace, err})
+		a.addJob(agentJob{Time: start, Command: op.op + " " + op.vm, Reason: op.reason, Error: errorText(err), Duration: time.Since(start)})
+		if err != nil {
+			a.logErr(now, fmt.Errorf("%s %s (%s): %s", op.op, op.vm, op.reason, shortError(err)))
+		} else {
+			fmt.Fprintf(a.stdout, "%s %s %s (%s)\n", now.Format(time.DateTime), op.op, op.vm, op.reason)
+		}
+	}
+	runs := a.runCronJobs(states, now)
+
+	st, err := updateState(a.statePath, func(st *appState) bool {
+		changed := len(results) > 0 || len(runs) > 0
+		for _, r := range results {
+			st.recordOp(r.op.vm, r.op.op, r.trace, r.err, time.Now())
+		}
+		for _, r := range runs {
+			st.recordJobRun(r.job.name, r.at, r.output, r.err)
+		}
+		if st.sampleUsage(vms, now) {
+			changed = true
+		}
+		return changed
+	})
+	if err != nil {
+		a.logErr(now, err)
+		return
+	}
+
+	if now.Sub(a.lastSnapshots) >= snapshotScheduleInterval {
+		a.lastSnapshots = now
+		if schedules := slices.Concat(a.snapshots, stateSchedules(st, a.snapshots)); len(schedules) > 0 {
+			r := runSnapshotSchedules(schedules, states, now)
+			for _, s := range r.created {
+				fmt.Fprintf(a.stdout, "%s snapshot %s\n", now.Format(time.DateTime), s)
+				a.addJob(agentJob{Time: now, Command: "snapshot " + s, Reason: "snapshot schedule"})
+			}
+			for _, s := range r.pruned {
+				fmt.Fprintf(a.stdout, "%s pruned %s\n", now.Format(time.DateTime), s)
+				a.addJob(agentJob{Time: now, Command: "delete-snapshot " + s, Reason: "snapshot schedule"})
+			}
+			for _, err := range r.errs {
+				a.logErr(now, err)
+				a.addJob(agentJob{Time: now, Command: "snapshot schedule", Reason: "snapshot schedule", Error: shortError(err)})
+			}
+		}
+	}
+}
+
+// idleOps applies the idle policy from .config: with idle-action stop, VMs
+// idle for idle-minutes are stopped, unless ops already has them.
+func (a *agent) idleOps(vms []vmData, ops []scheduledOp, now time.Time) []scheduledOp {
+	if !a.idle.enabled() {
+		return nil
+	}
+	if a.idleSince == nil {
+		a.idleSince = make(map[string]time.Time)
+	}
+	idle := a.idle.sample(a.idleSince, vms, now)
+	if !a.idle.stop {
+		return nil
+	}
+	names := make([]string, 0, len(idle))
+	for name := range idle {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var stops []scheduledOp
+	for _, name := range names {
+		delete(a.idleSince, name)
+		if slices.ContainsFunc(ops, func(op scheduledOp) bool { return op.vm == name }) {
+			continue
+		}
+		stops = append(stops, scheduledOp{vm: name, op: "stop", reason: "idle for " + formatRemaining(now.Sub(idle[name]))})
+	}
+	return stops
+}
+
+// runCronJobs runs the jobs due since the last poll, one after the other,
+// and returns their results for the state file.
+func (a *agent) runCronJobs(states map[string]string, now time.Time) []cronJobResultMsg {
+	due := dueCronJobs(a.cron, states, a.lastCron, now)
+	a.lastCron = now
+	var runs []cronJobResultMsg
+	for _, j := range due {
+		start := time.Now()
+		out, err := runCronJob(j, states[j.vm], now)
+		runs = append(runs, cronJobResultMsg{job: j, at: start, output: out, err: err})
+		a.addJob(agentJob{Time: start, Command: j.describe(), Reason: "job " + j.name, Error: errorText(err), Duration: time.Since(start)})
+		if err != nil {
+			a.logErr(now, fmt.Errorf("job %s: %s", j.name, shortError(err)))
+		} else {
+			fmt.Fprintf(a.stdout, "%s job %s: %s\n", now.Format(time.DateTime), j.name, out)
+		}
+	}
+	return runs
+}
+
+// refresh fetches the instances for the API.
+func (a *agent) refresh(now time.Time) ([]vmData, error) {
+	vms, err := doFetchVMList()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lastPoll = now
+	if err != nil {
+		a.pollErr = err.Error()
+		a.pollErrors++
+		return nil, err
+	}
+	a.vms, a.pollErr = vms, ""
+	return vms, nil
+}
+
+func (a *agent) logErr(now time.Time, err error) {
+	fmt.Fprintf(a.stderr, "%s %v\n", now.Format(time.DateTime), err)
+	if appLogger != nil {
+		appLogger.Printf("agent: %v", err)
+	}
+}
+
+// agentStatus is the answer to GET /v1/status.
+type agentStatus struct {
+	PID               int       `json:"pid"`
+	Version           string    `json:"version"`
+	Started           time.Time `json:"started"`
+	LastPoll          time.Time `json:"last_poll"`
+	PollError         string    `json:"poll_error,omitempty"`
+	Instances         int       `json:"instances"`
+	PowerSchedules    int       `json:"power_schedules"`
+	SnapshotSchedules int       `json:"snapshot_schedules"`
+	Jobs              int       `json:"jobs"`
+}
+
+// handler serves the agent's API. Over TCP it sits behind
+// requireAgentToken, which limits each token to its scope.
+func (a *agent) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/status", func(w http.ResponseWriter, _ *http.Request) {
+		a.mu.Lock()
+		status := agentStatus{PID: os.Getpid(), Version: Version, Started: a.started.UTC(), LastPoll: a.lastPoll.UTC(),
+			PollError: a.pollErr, Instances: len(a.vms), PowerSchedules: len(a.power), SnapshotSchedules: len(a.snapshots), Jobs: len(a.cron)}
+		a.mu.Unlock()
+		writeJSON(w, status)
+	})
+	mux.HandleFunc("GET /v1/vms", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fresh") != "" {
+			if _, err := a.refresh(time.Now()); err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+		}
+		a.mu.Lock()
+		infos := make([]VMInfo, 0, len(a.vms))
+		for _, vm := range a.vms {
+			infos = append(infos, vm.info)
+		}
+		a.mu.Unlock()
+		writeJSON(w, infos)
+	})
+	mux.HandleFunc("GET /v1/jobs", func(w http.ResponseWriter, _ *http.Request) {
+		a.mu.Lock()
+		jobs := slices.Clone(a.jobs)
+		a.mu.Unlock()
+		if jobs == nil {
+			jobs = []agentJob{}
+		}
+		writeJSON(w, jobs)
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, _ *http.Request) {
+		a.mu.Lock()
+		text := agentMetrics(a.vms, a.lastPoll, a.pollErrors)
+		a.mu.Unlock()
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = io.WriteString(w, text)
+	})
+	mux.HandleFunc("POST /v1/run", a.run)
+	return mux
+}
+
+// agentFrame is one line of a POST /v1/run answer: output of the command
+// as it comes, then its exit code or why it could not run.
+type agentFrame struct {
+	Stream string `json:"stream,omitempty"` // "stdout" or "stderr"
+	Data   []byte `json:"data,omitempty"`
+	Exit   *int   `json:"exit,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// frameWriter sends what a command writes to one of its streams as frames.
+type frameWriter struct {
+	mu     *sync.Mutex
+	enc    *json.Encoder
+	flush  func() error
+	stream string
+}
+
+func (w frameWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.enc.Encode(agentFrame{Stream: w.stream, Data: p}); err != nil {
+		return 0, err
+	}
+	_ = w.flush()
+	return len(p), nil
+}
+
+// run runs `multipass arg…` for a TUI, streaming its output back. The
+// request body, if any, is the command's stdin. Commands that change VMs
+// are serialised with the agent's own and kept as jobs.
+func (a *agent) run(w http.ResponseWriter, r *http.Request) {
+	args := r.URL.Query()["arg"]
+	if len(args) == 0 {
+		http.Error(w, "no arguments", http.StatusBadRequest)
+		return
+	}
+	rc := http.NewResponseController(w)
+	_ = rc.EnableFullDuplex()
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	stdout := frameWriter{mu: &mu, enc: enc, flush: rc.Flush, stream: "stdout"}
+	stderr := frameWriter{mu: &mu, enc: enc, flush: rc.Flush, stream: "stderr"}
+	var stdin io.Reader
+	if r.ContentLength != 0 {
+		stdin = r.Body
+	}
+
+	defer lockForCommand(args)()
+	start := time.Now()
+	err := mpRunner.run(r.Context(), args, stdin, stdout, stderr)
+	var final agentFrame
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		code := exitErr.ExitCode()
+		final.Exit = &code
+	case err != nil:
+		final.Error = err.Error()
+	default:
+		code := 0
+		final.Exit = &code
+	}
+	mu.Lock()
+	_ = enc.Encode(final)
+	mu.Unlock()
+	if len(lockTargets(args)) > 0 {
+		reason := "passgo"
+		if t, ok := r.Context().Value(agentTokenKey{}).(agentToken); ok {
+			reason = "token " + t.name
+		}
+		job := agentJob{Time: start, Command: strings.Join(redactExecEnv(args), " "), Reason: reason, Duration: time.Since(start)}
+		if err != nil {
+			job.Error = err.Error()
+		}
+		a.addJob(job)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// agentMetrics renders the instances in the Prometheus text format.
+func agentMetrics(vms []vmData, lastPoll time.Time, pollErrors int) string {
+	var b strings.Builder
+	gauge := func(name, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+	states := make(map[string]int)
+	for _, vm := range vms {
+		states[vm.info.State]++
+	}
+	gauge("passgo_instances", "Instances known to multipass, by state.")
+	for _, state := range sortedKeys(states) {
+		fmt.Fprintf(&b, "passgo_instances{state=%q} %d\n", state, states[state])
+	}
+	gauge("passgo_instance_running", "Whether the instance is running.")
+	for _, vm := range vms {
+		running := 0
+		if vm.info.State == "Running" {
+			running = 1
+		}
+		fmt.Fprintf(&b, "passgo_instance_running{vm=%q} %d\n", vm.info.Name, running)
+	}
+	for _, m := range []struct {
+		name, help string
+		value      func(VMInfo) (float64, bool)
+	}{
+		{"passgo_instance_load_ratio", "1-minute load average per vCPU.", func(v VMInfo) (float64, bool) { return parseCPULoadFraction(v.Load, v.CPUs) }},
+		{"passgo_instance_memory_used_ratio", "Fraction of the instance's memory in use.", func(v VMInfo) (float64, bool) { return parseUsageFraction(v.MemoryUsage) }},
+		{"passgo_instance_disk_used_ratio", "Fraction of the instance's disk in use.", func(v VMInfo) (float64, bool) { return parseUsageFraction(v.DiskUsage) }},
+	} {
+		gauge(m.name, m.help)
+		for _, vm := range vms {
+			if f, ok := m.value(vm.info); ok {
+				fmt.Fprintf(&b, "%s{vm=%q} %.4g\n", m.name, vm.info.Name, f)
+			}
+		}
+	}
+	gauge("passgo_agent_last_poll_timestamp_seconds", "When the agent last polled multipass.")
+	fmt.Fprintf(&b, "passgo_agent_last_poll_timestamp_seconds %d\n", lastPoll.Unix())
+	fmt.Fprintf(&b, "# HELP passgo_agent_poll_errors_total Failed polls of multipass.\n# TYPE passgo_agent_poll_errors_total counter\n")
+	fmt.Fprintf(&b, "passgo_agent_poll_errors_total %d\n", pollErrors)
+	return b.String()
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// listenAgentSocket listens on the agent's socket, replacing one left by an
+// agent that died, and refusing if another agent answers on it.
+func listenAgentSocket() (net.Listener, string, error) {
+	path, err := agentSocketPath()
+	if err != nil {
+		return nil, "", err
+	}
+	if agentRunning() {
+		return nil, "", fmt.Errorf("an agent is already running (%s)", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, "", err
+	}
+	_ = os.Remove(path)
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, "", err
+	}
+	_ = os.Chmod(path, 0o600)
+	return l, path, nil
+}
+
+// agentCommand implements `passgo agent`.
+func agentCommand(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	var out cliOutput
+	fs := flag.NewFlagSet("agent", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, agentUsage)
+		fs.PrintDefaults()
+	}
+	out.register(fs, "agent")
+	interval := fs.Duration("interval", 5*time.Second, "how often to poll multipass")
+	listen := fs.String("listen", "", "also serve the API and metrics on this TCP address")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return out.fail(stderr, usageError{err})
+	}
+	switch {
+	case fs.NArg() > 0:
+		return out.fail(stderr, usageErrorf("unexpected arguments %v", fs.Args()))
+	case *interval <= 0:
+		return out.fail(stderr, usageErrorf("-interval must be positive"))
+	}
+
+	statePath, err := stateFilePath()
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+	a := &agent{statePath: statePath, stdout: stdout, stderr: stderr, started: time.Now(), idle: loadIdlePolicy(configValue)}
+	var errs []error
+	a.snapshots, errs = loadSnapshotSchedules(configList("snapshots"))
+	power, powerErrs := loadPowerSchedules(configList("power"))
+	a.power = power
+	cron, cronErrs := loadCronJobs(configList("job"))
+	a.cron = cron
+	tokens, tokenErrs := loadAgentTokens(configList("agent-token"))
+	for _, err := range slices.Concat(errs, powerErrs, cronErrs, tokenErrs) {
+		fmt.Fprintf(stderr, "passgo agent: ignoring %v\n", err)
+	}
+	if *listen != "" && len(tokens) == 0 {
+		return out.fail(stderr, usageErrorf("-listen needs an agent-token entry in .config: the TCP API is open to anyone who can reach it"))
+	}
+
+	l, socket, err := listenAgentSocket()
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+	defer os.Remove(socket)
+	servers := []*http.Server{{Handler: a.handler(), ReadHeaderTimeout: 5 * time.Second}}
+	go func() { _ = servers[0].Serve(l) }()
+	if *listen != "" {
+		tcp, err := net.Listen("tcp", *listen)
+		if err != nil {
+			_ = servers[0].Close()
+			return out.fail(stderr, err)
+		}
+		srv := &http.Server{Handler: requireAgentToken(tokens, a.handler()), ReadHeaderTimeout: 5 * time.Second}
+		servers = append(servers, srv)
+		go func() { _ = srv.Serve(tcp) }()
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		for _, srv := range servers {
+			_ = srv.Shutdown(ctx)
+		}
+	}()
+	out.reporter(stderr).say("", "ready", 0, "agent listening on %s", socket)
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+	for {
+		a.poll(time.Now())
+		select {
+		case <-sigs:
+			return exitOK
+		case <-time.After(*interval):
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// agent_attach.go - The TUI attached to a running `passgo agent` through its socket
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// agentRunner sends multipass commands to the agent, which runs them with
+// its own transport: one multipassd client for the agent and every TUI,
+// and the agent's job history covers what the TUIs did. Terminal sessions
+// still start here. If the agent goes away, commands run through next.
+type agentRunner struct {
+	client *http.Client
+	next   multipassRunner
+	lost   atomic.Bool // the agent stopped answering
+}
+
+func newAgentRunner(socket string, next multipassRunner) *agentRunner {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &agentRunner{client: &http.Client{Transport: transport}, next: next}
+}
+
+// exitStatusError is a command that exited non-zero inside the agent.
+type exitStatusError int
+
+func (e exitStatusError) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+
+// agentURL is the URL of path on the agent's socket; the host is ignored.
+func agentURL(path string, query url.Values) string {
+	u := url.URL{Scheme: "http", Host: "agent", Path: path, RawQuery: query.Encode()}
+	return u.String()
+}
+
+// gone reports whether err means the agent is not there any more, and
+// notes it.
+func (r *agentRunner) gone(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		if !r.lost.Swap(true) && appLogger != nil {
+			appLogger.Printf("agent: not answering (%v); running multipass via %s", err, r.next)
+		}
+		return true
+	}
+	return false
+}
+
+func (r *agentRunner) run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if r.lost.Load() {
+		return r.next.run(ctx, args, stdin, stdout, stderr)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, agentURL("/v1/run", url.Values{"arg": args}), stdin)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		if r.gone(err) {
+			return r.next.run(ctx, args, stdin, stdout, stderr)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("agent: %s", strings.TrimSpace(string(msg)))
+	}
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var f agentFrame
+		if err := dec.Decode(&f); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("agent: connection lost: %w", err)
+		}
+		switch {
+		case f.Stream == "stdout" && stdout != nil:
+			_, _ = stdout.Write(f.Data)
+		case f.Stream == "stderr" && stderr != nil:
+			_, _ = stderr.Write(f.Data)
+		case f.Error != "":
+			return errors.New(f.Error)
+		case f.Exit != nil && *f.Exit != 0:
+			return exitStatusError(*f.Exit)
+		case f.Exit != nil:
+			return nil
+		}
+	}
+}
+
+func (r *agentRunner) interactive(args []string) *exec.Cmd { return r.next.interactive(args) }
+func (r *agentRunner) program() string                     { return r.next.program() }
+func (r *agentRunner) String() string                      { return "agent (" + r.next.String() + ")" }
+
+// get decodes the JSON the agent answers at path into v.
+func (r *agentRunner) get(path string, query url.Values, v any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), multipassTimeouts[classMedium])
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, agentURL(path, query), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return errors.New(strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// vms returns the agent's VM list: the one it last polled, or a new one
+// when fresh is set.
+func (r *agentRunner) vms(fresh bool) ([]vmData, error) {
+	query := url.Values{}
+	if fresh {
+		query.Set("fresh", "1")
+	}
+	var infos []VMInfo
+	if err := r.get("/v1/vms", query, &infos); err != nil {
+		if r.gone(err) {
+			return doFetchVMList()
+		}
+		return nil, err
+	}
+	vms := make([]vmData, 0, len(infos))
+	for _, info := range infos {
+		vms = append(vms, vmData{info: info})
+	}
+	return vms, nil
+}
+
+// fetchVMList gets the VM list from the attached agent, which polls anyway,
+// or else from multipass. Only fresh lists make the agent poll again.
+func fetchVMList(fresh bool) ([]vmData, error) {
+	if r, ok := mpRunner.(*agentRunner); ok && !r.lost.Load() {
+		return r.vms(fresh)
+	}
+	return doFetchVMList()
+}
+
+// ─── TUI ───────────────────────────────────────────────────────────────────────
+
+// attachAgent routes the TUI's multipass commands through a running agent
+// and leaves the automations to it. It reports whether an agent answered.
+func (m *rootModel) attachAgent() bool {
+	if !agentRunning() {
+		return false
+	}
+	socket, err := agentSocketPath()
+	if err != nil {
+		return false
+	}
+	mpRunner = newAgentRunner(socket, mpRunner)
+	m.agent = true
+	m.table.agent = true
+	if appLogger != nil {
+		appLogger.Printf("attached to the agent at %s", socket)
+	}
+	return true
+}
+
+// checkAgent detaches from an agent that stopped answering: multipass runs
+// here again, and so do the automations.
+func (m *rootModel) checkAgent() tea.Cmd {
+	r, ok := mpRunner.(*agentRunner)
+	if !m.agent || !ok || !r.lost.Load() {
+		return nil
+	}
+	mpRunner = r.next
+	m.agent = false
+	m.table.agent = false
+	m.lastPowerCheck = time.Time{} // the agent handled the moments until now
+	m.lastJobCheck = time.Time{}
+	return m.table.addToastFor("passgo agent stopped; schedules and TTLs run here again", "info", 8*time.Second)
+}
+
+// reloadState picks up what the agent wrote to the state file. Everything
+// the TUI changes is merged into the file as it happens (see persistState),
+// so nothing is lost.
+func (m *rootModel) reloadState() {
+	if m.statePath == "" {
+		return
+	}
+	st, err := loadState(m.statePath)
+	if err != nil {
+		if appLogger != nil {
+			appLogger.Printf("failed to reload state: %v", err)
+		}
+		return
+	}
+	m.useState(st)
+}
+
+// useState makes st, as just read or written, the TUI's state.
+func (m *rootModel) useState(st appState) {
+	m.state = st
+	m.stateBase = st.clone()
+	m.table.meta = m.state.VMs
+	m.table.regroup()
+}
+
+// agentJobsMsg carries the agent's job history.
+type agentJobsMsg struct {
+	jobs []agentJob
+	err  error
+}
+
+func fetchAgentJobsCmd() tea.Cmd {
+	return func() tea.Msg {
+		r, ok := mpRunner.(*agentRunner)
+		if !ok {
+			return agentJobsMsg{err: errors.New("no agent attached")}
+		}
+		var jobs []agentJob
+		err := r.get("/v1/jobs", nil, &jobs)
+		return agentJobsMsg{jobs: jobs, err: err}
+	}
+}
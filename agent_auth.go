@@ -0,0 +1,141 @@
+// agent_auth.go - Bearer tokens for the agent's TCP listener
+package main
+
+import (
+	"context"
+	constanttime "crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// agentToken lets clients of `passgo agent -listen` in, with what its
+// scope allows. Configured in .config, one line per client:
+//
+//	agent-token: {name: prometheus, token: 6f1d0c2e9a8b47d3}
+//	agent-token: {name: ci, token: 9b2e77c4d1a05f83, scope: operator}
+//
+// The socket needs none: only its owner can connect to it.
+type agentToken struct {
+	name  string
+	token string
+	scope agentScope
+}
+
+// agentScope is what a token may do over TCP.
+type agentScope string
+
+const (
+	scopeReadOnly agentScope = "read-only" // the GET endpoints (the default)
+	scopeOperator agentScope = "operator"  // and start, stop, restart, suspend and snapshot VMs
+	scopeAdmin    agentScope = "admin"     // and any multipass command
+)
+
+// operatorCommands are the multipass commands an operator token may run
+// through POST /v1/run: power and snapshots, plus the ones that only read.
+var operatorCommands = map[string]bool{
+	"start": true, "stop": true, "restart": true, "suspend": true, "snapshot": true,
+	"list": true, "info": true, "version": true, "find": true, "networks": true,
+}
+
+// allows reports whether the scope covers r.
+func (s agentScope) allows(r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	switch s {
+	case scopeAdmin:
+		return true
+	case scopeOperator:
+		args := r.URL.Query()["arg"]
+		return r.URL.Path == "/v1/run" && len(args) > 0 && operatorCommands[args[0]]
+	}
+	return false
+}
+
+// agentTokenMinLength keeps tokens from being guessable.
+const agentTokenMinLength = 16
+
+// parseAgentToken parses "{name: ci, token: …, scope: operator}". The
+// token itself never appears in an error.
+func parseAgentToken(s string) (agentToken, error) {
+	t := agentToken{scope: scopeReadOnly}
+	body := strings.TrimSpace(s)
+	body = strings.TrimSuffix(strings.TrimPrefix(body, "{"), "}")
+	for _, part := range strings.Split(body, ",") {
+		key, val, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		switch key {
+		case "name":
+			t.name = val
+		case "token":
+			t.token = val
+		case "scope":
+			t.scope = agentScope(strings.ToLower(val))
+		default:
+			return t, fmt.Errorf("agent-token: unknown key %q", key)
+		}
+	}
+	switch {
+	case t.name == "":
+		return t, fmt.Errorf("agent-token: name is required")
+	case len(t.token) < agentTokenMinLength:
+		return t, fmt.Errorf("agent-token %s: token must be at least %d characters", t.name, agentTokenMinLength)
+	case t.scope != scopeReadOnly && t.scope != scopeOperator && t.scope != scopeAdmin:
+		return t, fmt.Errorf("agent-token %s: unknown scope %q (read-only, operator or admin)", t.name, t.scope)
+	}
+	return t, nil
+}
+
+// loadAgentTokens parses every agent-token entry, skipping invalid ones.
+func loadAgentTokens(values []string) ([]agentToken, []error) {
+	var tokens []agentToken
+	var errs []error
+	for _, v := range values {
+		t, err := parseAgentToken(v)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, errs
+}
+
+// matchAgentToken returns the token sent in r's Authorization header.
+func matchAgentToken(tokens []agentToken, r *http.Request) (agentToken, bool) {
+	sent, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return agentToken{}, false
+	}
+	for _, t := range tokens {
+		if constanttime.ConstantTimeCompare([]byte(sent), []byte(t.token)) == 1 {
+			return t, true
+		}
+	}
+	return agentToken{}, false
+}
+
+// agentTokenKey carries the token of a TCP request in its context.
+type agentTokenKey struct{}
+
+// requireAgentToken answers 401 to requests without one of tokens, and 403
+// to those their token's scope does not cover.
+func requireAgentToken(tokens []agentToken, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t, ok := matchAgentToken(tokens, r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="passgo agent"`)
+			http.Error(w, "a valid bearer token is required", http.StatusUnauthorized)
+			return
+		}
+		if !t.scope.allows(r) {
+			http.Error(w, fmt.Sprintf("token %s (%s) may not do this", t.name, t.scope), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), agentTokenKey{}, t)))
+	})
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpiredTTLs(t *testing.T) {
+	now := time.Date(2026, time.October, 15, 12, 0, 0, 0, time.UTC)
+	st := newAppState()
+	st.setTTL("web", now.Add(-time.Minute), "stop")
+	st.setTTL("tmp", now.Add(-time.Minute), "delete")
+	st.setTTL("later", now.Add(time.Hour), "stop")
+	st.setTTL("gone", now.Add(-time.Minute), "delete")
+	vms := []vmData{
+		{info: VMInfo{Name: "web", State: "Running"}},
+		{info: VMInfo{Name: "tmp", State: "Stopped"}},
+		{info: VMInfo{Name: "later", State: "Running"}},
+	}
+	got := expiredTTLs(st, vms, now)
+	want := []scheduledOp{{vm: "tmp", op: "soft-delete", reason: "TTL expired"}, {vm: "web", op: "stop", reason: "TTL expired"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ops = %+v, want %+v", got, want)
+	}
+	if !st.VMs["later"].Expires.After(now) || !st.VMs["gone"].Expires.Before(now) {
+		t.Error("only the expired TTLs of listed VMs may be cleared")
+	}
+	if _, ok := st.VMs["web"]; ok {
+		t.Error("web's TTL should be cleared")
+	}
+}
+
+func TestAgentPoll(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path, err := stateFilePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := newAppState()
+	st.setTTL("web", time.Now().Add(-time.Minute), "stop")
+	st.addNote("db", "keep")
+	if err := saveState(path, st); err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeRunner{outputs: map[string]string{"info": `{"info": {
+		"web": {"state": "Running", "cpu_count": "1", "load": [0.1, 0, 0], "memory": {"total": 1073741824, "used": 536870912}},
+		"db": {"state": "Running", "cpu_count": "2", "load": [0.5, 0, 0]}}}`}}
+	useFakeRunner(t, f)
+
+	var stdout, stderr bytes.Buffer
+	a := &agent{statePath: path, stdout: &stdout, stderr: &stderr}
+	a.poll(time.Now())
+	if !slices.ContainsFunc(f.calls, func(c []string) bool { return c[0] == "stop" && slices.Contains(c, "web") }) {
+		t.Fatalf("web should be stopped, calls %v", f.calls)
+	}
+	if !strings.Contains(stdout.String(), "stop web (TTL expired)") || stderr.Len() > 0 {
+		t.Errorf("stdout %q, stderr %q", stdout.String(), stderr.String())
+	}
+	st, err = loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.VMs["web"].Expires.IsZero() {
+		t.Error("the TTL should be cleared")
+	}
+	if n := len(st.History); n != 1 || st.History[0].Op != "stop" {
+		t.Errorf("history = %+v", st.History)
+	}
+	if len(st.Usage[st.VMs["db"].ID]) != 1 {
+		t.Errorf("db's usage should be sampled: %+v", st.Usage)
+	}
+}
+
+func TestAgentHandler(t *testing.T) {
+	a := &agent{started: time.Now(), vms: []vmData{
+		{info: VMInfo{Name: "web", State: "Running", CPUs: "2", Load: "1.00 0.5 0.1", MemoryUsage: "512.0MiB out of 1.0GiB"}},
+		{info: VMInfo{Name: "db", State: "Stopped"}},
+	}}
+	h := a.handler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/vms", nil))
+	var infos []VMInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &infos); err != nil || len(infos) != 2 || infos[0].Name != "web" {
+		t.Fatalf("vms = %s, %v", rec.Body, err)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	for _, want := range []string{
+		`passgo_instances{state="Running"} 1`,
+		`passgo_instance_running{vm="db"} 0`,
+		`passgo_instance_load_ratio{vm="web"} 0.5`,
+		`passgo_instance_memory_used_ratio{vm="web"} 0.5`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("metrics lack %q:\n%s", want, rec.Body)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/v1/status", nil))
+	if rec.Code != 405 {
+		t.Errorf("POST /v1/status = %d, want 405", rec.Code)
+	}
+}
+
+func TestAgentIdleStop(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path, err := stateFilePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeRunner{outputs: map[string]string{"info": `{"info": {
+		"quiet": {"state": "Running", "cpu_count": "2", "load": [0.0, 0, 0]},
+		"busy": {"state": "Running", "cpu_count": "2", "load": [1.5, 0, 0]}}}`}}
+	useFakeRunner(t, f)
+
+	var stdout, stderr bytes.Buffer
+	a := &agent{statePath: path, stdout: &stdout, stderr: &stderr,
+		idle: idlePolicy{after: 10 * time.Minute, threshold: defaultIdleLoad, stop: true}}
+	now := time.Now()
+	a.poll(now)
+	if len(a.jobs) != 0 {
+		t.Fatalf("the first poll only starts tracking, jobs %+v", a.jobs)
+	}
+	a.poll(now.Add(11 * time.Minute))
+	if len(a.jobs) != 1 || a.jobs[0].Command != "stop quiet" || !strings.HasPrefix(a.jobs[0].Reason, "idle for") {
+		t.Fatalf("jobs = %+v, stderr %q", a.jobs, stderr.String())
+	}
+	st, err := loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(st.History); n != 1 || st.History[0].VM != "quiet" || st.History[0].Op != "stop" {
+		t.Errorf("history = %+v", st.History)
+	}
+}
+
+func TestAgentTokens(t *testing.T) {
+	tokens, errs := loadAgentTokens([]string{
+		"{name: prometheus, token: 6f1d0c2e9a8b47d3}",
+		"{name: ci, token: 9b2e77c4d1a05f83, scope: operator}",
+		"{name: me, token: 0f6a1e5c33d94b72, scope: admin}",
+		"{name: short, token: abc}",
+		"{token: 0123456789abcdef0}",
+		"{name: root, token: 0123456789abcdef0, scope: root}",
+	})
+	if len(tokens) != 3 || tokens[0].scope != scopeReadOnly || len(errs) != 3 {
+		t.Fatalf("tokens %+v, errs %v", tokens, errs)
+	}
+	if strings.Contains(errs[0].Error(), "abc") {
+		t.Errorf("error shows the token: %v", errs[0])
+	}
+
+	f := &fakeRunner{}
+	useFakeRunner(t, f)
+	a := &agent{started: time.Now()}
+	h := requireAgentToken(tokens, a.handler())
+	for _, tc := range []struct {
+		auth, method, target string
+		want                 int
+	}{
+		{"", "GET", "/v1/status", http.StatusUnauthorized},
+		{"Bearer wrong", "GET", "/v1/status", http.StatusUnauthorized},
+		{"6f1d0c2e9a8b47d3", "GET", "/v1/status", http.StatusUnauthorized},
+		{"Bearer 6f1d0c2e9a8b47d3", "GET", "/v1/status", http.StatusOK},
+		{"Bearer 6f1d0c2e9a8b47d3", "POST", "/v1/run?arg=list", http.StatusForbidden},
+		{"Bearer 9b2e77c4d1a05f83", "POST", "/v1/run?arg=stop&arg=web", http.StatusOK},
+		{"Bearer 9b2e77c4d1a05f83", "POST", "/v1/run?arg=delete&arg=web", http.StatusForbidden},
+		{"Bearer 9b2e77c4d1a05f83", "POST", "/v1/run?arg=exec&arg=web&arg=--&arg=id", http.StatusForbidden},
+		{"Bearer 0f6a1e5c33d94b72", "POST", "/v1/run?arg=delete&arg=web", http.StatusOK},
+	} {
+		req := httptest.NewRequest(tc.method, tc.target, nil)
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%s %s with %q = %d, want %d", tc.method, tc.target, tc.auth, rec.Code, tc.want)
+		}
+	}
+	if len(f.calls) != 2 || f.calls[0][0] != "stop" || f.calls[1][0] != "delete" {
+		t.Errorf("calls = %v", f.calls)
+	}
+	if len(a.jobs) != 2 || a.jobs[0].Reason != "token ci" || a.jobs[1].Reason != "token me" {
+		t.Errorf("jobs = %+v", a.jobs)
+	}
+}
+
+func TestAgentListenNeedsToken(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	var stdout, stderr bytes.Buffer
+	if code := agentCommand([]string{"-listen", "127.0.0.1:0"}, nil, &stdout, &stderr); code != exitUsage {
+		t.Fatalf("exit %d, stderr %q", code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "agent-token") {
+		t.Errorf("stderr %q", stderr.String())
+	}
+}
+
+func TestAgentSocket(t *testing.T) {
+	home, err := os.MkdirTemp("", "pg") // t.TempDir() can exceed the socket path limit
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(home) })
+	t.Setenv("HOME", home)
+	if agentRunning() {
+		t.Fatal("no agent yet")
+	}
+	// A socket left by an agent that died is replaced.
+	if err := os.MkdirAll(filepath.Join(home, ".passgo"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".passgo", agentSocketName), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	l, _, err := listenAgentSocket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			c.Close()
+		}
+	}()
+	if !agentRunning() {
+		t.Error("the agent should answer")
+	}
+	if _, _, err := listenAgentSocket(); err == nil {
+		t.Error("a second agent must be refused")
+	}
+}
+
+func TestAgentRun(t *testing.T) {
+	f := &fakeRunner{outputs: map[string]string{"stop": "stopped\n", "list": "web\n"}, fail: map[string]error{"start": errors.New("no such instance")}}
+	useFakeRunner(t, f)
+	a := &agent{}
+	h := a.handler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/v1/run?arg=stop&arg=web", nil))
+	dec := json.NewDecoder(rec.Body)
+	var frames []agentFrame
+	for dec.More() {
+		var f agentFrame
+		if err := dec.Decode(&f); err != nil {
+			t.Fatal(err)
+		}
+		frames = append(frames, f)
+	}
+	if len(frames) != 2 || frames[0].Stream != "stdout" || string(frames[0].Data) != "stopped\n" || frames[1].Exit == nil || *frames[1].Exit != 0 {
+		t.Fatalf("frames = %+v", frames)
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/v1/run?arg=list", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/v1/run?arg=start&arg=db", nil))
+	if len(a.jobs) != 2 || a.jobs[0].Command != "stop web" || a.jobs[0].Error != "" || a.jobs[1].Error != "no such instance" {
+		t.Errorf("jobs = %+v, want only the changes", a.jobs)
+	}
+}
+
+func TestAgentRunner(t *testing.T) {
+	home, err := os.MkdirTemp("", "pg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(home) })
+	t.Setenv("HOME", home)
+	f := &fakeRunner{outputs: map[string]string{"version": "multipass 1.14.0\n"}, fail: map[string]error{"start": errors.New("no such instance")}}
+	useFakeRunner(t, f)
+	l, socket, err := listenAgentSocket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &agent{vms: []vmData{{info: VMInfo{Name: "web", State: "Running"}}}}
+	srv := &http.Server{Handler: a.handler()}
+	go srv.Serve(l)
+
+	fallback := &fakeRunner{outputs: map[string]string{"version": "local\n"}}
+	r := newAgentRunner(socket, fallback)
+	var stdout, stderr bytes.Buffer
+	if err := r.run(context.Background(), []string{"version"}, nil, &stdout, &stderr); err != nil || stdout.String() != "multipass 1.14.0\n" {
+		t.Fatalf("version = %q, %v", stdout.String(), err)
+	}
+	if err := r.run(context.Background(), []string{"start", "db"}, nil, &stdout, &stderr); err == nil || !strings.Contains(stderr.String(), "boom") {
+		t.Errorf("start error = %v, stderr %q", err, stderr.String())
+	}
+	vms, err := r.vms(false)
+	if err != nil || len(vms) != 1 || vms[0].info.Name != "web" {
+		t.Errorf("vms = %+v, %v", vms, err)
+	}
+	var jobs []agentJob
+	if err := r.get("/v1/jobs", nil, &jobs); err != nil || len(jobs) != 1 || jobs[0].Command != "start db" {
+		t.Errorf("jobs = %+v, %v", jobs, err)
+	}
+
+	// Once the agent is gone, commands run here.
+	srv.Close()
+	stdout.Reset()
+	if err := r.run(context.Background(), []string{"version"}, nil, &stdout, &stderr); err != nil || stdout.String() != "local\n" {
+		t.Fatalf("after the agent stopped: %q, %v", stdout.String(), err)
+	}
+	if !r.lost.Load() {
+		t.Error("the agent should be marked lost")
+	}
+
+	mpRunner = r
+	m := rootModel{table: newTableModel(), agent: true}
+	m.table.agent = true
+	if m.checkAgent() == nil || m.agent || m.table.agent || mpRunner != fallback {
+		t.Error("the TUI should detach from a lost agent")
+	}
+}
+
+func TestAgentCronJobs(t *testing.T) {
+	f := &fakeRunner{}
+	useFakeRunner(t, f)
+	var stdout, stderr bytes.Buffer
+	now := time.Date(2026, time.October, 15, 20, 0, 30, 0, time.Local)
+	a := &agent{stdout: &stdout, stderr: &stderr, lastCron: now.Add(-time.Minute)}
+	a.cron, _ = loadCronJobs([]string{"{name: evening, cron: 0 20 * * *, vm: ci, do: stop}", "{cron: 0 20 * * *, vm: gone, do: stop}"})
+	runs := a.runCronJobs(map[string]string{"ci": "Running"}, now)
+	if len(runs) != 1 || runs[0].output != "stopped" || !a.lastCron.Equal(now) {
+		t.Fatalf("runs = %+v", runs)
+	}
+	if len(a.jobs) != 1 || a.jobs[0].Reason != "job evening" || !strings.Contains(stdout.String(), "job evening: stopped") {
+		t.Errorf("history %+v, stdout %q", a.jobs, stdout.String())
+	}
+}
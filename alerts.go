@@ -0,0 +1,91 @@
+// alerts.go - Disk usage alerts for running VMs
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// diskAlertPolicy flags VMs whose disk is fuller than threshold. Configured
+// in .config with disk-alert (percent, default 90, 0 or off to disable) and
+// disk-alert-notify (also send a desktop notification).
+type diskAlertPolicy struct {
+	threshold float64 // fraction of the disk, 0 = disabled
+	notify    bool
+}
+
+const defaultDiskAlertPercent = 90
+
+// loadDiskAlertPolicy reads the policy using lookup (normally configValue).
+func loadDiskAlertPolicy(lookup func(string) (string, bool)) diskAlertPolicy {
+	p := diskAlertPolicy{threshold: defaultDiskAlertPercent / 100.0}
+	if v, ok := lookup("disk-alert"); ok {
+		v = strings.TrimSuffix(strings.TrimSpace(v), "%")
+		if n, err := strconv.ParseFloat(v, 64); err == nil && n >= 0 && n <= 100 {
+			p.threshold = n / 100
+		} else if strings.EqualFold(v, "off") {
+			p.threshold = 0
+		}
+	}
+	if v, ok := lookup("disk-alert-notify"); ok {
+		p.notify = parseConfigBool(v)
+	}
+	return p
+}
+
+// checkDiskUsage updates the table's disk warnings from the latest VM list.
+// A VM crossing the threshold raises a toast (and a desktop notification if
+// enabled) once; it can alert again after dropping back below.
+func (m *rootModel) checkDiskUsage() []tea.Cmd {
+	if m.diskAlert.threshold <= 0 {
+		m.table.diskFull = nil
+		return nil
+	}
+	full := make(map[string]bool)
+	var cmds []tea.Cmd
+	for _, vm := range m.table.vms {
+		frac, ok := parseUsageFraction(vm.info.DiskUsage)
+		if vm.info.State != "Running" || !ok || frac < m.diskAlert.threshold {
+			continue
+		}
+		name := vm.info.Name
+		full[name] = true
+		if m.table.diskFull[name] {
+			continue
+		}
+		msg := fmt.Sprintf("%s disk is %d%% full (%s)", name, int(frac*100), vm.info.DiskUsage)
+		cmds = append(cmds, m.table.addToastFor("⚠ "+msg, "error", 8*time.Second))
+		if m.diskAlert.notify {
+			cmds = append(cmds, desktopNotifyCmd("passgo: disk almost full", msg))
+		}
+	}
+	m.table.diskFull = full
+	return cmds
+}
+
+// desktopNotifyCmd shows a desktop notification where a notifier exists
+// (notify-send on Linux, osascript on macOS). Failures are only logged.
+func desktopNotifyCmd(title, body string) tea.Cmd {
+	return func() tea.Msg {
+		var cmd *exec.Cmd
+		switch runtime.GOOS {
+		case "linux":
+			cmd = exec.Command("notify-send", title, body) // #nosec G204 -- fixed notifier, message as argument
+		case "darwin":
+			script := fmt.Sprintf("display notification %q with title %q", body, title)
+			cmd = exec.Command("osascript", "-e", script) // #nosec G204 -- fixed notifier, quoted message
+		default:
+			return nil
+		}
+		if err := cmd.Run(); err != nil && appLogger != nil {
+			appLogger.Printf("desktop notification failed: %v", err)
+		}
+		return nil
+	}
+}
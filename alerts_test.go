@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestLoadDiskAlertPolicy(t *testing.T) {
+	lookup := func(values map[string]string) func(string) (string, bool) {
+		return func(k string) (string, bool) { v, ok := values[k]; return v, ok }
+	}
+	if p := loadDiskAlertPolicy(lookup(nil)); p.threshold != 0.9 || p.notify {
+		t.Fatalf("unexpected default policy: %+v", p)
+	}
+	if p := loadDiskAlertPolicy(lookup(map[string]string{"disk-alert": "75%", "disk-alert-notify": "yes"})); p.threshold != 0.75 || !p.notify {
+		t.Fatalf("unexpected policy: %+v", p)
+	}
+	if p := loadDiskAlertPolicy(lookup(map[string]string{"disk-alert": "off"})); p.threshold != 0 {
+		t.Fatalf("expected alerts disabled, got %+v", p)
+	}
+}
+
+func TestCheckDiskUsage(t *testing.T) {
+	m := rootModel{table: newTableModel(), diskAlert: diskAlertPolicy{threshold: 0.9}}
+	m.table.setVMs([]vmData{
+		{info: VMInfo{Name: "full", State: "Running", DiskUsage: "4.6GiB out of 4.8GiB"}},
+		{info: VMInfo{Name: "fine", State: "Running", DiskUsage: "1.0GiB out of 4.8GiB"}},
+		{info: VMInfo{Name: "off", State: "Stopped", DiskUsage: "--"}},
+	})
+
+	if cmds := m.checkDiskUsage(); len(cmds) != 1 {
+		t.Fatalf("expected one alert, got %d", len(cmds))
+	}
+	if !m.table.diskFull["full"] || m.table.diskFull["fine"] || m.table.diskFull["off"] {
+		t.Fatalf("unexpected flags: %v", m.table.diskFull)
+	}
+	if cmds := m.checkDiskUsage(); len(cmds) != 0 {
+		t.Fatalf("expected no repeat alert while still full, got %d", len(cmds))
+	}
+}
@@ -0,0 +1,147 @@
+// arch.go - Host architecture and images that cannot run on it (Apple Silicon)
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// imageURLArchRe finds the architecture in an image file name, as in
+// noble-server-cloudimg-arm64.img or Fedora-Cloud-Base-41.x86_64.qcow2.
+var imageURLArchRe = regexp.MustCompile(`(?i)(?:^|[-_.])(arm64|aarch64|amd64|x86[-_]64)(?:[-_.]|$)`)
+
+// normalizeArch maps the names the same architecture goes by to Go's.
+func normalizeArch(arch string) string {
+	switch strings.ToLower(arch) {
+	case "aarch64", "arm64":
+		return "arm64"
+	case "x86_64", "x86-64", "amd64":
+		return "amd64"
+	}
+	return strings.ToLower(arch)
+}
+
+// detectHostArch returns the architecture VMs run as: goarch, except that an
+// amd64 passgo under Rosetta on an M-series Mac still launches arm64 VMs.
+// It is "" for multipass on another machine, whose architecture is unknown.
+func detectHostArch(goos, goarch string, runner multipassRunner, rosetta func() bool) string {
+	if _, local := runner.(localRunner); !local {
+		if _, wsl := runner.(wslRunner); !wsl {
+			return ""
+		}
+	}
+	if goos == "darwin" && goarch == "amd64" && rosetta() {
+		return "arm64"
+	}
+	return normalizeArch(goarch)
+}
+
+// macIsARM64 reports whether this Mac has an Apple Silicon CPU.
+func macIsARM64() bool {
+	out, err := exec.Command("sysctl", "-n", "hw.optional.arm64").Output()
+	return err == nil && strings.TrimSpace(string(out)) == "1"
+}
+
+// hostArch is detectHostArch for this process, worked out once.
+var hostArch = sync.OnceValue(func() string {
+	return detectHostArch(runtime.GOOS, runtime.GOARCH, mpRunner, macIsARM64)
+})
+
+// imageURLArch returns the architecture named in an image URL's file
+// name, or "" when it names none.
+func imageURLArch(url string) string {
+	m := imageURLArchRe.FindStringSubmatch(path.Base(url))
+	if m == nil {
+		return ""
+	}
+	return normalizeArch(m[1])
+}
+
+// imageURLArchWarning warns when an image URL is built for another
+// architecture than host; multipass would launch it and the VM not boot.
+func imageURLArchWarning(url, host string) string {
+	arch := imageURLArch(url)
+	if host == "" || arch == "" || arch == host {
+		return ""
+	}
+	return fmt.Sprintf("%s is an %s image and this host runs %s VMs", path.Base(url), arch, host)
+}
+
+// defaultRemoteImage reports whether release names an image of the
+// default remote, the one `multipass find` lists without a prefix.
+func defaultRemoteImage(release string) bool {
+	if release == "" || isImageURL(release) {
+		return false
+	}
+	remote, _, found := strings.Cut(release, ":")
+	return !found || remote == "release"
+}
+
+// imageOffered reports whether release is the name or an alias of one of
+// images. multipass find only lists images built for the host.
+func imageOffered(release string, images []ImageInfo) bool {
+	_, ok := findImage(release, images)
+	return ok
+}
+
+// unavailableArchMessage explains why release cannot launch on host.
+func unavailableArchMessage(release, host string) string {
+	return fmt.Sprintf("Ubuntu %s has no %s image, so multipass would fail with \"image not found\"", release, host)
+}
+
+// archWarning returns the Advanced Create warning for launching release on
+// host, given the default remote's images (nil while unknown).
+func archWarning(release, host string, images []ImageInfo) string {
+	if isImageURL(release) {
+		return imageURLArchWarning(release, host)
+	}
+	if host == "arm64" && images != nil && defaultRemoteImage(release) && !imageOffered(release, images) {
+		return unavailableArchMessage(release, host)
+	}
+	return ""
+}
+
+// checkImageArch is the same check for `passgo launch`/`run`: an image URL
+// for another architecture is a warning to print, while a release with no
+// image for the host is an error, as multipass cannot launch it either.
+// Listing images is skipped when it fails; multipass then has the last word.
+func checkImageArch(release, host string, find func(string) ([]ImageInfo, error)) (string, error) {
+	if isImageURL(release) {
+		return imageURLArchWarning(release, host), nil
+	}
+	if host != "arm64" || !defaultRemoteImage(release) {
+		return "", nil
+	}
+	images, err := find("")
+	if err != nil || imageOffered(release, images) {
+		return "", nil
+	}
+	return "", fmt.Errorf("%s; run `multipass find` for the images this host can launch", unavailableArchMessage(release, host))
+}
+
+// hostImagesMsg carries the default remote's images, listed when Advanced
+// Create opens on an arm64 host or with image pins to compare against.
+type hostImagesMsg struct {
+	images []ImageInfo
+	err    error
+}
+
+// fetchHostImagesCmd lists the images the host can launch, or does nothing
+// on hosts where every curated release is available, unless pinned says
+// launches have image pins (see imagepin.go).
+func fetchHostImagesCmd(pinned bool) tea.Cmd {
+	if hostArch() != "arm64" && !pinned {
+		return nil
+	}
+	return func() tea.Msg {
+		images, err := FindImages("")
+		return hostImagesMsg{images: images, err: err}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDetectHostArch(t *testing.T) {
+	rosetta := func() bool { return true }
+	native := func() bool { return false }
+	for _, c := range []struct {
+		goos, goarch string
+		runner       multipassRunner
+		rosetta      func() bool
+		want         string
+	}{
+		{"darwin", "arm64", localRunner{}, native, "arm64"},
+		{"darwin", "amd64", localRunner{}, rosetta, "arm64"},
+		{"darwin", "amd64", localRunner{}, native, "amd64"},
+		{"linux", "amd64", localRunner{}, rosetta, "amd64"},
+		{"windows", "arm64", wslRunner{}, native, "arm64"},
+		{"darwin", "arm64", sshRunner{host: "box"}, native, ""},
+	} {
+		if got := detectHostArch(c.goos, c.goarch, c.runner, c.rosetta); got != c.want {
+			t.Errorf("detectHostArch(%s, %s, %v) = %q, want %q", c.goos, c.goarch, c.runner, got, c.want)
+		}
+	}
+}
+
+func TestImageURLArch(t *testing.T) {
+	for url, want := range map[string]string{
+		"https://cloud-images.ubuntu.com/noble/current/noble-server-cloudimg-arm64.img": "arm64",
+		"https://example.com/Fedora-Cloud-Base-41-1.4.x86_64.qcow2":                     "amd64",
+		"file:///srv/images/debian-12-generic-amd64.qcow2":                              "amd64",
+		"file:///srv/images/alpine-aarch64.img":                                         "arm64",
+		"https://example.com/arm64/hardened.img":                                        "",
+		"file:///srv/images/noble-hardened.img":                                         "",
+	} {
+		if got := imageURLArch(url); got != want {
+			t.Errorf("imageURLArch(%s) = %q, want %q", url, got, want)
+		}
+	}
+	if imageURLArchWarning("file:///x/noble-amd64.img", "arm64") == "" {
+		t.Error("an amd64 image on an arm64 host should warn")
+	}
+	if imageURLArchWarning("file:///x/noble-arm64.img", "arm64") != "" || imageURLArchWarning("file:///x/noble-amd64.img", "") != "" {
+		t.Error("matching or unknown architectures should not warn")
+	}
+}
+
+func TestArchWarning(t *testing.T) {
+	images := []ImageInfo{
+		{Name: "22.04", Aliases: []string{"jammy"}},
+		{Name: "24.04", Aliases: []string{"noble", "lts"}},
+	}
+	for _, c := range []struct {
+		release, host string
+		images        []ImageInfo
+		warn          bool
+	}{
+		{"24.04", "arm64", images, false},
+		{"noble", "arm64", images, false},
+		{"release:jammy", "arm64", images, false},
+		{"18.04", "arm64", images, true},
+		{"18.04", "arm64", nil, false}, // not listed yet
+		{"18.04", "amd64", images, false},
+		{"daily:25.10", "arm64", images, false},
+		{"file:///x/noble-amd64.img", "arm64", nil, true},
+	} {
+		if got := archWarning(c.release, c.host, c.images); (got != "") != c.warn {
+			t.Errorf("archWarning(%s, %s) = %q, want warning: %v", c.release, c.host, got, c.warn)
+		}
+	}
+}
+
+func TestCheckImageArch(t *testing.T) {
+	calls := 0
+	find := func(remote string) ([]ImageInfo, error) {
+		calls++
+		return []ImageInfo{{Name: "24.04", Aliases: []string{"noble"}}}, nil
+	}
+	if _, err := checkImageArch("noble", "arm64", find); err != nil {
+		t.Errorf("noble should launch: %v", err)
+	}
+	_, err := checkImageArch("20.04", "arm64", find)
+	if err == nil || !strings.Contains(err.Error(), "no arm64 image") {
+		t.Errorf("20.04 on arm64: err = %v", err)
+	}
+	warn, err := checkImageArch("https://example.com/noble-amd64.img", "arm64", find)
+	if err != nil || warn == "" {
+		t.Errorf("foreign image URL: warn %q, err %v", warn, err)
+	}
+	calls = 0
+	if _, err := checkImageArch("20.04", "amd64", find); err != nil || calls != 0 {
+		t.Errorf("amd64 hosts should not list images (calls %d, err %v)", calls, err)
+	}
+	failing := func(string) ([]ImageInfo, error) { return nil, errors.New("daemon down") }
+	if _, err := checkImageArch("20.04", "arm64", failing); err != nil {
+		t.Errorf("a failed listing should leave the decision to multipass: %v", err)
+	}
+}
+
+func TestGalleryEntryRunsOn(t *testing.T) {
+	e := galleryEntry{Arch: []string{"x86_64"}}
+	if !e.runsOn("amd64") || e.runsOn("arm64") || !e.runsOn("") {
+		t.Error("runsOn should match normalized architectures and allow unknown hosts")
+	}
+	if !(galleryEntry{}).runsOn("arm64") {
+		t.Error("entries without arch run anywhere")
+	}
+
+	m := galleryModel{index: galleryIndex{Templates: []galleryEntry{e}}, arch: "arm64", height: 40, width: 100}
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || !m.warnAcked {
+		t.Fatal("first Enter on a foreign template should only warn")
+	}
+	if !strings.Contains(m.View(), "not made for arm64") {
+		t.Error("the warning should be shown")
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil {
+		t.Error("second Enter should pick the template")
+	}
+}
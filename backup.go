@@ -0,0 +1,151 @@
+// backup.go - Archiving paths inside a VM to the host and restoring them
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Multipass has no export, so backups are tarballs of chosen paths made with
+// `multipass exec` and copied to the host with `multipass transfer`. They
+// are named <vm>-<timestamp>.tar.gz in the backup directory.
+
+// backupTimeLayout timestamps archive names (local time).
+const backupTimeLayout = "20060102-150405"
+
+// defaultBackupPaths is offered when .config has no backup-paths.
+const defaultBackupPaths = "/home/ubuntu"
+
+// backupArchive is a backup file on the host.
+type backupArchive struct {
+	Name    string
+	Path    string
+	Size    int64
+	Created time.Time
+}
+
+// backupDir returns backup-dir from .config, or ~/.passgo/backups.
+func backupDir() (string, error) {
+	if v, ok := configValue("backup-dir"); ok && strings.TrimSpace(v) != "" {
+		return strings.TrimSpace(v), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".passgo", "backups"), nil
+}
+
+// backupArchiveName returns the archive file name for vmName at t.
+func backupArchiveName(vmName string, t time.Time) string {
+	return vmName + "-" + t.Format(backupTimeLayout) + ".tar.gz"
+}
+
+// parseBackupArchiveName returns when an archive of vmName was taken. Names
+// of other VMs sharing a prefix ("db" vs "db-test") do not match.
+func parseBackupArchiveName(vmName, name string) (time.Time, bool) {
+	rest, ok := strings.CutPrefix(name, vmName+"-")
+	if !ok {
+		return time.Time{}, false
+	}
+	rest, ok = strings.CutSuffix(rest, ".tar.gz")
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(backupTimeLayout, rest, time.Local)
+	return t, err == nil
+}
+
+// listBackups returns vmName's archives in dir, newest first. A missing dir
+// has no backups.
+func listBackups(dir, vmName string) ([]backupArchive, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var archives []backupArchive
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		created, ok := parseBackupArchiveName(vmName, e.Name())
+		if !ok {
+			continue
+		}
+		a := backupArchive{Name: e.Name(), Path: filepath.Join(dir, e.Name()), Created: created}
+		if info, err := e.Info(); err == nil {
+			a.Size = info.Size()
+		}
+		archives = append(archives, a)
+	}
+	sort.Slice(archives, func(i, j int) bool { return archives[i].Created.After(archives[j].Created) })
+	return archives, nil
+}
+
+// parseBackupPaths splits a space- or comma-separated list of absolute paths
+// into tar arguments relative to /.
+func parseBackupPaths(s string) ([]string, error) {
+	var rel []string
+	for _, p := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if !strings.HasPrefix(p, "/") {
+			return nil, fmt.Errorf("backup path %q must be absolute", p)
+		}
+		p = strings.TrimLeft(filepath.Clean(p), "/")
+		if p == "" {
+			return nil, errors.New("backing up / is not supported; choose directories")
+		}
+		rel = append(rel, p)
+	}
+	if len(rel) == 0 {
+		return nil, errors.New("no paths to back up")
+	}
+	return rel, nil
+}
+
+// BackupVM archives paths (see parseBackupPaths) inside vmName and copies
+// the archive into dir, returning its host path.
+func BackupVM(vmName string, paths []string, dir string, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+	name := backupArchiveName(vmName, now)
+	remote := "/tmp/passgo-backup-" + name
+	defer func() { _, _ = ExecInVM(vmName, "sudo", "rm", "-f", remote) }()
+
+	tarArgs := append([]string{"sudo", "tar", "-czpf", remote, "-C", "/"}, paths...)
+	if _, err := ExecInVM(vmName, tarArgs...); err != nil {
+		return "", fmt.Errorf("archive in VM failed: %w", err)
+	}
+	// The default user must be able to read the archive for transfer.
+	if _, err := ExecInVM(vmName, "sudo", "chmod", "0644", remote); err != nil {
+		return "", err
+	}
+	local := filepath.Join(dir, name)
+	if _, err := TransferFromVM(vmName, remote, local); err != nil {
+		_ = os.Remove(local)
+		return "", fmt.Errorf("transfer to host failed: %w", err)
+	}
+	return local, nil
+}
+
+// RestoreBackup uploads archive to vmName and extracts it over /, replacing
+// the backed-up files.
+func RestoreBackup(vmName, archive string) error {
+	remote := "/tmp/passgo-restore-" + filepath.Base(archive)
+	if _, err := TransferToVM(vmName, archive, remote); err != nil {
+		return fmt.Errorf("transfer to VM failed: %w", err)
+	}
+	defer func() { _, _ = ExecInVM(vmName, "sudo", "rm", "-f", remote) }()
+	if _, err := ExecInVM(vmName, "sudo", "tar", "-xzpf", remote, "-C", "/"); err != nil {
+		return fmt.Errorf("extract in VM failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestListBackups(t *testing.T) {
+	dir := t.TempDir()
+	older := time.Date(2026, time.October, 1, 9, 30, 0, 0, time.Local)
+	newer := older.Add(48 * time.Hour)
+	for _, name := range []string{
+		backupArchiveName("db", older),
+		backupArchiveName("db", newer),
+		backupArchiveName("db-test", newer), // other VM sharing the prefix
+		"db-notes.txt",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := listBackups(dir, "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d archives, want 2: %+v", len(got), got)
+	}
+	if !got[0].Created.Equal(newer) || !got[1].Created.Equal(older) || got[0].Size != 1 {
+		t.Fatalf("archives not newest-first with sizes: %+v", got)
+	}
+
+	if got, err := listBackups(filepath.Join(dir, "missing"), "db"); err != nil || got != nil {
+		t.Fatalf("missing dir: got %v, %v", got, err)
+	}
+}
+
+func TestParseBackupPaths(t *testing.T) {
+	got, err := parseBackupPaths("/home/ubuntu, /etc/nginx/ /var//lib/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"home/ubuntu", "etc/nginx", "var/lib/app"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for _, bad := range []string{"", "relative/path", "/"} {
+		if _, err := parseBackupPaths(bad); err == nil {
+			t.Errorf("parseBackupPaths(%q) should fail", bad)
+		}
+	}
+}
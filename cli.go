@@ -0,0 +1,137 @@
+// cli.go - Exit codes and output styles shared by the CLI subcommands
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Exit codes of the subcommands. They are stable so scripts can branch on
+// them; `passgo run` exits with the command's own code once it ran.
+const (
+	exitOK          = 0
+	exitFailed      = 1 // any other failure
+	exitUsage       = 2 // bad flags, arguments or script
+	exitNotFound    = 3 // an instance, snapshot or file does not exist
+	exitUnreachable = 4 // multipass or its daemon cannot be reached
+	exitTimeout     = 5 // a multipass command hit its timeout
+)
+
+// exitKinds names the exit codes in -porcelain errors.
+var exitKinds = map[int]string{
+	exitFailed:      "failed",
+	exitUsage:       "usage",
+	exitNotFound:    "not-found",
+	exitUnreachable: "unreachable",
+	exitTimeout:     "timeout",
+}
+
+// usageError is a mistake in how a subcommand was called.
+type usageError struct{ err error }
+
+func (e usageError) Error() string { return e.err.Error() }
+func (e usageError) Unwrap() error { return e.err }
+
+func usageErrorf(format string, args ...any) error {
+	return usageError{fmt.Errorf(format, args...)}
+}
+
+// inputError marks a bad file or pipe named on the command line as a usage
+// error, except a missing file, which exits as not found.
+func inputError(err error) error {
+	if errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return usageError{err}
+}
+
+// exitCodeFor classifies err. multipass only reports missing instances and
+// an unreachable daemon in its messages, so those are matched as text.
+func exitCodeFor(err error) int {
+	var usage usageError
+	msg := ""
+	if err != nil {
+		msg = strings.ToLower(err.Error())
+	}
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usage), errors.Is(err, errReplSyntax):
+		return exitUsage
+	case errors.Is(err, errCommandTimeout):
+		return exitTimeout
+	case errors.Is(err, exec.ErrNotFound),
+		strings.Contains(msg, "cannot connect to the multipass socket"),
+		strings.Contains(msg, "multipassd is running"):
+		return exitUnreachable
+	case errors.Is(err, os.ErrNotExist), strings.Contains(msg, "does not exist"):
+		return exitNotFound
+	}
+	return exitFailed
+}
+
+// cliOutput is a subcommand's output style. -quiet drops progress lines;
+// -porcelain does too, and prints errors as one JSON object per line.
+// -progress json replaces progress lines with JSON events (see progress.go).
+type cliOutput struct {
+	command      string
+	quiet        bool
+	porcelain    bool
+	progressJSON bool
+}
+
+// register adds -quiet and -porcelain to fs.
+func (c *cliOutput) register(fs *flag.FlagSet, command string) {
+	c.command = command
+	fs.BoolVar(&c.quiet, "quiet", false, "print only results and errors")
+	fs.BoolVar(&c.porcelain, "porcelain", false, "stable machine-readable output; errors as JSON on stderr")
+}
+
+// registerProgress adds -progress to fs, for long-running subcommands.
+func (c *cliOutput) registerProgress(fs *flag.FlagSet) {
+	fs.Func("progress", "progress output on stderr: text (default) or json (one event per line)", func(s string) error {
+		switch s {
+		case "text", "json":
+			c.progressJSON = s == "json"
+			return nil
+		}
+		return errors.New("want text or json")
+	})
+}
+
+// reporter returns the progress reporter writing to w. JSON progress is
+// written even with -quiet or -porcelain, which otherwise drop progress.
+func (c cliOutput) reporter(w io.Writer) progressReporter {
+	switch {
+	case c.progressJSON:
+		return progressReporter{w: w, json: true}
+	case c.quiet || c.porcelain:
+		return progressReporter{w: io.Discard}
+	}
+	return progressReporter{w: w}
+}
+
+// cliError is the -porcelain form of an error.
+type cliError struct {
+	Command string `json:"command"`
+	Exit    int    `json:"exit"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// fail reports err on w and returns its exit code.
+func (c cliOutput) fail(w io.Writer, err error) int {
+	code := exitCodeFor(err)
+	if c.porcelain {
+		_ = json.NewEncoder(w).Encode(cliError{Command: c.command, Exit: code, Kind: exitKinds[code], Message: err.Error()})
+	} else {
+		fmt.Fprintf(w, "passgo %s: %v\n", c.command, err)
+	}
+	return code
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestExitCodeFor(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, exitOK},
+		{errors.New("boom"), exitFailed},
+		{usageErrorf("bad flag"), exitUsage},
+		{fmt.Errorf("line 3: %w", errReplSyntax), exitUsage},
+		{fmt.Errorf("command failed: %w", errCommandTimeout), exitTimeout},
+		{fmt.Errorf("command failed: %w", exec.ErrNotFound), exitUnreachable},
+		{errors.New("command failed: exit status 2\nStderr: cannot connect to the multipass socket"), exitUnreachable},
+		{errors.New("command failed: exit status 2\nStderr: info failed: instance \"web\" does not exist"), exitNotFound},
+		{fmt.Errorf("open x: %w", os.ErrNotExist), exitNotFound},
+	}
+	for _, tt := range tests {
+		if got := exitCodeFor(tt.err); got != tt.want {
+			t.Errorf("exitCodeFor(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestCLIOutput(t *testing.T) {
+	var buf bytes.Buffer
+	out := cliOutput{command: "launch"}
+	if code := out.fail(&buf, usageErrorf("unexpected arguments")); code != exitUsage || buf.String() != "passgo launch: unexpected arguments\n" {
+		t.Errorf("fail = %d, %q", code, buf.String())
+	}
+	if r := out.reporter(&buf); r.w != &buf || r.json {
+		t.Error("progress should go to the writer as text by default")
+	}
+
+	buf.Reset()
+	out.porcelain = true
+	if out.reporter(&buf).w != io.Discard {
+		t.Error("porcelain should drop progress")
+	}
+	out.progressJSON = true
+	if r := out.reporter(&buf); r.w != &buf || !r.json {
+		t.Error("-progress json should be kept with -porcelain")
+	}
+	code := out.fail(&buf, fmt.Errorf("launch: %w", errCommandTimeout))
+	var got cliError
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("porcelain error %q: %v", buf.String(), err)
+	}
+	if code != exitTimeout || got.Exit != exitTimeout || got.Kind != "timeout" || got.Command != "launch" {
+		t.Errorf("porcelain error = %+v, code %d", got, code)
+	}
+}
@@ -0,0 +1,90 @@
+// clone.go - New instances cloned from another VM as it was at a snapshot
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// instanceNameRe matches the names multipass accepts: letters, digits and
+// dashes, starting with a letter and not ending with a dash.
+var instanceNameRe = regexp.MustCompile(`^[A-Za-z]([A-Za-z0-9-]*[A-Za-z0-9])?$`)
+
+// parseCloneNames splits the clone names (spaces or commas) and rejects
+// invalid, repeated or already used names, and names policy forbids.
+func parseCloneNames(s string, exists func(string) bool, policy namingPolicy) ([]string, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(fields) == 0 {
+		return nil, errors.New("enter at least one name")
+	}
+	seen := make(map[string]bool)
+	for _, name := range fields {
+		if err := policy.check(name); err != nil {
+			return nil, err
+		}
+		switch {
+		case seen[name]:
+			return nil, fmt.Errorf("%q is listed twice", name)
+		case exists(name):
+			return nil, fmt.Errorf("an instance named %q already exists", name)
+		}
+		seen[name] = true
+	}
+	return fields, nil
+}
+
+// cloneHoldSnapshot names the snapshot that keeps the source's current state
+// while it is reverted for cloning.
+func cloneHoldSnapshot(now time.Time) string {
+	return "passgo-clone-" + now.UTC().Format("20060102-150405")
+}
+
+// CloneFromSnapshot creates one instance per name from the stopped VM source
+// as it was at snapshot snap. multipass clone copies an instance's current
+// state, so the source is snapshotted, reverted to snap, cloned, and then
+// put back the way it was; the holding snapshot is deleted afterwards.
+func CloneFromSnapshot(source, snap string, names []string, now time.Time) error {
+	hold := cloneHoldSnapshot(now)
+	if _, err := CreateSnapshot(source, hold, "passgo: state before cloning from "+snap); err != nil {
+		return fmt.Errorf("saving the current state of %s: %w", source, err)
+	}
+	if _, err := RestoreSnapshot(source, snap); err != nil {
+		_, _ = DeleteSnapshot(source, hold)
+		return fmt.Errorf("reverting %s to %s: %w", source, snap, err)
+	}
+	var errs []error
+	for _, name := range names {
+		if _, err := runMultipassCommand("clone", source, "--name", name); err != nil {
+			errs = append(errs, fmt.Errorf("cloning %s: %w", name, err))
+		}
+	}
+	if _, err := RestoreSnapshot(source, hold); err != nil {
+		// Keep the holding snapshot: it is the only copy of the source's state.
+		return errors.Join(append(errs, fmt.Errorf("putting %s back (its state is in snapshot %s): %w", source, hold, err))...)
+	}
+	if _, err := DeleteSnapshot(source, hold); err != nil {
+		errs = append(errs, fmt.Errorf("deleting snapshot %s.%s: %w", source, hold, err))
+	}
+	return errors.Join(errs...)
+}
+
+// cloneResultMsg reports a finished clone from a snapshot.
+type cloneResultMsg struct {
+	source string
+	snap   string
+	names  []string
+	err    error
+}
+
+// cloneFromSnapshotCmd clones source at snap in the background.
+func cloneFromSnapshotCmd(source, snap string, names []string) tea.Cmd {
+	return func() tea.Msg {
+		err := CloneFromSnapshot(source, snap, names, time.Now())
+		return cloneResultMsg{source: source, snap: snap, names: names, err: err}
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCloneNames(t *testing.T) {
+	exists := func(name string) bool { return name == "golden" }
+	got, err := parseCloneNames("web-1, web-2 web3", exists, namingPolicy{})
+	if err != nil || len(got) != 3 || got[0] != "web-1" || got[2] != "web3" {
+		t.Fatalf("got %v, %v", got, err)
+	}
+	for _, s := range []string{"", "web-1 web-1", "golden", "1web", "web-", "web_1"} {
+		if _, err := parseCloneNames(s, exists, namingPolicy{}); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestCloneHoldSnapshot(t *testing.T) {
+	now := time.Date(2026, time.October, 15, 9, 30, 5, 0, time.UTC)
+	if got := cloneHoldSnapshot(now); got != "passgo-clone-20261015-093005" {
+		t.Fatalf("cloneHoldSnapshot = %q", got)
+	}
+}
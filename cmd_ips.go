@@ -0,0 +1,67 @@
+// cmd_ips.go - `passgo ips`: running VMs and their IPs for other tools
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/atotto/clipboard"
+)
+
+const ipsUsage = `Usage: passgo ips [-format plain|json|ssh] [-copy] [FILTER...]
+
+Prints the running instances and their first IPv4 address, for pasting
+into inventories, hosts files or ~/.ssh/config. Only VMs whose name
+contains one of the FILTERs are listed, when given. Formats:
+
+  plain  one "name ip" line per VM (the default)
+  json   an object mapping each name to its IP
+  ssh    a Host block per VM: HostName, User ubuntu and, when the private
+         key of ssh-key in .config exists, IdentityFile
+
+Flags:
+`
+
+// ipsCommand implements `passgo ips`.
+func ipsCommand(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	var out cliOutput
+	fs := flag.NewFlagSet("ips", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, ipsUsage)
+		fs.PrintDefaults()
+	}
+	out.register(fs, "ips")
+	format := fs.String("format", "plain", "plain, json or ssh")
+	toClipboard := fs.Bool("copy", false, "copy to the clipboard instead of printing")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return out.fail(stderr, usageError{err})
+	}
+	if !slices.Contains(ipFormats, *format) {
+		return out.fail(stderr, usageErrorf("-format %q: want plain, json or ssh", *format))
+	}
+	vms, err := doFetchVMList()
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+	addrs := vmAddresses(vms, fs.Args())
+	data, err := renderIPs(*format, addrs, sshIdentityFile())
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+	if !*toClipboard {
+		_, _ = stdout.Write(data)
+		return exitOK
+	}
+	if err := clipboard.WriteAll(string(data)); err != nil {
+		return out.fail(stderr, fmt.Errorf("copying to the clipboard: %w", err))
+	}
+	out.reporter(stderr).say("", "done", 100, "copied %d VMs to the clipboard", len(addrs))
+	return exitOK
+}
@@ -0,0 +1,290 @@
+// cmd_k8s.go - `passgo k8s-lab`: a kubeadm cluster of one control plane and N workers
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// k8sLabOptions are the flags accepted by `passgo k8s-lab`.
+type k8sLabOptions struct {
+	prefix        string // control plane is <prefix>-cp, workers <prefix>-w1…
+	workers       int
+	release       string
+	cpus          int
+	memoryMB      int
+	diskGB        int
+	version       string // Kubernetes minor release, e.g. v1.31
+	podCIDR       string
+	cniManifest   string
+	cloudInitFile string // node template instead of the built-in one
+	kubeconfig    string
+	out           cliOutput
+}
+
+// Defaults for the lab nodes; kubeadm needs 2 CPUs and about 2GB per node.
+const (
+	k8sDefaultVersion = "v1.31"
+	k8sDefaultPodCIDR = "10.244.0.0/16"
+	k8sDefaultCNI     = "https://github.com/flannel-io/flannel/releases/latest/download/kube-flannel.yml"
+	k8sMinCPUs        = 2
+	k8sMinMemoryMB    = 2048
+)
+
+var (
+	k8sVersionRe = regexp.MustCompile(`^v[0-9]+\.[0-9]+$`)
+	k8sPrefixRe  = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*$`)
+	k8sCIDRRe    = regexp.MustCompile(`^[0-9]{1,3}(\.[0-9]{1,3}){3}/[0-9]{1,2}$`)
+)
+
+const k8sLabUsage = `Usage: passgo k8s-lab [flags]
+
+Launches a control-plane VM and N workers with containerd and kubeadm,
+initialises the cluster with a CNI plugin, joins the workers with the token
+from the control plane and writes the admin kubeconfig to the host.
+
+Flags:
+`
+
+// parseK8sLabOptions parses `passgo k8s-lab` arguments.
+func parseK8sLabOptions(args []string, stderr io.Writer) (k8sLabOptions, error) {
+	var o k8sLabOptions
+	fs := flag.NewFlagSet("k8s-lab", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, k8sLabUsage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&o.prefix, "name", "k8s", "name prefix: nodes are <name>-cp, <name>-w1, …")
+	fs.IntVar(&o.workers, "workers", 2, "number of worker nodes")
+	fs.StringVar(&o.release, "release", DefaultUbuntuRelease, "Ubuntu release for every node")
+	fs.IntVar(&o.cpus, "cpus", k8sMinCPUs, "vCPUs per node")
+	fs.IntVar(&o.memoryMB, "memory", 4096, "memory per node in MB")
+	fs.IntVar(&o.diskGB, "disk", 20, "disk per node in GB")
+	fs.StringVar(&o.version, "k8s-version", k8sDefaultVersion, "Kubernetes minor release from pkgs.k8s.io")
+	fs.StringVar(&o.podCIDR, "pod-cidr", k8sDefaultPodCIDR, "pod network CIDR passed to kubeadm init")
+	fs.StringVar(&o.cniManifest, "cni", k8sDefaultCNI, "CNI manifest applied after kubeadm init")
+	fs.StringVar(&o.cloudInitFile, "cloud-init", "", "node cloud-init template to use instead of the built-in one (must install kubeadm)")
+	fs.StringVar(&o.kubeconfig, "kubeconfig", "", "where to write the kubeconfig (default ~/.kube/passgo-<name>.yaml)")
+	o.out.register(fs, "k8s-lab")
+	o.out.registerProgress(fs)
+	if err := fs.Parse(args); err != nil {
+		return o, err
+	}
+	if fs.NArg() > 0 {
+		return o, fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+	switch {
+	case !k8sPrefixRe.MatchString(o.prefix):
+		return o, fmt.Errorf("-name %q: use letters, digits and dashes", o.prefix)
+	case o.workers < 0:
+		return o, errors.New("-workers must not be negative")
+	case o.cpus < k8sMinCPUs || o.memoryMB < k8sMinMemoryMB || o.diskGB < MinDiskGB:
+		return o, fmt.Errorf("kubeadm needs at least %d CPUs, %dMB and %dGB per node", k8sMinCPUs, k8sMinMemoryMB, MinDiskGB)
+	case !k8sVersionRe.MatchString(o.version):
+		return o, fmt.Errorf("-k8s-version %q: use a minor release such as %s", o.version, k8sDefaultVersion)
+	case !k8sCIDRRe.MatchString(o.podCIDR):
+		return o, fmt.Errorf("-pod-cidr %q is not a CIDR", o.podCIDR)
+	case o.cloudInitFile == "-":
+		return o, errors.New("-cloud-init: stdin cannot be used, every node reads the template")
+	}
+	// The nodes are launched directly, so their names are checked here
+	// rather than by launchOptions.finish.
+	controlPlane, workers := o.nodeNames()
+	policy := loadNamingPolicy(configValue)
+	for _, name := range append([]string{controlPlane}, workers...) {
+		if err := policy.check(name); err != nil {
+			return o, err
+		}
+	}
+	if o.kubeconfig == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return o, err
+		}
+		o.kubeconfig = filepath.Join(home, ".kube", "passgo-"+o.prefix+".yaml")
+	}
+	return o, nil
+}
+
+// nodeNames returns the control plane name and the worker names.
+func (o k8sLabOptions) nodeNames() (string, []string) {
+	workers := make([]string, o.workers)
+	for i := range workers {
+		workers[i] = fmt.Sprintf("%s-w%d", o.prefix, i+1)
+	}
+	return o.prefix + "-cp", workers
+}
+
+// nodeResources is what each node is launched with.
+func (o k8sLabOptions) nodeResources() resources {
+	return resources{o.cpus, o.memoryMB, o.diskGB}
+}
+
+// k8sNodeCloudInit is the built-in node template: kernel modules and sysctls
+// for pod networking, containerd with the systemd cgroup driver, and the
+// kubelet, kubeadm and kubectl packages of one minor release, held.
+func k8sNodeCloudInit(version string) string {
+	repo := "https://pkgs.k8s.io/core:/stable:/" + version + "/deb/"
+	return `#cloud-config
+package_update: true
+packages: [apt-transport-https, ca-certificates, curl, gpg, containerd]
+write_files:
+  - path: /etc/modules-load.d/k8s.conf
+    content: |
+      overlay
+      br_netfilter
+  - path: /etc/sysctl.d/99-k8s.conf
+    content: |
+      net.bridge.bridge-nf-call-iptables = 1
+      net.bridge.bridge-nf-call-ip6tables = 1
+      net.ipv4.ip_forward = 1
+runcmd:
+  - modprobe overlay
+  - modprobe br_netfilter
+  - sysctl --system
+  - swapoff -a
+  - mkdir -p /etc/containerd /etc/apt/keyrings
+  - containerd config default | sed 's/SystemdCgroup = false/SystemdCgroup = true/' > /etc/containerd/config.toml
+  - systemctl restart containerd
+  - curl -fsSL ` + repo + `Release.key | gpg --dearmor -o /etc/apt/keyrings/kubernetes-apt-keyring.gpg
+  - echo 'deb [signed-by=/etc/apt/keyrings/kubernetes-apt-keyring.gpg] ` + repo + ` /' > /etc/apt/sources.list.d/kubernetes.list
+  - apt-get update
+  - apt-get install -y kubelet kubeadm kubectl
+  - apt-mark hold kubelet kubeadm kubectl
+`
+}
+
+// k8sControlPlaneScript initialises the cluster, installs the CNI plugin
+// and gives the default user a kubeconfig.
+func k8sControlPlaneScript(podCIDR, cniManifest string) string {
+	return strings.Join([]string{
+		"set -e",
+		"sudo kubeadm init --pod-network-cidr=" + podCIDR,
+		"sudo kubectl --kubeconfig /etc/kubernetes/admin.conf apply -f " + cniManifest,
+		"mkdir -p ~/.kube",
+		"sudo cp /etc/kubernetes/admin.conf ~/.kube/config",
+		`sudo chown "$(id -u):$(id -g)" ~/.kube/config`,
+	}, "\n")
+}
+
+// parseJoinCommand checks the output of `kubeadm token create
+// --print-join-command` and returns the join command.
+func parseJoinCommand(output string) (string, error) {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "kubeadm join ") && strings.Contains(line, "--token ") {
+			return line, nil
+		}
+	}
+	return "", errors.New("no kubeadm join command in the control plane output")
+}
+
+// k8sLabCommand implements `passgo k8s-lab` and returns the process exit code.
+func k8sLabCommand(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	o, err := parseK8sLabOptions(args, stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return o.out.fail(stderr, usageError{err})
+	}
+	controlPlane, workers := o.nodeNames()
+	progress := o.out.reporter(stderr)
+	// The whole cluster is checked up front rather than node by node, so a
+	// lab that does not fit is refused before anything is launched.
+	warn, err := checkQuota(loadResourceQuota(configValue), o.nodeResources().times(1+len(workers)))
+	if err != nil {
+		return o.out.fail(stderr, err)
+	}
+	if warn != "" {
+		progress.say(controlPlane, "warning", -1, "warning: %s", warn)
+	}
+	if err := runK8sLab(o, controlPlane, workers, progress); err != nil {
+		return o.out.fail(stderr, fmt.Errorf("%w (nodes launched so far are kept; remove them with: multipass delete --purge %s)",
+			err, strings.Join(append([]string{controlPlane}, workers...), " ")))
+	}
+	fmt.Fprintf(stdout, "%s\n", o.kubeconfig)
+	progress.say(controlPlane, "done", 100, "cluster ready; try: kubectl --kubeconfig %s get nodes", o.kubeconfig)
+	return exitOK
+}
+
+// runK8sLab launches the nodes, initialises the control plane, joins the
+// workers and writes the kubeconfig.
+func runK8sLab(o k8sLabOptions, controlPlane string, workers []string, progress progressReporter) error {
+	cloudInit := o.cloudInitFile
+	if cloudInit == "" {
+		path, cleanup, err := writeTempCloudInit([]byte(k8sNodeCloudInit(o.version)))
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		cloudInit = path
+	}
+
+	for _, name := range append([]string{controlPlane}, workers...) {
+		opts := launchOptions{name: name, release: o.release, cpus: o.cpus, memoryMB: o.memoryMB, diskGB: o.diskGB, cloudInitFile: cloudInit, quotaChecked: true}
+		if err := launchFromOptions(opts, nil, progress); err != nil {
+			return fmt.Errorf("launching %s: %w", name, err)
+		}
+		role := "worker"
+		if name == controlPlane {
+			role = "control plane"
+		}
+		if err := recordK8sNode(name, o.prefix, role, o.nodeResources()); err != nil {
+			progress.say(name, "warning", -1, "warning: could not record %s: %v", name, err)
+		}
+	}
+
+	progress.say(controlPlane, "kubeadm init", -1, "initialising the control plane on %s…", controlPlane)
+	if _, err := ExecInVM(controlPlane, "bash", "-c", k8sControlPlaneScript(o.podCIDR, o.cniManifest)); err != nil {
+		return fmt.Errorf("kubeadm init: %w", err)
+	}
+	if len(workers) > 0 {
+		out, err := ExecInVM(controlPlane, "sudo", "kubeadm", "token", "create", "--print-join-command")
+		if err != nil {
+			return fmt.Errorf("creating a join token: %w", err)
+		}
+		join, err := parseJoinCommand(out)
+		if err != nil {
+			return err
+		}
+		// The join command carries the token, so it goes through the
+		// environment, whose values are redacted from the log.
+		for _, w := range workers {
+			progress.say(w, "kubeadm join", -1, "joining %s…", w)
+			if _, err := ExecInVMWith(w, execOptions{Env: []string{"PASSGO_JOIN=" + join}}, "bash", "-c", "sudo $PASSGO_JOIN"); err != nil {
+				return fmt.Errorf("joining %s: %w", w, err)
+			}
+		}
+	}
+
+	kubeconfig, err := ExecInVM(controlPlane, "sudo", "cat", "/etc/kubernetes/admin.conf")
+	if err != nil {
+		return fmt.Errorf("reading the kubeconfig: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(o.kubeconfig), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(o.kubeconfig, []byte(kubeconfig+"\n"), 0o600)
+}
+
+// recordK8sNode notes a lab node's role and resources in the state file.
+func recordK8sNode(name, prefix, role string, r resources) error {
+	path, err := stateFilePath()
+	if err != nil {
+		return err
+	}
+	_, err = updateState(path, func(st *appState) bool {
+		st.setAllocation(name, r)
+		st.addNote(name, fmt.Sprintf("k8s-lab %s: %s", prefix, role))
+		return true
+	})
+	return err
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseK8sLabOptions(t *testing.T) {
+	o, err := parseK8sLabOptions([]string{"--name", "lab", "--workers", "3", "--kubeconfig", "/tmp/kc"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cp, workers := o.nodeNames()
+	if cp != "lab-cp" || strings.Join(workers, " ") != "lab-w1 lab-w2 lab-w3" || o.kubeconfig != "/tmp/kc" {
+		t.Fatalf("unexpected options: %+v (nodes %s %v)", o, cp, workers)
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	o, err = parseK8sLabOptions(nil, io.Discard)
+	if err != nil || o.version != k8sDefaultVersion || filepath.Base(o.kubeconfig) != "passgo-k8s.yaml" {
+		t.Fatalf("defaults: got %+v, %v", o, err)
+	}
+
+	for _, args := range [][]string{
+		{"--workers", "-1"},
+		{"--memory", "1024"},
+		{"--k8s-version", "1.31"},
+		{"--pod-cidr", "10.244.0.0"},
+		{"--name", "bad_name"},
+		{"--cloud-init", "-"},
+		{"extra"},
+	} {
+		if _, err := parseK8sLabOptions(args, io.Discard); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
+
+func TestK8sNodeCloudInit(t *testing.T) {
+	ci := k8sNodeCloudInit("v1.30")
+	if !strings.HasPrefix(ci, "#cloud-config\n") {
+		t.Fatal("node template lacks the #cloud-config header")
+	}
+	if !strings.Contains(ci, "pkgs.k8s.io/core:/stable:/v1.30/deb/") || !strings.Contains(ci, "apt-get install -y kubelet kubeadm kubectl") {
+		t.Fatalf("node template does not install v1.30 packages:\n%s", ci)
+	}
+}
+
+func TestParseJoinCommand(t *testing.T) {
+	out := "W1015 warning: something\nkubeadm join 10.0.0.5:6443 --token abc.def --discovery-token-ca-cert-hash sha256:123 \n"
+	got, err := parseJoinCommand(out)
+	if err != nil || got != "kubeadm join 10.0.0.5:6443 --token abc.def --discovery-token-ca-cert-hash sha256:123" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+	if _, err := parseJoinCommand("error: token expired"); err == nil {
+		t.Fatal("expected an error without a join command")
+	}
+}
+
+func TestK8sLabFollowsNamingPolicy(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile(filepath.Join(dir, ".config"), []byte("name-prefix=dev-\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseK8sLabOptions([]string{"--name", "lab"}, io.Discard); err == nil {
+		t.Error("lab-cp does not start with dev- and should be refused")
+	}
+	if _, err := parseK8sLabOptions([]string{"--name", "dev-lab"}, io.Discard); err != nil {
+		t.Errorf("dev-lab nodes follow the policy: %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+// cmd_launch.go - `passgo launch` and launch flags shared with `passgo run`
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// launchOptions are the VM settings shared by the launch-style subcommands.
+type launchOptions struct {
+	name          string
+	release       string
+	image         string // file:// or http(s):// image, overrides release
+	cpus          int
+	memoryMB      int
+	diskGB        int
+	cloudInitFile string // "-" reads user-data from stdin
+	mountProject  bool   // mount the current workspace at projectTarget
+	projectDir    string // set by finish when mountProject is on
+	projectTarget string
+	quotaChecked  bool // the caller already checked the quota for this launch
+}
+
+// register adds the launch flags to fs.
+func (o *launchOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.name, "name", "", "instance name (default: random "+VMNamePrefix+"xxxx)")
+	fs.StringVar(&o.release, "release", DefaultUbuntuRelease, "Ubuntu release or image")
+	fs.StringVar(&o.image, "image", "", "custom image to launch, as file:///path/image.img or an https:// URL (overrides -release)")
+	fs.IntVar(&o.cpus, "cpus", DefaultCPUCores, "number of vCPUs")
+	fs.IntVar(&o.memoryMB, "memory", DefaultRAMMB, "memory in MB")
+	fs.IntVar(&o.diskGB, "disk", DefaultDiskGB, "disk size in GB")
+	fs.StringVar(&o.cloudInitFile, "cloud-init", "", "cloud-init user-data file, or - to read it from stdin")
+	fs.BoolVar(&o.mountProject, "mount-project", false, "mount the current project workspace at project-mount-target (default "+defaultProjectMountTarget+")")
+}
+
+// finish validates the parsed flags and fills in a name from the naming
+// policy (see naming.go) if needed.
+func (o *launchOptions) finish() error {
+	if o.cpus < MinCPUCores || o.memoryMB < MinRAMMB || o.diskGB < MinDiskGB {
+		return fmt.Errorf("resources below minimum (%d CPU, %dMB, %dGB)", MinCPUCores, MinRAMMB, MinDiskGB)
+	}
+	if o.image != "" {
+		if !isImageURL(o.image) {
+			return fmt.Errorf("-image %q: use file:///absolute/path or an http(s):// URL", o.image)
+		}
+		o.release = o.image
+	}
+	if isImageURL(o.release) {
+		if err := validateImageURL(o.release); err != nil {
+			return err
+		}
+	}
+	if o.mountProject {
+		dir, ok := currentWorkspace()
+		if !ok {
+			return errors.New("-mount-project: the current directory is not a project workspace (no .git or project file)")
+		}
+		o.projectDir, o.projectTarget = dir, projectMountTarget(configValue)
+	}
+	policy := loadNamingPolicy(configValue)
+	if o.name == "" {
+		o.name = policy.generate(func() string { return VMNamePrefix + randomString(VMNameRandomLength) }, func(string) bool { return false })
+	}
+	return policy.check(o.name)
+}
+
+// writeTempCloudInit writes user-data to a private (0600) temp file. The
+// returned cleanup removes it; call it once the launch has finished.
+func writeTempCloudInit(data []byte) (string, func(), error) {
+	f, err := os.CreateTemp("", ownedTempPattern("passgo-userdata-*.yaml"))
+	if err != nil {
+		return "", func() {}, fmt.Errorf("failed to create temp cloud-init file: %w", err)
+	}
+	tempArtifacts.add(f.Name())
+	cleanup := func() { tempArtifacts.remove(f.Name()) }
+	if err := f.Chmod(0o600); err != nil {
+		f.Close()
+		cleanup()
+		return "", func() {}, err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		cleanup()
+		return "", func() {}, err
+	}
+	if err := f.Close(); err != nil {
+		cleanup()
+		return "", func() {}, err
+	}
+	return f.Name(), cleanup, nil
+}
+
+// resolveCloudInit turns "-" into a temp file holding stdin's contents.
+// Other values are returned unchanged with a no-op cleanup.
+func resolveCloudInit(path string, stdin io.Reader) (string, func(), error) {
+	if path != "-" {
+		return path, func() {}, nil
+	}
+	if stdin == nil {
+		return "", func() {}, errors.New("--cloud-init - needs user-data on stdin")
+	}
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", func() {}, fmt.Errorf("failed to read cloud-init from stdin: %w", err)
+	}
+	if len(data) == 0 {
+		return "", func() {}, errors.New("no cloud-init user-data on stdin")
+	}
+	return writeTempCloudInit(data)
+}
+
+// launchFromOptions launches the VM described by o, reporting launch phases
+// to progress. Any stdin user-data file is removed once multipass returns.
+func launchFromOptions(o launchOptions, stdin io.Reader, progress progressReporter) error {
+	if !o.quotaChecked {
+		warn, err := checkQuota(loadResourceQuota(configValue), resources{o.cpus, o.memoryMB, o.diskGB})
+		if err != nil {
+			return err
+		}
+		if warn != "" {
+			progress.say(o.name, "warning", -1, "warning: %s", warn)
+		}
+	}
+	cloudInit, cleanup, err := resolveCloudInit(o.cloudInitFile, stdin)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	cloudInit, cleanupRendered, err := prepareCloudInit(cloudInit)
+	if err != nil {
+		return err
+	}
+	defer cleanupRendered()
+
+	if warn := releaseWarning(o.release, time.Now()); warn != "" {
+		progress.say(o.name, "warning", -1, "warning: %s", warn)
+	}
+	warn, err := checkImageArch(o.release, hostArch(), FindImages)
+	if err != nil {
+		return err
+	}
+	if warn != "" {
+		progress.say(o.name, "warning", -1, "warning: %s", warn)
+	}
+	if warn := checkImagePin(o.release, loadImagePins(), FindImages); warn != "" {
+		progress.say(o.name, "warning", -1, "warning: %s", warn)
+	}
+	progress.say(o.name, "launch", 0, "launching %s (%s)…", o.name, o.release)
+	lastStep, lastPercent := -1, -1
+	args := withProjectMount(launchVMArgs(o.name, o.release, o.cpus, o.memoryMB, o.diskGB, cloudInit, nil), o.projectDir, o.projectTarget)
+	err = launchWithHooks(o.name, args, func(line string) {
+		phase, ok := parseLaunchPhase(line)
+		if !ok {
+			return
+		}
+		// Text output shows each phase once; JSON also follows the download.
+		pct := launchPercent(phase)
+		if phase.Step != lastStep || progress.json && pct != lastPercent {
+			lastStep, lastPercent = phase.Step, pct
+			progress.say(o.name, phase.Label, pct, "[%d/%d] %s…", phase.Step+1, len(launchPhaseLabels), phase.Label)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if o.projectDir != "" {
+		progress.say(o.name, "mount", -1, "mounted %s at %s", o.projectDir, o.projectTarget)
+		if err := recordProjectMount(o.name, o.projectDir, o.projectTarget); err != nil {
+			progress.say(o.name, "warning", -1, "warning: could not record the project mount: %v", err)
+		}
+	}
+	if err := recordLaunchOwner(o.name); err != nil {
+		progress.say(o.name, "warning", -1, "warning: could not record the owner: %v", err)
+	}
+	if _, ok := pinKey(o.release); ok {
+		pin, err := readImagePin(o.name, o.release, FindImages, time.Now())
+		if err == nil {
+			err = recordImagePin(pin)
+		}
+		if err != nil {
+			progress.say(o.name, "warning", -1, "warning: could not record the image: %v", err)
+		} else {
+			progress.say(o.name, "image", -1, "image %s", pin.describe())
+		}
+	}
+	progress.say(o.name, "done", 100, "launched %s", o.name)
+	return nil
+}
+
+const launchUsage = `Usage: passgo launch [flags]
+
+Launches a VM and prints its name on stdout. User-data can be piped in:
+
+  generate-userdata | passgo launch --cloud-init -
+
+Flags:
+`
+
+// launchCommand implements `passgo launch` and returns the process exit code.
+func launchCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var o launchOptions
+	var out cliOutput
+	fs := flag.NewFlagSet("launch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, launchUsage)
+		fs.PrintDefaults()
+	}
+	o.register(fs)
+	out.register(fs, "launch")
+	out.registerProgress(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		return out.fail(stderr, usageErrorf("unexpected arguments %v", fs.Args()))
+	}
+	if err := o.finish(); err != nil {
+		return out.fail(stderr, usageError{err})
+	}
+	if err := launchFromOptions(o, stdin, out.reporter(stderr)); err != nil {
+		return out.fail(stderr, err)
+	}
+	fmt.Fprintln(stdout, o.name)
+	return exitOK
+}
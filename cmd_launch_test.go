@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestResolveCloudInitFromStdin(t *testing.T) {
+	path, cleanup, err := resolveCloudInit("-", strings.NewReader("#cloud-config\npackages: [git]\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.HasPrefix(string(data), "#cloud-config") {
+		t.Fatalf("unexpected temp file contents %q: %v", data, err)
+	}
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+			t.Fatalf("expected 0600 temp file, got %v %v", info.Mode().Perm(), err)
+		}
+	}
+	cleanup()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected temp file to be removed, got %v", err)
+	}
+
+	if got, _, err := resolveCloudInit("user-data.yaml", nil); err != nil || got != "user-data.yaml" {
+		t.Fatalf("expected file path to pass through, got %q %v", got, err)
+	}
+	if _, _, err := resolveCloudInit("-", strings.NewReader("")); err == nil {
+		t.Fatalf("expected error for empty stdin")
+	}
+}
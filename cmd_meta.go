@@ -0,0 +1,322 @@
+// cmd_meta.go - `passgo meta export|import`: move tags, notes, presets and snippets between machines
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// metaFormat is the version of the export file; import refuses newer ones.
+const metaFormat = 1
+
+// metaBundle is the export file.
+type metaBundle struct {
+	Format   int               `json:"passgo_metadata"`
+	Exported time.Time         `json:"exported"`
+	VMs      map[string]metaVM `json:"vms,omitempty"` // by VM name
+	Presets  map[string]string `json:"presets,omitempty"`
+	Snippets []string          `json:"snippets,omitempty"` // exec-snippet values
+}
+
+// metaVM is what is exported per VM. TTLs, allocations and project mounts
+// only make sense on the machine that recorded them.
+type metaVM struct {
+	Tags  []string `json:"tags,omitempty"`
+	Notes string   `json:"notes,omitempty"`
+}
+
+// metaPresetKeys are the .config launch defaults carried in presets.
+var metaPresetKeys = []string{"default-release", "default-cpus", "default-memory", "default-disk", "default-cloud-init"}
+
+const metaUsage = `Usage: passgo meta export [-o FILE]
+       passgo meta import [-dry-run] FILE
+
+export writes the tags and notes passgo recorded about VMs, the launch
+presets and the exec snippets as one JSON file (stdout by default).
+import merges such a file into ~/.passgo: tags are added, notes appended,
+and presets and snippets missing from ~/.passgo/.config are appended to it.
+Nothing already set is overwritten. Use - to read the file from stdin.
+
+Flags:
+`
+
+// buildMetaBundle collects the exportable parts of st and the config.
+func buildMetaBundle(st appState, lookup func(string) (string, bool), snippets []string, now time.Time) metaBundle {
+	b := metaBundle{Format: metaFormat, Exported: now.UTC(), VMs: make(map[string]metaVM), Presets: make(map[string]string), Snippets: snippets}
+	for name, meta := range st.VMs {
+		if len(meta.Tags) > 0 || meta.Notes != "" {
+			b.VMs[name] = metaVM{Tags: meta.Tags, Notes: meta.Notes}
+		}
+	}
+	for _, key := range metaPresetKeys {
+		if v, ok := lookup(key); ok && strings.TrimSpace(v) != "" {
+			b.Presets[key] = strings.TrimSpace(v)
+		}
+	}
+	return b
+}
+
+// parseMetaBundle reads an export file and checks its snippets.
+func parseMetaBundle(data []byte) (metaBundle, error) {
+	var b metaBundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return b, fmt.Errorf("not a passgo metadata file: %w", err)
+	}
+	switch {
+	case b.Format == 0:
+		return b, errors.New("not a passgo metadata file: passgo_metadata is missing")
+	case b.Format > metaFormat:
+		return b, fmt.Errorf("metadata format %d is newer than this passgo supports (%d); upgrade passgo", b.Format, metaFormat)
+	}
+	for key := range b.Presets {
+		if !slices.Contains(metaPresetKeys, key) {
+			return b, fmt.Errorf("unknown preset %q", key)
+		}
+	}
+	for _, s := range b.Snippets {
+		if _, err := parseExecSnippet(s); err != nil {
+			return b, err
+		}
+	}
+	return b, nil
+}
+
+// metaChanges is what an import adds.
+type metaChanges struct {
+	vms      []string      // VMs whose tags or notes changed
+	config   []configEntry // lines to append to .config
+	skipped  []string      // presets already set locally
+	snippets int
+}
+
+// mergeMetaBundle merges b into st and returns what changed and which
+// .config lines to add, given the entries already in that file.
+func mergeMetaBundle(st appState, b metaBundle, existing []configEntry) metaChanges {
+	var c metaChanges
+	for name, in := range b.VMs {
+		meta := st.VMs[name]
+		changed := false
+		for _, tag := range in.Tags {
+			if !slices.Contains(meta.Tags, tag) {
+				meta.Tags = append(meta.Tags, tag)
+				changed = true
+			}
+		}
+		if in.Notes != "" && !strings.Contains(meta.Notes, in.Notes) {
+			if meta.Notes != "" {
+				meta.Notes += "\n"
+			}
+			meta.Notes += in.Notes
+			changed = true
+		}
+		if changed {
+			st.VMs[name] = meta
+			c.vms = append(c.vms, name)
+		}
+	}
+	sort.Strings(c.vms)
+
+	set := make(map[string]bool)
+	snippets := make(map[string]bool)
+	for _, e := range existing {
+		set[e.key] = true
+		if e.key == "exec-snippet" {
+			snippets[e.value] = true
+		}
+	}
+	for _, key := range metaPresetKeys {
+		v, ok := b.Presets[key]
+		switch {
+		case !ok:
+		case set[key]:
+			c.skipped = append(c.skipped, key)
+		default:
+			c.config = append(c.config, configEntry{key, v})
+		}
+	}
+	for _, s := range b.Snippets {
+		if !snippets[s] {
+			snippets[s] = true
+			c.config = append(c.config, configEntry{"exec-snippet", s})
+			c.snippets++
+		}
+	}
+	return c
+}
+
+// appendConfigEntries appends entries to the .config at path, creating it
+// if needed.
+func appendConfigEntries(path string, entries []configEntry, now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600) // #nosec G304 -- path under the user's config dir
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n# imported by passgo meta import, %s\n", now.Format("2006-01-02 15:04"))
+	for _, e := range entries {
+		fmt.Fprintf(&b, "%s=%s\n", e.key, e.value)
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func metaCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	out := cliOutput{command: "meta"}
+	if len(args) == 0 {
+		fmt.Fprint(stderr, metaUsage)
+		return exitUsage
+	}
+	switch args[0] {
+	case "export":
+		return metaExport(args[1:], stdout, stderr)
+	case "import":
+		return metaImport(args[1:], stdin, stdout, stderr)
+	case "-h", "-help", "--help":
+		fmt.Fprint(stderr, metaUsage)
+		return exitOK
+	}
+	return out.fail(stderr, usageErrorf("unknown action %q: want export or import", args[0]))
+}
+
+// newMetaFlagSet returns the flag set of a meta action.
+func newMetaFlagSet(action string, out *cliOutput, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("meta "+action, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, metaUsage)
+		fs.PrintDefaults()
+	}
+	out.register(fs, "meta "+action)
+	return fs
+}
+
+func metaExport(args []string, stdout, stderr io.Writer) int {
+	var out cliOutput
+	fs := newMetaFlagSet("export", &out, stderr)
+	file := fs.String("o", "", "write to FILE instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return out.fail(stderr, usageError{err})
+	}
+	if fs.NArg() > 0 {
+		return out.fail(stderr, usageErrorf("unexpected arguments %v", fs.Args()))
+	}
+	path, err := stateFilePath()
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+	st, err := loadState(path)
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+	data, err := json.MarshalIndent(buildMetaBundle(st, configValue, configList("exec-snippet"), time.Now()), "", "  ")
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+	data = append(data, '\n')
+	if *file == "" {
+		_, _ = stdout.Write(data)
+		return exitOK
+	}
+	if err := os.WriteFile(*file, data, 0o600); err != nil {
+		return out.fail(stderr, err)
+	}
+	out.reporter(stderr).say("", "done", 100, "wrote %s", *file)
+	return exitOK
+}
+
+func metaImport(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var out cliOutput
+	fs := newMetaFlagSet("import", &out, stderr)
+	dryRun := fs.Bool("dry-run", false, "print what would change without writing anything")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return out.fail(stderr, usageError{err})
+	}
+	if fs.NArg() != 1 {
+		return out.fail(stderr, usageErrorf("want one FILE to import (or - for stdin)"))
+	}
+	var data []byte
+	var err error
+	if src := fs.Arg(0); src == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(src) // #nosec G304 -- file named by the user
+	}
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+	b, err := parseMetaBundle(data)
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+
+	statePath, err := stateFilePath()
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+	st, err := loadState(statePath)
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+	dir, err := userConfigDir()
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+	configPath := filepath.Join(dir, ".config")
+	existing, err := readConfigEntriesFromFile(configPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return out.fail(stderr, err)
+	}
+	c := mergeMetaBundle(st, b, existing)
+
+	if !*dryRun {
+		if len(c.vms) > 0 {
+			if err := saveState(statePath, st); err != nil {
+				return out.fail(stderr, err)
+			}
+		}
+		if len(c.config) > 0 {
+			if err := appendConfigEntries(configPath, c.config, time.Now()); err != nil {
+				return out.fail(stderr, err)
+			}
+		}
+	}
+	verb := "imported"
+	if *dryRun {
+		verb = "would import"
+	}
+	fmt.Fprintf(stdout, "%s tags and notes for %d VM(s), %d preset(s) and %d snippet(s)\n",
+		verb, len(c.vms), len(c.config)-c.snippets, c.snippets)
+	if !out.quiet && !out.porcelain {
+		for _, name := range c.vms {
+			fmt.Fprintf(stdout, "  vm %s\n", name)
+		}
+		for _, e := range c.config {
+			fmt.Fprintf(stdout, "  %s=%s\n", e.key, e.value)
+		}
+		for _, key := range c.skipped {
+			fmt.Fprintf(stdout, "  kept local %s\n", key)
+		}
+	}
+	return exitOK
+}
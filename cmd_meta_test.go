@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetaBundleRoundTrip(t *testing.T) {
+	st := newAppState()
+	st.VMs["web"] = vmMeta{Tags: []string{"prod"}, Notes: "nginx", CPUs: 2}
+	st.VMs["bare"] = vmMeta{CPUs: 4}
+	lookup := func(key string) (string, bool) {
+		if key == "default-cpus" {
+			return " 4 ", true
+		}
+		return "", false
+	}
+	b := buildMetaBundle(st, lookup, []string{"Tests | | make test"}, time.Now())
+	if _, ok := b.VMs["bare"]; ok || len(b.VMs) != 1 {
+		t.Errorf("VMs = %+v, want only web", b.VMs)
+	}
+	if !reflect.DeepEqual(b.Presets, map[string]string{"default-cpus": "4"}) {
+		t.Errorf("Presets = %v", b.Presets)
+	}
+	data, _ := json.Marshal(b)
+	got, err := parseMetaBundle(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.VMs, b.VMs) || !reflect.DeepEqual(got.Snippets, b.Snippets) {
+		t.Errorf("round trip = %+v, want %+v", got, b)
+	}
+}
+
+func TestParseMetaBundleRejects(t *testing.T) {
+	for _, in := range []string{
+		`{}`,
+		`{"passgo_metadata": 99}`,
+		`{"passgo_metadata": 1, "presets": {"ssh-key": "x"}}`,
+		`{"passgo_metadata": 1, "snippets": ["no command"]}`,
+		`not json`,
+	} {
+		if _, err := parseMetaBundle([]byte(in)); err == nil {
+			t.Errorf("parseMetaBundle(%s) should fail", in)
+		}
+	}
+}
+
+func TestMergeMetaBundle(t *testing.T) {
+	st := newAppState()
+	st.VMs["web"] = vmMeta{Tags: []string{"prod"}, Notes: "nginx"}
+	b := metaBundle{
+		Format: metaFormat,
+		VMs: map[string]metaVM{
+			"web": {Tags: []string{"prod", "eu"}, Notes: "nginx"},
+			"db":  {Notes: "postgres"},
+			"api": {Tags: []string{"prod"}},
+		},
+		Presets:  map[string]string{"default-cpus": "4", "default-release": "24.04"},
+		Snippets: []string{"Tests | | make test", "Logs | | journalctl -f"},
+	}
+	existing := []configEntry{{"default-release", "22.04"}, {"exec-snippet", "Tests | | make test"}}
+	c := mergeMetaBundle(st, b, existing)
+
+	if want := []string{"api", "db", "web"}; !reflect.DeepEqual(c.vms, want) {
+		t.Errorf("changed VMs = %v, want %v", c.vms, want)
+	}
+	if got := st.VMs["web"]; !reflect.DeepEqual(got.Tags, []string{"prod", "eu"}) || got.Notes != "nginx" {
+		t.Errorf("web = %+v", got)
+	}
+	wantConfig := []configEntry{{"default-cpus", "4"}, {"exec-snippet", "Logs | | journalctl -f"}}
+	if !reflect.DeepEqual(c.config, wantConfig) || c.snippets != 1 {
+		t.Errorf("config = %v (%d snippets), want %v", c.config, c.snippets, wantConfig)
+	}
+	if !reflect.DeepEqual(c.skipped, []string{"default-release"}) {
+		t.Errorf("skipped = %v", c.skipped)
+	}
+	if again := mergeMetaBundle(st, b, append(existing, c.config...)); len(again.vms) != 0 || len(again.config) != 0 {
+		t.Errorf("second import changed %+v", again)
+	}
+}
+
+func TestAppendConfigEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cfg", ".config")
+	if err := appendConfigEntries(path, []configEntry{{"default-cpus", "4"}, {"exec-snippet", "Tests | | make test"}}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := readConfigEntriesFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[1].key != "exec-snippet" || !strings.Contains(entries[1].value, "make test") {
+		t.Errorf("entries = %v", entries)
+	}
+}
@@ -0,0 +1,526 @@
+// cmd_repl.go - `passgo repl`: newline-delimited commands, interactive or from a script
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const replUsage = `Usage: passgo repl [flags] [script]
+
+Runs passgo commands one per line, from a script file or typed at the
+passgo> prompt. A script stops at the first failing command.
+
+  set NAME value...             set a variable, used as $NAME or ${NAME}
+  launch VM [cpus [memMB [diskGB]]] [release=R] [cloud-init=FILE]
+  exec VM command [args...]     output is also kept in $output
+  snapshot VM NAME [comment...] restore VM NAME
+  start VM...  stop VM...  delete VM...  (delete purges)
+  echo text...  sleep DURATION
+  if [not] exists VM | running VM | failed | A == B | A != B
+  else
+  end
+
+Lines starting with # are comments. Quote arguments with spaces.
+delete and restore ask first (see confirm in .config); without a terminal
+to answer they fail unless -yes is given.
+
+Flags:
+`
+
+// replVarNameRe matches the names `set` accepts.
+var replVarNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// errReplSyntax marks mistakes in the script itself, as opposed to
+// commands that failed.
+var errReplSyntax = errors.New("syntax error")
+
+// replBlock is one open if/else. A line runs when every enclosing block is
+// on its taken branch.
+type replBlock struct {
+	parentActive bool
+	cond         bool
+	inElse       bool
+}
+
+// replSession interprets repl lines. Variables persist across lines and
+// lookup reports whether an instance exists and its state.
+type replSession struct {
+	vars   map[string]string
+	blocks []replBlock
+	failed bool // the last command failed
+	dryRun bool
+	// confirm says which commands ask first; answers is where replies are
+	// read, nil when there is no terminal to ask
+	confirm confirmLevel
+	answers *bufio.Scanner
+	stdout  io.Writer
+	stderr  io.Writer
+	out     cliOutput
+	lookup  func(name string) (VMInfo, bool)
+}
+
+func newReplSession(vars map[string]string, stdout, stderr io.Writer) *replSession {
+	if vars == nil {
+		vars = make(map[string]string)
+	}
+	return &replSession{vars: vars, stdout: stdout, stderr: stderr, out: cliOutput{command: "repl"}, lookup: lookupVM}
+}
+
+// lookupVM asks multipass for name's info.
+func lookupVM(name string) (VMInfo, bool) {
+	info, err := GetVMInfo(name)
+	if err != nil {
+		return VMInfo{}, false
+	}
+	return parseVMInfo(info), true
+}
+
+// active reports whether lines at the current nesting run.
+func (s *replSession) active() bool {
+	if len(s.blocks) == 0 {
+		return true
+	}
+	b := s.blocks[len(s.blocks)-1]
+	return b.parentActive && b.cond != b.inElse
+}
+
+// splitReplLine splits a line into words. Double quotes group words and
+// expand variables; single quotes group words literally.
+func splitReplLine(line string, vars map[string]string) ([]string, error) {
+	var words []string
+	var cur strings.Builder
+	inWord := false
+	var quote rune
+	rs := []rune(line)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case quote == '\'' && r != '\'':
+			cur.WriteRune(r)
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\''):
+			quote, inWord = r, true
+		case quote == 0 && (r == ' ' || r == '\t'):
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		case r == '$':
+			name, n := replVarName(rs[i+1:])
+			if n == 0 {
+				cur.WriteRune(r)
+				inWord = true
+				continue
+			}
+			if name == "$" {
+				cur.WriteRune('$')
+			} else {
+				v, ok := vars[name]
+				if !ok {
+					return nil, fmt.Errorf("undefined variable $%s", name)
+				}
+				cur.WriteString(v)
+			}
+			i += n
+			inWord = true
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("%w: unterminated %c quote", errReplSyntax, quote)
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words, nil
+}
+
+// replVarName reads a variable reference after '$': NAME, {NAME} or $.
+// It returns the name and how many runes it used (0 when there is none).
+func replVarName(rs []rune) (string, int) {
+	if len(rs) == 0 {
+		return "", 0
+	}
+	if rs[0] == '$' {
+		return "$", 1
+	}
+	if rs[0] == '{' {
+		for j := 1; j < len(rs); j++ {
+			if rs[j] == '}' {
+				if j == 1 {
+					return "", 0
+				}
+				return string(rs[1:j]), j + 1
+			}
+		}
+		return "", 0
+	}
+	j := 0
+	for j < len(rs) && (rs[j] == '_' || rs[j] >= 'a' && rs[j] <= 'z' || rs[j] >= 'A' && rs[j] <= 'Z' || rs[j] >= '0' && rs[j] <= '9') {
+		j++
+	}
+	return string(rs[:j]), j
+}
+
+// execLine runs one line. Errors wrapping errReplSyntax are mistakes in
+// the script itself; others are failed commands.
+func (s *replSession) execLine(line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	verb := strings.Fields(line)[0]
+	switch verb {
+	case "if":
+		b := replBlock{parentActive: s.active()}
+		if b.parentActive {
+			words, err := splitReplLine(line, s.vars)
+			if err != nil {
+				return err
+			}
+			if b.cond, err = s.evalCondition(words[1:]); err != nil {
+				return err
+			}
+		}
+		s.blocks = append(s.blocks, b)
+		return nil
+	case "else", "end":
+		if line != verb {
+			return fmt.Errorf("%w: %s takes no arguments", errReplSyntax, verb)
+		}
+		if len(s.blocks) == 0 {
+			return fmt.Errorf("%w: %s without if", errReplSyntax, verb)
+		}
+		top := &s.blocks[len(s.blocks)-1]
+		if verb == "end" {
+			s.blocks = s.blocks[:len(s.blocks)-1]
+		} else if top.inElse {
+			return fmt.Errorf("%w: second else", errReplSyntax)
+		} else {
+			top.inElse = true
+		}
+		return nil
+	}
+	if !s.active() {
+		return nil
+	}
+	words, err := splitReplLine(line, s.vars)
+	if err != nil {
+		return err
+	}
+	v, ok := replVerbs[verb]
+	if !ok {
+		return fmt.Errorf("%w: unknown command %q", errReplSyntax, verb)
+	}
+	args := words[1:]
+	if len(args) < v.min || v.max >= 0 && len(args) > v.max {
+		return fmt.Errorf("%w: usage: %s", errReplSyntax, v.usage)
+	}
+	if s.dryRun && v.mutates {
+		fmt.Fprintf(s.stdout, "would run: %s\n", strings.Join(words, " "))
+		s.failed = false
+		return nil
+	}
+	if v.mutates && s.confirm.asks(replDestructive[verb]) {
+		if err := s.confirmLine(words); err != nil {
+			s.failed = true
+			return err
+		}
+	}
+	err = v.run(s, args)
+	s.failed = err != nil
+	return err
+}
+
+// replDestructive lists the commands the destructive confirm level asks
+// about.
+var replDestructive = map[string]bool{"delete": true, "restore": true}
+
+// confirmLine asks before running words. With no terminal to answer it
+// refuses, since nobody is there to say yes.
+func (s *replSession) confirmLine(words []string) error {
+	line := strings.Join(words, " ")
+	if s.answers == nil {
+		return fmt.Errorf("%s: needs confirmation; pass -yes (or set confirm: none in .config) to run it unattended", line)
+	}
+	fmt.Fprintf(s.stderr, "Run %q? [y/N] ", line)
+	if s.answers.Scan() {
+		switch strings.ToLower(strings.TrimSpace(s.answers.Text())) {
+		case "y", "yes":
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: not confirmed", line)
+}
+
+// evalCondition evaluates the words after `if`.
+func (s *replSession) evalCondition(words []string) (bool, error) {
+	negate := len(words) > 0 && words[0] == "not"
+	if negate {
+		words = words[1:]
+	}
+	var result bool
+	switch {
+	case len(words) == 2 && words[0] == "exists":
+		_, result = s.lookup(words[1])
+	case len(words) == 2 && words[0] == "running":
+		vm, ok := s.lookup(words[1])
+		result = ok && vm.State == "Running"
+	case len(words) == 1 && words[0] == "failed":
+		result = s.failed
+	case len(words) == 3 && (words[1] == "==" || words[1] == "!="):
+		result = (words[0] == words[2]) == (words[1] == "==")
+	default:
+		return false, fmt.Errorf("%w: if [not] exists VM | running VM | failed | A == B | A != B", errReplSyntax)
+	}
+	return result != negate, nil
+}
+
+// replVerb is one repl command. mutates commands are only printed with -dry-run.
+type replVerb struct {
+	min, max int // argument counts; max -1 is unlimited
+	usage    string
+	mutates  bool
+	run      func(s *replSession, args []string) error
+}
+
+var replVerbs = map[string]replVerb{
+	"set": {1, -1, "set NAME value...", false, func(s *replSession, args []string) error {
+		if !replVarNameRe.MatchString(args[0]) {
+			return fmt.Errorf("%w: invalid variable name %q", errReplSyntax, args[0])
+		}
+		s.vars[args[0]] = strings.Join(args[1:], " ")
+		return nil
+	}},
+	"echo": {0, -1, "echo text...", false, func(s *replSession, args []string) error {
+		fmt.Fprintln(s.stdout, strings.Join(args, " "))
+		return nil
+	}},
+	"sleep": {1, 1, "sleep DURATION (e.g. 10s)", false, func(s *replSession, args []string) error {
+		d, err := time.ParseDuration(args[0])
+		if err != nil {
+			return fmt.Errorf("%w: sleep %q: %v", errReplSyntax, args[0], err)
+		}
+		time.Sleep(d)
+		return nil
+	}},
+	"launch": {1, 6, "launch VM [cpus [memMB [diskGB]]] [release=R] [cloud-init=FILE]", true, func(s *replSession, args []string) error {
+		o, err := replLaunchOptions(args, loadLaunchDefaults(configValue))
+		if err != nil {
+			return err
+		}
+		return launchFromOptions(o, nil, s.out.reporter(s.stderr))
+	}},
+	"exec": {2, -1, "exec VM command [args...]", true, func(s *replSession, args []string) error {
+		out, err := ExecInVM(args[0], args[1:]...)
+		s.vars["output"] = strings.TrimSpace(out)
+		if out != "" {
+			fmt.Fprint(s.stdout, out)
+			if !strings.HasSuffix(out, "\n") {
+				fmt.Fprintln(s.stdout)
+			}
+		}
+		return err
+	}},
+	"snapshot": {2, -1, "snapshot VM NAME [comment...]", true, func(s *replSession, args []string) error {
+		_, err := CreateSnapshot(args[0], args[1], strings.Join(args[2:], " "))
+		return err
+	}},
+	"restore": {2, 2, "restore VM NAME", true, func(s *replSession, args []string) error {
+		_, err := RestoreSnapshot(args[0], args[1])
+		return err
+	}},
+	"start":  {1, -1, "start VM...", true, replEach(StartVM)},
+	"stop":   {1, -1, "stop VM...", true, replEach(StopVM)},
+	"delete": {1, -1, "delete VM...", true, replEach(func(name string) (string, error) { return DeleteVM(name, true) })},
+}
+
+// replEach runs fn for every VM argument and joins the errors.
+func replEach(fn func(string) (string, error)) func(*replSession, []string) error {
+	return func(s *replSession, args []string) error {
+		var errs []error
+		for _, name := range args {
+			if _, err := fn(name); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// replLaunchOptions builds launch options from `launch` arguments, taking
+// anything not given from the launch defaults.
+func replLaunchOptions(args []string, d launchDefaults) (launchOptions, error) {
+	o := launchOptions{name: args[0], release: d.release, cpus: d.cpus, memoryMB: d.memoryMB, diskGB: d.diskGB, cloudInitFile: d.cloudInit}
+	sizes := []*int{&o.cpus, &o.memoryMB, &o.diskGB}
+	n := 0
+	for _, a := range args[1:] {
+		if key, val, ok := strings.Cut(a, "="); ok {
+			switch key {
+			case "release":
+				o.release = val
+			case "cloud-init":
+				o.cloudInitFile = val
+			default:
+				return o, fmt.Errorf("%w: launch: unknown option %q", errReplSyntax, key)
+			}
+			continue
+		}
+		if n == len(sizes) {
+			return o, fmt.Errorf("%w: launch: too many sizes", errReplSyntax)
+		}
+		v, err := strconv.Atoi(a)
+		if err != nil {
+			return o, fmt.Errorf("%w: launch: %q is not a number", errReplSyntax, a)
+		}
+		*sizes[n] = v
+		n++
+	}
+	if !instanceNameRe.MatchString(o.name) {
+		return o, fmt.Errorf("%w: invalid instance name %q", errReplSyntax, o.name)
+	}
+	return o, o.finish()
+}
+
+// runReplScript runs lines from r. Interactive sessions print a prompt and
+// report errors without stopping; scripts stop at the first error unless
+// keepGoing is set. It returns the process exit code.
+func runReplScript(s *replSession, r io.Reader, source string, interactive, keepGoing bool) int {
+	scanner := bufio.NewScanner(r)
+	if interactive {
+		s.answers = scanner
+	}
+	code := exitOK
+	lineNo := 0
+	prompt := func() {
+		if interactive {
+			p := "passgo> "
+			if len(s.blocks) > 0 {
+				p = "...> "
+			}
+			fmt.Fprint(s.stderr, p)
+		}
+	}
+	for prompt(); scanner.Scan(); prompt() {
+		lineNo++
+		err := s.execLine(scanner.Text())
+		if err == nil {
+			continue
+		}
+		if interactive {
+			fmt.Fprintf(s.stderr, "error: %v\n", err)
+			continue
+		}
+		code = s.out.fail(s.stderr, fmt.Errorf("%s:%d: %w", source, lineNo, err))
+		if code == exitUsage || !keepGoing {
+			return code
+		}
+	}
+	if interactive {
+		fmt.Fprintln(s.stderr)
+	}
+	if err := scanner.Err(); err != nil {
+		return s.out.fail(s.stderr, err)
+	}
+	if len(s.blocks) > 0 && !interactive {
+		return s.out.fail(s.stderr, fmt.Errorf("%s: %w: missing end", source, errReplSyntax))
+	}
+	return code
+}
+
+// replVars collects repeated -var NAME=value flags.
+type replVars map[string]string
+
+func (v replVars) String() string {
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func (v replVars) Set(s string) error {
+	name, val, ok := strings.Cut(s, "=")
+	if !ok || !replVarNameRe.MatchString(name) {
+		return fmt.Errorf("want NAME=value, got %q", s)
+	}
+	v[name] = val
+	return nil
+}
+
+// isTerminal reports whether r is an interactive terminal.
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// replCommand implements `passgo repl` and returns the process exit code.
+func replCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	vars := replVars{}
+	fs := flag.NewFlagSet("repl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, replUsage)
+		fs.PrintDefaults()
+	}
+	script := fs.String("script", "", "script file to run (same as the positional argument)")
+	keepGoing := fs.Bool("keep-going", false, "keep running a script after a command fails")
+	dryRun := fs.Bool("dry-run", false, "print the commands that would change VMs instead of running them")
+	yes := fs.Bool("yes", false, "run commands that need confirmation without asking")
+	fs.Var(vars, "var", "set a variable, NAME=value (repeatable)")
+	var out cliOutput
+	out.register(fs, "repl")
+	out.registerProgress(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	switch {
+	case fs.NArg() > 1, fs.NArg() == 1 && *script != "":
+		return out.fail(stderr, usageErrorf("give one script"))
+	case fs.NArg() == 1:
+		*script = fs.Arg(0)
+	}
+
+	s := newReplSession(vars, stdout, stderr)
+	s.dryRun = *dryRun
+	s.out = out
+	s.confirm = loadConfirmLevel(configValue)
+	if *yes {
+		s.confirm = confirmNone
+	}
+	if *script == "" || *script == "-" {
+		interactive := *script == "" && isTerminal(stdin)
+		return runReplScript(s, stdin, "stdin", interactive, *keepGoing)
+	}
+	f, err := os.Open(*script) // #nosec G304 -- script path given by the user
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+	defer f.Close()
+	if isTerminal(stdin) {
+		s.answers = bufio.NewScanner(stdin)
+	}
+	return runReplScript(s, f, *script, false, *keepGoing)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSplitReplLine(t *testing.T) {
+	vars := map[string]string{"vm": "web", "n": "2"}
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"exec $vm apt update", []string{"exec", "web", "apt", "update"}},
+		{`exec ${vm}-$n bash -c "echo $vm"`, []string{"exec", "web-2", "bash", "-c", "echo web"}},
+		{`echo '$vm costs $$5' $$`, []string{"echo", "$vm costs $$5", "$"}},
+		{`echo "" x`, []string{"echo", "", "x"}},
+	}
+	for _, tt := range tests {
+		got, err := splitReplLine(tt.line, vars)
+		if err != nil || strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("splitReplLine(%q) = %q, %v; want %q", tt.line, got, err, tt.want)
+		}
+	}
+	if _, err := splitReplLine("echo $missing", vars); err == nil {
+		t.Error("expected error for an undefined variable")
+	}
+	if _, err := splitReplLine(`echo "open`, vars); !errors.Is(err, errReplSyntax) {
+		t.Errorf("expected syntax error, got %v", err)
+	}
+}
+
+func TestReplConditionals(t *testing.T) {
+	var out strings.Builder
+	s := newReplSession(map[string]string{"who": "class"}, &out, &out)
+	s.lookup = func(name string) (VMInfo, bool) {
+		return VMInfo{Name: name, State: "Stopped"}, name == "web"
+	}
+	script := `
+# nested blocks; the skipped branch may use undefined variables
+if exists web
+  echo web exists
+  if running web
+    echo $undefined
+  else
+    echo web stopped
+  end
+else
+  echo no web
+end
+if not exists db
+  echo no db
+end
+if $who == class
+  echo hello $who
+end
+`
+	if code := runReplScript(s, strings.NewReader(script), "test", false, false); code != 0 {
+		t.Fatalf("exit code %d, output:\n%s", code, out.String())
+	}
+	want := "web exists\nweb stopped\nno db\nhello class\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestReplScriptErrors(t *testing.T) {
+	for _, script := range []string{"else", "if exists web\necho", "bogus", "if web", "set 1x y", "launch web two"} {
+		var out strings.Builder
+		s := newReplSession(nil, &out, &out)
+		s.lookup = func(string) (VMInfo, bool) { return VMInfo{}, false }
+		if code := runReplScript(s, strings.NewReader(script), "test", false, false); code != 2 {
+			t.Errorf("script %q: exit code %d, want 2 (%s)", script, code, out.String())
+		}
+	}
+}
+
+func TestReplDryRun(t *testing.T) {
+	var out strings.Builder
+	s := newReplSession(map[string]string{"vm": "web"}, &out, &out)
+	s.dryRun = true
+	if err := s.execLine("snapshot $vm base before class"); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "would run: snapshot web base before class\n" {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func TestReplLaunchOptions(t *testing.T) {
+	d := launchDefaults{release: "24.04", cpus: 1, memoryMB: 1024, diskGB: 8}
+	o, err := replLaunchOptions([]string{"foo", "2", "2048", "release=22.04"}, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.name != "foo" || o.cpus != 2 || o.memoryMB != 2048 || o.diskGB != 8 || o.release != "22.04" {
+		t.Errorf("got %+v", o)
+	}
+	for _, args := range [][]string{{"foo", "1", "2", "3", "4"}, {"foo", "x=1"}, {"-bad"}} {
+		if _, err := replLaunchOptions(args, d); err == nil {
+			t.Errorf("expected error for %q", args)
+		}
+	}
+}
+
+func TestReplConfirmation(t *testing.T) {
+	f := &fakeRunner{}
+	useFakeRunner(t, f)
+	var out strings.Builder
+
+	s := newReplSession(nil, &out, &out)
+	if err := s.execLine("delete web"); err == nil || !strings.Contains(err.Error(), "-yes") {
+		t.Fatalf("delete without a terminal: err = %v", err)
+	}
+	if len(f.calls) != 0 {
+		t.Fatalf("nothing should run unconfirmed: %v", f.calls)
+	}
+
+	s.answers = bufio.NewScanner(strings.NewReader("n\ny\n"))
+	if err := s.execLine("delete web"); err == nil || !strings.Contains(err.Error(), "not confirmed") {
+		t.Fatalf("answering n: err = %v", err)
+	}
+	if err := s.execLine("delete web"); err != nil || len(f.calls) != 1 {
+		t.Fatalf("answering y should delete: err %v, calls %v", err, f.calls)
+	}
+
+	s = newReplSession(nil, &out, &out)
+	s.confirm = confirmNone
+	if err := s.execLine("restore web base"); err != nil {
+		t.Errorf("-yes should skip the question: %v", err)
+	}
+	s.confirm = confirmDestructive
+	if err := s.execLine("stop web"); err != nil {
+		t.Errorf("stop is not destructive: %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+// cmd_report.go - `passgo report`: what each owner or tag holds on a shared host
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const reportUsage = `Usage: passgo report [-by owner|tag] [-since 24h] [-format table|csv|markdown|json] [-o FILE]
+
+Summarises the instances on this multipass host per owner or per tag: how
+many there are and are running, the vCPUs, memory and disk they hold, and
+how long they ran since -since. Uptime comes from the usage samples passgo
+takes every 5 minutes while it is open, and covers at most the last day.
+A VM with several tags counts towards each; the TOTAL row counts it once.
+
+Flags:
+`
+
+// reportGroup is one row of the report.
+type reportGroup struct {
+	name      string
+	instances int
+	running   int
+	alloc     resources
+	uptime    time.Duration
+}
+
+func (g *reportGroup) add(vm vmData, meta map[string]vmMeta, uptime time.Duration) {
+	g.instances++
+	if vm.info.State == "Running" {
+		g.running++
+	}
+	r := allocatedResources([]vmData{vm}, meta)
+	g.alloc.cpus += r.cpus
+	g.alloc.memoryMB += r.memoryMB
+	g.alloc.diskGB += r.diskGB
+	g.uptime += uptime
+}
+
+// reportUnknown names the group of VMs without an owner or a tag.
+var reportUnknown = map[string]string{"owner": "(unknown)", "tag": "(untagged)"}
+
+// buildReport groups vms by owner or tag. owners maps VM names to owners;
+// uptime counts the usage samples st holds from since on.
+func buildReport(vms []vmData, st appState, owners map[string]string, by string, since time.Time) (groups []reportGroup, total reportGroup) {
+	byName := make(map[string]*reportGroup)
+	total.name = "TOTAL"
+	for _, vm := range vms {
+		if vm.info.State == "Deleted" {
+			continue
+		}
+		meta := st.VMs[vm.info.Name]
+		var uptime time.Duration
+		if id := st.lookupID(vm.info.Name); id != "" {
+			for _, s := range st.Usage[id] {
+				if !s.Time.Before(since) {
+					uptime += usageSampleInterval
+				}
+			}
+		}
+		keys := meta.Tags
+		if by == "owner" {
+			keys = nil
+			if owner := owners[vm.info.Name]; owner != "" {
+				keys = []string{owner}
+			}
+		}
+		if len(keys) == 0 {
+			keys = []string{reportUnknown[by]}
+		}
+		for _, key := range keys {
+			g, ok := byName[key]
+			if !ok {
+				g = &reportGroup{name: key}
+				byName[key] = g
+			}
+			g.add(vm, st.VMs, uptime)
+		}
+		total.add(vm, st.VMs, uptime)
+	}
+	for _, g := range byName {
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i].name, groups[j].name
+		if ua, ub := a == reportUnknown[by], b == reportUnknown[by]; ua != ub {
+			return ub
+		}
+		return compareStringsFold(a, b) < 0
+	})
+	return groups, total
+}
+
+// reportTable turns the groups and total into rows under headers.
+func reportTable(by string, groups []reportGroup, total reportGroup) ([]string, [][]string) {
+	headers := []string{strings.ToUpper(by[:1]) + by[1:], "Instances", "Running", "vCPUs", "Memory", "Disk", "Uptime"}
+	var rows [][]string
+	for _, g := range append(groups, total) {
+		rows = append(rows, []string{
+			g.name,
+			strconv.Itoa(g.instances),
+			strconv.Itoa(g.running),
+			strconv.Itoa(g.alloc.cpus),
+			formatSizeMB(g.alloc.memoryMB),
+			strconv.Itoa(g.alloc.diskGB) + "G",
+			strconv.FormatFloat(g.uptime.Hours(), 'f', 1, 64) + "h",
+		})
+	}
+	return headers, rows
+}
+
+// renderReport formats the report as an aligned table or an export format.
+func renderReport(format string, headers []string, rows [][]string) ([]byte, error) {
+	if format != "table" {
+		names := map[string]string{"csv": "CSV", "markdown": "Markdown", "json": "JSON"}
+		return renderExport(names[format], headers, rows)
+	}
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	err := w.Flush()
+	return []byte(b.String()), err
+}
+
+// reportCommand implements `passgo report`.
+func reportCommand(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	var out cliOutput
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, reportUsage)
+		fs.PrintDefaults()
+	}
+	out.register(fs, "report")
+	by := fs.String("by", "owner", "group instances by owner or tag")
+	period := 24 * time.Hour
+	fs.Func("since", "period the uptime covers, e.g. 12h or 1d (default 24h)", func(s string) error {
+		d, err := parseTTL(s)
+		if err != nil || d == 0 {
+			return errors.New("want a duration such as 12h or 1d")
+		}
+		period = d
+		return nil
+	})
+	format := fs.String("format", "table", "table, csv, markdown or json")
+	file := fs.String("o", "", "write to FILE instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return out.fail(stderr, usageError{err})
+	}
+	switch {
+	case fs.NArg() > 0:
+		return out.fail(stderr, usageErrorf("unexpected arguments %v", fs.Args()))
+	case *by != "owner" && *by != "tag":
+		return out.fail(stderr, usageErrorf("-by %q: want owner or tag", *by))
+	}
+	*format = strings.ToLower(*format)
+	switch *format {
+	case "table", "csv", "markdown", "json":
+	default:
+		return out.fail(stderr, usageErrorf("-format %q: want table, csv, markdown or json", *format))
+	}
+
+	path, err := stateFilePath()
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+	st, err := loadState(path)
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+	vms, err := doFetchVMList()
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+	owners := mergeOwners(loadOwnerPolicy(configValue).read(), st.VMs)
+	progress := out.reporter(stderr)
+	if history := usageSampleLimit * usageSampleInterval; period > history {
+		progress.say("", "note", 0, "uptime only covers the last %.0fh: passgo keeps no older usage samples", history.Hours())
+	}
+	groups, total := buildReport(vms, st, owners, *by, time.Now().Add(-period))
+	headers, rows := reportTable(*by, groups, total)
+	data, err := renderReport(*format, headers, rows)
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+	if *file == "" {
+		_, _ = stdout.Write(data)
+		return exitOK
+	}
+	if err := os.WriteFile(*file, data, 0o600); err != nil {
+		return out.fail(stderr, err)
+	}
+	progress.say("", "done", 100, "wrote %s", *file)
+	return exitOK
+}
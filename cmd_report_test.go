@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildReport(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	st := newAppState()
+	st.VMs["web"] = vmMeta{ID: "a", CPUs: 2, MemoryMB: 4096, DiskGB: 20, Tags: []string{"team-a", "web"}}
+	st.VMs["db"] = vmMeta{ID: "b", CPUs: 4, MemoryMB: 8192, DiskGB: 40, Tags: []string{"team-a"}}
+	st.Usage["a"] = []usageSample{{Time: now.Add(-25 * time.Hour)}, {Time: now.Add(-time.Hour)}, {Time: now.Add(-55 * time.Minute)}}
+	st.Usage["b"] = []usageSample{{Time: now.Add(-10 * time.Minute)}}
+	vms := []vmData{
+		{info: VMInfo{Name: "web", State: "Running"}},
+		{info: VMInfo{Name: "db", State: "Stopped"}},
+		{info: VMInfo{Name: "odd", State: "Stopped", CPUs: "1", MemoryUsage: "-- out of 1.0GiB", DiskUsage: "-- out of 5.0GiB"}},
+		{info: VMInfo{Name: "gone", State: "Deleted", CPUs: "8"}},
+	}
+	owners := map[string]string{"web": "alice", "db": "bob"}
+
+	groups, total := buildReport(vms, st, owners, "owner", now.Add(-24*time.Hour))
+	headers, rows := reportTable("owner", groups, total)
+	if want := []string{"Owner", "Instances", "Running", "vCPUs", "Memory", "Disk", "Uptime"}; !reflect.DeepEqual(headers, want) {
+		t.Errorf("headers = %v", headers)
+	}
+	want := [][]string{
+		{"alice", "1", "1", "2", "4G", "20G", "0.2h"},
+		{"bob", "1", "0", "4", "8G", "40G", "0.1h"},
+		{"(unknown)", "1", "0", "1", "1G", "5G", "0.0h"},
+		{"TOTAL", "3", "1", "7", "13G", "65G", "0.2h"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("by owner:\n got %v\nwant %v", rows, want)
+	}
+
+	groups, total = buildReport(vms, st, owners, "tag", now.Add(-24*time.Hour))
+	var names []string
+	for _, g := range groups {
+		names = append(names, fmt.Sprintf("%s:%d", g.name, g.instances))
+	}
+	if got := strings.Join(names, " "); got != "team-a:2 web:1 (untagged):1" {
+		t.Errorf("tag groups = %s", got)
+	}
+	if total.instances != 3 || total.alloc.cpus != 7 {
+		t.Errorf("a VM with two tags must count once in the total: %+v", total)
+	}
+}
+
+func TestRenderReportTable(t *testing.T) {
+	data, err := renderReport("table", []string{"Tag", "Instances"}, [][]string{{"web", "1"}, {"TOTAL", "12"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Tag    Instances\nweb    1\nTOTAL  12\n"; string(data) != want {
+		t.Errorf("table:\n%s", data)
+	}
+	data, err = renderReport("csv", []string{"Tag", "Instances"}, [][]string{{"web", "1"}})
+	if err != nil || string(data) != "Tag,Instances\nweb,1\n" {
+		t.Errorf("csv = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,134 @@
+// cmd_run.go - `passgo run`: launch a throwaway VM, run a command, purge it
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+)
+
+// runOptions are the flags accepted by `passgo run`.
+type runOptions struct {
+	launchOptions
+	script  string // local script piped to bash in the VM
+	keep    bool   // leave the VM behind instead of purging it
+	command []string
+	out     cliOutput
+}
+
+const runUsage = `Usage: passgo run [flags] [--] command [args...]
+       passgo run [flags] --script ./job.sh
+
+Launches a fresh VM, runs the command (or script) inside it with output
+streamed, then deletes and purges the VM. Exits with the command's exit code.
+
+Flags:
+`
+
+// parseRunOptions parses `passgo run` arguments.
+func parseRunOptions(args []string, stderr io.Writer) (runOptions, error) {
+	var o runOptions
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, runUsage)
+		fs.PrintDefaults()
+	}
+	o.launchOptions.register(fs)
+	fs.StringVar(&o.script, "script", "", "local script to run with bash inside the VM")
+	fs.BoolVar(&o.keep, "keep", false, "keep the VM instead of purging it afterwards")
+	o.out.register(fs, "run")
+	o.out.registerProgress(fs)
+	if err := fs.Parse(args); err != nil {
+		return o, err
+	}
+	o.command = fs.Args()
+	if (o.script == "") == (len(o.command) == 0) {
+		fs.Usage()
+		return o, usageErrorf("give either a command or --script")
+	}
+	if err := o.launchOptions.finish(); err != nil {
+		return o, usageError{err}
+	}
+	return o, nil
+}
+
+// runCommand implements `passgo run` and returns the process exit code.
+func runCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	o, err := parseRunOptions(args, stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return o.out.fail(stderr, usageError{err})
+	}
+	progress := o.out.reporter(stderr)
+
+	// Read stdin user-data and render secrets before launching so a bad
+	// pipe or missing secret fails before any VM exists.
+	cloudInit, cleanup, err := resolveCloudInit(o.cloudInitFile, stdin)
+	if err != nil {
+		return o.out.fail(stderr, inputError(err))
+	}
+	defer cleanup()
+	cloudInit, cleanupRendered, err := prepareCloudInit(cloudInit)
+	if err != nil {
+		return o.out.fail(stderr, inputError(err))
+	}
+	defer cleanupRendered()
+	o.cloudInitFile = cloudInit
+
+	var cmdStdin io.Reader
+	command := o.command
+	if o.script != "" {
+		f, err := os.Open(o.script) // #nosec G304 -- script path given by the user
+		if err != nil {
+			return o.out.fail(stderr, inputError(err))
+		}
+		defer f.Close()
+		cmdStdin = f
+		command = []string{"bash", "-s"}
+	}
+
+	// Keep Ctrl+C from killing passgo before cleanup; the child still gets it.
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	defer signal.Stop(sigs)
+
+	// The VM is purged afterwards, so it must not be one that already exists.
+	if instanceExists(o.name) {
+		return o.out.fail(stderr, usageErrorf("instance %q already exists; choose another -name", o.name))
+	}
+	if err := launchFromOptions(o.launchOptions, stdin, progress); err != nil {
+		if instanceExists(o.name) {
+			err = fmt.Errorf("%w (remove the partly launched VM with: multipass delete --purge %s)", err, o.name)
+		}
+		return o.out.fail(stderr, fmt.Errorf("launch failed: %w", err))
+	}
+
+	if o.keep {
+		defer progress.say(o.name, "keep", -1, "keeping %s", o.name)
+	} else {
+		defer func() {
+			progress.say(o.name, "delete", -1, "deleting %s…", o.name)
+			if _, err := DeleteVM(o.name, true); err != nil {
+				fmt.Fprintf(stderr, "passgo: cleanup failed, delete %s manually: %v\n", o.name, err)
+			}
+		}()
+	}
+
+	err = ExecInVMAttached(o.name, cmdStdin, stdout, stderr, command...)
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &exitErr):
+		return exitErr.ExitCode()
+	default:
+		return o.out.fail(stderr, err)
+	}
+}
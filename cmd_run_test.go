@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseRunOptions(t *testing.T) {
+	o, err := parseRunOptions([]string{"--cpus", "4", "--name", "ci", "--", "make", "test"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o.name != "ci" || o.cpus != 4 || strings.Join(o.command, " ") != "make test" {
+		t.Fatalf("unexpected options: %+v", o)
+	}
+
+	o, err = parseRunOptions([]string{"--script", "job.sh"}, io.Discard)
+	if err != nil || o.script != "job.sh" || !strings.HasPrefix(o.name, VMNamePrefix) {
+		t.Fatalf("expected script run with generated name, got %+v %v", o, err)
+	}
+
+	for _, args := range [][]string{
+		{},
+		{"--script", "job.sh", "echo", "hi"},
+		{"--memory", "64", "true"},
+	} {
+		if _, err := parseRunOptions(args, io.Discard); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
+
+func TestRunCommandLeavesExistingVMs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	launched := func(f *fakeRunner, cmd string) bool {
+		return slices.ContainsFunc(f.calls, func(c []string) bool { return c[0] == cmd })
+	}
+
+	f := &fakeRunner{outputs: map[string]string{"info": "Name: web\nState: Running\n"}}
+	useFakeRunner(t, f)
+	if code := runCommand([]string{"--name", "web", "true"}, nil, io.Discard, io.Discard); code != exitUsage {
+		t.Fatalf("exit code %d, want %d", code, exitUsage)
+	}
+	if launched(f, "launch") || launched(f, "delete") {
+		t.Fatalf("an existing VM must be left alone, calls %v", f.calls)
+	}
+
+	f = &fakeRunner{fail: map[string]error{"info": errors.New("does not exist"), "launch": errors.New("no space left")}}
+	useFakeRunner(t, f)
+	if code := runCommand([]string{"--name", "web", "true"}, nil, io.Discard, io.Discard); code == exitOK {
+		t.Fatal("a failed launch should fail the run")
+	}
+	if !launched(f, "launch") || launched(f, "delete") {
+		t.Fatalf("nothing was launched, so nothing should be deleted: %v", f.calls)
+	}
+}
+
+func TestRunCommandMissingFiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useFakeRunner(t, &fakeRunner{})
+	missing := filepath.Join(t.TempDir(), "missing")
+	for _, args := range [][]string{
+		{"--cloud-init", missing, "true"},
+		{"--script", missing},
+	} {
+		if code := runCommand(args, nil, io.Discard, io.Discard); code != exitNotFound {
+			t.Errorf("%v: exit code %d, want %d", args, code, exitNotFound)
+		}
+	}
+	if code := runCommand([]string{"--cloud-init", "-", "true"}, strings.NewReader(""), io.Discard, io.Discard); code != exitUsage {
+		t.Errorf("empty stdin user-data: exit code %d, want %d", code, exitUsage)
+	}
+}
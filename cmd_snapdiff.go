@@ -0,0 +1,62 @@
+// cmd_snapdiff.go - `passgo snapshot-diff`: what changed in a VM since a snapshot
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const snapshotDiffUsage = `Usage: passgo snapshot-diff VM SNAPSHOT [PATH...]
+
+Lists the files under each PATH that changed in VM since SNAPSHOT. The
+stopped VM is cloned as it is now, then restored to SNAPSHOT to clone it
+as it was, and put back from a holding snapshot (passgo-clone-…) that is
+deleted afterwards. The files are checksummed in both clones, and the
+clones are deleted. PATH defaults to /etc /home /opt /usr/local. Each
+file is printed as M (changed), A (added) or D (deleted).
+
+Flags:
+`
+
+// snapshotDiffCommand implements `passgo snapshot-diff`.
+func snapshotDiffCommand(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	var out cliOutput
+	fs := flag.NewFlagSet("snapshot-diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, snapshotDiffUsage)
+		fs.PrintDefaults()
+	}
+	out.register(fs, "snapshot-diff")
+	out.registerProgress(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return out.fail(stderr, usageError{err})
+	}
+	if fs.NArg() < 2 {
+		return out.fail(stderr, usageErrorf("want a VM and a snapshot"))
+	}
+	vm, snap, paths := fs.Arg(0), fs.Arg(1), defaultComparePaths
+	if fs.NArg() > 2 {
+		var err error
+		if paths, err = parseComparePaths(strings.Join(fs.Args()[2:], " ")); err != nil {
+			return out.fail(stderr, usageError{err})
+		}
+	}
+	was, now, err := compareCloneNames(vm, loadNamingPolicy(configValue))
+	if err != nil {
+		return out.fail(stderr, usageError{err})
+	}
+	progress := out.reporter(stderr)
+	diff, err := CompareSnapshot(vm, snap, paths, was, now, func(s string) { progress.say(vm, "compare", 0, "%s", s) })
+	if err != nil {
+		return out.fail(stderr, err)
+	}
+	fmt.Fprint(stdout, diff.report())
+	return exitOK
+}
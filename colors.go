@@ -0,0 +1,159 @@
+// colors.go - State-colored rows, usage thresholds and per-theme color overrides
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Set from .config when passgo starts (see applyColorConfig):
+//
+//	row-colors=off            leave name, snapshot and IP cells uncolored
+//	usage-warn=60             CPU, disk and memory bars turn amber at 60%
+//	usage-critical=90         and red at 90%
+//	theme-color=Nord running=#A3BE8C critical=#FF0000   (repeatable; * is every theme)
+var (
+	rowStateColors  = true
+	usageWarnAt     = 0.6
+	usageCriticalAt = 0.9
+)
+
+// themeColorRoles maps the roles theme-color can set to their theme field.
+var themeColorRoles = map[string]func(*theme) *lipgloss.Color{
+	"running":   func(t *theme) *lipgloss.Color { return &t.Running },
+	"stopped":   func(t *theme) *lipgloss.Color { return &t.Stopped },
+	"suspended": func(t *theme) *lipgloss.Color { return &t.Suspended },
+	"deleted":   func(t *theme) *lipgloss.Color { return &t.Deleted },
+	"warn":      func(t *theme) *lipgloss.Color { return &t.Warn },
+	"critical":  func(t *theme) *lipgloss.Color { return &t.Critical },
+}
+
+// colorValueRe matches the colors lipgloss understands: #rgb, #rrggbb or
+// an ANSI color number.
+var colorValueRe = regexp.MustCompile(`^(#[0-9A-Fa-f]{3}|#[0-9A-Fa-f]{6}|[0-9]{1,3})$`)
+
+// themeColorOverride is one parsed theme-color entry.
+type themeColorOverride struct {
+	theme  string // theme name, or * for all
+	colors map[string]lipgloss.Color
+}
+
+// parseThemeColor parses "THEME role=color...". Theme names with spaces
+// are quoted, e.g. "Tokyo Night" running=#9ECE6A.
+func parseThemeColor(v string) (themeColorOverride, error) {
+	v = strings.TrimSpace(v)
+	var name, rest string
+	if strings.HasPrefix(v, `"`) {
+		end := strings.Index(v[1:], `"`)
+		if end < 0 {
+			return themeColorOverride{}, fmt.Errorf("theme-color %q: unterminated quote", v)
+		}
+		name, rest = v[1:end+1], v[end+2:]
+	} else {
+		name, rest, _ = strings.Cut(v, " ")
+	}
+	o := themeColorOverride{theme: name, colors: make(map[string]lipgloss.Color)}
+	if name != "*" && themeIndex(name) < 0 {
+		return o, fmt.Errorf("theme-color %q: no theme called %q", v, name)
+	}
+	for _, f := range strings.Fields(rest) {
+		role, clr, ok := strings.Cut(f, "=")
+		role = strings.ToLower(role)
+		if _, known := themeColorRoles[role]; !ok || !known {
+			return o, fmt.Errorf("theme-color %q: want role=color with a role of running, stopped, suspended, deleted, warn or critical, got %q", v, f)
+		}
+		if !colorValueRe.MatchString(clr) {
+			return o, fmt.Errorf("theme-color %q: %q is not a #rrggbb or ANSI color", v, clr)
+		}
+		o.colors[role] = lipgloss.Color(clr)
+	}
+	if len(o.colors) == 0 {
+		return o, fmt.Errorf("theme-color %q: no colors given", v)
+	}
+	return o, nil
+}
+
+// themeIndex finds a theme by name, ignoring case, or returns -1.
+func themeIndex(name string) int {
+	for i, t := range themes {
+		if strings.EqualFold(t.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// apply writes the override into ts.
+func (o themeColorOverride) apply(ts []theme) {
+	for i := range ts {
+		if o.theme != "*" && !strings.EqualFold(ts[i].Name, o.theme) {
+			continue
+		}
+		for role, clr := range o.colors {
+			*themeColorRoles[role](&ts[i]) = clr
+		}
+	}
+}
+
+// parsePercent reads a percentage between 1 and 100 as a fraction.
+func parsePercent(v string) (float64, bool) {
+	n, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(v), "%"), 64)
+	if err != nil || n <= 0 || n > 100 {
+		return 0, false
+	}
+	return n / 100, true
+}
+
+// applyColorConfig reads the row color, threshold and theme-color settings
+// and rebuilds the styles. Bad entries are skipped and returned.
+func applyColorConfig(lookup func(string) (string, bool), themeColors []string) []error {
+	var errs []error
+	if v, ok := lookup("row-colors"); ok {
+		rowStateColors = parseConfigBool(v)
+	}
+	for _, s := range []struct {
+		key string
+		dst *float64
+	}{{"usage-warn", &usageWarnAt}, {"usage-critical", &usageCriticalAt}} {
+		v, ok := lookup(s.key)
+		if !ok {
+			continue
+		}
+		if f, ok := parsePercent(v); ok {
+			*s.dst = f
+		} else {
+			errs = append(errs, fmt.Errorf("%s %q: want a percentage from 1 to 100", s.key, v))
+		}
+	}
+	if usageWarnAt > usageCriticalAt {
+		errs = append(errs, fmt.Errorf("usage-warn %.0f%% is above usage-critical %.0f%%; warning at the critical level", usageWarnAt*100, usageCriticalAt*100))
+		usageWarnAt = usageCriticalAt
+	}
+	for _, v := range themeColors {
+		o, err := parseThemeColor(v)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		o.apply(themes)
+	}
+	rebuildStyles()
+	return errs
+}
+
+// rowTextColor is the color of a row's plain cells, or "" to keep the
+// default text color.
+func rowTextColor(state string) lipgloss.Color {
+	if !rowStateColors {
+		return ""
+	}
+	switch state {
+	case "Running", "Stopped", "Suspended", "Deleted":
+		return stateColor(state)
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keepColorConfig restores the themes and color settings after a test.
+func keepColorConfig(t *testing.T) {
+	t.Helper()
+	saved := append([]theme(nil), themes...)
+	rows, warn, critical := rowStateColors, usageWarnAt, usageCriticalAt
+	t.Cleanup(func() {
+		themes = saved
+		rowStateColors, usageWarnAt, usageCriticalAt = rows, warn, critical
+		rebuildStyles()
+	})
+}
+
+func TestParseThemeColor(t *testing.T) {
+	o, err := parseThemeColor(`"tokyo night" running=#00ff00 Critical=196`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.theme != "tokyo night" || o.colors["running"] != "#00ff00" || o.colors["critical"] != "196" {
+		t.Errorf("got %+v", o)
+	}
+	for _, bad := range []string{"Nord", "Nowhere running=#fff", "Nord running", "Nord glow=#fff", "Nord running=green", `"Nord running=#fff`} {
+		if _, err := parseThemeColor(bad); err == nil {
+			t.Errorf("%q should not parse", bad)
+		}
+	}
+}
+
+func TestApplyColorConfig(t *testing.T) {
+	keepColorConfig(t)
+	cfg := map[string]string{"row-colors": "off", "usage-warn": "50", "usage-critical": "95%"}
+	lookup := func(k string) (string, bool) { v, ok := cfg[k]; return v, ok }
+	errs := applyColorConfig(lookup, []string{"* deleted=#111111", "Nord warn=#222222", "Nord bogus"})
+	if len(errs) != 1 {
+		t.Errorf("want one error for the bad entry, got %v", errs)
+	}
+	if rowStateColors || usageWarnAt != 0.5 || usageCriticalAt != 0.95 {
+		t.Errorf("settings not applied: rows %v, warn %v, critical %v", rowStateColors, usageWarnAt, usageCriticalAt)
+	}
+	nord := themes[themeIndex("Nord")]
+	if nord.Deleted != "#111111" || nord.Warn != "#222222" || themes[0].Deleted != "#111111" || themes[0].Warn != "" {
+		t.Errorf("overrides not applied: %+v", nord)
+	}
+	if rowTextColor("Running") != "" {
+		t.Error("row-colors=off should leave rows uncolored")
+	}
+
+	cfg = map[string]string{"usage-warn": "95", "usage-critical": "90"}
+	if errs := applyColorConfig(lookup, nil); len(errs) != 1 || usageWarnAt != usageCriticalAt {
+		t.Errorf("warn above critical should be clamped: %v", errs)
+	}
+}
+
+func TestUsageBarColorThresholds(t *testing.T) {
+	keepColorConfig(t)
+	themes[currentThemeIndex].Warn, themes[currentThemeIndex].Critical = "", ""
+	usageWarnAt, usageCriticalAt = 0.6, 0.9
+	rebuildStyles()
+	for frac, want := range map[float64]lipgloss.Color{0.3: runningClr, 0.7: suspendClr, 0.89: suspendClr, 0.91: stoppedClr} {
+		if got := usageBarColor(frac); got != want {
+			t.Errorf("usageBarColor(%v) = %v, want %v", frac, got, want)
+		}
+	}
+	rowStateColors = true
+	if rowTextColor("Suspended") != suspendClr || rowTextColor("Creating") != "" {
+		t.Error("rows should take the state color")
+	}
+}
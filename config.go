@@ -0,0 +1,188 @@
+// config.go - Reading key=value settings from the .config file
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// configEntry is one key/value line of a .config file.
+type configEntry struct{ key, value string }
+
+// readConfigEntriesFromFile parses a .config file of "key=value" (or
+// "key: value") lines in order. Blank lines and lines starting with # are
+// ignored.
+func readConfigEntriesFromFile(configPath string) ([]configEntry, error) {
+	file, err := os.Open(configPath) // #nosec G304 -- path from app search dirs
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []configEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		idx := strings.IndexAny(line, "=:")
+		if idx <= 0 {
+			continue
+		}
+		entries = append(entries, configEntry{strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+1:])})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// readConfigValuesFromFile parses a .config file into a map; when a key
+// repeats, the last value wins.
+func readConfigValuesFromFile(configPath string) (map[string]string, error) {
+	entries, err := readConfigEntriesFromFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	for _, e := range entries {
+		values[e.key] = e.value
+	}
+	return values, nil
+}
+
+// readConfigListFromDirs returns every value of a repeatable key from the
+// first .config in searchDirs that sets it.
+func readConfigListFromDirs(searchDirs []string, key string) []string {
+	for _, dir := range searchDirs {
+		entries, err := readConfigEntriesFromFile(filepath.Join(dir, ".config"))
+		if err != nil {
+			continue
+		}
+		var values []string
+		for _, e := range entries {
+			if e.key == key {
+				values = append(values, e.value)
+			}
+		}
+		if len(values) > 0 {
+			return values
+		}
+	}
+	return nil
+}
+
+// readConfigValueFromDirs returns the value of key from the first .config
+// in searchDirs that sets it.
+func readConfigValueFromDirs(searchDirs []string, key string) (string, bool) {
+	for _, dir := range searchDirs {
+		values, err := readConfigValuesFromFile(filepath.Join(dir, ".config"))
+		if err != nil {
+			continue
+		}
+		if v, ok := values[key]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// configValue reads key from .config in the preferred app search directories.
+func configValue(key string) (string, bool) {
+	return readConfigValueFromDirs(appSearchDirs(), key)
+}
+
+// configList reads every value of a repeatable key from .config.
+func configList(key string) []string {
+	return readConfigListFromDirs(appSearchDirs(), key)
+}
+
+// configBool reads a boolean setting; "true", "yes", "on" and "1" are true.
+func configBool(key string) bool {
+	v, ok := configValue(key)
+	if !ok {
+		return false
+	}
+	return parseConfigBool(v)
+}
+
+func parseConfigBool(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "true", "yes", "on", "1":
+		return true
+	}
+	return false
+}
+
+// proxyConfigKeys maps .config proxy settings to the environment variables
+// honoured by git, curl-style tools and Go's HTTP client.
+var proxyConfigKeys = []struct{ key, env string }{
+	{"http-proxy", "HTTP_PROXY"},
+	{"https-proxy", "HTTPS_PROXY"},
+	{"no-proxy", "NO_PROXY"},
+}
+
+// applyProxyConfig exports proxy settings from lookup into the process
+// environment (upper and lower case) so git clones and downloads started by
+// passgo go through the proxy. Variables already set in the environment win.
+// It returns the environment variables it set.
+func applyProxyConfig(lookup func(string) (string, bool)) []string {
+	var applied []string
+	for _, pk := range proxyConfigKeys {
+		v, ok := lookup(pk.key)
+		if !ok || strings.TrimSpace(v) == "" {
+			continue
+		}
+		if os.Getenv(pk.env) != "" || os.Getenv(strings.ToLower(pk.env)) != "" {
+			continue
+		}
+		v = strings.TrimSpace(v)
+		_ = os.Setenv(pk.env, v)
+		_ = os.Setenv(strings.ToLower(pk.env), v)
+		applied = append(applied, pk.env)
+	}
+	return applied
+}
+
+// launchDefaults are the settings used by quick launch (L). Configured in
+// .config with default-release, default-cpus, default-memory (bare number =
+// MB), default-disk (bare number = GB) and default-cloud-init.
+type launchDefaults struct {
+	release   string
+	cpus      int
+	memoryMB  int
+	diskGB    int
+	cloudInit string
+}
+
+// loadLaunchDefaults reads launch defaults using lookup (normally
+// configValue), falling back to the Advanced Create defaults.
+func loadLaunchDefaults(lookup func(string) (string, bool)) launchDefaults {
+	d := launchDefaults{release: DefaultUbuntuRelease, cpus: DefaultCPUCores, memoryMB: DefaultRAMMB, diskGB: DefaultDiskGB}
+	if v, ok := lookup("default-release"); ok && strings.TrimSpace(v) != "" {
+		d.release = strings.TrimSpace(v)
+	}
+	if v, ok := lookup("default-cpus"); ok {
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && n >= MinCPUCores {
+			d.cpus = n
+		}
+	}
+	if v, ok := lookup("default-memory"); ok {
+		if n := parseQuotaSizeMB(v, 1); n >= MinRAMMB {
+			d.memoryMB = n
+		}
+	}
+	if v, ok := lookup("default-disk"); ok {
+		if n := parseQuotaSizeMB(v, 1024) / 1024; n >= MinDiskGB {
+			d.diskGB = n
+		}
+	}
+	if v, ok := lookup("default-cloud-init"); ok {
+		d.cloudInit = strings.TrimSpace(v)
+	}
+	return d
+}
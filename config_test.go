@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadConfigValueFromDirs(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+	if err := os.WriteFile(filepath.Join(first, ".config"), []byte("# comment\naccessible = yes\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(second, ".config"), []byte("accessible=false\nproxy: http://proxy:3128\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	dirs := []string{first, second}
+	if v, ok := readConfigValueFromDirs(dirs, "accessible"); !ok || !parseConfigBool(v) {
+		t.Fatalf("expected first dir to win with a true value, got %q %v", v, ok)
+	}
+	if v, ok := readConfigValueFromDirs(dirs, "proxy"); !ok || v != "http://proxy:3128" {
+		t.Fatalf("expected fallback to second dir, got %q %v", v, ok)
+	}
+	if _, ok := readConfigValueFromDirs(dirs, "missing"); ok {
+		t.Fatalf("expected missing key to be reported as not found")
+	}
+}
+
+func TestApplyProxyConfig(t *testing.T) {
+	for _, env := range []string{"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy", "NO_PROXY", "no_proxy"} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("HTTPS_PROXY", "http://from-env:8080")
+
+	values := map[string]string{
+		"http-proxy":  "http://proxy:3128",
+		"https-proxy": "http://proxy:3128",
+		"no-proxy":    "localhost,.local",
+	}
+	lookup := func(key string) (string, bool) {
+		v, ok := values[key]
+		return v, ok
+	}
+
+	applied := applyProxyConfig(lookup)
+	if len(applied) != 2 {
+		t.Fatalf("expected HTTP_PROXY and NO_PROXY to be applied, got %v", applied)
+	}
+	if got := os.Getenv("http_proxy"); got != "http://proxy:3128" {
+		t.Fatalf("expected lower-case http_proxy to be set, got %q", got)
+	}
+	if got := os.Getenv("HTTPS_PROXY"); got != "http://from-env:8080" {
+		t.Fatalf("expected existing HTTPS_PROXY to win, got %q", got)
+	}
+}
+
+func TestLoadLaunchDefaults(t *testing.T) {
+	d := loadLaunchDefaults(func(string) (string, bool) { return "", false })
+	if d.release != DefaultUbuntuRelease || d.cpus != DefaultCPUCores || d.memoryMB != DefaultRAMMB || d.diskGB != DefaultDiskGB {
+		t.Fatalf("unexpected fallback defaults: %+v", d)
+	}
+
+	cfg := map[string]string{
+		"default-release":    "22.04",
+		"default-cpus":       "4",
+		"default-memory":     "2G",
+		"default-disk":       "20",
+		"default-cloud-init": " /tmp/dev.yaml ",
+	}
+	d = loadLaunchDefaults(func(k string) (string, bool) { v, ok := cfg[k]; return v, ok })
+	want := launchDefaults{release: "22.04", cpus: 4, memoryMB: 2048, diskGB: 20, cloudInit: "/tmp/dev.yaml"}
+	if d != want {
+		t.Fatalf("got %+v, want %+v", d, want)
+	}
+
+	cfg = map[string]string{"default-cpus": "0", "default-memory": "64"}
+	d = loadLaunchDefaults(func(k string) (string, bool) { v, ok := cfg[k]; return v, ok })
+	if d.cpus != DefaultCPUCores || d.memoryMB != DefaultRAMMB {
+		t.Fatalf("values below the minimum should be ignored, got %+v", d)
+	}
+}
+
+func TestReadConfigListFromDirs(t *testing.T) {
+	empty := t.TempDir()
+	dir := t.TempDir()
+	content := "snapshots: {vm: a, every: 1h}\nother=1\nsnapshots: {vm: b, every: 2h}\n"
+	if err := os.WriteFile(filepath.Join(dir, ".config"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got := readConfigListFromDirs([]string{empty, dir}, "snapshots")
+	want := []string{"{vm: a, every: 1h}", "{vm: b, every: 2h}"}
+	if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if got := readConfigListFromDirs([]string{dir}, "missing"); got != nil {
+		t.Fatalf("expected nil for missing key, got %q", got)
+	}
+}
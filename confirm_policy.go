@@ -0,0 +1,100 @@
+// confirm_policy.go - Which operations ask before running, in the TUI and in scripts
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// confirmLevel says which operations ask for confirmation. Set in .config
+// with confirm: none, destructive (the default) or all. Destructive covers
+// every dialog passgo has always shown: deletes, purges, restores,
+// re-applying cloud-init, bulk start and stop and host network changes.
+// All adds single-VM start, stop, suspend, trash, recover and network
+// repair. Force stops ask at every level.
+type confirmLevel int
+
+const (
+	confirmDestructive confirmLevel = iota
+	confirmNone
+	confirmAll
+)
+
+// loadConfirmLevel reads the level using lookup (normally configValue).
+// Unknown values keep the default.
+func loadConfirmLevel(lookup func(string) (string, bool)) confirmLevel {
+	v, _ := lookup("confirm")
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "none", "off", "never":
+		return confirmNone
+	case "all", "always":
+		return confirmAll
+	}
+	return confirmDestructive
+}
+
+// asks reports whether an operation, destructive or not, needs confirming.
+func (l confirmLevel) asks(destructive bool) bool {
+	switch l {
+	case confirmNone:
+		return false
+	case confirmAll:
+		return true
+	}
+	return destructive
+}
+
+// pendingRowOp is a single-VM operation waiting on a confirm dialog; once
+// accepted its row shows op while it runs, instead of the loading screen.
+type pendingRowOp struct {
+	vmName string
+	op     string
+}
+
+// confirmFirst shows c and runs cmd once it is accepted, or runs cmd
+// straight away when the policy does not ask for this kind of operation.
+// A cancelled dialog returns to returnView.
+func (m *rootModel) confirmFirst(destructive bool, c confirmModel, cmd tea.Cmd, returnView viewState) tea.Cmd {
+	m.pendingCmd = cmd
+	m.pendingRow = pendingRowOp{}
+	m.confirmReturnView = returnView
+	if !m.confirmLevel.asks(destructive) {
+		return func() tea.Msg { return confirmResultMsg{confirmed: true} }
+	}
+	m.confirm = c
+	m.setChildSizes()
+	m.currentView = viewConfirm
+	return m.confirm.Init()
+}
+
+// confirmRowOp runs cmd on vmName, marking its row busy with op. Only the
+// all level asks first, with question.
+func (m *rootModel) confirmRowOp(vmName, op, question string, cmd tea.Cmd) tea.Cmd {
+	if !m.confirmLevel.asks(false) {
+		m.table.busyVMs[vmName] = busyInfo{operation: op, startTime: time.Now()}
+		return cmd
+	}
+	m.pendingCmd = cmd
+	m.pendingRow = pendingRowOp{vmName: vmName, op: op}
+	m.confirmReturnView = viewTable
+	m.confirm = newConfirmModel(question)
+	m.setChildSizes()
+	m.currentView = viewConfirm
+	return nil
+}
+
+// confirmForceStop asks whether to force stop vmName, whatever the level:
+// the VM is powered off and loses anything not yet written to its disk.
+// why, if set, opens the question.
+func (m *rootModel) confirmForceStop(vmName, why string) tea.Cmd {
+	m.pendingCmd = forceStopVMCmd(vmName)
+	m.pendingRow = pendingRowOp{vmName: vmName, op: "Force stopping"}
+	m.confirmReturnView = viewTable
+	m.confirm = newConfirmModel(strings.TrimSpace(fmt.Sprintf("%s Force stop '%s'? It is powered off without a clean shutdown.", why, vmName)))
+	m.setChildSizes()
+	m.currentView = viewConfirm
+	return m.confirm.Init()
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLoadConfirmLevel(t *testing.T) {
+	for v, want := range map[string]confirmLevel{
+		"":            confirmDestructive,
+		"destructive": confirmDestructive,
+		"None":        confirmNone,
+		"all":         confirmAll,
+		"bogus":       confirmDestructive,
+	} {
+		lookup := func(string) (string, bool) { return v, v != "" }
+		if got := loadConfirmLevel(lookup); got != want {
+			t.Errorf("confirm: %q = %v, want %v", v, got, want)
+		}
+	}
+	if confirmNone.asks(true) || !confirmAll.asks(false) || confirmDestructive.asks(false) || !confirmDestructive.asks(true) {
+		t.Error("asks does not follow the levels")
+	}
+}
+
+func TestConfirmFirstFollowsLevel(t *testing.T) {
+	ran := false
+	cmd := func() tea.Msg { ran = true; return nil }
+
+	m := rootModel{table: newTableModel(), confirmLevel: confirmNone}
+	next := m.confirmFirst(true, newConfirmModel("Delete?"), cmd, viewTable)
+	if m.currentView == viewConfirm {
+		t.Fatal("confirm: none should not show the dialog")
+	}
+	res, ok := next().(confirmResultMsg)
+	if !ok || !res.confirmed || m.pendingCmd == nil {
+		t.Fatal("confirm: none should accept straight away")
+	}
+	model, _ := m.Update(res)
+	m = model.(rootModel)
+	if m.pendingCmd != nil || m.currentView != viewLoading {
+		t.Error("the accepted command should run")
+	}
+
+	m = rootModel{table: newTableModel()}
+	m.confirmFirst(true, newConfirmModel("Delete?"), cmd, viewBackup)
+	if m.currentView != viewConfirm || m.confirmReturnView != viewBackup {
+		t.Error("destructive operations should ask by default")
+	}
+	if ran {
+		t.Error("nothing should run before it is confirmed")
+	}
+}
+
+func TestConfirmRowOp(t *testing.T) {
+	cmd := func() tea.Msg { return nil }
+
+	m := rootModel{table: newTableModel()}
+	if m.confirmRowOp("web", "Stopping", "Stop 'web'?", cmd) == nil || m.table.busyVMs["web"].operation != "Stopping" {
+		t.Fatal("by default row operations run at once")
+	}
+
+	m = rootModel{table: newTableModel(), confirmLevel: confirmAll}
+	if m.confirmRowOp("web", "Stopping", "Stop 'web'?", cmd) != nil || m.currentView != viewConfirm {
+		t.Fatal("confirm: all should ask first")
+	}
+	if _, busy := m.table.busyVMs["web"]; busy {
+		t.Error("the row should not be busy before it is confirmed")
+	}
+	model, next := m.Update(confirmResultMsg{confirmed: true})
+	m = model.(rootModel)
+	if next == nil || m.currentView != viewTable || m.table.busyVMs["web"].operation != "Stopping" {
+		t.Errorf("accepting should run on the row (view %v, busy %v)", m.currentView, m.table.busyVMs)
+	}
+}
+
+func TestDestructiveContextUptime(t *testing.T) {
+	vm := VMInfo{Name: "web", State: "Running", Snapshots: "2"}
+	if got := destructiveContext(vm, 90*time.Minute); !slices.Contains(got, "Uptime: "+formatRemaining(90*time.Minute)) {
+		t.Fatalf("a running VM should show its uptime: %v", got)
+	}
+	vm.State = "Stopped"
+	for _, line := range destructiveContext(vm, 90*time.Minute) {
+		if strings.HasPrefix(line, "Uptime") {
+			t.Fatalf("a stopped VM has no uptime: %v", line)
+		}
+	}
+
+	m := rootModel{table: newTableModel(), currentView: viewTable}
+	model, _ := m.Update(deletePreviewMsg{vm: VMInfo{Name: "web", State: "Running"}, uptime: time.Hour})
+	m = model.(rootModel)
+	if m.currentView != viewConfirm || m.confirm.token != "web" {
+		t.Fatalf("the purge should ask to type the name (view %v)", m.currentView)
+	}
+}
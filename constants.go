@@ -6,9 +6,6 @@ const (
 	// DefaultUbuntuRelease is the default Ubuntu version for new VMs
 	DefaultUbuntuRelease = "24.04"
 
-	// DefaultReleaseIndex is the dropdown index for the default release
-	DefaultReleaseIndex = 3
-
 	// DefaultCPUCores is the default number of CPU cores for new VMs
 	DefaultCPUCores = 2
 
@@ -19,6 +16,13 @@ const (
 	DefaultDiskGB = 8
 )
 
+// Multipass's own defaults, used for quota accounting of quick-created VMs
+const (
+	MultipassDefaultCPUs     = 1
+	MultipassDefaultMemoryMB = 1024
+	MultipassDefaultDiskGB   = 5
+)
+
 // VM Resource Limits
 const (
 	// MinCPUCores is the minimum number of CPU cores allowed
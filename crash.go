@@ -0,0 +1,207 @@
+// crash.go - Panic recovery for the TUI and crash reports
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// crashLogLines is how many trailing passgo.log lines a crash report includes.
+const crashLogLines = 50
+
+// crashRecorder keeps the first panic seen by crashGuard. It is shared by
+// every copy of the guard and by wrapped commands running in goroutines.
+type crashRecorder struct {
+	mu    sync.Mutex
+	value any
+	stack []byte
+	send  func(tea.Msg) // Program.Send, to stop the program after a View panic
+}
+
+// record stores r and its stack unless a panic was already recorded; it
+// reports whether this was the first.
+func (c *crashRecorder) record(r any, stack []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.value != nil {
+		return false
+	}
+	c.value, c.stack = r, stack
+	return true
+}
+
+func (c *crashRecorder) crashed() (any, []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value, c.stack
+}
+
+// crashMsg tells the guard a panic was recorded and the program should quit.
+type crashMsg struct{}
+
+// crashGuard wraps the root model so a panic in Update, View or a command
+// quits the program normally, restoring the terminal, instead of leaving it
+// in raw mode. main writes the recorded panic to a crash report.
+type crashGuard struct {
+	model tea.Model
+	rec   *crashRecorder
+}
+
+func (g crashGuard) Init() (cmd tea.Cmd) {
+	defer func() {
+		if r := recover(); r != nil {
+			g.rec.record(r, debug.Stack())
+			cmd = tea.Quit
+		}
+	}()
+	return g.wrap(g.model.Init())
+}
+
+func (g crashGuard) Update(msg tea.Msg) (next tea.Model, cmd tea.Cmd) {
+	if _, ok := msg.(crashMsg); ok {
+		return g, tea.Quit
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			g.rec.record(r, debug.Stack())
+			next, cmd = g, tea.Quit
+		}
+	}()
+	m, cmd := g.model.Update(msg)
+	g.model = m
+	return g, g.wrap(cmd)
+}
+
+func (g crashGuard) View() (view string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if g.rec.record(r, debug.Stack()) && g.rec.send != nil {
+				go g.rec.send(crashMsg{})
+			}
+			view = ""
+		}
+	}()
+	return g.model.View()
+}
+
+// wrap recovers panics in cmd, including the commands of a tea.Batch.
+func (g crashGuard) wrap(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				g.rec.record(r, debug.Stack())
+				msg = crashMsg{}
+			}
+		}()
+		msg = cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			wrapped := make(tea.BatchMsg, len(batch))
+			for i, c := range batch {
+				wrapped[i] = g.wrap(c)
+			}
+			return wrapped
+		}
+		return msg
+	}
+}
+
+// ─── Reports ───────────────────────────────────────────────────────────────────
+
+// tailLines returns the last n lines of the file at path.
+func tailLines(path string, n int) []string {
+	f, err := os.Open(path) // #nosec G304 -- passgo's own log file
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines
+}
+
+// multipassVersionForReport asks multipass for its version, giving up after
+// a few seconds so a hung daemon cannot block the report.
+func multipassVersionForReport() string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var out bytes.Buffer
+	if err := mpRunner.run(ctx, []string{"version"}, nil, &out, nil); err != nil {
+		return fmt.Sprintf("unavailable (%v)", err)
+	}
+	return strings.TrimSpace(out.String())
+}
+
+// renderCrashReport formats a crash report.
+func renderCrashReport(now time.Time, value any, stack []byte, mpVersion string, logLines []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "passgo crash report, %s\n\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Version:   %s\n", GetVersion())
+	fmt.Fprintf(&b, "Multipass: %s\n\n", strings.ReplaceAll(mpVersion, "\n", "\n           "))
+	if value == nil {
+		b.WriteString("Panic: in a background goroutine (no stack captured)\n")
+	} else {
+		fmt.Fprintf(&b, "Panic: %v\n\n%s\n", value, stack)
+	}
+	fmt.Fprintf(&b, "\nLast %d log lines:\n", len(logLines))
+	for _, l := range logLines {
+		b.WriteString(l + "\n")
+	}
+	return b.String()
+}
+
+// writeCrashReport saves a crash report under dir and returns its path.
+func writeCrashReport(dir string, now time.Time, report string) (string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "crash-"+now.Format("20060102-150405")+".txt")
+	return path, os.WriteFile(path, []byte(report), 0o600)
+}
+
+// reportCrash writes the recorded panic to ~/.passgo and tells the user
+// where it is. It returns the process exit code.
+func reportCrash(rec *crashRecorder) int {
+	value, stack := rec.crashed()
+	if appLogger != nil {
+		appLogger.Printf("panic: %v\n%s", value, stack)
+	}
+	now := time.Now()
+	var logLines []string
+	dir, err := userConfigDir()
+	if err == nil {
+		logLines = tailLines(filepath.Join(dir, "passgo.log"), crashLogLines)
+	}
+	report := renderCrashReport(now, value, stack, multipassVersionForReport(), logLines)
+	if value == nil {
+		value = "panic in a background goroutine"
+	}
+	fmt.Fprintf(os.Stderr, "passgo crashed: %v\n", value)
+	if err == nil {
+		var path string
+		if path, err = writeCrashReport(dir, now, report); err == nil {
+			fmt.Fprintf(os.Stderr, "Crash report saved to %s\nPlease attach it when reporting the bug.\n", path)
+			return 2
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Could not save crash report (%v):\n\n%s", err, report)
+	return 2
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// panicModel panics in Update on "boom" keys and in View when asked.
+type panicModel struct{ viewPanics bool }
+
+func (m panicModel) Init() tea.Cmd { return nil }
+
+func (m panicModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if k, ok := msg.(tea.KeyMsg); ok && k.String() == "b" {
+		panic("boom")
+	}
+	return m, tea.Batch(func() tea.Msg { panic("in command") }, func() tea.Msg { return nil })
+}
+
+func (m panicModel) View() string {
+	if m.viewPanics {
+		panic("in view")
+	}
+	return "ok"
+}
+
+func isQuit(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	_, ok := cmd().(tea.QuitMsg)
+	return ok
+}
+
+func TestCrashGuardUpdate(t *testing.T) {
+	rec := &crashRecorder{}
+	g := crashGuard{model: panicModel{}, rec: rec}
+	_, cmd := g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	if !isQuit(cmd) {
+		t.Fatalf("expected quit after panic in Update")
+	}
+	value, stack := rec.crashed()
+	if value != "boom" || !strings.Contains(string(stack), "panicModel.Update") {
+		t.Fatalf("recorded %v with stack:\n%s", value, stack)
+	}
+}
+
+func TestCrashGuardCommands(t *testing.T) {
+	rec := &crashRecorder{}
+	g := crashGuard{model: panicModel{}, rec: rec}
+	_, cmd := g.Update(tea.WindowSizeMsg{})
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("expected the batch to be passed through, got %T", cmd())
+	}
+	if _, ok := batch[0]().(crashMsg); !ok {
+		t.Fatalf("expected crashMsg from panicking batch command")
+	}
+	if value, _ := rec.crashed(); value != "in command" {
+		t.Fatalf("recorded %v", value)
+	}
+	if _, cmd := g.Update(crashMsg{}); !isQuit(cmd) {
+		t.Fatalf("expected crashMsg to quit")
+	}
+}
+
+func TestCrashGuardView(t *testing.T) {
+	sent := make(chan tea.Msg, 1)
+	rec := &crashRecorder{send: func(msg tea.Msg) { sent <- msg }}
+	g := crashGuard{model: panicModel{viewPanics: true}, rec: rec}
+	if v := g.View(); v != "" {
+		t.Fatalf("View = %q after panic", v)
+	}
+	select {
+	case msg := <-sent:
+		if _, ok := msg.(crashMsg); !ok {
+			t.Fatalf("sent %T", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected crashMsg to be sent")
+	}
+}
+
+func TestCrashReport(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "passgo.log")
+	var log strings.Builder
+	for i := 0; i < crashLogLines+10; i++ {
+		log.WriteString("line\n")
+	}
+	log.WriteString("last line\n")
+	if err := os.WriteFile(logPath, []byte(log.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	lines := tailLines(logPath, crashLogLines)
+	if len(lines) != crashLogLines || lines[len(lines)-1] != "last line" {
+		t.Fatalf("tailLines returned %d lines ending %q", len(lines), lines[len(lines)-1])
+	}
+
+	now := time.Date(2026, time.October, 15, 9, 30, 0, 0, time.UTC)
+	report := renderCrashReport(now, "boom", []byte("goroutine 1 [running]:"), "multipass 1.14.0", lines)
+	for _, want := range []string{"Panic: boom", "goroutine 1 [running]:", "Multipass: multipass 1.14.0", "last line"} {
+		if !strings.Contains(report, want) {
+			t.Fatalf("report missing %q:\n%s", want, report)
+		}
+	}
+	path, err := writeCrashReport(dir, now, report)
+	if err != nil || filepath.Base(path) != "crash-20261015-093000.txt" {
+		t.Fatalf("writeCrashReport = %q, %v", path, err)
+	}
+}
@@ -0,0 +1,185 @@
+// cron.go - Cron expressions: the clock behind jobs and power schedules
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSpec is a five-field cron expression, "minute hour day-of-month
+// month day-of-week", matched in local time. Fields take *, numbers,
+// ranges (1-5), lists (1,15) and steps (*/15, 9-17/2); months and days
+// may be named (jan, mon). As in cron, a day matches when either day
+// field does if both are restricted.
+type cronSpec struct {
+	expr                     string
+	minute, hour, dom, month uint64 // bit i set: value i matches
+	dow                      uint64 // 0 is Sunday
+	domAny, dowAny           bool   // the field starts with *
+}
+
+// cronMacros are the @ shorthands for common expressions.
+var cronMacros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+// monthNames are the accepted month abbreviations, January first.
+var monthNames = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+
+// cronField describes the values one field of an expression takes.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // names[i] is min+i
+}
+
+var cronFields = [5]cronField{
+	{name: "minute", max: 59},
+	{name: "hour", max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: monthNames},
+	{name: "day of week", max: 7, names: dayNames}, // 7 is Sunday too
+}
+
+// parseCron parses a cron expression such as "0 2 * * mon-fri" or "@daily".
+func parseCron(expr string) (cronSpec, error) {
+	expr = strings.TrimSpace(expr)
+	spec := cronSpec{expr: expr}
+	fields := strings.Fields(expr)
+	if m, ok := cronMacros[strings.ToLower(expr)]; ok {
+		fields = strings.Fields(m)
+	}
+	if len(fields) != 5 {
+		return spec, fmt.Errorf("cron %q: want 5 fields (minute hour day month weekday) or @daily, @hourly, …", expr)
+	}
+	sets := [5]*uint64{&spec.minute, &spec.hour, &spec.dom, &spec.month, &spec.dow}
+	for i, f := range fields {
+		bits, err := cronFields[i].parse(f)
+		if err != nil {
+			return spec, fmt.Errorf("cron %q: %w", expr, err)
+		}
+		*sets[i] = bits
+	}
+	if spec.dow&(1<<7) != 0 {
+		spec.dow = spec.dow&^(1<<7) | 1
+	}
+	spec.domAny = strings.HasPrefix(fields[2], "*")
+	spec.dowAny = strings.HasPrefix(fields[4], "*")
+	return spec, nil
+}
+
+// parse returns the set of values s selects.
+func (f cronField) parse(s string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		span, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepText, f.name)
+			}
+			step = n
+		}
+		lo, hi := f.min, f.max
+		if span != "*" {
+			from, to, isRange := strings.Cut(span, "-")
+			var err error
+			if lo, err = f.value(from); err != nil {
+				return 0, err
+			}
+			switch {
+			case isRange:
+				if hi, err = f.value(to); err != nil {
+					return 0, err
+				}
+			case !hasStep:
+				hi = lo
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q in %s", span, f.name)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// value parses one number or name of the field.
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("invalid %s %q (%d-%d)", f.name, s, f.min, f.max)
+	}
+	return n, nil
+}
+
+func (c cronSpec) String() string { return c.expr }
+
+// dayMatches reports whether t's day is one the expression runs on.
+func (c cronSpec) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<int(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// next returns the first moment after t the expression matches, in t's
+// time zone, or the zero time if there is none within five years (such
+// as February 30th).
+func (c cronSpec) next(t time.Time) time.Time {
+	limit := t.AddDate(5, 0, 0)
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	for t.Before(limit) {
+		y, mo, d := t.Date()
+		var n time.Time
+		switch {
+		case c.month&(1<<int(mo)) == 0:
+			n = time.Date(y, mo+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			n = time.Date(y, mo, d+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<t.Hour()) == 0:
+			n = time.Date(y, mo, d, t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<t.Minute()) == 0:
+			n = t.Add(time.Minute)
+		default:
+			return t
+		}
+		if !n.After(t) { // a daylight saving change repeated the hour
+			n = t.Add(time.Minute)
+		}
+		t = n
+	}
+	return time.Time{}
+}
+
+// last returns the latest moment in (after, now] the expression matches,
+// in now's time zone, or the zero time if none did. Only the week before
+// now is looked at.
+func (c cronSpec) last(after, now time.Time) time.Time {
+	if earliest := now.AddDate(0, 0, -7); after.Before(earliest) {
+		after = earliest
+	}
+	var latest time.Time
+	for t := c.next(after.In(now.Location())); !t.IsZero() && !t.After(now); t = c.next(t) {
+		latest = t
+	}
+	return latest
+}
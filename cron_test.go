@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	for expr, want := range map[string]cronSpec{
+		"*/15 9-17 * * mon-fri": {minute: 1 | 1<<15 | 1<<30 | 1<<45, hour: 0x3fe00, dom: 0xfffffffe, month: 0x1ffe, dow: 0x3e, domAny: true},
+		"0 0 1,15 jan,jul 7":    {minute: 1, hour: 1, dom: 1<<1 | 1<<15, month: 1<<1 | 1<<7, dow: 1},
+		"@weekly":               {minute: 1, hour: 1, dom: 0xfffffffe, month: 0x1ffe, dow: 1, domAny: true},
+		"5/20 * * * *":          {minute: 1<<5 | 1<<25 | 1<<45, hour: 0xffffff, dom: 0xfffffffe, month: 0x1ffe, dow: 0xff &^ 0x80, domAny: true, dowAny: true},
+	} {
+		got, err := parseCron(expr)
+		want.expr = expr
+		if err != nil || got != want {
+			t.Errorf("parseCron(%q) = %+v, %v; want %+v", expr, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "@often", "* * * foo *"} {
+		if _, err := parseCron(bad); err == nil {
+			t.Errorf("parseCron(%q) should fail", bad)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC) // October 15th is a Thursday
+	}
+	for _, c := range []struct {
+		expr       string
+		from, want time.Time
+	}{
+		{"*/15 * * * *", at(10, 15, 12, 7), at(10, 15, 12, 15)},
+		{"*/15 * * * *", at(10, 15, 12, 15), at(10, 15, 12, 30)},
+		{"0 2 * * *", at(10, 15, 12, 0), at(10, 16, 2, 0)},
+		{"0 9 * * mon-fri", at(10, 16, 10, 0), at(10, 19, 9, 0)},
+		{"0 0 1 * *", at(10, 15, 0, 0), at(11, 1, 0, 0)},
+		{"0 0 31 * *", at(11, 1, 0, 0), at(12, 31, 0, 0)},
+		{"0 0 13 * fri", at(10, 15, 0, 0), at(10, 16, 0, 0)}, // either day field
+		{"0 0 30 2 *", at(10, 15, 0, 0), time.Time{}},
+	} {
+		spec, err := parseCron(c.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := spec.next(c.from); !got.Equal(c.want) {
+			t.Errorf("%q next after %s = %s, want %s", c.expr, c.from, got, c.want)
+		}
+	}
+
+	spec, _ := parseCron("0 * * * *")
+	if got := spec.last(at(10, 15, 9, 30), at(10, 15, 12, 30)); !got.Equal(at(10, 15, 12, 0)) {
+		t.Errorf("last = %s, want the latest moment", got)
+	}
+	if got := spec.last(at(10, 15, 12, 0), at(10, 15, 12, 59)); !got.IsZero() {
+		t.Errorf("last = %s, want none: the window excludes its start", got)
+	}
+}
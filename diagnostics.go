@@ -0,0 +1,196 @@
+// diagnostics.go - Collecting what explains a VM that will not start or misbehaves
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// diagnosticsLines is how many lines each diagnostics section keeps.
+const diagnosticsLines = 30
+
+// diagnosticsTimeout bounds each source; a VM with broken networking makes
+// multipass exec hang, and the daemon log can be slow to read.
+const diagnosticsTimeout = 20 * time.Second
+
+// diagSection is one titled block of the Diagnostics tab. note explains an
+// empty or unavailable section.
+type diagSection struct {
+	title string
+	lines []string
+	note  string
+}
+
+// failureRe matches log lines that report a failure.
+var failureRe = regexp.MustCompile(`(?i)\b(fail(ed|ure)?|error|timed out|cannot|unable)\b`)
+
+// failedOps lists vm's failed operations from passgo's history, newest
+// first.
+func failedOps(history []opRecord, vm string, n int) []string {
+	var out []string
+	for i := len(history) - 1; i >= 0 && len(out) < n; i-- {
+		r := history[i]
+		if r.VM != vm || r.Error == "" {
+			continue
+		}
+		line := fmt.Sprintf("%s  %s: %s", r.Time.Local().Format("2006-01-02 15:04"), r.Op, firstLine(r.Error))
+		if r.Trace != "" {
+			line += " (" + r.Trace + ")"
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// failureLines keeps the last n lines that mention vm and report a failure.
+func failureLines(lines []string, vm string, n int) []string {
+	var out []string
+	for _, l := range lines {
+		if strings.Contains(l, vm) && failureRe.MatchString(l) {
+			out = append(out, l)
+		}
+	}
+	if len(out) > n {
+		out = out[len(out)-n:]
+	}
+	return out
+}
+
+// daemonLogSource says where multipassd logs on goos: a command to run, or
+// a file to read.
+func daemonLogSource(goos string) (cmd []string, path string) {
+	switch goos {
+	case "linux":
+		return []string{"journalctl", "--no-pager", "-q", "-n", "5000", "-u", "snap.multipass.multipassd"}, ""
+	case "darwin":
+		return nil, "/Library/Logs/Multipass/multipassd.log"
+	case "windows":
+		return nil, filepath.Join(os.Getenv("ProgramData"), "Multipass", "data", "logs", "multipassd.log")
+	}
+	return nil, ""
+}
+
+// readDaemonLog returns the daemon's recent log lines on this host.
+func readDaemonLog() ([]string, error) {
+	if _, local := mpRunner.(localRunner); !local {
+		return nil, fmt.Errorf("multipass runs via %s; read the daemon log on that machine", mpRunner)
+	}
+	cmd, path := daemonLogSource(runtime.GOOS)
+	if cmd != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, cmd[0], cmd[1:]...).Output() // #nosec G204 -- fixed command
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", strings.Join(cmd, " "), err)
+		}
+		return strings.Split(string(out), "\n"), nil
+	}
+	if path == "" {
+		return nil, fmt.Errorf("no known daemon log on %s", runtime.GOOS)
+	}
+	lines := tailLines(path, 5000)
+	if lines == nil {
+		return nil, fmt.Errorf("cannot read %s", path)
+	}
+	return lines, nil
+}
+
+// guestDiagnosticsScript prints cloud-init's status and the error-level
+// kernel and journal messages of the current boot, each under a ### header.
+const guestDiagnosticsScript = `echo "### cloud-init status"; cloud-init status --long 2>&1 | head -n 30
+echo "### dmesg (warnings and errors)"; sudo dmesg --level=emerg,alert,crit,err,warn 2>&1 | tail -n 30
+echo "### journal (errors this boot)"; sudo journalctl -p err -b --no-pager -q 2>&1 | tail -n 30`
+
+// parseGuestDiagnostics splits the script's output into sections.
+func parseGuestDiagnostics(out string) []diagSection {
+	var sections []diagSection
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if title, ok := strings.CutPrefix(line, "### "); ok {
+			sections = append(sections, diagSection{title: "Guest " + title})
+			continue
+		}
+		if len(sections) > 0 && strings.TrimSpace(line) != "" {
+			s := &sections[len(sections)-1]
+			s.lines = append(s.lines, line)
+		}
+	}
+	for i := range sections {
+		if len(sections[i].lines) == 0 {
+			sections[i].note = "nothing reported"
+		}
+	}
+	return sections
+}
+
+// guestDiagnostics runs the script in a running VM.
+func guestDiagnostics(vmName string) ([]diagSection, error) {
+	args := []string{"exec", vmName, "--", "bash", "-c", guestDiagnosticsScript}
+	ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	if err := mpRunner.run(ctx, args, nil, &stdout, &stderr); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("no answer within %s (networking may be down; try N)", diagnosticsTimeout)
+		}
+		return nil, fmt.Errorf("%w: %s", err, firstLine(strings.TrimSpace(stderr.String())))
+	}
+	return parseGuestDiagnostics(stdout.String()), nil
+}
+
+// collectDiagnostics gathers every section for vmName: passgo's failed
+// operations and log lines, the daemon's errors, and, when the VM is
+// running, what the guest reports.
+func collectDiagnostics(vmName, state string, history []opRecord) []diagSection {
+	ops := diagSection{title: "Failed passgo operations", lines: failedOps(history, vmName, diagnosticsLines)}
+	if len(ops.lines) == 0 {
+		ops.note = "none recorded"
+	}
+
+	passgoLog := diagSection{title: "passgo.log errors"}
+	if dir, err := userConfigDir(); err == nil {
+		passgoLog.lines = failureLines(tailLines(filepath.Join(dir, "passgo.log"), 5000), vmName, diagnosticsLines)
+	}
+	if len(passgoLog.lines) == 0 {
+		passgoLog.note = "none"
+	}
+
+	daemon := diagSection{title: "multipassd errors"}
+	if lines, err := readDaemonLog(); err != nil {
+		daemon.note = err.Error()
+	} else if daemon.lines = failureLines(lines, vmName, diagnosticsLines); len(daemon.lines) == 0 {
+		daemon.note = "none"
+	}
+
+	sections := []diagSection{ops, passgoLog, daemon}
+	if state != "Running" {
+		return append(sections, diagSection{title: "Guest", note: "not running (state " + orDashes(state) + "); start it to read dmesg and the journal"})
+	}
+	guest, err := guestDiagnostics(vmName)
+	if err != nil {
+		return append(sections, diagSection{title: "Guest", note: err.Error()})
+	}
+	return append(sections, guest...)
+}
+
+// diagnosticsResultMsg carries the Diagnostics tab of the info view.
+type diagnosticsResultMsg struct {
+	vmName   string
+	sections []diagSection
+}
+
+// fetchDiagnosticsCmd collects diagnostics in the background.
+func fetchDiagnosticsCmd(vmName, state string, history []opRecord) tea.Cmd {
+	return func() tea.Msg {
+		return diagnosticsResultMsg{vmName: vmName, sections: collectDiagnostics(vmName, state, history)}
+	}
+}
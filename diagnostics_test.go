@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDiagnosticsFilters(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	history := []opRecord{
+		{Time: now, VM: "web", Op: "start", Error: "start failed: timed out\nmore"},
+		{Time: now.Add(time.Minute), VM: "db", Op: "start", Error: "boom"},
+		{Time: now.Add(2 * time.Minute), VM: "web", Op: "stop"},
+		{Time: now.Add(3 * time.Minute), VM: "web", Op: "create", Error: "image not found", Trace: "op-abc123"},
+	}
+	ops := failedOps(history, "web", 10)
+	if len(ops) != 2 || !strings.Contains(ops[0], "create: image not found (op-abc123)") || !strings.HasSuffix(ops[1], "start: start failed: timed out") {
+		t.Errorf("failedOps = %q", ops)
+	}
+
+	log := []string{
+		"[op-1] exec: multipass start web",
+		"[op-1] start web failed: exit status 2",
+		"web: instance is starting",
+		"db: error mounting",
+		"Unable to start web: timed out waiting for response",
+	}
+	got := failureLines(log, "web", 10)
+	if len(got) != 2 || got[0] != log[1] || got[1] != log[4] {
+		t.Errorf("failureLines = %q", got)
+	}
+	if got := failureLines(log, "web", 1); len(got) != 1 || got[0] != log[4] {
+		t.Errorf("failureLines should keep the newest lines, got %q", got)
+	}
+}
+
+func TestDaemonLogSource(t *testing.T) {
+	if cmd, _ := daemonLogSource("linux"); len(cmd) == 0 || cmd[0] != "journalctl" {
+		t.Errorf("linux source = %v", cmd)
+	}
+	if _, path := daemonLogSource("darwin"); !strings.HasSuffix(path, "multipassd.log") {
+		t.Errorf("darwin path = %q", path)
+	}
+	if cmd, path := daemonLogSource("plan9"); cmd != nil || path != "" {
+		t.Error("unknown systems have no daemon log")
+	}
+}
+
+func TestParseGuestDiagnostics(t *testing.T) {
+	out := "### cloud-init status\nstatus: error\n### dmesg (warnings and errors)\n\n### journal (errors this boot)\nsystemd-networkd: DHCP failed\n"
+	s := parseGuestDiagnostics(out)
+	if len(s) != 3 || s[0].title != "Guest cloud-init status" || s[0].lines[0] != "status: error" {
+		t.Fatalf("sections = %+v", s)
+	}
+	if s[1].note != "nothing reported" || len(s[2].lines) != 1 {
+		t.Errorf("sections = %+v", s)
+	}
+}
+
+func TestCollectDiagnostics(t *testing.T) {
+	f := &fakeRunner{outputs: map[string]string{"exec": "### cloud-init status\nstatus: done\n"}}
+	useFakeRunner(t, f)
+	t.Setenv("HOME", t.TempDir())
+
+	sections := collectDiagnostics("web", "Stopped", nil)
+	if len(f.calls) != 0 {
+		t.Errorf("a stopped VM should not be exec'd into: %v", f.calls)
+	}
+	if last := sections[len(sections)-1]; last.title != "Guest" || !strings.Contains(last.note, "not running") {
+		t.Errorf("guest section = %+v", last)
+	}
+	if daemon := sections[2]; !strings.Contains(daemon.note, "via fake") {
+		t.Errorf("non-local runners cannot read the daemon log: %+v", daemon)
+	}
+
+	sections = collectDiagnostics("web", "Running", nil)
+	if last := sections[len(sections)-1]; last.title != "Guest cloud-init status" || last.lines[0] != "status: done" {
+		t.Errorf("guest section = %+v", last)
+	}
+}
+
+func TestInfoDiagnosticsTab(t *testing.T) {
+	m := newInfoModel("web", 100, 40)
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if cmd == nil || !m.diagLoading || m.tab != infoTabDiagnostics {
+		t.Fatal("switching to diagnostics should start collecting")
+	}
+	if !strings.Contains(m.View(), "Collecting logs") {
+		t.Error("the tab should say it is collecting")
+	}
+	m, _ = m.Update(diagnosticsResultMsg{vmName: "web", sections: []diagSection{{title: "multipassd errors", lines: []string{"web failed to start"}}}})
+	if view := m.View(); !strings.Contains(view, "web failed to start") {
+		t.Errorf("diagnostics not shown:\n%s", view)
+	}
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if cmd != nil || m.tab != infoTabDetails {
+		t.Error("switching back should not collect again")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if _, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}); cmd == nil {
+		t.Error("r should collect again")
+	}
+}
@@ -0,0 +1,200 @@
+// docker.go - Docker host VM setup and a host `docker context` pointing at it
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultDockerVM is the instance `D` launches or reuses, unless docker-vm
+// is set in .config.
+const defaultDockerVM = "docker"
+
+// dockerCloudInit installs Docker Engine from the Ubuntu archive and lets the
+// default user talk to it, which `docker context` over SSH relies on.
+const dockerCloudInit = `#cloud-config
+package_update: true
+packages: [docker.io]
+runcmd:
+  - usermod -aG docker ubuntu
+  - systemctl enable --now docker
+`
+
+// dockerVMName returns docker-vm from lookup, or defaultDockerVM.
+func dockerVMName(lookup func(string) (string, bool)) string {
+	if v, ok := lookup("docker-vm"); ok && strings.TrimSpace(v) != "" {
+		return strings.TrimSpace(v)
+	}
+	return defaultDockerVM
+}
+
+// dockerContextName is the host docker context created for vmName.
+func dockerContextName(vmName string) string {
+	return "passgo-" + vmName
+}
+
+// dockerHostResultMsg reports a finished Docker host setup.
+type dockerHostResultMsg struct {
+	vmName  string
+	context string
+	err     error
+}
+
+// dockerHostCmd launches (launch) or starts (start) vmName as needed, then
+// wires up its docker context (inline — stays on table).
+func dockerHostCmd(vmName string, launch, start bool) tea.Cmd {
+	return func() tea.Msg {
+		err := SetupDockerHost(vmName, launch, start)
+		return dockerHostResultMsg{vmName: vmName, context: dockerContextName(vmName), err: err}
+	}
+}
+
+// SetupDockerHost makes vmName a Docker host reachable from the host: it is
+// launched from docker-template (or the built-in template) when launch is
+// set, the ssh-key from .config is authorized for the default user, the VM's
+// host key is added to ~/.ssh/known_hosts, and a docker context
+// ssh://ubuntu@<ip> is created (or updated) and made current.
+func SetupDockerHost(vmName string, launch, start bool) error {
+	keyPath, ok := configValue("ssh-key")
+	if !ok || strings.TrimSpace(keyPath) == "" {
+		return errors.New("set ssh-key in .config to the public key docker should use over SSH")
+	}
+	key, err := os.ReadFile(strings.TrimSpace(keyPath)) // #nosec G304 -- key path from the user's .config
+	if err != nil {
+		return fmt.Errorf("failed to read ssh-key: %w", err)
+	}
+	if _, err := exec.LookPath("docker"); err != nil {
+		return errors.New("the docker CLI is not installed on this host")
+	}
+
+	switch {
+	case launch:
+		if err := launchDockerVM(vmName); err != nil {
+			return err
+		}
+	case start:
+		if _, err := StartVM(vmName); err != nil {
+			return err
+		}
+	}
+
+	authorize := `mkdir -p ~/.ssh && touch ~/.ssh/authorized_keys && (grep -qxF "$PASSGO_KEY" ~/.ssh/authorized_keys || echo "$PASSGO_KEY" >> ~/.ssh/authorized_keys)`
+	if _, err := ExecInVMWith(vmName, execOptions{Env: []string{"PASSGO_KEY=" + strings.TrimSpace(string(key))}}, "bash", "-c", authorize); err != nil {
+		return fmt.Errorf("authorizing ssh-key: %w", err)
+	}
+	if _, err := ExecInVM(vmName, "docker", "version", "--format", "{{.Server.Version}}"); err != nil {
+		return fmt.Errorf("docker is not usable by the default user in %s (is it a Docker host?): %w", vmName, err)
+	}
+
+	info, err := GetVMInfo(vmName)
+	if err != nil {
+		return err
+	}
+	ip, err := vmIPv4(parseVMInfo(info))
+	if err != nil {
+		return err
+	}
+	// The host key comes over multipass exec, so the first SSH connection
+	// does not need to prompt or trust blindly.
+	hostKey, err := ExecInVM(vmName, "cat", "/etc/ssh/ssh_host_ed25519_key.pub")
+	if err != nil {
+		return fmt.Errorf("reading the VM's host key: %w", err)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	if err := addKnownHost(filepath.Join(home, ".ssh", "known_hosts"), ip, hostKey); err != nil {
+		return err
+	}
+	return useDockerContext(dockerContextName(vmName), "ssh://ubuntu@"+ip)
+}
+
+// launchDockerVM launches vmName with the launch defaults and the Docker
+// template: docker-template from .config, or dockerCloudInit.
+func launchDockerVM(vmName string) error {
+	template, ok := configValue("docker-template")
+	template = strings.TrimSpace(template)
+	if !ok || template == "" {
+		path, cleanup, err := writeTempCloudInit([]byte(dockerCloudInit))
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		template = path
+	}
+	rendered, cleanup, err := prepareCloudInit(template)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	d := loadLaunchDefaults(configValue)
+	return launchWithHooks(vmName, launchVMArgs(vmName, d.release, d.cpus, d.memoryMB, d.diskGB, rendered, nil), nil)
+}
+
+// knownHostLine turns a host public key file ("type key comment") into a
+// known_hosts line for ip.
+func knownHostLine(ip, hostKey string) (string, error) {
+	f := strings.Fields(hostKey)
+	if len(f) < 2 {
+		return "", errors.New("unexpected host key format")
+	}
+	return ip + " " + f[0] + " " + f[1], nil
+}
+
+// addKnownHost appends the host key for ip to the known_hosts file at path
+// unless that exact entry is already there.
+func addKnownHost(path, ip, hostKey string) error {
+	line, err := knownHostLine(ip, hostKey)
+	if err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(path) // #nosec G304 -- the user's known_hosts
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for _, l := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(l) == line {
+			return nil
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) // #nosec G304 -- the user's known_hosts
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		line = "\n" + line
+	}
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// useDockerContext creates the context (or points an existing one at host)
+// and makes it the current context.
+func useDockerContext(name, host string) error {
+	verb := "create"
+	if exec.Command("docker", "context", "inspect", name).Run() == nil { // #nosec G204 -- context name built by passgo
+		verb = "update"
+	}
+	for _, args := range [][]string{
+		{"context", verb, name, "--description", "passgo VM", "--docker", "host=" + host},
+		{"context", "use", name},
+	} {
+		if out, err := exec.Command("docker", args...).CombinedOutput(); err != nil { // #nosec G204 -- fixed docker subcommands
+			return fmt.Errorf("docker %s: %v: %s", strings.Join(args[:2], " "), err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
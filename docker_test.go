@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDockerVMName(t *testing.T) {
+	if got := dockerVMName(func(string) (string, bool) { return "", false }); got != defaultDockerVM {
+		t.Fatalf("default: got %q", got)
+	}
+	if got := dockerVMName(func(string) (string, bool) { return " dev-docker ", true }); got != "dev-docker" {
+		t.Fatalf("configured: got %q", got)
+	}
+}
+
+func TestAddKnownHost(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".ssh", "known_hosts")
+	hostKey := "ssh-ed25519 AAAAC3Nza root@docker\n"
+	for i := 0; i < 2; i++ {
+		if err := addKnownHost(path, "10.1.2.3", hostKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "10.1.2.3 ssh-ed25519 AAAAC3Nza\n" {
+		t.Fatalf("known_hosts = %q, want one entry", data)
+	}
+
+	if err := os.WriteFile(path, []byte("other ssh-rsa AAAA"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := addKnownHost(path, "10.1.2.3", hostKey); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(path)
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 {
+		t.Fatalf("entry not appended on its own line: %q", data)
+	}
+
+	if err := addKnownHost(path, "10.1.2.3", "garbage"); err == nil {
+		t.Fatal("expected an error for a malformed host key")
+	}
+}
+
+func TestDockerHostFollowsNamingPolicy(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile(filepath.Join(dir, ".config"), []byte("name-prefix=dev-\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := rootModel{table: newTableModel(), state: newAppState(), currentView: viewTable}
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("D")})
+	m = model.(rootModel)
+	if _, busy := m.table.busyVMs[defaultDockerVM]; busy || len(m.table.vms) != 0 {
+		t.Fatalf("%s breaks the naming policy and should not be launched", defaultDockerVM)
+	}
+	if len(m.table.toasts) == 0 || !strings.Contains(m.table.toasts[len(m.table.toasts)-1].message, "Naming policy") {
+		t.Fatalf("expected a naming policy toast, got %+v", m.table.toasts)
+	}
+}
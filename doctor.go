@@ -0,0 +1,550 @@
+// doctor.go - `passgo doctor`: environment and configuration diagnostics
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// doctorStatus is the outcome of one check.
+type doctorStatus int
+
+const (
+	doctorPass doctorStatus = iota
+	doctorWarn
+	doctorFail
+)
+
+func (s doctorStatus) String() string {
+	switch s {
+	case doctorWarn:
+		return "WARN"
+	case doctorFail:
+		return "FAIL"
+	}
+	return "PASS"
+}
+
+// doctorResult is one line of the doctor report; fix is shown for warnings
+// and failures.
+type doctorResult struct {
+	name   string
+	status doctorStatus
+	detail string
+	fix    string
+}
+
+// minMultipassVersion is the oldest multipass with everything passgo uses
+// (snapshots and `info --snapshots`).
+const minMultipassVersion = "1.13.0"
+
+// doctorGitTimeout bounds the template repo reachability check.
+const doctorGitTimeout = 15 * time.Second
+
+// parseMultipassVersions reads the client and daemon versions from
+// `multipass version` output ("multipass  1.14.0+mac" / "multipassd 1.14.0+mac").
+func parseMultipassVersions(out string) (client, daemon string) {
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "multipass":
+			client = fields[1]
+		case "multipassd":
+			daemon = fields[1]
+		}
+	}
+	return client, daemon
+}
+
+// compareVersions compares the numeric dotted prefixes of a and b
+// ("1.14.0+mac" vs "1.13.0"), returning -1, 0 or 1.
+func compareVersions(a, b string) int {
+	parts := func(v string) []int {
+		v, _, _ = strings.Cut(v, "+")
+		v, _, _ = strings.Cut(v, "-")
+		var out []int
+		for _, p := range strings.Split(v, ".") {
+			n, err := strconv.Atoi(p)
+			if err != nil {
+				break
+			}
+			out = append(out, n)
+		}
+		return out
+	}
+	pa, pb := parts(a), parts(b)
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+// checkMultipassVersions checks the client and daemon agree and are recent enough.
+func checkMultipassVersions(out string) doctorResult {
+	r := doctorResult{name: "Multipass version"}
+	client, daemon := parseMultipassVersions(out)
+	switch {
+	case client == "":
+		r.status, r.detail = doctorWarn, "could not read the version"
+		r.fix = "run `multipass version` to check the installation"
+	case daemon != "" && compareVersions(client, daemon) != 0:
+		r.status, r.detail = doctorWarn, fmt.Sprintf("client %s does not match daemon %s", client, daemon)
+		r.fix = "restart the multipass daemon after upgrading, or reinstall multipass"
+	case compareVersions(client, minMultipassVersion) < 0:
+		r.status, r.detail = doctorFail, fmt.Sprintf("%s is older than %s", client, minMultipassVersion)
+		r.fix = "upgrade multipass (https://multipass.run/install); snapshots need " + minMultipassVersion
+	default:
+		r.detail = client
+	}
+	return r
+}
+
+// checkBridge checks the default bridged network names an existing network.
+func checkBridge(bridge string, nets []NetworkInfo, netsErr error) doctorResult {
+	r := doctorResult{name: "Bridged network"}
+	switch {
+	case bridge == "":
+		r.status, r.detail = doctorWarn, "local.bridged-network is not set"
+		r.fix = "press B in passgo or run `multipass set local.bridged-network=<name>` to use --bridged"
+		return r
+	case netsErr != nil:
+		r.status, r.detail = doctorWarn, fmt.Sprintf("%s (networks unavailable on this driver)", bridge)
+		return r
+	}
+	for _, n := range nets {
+		if n.Name == bridge {
+			r.detail = bridge
+			return r
+		}
+	}
+	r.status, r.detail = doctorFail, fmt.Sprintf("%s is not listed by `multipass networks`", bridge)
+	r.fix = "pick an existing network with B in passgo or `multipass set local.bridged-network=<name>`"
+	return r
+}
+
+// configValidators check the value of each known .config key; nil means
+// any value is accepted.
+var configValidators = map[string]func(string) error{
+	"github-cloud-init-repo": nil,
+	"http-proxy":             nil,
+	"https-proxy":            nil,
+	"no-proxy":               nil,
+	"accessible":             nil,
+	"read-only":              nil,
+	"release-refresh":        nil,
+	"backup-dir":             nil,
+	"backup-paths":           func(v string) error { _, err := parseBackupPaths(v); return err },
+	"default-release":        nil,
+	"default-cpus":           intAtLeast(MinCPUCores),
+	"default-memory":         sizeAtLeast(MinRAMMB, 1),
+	"default-disk":           sizeAtLeast(MinDiskGB*1024, 1024),
+	"default-cloud-init":     fileExists,
+	"ssh-key":                fileExists,
+	"idle-minutes":           intAtLeast(0),
+	"idle-load":              func(v string) error { _, err := strconv.ParseFloat(v, 64); return err },
+	"idle-action":            oneOf("flag", "stop"),
+	"quota-cpus":             intAtLeast(1),
+	"quota-memory":           sizeAtLeast(1, 1),
+	"quota-disk":             sizeAtLeast(1024, 1024),
+	"quota-mode":             oneOf("refuse", "warn"),
+	"disk-alert": func(v string) error {
+		v = strings.TrimSuffix(v, "%")
+		if n, err := strconv.ParseFloat(v, 64); (err == nil && n >= 0 && n <= 100) || strings.EqualFold(v, "off") {
+			return nil
+		}
+		return errors.New("want a percentage from 0 to 100, or off")
+	},
+	"disk-alert-notify": nil,
+	"startup-banner":    nil,
+	"table-group":       oneOf("prefix", "tag", "off"),
+	"stuck-minutes": func(v string) error {
+		if n, err := strconv.Atoi(v); (err == nil && n >= 0) || strings.EqualFold(v, "off") {
+			return nil
+		}
+		return errors.New("want a whole number of minutes, or off")
+	},
+	"snapshots":        func(v string) error { _, err := parseSnapshotSchedule(v); return err },
+	"power":            func(v string) error { _, err := parsePowerSchedule(v); return err },
+	"job":              func(v string) error { _, err := parseCronJob(v); return err },
+	"agent-token":      func(v string) error { _, err := parseAgentToken(v); return err },
+	"hook-pre-launch":  nil,
+	"hook-post-launch": nil,
+	"hook-pre-delete":  nil,
+	"hook-post-stop":   nil,
+	"action":           func(v string) error { _, err := parseCustomAction(v, false); return err },
+	"action-tty":       func(v string) error { _, err := parseCustomAction(v, true); return err },
+	"exec-snippet":     func(v string) error { _, err := parseExecSnippet(v); return err },
+	"timeout-fast":     func(v string) error { _, err := parseCommandTimeout(v); return err },
+	"timeout-medium":   func(v string) error { _, err := parseCommandTimeout(v); return err },
+	"timeout-slow":     func(v string) error { _, err := parseCommandTimeout(v); return err },
+	"event-toasts":     nil,
+	"event-notify":     nil,
+	"event-webhook": func(v string) error {
+		if !strings.HasPrefix(v, "http://") && !strings.HasPrefix(v, "https://") {
+			return errors.New("want an http:// or https:// URL")
+		}
+		return nil
+	},
+	"project-mount-target": func(v string) error {
+		if !strings.HasPrefix(v, "/") {
+			return errors.New("want an absolute path in the VM, e.g. /home/ubuntu/src")
+		}
+		return nil
+	},
+	"adopt-prompt":         nil,
+	"template-verify":      oneOf(templateVerifyOff, templateVerifyWarn, templateVerifyRequire),
+	"template-signing-key": nil,
+	"template-repo-paths":  func(v string) error { _, err := parseRepoPaths(v); return err },
+	"template-ignore":      nil,
+	"template-repo-all":    nil,
+	"reachability": func(v string) error {
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && (n <= 0 || n > 65535) {
+			return errors.New("want on, off or a port from 1 to 65535")
+		}
+		return nil
+	},
+	"name-template":   validateNameTemplate,
+	"name-prefix":     validateNamePrefixes,
+	"name-max-length": intAtLeast(1),
+	"name-forbidden":  nil,
+	"owner-dir":       validateOwnerDir,
+	"owner-only":      nil,
+	"confirm":         oneOf("none", "destructive", "all"),
+	"row-colors":      nil,
+	"usage-warn":      percentValue,
+	"usage-critical":  percentValue,
+	"theme-color":     func(v string) error { _, err := parseThemeColor(v); return err },
+}
+
+func percentValue(v string) error {
+	if _, ok := parsePercent(v); !ok {
+		return errors.New("want a percentage from 1 to 100")
+	}
+	return nil
+}
+
+func intAtLeast(lo int) func(string) error {
+	return func(v string) error {
+		if n, err := strconv.Atoi(v); err != nil || n < lo {
+			return fmt.Errorf("want a whole number of at least %d", lo)
+		}
+		return nil
+	}
+}
+
+func sizeAtLeast(loMB, bareUnitMB int) func(string) error {
+	return func(v string) error {
+		if parseQuotaSizeMB(v, bareUnitMB) < loMB {
+			return fmt.Errorf("want a size such as 2G of at least %dMB", loMB)
+		}
+		return nil
+	}
+}
+
+func oneOf(values ...string) func(string) error {
+	return func(v string) error {
+		for _, want := range values {
+			if strings.EqualFold(v, want) {
+				return nil
+			}
+		}
+		return fmt.Errorf("want one of %s", strings.Join(values, ", "))
+	}
+}
+
+func fileExists(v string) error {
+	_, err := os.Stat(v)
+	return err
+}
+
+// checkConfigEntries validates the entries of the .config at path.
+func checkConfigEntries(path string, entries []configEntry) []doctorResult {
+	var problems []doctorResult
+	for _, e := range entries {
+		validate, known := configValidators[e.key]
+		switch {
+		case strings.HasPrefix(e.key, "secret."):
+			continue
+		case !known:
+			problems = append(problems, doctorResult{
+				name: "Config", status: doctorWarn,
+				detail: fmt.Sprintf("%s: unknown key %q", path, e.key),
+				fix:    "check the spelling against the README; unknown keys are ignored",
+			})
+		case validate != nil:
+			if err := validate(e.value); err != nil {
+				value := e.value
+				if e.key == "agent-token" {
+					value = "…" // the error names the entry; keep the token out of the report
+				}
+				problems = append(problems, doctorResult{
+					name: "Config", status: doctorFail,
+					detail: fmt.Sprintf("%s: %s=%s: %v", path, e.key, value, err),
+					fix:    "fix or remove the line; invalid values fall back to defaults",
+				})
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return []doctorResult{{name: "Config", detail: fmt.Sprintf("%s (%d settings)", path, len(entries))}}
+	}
+	return problems
+}
+
+// runDoctor runs every check against the live environment.
+func runDoctor() []doctorResult {
+	results := checkMultipassInstall()
+
+	repoURL, _ := ReadConfigGithubRepo()
+	gitPath, gitErr := exec.LookPath("git")
+	switch {
+	case gitErr == nil:
+		results = append(results, doctorResult{name: "Git", detail: gitPath})
+	case repoURL != "":
+		results = append(results, doctorResult{
+			name: "Git", status: doctorFail, detail: "git not found on PATH",
+			fix: "install git; it is needed to fetch github-cloud-init-repo templates",
+		})
+	default:
+		results = append(results, doctorResult{
+			name: "Git", status: doctorWarn, detail: "git not found on PATH",
+			fix: "install git to use a template repository",
+		})
+	}
+
+	configPath := ""
+	for _, dir := range appSearchDirs() {
+		if p := filepath.Join(dir, ".config"); fileExists(p) == nil {
+			configPath = p
+			break
+		}
+	}
+	if configPath == "" {
+		results = append(results, doctorResult{
+			name: "Config", status: doctorWarn, detail: "no .config found",
+			fix: "run `passgo -setup` to create ~/.passgo/.config",
+		})
+	} else if entries, err := readConfigEntriesFromFile(configPath); err != nil {
+		results = append(results, doctorResult{
+			name: "Config", status: doctorFail, detail: fmt.Sprintf("%s: %v", configPath, err),
+			fix: "check the file's permissions",
+		})
+	} else {
+		results = append(results, checkConfigEntries(configPath, entries)...)
+	}
+
+	if repoURL != "" && gitErr == nil {
+		results = append(results, checkRepoReachable(repoURL))
+	}
+	if r, ok := checkDaemonProxy(configValue, runtime.GOOS, snapGet); ok {
+		results = append(results, r)
+	}
+	return results
+}
+
+// snapGet reads a snap setting with `snap get`.
+func snapGet(snap, key string) (string, error) {
+	out, err := exec.Command("snap", "get", snap, key).Output() // #nosec G204 -- fixed keys
+	return strings.TrimSpace(string(out)), err
+}
+
+// checkDaemonProxy checks that multipassd, which downloads images itself
+// and does not see passgo's environment, uses the proxy set in .config.
+// Only the snap install on Linux can be checked (through get); ok is false
+// when no proxy is configured.
+func checkDaemonProxy(lookup func(string) (string, bool), goos string, get func(snap, key string) (string, error)) (doctorResult, bool) {
+	want, _ := lookup("https-proxy")
+	key := "proxy.https"
+	if strings.TrimSpace(want) == "" {
+		want, _ = lookup("http-proxy")
+		key = "proxy.http"
+	}
+	want = strings.TrimSpace(want)
+	if want == "" {
+		return doctorResult{}, false
+	}
+	r := doctorResult{name: "Daemon proxy", status: doctorWarn}
+	if goos != "linux" {
+		r.detail = "cannot check the proxy multipassd uses on " + goos + "; image downloads need it"
+		r.fix = "set HTTP_PROXY and HTTPS_PROXY for the multipassd service and restart it"
+		return r, true
+	}
+	r.fix = fmt.Sprintf("sudo snap set system %s=%s", key, want)
+	got, err := get("system", key)
+	switch {
+	case err != nil:
+		r.detail = "could not read " + key + " (is multipass installed from the snap?)"
+	case got == "":
+		r.detail = key + " is not set, so multipassd downloads images without the proxy"
+	case got != want:
+		r.detail = fmt.Sprintf("%s is %s, not %s as in .config", key, got, want)
+	default:
+		r.status, r.detail, r.fix = doctorPass, key+" "+got, ""
+	}
+	return r, true
+}
+
+// checkMultipassInstall checks the binary and daemon, then the version,
+// driver and bridged network, which need a responding daemon.
+func checkMultipassInstall() []doctorResult {
+	check := checkMultipass()
+	binary := check.path
+	if _, local := mpRunner.(localRunner); !local {
+		binary += " (multipass-runner=" + mpRunner.String() + ")"
+	}
+	switch {
+	case check.path == "" && mpRunner.program() != "multipass":
+		return []doctorResult{{
+			name: "Multipass binary", status: doctorFail, detail: mpRunner.program() + " not found on PATH",
+			fix: "install it, or remove multipass-runner=" + mpRunner.String() + " from .config",
+		}}
+	case check.path == "":
+		return []doctorResult{{
+			name: "Multipass binary", status: doctorFail, detail: "multipass not found on PATH",
+			fix: "install multipass from https://multipass.run/install",
+		}}
+	case check.err != nil:
+		return []doctorResult{
+			{name: "Multipass binary", detail: binary},
+			{
+				name: "Multipass daemon", status: doctorFail, detail: "not responding",
+				fix: "start the multipassd service (e.g. `sudo snap restart multipass`) and check `multipass version`",
+			},
+		}
+	}
+	results := []doctorResult{
+		{name: "Multipass binary", detail: binary},
+		{name: "Multipass daemon", detail: "responding"},
+	}
+	if out, err := runMultipassCommand("version"); err == nil {
+		results = append(results, checkMultipassVersions(out))
+	}
+
+	if driver, err := GetDriver(); err != nil || driver == "" {
+		results = append(results, doctorResult{
+			name: "Driver", status: doctorWarn, detail: "could not read local.driver",
+			fix: "run `multipass get local.driver`",
+		})
+	} else {
+		detail := driver
+		if missing := capsFor(driver).missing(); len(missing) > 0 {
+			detail += " (no " + strings.Join(missing, ", ") + ")"
+		}
+		results = append(results, doctorResult{name: "Driver", detail: detail})
+	}
+
+	bridge, _ := GetBridgedNetwork()
+	nets, netsErr := ListNetworks()
+	return append(results, checkBridge(bridge, nets, netsErr))
+}
+
+// checkRepoReachable runs `git ls-remote` against the template repo.
+func checkRepoReachable(repoURL string) doctorResult {
+	r := doctorResult{name: "Template repo"}
+	ctx, cancel := context.WithTimeout(context.Background(), doctorGitTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", "--heads", repoURL) // #nosec G204 -- repo URL from user .config
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		r.status, r.detail = doctorFail, fmt.Sprintf("%s: %s", repoURL, firstLine(msg))
+		r.fix = "check the URL and your network or proxy settings (http-proxy in .config)"
+		return r
+	}
+	r.detail = repoURL
+	return r
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// writeDoctorReport prints results and returns the number of failures.
+// quiet leaves out passing checks; porcelain prints one tab-separated
+// status, check, detail, fix line per result.
+func writeDoctorReport(w io.Writer, results []doctorResult, out cliOutput) int {
+	failures := 0
+	for _, r := range results {
+		if r.status == doctorFail {
+			failures++
+		}
+		switch {
+		case out.porcelain:
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.status, r.name, r.detail, r.fix)
+			continue
+		case out.quiet && r.status == doctorPass:
+			continue
+		}
+		fmt.Fprintf(w, "[%s] %-18s %s\n", r.status, r.name, r.detail)
+		if r.status != doctorPass && r.fix != "" {
+			fmt.Fprintf(w, "       %-18s → %s\n", "", r.fix)
+		}
+	}
+	return failures
+}
+
+const doctorUsage = `Usage: passgo doctor
+
+Checks the multipass installation, daemon, driver and bridged network, git,
+the .config file and the template repository, with suggested fixes.
+Exits non-zero when a check fails.
+
+Flags:
+`
+
+// doctorCommand implements `passgo doctor`.
+func doctorCommand(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, doctorUsage)
+		fs.PrintDefaults()
+	}
+	var out cliOutput
+	out.register(fs, "doctor")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if failures := writeDoctorReport(stdout, runDoctor(), out); failures > 0 {
+		if !out.porcelain {
+			fmt.Fprintf(stdout, "\n%d check(s) failed\n", failures)
+		}
+		return exitFailed
+	}
+	return exitOK
+}
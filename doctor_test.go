@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckMultipassVersions(t *testing.T) {
+	tests := []struct {
+		out  string
+		want doctorStatus
+	}{
+		{"multipass   1.14.0+mac\nmultipassd  1.14.0+mac", doctorPass},
+		{"multipass   1.14.1\nmultipassd  1.13.0", doctorWarn},
+		{"multipass   1.12.2\nmultipassd  1.12.2", doctorFail},
+		{"", doctorWarn},
+	}
+	for _, tt := range tests {
+		if got := checkMultipassVersions(tt.out); got.status != tt.want {
+			t.Errorf("%q: got %v (%s), want %v", tt.out, got.status, got.detail, tt.want)
+		}
+	}
+	if compareVersions("1.10.0", "1.9.9") != 1 || compareVersions("1.13", "1.13.0") != 0 {
+		t.Fatalf("compareVersions must compare numerically")
+	}
+}
+
+func TestCheckBridge(t *testing.T) {
+	nets := []NetworkInfo{{Name: "eth0"}, {Name: "wlan0"}}
+	if r := checkBridge("eth0", nets, nil); r.status != doctorPass {
+		t.Fatalf("existing bridge: %+v", r)
+	}
+	if r := checkBridge("br9", nets, nil); r.status != doctorFail || r.fix == "" {
+		t.Fatalf("missing bridge: %+v", r)
+	}
+	if r := checkBridge("", nets, nil); r.status != doctorWarn {
+		t.Fatalf("unset bridge: %+v", r)
+	}
+	if r := checkBridge("eth0", nil, errors.New("unsupported")); r.status != doctorWarn {
+		t.Fatalf("networks unavailable: %+v", r)
+	}
+}
+
+func TestCheckConfigEntries(t *testing.T) {
+	key := filepath.Join(t.TempDir(), "id.pub")
+	if err := os.WriteFile(key, []byte("k"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	good := []configEntry{
+		{"default-cpus", "2"}, {"default-memory", "2G"}, {"disk-alert", "85%"},
+		{"ssh-key", key}, {"secret.token", "env:TOKEN"},
+		{"snapshots", "{vm: db, every: 24h, keep: 3}"},
+	}
+	if got := checkConfigEntries(".config", good); len(got) != 1 || got[0].status != doctorPass {
+		t.Fatalf("valid config: %+v", got)
+	}
+
+	bad := []configEntry{
+		{"default-cpu", "2"},          // typo
+		{"default-memory", "64"},      // below minimum
+		{"quota-mode", "strict"},      // not an option
+		{"snapshots", "{vm: db}"},     // missing every
+		{"ssh-key", key + ".missing"}, // no such file
+	}
+	got := checkConfigEntries(".config", bad)
+	if len(got) != len(bad) {
+		t.Fatalf("got %d problems, want %d: %+v", len(got), len(bad), got)
+	}
+	if got[0].status != doctorWarn || !strings.Contains(got[0].detail, "default-cpu") {
+		t.Fatalf("unknown key should warn: %+v", got[0])
+	}
+	for _, r := range got[1:] {
+		if r.status != doctorFail {
+			t.Fatalf("invalid value should fail: %+v", r)
+		}
+	}
+}
+
+func TestWriteDoctorReport(t *testing.T) {
+	var buf bytes.Buffer
+	results := []doctorResult{
+		{name: "Git", detail: "/usr/bin/git", fix: "unused"},
+		{name: "Driver", status: doctorWarn, detail: "unknown", fix: "run it"},
+		{name: "Config", status: doctorFail, detail: "bad", fix: "fix it"},
+	}
+	failures := writeDoctorReport(&buf, results, cliOutput{})
+	if failures != 1 {
+		t.Fatalf("failures = %d, want 1", failures)
+	}
+	out := buf.String()
+	if strings.Contains(out, "unused") || !strings.Contains(out, "→ run it") || !strings.Contains(out, "[FAIL] Config") {
+		t.Fatalf("unexpected report:\n%s", out)
+	}
+
+	buf.Reset()
+	writeDoctorReport(&buf, results, cliOutput{quiet: true})
+	if strings.Contains(buf.String(), "Git") || !strings.Contains(buf.String(), "[WARN] Driver") {
+		t.Fatalf("unexpected quiet report:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	writeDoctorReport(&buf, results, cliOutput{porcelain: true})
+	if want := "PASS\tGit\t/usr/bin/git\tunused\nWARN\tDriver\tunknown\trun it\nFAIL\tConfig\tbad\tfix it\n"; buf.String() != want {
+		t.Fatalf("porcelain report = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestCheckDaemonProxy(t *testing.T) {
+	lookup := func(values map[string]string) func(string) (string, bool) {
+		return func(k string) (string, bool) { v, ok := values[k]; return v, ok }
+	}
+	proxy := lookup(map[string]string{"https-proxy": "http://proxy:3128"})
+	snap := func(value string, err error) func(string, string) (string, error) {
+		return func(snap, key string) (string, error) {
+			if snap != "system" || key != "proxy.https" {
+				t.Errorf("snap get %s %s", snap, key)
+			}
+			return value, err
+		}
+	}
+
+	if _, ok := checkDaemonProxy(lookup(nil), "linux", snap("", nil)); ok {
+		t.Error("no proxy configured, nothing to check")
+	}
+	for _, tc := range []struct {
+		name  string
+		goos  string
+		get   func(string, string) (string, error)
+		want  doctorStatus
+		fixed bool
+	}{
+		{"matches", "linux", snap("http://proxy:3128", nil), doctorPass, false},
+		{"unset", "linux", snap("", nil), doctorWarn, true},
+		{"differs", "linux", snap("http://other:8080", nil), doctorWarn, true},
+		{"no snap", "linux", snap("", errors.New("snap: not found")), doctorWarn, true},
+		{"macOS", "darwin", snap("", nil), doctorWarn, false},
+	} {
+		r, ok := checkDaemonProxy(proxy, tc.goos, tc.get)
+		if !ok || r.status != tc.want {
+			t.Errorf("%s: %+v", tc.name, r)
+		}
+		if tc.fixed && r.fix != "sudo snap set system proxy.https=http://proxy:3128" {
+			t.Errorf("%s: fix %q", tc.name, r.fix)
+		}
+	}
+}
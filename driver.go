@@ -0,0 +1,110 @@
+// driver.go - What each multipass driver supports, so the UI can hide the rest
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// driverCaps lists the features that depend on the multipass driver.
+type driverCaps struct {
+	networks     bool // `multipass networks` works, so bridging has a picker
+	nativeMounts bool // `multipass mount --type native`
+	suspend      bool
+	snapshots    bool
+}
+
+// allDriverCaps is assumed until the driver is known, and for drivers not
+// in the matrix: passgo does not hide what it cannot rule out.
+var allDriverCaps = driverCaps{networks: true, nativeMounts: true, suspend: true, snapshots: true}
+
+// driverCapabilities is the matrix, keyed by local.driver.
+var driverCapabilities = map[string]driverCaps{
+	"qemu":       allDriverCaps,
+	"hyperv":     allDriverCaps,
+	"virtualbox": {networks: true, suspend: true, snapshots: true},
+	"lxd":        {nativeMounts: true},
+}
+
+// capsFor returns the capabilities of driver.
+func capsFor(driver string) driverCaps {
+	if caps, ok := driverCapabilities[strings.ToLower(strings.TrimSpace(driver))]; ok {
+		return caps
+	}
+	return allDriverCaps
+}
+
+// mountTypes returns the mount types offered in the mount forms.
+func (c driverCaps) mountTypes() []string {
+	if c.nativeMounts {
+		return mountTypes
+	}
+	return mountTypes[:1]
+}
+
+// missing names the features the driver lacks, for doctor and the help text.
+func (c driverCaps) missing() []string {
+	var out []string
+	for _, f := range []struct {
+		ok   bool
+		name string
+	}{
+		{c.networks, "bridged network picker"},
+		{c.nativeMounts, "native mounts"},
+		{c.suspend, "suspend"},
+		{c.snapshots, "snapshots"},
+	} {
+		if !f.ok {
+			out = append(out, f.name)
+		}
+	}
+	return out
+}
+
+// unsupportedMessage is the error shown when a key asks for feature on a
+// driver that lacks it.
+func unsupportedMessage(driver, feature string) string {
+	return fmt.Sprintf("The %s driver does not support %s.", driver, feature)
+}
+
+// driverInfoMsg carries local.driver and the multipass versions, read when
+// passgo starts. daemon is "" when multipassd did not answer.
+type driverInfoMsg struct {
+	driver         string
+	client, daemon string
+}
+
+// fetchDriverCmd reads the active driver and the versions in the background.
+func fetchDriverCmd() tea.Cmd {
+	return func() tea.Msg {
+		var msg driverInfoMsg
+		if out, err := runMultipassCommand("version"); err == nil {
+			msg.client, msg.daemon = parseMultipassVersions(out)
+		} else if appLogger != nil {
+			appLogger.Printf("could not read the multipass version: %v", err)
+		}
+		driver, err := GetDriver()
+		if err != nil && appLogger != nil {
+			appLogger.Printf("could not read local.driver: %v", err)
+		}
+		msg.driver = driver
+		return msg
+	}
+}
+
+// driverKeyFeatures maps table keys to the feature they need.
+var driverKeyFeatures = map[string]string{
+	"p": "suspend",
+	"n": "snapshots",
+	"m": "snapshots",
+	"S": "snapshots",
+	"B": "bridged network picker",
+}
+
+// lacks reports whether feature (a name from missing) is unsupported.
+func (c driverCaps) lacks(feature string) bool {
+	return slices.Contains(c.missing(), feature)
+}
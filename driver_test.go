@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDriverCaps(t *testing.T) {
+	lxd := capsFor("LXD ")
+	if lxd.networks || lxd.suspend || lxd.snapshots || !lxd.nativeMounts {
+		t.Errorf("lxd caps = %+v", lxd)
+	}
+	if got := strings.Join(lxd.missing(), ", "); got != "bridged network picker, suspend, snapshots" {
+		t.Errorf("lxd missing = %q", got)
+	}
+	if capsFor("") != allDriverCaps || capsFor("something-new") != allDriverCaps {
+		t.Error("unknown drivers should not hide anything")
+	}
+	if got := capsFor("virtualbox").mountTypes(); len(got) != 1 || got[0] != "classic" {
+		t.Errorf("virtualbox mount types = %v", got)
+	}
+	if got := capsFor("qemu").mountTypes(); len(got) != len(mountTypes) {
+		t.Errorf("qemu mount types = %v", got)
+	}
+}
+
+func TestDriverHidesUnsupportedKeys(t *testing.T) {
+	m := rootModel{table: newTableModel()}
+	m.table.width, m.table.height = 160, 40
+	if !strings.Contains(m.table.renderFooter(), "Suspend") {
+		t.Fatal("an unknown driver should list suspend")
+	}
+	next, _ := m.Update(driverInfoMsg{driver: "lxd"})
+	m = next.(rootModel)
+	footer := m.table.renderFooter()
+	for _, hidden := range []string{"Suspend", "Bridge", "Find Snap"} {
+		if strings.Contains(footer, hidden) {
+			t.Errorf("lxd footer should hide %s", hidden)
+		}
+	}
+	if !strings.Contains(footer, "Mount") {
+		t.Error("lxd footer should keep mounts")
+	}
+
+	m.table.vms = []vmData{{info: VMInfo{Name: "web", State: "Running"}}}
+	m.table.filteredVMs = m.table.vms
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if _, busy := next.(rootModel).table.busyVMs["web"]; busy {
+		t.Error("suspend should not start on lxd")
+	}
+}
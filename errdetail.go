@@ -0,0 +1,60 @@
+// errdetail.go - Failed multipass commands, kept whole for the error dialog
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// commandError is a failed multipass command. Its message is the one
+// runMultipassCommand has always returned; the error dialog can expand it
+// to the command line, how long it ran and everything multipass printed.
+type commandError struct {
+	command  string // as logged, with exec environment values redacted
+	err      error
+	stderr   string
+	duration time.Duration
+}
+
+func newCommandError(args []string, err error, stderr string, duration time.Duration) *commandError {
+	return &commandError{command: shellJoin("multipass", redactExecEnv(args)), err: err, stderr: stderr, duration: duration}
+}
+
+func (e *commandError) Error() string {
+	return fmt.Sprintf("command failed: %v\nStderr: %s", e.err, e.stderr)
+}
+
+func (e *commandError) Unwrap() error { return e.err }
+
+// shortError is err on one line: multipass's own message when it printed
+// one, kept with whatever context the error was wrapped in.
+func shortError(err error) string {
+	var ce *commandError
+	if !errors.As(err, &ce) {
+		return firstLine(strings.TrimSpace(err.Error()))
+	}
+	short := firstLine(strings.TrimSpace(ce.stderr))
+	if short == "" {
+		short = ce.err.Error()
+	}
+	return firstLine(strings.Replace(err.Error(), ce.Error(), short, 1))
+}
+
+// errorReport is the plain text copied from the error dialog.
+func errorReport(title string, err error, trace string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s\n", title, shortError(err))
+	var ce *commandError
+	if errors.As(err, &ce) {
+		fmt.Fprintf(&b, "Command: %s\nError: %v\nDuration: %s\n", ce.command, ce.err, ce.duration.Round(time.Millisecond))
+	}
+	if trace != "" {
+		fmt.Fprintf(&b, "Operation: %s\n", trace)
+	}
+	if ce != nil && strings.TrimSpace(ce.stderr) != "" {
+		fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(ce.stderr))
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCommandErrorDetails(t *testing.T) {
+	useFakeRunner(t, &fakeRunner{fail: map[string]error{"exec": errors.New("exit status 1")}})
+	_, err := runMultipassCommand("exec", "web", "--", "env", "TOKEN=s3cret", "make")
+	var ce *commandError
+	if !errors.As(err, &ce) {
+		t.Fatalf("got %T, want a *commandError", err)
+	}
+	if ce.command != "multipass exec web -- env 'TOKEN=***' make" {
+		t.Errorf("command = %q", ce.command)
+	}
+	if err.Error() != "command failed: exit status 1\nStderr: boom\n" {
+		t.Errorf("message changed: %q", err.Error())
+	}
+
+	wrapped := fmt.Errorf("launch web: %w", err)
+	if got := shortError(wrapped); got != "launch web: boom" {
+		t.Errorf("shortError = %q", got)
+	}
+	report := errorReport("Operation Error", wrapped, "op-abc123")
+	for _, want := range []string{"Command: multipass exec web", "Error: exit status 1", "Operation: op-abc123", "\nboom\n"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "s3cret") {
+		t.Error("the report must not contain environment values")
+	}
+}
+
+func TestErrorModelExpands(t *testing.T) {
+	ce := &commandError{command: "multipass launch -n web", err: errors.New("exit status 2"),
+		stderr: strings.Repeat("launch failed: disk full\n", 40)}
+	m := newErrorModelFor("Operation Error", ce, "")
+	m.width, m.height = 100, 30
+	if strings.Count(m.View(), "disk full") != 1 || strings.Contains(m.View(), "multipass launch") {
+		t.Fatal("collapsed, the dialog should show one line of the error")
+	}
+
+	m = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if v := m.View(); !strings.Contains(v, "multipass launch -n web") || !strings.Contains(v, "lines 1-10 of 40") {
+		t.Fatalf("expanded view:\n%s", v)
+	}
+	for range 50 {
+		m = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	if !strings.Contains(m.View(), "lines 31-40 of 40") {
+		t.Errorf("scrolling should stop at the last line:\n%s", m.View())
+	}
+
+	plain := newErrorModelFor("Snapshot Error", errors.New("no such snapshot"), "")
+	if plain.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")}).expanded {
+		t.Error("errors without a command have no details")
+	}
+}
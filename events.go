@@ -0,0 +1,297 @@
+// events.go - VM change detection shared by the TUI, notifications, webhooks and `passgo watch`
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ─── Events ────────────────────────────────────────────────────────────────────
+
+// vmEventKind is what changed about an instance between two VM lists.
+type vmEventKind string
+
+const (
+	vmCreated      vmEventKind = "created"
+	vmDeleted      vmEventKind = "deleted"
+	vmStateChanged vmEventKind = "state"
+	vmIPChanged    vmEventKind = "ip"
+)
+
+// vmEvent is one change. From and To hold the old and new state or IPv4
+// address; created events only have To, deleted events only From.
+type vmEvent struct {
+	Time time.Time   `json:"time"`
+	Kind vmEventKind `json:"kind"`
+	VM   string      `json:"vm"`
+	From string      `json:"from,omitempty"`
+	To   string      `json:"to,omitempty"`
+}
+
+func (e vmEvent) String() string {
+	switch e.Kind {
+	case vmCreated:
+		return fmt.Sprintf("%s created (%s)", e.VM, e.To)
+	case vmDeleted:
+		return e.VM + " deleted"
+	case vmIPChanged:
+		return fmt.Sprintf("%s IP %s → %s", e.VM, orDashes(e.From), orDashes(e.To))
+	}
+	return fmt.Sprintf("%s %s → %s", e.VM, e.From, e.To)
+}
+
+// eventIP normalises an IPv4 column so "--", "N/A" and "" all mean none.
+func eventIP(ip string) string {
+	ip = strings.TrimSpace(ip)
+	if ip == "--" || ip == "N/A" {
+		return ""
+	}
+	return ip
+}
+
+// vmWatcher diffs each VM list against the previous one. The first list only
+// primes it, so starting up does not report every VM as created.
+type vmWatcher struct {
+	prev   map[string]VMInfo
+	primed bool
+}
+
+// observe records vms and returns what changed since the last call, in VM
+// name order.
+func (w *vmWatcher) observe(vms []VMInfo, now time.Time) []vmEvent {
+	next := make(map[string]VMInfo, len(vms))
+	for _, vm := range vms {
+		next[vm.Name] = vm
+	}
+	prev, primed := w.prev, w.primed
+	w.prev, w.primed = next, true
+	if !primed {
+		return nil
+	}
+
+	var events []vmEvent
+	for name, vm := range next {
+		old, existed := prev[name]
+		if !existed {
+			events = append(events, vmEvent{Time: now, Kind: vmCreated, VM: name, To: vm.State})
+			continue
+		}
+		if old.State != vm.State {
+			events = append(events, vmEvent{Time: now, Kind: vmStateChanged, VM: name, From: old.State, To: vm.State})
+		}
+		if from, to := eventIP(old.IPv4), eventIP(vm.IPv4); from != to {
+			events = append(events, vmEvent{Time: now, Kind: vmIPChanged, VM: name, From: from, To: to})
+		}
+	}
+	for name, old := range prev {
+		if _, ok := next[name]; !ok {
+			events = append(events, vmEvent{Time: now, Kind: vmDeleted, VM: name, From: old.State})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].VM < events[j].VM })
+	return events
+}
+
+func vmInfos(vms []vmData) []VMInfo {
+	infos := make([]VMInfo, len(vms))
+	for i, vm := range vms {
+		infos[i] = vm.info
+	}
+	return infos
+}
+
+// ─── Consumers ─────────────────────────────────────────────────────────────────
+
+// eventQuietPeriod is how long after passgo itself ran an operation on a VM
+// its changes count as expected, so they are not toasted or notified.
+const eventQuietPeriod = 15 * time.Second
+
+// eventPolicy says who hears about events, from .config:
+//
+//	event-toasts=false    no toasts in the TUI for changes made outside passgo
+//	event-notify=true     desktop notifications for those changes
+//	event-webhook=URL     POST every event as JSON
+type eventPolicy struct {
+	toasts  bool
+	notify  bool
+	webhook string
+}
+
+// loadEventPolicy reads the policy using lookup (normally configValue).
+func loadEventPolicy(lookup func(string) (string, bool)) eventPolicy {
+	p := eventPolicy{toasts: true}
+	if v, ok := lookup("event-toasts"); ok {
+		p.toasts = parseConfigBool(v)
+	}
+	if v, ok := lookup("event-notify"); ok {
+		p.notify = parseConfigBool(v)
+	}
+	if v, ok := lookup("event-webhook"); ok {
+		p.webhook = strings.TrimSpace(v)
+	}
+	return p
+}
+
+// webhookTimeout bounds each webhook POST.
+const webhookTimeout = 10 * time.Second
+
+// postEventWebhook sends e to url as JSON.
+func postEventWebhook(client *http.Client, url string, e vmEvent) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// eventWebhookCmd posts events in the background; failures are only logged.
+func eventWebhookCmd(url string, events []vmEvent) tea.Cmd {
+	return func() tea.Msg {
+		for _, e := range events {
+			if err := postEventWebhook(http.DefaultClient, url, e); err != nil && appLogger != nil {
+				appLogger.Printf("event webhook: %v", err)
+			}
+		}
+		return nil
+	}
+}
+
+// touchVM notes that passgo just changed name, see eventQuietPeriod.
+func (m *rootModel) touchVM(name string) {
+	if name == "" {
+		return
+	}
+	if m.touched == nil {
+		m.touched = make(map[string]time.Time)
+	}
+	m.touched[name] = time.Now()
+}
+
+// external reports whether an event on name was not caused by passgo.
+func (m *rootModel) external(name string, now time.Time) bool {
+	if _, busy := m.table.busyVMs[name]; busy {
+		return false
+	}
+	return now.Sub(m.touched[name]) > eventQuietPeriod
+}
+
+// handleVMEvents diffs a fresh VM list and hands the changes to each consumer.
+func (m *rootModel) handleVMEvents(vms []vmData, now time.Time) []tea.Cmd {
+	events := m.watcher.observe(vmInfos(vms), now)
+	if len(events) == 0 {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, e := range events {
+		if appLogger != nil {
+			appLogger.Printf("event: %s", e)
+		}
+		if !m.external(e.VM, now) {
+			continue
+		}
+		if m.events.toasts {
+			cmds = append(cmds, m.table.addToast("● "+e.String(), "info"))
+		}
+		if m.events.notify {
+			cmds = append(cmds, desktopNotifyCmd("passgo", e.String()))
+		}
+	}
+	if m.events.webhook != "" {
+		cmds = append(cmds, eventWebhookCmd(m.events.webhook, events))
+	}
+	return cmds
+}
+
+// ─── Watch Mode ────────────────────────────────────────────────────────────────
+
+const watchUsage = `Usage: passgo watch [--json] [--interval 5s]
+
+Polls multipass and prints a line for every VM created, deleted, changing
+state or changing IP address, until interrupted. Events also go to the
+event-webhook in .config, if set.
+
+Flags:
+`
+
+// watchCommand implements `passgo watch`.
+func watchCommand(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, watchUsage)
+		fs.PrintDefaults()
+	}
+	asJSON := fs.Bool("json", false, "print events as JSON lines")
+	interval := fs.Duration("interval", 5*time.Second, "how often to poll multipass")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if *interval <= 0 {
+		fmt.Fprintln(stderr, "passgo watch: --interval must be positive")
+		return exitUsage
+	}
+	policy := loadEventPolicy(configValue)
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	defer signal.Stop(sigs)
+
+	var w vmWatcher
+	enc := json.NewEncoder(stdout)
+	for {
+		now := time.Now()
+		vms, err := doFetchVMList()
+		if err != nil {
+			fmt.Fprintf(stderr, "%s list VMs: %s\n", now.Format(time.DateTime), staleReason(err))
+		} else {
+			for _, e := range w.observe(vmInfos(vms), now) {
+				if *asJSON {
+					_ = enc.Encode(e)
+				} else {
+					fmt.Fprintf(stdout, "%s %s\n", now.Format(time.DateTime), e)
+				}
+				if policy.webhook != "" {
+					if err := postEventWebhook(http.DefaultClient, policy.webhook, e); err != nil {
+						fmt.Fprintf(stderr, "%s webhook: %v\n", now.Format(time.DateTime), err)
+					}
+				}
+			}
+		}
+		select {
+		case <-sigs:
+			return exitOK
+		case <-time.After(*interval):
+		}
+	}
+}
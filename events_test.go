@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVMWatcherObserve(t *testing.T) {
+	var w vmWatcher
+	now := time.Now()
+	first := []VMInfo{
+		{Name: "web", State: "Starting", IPv4: "--"},
+		{Name: "db", State: "Running", IPv4: "10.0.0.5"},
+		{Name: "old", State: "Stopped"},
+	}
+	if events := w.observe(first, now); events != nil {
+		t.Fatalf("first list should only prime the watcher, got %v", events)
+	}
+
+	second := []VMInfo{
+		{Name: "web", State: "Running", IPv4: "10.0.0.7"},
+		{Name: "db", State: "Running", IPv4: "10.0.0.5"},
+		{Name: "new", State: "Starting"},
+	}
+	got := w.observe(second, now)
+	want := []vmEvent{
+		{Time: now, Kind: vmCreated, VM: "new", To: "Starting"},
+		{Time: now, Kind: vmDeleted, VM: "old", From: "Stopped"},
+		{Time: now, Kind: vmStateChanged, VM: "web", From: "Starting", To: "Running"},
+		{Time: now, Kind: vmIPChanged, VM: "web", From: "", To: "10.0.0.7"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("observe = %v\nwant %v", got, want)
+	}
+	if events := w.observe(second, now); len(events) != 0 {
+		t.Errorf("unchanged list produced events: %v", events)
+	}
+}
+
+func TestLoadEventPolicy(t *testing.T) {
+	values := map[string]string{"event-toasts": "off", "event-notify": "yes", "event-webhook": " https://hooks.example/passgo "}
+	got := loadEventPolicy(func(k string) (string, bool) { v, ok := values[k]; return v, ok })
+	want := eventPolicy{toasts: false, notify: true, webhook: "https://hooks.example/passgo"}
+	if got != want {
+		t.Errorf("loadEventPolicy = %+v, want %+v", got, want)
+	}
+	if def := loadEventPolicy(func(string) (string, bool) { return "", false }); !def.toasts || def.notify || def.webhook != "" {
+		t.Errorf("default policy = %+v", def)
+	}
+}
+
+func TestPostEventWebhook(t *testing.T) {
+	var received vmEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer srv.Close()
+
+	e := vmEvent{Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Kind: vmStateChanged, VM: "web", From: "Running", To: "Stopped"}
+	if err := postEventWebhook(srv.Client(), srv.URL, e); err != nil {
+		t.Fatalf("postEventWebhook: %v", err)
+	}
+	if received != e {
+		t.Errorf("webhook received %+v, want %+v", received, e)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if err := postEventWebhook(failing.Client(), failing.URL, e); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("expected a 500 error, got %v", err)
+	}
+}
+
+func TestHandleVMEventsSkipsPassgoOwnChanges(t *testing.T) {
+	m := rootModel{table: newTableModel(), events: eventPolicy{toasts: true}}
+	now := time.Now()
+	m.handleVMEvents([]vmData{{info: VMInfo{Name: "web", State: "Running"}}, {info: VMInfo{Name: "db", State: "Running"}}}, now)
+
+	m.touchVM("web")
+	cmds := m.handleVMEvents([]vmData{{info: VMInfo{Name: "web", State: "Stopped"}}, {info: VMInfo{Name: "db", State: "Stopped"}}}, now)
+	if len(cmds) != 1 || len(m.table.toasts) != 1 || !strings.Contains(m.table.toasts[0].message, "db") {
+		t.Errorf("expected one toast for the external change to db, got %d toasts", len(m.table.toasts))
+	}
+}
@@ -0,0 +1,136 @@
+// export.go - Write the VM table to CSV, Markdown or JSON
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// exportFormats are the formats the export dialog offers, in order.
+var exportFormats = []string{"CSV", "Markdown", "JSON"}
+
+// exportExtension returns the file extension for format.
+func exportExtension(format string) string {
+	switch format {
+	case "Markdown":
+		return ".md"
+	case "JSON":
+		return ".json"
+	}
+	return ".csv"
+}
+
+// exportFileName suggests a file name for an export made at now.
+func exportFileName(format string, now time.Time) string {
+	return "passgo-vms-" + now.Format("20060102-150405") + exportExtension(format)
+}
+
+// exportCell returns the plain value of column i for vm, the text behind
+// what renderRow draws.
+func exportCell(i int, vm VMInfo) string {
+	switch i {
+	case 0:
+		return vm.Name
+	case 1:
+		return vm.State
+	case 2:
+		return vm.Snapshots
+	case 3:
+		return strings.Join(strings.Fields(vm.IPv4), " ")
+	case 4:
+		if vm.Load != "" && vm.Load != "--" {
+			return vm.CPUs + " (load " + strings.Fields(vm.Load)[0] + ")"
+		}
+		return vm.CPUs
+	case 5:
+		return vm.DiskUsage
+	case 6:
+		return vm.MemoryUsage
+	}
+	return ""
+}
+
+// exportTable returns the visible columns and the filtered rows in their
+// current sort order.
+func (m tableModel) exportTable() (headers []string, rows [][]string) {
+	cols := m.computeColumnWidths()
+	var visible []int
+	for i, c := range cols {
+		if !c.hidden {
+			visible = append(visible, i)
+			headers = append(headers, c.title)
+		}
+	}
+	for _, vm := range m.filteredVMs {
+		row := make([]string, len(visible))
+		for j, i := range visible {
+			row[j] = exportCell(i, vm.info)
+			switch i {
+			case m.columnIndex(reachColumn.title):
+				row[j] = m.reachLabel(vm.info.Name)
+			case m.columnIndex(ownerColumn.title):
+				row[j] = m.owners[vm.info.Name]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+// renderExport formats the table in format.
+func renderExport(format string, headers []string, rows [][]string) ([]byte, error) {
+	var b bytes.Buffer
+	switch format {
+	case "CSV":
+		w := csv.NewWriter(&b)
+		_ = w.Write(headers)
+		_ = w.WriteAll(rows) // flushes
+		return b.Bytes(), w.Error()
+
+	case "Markdown":
+		cell := func(s string) string { return strings.ReplaceAll(orDashes(s), "|", `\|`) }
+		b.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+		b.WriteString("|" + strings.Repeat(" --- |", len(headers)) + "\n")
+		for _, row := range rows {
+			cells := make([]string, len(row))
+			for i, v := range row {
+				cells[i] = cell(v)
+			}
+			b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+		return b.Bytes(), nil
+
+	case "JSON":
+		objects := make([]map[string]string, len(rows))
+		for i, row := range rows {
+			objects[i] = make(map[string]string, len(headers))
+			for j, h := range headers {
+				objects[i][strings.ToLower(h)] = row[j]
+			}
+		}
+		out, err := json.MarshalIndent(objects, "", "  ")
+		return append(out, '\n'), err
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// writeExport renders the table and writes it to path, creating parent
+// directories as needed.
+func writeExport(path, format string, headers []string, rows [][]string) error {
+	data, err := renderExport(format, headers, rows)
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, data, 0o600)
+}
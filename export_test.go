@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportTableRespectsFilterSortAndColumns(t *testing.T) {
+	m := newTableModel()
+	m.width = 200
+	m.setVMs([]vmData{
+		{info: VMInfo{Name: "web-2", State: "Stopped", Snapshots: "0", IPv4: "--", CPUs: "2"}},
+		{info: VMInfo{Name: "db", State: "Running", Snapshots: "1", IPv4: "10.0.0.5", CPUs: "4", Load: "0.50 0.40 0.30"}},
+		{info: VMInfo{Name: "web-1", State: "Running", Snapshots: "2", IPv4: "10.0.0.6 192.168.1.9", CPUs: "2"}},
+	})
+	m.filterText = "web"
+	m.applyFilterAndSort()
+
+	headers, rows := m.exportTable()
+	if strings.Join(headers, ",") != "Name,State,Snaps,IPv4,CPU,Disk,Memory" {
+		t.Fatalf("headers = %v", headers)
+	}
+	if len(rows) != 2 || rows[0][0] != "web-1" || rows[1][0] != "web-2" {
+		t.Fatalf("rows = %v, want web-1 then web-2", rows)
+	}
+	if rows[0][3] != "10.0.0.6 192.168.1.9" {
+		t.Errorf("IPv4 cell = %q", rows[0][3])
+	}
+
+	m.width = 40 // narrow terminal hides the low-priority columns
+	if headers, _ := m.exportTable(); len(headers) >= 7 {
+		t.Errorf("expected hidden columns to be left out, got %v", headers)
+	}
+}
+
+func TestRenderExport(t *testing.T) {
+	headers := []string{"Name", "IPv4"}
+	rows := [][]string{{"web", "10.0.0.6"}, {"a|b", ""}}
+
+	csvOut, _ := renderExport("CSV", headers, rows)
+	if string(csvOut) != "Name,IPv4\nweb,10.0.0.6\na|b,\n" {
+		t.Errorf("CSV = %q", csvOut)
+	}
+
+	md, _ := renderExport("Markdown", headers, rows)
+	want := "| Name | IPv4 |\n| --- | --- |\n| web | 10.0.0.6 |\n| a\\|b | -- |\n"
+	if string(md) != want {
+		t.Errorf("Markdown = %q, want %q", md, want)
+	}
+
+	js, _ := renderExport("JSON", headers, rows)
+	var objects []map[string]string
+	if err := json.Unmarshal(js, &objects); err != nil || len(objects) != 2 || objects[0]["ipv4"] != "10.0.0.6" {
+		t.Errorf("JSON = %s (%v)", js, err)
+	}
+
+	if _, err := renderExport("XML", headers, rows); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestExportModelFormatKeepsExtension(t *testing.T) {
+	m := newExportModel(3, 80, 24)
+	m.pathInput.SetValue("report.csv")
+	m.setFormat(1)
+	if got := m.pathInput.Value(); got != "report.md" {
+		t.Errorf("path = %q, want report.md", got)
+	}
+	m.pathInput.SetValue("custom.txt")
+	m.setFormat(2)
+	if got := m.pathInput.Value(); got != "custom.txt" {
+		t.Errorf("a custom extension should be left alone, got %q", got)
+	}
+}
+
+func TestWriteExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports", "vms.csv")
+	if err := writeExport(path, "CSV", []string{"Name"}, [][]string{{"web"}}); err != nil {
+		t.Fatalf("writeExport: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "Name\nweb\n" {
+		t.Errorf("file = %q, %v", data, err)
+	}
+}
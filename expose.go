@@ -0,0 +1,169 @@
+// expose.go - Forwarding host ports to web services in VMs
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// portForward relays connections to a localhost port on the host to a port
+// on a VM's IP. Forwards live in the passgo process, so they end when passgo
+// exits, and the root model closes them when the VM stops.
+type portForward struct {
+	vmName   string
+	vmAddr   string // ip:port in the VM
+	vmPort   int
+	hostPort int
+	listener net.Listener
+	wg       sync.WaitGroup
+}
+
+// url is the local address of the forward.
+func (f *portForward) url() string {
+	return fmt.Sprintf("http://localhost:%d", f.hostPort)
+}
+
+// startPortForward listens on 127.0.0.1:hostPort and relays each connection
+// to ip:vmPort. hostPort 0 picks a free port.
+func startPortForward(vmName, ip string, vmPort, hostPort int) (*portForward, error) {
+	ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(hostPort)))
+	if err != nil {
+		return nil, err
+	}
+	f := &portForward{
+		vmName:   vmName,
+		vmAddr:   net.JoinHostPort(ip, strconv.Itoa(vmPort)),
+		vmPort:   vmPort,
+		hostPort: ln.Addr().(*net.TCPAddr).Port,
+		listener: ln,
+	}
+	f.wg.Add(1)
+	go f.serve()
+	return f, nil
+}
+
+func (f *portForward) serve() {
+	defer f.wg.Done()
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return // listener closed
+		}
+		go f.relay(conn)
+	}
+}
+
+func (f *portForward) relay(conn net.Conn) {
+	defer conn.Close()
+	upstream, err := net.Dial("tcp", f.vmAddr)
+	if err != nil {
+		if appLogger != nil {
+			appLogger.Printf("forward %s -> %s: %v", f.url(), f.vmAddr, err)
+		}
+		return
+	}
+	defer upstream.Close()
+	done := make(chan struct{}, 2)
+	go func() { _, _ = io.Copy(upstream, conn); done <- struct{}{} }()
+	go func() { _, _ = io.Copy(conn, upstream); done <- struct{}{} }()
+	<-done
+}
+
+// close stops accepting connections. Relays already open finish on their own.
+func (f *portForward) close() {
+	_ = f.listener.Close()
+	f.wg.Wait()
+}
+
+// portForwards tracks the open forwards per VM.
+type portForwards map[string][]*portForward
+
+// add records f, replacing any forward of the same VM port.
+func (p portForwards) add(f *portForward) {
+	list := p[f.vmName][:0]
+	for _, old := range p[f.vmName] {
+		if old.vmPort == f.vmPort {
+			old.close()
+			continue
+		}
+		list = append(list, old)
+	}
+	p[f.vmName] = append(list, f)
+}
+
+// remove closes the forward of vmName's vmPort, if any.
+func (p portForwards) remove(vmName string, vmPort int) {
+	list := p[vmName][:0]
+	for _, f := range p[vmName] {
+		if f.vmPort == vmPort {
+			f.close()
+			continue
+		}
+		list = append(list, f)
+	}
+	if len(list) == 0 {
+		delete(p, vmName)
+		return
+	}
+	p[vmName] = list
+}
+
+// closeVM closes all of vmName's forwards and reports how many there were.
+func (p portForwards) closeVM(vmName string) int {
+	n := len(p[vmName])
+	for _, f := range p[vmName] {
+		f.close()
+	}
+	delete(p, vmName)
+	return n
+}
+
+// closeStopped closes the forwards of VMs that are no longer running (or no
+// longer listed) and returns their names, sorted.
+func (p portForwards) closeStopped(vms []vmData) []string {
+	running := make(map[string]bool)
+	for _, vm := range vms {
+		if vm.info.State == "Running" {
+			running[vm.info.Name] = true
+		}
+	}
+	var closed []string
+	for name := range p {
+		if !running[name] {
+			p.closeVM(name)
+			closed = append(closed, name)
+		}
+	}
+	sort.Strings(closed)
+	return closed
+}
+
+// parsePort parses a TCP port; blank is allowed when allowZero is set.
+func parsePort(s string, allowZero bool) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" && allowZero {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 65535 || (n == 0 && !allowZero) {
+		return 0, fmt.Errorf("invalid port %q", s)
+	}
+	return n, nil
+}
+
+// vmIPv4 returns the first address of a VM list row, or an error when it
+// has none yet.
+func vmIPv4(vm VMInfo) (string, error) {
+	ip, _, _ := strings.Cut(vm.IPv4, ",")
+	ip = strings.TrimSpace(ip)
+	if ip == "" || ip == "--" || ip == "N/A" {
+		return "", errors.New("VM '" + vm.Name + "' has no IPv4 address yet")
+	}
+	return ip, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"net"
+	"testing"
+)
+
+func TestPortForwardRelays(t *testing.T) {
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+	go func() {
+		for {
+			conn, err := backend.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				line, _ := bufio.NewReader(conn).ReadString('\n')
+				_, _ = conn.Write([]byte("echo " + line))
+			}()
+		}
+	}()
+
+	vmPort := backend.Addr().(*net.TCPAddr).Port
+	f, err := startPortForward("web", "127.0.0.1", vmPort, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fwds := make(portForwards)
+	fwds.add(f)
+
+	conn, err := net.Dial("tcp", f.listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = conn.Write([]byte("hi\n"))
+	got, _ := bufio.NewReader(conn).ReadString('\n')
+	conn.Close()
+	if got != "echo hi\n" {
+		t.Fatalf("relayed %q", got)
+	}
+
+	if closed := fwds.closeStopped([]vmData{{info: VMInfo{Name: "web", State: "Running"}}}); closed != nil {
+		t.Fatalf("closed forwards of a running VM: %v", closed)
+	}
+	if closed := fwds.closeStopped([]vmData{{info: VMInfo{Name: "web", State: "Stopped"}}}); len(closed) != 1 || closed[0] != "web" {
+		t.Fatalf("closeStopped = %v, want [web]", closed)
+	}
+	if _, err := net.Dial("tcp", f.listener.Addr().String()); err == nil {
+		t.Fatal("forward still listening after the VM stopped")
+	}
+}
+
+func TestParsePort(t *testing.T) {
+	if n, err := parsePort(" 8080 ", false); err != nil || n != 8080 {
+		t.Fatalf("got %d, %v", n, err)
+	}
+	if n, err := parsePort("", true); err != nil || n != 0 {
+		t.Fatalf("blank host port: got %d, %v", n, err)
+	}
+	for _, s := range []string{"", "0", "70000", "http"} {
+		if _, err := parsePort(s, false); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// files.go - Listing VM directories and transferring files for the file browser
+package main
+
+import (
+	"errors"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// remoteEntry is one file or directory in a VM.
+type remoteEntry struct {
+	Name    string
+	Path    string
+	Kind    string // "dir", "file", "link" or "other"
+	Size    int64
+	ModTime time.Time
+}
+
+func (e remoteEntry) isDir() bool { return e.Kind == "dir" }
+
+// listDirScript prints the absolute directory, then one
+// "%F<TAB>%s<TAB>%Y<TAB>%n" stat line per entry. Unreadable entries are
+// skipped rather than failing the whole listing.
+const listDirScript = `cd -- "$1" || exit 1
+pwd
+find . -mindepth 1 -maxdepth 1 -exec stat -c '%F	%s	%Y	%n' {} + 2>/dev/null
+exit 0`
+
+// parseRemoteDir parses listDirScript's output into the absolute directory
+// and its entries, directories first.
+func parseRemoteDir(output string) (string, []remoteEntry, error) {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	dir := strings.TrimSpace(lines[0])
+	if !strings.HasPrefix(dir, "/") {
+		return "", nil, errors.New("unexpected directory listing")
+	}
+	var entries []remoteEntry
+	for _, line := range lines[1:] {
+		f := strings.SplitN(line, "\t", 4)
+		if len(f) != 4 {
+			continue
+		}
+		name := strings.TrimPrefix(f[3], "./")
+		e := remoteEntry{Name: name, Path: path.Join(dir, name), Kind: "other"}
+		switch {
+		case f[0] == "directory":
+			e.Kind = "dir"
+		case f[0] == "symbolic link":
+			e.Kind = "link"
+		case strings.HasSuffix(f[0], "file"):
+			e.Kind = "file"
+		}
+		e.Size, _ = strconv.ParseInt(f[1], 10, 64)
+		if secs, err := strconv.ParseInt(f[2], 10, 64); err == nil {
+			e.ModTime = time.Unix(secs, 0)
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].isDir() != entries[j].isDir() {
+			return entries[i].isDir()
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return dir, entries, nil
+}
+
+// ListRemoteDir lists dir in the VM; relative paths start from the home
+// directory.
+func ListRemoteDir(vmName, dir string) (string, []remoteEntry, error) {
+	output, err := ExecInVM(vmName, "bash", "-c", listDirScript, "passgo-ls", dir)
+	if err != nil {
+		return "", nil, err
+	}
+	return parseRemoteDir(output)
+}
+
+// remoteDirResultMsg carries a directory listing for the file browser.
+type remoteDirResultMsg struct {
+	vmName  string
+	dir     string
+	entries []remoteEntry
+	err     error
+}
+
+// fetchRemoteDirCmd lists dir in the background.
+func fetchRemoteDirCmd(vmName, dir string) tea.Cmd {
+	return func() tea.Msg {
+		abs, entries, err := ListRemoteDir(vmName, dir)
+		return remoteDirResultMsg{vmName: vmName, dir: abs, entries: entries, err: err}
+	}
+}
+
+// fileTransferResultMsg reports a download or upload from the file browser.
+type fileTransferResultMsg struct {
+	vmName string
+	done   string // e.g. "Downloaded syslog to ./syslog"
+	err    error
+}
+
+// downloadFileCmd copies remotePath from the VM to localPath.
+func downloadFileCmd(vmName, remotePath, localPath string) tea.Cmd {
+	return func() tea.Msg {
+		_, err := TransferFromVM(vmName, remotePath, localPath)
+		return fileTransferResultMsg{vmName: vmName, done: "Downloaded " + path.Base(remotePath) + " to " + localPath, err: err}
+	}
+}
+
+// uploadFileCmd copies localPath on the host into remoteDir in the VM.
+func uploadFileCmd(vmName, localPath, remoteDir string) tea.Cmd {
+	return func() tea.Msg {
+		_, err := TransferToVM(vmName, localPath, strings.TrimSuffix(remoteDir, "/")+"/")
+		return fileTransferResultMsg{vmName: vmName, done: "Uploaded " + localPath + " to " + remoteDir, err: err}
+	}
+}
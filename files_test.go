@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseRemoteDir(t *testing.T) {
+	out := "/home/ubuntu\n" +
+		"regular file\t1536\t1700000000\t./notes.txt\n" +
+		"directory\t4096\t1700000000\t./src\n" +
+		"symbolic link\t12\t1700000000\t./latest\n" +
+		"regular empty file\t0\t1700000000\t./.hidden\n" +
+		"garbage line\n"
+	dir, entries, err := parseRemoteDir(out)
+	if err != nil || dir != "/home/ubuntu" {
+		t.Fatalf("dir = %q, err = %v", dir, err)
+	}
+	want := []struct{ name, kind string }{{"src", "dir"}, {".hidden", "file"}, {"latest", "link"}, {"notes.txt", "file"}}
+	if len(entries) != len(want) {
+		t.Fatalf("entries = %+v", entries)
+	}
+	for i, w := range want {
+		if entries[i].Name != w.name || entries[i].Kind != w.kind {
+			t.Errorf("entry %d = %s (%s), want %s (%s)", i, entries[i].Name, entries[i].Kind, w.name, w.kind)
+		}
+	}
+	if entries[3].Path != "/home/ubuntu/notes.txt" || entries[3].Size != 1536 || entries[3].ModTime.Unix() != 1700000000 {
+		t.Errorf("notes.txt = %+v", entries[3])
+	}
+
+	if _, _, err := parseRemoteDir("cd: no such file"); err == nil {
+		t.Error("expected an error for output without a directory line")
+	}
+}
+
+func TestFilesModelNavigation(t *testing.T) {
+	m := newFilesModel(VMInfo{Name: "web"}, false, 100, 40)
+	if m.dir != "." {
+		t.Fatalf("start dir = %q, want the home directory", m.dir)
+	}
+	m, _ = m.Update(remoteDirResultMsg{vmName: "web", dir: "/home/ubuntu", entries: []remoteEntry{
+		{Name: "src", Path: "/home/ubuntu/src", Kind: "dir"},
+		{Name: "log.txt", Path: "/home/ubuntu/log.txt", Kind: "file"},
+	}})
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || !m.loading {
+		t.Fatal("enter on a directory should list it")
+	}
+	m, _ = m.Update(remoteDirResultMsg{vmName: "web", err: errors.New("command failed: exit status 1\nStderr: Permission denied")})
+	if m.dir != "/home/ubuntu" || len(m.entries) != 2 || m.errMsg != "Permission denied" {
+		t.Errorf("a failed listing should keep the old one: dir=%q err=%q", m.dir, m.errMsg)
+	}
+
+	// Going up highlights the directory we came from.
+	m.dir = "/home/ubuntu/src"
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	m, _ = m.Update(remoteDirResultMsg{vmName: "web", dir: "/home/ubuntu", entries: []remoteEntry{
+		{Name: "a", Kind: "dir"}, {Name: "src", Kind: "dir"},
+	}})
+	if m.cursor != 1 {
+		t.Errorf("cursor = %d, want src highlighted", m.cursor)
+	}
+}
+
+func TestFilesModelTransferPrompts(t *testing.T) {
+	m := newFilesModel(VMInfo{Name: "web"}, true, 100, 40)
+	m, _ = m.Update(remoteDirResultMsg{vmName: "web", dir: "/var/log", entries: []remoteEntry{
+		{Name: "syslog", Path: "/var/log/syslog", Kind: "file"},
+	}})
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if m.prompt != filesPromptNone {
+		t.Error("upload should be unavailable in read-only mode")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if m.prompt != filesPromptDownload || m.input.Value() != "syslog" {
+		t.Fatalf("prompt = %d, value = %q", m.prompt, m.input.Value())
+	}
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || !m.transferring || m.prompt != filesPromptNone {
+		t.Error("enter should start the download")
+	}
+}
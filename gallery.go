@@ -0,0 +1,236 @@
+// gallery.go - Curated template gallery from an index.json registry
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// galleryIndex is a template registry, set with template-index in .config
+// as an http(s) URL or a local path:
+//
+//	{"name": "Team templates", "templates": [
+//	  {"name": "web", "title": "Nginx web server", "icon": "🌐",
+//	   "description": "Nginx with TLS from Let's Encrypt",
+//	   "url": "web.yaml", "sha256": "…", "release": "24.04",
+//	   "cpus": 2, "memory": "2G", "disk": "10G", "tags": ["web"]}]}
+//
+// Relative template URLs are resolved against the index location.
+type galleryIndex struct {
+	Name      string         `json:"name"`
+	Templates []galleryEntry `json:"templates"`
+}
+
+// galleryEntry is one curated template.
+type galleryEntry struct {
+	Name        string   `json:"name"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Icon        string   `json:"icon"`
+	URL         string   `json:"url"`
+	SHA256      string   `json:"sha256"`
+	Release     string   `json:"release"`
+	CPUs        int      `json:"cpus"`
+	Memory      string   `json:"memory"` // e.g. "2G"; bare numbers are MB
+	Disk        string   `json:"disk"`   // e.g. "20G"; bare numbers are GB
+	Tags        []string `json:"tags"`
+	Arch        []string `json:"arch"` // architectures the template works on; empty means any
+}
+
+// label is the title, falling back to the name.
+func (e galleryEntry) label() string {
+	if e.Title != "" {
+		return e.Title
+	}
+	return e.Name
+}
+
+// runsOn reports whether the template works on arch ("" when unknown).
+func (e galleryEntry) runsOn(arch string) bool {
+	if arch == "" || len(e.Arch) == 0 {
+		return true
+	}
+	for _, a := range e.Arch {
+		if normalizeArch(a) == arch {
+			return true
+		}
+	}
+	return false
+}
+
+// memoryMB and diskGB return the required sizes, 0 when unset or invalid.
+func (e galleryEntry) memoryMB() int { return parseQuotaSizeMB(e.Memory, 1) }
+func (e galleryEntry) diskGB() int   { return parseQuotaSizeMB(e.Disk, 1024) / 1024 }
+
+// resources summarises the requirements, e.g. "2 CPU · 2048MB · 10GB".
+func (e galleryEntry) resources() string {
+	var parts []string
+	if e.CPUs > 0 {
+		parts = append(parts, fmt.Sprintf("%d CPU", e.CPUs))
+	}
+	if mb := e.memoryMB(); mb > 0 {
+		parts = append(parts, fmt.Sprintf("%dMB", mb))
+	}
+	if gb := e.diskGB(); gb > 0 {
+		parts = append(parts, fmt.Sprintf("%dGB", gb))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// parseGalleryIndex decodes an index and drops entries without a name or URL.
+func parseGalleryIndex(data []byte) (galleryIndex, error) {
+	var idx galleryIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return idx, fmt.Errorf("invalid template index: %w", err)
+	}
+	kept := idx.Templates[:0]
+	for _, e := range idx.Templates {
+		if e.Name != "" && e.URL != "" {
+			kept = append(kept, e)
+		}
+	}
+	idx.Templates = kept
+	return idx, nil
+}
+
+// isHTTPURL reports whether s is an http(s) URL rather than a local path.
+func isHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
+
+// resolveGalleryRef resolves a template URL against the index location.
+func resolveGalleryRef(base, ref string) (string, error) {
+	if isHTTPURL(ref) {
+		return ref, nil
+	}
+	if isHTTPURL(base) {
+		b, err := url.Parse(base)
+		if err != nil {
+			return "", err
+		}
+		r, err := url.Parse(ref)
+		if err != nil {
+			return "", err
+		}
+		return b.ResolveReference(r).String(), nil
+	}
+	if filepath.IsAbs(ref) {
+		return ref, nil
+	}
+	return filepath.Join(filepath.Dir(base), ref), nil
+}
+
+// readGallerySource reads an http(s) URL or a local file.
+func readGallerySource(src string) ([]byte, error) {
+	if !isHTTPURL(src) {
+		return os.ReadFile(src) // #nosec G304 -- path from the user's .config or its index
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(src)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", src, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+}
+
+// loadGalleryIndex reads and parses the index at src.
+func loadGalleryIndex(src string) (galleryIndex, error) {
+	data, err := readGallerySource(src)
+	if err != nil {
+		return galleryIndex{}, err
+	}
+	return parseGalleryIndex(data)
+}
+
+// fetchGalleryTemplate downloads e's template into a new temp directory,
+// checking its sha256 when the index gives one. The caller removes dir.
+func fetchGalleryTemplate(indexSrc string, e galleryEntry) (path, dir string, err error) {
+	src, err := resolveGalleryRef(indexSrc, e.URL)
+	if err != nil {
+		return "", "", err
+	}
+	data, err := readGallerySource(src)
+	if err != nil {
+		return "", "", err
+	}
+	if e.SHA256 != "" {
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), e.SHA256) {
+			return "", "", fmt.Errorf("%s: checksum does not match the index", e.Name)
+		}
+	}
+	if !strings.HasPrefix(strings.TrimSpace(string(data)), "#cloud-config") {
+		return "", "", fmt.Errorf("%s: not a cloud-config file (missing #cloud-config header)", e.Name)
+	}
+	dir, err = os.MkdirTemp("", ownedTempPattern("passgo-gallery-*"))
+	if err != nil {
+		return "", "", err
+	}
+	tempArtifacts.add(dir)
+	path = filepath.Join(dir, filepath.Base(e.Name)+".yaml")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		tempArtifacts.remove(dir)
+		return "", "", err
+	}
+	return path, dir, nil
+}
+
+// errNoTemplateIndex is returned when template-index is not configured.
+var errNoTemplateIndex = errors.New("no template gallery configured: set template-index in .config to an index.json URL or path")
+
+// galleryResultMsg carries a loaded template index.
+type galleryResultMsg struct {
+	source string
+	index  galleryIndex
+	err    error
+}
+
+// fetchGalleryCmd loads the configured template index.
+func fetchGalleryCmd() tea.Cmd {
+	return func() tea.Msg {
+		src, ok := configValue("template-index")
+		src = strings.TrimSpace(src)
+		if !ok || src == "" {
+			return galleryResultMsg{err: errNoTemplateIndex}
+		}
+		idx, err := loadGalleryIndex(src)
+		return galleryResultMsg{source: src, index: idx, err: err}
+	}
+}
+
+// galleryTemplateMsg carries a downloaded gallery template.
+type galleryTemplateMsg struct {
+	source string // index name or location
+	entry  galleryEntry
+	path   string
+	dir    string
+	err    error
+}
+
+// fetchGalleryTemplateCmd downloads e's template in the background.
+func fetchGalleryTemplateCmd(indexSrc, indexName string, e galleryEntry) tea.Cmd {
+	return func() tea.Msg {
+		path, dir, err := fetchGalleryTemplate(indexSrc, e)
+		source := indexName
+		if source == "" {
+			source = indexSrc
+		}
+		return galleryTemplateMsg{source: source, entry: e, path: path, dir: dir, err: err}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGalleryIndex(t *testing.T) {
+	idx, err := parseGalleryIndex([]byte(`{"name": "Team", "templates": [
+		{"name": "web", "title": "Web server", "url": "web.yaml", "cpus": 2, "memory": "2G", "disk": "10G"},
+		{"name": "no-url"},
+		{"url": "no-name.yaml"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.Name != "Team" || len(idx.Templates) != 1 {
+		t.Fatalf("got %+v", idx)
+	}
+	e := idx.Templates[0]
+	if e.memoryMB() != 2048 || e.diskGB() != 10 || e.resources() != "2 CPU · 2048MB · 10GB" {
+		t.Errorf("resources = %q (%d MB, %d GB)", e.resources(), e.memoryMB(), e.diskGB())
+	}
+	if _, err := parseGalleryIndex([]byte("not json")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestResolveGalleryRef(t *testing.T) {
+	tests := []struct{ base, ref, want string }{
+		{"https://example.com/t/index.json", "web.yaml", "https://example.com/t/web.yaml"},
+		{"https://example.com/t/index.json", "/other/web.yaml", "https://example.com/other/web.yaml"},
+		{"https://example.com/t/index.json", "https://cdn.example.com/web.yaml", "https://cdn.example.com/web.yaml"},
+		{filepath.Join("srv", "index.json"), "web.yaml", filepath.Join("srv", "web.yaml")},
+	}
+	for _, tt := range tests {
+		if got, err := resolveGalleryRef(tt.base, tt.ref); err != nil || got != tt.want {
+			t.Errorf("resolveGalleryRef(%q, %q) = %q, %v; want %q", tt.base, tt.ref, got, err, tt.want)
+		}
+	}
+}
+
+func TestFetchGalleryTemplate(t *testing.T) {
+	body := "#cloud-config\npackages: [nginx]\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/t/web.yaml":
+			_, _ = w.Write([]byte(body))
+		case "/t/plain.yaml":
+			_, _ = w.Write([]byte("packages: [nginx]\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	index := srv.URL + "/t/index.json"
+	sum := sha256.Sum256([]byte(body))
+
+	path, dir, err := fetchGalleryTemplate(index, galleryEntry{Name: "web", URL: "web.yaml", SHA256: hex.EncodeToString(sum[:])})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tempArtifacts.remove(dir)
+	if data, _ := os.ReadFile(path); string(data) != body {
+		t.Errorf("downloaded %q", data)
+	}
+
+	if _, _, err := fetchGalleryTemplate(index, galleryEntry{Name: "web", URL: "web.yaml", SHA256: strings.Repeat("0", 64)}); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("expected checksum error, got %v", err)
+	}
+	if _, _, err := fetchGalleryTemplate(index, galleryEntry{Name: "plain", URL: "plain.yaml"}); err == nil {
+		t.Error("expected error for a template without #cloud-config")
+	}
+	if _, _, err := fetchGalleryTemplate(index, galleryEntry{Name: "gone", URL: "gone.yaml"}); err == nil {
+		t.Error("expected error for a missing template")
+	}
+}
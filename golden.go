@@ -0,0 +1,116 @@
+// golden.go - Golden images: VMs promoted to a snapshot that new instances are cloned from
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// goldenImage is a VM promoted to a launch source. New instances are
+// cloned from Source as it was at Snapshot (see CloneFromSnapshot), so the
+// source can keep changing without affecting the image.
+type goldenImage struct {
+	Name     string    `json:"name"`
+	Source   string    `json:"source"`
+	Snapshot string    `json:"snapshot"`
+	Created  time.Time `json:"created"`
+}
+
+// goldenSnapshotName is the snapshot that holds golden image name.
+func goldenSnapshotName(name string) string {
+	return "golden-" + name
+}
+
+// validateGoldenName checks a new golden image name: a valid snapshot
+// name not already used.
+func validateGoldenName(name string, goldens []goldenImage) error {
+	if !instanceNameRe.MatchString(name) {
+		return fmt.Errorf("invalid name %q: use letters, digits and dashes", name)
+	}
+	if slices.ContainsFunc(goldens, func(g goldenImage) bool { return g.Name == name }) {
+		return fmt.Errorf("a golden image named %q already exists", name)
+	}
+	return nil
+}
+
+// PromoteToGolden snapshots the stopped VM vmName as golden image name.
+func PromoteToGolden(vmName, name string, now time.Time) (goldenImage, error) {
+	g := goldenImage{Name: name, Source: vmName, Snapshot: goldenSnapshotName(name), Created: now}
+	if _, err := CreateSnapshot(vmName, g.Snapshot, "passgo golden image "+name); err != nil {
+		return g, fmt.Errorf("snapshotting %s: %w", vmName, err)
+	}
+	return g, nil
+}
+
+// RemoveGolden deletes the snapshot behind g. A snapshot that is already
+// gone, with its source, is not an error.
+func RemoveGolden(g goldenImage, sourceExists bool) error {
+	if !sourceExists {
+		return nil
+	}
+	if _, err := DeleteSnapshot(g.Source, g.Snapshot); err != nil {
+		return fmt.Errorf("deleting snapshot %s.%s: %w", g.Source, g.Snapshot, err)
+	}
+	return nil
+}
+
+// addGolden records g.
+func (s *appState) addGolden(g goldenImage) {
+	s.Goldens = append(s.Goldens, g)
+}
+
+// removeGolden forgets the golden image called name.
+func (s *appState) removeGolden(name string) {
+	s.Goldens = slices.DeleteFunc(s.Goldens, func(g goldenImage) bool { return g.Name == name })
+}
+
+// goldenPromoteRequestMsg asks root to promote vmName as golden image name.
+type goldenPromoteRequestMsg struct {
+	vmName string
+	name   string
+}
+
+// goldenPromotedMsg reports a finished promotion.
+type goldenPromotedMsg struct {
+	golden goldenImage
+	err    error
+}
+
+// promoteGoldenCmd promotes vmName in the background.
+func promoteGoldenCmd(vmName, name string) tea.Cmd {
+	return func() tea.Msg {
+		var g goldenImage
+		_, err := traceOp("promote", []string{vmName}, func() error {
+			var err error
+			g, err = PromoteToGolden(vmName, name, time.Now())
+			return err
+		})
+		return goldenPromotedMsg{golden: g, err: err}
+	}
+}
+
+// goldenRemoveRequestMsg asks root to confirm and remove a golden image.
+type goldenRemoveRequestMsg struct {
+	golden       goldenImage
+	sourceExists bool
+}
+
+// goldenRemovedMsg reports a removed golden image.
+type goldenRemovedMsg struct {
+	name string
+	err  error
+}
+
+// removeGoldenCmd removes g in the background.
+func removeGoldenCmd(g goldenImage, sourceExists bool) tea.Cmd {
+	return func() tea.Msg {
+		return goldenRemovedMsg{name: g.Name, err: RemoveGolden(g, sourceExists)}
+	}
+}
+
+// errGoldenSourceGone is shown when a golden image's source was purged.
+var errGoldenSourceGone = errors.New("its source VM no longer exists, so there is nothing to clone; press d to remove it")
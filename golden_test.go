@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestValidateGoldenName(t *testing.T) {
+	goldens := []goldenImage{{Name: "base"}}
+	for name, ok := range map[string]bool{
+		"web":      true,
+		"base-2":   true,
+		"base":     false,
+		"":         false,
+		"has dots": false,
+		"-lead":    false,
+	} {
+		if err := validateGoldenName(name, goldens); (err == nil) != ok {
+			t.Errorf("validateGoldenName(%q) = %v, want ok=%v", name, err, ok)
+		}
+	}
+}
+
+func TestPromoteAndRemoveGolden(t *testing.T) {
+	f := &fakeRunner{}
+	useFakeRunner(t, f)
+	now := time.Now()
+	g, err := PromoteToGolden("web", "base", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g != (goldenImage{Name: "base", Source: "web", Snapshot: "golden-base", Created: now}) {
+		t.Errorf("golden = %+v", g)
+	}
+	if len(f.calls) != 1 || f.calls[0][0] != "snapshot" || !slices.Contains(f.calls[0], "golden-base") {
+		t.Errorf("promote calls = %v", f.calls)
+	}
+
+	if err := RemoveGolden(g, false); err != nil || len(f.calls) != 1 {
+		t.Errorf("removing with the source gone: %v, calls %v", err, f.calls)
+	}
+	if err := RemoveGolden(g, true); err != nil || len(f.calls) != 2 || f.calls[1][0] != "delete" {
+		t.Errorf("removing: %v, calls %v", err, f.calls)
+	}
+
+	useFakeRunner(t, &fakeRunner{fail: map[string]error{"snapshot": errors.New("exit 2")}})
+	if _, err := PromoteToGolden("web", "base", now); err == nil {
+		t.Error("a failed snapshot should fail the promotion")
+	}
+}
+
+func TestGoldenModelKeys(t *testing.T) {
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	goldens := []goldenImage{{Name: "base", Source: "web", Snapshot: "golden-base"}, {Name: "old", Source: "gone", Snapshot: "golden-old"}}
+	states := map[string]string{"web": "Running", "db": "Stopped"}
+
+	m := newGoldenModel(goldens, "web", "Running", states, 80, 24)
+	if m, _ = m.Update(key("p")); m.naming || m.errMsg == "" {
+		t.Error("p on a running VM should refuse")
+	}
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if req, ok := cmd().(snapCloneRequestMsg); !ok || req.vmName != "web" || req.snapName != "golden-base" {
+		t.Errorf("enter = %#v, want a clone of web.golden-base", cmd())
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil || m.errMsg == "" {
+		t.Error("enter on an image whose source is gone should refuse")
+	}
+	if _, cmd = m.Update(key("d")); cmd == nil {
+		t.Fatal("d should ask to remove")
+	} else if req := cmd().(goldenRemoveRequestMsg); req.golden.Name != "old" || req.sourceExists {
+		t.Errorf("d = %+v", req)
+	}
+
+	m = newGoldenModel(goldens, "db", "Stopped", states, 80, 24)
+	if m, _ = m.Update(key("p")); !m.naming {
+		t.Fatal("p on a stopped VM should ask for a name")
+	}
+	m.input.SetValue("base")
+	if m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil || m.errMsg == "" {
+		t.Error("a taken name should be refused")
+	}
+	m.input.SetValue("db-base")
+	if _, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil {
+		t.Fatal("enter should promote")
+	} else if req := cmd().(goldenPromoteRequestMsg); req != (goldenPromoteRequestMsg{vmName: "db", name: "db-base"}) {
+		t.Errorf("promote request = %+v", req)
+	}
+}
@@ -0,0 +1,386 @@
+// group.go - Grouped table mode: VMs clustered by name prefix or tag under collapsible headers
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Table grouping modes, in the order z cycles through them.
+const (
+	groupNone   = ""
+	groupPrefix = "prefix"
+	groupTag    = "tag"
+)
+
+var groupModes = []string{groupNone, groupPrefix, groupTag}
+
+// Group keys that are not a prefix or tag. They sort after the others.
+const (
+	groupOther   = "\x00other" // no prefix, or no tags
+	groupDeleted = "\x00deleted"
+)
+
+// loadTableGrouping reads table-group (prefix, tag or off) from .config.
+func loadTableGrouping(lookup func(string) (string, bool)) string {
+	v, _ := lookup("table-group")
+	switch v = strings.ToLower(strings.TrimSpace(v)); v {
+	case groupPrefix, groupTag:
+		return v
+	}
+	return groupNone
+}
+
+// namePrefix is the part of a VM name before its first '-' ("web" for
+// "web-1"), or "" when the name has none.
+func namePrefix(name string) string {
+	prefix, _, ok := strings.Cut(name, "-")
+	if !ok || prefix == "" {
+		return ""
+	}
+	return prefix
+}
+
+// groupKeys returns the groups vm belongs to. As in `passgo report`, a VM
+// with several tags is listed under each.
+func (m tableModel) groupKeys(vm vmData) []string {
+	if vm.info.State == "Deleted" {
+		return []string{groupDeleted}
+	}
+	var keys []string
+	switch m.groupBy {
+	case groupPrefix:
+		if p := namePrefix(vm.info.Name); p != "" {
+			keys = []string{p}
+		}
+	case groupTag:
+		keys = m.meta[vm.info.Name].Tags
+	}
+	if len(keys) == 0 {
+		return []string{groupOther}
+	}
+	return keys
+}
+
+// groupLabel is how a group key is shown.
+func (m tableModel) groupLabel(key string) string {
+	switch key {
+	case groupDeleted:
+		return "Deleted"
+	case groupOther:
+		if m.groupBy == groupTag {
+			return "(untagged)"
+		}
+		return "(no prefix)"
+	case "":
+		return ""
+	}
+	if m.groupBy == groupPrefix {
+		return key + "-*"
+	}
+	return key
+}
+
+// tableRow is a line of the grouped table: a group header, or a VM in it.
+type tableRow struct {
+	group string
+	vm    int // index into filteredVMs; -1 for the group header
+}
+
+// buildRows lays filteredVMs out under group headers, in order of group
+// and then of the current sort, leaving out the VMs of collapsed groups.
+func (m *tableModel) buildRows() {
+	m.rows = nil
+	if m.groupBy == groupNone {
+		return
+	}
+	members := make(map[string][]int)
+	var keys []string
+	for i, vm := range m.filteredVMs {
+		for _, key := range m.groupKeys(vm) {
+			if _, ok := members[key]; !ok {
+				keys = append(keys, key)
+			}
+			members[key] = append(members[key], i)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if ra, rb := groupRank(a), groupRank(b); ra != rb {
+			return ra < rb
+		}
+		return compareStringsFold(a, b) < 0
+	})
+	for _, key := range keys {
+		m.rows = append(m.rows, tableRow{group: key, vm: -1})
+		if m.collapsed[key] {
+			continue
+		}
+		for _, i := range members[key] {
+			m.rows = append(m.rows, tableRow{group: key, vm: i})
+		}
+	}
+}
+
+func groupRank(key string) int {
+	switch key {
+	case groupOther:
+		return 1
+	case groupDeleted:
+		return 2
+	}
+	return 0
+}
+
+// rowCount is the number of lines the cursor moves over.
+func (m tableModel) rowCount() int {
+	if m.groupBy == groupNone {
+		return len(m.filteredVMs)
+	}
+	return len(m.rows)
+}
+
+// rowVM returns the VM on line i, or false for a group header.
+func (m tableModel) rowVM(i int) (vmData, bool) {
+	if m.groupBy == groupNone {
+		if i >= 0 && i < len(m.filteredVMs) {
+			return m.filteredVMs[i], true
+		}
+		return vmData{}, false
+	}
+	if i < 0 || i >= len(m.rows) || m.rows[i].vm < 0 {
+		return vmData{}, false
+	}
+	return m.filteredVMs[m.rows[i].vm], true
+}
+
+// selectedGroup returns the group whose header the cursor is on.
+func (m tableModel) selectedGroup() (string, bool) {
+	if m.groupBy == groupNone || m.cursor < 0 || m.cursor >= len(m.rows) || m.rows[m.cursor].vm >= 0 {
+		return "", false
+	}
+	return m.rows[m.cursor].group, true
+}
+
+// selectGroup moves the cursor to the header of group key.
+func (m *tableModel) selectGroup(key string) bool {
+	for i, row := range m.rows {
+		if row.vm < 0 && row.group == key {
+			m.cursor = i
+			return true
+		}
+	}
+	return false
+}
+
+// groupVMs returns the VMs in group key, collapsed or not.
+func (m tableModel) groupVMs(key string) []vmData {
+	var vms []vmData
+	for _, vm := range m.filteredVMs {
+		for _, k := range m.groupKeys(vm) {
+			if k == key {
+				vms = append(vms, vm)
+				break
+			}
+		}
+	}
+	return vms
+}
+
+// groupNames returns the names of the VMs in group key that are not deleted.
+func (m tableModel) groupNames(key string) []string {
+	var names []string
+	for _, vm := range m.groupVMs(key) {
+		if vm.info.State != "Deleted" {
+			names = append(names, vm.info.Name)
+		}
+	}
+	return names
+}
+
+// cycleGrouping switches to the next grouping mode, keeping the cursor on
+// the selected VM.
+func (m *tableModel) cycleGrouping() {
+	next := groupModes[0]
+	for i, mode := range groupModes {
+		if mode == m.groupBy {
+			next = groupModes[(i+1)%len(groupModes)]
+		}
+	}
+	m.setGrouping(next)
+}
+
+// setGrouping groups the table by mode (groupNone to stop grouping).
+func (m *tableModel) setGrouping(mode string) {
+	selected, ok := m.selectedVM()
+	m.groupBy = mode
+	m.collapsed = nil
+	m.applyFilterAndSort()
+	if !ok || !m.selectVMByName(selected.Name) {
+		m.cursor = 0
+	}
+	m.scrollToCursor()
+}
+
+// toggleGroup collapses or expands group key, leaving the cursor on its
+// header.
+func (m *tableModel) toggleGroup(key string) {
+	if m.collapsed == nil {
+		m.collapsed = make(map[string]bool)
+	}
+	m.collapsed[key] = !m.collapsed[key]
+	m.buildRows()
+	m.selectGroup(key)
+	m.scrollToCursor()
+}
+
+// toggleAllGroups collapses every group, or expands them all when they
+// already are.
+func (m *tableModel) toggleAllGroups() {
+	all := true
+	for _, row := range m.rows {
+		if row.vm < 0 && !m.collapsed[row.group] {
+			all = false
+			break
+		}
+	}
+	key := ""
+	if row := m.cursor; row >= 0 && row < len(m.rows) {
+		key = m.rows[row].group
+	}
+	m.collapsed = make(map[string]bool)
+	for _, row := range m.rows {
+		if row.vm < 0 {
+			m.collapsed[row.group] = !all
+		}
+	}
+	m.buildRows()
+	m.selectGroup(key)
+	m.scrollToCursor()
+}
+
+// regroup rebuilds the tag groups after tags changed, keeping the
+// selected VM.
+func (m *tableModel) regroup() {
+	if m.groupBy != groupTag {
+		return
+	}
+	selected, ok := m.selectedVM()
+	m.buildRows()
+	if !ok || !m.selectVMByName(selected.Name) {
+		m.cursor = min(m.cursor, max(0, m.rowCount()-1))
+	}
+}
+
+// revealVM selects the named VM, expanding its group if it is collapsed,
+// and scrolls it into view.
+func (m *tableModel) revealVM(name string) bool {
+	for _, vm := range m.filteredVMs {
+		if vm.info.Name != name {
+			continue
+		}
+		if key := m.groupKeys(vm)[0]; m.collapsed[key] {
+			delete(m.collapsed, key)
+			m.buildRows()
+		}
+		break
+	}
+	if !m.selectVMByName(name) {
+		return false
+	}
+	m.scrollToCursor()
+	return true
+}
+
+// scrollToCursor adjusts the offset so the cursor row is on screen.
+func (m *tableModel) scrollToCursor() {
+	visible := m.visibleRows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+visible {
+		m.offset = m.cursor - visible + 1
+	}
+}
+
+// groupSummary describes a group for its header: "3 VMs, 2 running".
+func groupSummary(vms []vmData) string {
+	running := 0
+	for _, vm := range vms {
+		if vm.info.State == "Running" {
+			running++
+		}
+	}
+	noun := "VMs"
+	if len(vms) == 1 {
+		noun = "VM"
+	}
+	if running == 0 {
+		return fmt.Sprintf("%d %s", len(vms), noun)
+	}
+	return fmt.Sprintf("%d %s, %d running", len(vms), noun, running)
+}
+
+// renderGroupHeader draws the header line of group key.
+func (m tableModel) renderGroupHeader(key string, cols []tableColumn, selected bool) string {
+	width := 0
+	visibleCols := 0
+	for _, c := range cols {
+		if !c.hidden {
+			width += c.width
+			visibleCols++
+		}
+	}
+	width += max(0, visibleCols-1)
+	arrow := "▾"
+	if m.collapsed[key] {
+		arrow = "▸"
+	}
+	text := truncateToRunes(fmt.Sprintf("%s %s  %s", arrow, m.groupLabel(key), groupSummary(m.groupVMs(key))), max(1, width))
+	style := lipgloss.NewStyle().Foreground(accentLight).Bold(true)
+	if key == groupDeleted {
+		style = lipgloss.NewStyle().Foreground(deletedClr).Italic(true)
+	}
+	if selected {
+		return tableCursorStyle.Render("▎") + tableSelectedCellStyle.Width(width).Bold(true).Render(text)
+	}
+	return " " + style.Render(text)
+}
+
+// describeGroup summarises a group header for the accessible view.
+func (m tableModel) describeGroup(key string) string {
+	state := "expanded"
+	if m.collapsed[key] {
+		state = "collapsed"
+	}
+	return fmt.Sprintf("Group %s, %s, %s", m.groupLabel(key), groupSummary(m.groupVMs(key)), state)
+}
+
+// groupBulk stops, starts or deletes (verb) every VM in group key, through
+// the bulk progress view, after confirming. Deleted VMs go to the trash.
+func (m *rootModel) groupBulk(key, verb string) tea.Cmd {
+	names := m.table.groupNames(key)
+	label := m.table.groupLabel(key)
+	if len(names) == 0 {
+		return m.table.addToast("No VMs to "+verb+" in "+label, "info")
+	}
+	var cmd tea.Cmd
+	switch verb {
+	case "stop":
+		cmd = stopAllVMsCmd(names)
+	case "delete":
+		cmd = deleteVMsCmd(names)
+	default:
+		cmd = startAllVMsCmd(names)
+	}
+	title := strings.ToUpper(verb[:1]) + verb[1:]
+	if others := m.othersVMs(names); len(others) > 0 {
+		return m.overrideOwner(fmt.Sprintf("%s the %d VMs in %s, including other users'?", title, len(names), label),
+			"override", others, cmd, pendingRowOp{}, viewTable)
+	}
+	return m.confirmFirst(true, newConfirmModel(fmt.Sprintf("%s the %d VMs in %s?", title, len(names), label)), cmd, viewTable)
+}
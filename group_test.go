@@ -0,0 +1,136 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func groupedTable(mode string) tableModel {
+	m := newTableModel()
+	m.width, m.height = 120, 40
+	m.meta = map[string]vmMeta{
+		"web-1": {Tags: []string{"prod"}},
+		"db-1":  {Tags: []string{"prod", "data"}},
+	}
+	m.groupBy = mode
+	m.setVMs([]vmData{
+		{info: VMInfo{Name: "web-1", State: "Running"}},
+		{info: VMInfo{Name: "db-1", State: "Stopped"}},
+		{info: VMInfo{Name: "web-2", State: "Stopped"}},
+		{info: VMInfo{Name: "scratch", State: "Running"}},
+		{info: VMInfo{Name: "web-old", State: "Deleted"}},
+	})
+	return m
+}
+
+// rowLabels lists the rows as "[group]" headers and VM names.
+func rowLabels(m tableModel) []string {
+	var out []string
+	for i := range m.rowCount() {
+		if vm, ok := m.rowVM(i); ok {
+			out = append(out, vm.info.Name)
+		} else {
+			out = append(out, "["+m.groupLabel(m.rows[i].group)+"]")
+		}
+	}
+	return out
+}
+
+func TestBuildRowsByPrefix(t *testing.T) {
+	m := groupedTable(groupPrefix)
+	want := []string{"[db-*]", "db-1", "[web-*]", "web-1", "web-2", "[(no prefix)]", "scratch", "[Deleted]", "web-old"}
+	if got := rowLabels(m); !slices.Equal(got, want) {
+		t.Fatalf("rows = %v, want %v", got, want)
+	}
+
+	m.cursor = 2
+	if _, ok := m.selectedVM(); ok {
+		t.Fatal("a group header should not select a VM")
+	}
+	m.toggleGroup("web")
+	want = []string{"[db-*]", "db-1", "[web-*]", "[(no prefix)]", "scratch", "[Deleted]", "web-old"}
+	if got := rowLabels(m); !slices.Equal(got, want) {
+		t.Fatalf("collapsed rows = %v, want %v", got, want)
+	}
+	if key, ok := m.selectedGroup(); !ok || key != "web" {
+		t.Fatalf("cursor should stay on the web header, got %q", key)
+	}
+	if names := m.groupNames("web"); !slices.Equal(names, []string{"web-1", "web-2"}) {
+		t.Fatalf("groupNames = %v", names)
+	}
+
+	if !m.revealVM("web-2") || m.collapsed["web"] {
+		t.Fatal("revealing a VM should expand its group")
+	}
+	if vm, _ := m.selectedVM(); vm.Name != "web-2" {
+		t.Fatalf("selected %q, want web-2", vm.Name)
+	}
+}
+
+func TestBuildRowsByTag(t *testing.T) {
+	m := groupedTable(groupTag)
+	want := []string{"[data]", "db-1", "[prod]", "db-1", "web-1", "[(untagged)]", "scratch", "web-2", "[Deleted]", "web-old"}
+	if got := rowLabels(m); !slices.Equal(got, want) {
+		t.Fatalf("rows = %v, want %v", got, want)
+	}
+}
+
+func TestGroupingKeepsSelection(t *testing.T) {
+	m := groupedTable(groupNone)
+	if m.rows != nil || m.rowCount() != 5 {
+		t.Fatalf("an ungrouped table has no group rows: %v", m.rows)
+	}
+	m.selectVMByName("web-2")
+	m.cycleGrouping()
+	if m.groupBy != groupPrefix {
+		t.Fatalf("groupBy = %q", m.groupBy)
+	}
+	if vm, _ := m.selectedVM(); vm.Name != "web-2" {
+		t.Fatalf("selected %q after grouping, want web-2", vm.Name)
+	}
+
+	// A refresh keeps the cursor on a selected header.
+	m.selectGroup(groupOther)
+	m.setVMs([]vmData{
+		{info: VMInfo{Name: "api-1", State: "Running"}},
+		{info: VMInfo{Name: "web-1", State: "Running"}},
+		{info: VMInfo{Name: "db-1", State: "Stopped"}},
+		{info: VMInfo{Name: "web-2", State: "Stopped"}},
+		{info: VMInfo{Name: "scratch", State: "Running"}},
+	})
+	if key, ok := m.selectedGroup(); !ok || key != groupOther {
+		t.Fatalf("selected group = %q, %v", key, ok)
+	}
+
+	m.toggleAllGroups()
+	if got := rowLabels(m); !slices.Equal(got, []string{"[api-*]", "[db-*]", "[web-*]", "[(no prefix)]"}) {
+		t.Fatalf("all collapsed = %v", got)
+	}
+	m.toggleAllGroups()
+	if m.rowCount() != 9 {
+		t.Fatalf("all expanded = %v", rowLabels(m))
+	}
+	if !strings.Contains(m.View(), "web-*  2 VMs, 1 running") {
+		t.Fatal("the view should show the web group header")
+	}
+}
+
+func TestGroupBulk(t *testing.T) {
+	m := rootModel{table: groupedTable(groupPrefix)}
+	m.groupBulk("web", "stop")
+	if m.currentView != viewConfirm || m.confirm.question != "Stop the 2 VMs in web-*?" {
+		t.Fatalf("view %v, question %q", m.currentView, m.confirm.question)
+	}
+	if m.pendingCmd == nil {
+		t.Fatal("the bulk stop should wait on the dialog")
+	}
+}
+
+func TestLoadTableGrouping(t *testing.T) {
+	for v, want := range map[string]string{"prefix": groupPrefix, " Tag ": groupTag, "off": groupNone, "owner": groupNone} {
+		if got := loadTableGrouping(func(string) (string, bool) { return v, true }); got != want {
+			t.Errorf("table-group=%q: got %q, want %q", v, got, want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// health.go - One-line startup summary of the multipass host and the instances needing attention
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// hostHealth is what passgo learns about multipass when it starts.
+type hostHealth struct {
+	known  bool   // the driver and versions were read
+	client string // multipass version
+	daemon string // multipassd version; "" when the daemon did not answer
+	driver string
+}
+
+// Levels of the startup banner, which pick its colour.
+const (
+	bannerOK = iota
+	bannerWarn
+	bannerError
+)
+
+// attention counts the instances needing attention and names them once
+// each: EOL releases, disks past the alert threshold (diskFull) and
+// instances multipass reports as Unknown.
+func attention(vms []vmData, diskFull map[string]bool, now time.Time) (eol, disk, unknown int, names []string) {
+	seen := make(map[string]bool)
+	note := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, vm := range vms {
+		name := vm.info.Name
+		if vm.info.State == "Deleted" {
+			continue
+		}
+		if releaseStatusOf(vm.info.Release, now) == releaseEOL {
+			eol++
+			note(name)
+		}
+		if diskFull[name] {
+			disk++
+			note(name)
+		}
+		if vm.info.State == "Unknown" {
+			unknown++
+			note(name)
+		}
+	}
+	return eol, disk, unknown, names
+}
+
+// healthBanner summarises h and, once listed (vms non-nil), the
+// instances: "multipassd 1.14.1 · driver qemu · 6 instance(s) · ⚠ 2 need
+// attention: 1 on an EOL release, 1 Unknown". It returns "" until h is
+// known.
+func healthBanner(h hostHealth, vms []vmData, diskFull map[string]bool, now time.Time) (string, int) {
+	if !h.known {
+		return "", bannerOK
+	}
+	if h.daemon == "" {
+		client := ""
+		if h.client != "" {
+			client = " (client " + h.client + ")"
+		}
+		return "✗ multipassd is not responding" + client + " · run passgo doctor", bannerError
+	}
+	parts := []string{"multipassd " + h.daemon}
+	if h.client != "" && compareVersions(h.client, h.daemon) != 0 {
+		parts[0] += " (client " + h.client + ")"
+	}
+	driver := h.driver
+	if driver == "" {
+		driver = "unknown"
+	}
+	parts = append(parts, "driver "+driver)
+	if vms == nil {
+		return strings.Join(parts, " · "), bannerOK
+	}
+	live := 0
+	for _, vm := range vms {
+		if vm.info.State != "Deleted" {
+			live++
+		}
+	}
+	parts = append(parts, fmt.Sprintf("%d instance(s)", live))
+	eol, disk, unknown, names := attention(vms, diskFull, now)
+	if len(names) == 0 {
+		return "✓ " + strings.Join(append(parts, "nothing needs attention"), " · "), bannerOK
+	}
+	var why []string
+	for _, c := range []struct {
+		n    int
+		what string
+	}{{eol, "on an EOL release"}, {disk, "disk nearly full"}, {unknown, "Unknown"}} {
+		if c.n > 0 {
+			why = append(why, fmt.Sprintf("%d %s", c.n, c.what))
+		}
+	}
+	parts = append(parts, fmt.Sprintf("⚠ %d need attention: %s", len(names), strings.Join(why, ", ")))
+	return strings.Join(parts, " · "), bannerWarn
+}
+
+// startupBannerEnabled reads startup-banner from .config (on by default).
+func startupBannerEnabled(lookup func(string) (string, bool)) bool {
+	v, ok := lookup("startup-banner")
+	return !ok || parseConfigBool(v)
+}
+
+// updateBanner refreshes the startup banner until it is dismissed.
+func (m *rootModel) updateBanner() {
+	if !m.bannerShown {
+		return
+	}
+	var vms []vmData
+	if !m.table.lastRefresh.IsZero() {
+		vms = m.table.vms
+		if vms == nil {
+			vms = []vmData{}
+		}
+	}
+	m.table.banner, m.table.bannerLevel = healthBanner(m.health, vms, m.table.diskFull, time.Now())
+}
+
+// dismissBanner hides the startup banner for the rest of the session.
+func (m *rootModel) dismissBanner() {
+	m.bannerShown = false
+	m.table.banner = ""
+}
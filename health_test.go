@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHealthBanner(t *testing.T) {
+	now := time.Date(2026, time.October, 15, 9, 0, 0, 0, time.UTC)
+	host := hostHealth{known: true, client: "1.14.1", daemon: "1.14.1", driver: "qemu"}
+
+	if got, _ := healthBanner(hostHealth{}, nil, nil, now); got != "" {
+		t.Errorf("unknown host: %q", got)
+	}
+	if got, level := healthBanner(host, nil, nil, now); got != "multipassd 1.14.1 · driver qemu" || level != bannerOK {
+		t.Errorf("before the list: %q, %d", got, level)
+	}
+	if got, level := healthBanner(hostHealth{known: true, client: "1.14.1"}, nil, nil, now); !strings.Contains(got, "not responding") || level != bannerError {
+		t.Errorf("daemon down: %q, %d", got, level)
+	}
+	mismatch := host
+	mismatch.client = "1.15.0"
+	if got, _ := healthBanner(mismatch, nil, nil, now); !strings.HasPrefix(got, "multipassd 1.14.1 (client 1.15.0)") {
+		t.Errorf("version mismatch: %q", got)
+	}
+
+	healthy := []vmData{{info: VMInfo{Name: "web", State: "Running", Release: "24.04"}}}
+	if got, level := healthBanner(host, healthy, nil, now); got != "✓ multipassd 1.14.1 · driver qemu · 1 instance(s) · nothing needs attention" || level != bannerOK {
+		t.Errorf("healthy: %q, %d", got, level)
+	}
+
+	vms := []vmData{
+		{info: VMInfo{Name: "old", State: "Running", Release: "18.04"}},
+		{info: VMInfo{Name: "full", State: "Running", Release: "24.04"}},
+		{info: VMInfo{Name: "stuck", State: "Unknown"}},
+		{info: VMInfo{Name: "gone", State: "Deleted", Release: "18.04"}},
+	}
+	got, level := healthBanner(host, vms, map[string]bool{"full": true, "old": true}, now)
+	want := "multipassd 1.14.1 · driver qemu · 3 instance(s) · ⚠ 3 need attention: 1 on an EOL release, 2 disk nearly full, 1 Unknown"
+	if got != want || level != bannerWarn {
+		t.Errorf("attention:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestBannerDismissedByKey(t *testing.T) {
+	m := rootModel{table: newTableModel(), currentView: viewTable, bannerShown: true}
+	m.health = hostHealth{known: true, daemon: "1.14.1", driver: "qemu"}
+	m.updateBanner()
+	if m.table.banner == "" {
+		t.Fatal("the banner should show once the host is known")
+	}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = next.(rootModel)
+	if m.table.banner != "" || m.bannerShown {
+		t.Fatal("a key on the table should hide the banner")
+	}
+	m.updateBanner()
+	if m.table.banner != "" {
+		t.Fatal("the banner should stay hidden")
+	}
+}
+
+func TestStartupBannerEnabled(t *testing.T) {
+	lookup := func(v string) func(string) (string, bool) {
+		return func(string) (string, bool) { return v, v != "" }
+	}
+	if !startupBannerEnabled(lookup("")) || startupBannerEnabled(lookup("false")) || !startupBannerEnabled(lookup("on")) {
+		t.Fatal("startup-banner should default to on and honour false")
+	}
+}
@@ -0,0 +1,270 @@
+// hooks.go - Lifecycle hook scripts and custom key-bound actions from .config
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ─── Hooks ─────────────────────────────────────────────────────────────────────
+
+// Hook events, configured in .config as hook-<event>=<command>. A failing
+// pre-* hook cancels the operation; post-* failures are only logged.
+const (
+	hookPreLaunch  = "pre-launch"
+	hookPostLaunch = "post-launch"
+	hookPreDelete  = "pre-delete"
+	hookPostStop   = "post-stop"
+)
+
+// hookTimeout bounds how long a hook may run.
+const hookTimeout = 2 * time.Minute
+
+// hookVM is what a hook is told about the instance, via PASSGO_VM_* variables.
+type hookVM struct {
+	name     string
+	state    string
+	release  string
+	ipv4     string
+	cpus     int
+	memoryMB int
+	diskGB   int
+}
+
+// hookEnv returns the environment variables describing event and vm.
+func hookEnv(event string, vm hookVM) []string {
+	env := []string{
+		"PASSGO_HOOK=" + event,
+		"PASSGO_VM_NAME=" + vm.name,
+		"PASSGO_VM_STATE=" + vm.state,
+		"PASSGO_VM_RELEASE=" + vm.release,
+		"PASSGO_VM_IPV4=" + vm.ipv4,
+	}
+	if vm.cpus > 0 {
+		env = append(env,
+			"PASSGO_VM_CPUS="+strconv.Itoa(vm.cpus),
+			"PASSGO_VM_MEMORY_MB="+strconv.Itoa(vm.memoryMB),
+			"PASSGO_VM_DISK_GB="+strconv.Itoa(vm.diskGB))
+	}
+	return env
+}
+
+// hookVMFromInfo describes an existing instance using `multipass info`.
+func hookVMFromInfo(name string) hookVM {
+	vm := hookVM{name: name}
+	if out, err := GetVMInfo(name); err == nil {
+		info := parseVMInfo(out)
+		vm.state, vm.release, vm.ipv4 = info.State, info.Release, info.IPv4
+		vm.cpus, _ = strconv.Atoi(info.CPUs)
+	}
+	return vm
+}
+
+// launchHookVM describes the instance a `multipass launch` argument list
+// (see launchVMArgs) is about to create.
+func launchHookVM(name string, args []string) hookVM {
+	vm := hookVM{name: name, state: "Creating"}
+	for i := 0; i+1 < len(args); i++ {
+		switch args[i] {
+		case "--cpus":
+			vm.cpus, _ = strconv.Atoi(args[i+1])
+		case "--memory":
+			vm.memoryMB, _ = strconv.Atoi(strings.TrimSuffix(args[i+1], "M"))
+		case "--disk":
+			vm.diskGB, _ = strconv.Atoi(strings.TrimSuffix(args[i+1], "G"))
+		}
+	}
+	if len(args) > 0 && !strings.HasPrefix(args[len(args)-1], "-") {
+		vm.release = args[len(args)-1]
+	}
+	return vm
+}
+
+// shellCommand runs command through the platform shell.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command) // #nosec G204 -- command from the user's own .config
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command) // #nosec G204 -- command from the user's own .config
+}
+
+// runHook runs the hook configured in .config for event, if any. describe
+// is only called when a hook is configured.
+func runHook(event string, describe func() hookVM) error {
+	return runHookFrom(configValue, event, describe)
+}
+
+func runHookFrom(lookup func(string) (string, bool), event string, describe func() hookVM) error {
+	command, ok := lookup("hook-" + event)
+	command = strings.TrimSpace(command)
+	if !ok || command == "" {
+		return nil
+	}
+	vm := describe()
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	cmd := shellCommand(ctx, command)
+	cmd.Env = append(os.Environ(), hookEnv(event, vm)...)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if appLogger != nil {
+		appLogger.Printf("hook %s for %s: %s", event, vm.name, command)
+	}
+	err := cmd.Run()
+	if out := strings.TrimSpace(output.String()); out != "" && appLogger != nil {
+		appLogger.Printf("hook %s output: %s", event, out)
+	}
+	if err != nil {
+		msg := firstLine(strings.TrimSpace(output.String()))
+		if msg == "" {
+			msg = err.Error()
+		}
+		err = fmt.Errorf("%s hook failed: %s", event, msg)
+		if appLogger != nil {
+			appLogger.Printf("%v", err)
+		}
+	}
+	return err
+}
+
+// launchWithHooks runs the pre-launch hook, the launch itself, then the
+// post-launch hook. onLine receives multipass's output as it streams.
+func launchWithHooks(name string, args []string, onLine func(string)) error {
+	if err := runHook(hookPreLaunch, func() hookVM { return launchHookVM(name, args) }); err != nil {
+		return err
+	}
+	if _, err := runMultipassCommandStreaming(onLine, args...); err != nil {
+		return err
+	}
+	_ = runHook(hookPostLaunch, func() hookVM { return hookVMFromInfo(name) })
+	return nil
+}
+
+// ─── Custom Actions ────────────────────────────────────────────────────────────
+
+// customAction is a user command bound to a key on the VM table.
+// Configured in .config, one line per action:
+//
+//	action: o | Open web UI | xdg-open http://{{ip}}:8080
+//	action-tty: t | htop | multipass exec {{name}} -- htop
+//
+// action runs in the background and reports the result in a toast;
+// action-tty hands the terminal to the command, like s (shell) does.
+type customAction struct {
+	key     string
+	label   string
+	command string
+	tty     bool
+}
+
+// reservedTableKey reports whether key is bound by one of the table's
+// built-in shortcuts (tableKeys), which custom actions may not override.
+func reservedTableKey(key string) bool {
+	for _, k := range tableKeys {
+		if strings.Contains(k.keys, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCustomAction parses "KEY | LABEL | COMMAND".
+func parseCustomAction(s string, tty bool) (customAction, error) {
+	parts := strings.SplitN(s, "|", 3)
+	if len(parts) != 3 {
+		return customAction{}, fmt.Errorf("action %q: want KEY | LABEL | COMMAND", s)
+	}
+	a := customAction{
+		key:     strings.TrimSpace(parts[0]),
+		label:   strings.TrimSpace(parts[1]),
+		command: strings.TrimSpace(parts[2]),
+		tty:     tty,
+	}
+	switch {
+	case len([]rune(a.key)) != 1:
+		return a, fmt.Errorf("action %q: key must be a single character", s)
+	case reservedTableKey(a.key):
+		return a, fmt.Errorf("action %q: %s is already a built-in shortcut", s, a.key)
+	case a.command == "":
+		return a, fmt.Errorf("action %q: command is empty", s)
+	}
+	if a.label == "" {
+		a.label = a.command
+	}
+	return a, nil
+}
+
+// loadCustomActions parses the action and action-tty entries. The first
+// action bound to a key wins.
+func loadCustomActions(actions, ttyActions []string) ([]customAction, []error) {
+	var out []customAction
+	var errs []error
+	seen := make(map[string]bool)
+	add := func(values []string, tty bool) {
+		for _, v := range values {
+			a, err := parseCustomAction(v, tty)
+			if err == nil && seen[a.key] {
+				err = fmt.Errorf("action %q: %s is bound twice", v, a.key)
+			}
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			seen[a.key] = true
+			out = append(out, a)
+		}
+	}
+	add(actions, false)
+	add(ttyActions, true)
+	return out, errs
+}
+
+// expand fills the {{name}}, {{ip}}, {{state}} and {{release}} placeholders.
+func (a customAction) expand(vm VMInfo) string {
+	ip := vm.IPv4
+	if f := strings.Fields(ip); len(f) > 0 {
+		ip = f[0]
+	}
+	return strings.NewReplacer(
+		"{{name}}", vm.Name,
+		"{{ip}}", ip,
+		"{{state}}", vm.State,
+		"{{release}}", vm.Release,
+	).Replace(a.command)
+}
+
+// customActionResultMsg reports a background custom action.
+type customActionResultMsg struct {
+	label  string
+	output string
+	err    error
+}
+
+// runCustomActionCmd runs a for vm, in the background or on the terminal.
+func runCustomActionCmd(a customAction, vm VMInfo) tea.Cmd {
+	command := a.expand(vm)
+	if appLogger != nil {
+		appLogger.Printf("action %s on %s: %s", a.label, vm.Name, command)
+	}
+	if a.tty {
+		return tea.ExecProcess(shellCommand(context.Background(), command), func(err error) tea.Msg {
+			return shellFinishedMsg{err: err}
+		})
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+		defer cancel()
+		out, err := shellCommand(ctx, command).CombinedOutput()
+		return customActionResultMsg{label: a.label, output: firstLine(strings.TrimSpace(string(out))), err: err}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLaunchHookVM(t *testing.T) {
+	args := launchVMArgs("web", "24.04", 2, 2048, 20, "/tmp/user-data", nil)
+	want := hookVM{name: "web", state: "Creating", release: "24.04", cpus: 2, memoryMB: 2048, diskGB: 20}
+	if got := launchHookVM("web", args); got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	if got := launchHookVM("q", []string{"launch", "--name", "q", DefaultUbuntuRelease}); got.release != DefaultUbuntuRelease || got.cpus != 0 {
+		t.Fatalf("quick create: %+v", got)
+	}
+}
+
+func TestRunHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	out := filepath.Join(t.TempDir(), "env")
+	config := map[string]string{
+		"hook-pre-launch": `echo "$PASSGO_HOOK $PASSGO_VM_NAME $PASSGO_VM_CPUS" > ` + out,
+		"hook-pre-delete": `echo "protected VM" >&2; exit 1`,
+	}
+	lookup := func(k string) (string, bool) { v, ok := config[k]; return v, ok }
+
+	if err := runHookFrom(lookup, hookPreLaunch, func() hookVM { return hookVM{name: "web", cpus: 2} }); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(out); strings.TrimSpace(string(data)) != "pre-launch web 2" {
+		t.Fatalf("hook saw %q", data)
+	}
+
+	err := runHookFrom(lookup, hookPreDelete, func() hookVM { return hookVM{name: "db"} })
+	if err == nil || !strings.Contains(err.Error(), "protected VM") {
+		t.Fatalf("expected failing hook error with its output, got %v", err)
+	}
+
+	called := false
+	if err := runHookFrom(lookup, hookPostStop, func() hookVM { called = true; return hookVM{} }); err != nil || called {
+		t.Fatalf("unconfigured hook should not run: err=%v described=%v", err, called)
+	}
+}
+
+func TestLoadCustomActions(t *testing.T) {
+	actions, errs := loadCustomActions(
+		[]string{
+			"o | Open web UI | xdg-open http://{{ip}}:8080",
+			"s | Shadow shell | ssh {{ip}}", // built-in key
+			"o | Again | true",              // bound twice
+			"w | missing command",           // too few fields
+		},
+		[]string{"t | htop | multipass exec {{name}} -- htop | less"},
+	)
+	if len(errs) != 3 {
+		t.Fatalf("got %d errors, want 3: %v", len(errs), errs)
+	}
+	want := []customAction{
+		{key: "o", label: "Open web UI", command: "xdg-open http://{{ip}}:8080"},
+		{key: "t", label: "htop", command: "multipass exec {{name}} -- htop | less", tty: true},
+	}
+	if !reflect.DeepEqual(actions, want) {
+		t.Fatalf("got %+v, want %+v", actions, want)
+	}
+
+	vm := VMInfo{Name: "web", IPv4: "10.0.0.5 192.168.1.20", State: "Running"}
+	if got := actions[0].expand(vm); got != "xdg-open http://10.0.0.5:8080" {
+		t.Fatalf("expand = %q", got)
+	}
+}
+
+func TestCustomActionsCannotTakeBuiltinKeys(t *testing.T) {
+	for _, key := range strings.Split("qhvicLC[]p<>dxur!1234567890/fbBsnemESMXFkjgGlJUYKzZRDPTNIa ", "") {
+		if _, err := parseCustomAction(key+" | Mine | true", false); err == nil {
+			t.Errorf("custom action took the built-in key %q", key)
+		}
+	}
+	if _, err := parseCustomAction("o | Mine | true", false); err != nil {
+		t.Errorf("o is free: %v", err)
+	}
+}
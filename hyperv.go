@@ -0,0 +1,106 @@
+// hyperv.go - Creating an external Hyper-V switch for bridged networking on Windows
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// errHyperVNeedsAdmin means New-VMSwitch was refused for lack of rights.
+var errHyperVNeedsAdmin = errors.New("creating a Hyper-V switch needs administrator rights")
+
+// canCreateHyperVSwitch reports whether passgo can create switches here:
+// Windows, the hyperv driver, and multipass on this machine.
+func canCreateHyperVSwitch(goos, driver string, runner multipassRunner) bool {
+	_, local := runner.(localRunner)
+	return goos == "windows" && strings.EqualFold(driver, "hyperv") && local
+}
+
+// hasExternalSwitch reports whether networks include a Hyper-V switch the
+// hyperv driver can bridge to the LAN.
+func hasExternalSwitch(networks []NetworkInfo) bool {
+	for _, n := range networks {
+		if classifyNetwork(n) == netKindHyperVSwitch && bridgeWarning("hyperv", n) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// switchableAdapter reports whether n is a physical adapter an external
+// switch can be bound to.
+func switchableAdapter(n NetworkInfo) bool {
+	kind := classifyNetwork(n)
+	return kind == netKindEthernet || kind == netKindWifi
+}
+
+// hyperVSwitchName is the name passgo gives the switch bound to adapter.
+func hyperVSwitchName(adapter string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\'' || r == '"' {
+			return '-'
+		}
+		return r
+	}, adapter)
+	return "passgo-" + name
+}
+
+// psQuote quotes s as a PowerShell single-quoted string.
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// newVMSwitchScript is the PowerShell that creates an external switch on
+// adapter, keeping the host's own connection through it.
+func newVMSwitchScript(name, adapter string) string {
+	return fmt.Sprintf("$ErrorActionPreference = 'Stop'; New-VMSwitch -Name %s -NetAdapterName %s -AllowManagementOS $true | Out-Null",
+		psQuote(name), psQuote(adapter))
+}
+
+// CreateHyperVSwitch creates an external switch named name on adapter.
+func CreateHyperVSwitch(name, adapter string) error {
+	script := newVMSwitchScript(name, adapter)
+	if appLogger != nil {
+		appLogger.Printf("exec: powershell %s", script)
+	}
+	out, err := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-Command", script).CombinedOutput() // #nosec G204 -- names quoted by psQuote
+	if err == nil {
+		return nil
+	}
+	msg := strings.TrimSpace(string(out))
+	if appLogger != nil {
+		appLogger.Printf("New-VMSwitch failed: %v; %s", err, msg)
+	}
+	lower := strings.ToLower(msg)
+	if strings.Contains(lower, "permission") || strings.Contains(lower, "access is denied") || strings.Contains(lower, "elevat") {
+		return fmt.Errorf("%w: run passgo from an administrator terminal, or run this in one and pick the switch here:\n  New-VMSwitch -Name %s -NetAdapterName %s -AllowManagementOS $true",
+			errHyperVNeedsAdmin, psQuote(name), psQuote(adapter))
+	}
+	return fmt.Errorf("New-VMSwitch failed: %w\n%s", err, msg)
+}
+
+// hyperVSwitchRequestMsg asks the root model to confirm creating a switch.
+type hyperVSwitchRequestMsg struct{ adapter string }
+
+// createHyperVSwitchCmd creates the switch and makes it the default
+// bridged network.
+func createHyperVSwitchCmd(adapter string) tea.Cmd {
+	return func() tea.Msg {
+		name := hyperVSwitchName(adapter)
+		if err := CreateHyperVSwitch(name, adapter); err != nil {
+			return bridgeSetResultMsg{name: name, err: err}
+		}
+		_, err := SetSetting("local.bridged-network", name)
+		return bridgeSetResultMsg{name: name, err: err}
+	}
+}
+
+// hostCanCreateHyperVSwitch is canCreateHyperVSwitch for this process.
+func hostCanCreateHyperVSwitch(driver string) bool {
+	return canCreateHyperVSwitch(runtime.GOOS, driver, mpRunner)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestHyperVSwitchHelpers(t *testing.T) {
+	nets := []NetworkInfo{
+		{Name: "Default Switch", Type: "switch", Description: "Virtual Switch with internal networking"},
+		{Name: "Ethernet", Type: "ethernet", Description: "Intel(R) Ethernet Connection"},
+		{Name: "Wi-Fi", Type: "wifi", Description: "Intel(R) Wi-Fi 6"},
+	}
+	if hasExternalSwitch(nets) {
+		t.Error("the internal Default Switch is not an external switch")
+	}
+	if !hasExternalSwitch(append(nets, NetworkInfo{Name: "passgo-Ethernet", Type: "switch", Description: "Virtual Switch with external networking"})) {
+		t.Error("expected an external switch to be found")
+	}
+	if switchableAdapter(nets[0]) || !switchableAdapter(nets[1]) || !switchableAdapter(nets[2]) {
+		t.Error("only physical adapters can back a switch")
+	}
+
+	if got := hyperVSwitchName("Ethernet 2"); got != "passgo-Ethernet-2" {
+		t.Errorf("hyperVSwitchName = %q", got)
+	}
+	want := `$ErrorActionPreference = 'Stop'; New-VMSwitch -Name 'passgo-x' -NetAdapterName 'Bob''s NIC' -AllowManagementOS $true | Out-Null`
+	if got := newVMSwitchScript("passgo-x", "Bob's NIC"); got != want {
+		t.Errorf("script = %s", got)
+	}
+
+	if !canCreateHyperVSwitch("windows", "hyperv", localRunner{}) {
+		t.Error("windows + hyperv + local should allow creating a switch")
+	}
+	for _, c := range []struct {
+		goos, driver string
+		r            multipassRunner
+	}{{"linux", "hyperv", localRunner{}}, {"windows", "virtualbox", localRunner{}}, {"windows", "hyperv", sshRunner{host: "x"}}} {
+		if canCreateHyperVSwitch(c.goos, c.driver, c.r) {
+			t.Errorf("canCreateHyperVSwitch(%s, %s, %v) should be false", c.goos, c.driver, c.r)
+		}
+	}
+}
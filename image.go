@@ -0,0 +1,180 @@
+// image.go - Launching from custom image files and URLs, and image remotes
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// customImageOption is the release picker entry that uses the Image URL field.
+const customImageOption = "Image URL…"
+
+// isImageURL reports whether release is an image file or URL rather than a
+// release name or alias. multipass launch accepts file://, http:// and
+// https:// images.
+func isImageURL(release string) bool {
+	for _, scheme := range []string{"file://", "http://", "https://"} {
+		if strings.HasPrefix(strings.ToLower(release), scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateImageURL checks an image URL before multipass is asked to fetch
+// it: file:// images must be an existing absolute path, http(s) ones need a
+// host.
+func validateImageURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("image URL: %w", err)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "file":
+		path := filepath.FromSlash(u.Path)
+		if u.Host != "" || !filepath.IsAbs(path) {
+			return errors.New("image URL: use file:///absolute/path/to/image.img")
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("image file: %w", err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("image file: %s is a directory", path)
+		}
+	case "http", "https":
+		if u.Host == "" {
+			return errors.New("image URL: missing host")
+		}
+	default:
+		return errors.New("image URL must start with file://, http:// or https://")
+	}
+	return nil
+}
+
+// shortImageLabel fits an image URL into width runes for the picker,
+// keeping the file name at the end.
+func shortImageLabel(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width || width < 4 {
+		return s
+	}
+	return "…" + string(r[len(r)-width+1:])
+}
+
+// ─── Image Remotes ─────────────────────────────────────────────────────────────
+
+// imageSources are the choices of the Image source picker. The first is the
+// curated release list; the rest are multipass image remotes.
+var imageSources = []string{"Releases", "release:", "daily:", "appliance:"}
+
+// ImageInfo is one image from `multipass find --only-images`.
+type ImageInfo struct {
+	Name    string // what to pass to multipass launch, e.g. "daily:24.10"
+	Aliases []string
+	OS      string
+	Release string
+	Version string
+}
+
+// describe returns a short description for the image picker.
+func (i ImageInfo) describe() string {
+	var parts []string
+	for _, p := range []string{i.OS, i.Release} {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 && i.Version != "" {
+		parts = append(parts, i.Version)
+	}
+	return strings.Join(parts, " ")
+}
+
+// findImagesJSON is the response of `multipass find --format json`.
+type findImagesJSON struct {
+	Images map[string]struct {
+		Aliases []string `json:"aliases"`
+		OS      string   `json:"os"`
+		Release string   `json:"release"`
+		Remote  string   `json:"remote"`
+		Version string   `json:"version"`
+	} `json:"images"`
+}
+
+// parseFindImagesJSON parses `multipass find --only-images --format json`,
+// sorted by name. Images from a non-default remote get its prefix so the
+// name can be launched as-is.
+func parseFindImagesJSON(output string) ([]ImageInfo, error) {
+	var resp findImagesJSON
+	if err := json.Unmarshal([]byte(output), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse images: %w", err)
+	}
+	images := make([]ImageInfo, 0, len(resp.Images))
+	for key, img := range resp.Images {
+		name := key
+		if img.Remote != "" && !strings.Contains(key, ":") {
+			name = img.Remote + ":" + key
+		}
+		images = append(images, ImageInfo{Name: name, Aliases: img.Aliases, OS: img.OS, Release: img.Release, Version: img.Version})
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].Name < images[j].Name })
+	return images, nil
+}
+
+// findImage returns the image release names, by name or alias, among
+// images listed from release's remote.
+func findImage(release string, images []ImageInfo) (ImageInfo, bool) {
+	remote, name, found := strings.Cut(strings.TrimPrefix(release, "release:"), ":")
+	if !found {
+		remote, name = "", remote
+	}
+	for _, img := range images {
+		imgRemote, imgName, ok := strings.Cut(strings.TrimPrefix(img.Name, "release:"), ":")
+		if !ok {
+			imgRemote, imgName = "", imgRemote
+		}
+		if imgRemote == remote && (imgName == name || slices.Contains(img.Aliases, name)) {
+			return img, true
+		}
+	}
+	return ImageInfo{}, false
+}
+
+// FindImages lists the images of remote (e.g. "daily:"); "" means the
+// default remote.
+func FindImages(remote string) ([]ImageInfo, error) {
+	args := []string{"find", "--only-images", "--format", "json"}
+	if remote != "" {
+		args = append(args, remote)
+	}
+	output, err := runMultipassCommand(args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseFindImagesJSON(output)
+}
+
+// imageListResultMsg carries the images of one remote for the create form.
+type imageListResultMsg struct {
+	remote string
+	images []ImageInfo
+	err    error
+}
+
+// fetchImagesCmd lists remote's images in the background.
+func fetchImagesCmd(remote string) tea.Cmd {
+	return func() tea.Msg {
+		images, err := FindImages(remote)
+		return imageListResultMsg{remote: remote, images: images, err: err}
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateImageURL(t *testing.T) {
+	dir := t.TempDir()
+	img := filepath.Join(dir, "custom.img")
+	if err := os.WriteFile(img, []byte("qcow"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fileURL := "file://" + filepath.ToSlash(img)
+
+	tests := []struct {
+		in      string
+		wantErr bool
+	}{
+		{fileURL, false},
+		{"https://cloud-images.example.com/noble/disk.img", false},
+		{"http://10.0.0.1/img.qcow2", false},
+		{"file://" + filepath.ToSlash(filepath.Join(dir, "missing.img")), true},
+		{"file://" + filepath.ToSlash(dir), true},
+		{"file://relative/image.img", true},
+		{"https:///disk.img", true},
+		{"ftp://host/disk.img", true},
+	}
+	for _, tt := range tests {
+		if err := validateImageURL(tt.in); (err != nil) != tt.wantErr {
+			t.Errorf("validateImageURL(%q) = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+	}
+}
+
+func TestIsImageURL(t *testing.T) {
+	for in, want := range map[string]bool{
+		"24.04": false, "noble": false, "file:///x.img": true, "HTTPS://h/x.img": true, "/tmp/x.img": false,
+	} {
+		if got := isImageURL(in); got != want {
+			t.Errorf("isImageURL(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestNoteLaunchKeepsImagesApart(t *testing.T) {
+	st := newAppState()
+	st.noteLaunch("24.04", "")
+	st.noteLaunch("https://example.com/a.img", "")
+	st.noteLaunch("file:///srv/b.img", "")
+	if want := []string{"24.04"}; !reflect.DeepEqual(st.Recent.Releases, want) {
+		t.Errorf("Releases = %v, want %v", st.Recent.Releases, want)
+	}
+	if want := []string{"file:///srv/b.img", "https://example.com/a.img"}; !reflect.DeepEqual(st.Recent.Images, want) {
+		t.Errorf("Images = %v, want %v", st.Recent.Images, want)
+	}
+}
+
+func TestLaunchOptionsImage(t *testing.T) {
+	img := filepath.Join(t.TempDir(), "custom.img")
+	if err := os.WriteFile(img, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	o := launchOptions{release: "24.04", image: "file://" + filepath.ToSlash(img), cpus: 1, memoryMB: 512, diskGB: 5}
+	if err := o.finish(); err != nil || o.release != o.image {
+		t.Fatalf("finish() = %v, release %q", err, o.release)
+	}
+	o = launchOptions{image: "/not/a/url.img", cpus: 1, memoryMB: 512, diskGB: 5}
+	if err := o.finish(); err == nil {
+		t.Error("expected an error for an image that is not a URL")
+	}
+}
+
+func TestShortImageLabel(t *testing.T) {
+	if got := shortImageLabel("https://example.com/images/noble-custom.img", 20); len([]rune(got)) != 20 || !strings.HasSuffix(got, "/noble-custom.img") {
+		t.Errorf("shortImageLabel = %q", got)
+	}
+	if got := shortImageLabel("file:///a.img", 20); got != "file:///a.img" {
+		t.Errorf("short URLs should be left alone, got %q", got)
+	}
+}
+
+func TestParseFindImagesJSON(t *testing.T) {
+	out := `{"errors":[],"images":{
+		"24.04":{"aliases":["noble","lts"],"os":"Ubuntu","release":"24.04 LTS","remote":"","version":"20240423"},
+		"daily:25.04":{"aliases":["plucky"],"os":"Ubuntu","release":"25.04","remote":"daily","version":"20250101"},
+		"adguard-home":{"aliases":[],"os":"","release":"","remote":"appliance","version":"20200812"}}}`
+	images, err := parseFindImagesJSON(out)
+	if err != nil {
+		t.Fatalf("parseFindImagesJSON: %v", err)
+	}
+	var names []string
+	for _, img := range images {
+		names = append(names, img.Name)
+	}
+	if want := []string{"24.04", "appliance:adguard-home", "daily:25.04"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+	if d := images[0].describe(); d != "Ubuntu 24.04 LTS" {
+		t.Errorf("describe = %q", d)
+	}
+	if d := images[1].describe(); d != "20200812" {
+		t.Errorf("describe without os/release = %q, want the version", d)
+	}
+	if _, err := parseFindImagesJSON("not json"); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestAdvCreateImageSource(t *testing.T) {
+	m := advCreateModel{
+		releases: []string{"24.04", "22.04", customImageOption},
+		fields: []advField{
+			{label: "Image source", isSelect: true, options: imageSources},
+			{label: "Release", isSelect: true, options: []string{"24.04", "22.04", customImageOption}},
+		},
+	}
+	m.fields[0].optionIdx = 2 // daily:
+	if cmd := m.switchImageSource(); cmd == nil || m.loadingImages != "daily:" {
+		t.Fatalf("switching to a remote should start listing it")
+	}
+	if _, errMsg := m.submit(); errMsg == "" {
+		t.Error("submit should refuse while images are loading")
+	}
+
+	m.setImages(imageListResultMsg{remote: "release:", images: []ImageInfo{{Name: "stale"}}})
+	if m.loadingImages != "daily:" {
+		t.Fatal("a result for another remote must be ignored")
+	}
+	m.setImages(imageListResultMsg{remote: "daily:", images: []ImageInfo{{Name: "daily:25.04", OS: "Ubuntu", Release: "25.04"}}})
+	if got := m.field("Release").options; !reflect.DeepEqual(got, []string{"daily:25.04", customImageOption}) {
+		t.Errorf("Release options = %v", got)
+	}
+	if m.imageNotes["daily:25.04"] != "Ubuntu 25.04" {
+		t.Errorf("imageNotes = %v", m.imageNotes)
+	}
+
+	m.fields[0].optionIdx = 0
+	if cmd := m.switchImageSource(); cmd != nil || len(m.field("Release").options) != 3 {
+		t.Errorf("switching back should restore the release list without fetching")
+	}
+}
@@ -0,0 +1,212 @@
+// imagepin.go - Recording the exact image each launch got, and warning when a release moves on
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// imagePin is the image a launch of Release resolved to. Aliases such as
+// 24.04 or lts move to a new image whenever Canonical publishes one, so a
+// later launch of the same preset can boot different software.
+type imagePin struct {
+	Release  string    `json:"release"`           // as asked for, e.g. 24.04 or daily:25.10
+	Version  string    `json:"version,omitempty"` // image version from multipass find, e.g. 20240423
+	Hash     string    `json:"hash,omitempty"`    // image hash from multipass info
+	VM       string    `json:"vm,omitempty"`
+	Launched time.Time `json:"launched,omitzero"`
+}
+
+// pinned reports whether the image has been read back after the launch.
+func (p imagePin) pinned() bool {
+	return p.Version != "" || p.Hash != ""
+}
+
+// describe is the pin as shown in warnings and the info view.
+func (p imagePin) describe() string {
+	var parts []string
+	if p.Version != "" {
+		parts = append(parts, p.Version)
+	}
+	if p.Hash != "" {
+		parts = append(parts, "hash "+shortHash(p.Hash))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// shortHash is the 12-character prefix multipass itself shows.
+func shortHash(h string) string {
+	if len(h) > 12 {
+		return h[:12]
+	}
+	return h
+}
+
+// pinKey is the key a release is pinned under: custom images and an empty
+// release are not pinned.
+func pinKey(release string) (string, bool) {
+	release = strings.TrimPrefix(strings.TrimSpace(release), "release:")
+	if release == "" || isImageURL(release) {
+		return "", false
+	}
+	return release, true
+}
+
+// releaseRemote is the remote whose images list release: "" for the
+// default remote, or e.g. "daily:".
+func releaseRemote(release string) string {
+	if remote, _, ok := strings.Cut(strings.TrimPrefix(release, "release:"), ":"); ok {
+		return remote + ":"
+	}
+	return ""
+}
+
+// pinDrift returns a warning when release now resolves to another image
+// than its pin, given the images of its remote.
+func pinDrift(release string, pins map[string]imagePin, images []ImageInfo) string {
+	key, ok := pinKey(release)
+	if !ok {
+		return ""
+	}
+	pin, ok := pins[key]
+	if !ok || pin.Version == "" {
+		return ""
+	}
+	img, ok := findImage(release, images)
+	if !ok || img.Version == "" || img.Version == pin.Version {
+		return ""
+	}
+	return fmt.Sprintf("%s now resolves to image %s; the last %s launch (%s, %s) got %s",
+		key, img.Version, key, orDashes(pin.VM), pin.Launched.Local().Format("2006-01-02"), pin.describe())
+}
+
+// checkImagePin is pinDrift for `passgo launch`/`run`, listing images only
+// when release has a pin. A failed listing gives no warning.
+func checkImagePin(release string, pins map[string]imagePin, find func(string) ([]ImageInfo, error)) string {
+	key, ok := pinKey(release)
+	if !ok {
+		return ""
+	}
+	if _, pinned := pins[key]; !pinned {
+		return ""
+	}
+	images, err := find(releaseRemote(release))
+	if err != nil {
+		return ""
+	}
+	return pinDrift(release, pins, images)
+}
+
+// readImagePin reads the image vmName was launched from: its hash from
+// multipass info and the version release resolves to from multipass find.
+func readImagePin(vmName, release string, find func(string) ([]ImageInfo, error), now time.Time) (imagePin, error) {
+	pin := imagePin{Release: release, VM: vmName, Launched: now}
+	out, err := runMultipassCommand("info", vmName, "--format", "json")
+	if err != nil {
+		return pin, err
+	}
+	var resp multipassInfoResponse
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		return pin, fmt.Errorf("failed to parse VM info JSON: %w", err)
+	}
+	pin.Hash = resp.Info[vmName].ImageHash
+	if images, err := find(releaseRemote(release)); err == nil {
+		if img, ok := findImage(release, images); ok {
+			pin.Version = img.Version
+		}
+	}
+	if !pin.pinned() {
+		return pin, fmt.Errorf("multipass reported no image hash or version for %s", vmName)
+	}
+	return pin, nil
+}
+
+// loadImagePins reads the pins from the state file, for the CLI. A missing
+// or unreadable file has none.
+func loadImagePins() map[string]imagePin {
+	path, err := stateFilePath()
+	if err != nil {
+		return nil
+	}
+	st, err := loadState(path)
+	if err != nil {
+		return nil
+	}
+	return st.Pins
+}
+
+// recordImagePin saves pin in the state file, for the CLI.
+func recordImagePin(pin imagePin) error {
+	path, err := stateFilePath()
+	if err != nil {
+		return err
+	}
+	_, err = updateState(path, func(st *appState) bool {
+		st.notePin(pin)
+		return true
+	})
+	return err
+}
+
+// setPendingPin records that vmName is being launched from release; the
+// pin is filled in once the launch succeeds.
+func (s appState) setPendingPin(vmName, release string) {
+	if _, ok := pinKey(release); !ok {
+		return
+	}
+	meta := s.VMs[vmName]
+	meta.Image = &imagePin{Release: release}
+	s.VMs[vmName] = meta
+}
+
+// notePin stores pin on its VM and as the latest pin of its release.
+func (s *appState) notePin(pin imagePin) {
+	key, ok := pinKey(pin.Release)
+	if !ok {
+		return
+	}
+	if meta, tracked := s.VMs[pin.VM]; tracked {
+		meta.Image = &pin
+		s.VMs[pin.VM] = meta
+	}
+	if s.Pins == nil {
+		s.Pins = make(map[string]imagePin)
+	}
+	s.Pins[key] = pin
+}
+
+// imagePinMsg carries the image a finished launch got.
+type imagePinMsg struct {
+	pin imagePin
+	err error
+}
+
+// pinImageCmd reads vmName's image in the background.
+func pinImageCmd(vmName, release string) tea.Cmd {
+	return func() tea.Msg {
+		pin, err := readImagePin(vmName, release, FindImages, time.Now())
+		return imagePinMsg{pin: pin, err: err}
+	}
+}
+
+// imageDriftMsg carries a drift warning for a launch that had no form to
+// show it in.
+type imageDriftMsg struct {
+	warning string
+}
+
+// checkImageDriftCmd compares release against its pin in the background.
+func checkImageDriftCmd(release string, pins map[string]imagePin) tea.Cmd {
+	key, ok := pinKey(release)
+	if !ok || pins[key].Version == "" {
+		return nil
+	}
+	pin := map[string]imagePin{key: pins[key]} // pins may change meanwhile
+	return func() tea.Msg {
+		return imageDriftMsg{warning: checkImagePin(release, pin, FindImages)}
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindImage(t *testing.T) {
+	images := []ImageInfo{
+		{Name: "24.04", Aliases: []string{"noble", "lts"}, Version: "20240423"},
+		{Name: "daily:25.10", Aliases: []string{"questing"}, Version: "20250901"},
+	}
+	for release, want := range map[string]string{
+		"24.04":          "24.04",
+		"lts":            "24.04",
+		"release:noble":  "24.04",
+		"daily:questing": "daily:25.10",
+		"daily:25.10":    "daily:25.10",
+		"questing":       "",
+		"daily:noble":    "",
+	} {
+		img, ok := findImage(release, images)
+		if ok != (want != "") || img.Name != want {
+			t.Errorf("findImage(%s) = %q, %v; want %q", release, img.Name, ok, want)
+		}
+	}
+}
+
+func TestPinDrift(t *testing.T) {
+	launched := time.Date(2024, 4, 30, 12, 0, 0, 0, time.UTC)
+	pins := map[string]imagePin{"24.04": {Release: "24.04", Version: "20240423", Hash: "1f2d3e4f5a6b7c8d", VM: "web", Launched: launched}}
+	same := []ImageInfo{{Name: "24.04", Version: "20240423"}}
+	newer := []ImageInfo{{Name: "24.04", Version: "20241002"}}
+
+	if w := pinDrift("24.04", pins, same); w != "" {
+		t.Errorf("an unchanged image should not warn: %q", w)
+	}
+	w := pinDrift("release:24.04", pins, newer)
+	if !strings.Contains(w, "20241002") || !strings.Contains(w, "20240423") || !strings.Contains(w, "1f2d3e4f5a6b") || strings.Contains(w, "7c8d") {
+		t.Errorf("drift warning = %q", w)
+	}
+	if pinDrift("22.04", pins, newer) != "" || pinDrift("file:///x.img", pins, newer) != "" {
+		t.Error("releases without a pin should not warn")
+	}
+
+	calls := 0
+	find := func(remote string) ([]ImageInfo, error) { calls++; return newer, nil }
+	if checkImagePin("22.04", pins, find) != "" || calls != 0 {
+		t.Error("unpinned releases should not list images")
+	}
+	if checkImagePin("24.04", pins, find) == "" {
+		t.Error("a pinned release that moved should warn")
+	}
+	failing := func(string) ([]ImageInfo, error) { return nil, errors.New("offline") }
+	if checkImagePin("24.04", pins, failing) != "" {
+		t.Error("a failed listing should not warn")
+	}
+}
+
+func TestReadImagePin(t *testing.T) {
+	useFakeRunner(t, &fakeRunner{outputs: map[string]string{
+		"info": `{"errors": [], "info": {"web": {"image_hash": "1f2d3e4f5a6b7c8d", "image_release": "24.04 LTS"}}}`,
+	}})
+	var remote string
+	find := func(r string) ([]ImageInfo, error) {
+		remote = r
+		return []ImageInfo{{Name: "daily:25.10", Aliases: []string{"questing"}, Version: "20250901"}}, nil
+	}
+	now := time.Now()
+	pin, err := readImagePin("web", "daily:questing", find, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := imagePin{Release: "daily:questing", Version: "20250901", Hash: "1f2d3e4f5a6b7c8d", VM: "web", Launched: now}
+	if pin != want || remote != "daily:" {
+		t.Errorf("pin = %+v (listed %q), want %+v", pin, remote, want)
+	}
+
+	useFakeRunner(t, &fakeRunner{outputs: map[string]string{"info": `{"info": {"web": {}}}`}})
+	if _, err := readImagePin("web", "24.04", func(string) ([]ImageInfo, error) { return nil, nil }, now); err == nil {
+		t.Error("no hash and no version should be an error")
+	}
+}
+
+func TestImagePinsPersist(t *testing.T) {
+	st := newAppState()
+	st.setPendingPin("web", "24.04")
+	st.setPendingPin("img", "file:///x/noble.img")
+	if st.VMs["web"].Image == nil || st.VMs["web"].Image.pinned() || st.VMs["img"].Image != nil {
+		t.Fatalf("pending pins: %+v", st.VMs)
+	}
+	st.notePin(imagePin{Release: "24.04", Version: "20240423", VM: "web", Launched: time.Now().UTC().Truncate(time.Second)})
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := saveState(path, st); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Pins["24.04"].Version != "20240423" || got.VMs["web"].Image == nil || got.VMs["web"].Image.Version != "20240423" {
+		t.Errorf("pins after reload: %+v, web %+v", got.Pins, got.VMs["web"].Image)
+	}
+}
@@ -0,0 +1,99 @@
+// ipexport.go - VM name → IP mappings as plain lines, JSON or ssh_config
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ipFormats are the formats of an IP export, in the order the dialog offers
+// them.
+var ipFormats = []string{"plain", "json", "ssh"}
+
+// ipFormatLabels name the formats in the dialog.
+var ipFormatLabels = map[string]string{"plain": "name ip", "json": "JSON", "ssh": "ssh_config"}
+
+// vmAddress is a running VM and its first IPv4 address.
+type vmAddress struct {
+	name string
+	ip   string
+}
+
+// vmAddresses lists the running VMs with an address whose name contains
+// one of filters (any VM without filters), in the order given.
+func vmAddresses(vms []vmData, filters []string) []vmAddress {
+	var out []vmAddress
+	for _, d := range vms {
+		vm := d.info
+		ips := strings.Fields(vm.IPv4)
+		if vm.State != "Running" || len(ips) == 0 || ips[0] == "--" || !nameMatchesAny(vm.Name, filters) {
+			continue
+		}
+		out = append(out, vmAddress{name: vm.Name, ip: ips[0]})
+	}
+	return out
+}
+
+// nameMatchesAny reports whether name contains one of filters, ignoring
+// case, as the table filter does.
+func nameMatchesAny(name string, filters []string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	lower := strings.ToLower(name)
+	for _, f := range filters {
+		if strings.Contains(lower, strings.ToLower(f)) {
+			return true
+		}
+	}
+	return false
+}
+
+// sshIdentityFile is the private key matching the ssh-key in .config, when
+// it exists next to it.
+func sshIdentityFile() string {
+	pub, ok := configValue("ssh-key")
+	priv := strings.TrimSuffix(strings.TrimSpace(pub), ".pub")
+	if !ok || priv == strings.TrimSpace(pub) {
+		return ""
+	}
+	if _, err := os.Stat(priv); err != nil {
+		return ""
+	}
+	return priv
+}
+
+// renderIPs formats addrs: "name ip" lines, a JSON object of name to IP, or
+// an ssh_config Host block per VM for the ubuntu user, with identity as the
+// IdentityFile when set.
+func renderIPs(format string, addrs []vmAddress, identity string) ([]byte, error) {
+	var b strings.Builder
+	switch format {
+	case "plain":
+		for _, a := range addrs {
+			fmt.Fprintf(&b, "%s %s\n", a.name, a.ip)
+		}
+	case "json":
+		m := make(map[string]string, len(addrs))
+		for _, a := range addrs {
+			m[a.name] = a.ip
+		}
+		out, err := json.MarshalIndent(m, "", "  ")
+		return append(out, '\n'), err
+	case "ssh":
+		for i, a := range addrs {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "Host %s\n  HostName %s\n  User ubuntu\n", a.name, a.ip)
+			if identity != "" {
+				fmt.Fprintf(&b, "  IdentityFile %s\n", identity)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q (plain, json or ssh)", format)
+	}
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func testAddressVMs() []vmData {
+	return []vmData{
+		{info: VMInfo{Name: "web", State: "Running", IPv4: "10.0.0.2 172.17.0.1"}},
+		{info: VMInfo{Name: "db", State: "Running", IPv4: "10.0.0.3"}},
+		{info: VMInfo{Name: "web-old", State: "Stopped", IPv4: "--"}},
+		{info: VMInfo{Name: "booting", State: "Running"}},
+	}
+}
+
+func TestVMAddresses(t *testing.T) {
+	got := vmAddresses(testAddressVMs(), nil)
+	if len(got) != 2 || got[0] != (vmAddress{"web", "10.0.0.2"}) || got[1] != (vmAddress{"db", "10.0.0.3"}) {
+		t.Fatalf("vmAddresses = %v", got)
+	}
+	if got := vmAddresses(testAddressVMs(), []string{"WEB", "cache"}); len(got) != 1 || got[0].name != "web" {
+		t.Fatalf("filtered = %v", got)
+	}
+}
+
+func TestRenderIPs(t *testing.T) {
+	addrs := []vmAddress{{"web", "10.0.0.2"}, {"db", "10.0.0.3"}}
+	for format, want := range map[string]string{
+		"plain": "web 10.0.0.2\ndb 10.0.0.3\n",
+		"json":  "{\n  \"db\": \"10.0.0.3\",\n  \"web\": \"10.0.0.2\"\n}\n",
+		"ssh":   "Host web\n  HostName 10.0.0.2\n  User ubuntu\n  IdentityFile /k/id\n\nHost db\n  HostName 10.0.0.3\n  User ubuntu\n  IdentityFile /k/id\n",
+	} {
+		got, err := renderIPs(format, addrs, "/k/id")
+		if err != nil || string(got) != want {
+			t.Errorf("%s: got %q, %v", format, got, err)
+		}
+	}
+	if got, _ := renderIPs("ssh", addrs[:1], ""); strings.Contains(string(got), "IdentityFile") {
+		t.Errorf("no IdentityFile without a key: %q", got)
+	}
+	if _, err := renderIPs("csv", addrs, ""); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestIPsCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useFakeRunner(t, &fakeRunner{outputs: map[string]string{"info": `{"info": {
+		"web": {"state": "Running", "ipv4": ["10.0.0.2"]},
+		"db": {"state": "Stopped", "ipv4": []}}}`}})
+
+	var stdout, stderr bytes.Buffer
+	if code := ipsCommand([]string{"-format", "plain"}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit %d, stderr %q", code, stderr.String())
+	}
+	if stdout.String() != "web 10.0.0.2\n" {
+		t.Fatalf("stdout = %q", stdout.String())
+	}
+	stdout.Reset()
+	if code := ipsCommand([]string{"-format", "yaml"}, nil, &stdout, &stderr); code != exitUsage {
+		t.Fatalf("exit %d for an unknown format, want %d", code, exitUsage)
+	}
+}
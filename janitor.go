@@ -0,0 +1,136 @@
+// janitor.go - Startup sweep of temp files left behind by earlier passgo runs
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// tempArtifactPatterns match the temp files and directories passgo creates
+// in the system temp dir. A crash or kill leaves them behind.
+var tempArtifactPatterns = []string{
+	"passgo-cloudinit-*",     // template repo clones
+	"passgo-gallery-*",       // gallery downloads
+	"passgo-userdata-*.yaml", // rendered user-data, possibly holding secrets
+}
+
+// defaultTempMaxAge is how old a leftover must be before the sweep removes
+// it; younger ones may belong to another passgo that is still running.
+const defaultTempMaxAge = 24 * time.Hour
+
+// ownedTempPattern puts this process's PID in a temp name pattern, so a
+// sweep in another passgo can tell whether the owner still runs:
+// passgo-gallery-* becomes passgo-gallery-pid4242-*.
+func ownedTempPattern(pattern string) string {
+	prefix, suffix, _ := strings.Cut(pattern, "*")
+	return fmt.Sprintf("%spid%d-*%s", prefix, os.Getpid(), suffix)
+}
+
+// tempOwner returns the PID in name, made from ownedTempPattern(pattern).
+// Leftovers of older passgo versions have none.
+func tempOwner(pattern, name string) (int, bool) {
+	prefix, _, _ := strings.Cut(pattern, "*")
+	rest, ok := strings.CutPrefix(name, prefix+"pid")
+	if !ok {
+		return 0, false
+	}
+	digits, _, ok := strings.Cut(rest, "-")
+	pid, err := strconv.Atoi(digits)
+	return pid, ok && err == nil && pid > 0
+}
+
+// processAlive reports whether pid is a running process. One of another
+// user (EPERM) counts as running.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false // on Windows: no such process
+	}
+	defer p.Release()
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// loadTempMaxAge reads temp-max-age (a duration, or off) using lookup.
+// Invalid values keep the default; 0 turns the sweep off.
+func loadTempMaxAge(lookup func(string) (string, bool)) time.Duration {
+	if v, ok := lookup("temp-max-age"); ok {
+		if d, err := parseCommandTimeout(v); err == nil {
+			return d
+		}
+	}
+	return defaultTempMaxAge
+}
+
+// tempSweep counts what sweepTempArtifacts removed, by pattern.
+type tempSweep struct {
+	removed map[string]int
+	failed  int
+}
+
+func (s tempSweep) total() int {
+	n := 0
+	for _, c := range s.removed {
+		n += c
+	}
+	return n
+}
+
+// sweepTempArtifacts removes passgo temp files and directories in dir last
+// modified more than maxAge before now, unless the passgo that made them
+// still runs: a long-lived agent or TUI keeps its clones.
+func sweepTempArtifacts(dir string, now time.Time, maxAge time.Duration) tempSweep {
+	s := tempSweep{removed: make(map[string]int)}
+	if maxAge <= 0 {
+		return s
+	}
+	for _, pattern := range tempArtifactPatterns {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, path := range matches {
+			info, err := os.Lstat(path)
+			if err != nil || now.Sub(info.ModTime()) < maxAge {
+				continue
+			}
+			if pid, ok := tempOwner(pattern, filepath.Base(path)); ok && processAlive(pid) {
+				continue
+			}
+			if err := os.RemoveAll(path); err != nil {
+				s.failed++
+				if appLogger != nil {
+					appLogger.Printf("temp sweep: cannot remove %s: %v", path, err)
+				}
+				continue
+			}
+			s.removed[pattern]++
+		}
+	}
+	return s
+}
+
+// sweepOldTempArtifacts runs the sweep on the system temp dir and logs the
+// counts.
+func sweepOldTempArtifacts() {
+	maxAge := loadTempMaxAge(configValue)
+	s := sweepTempArtifacts(os.TempDir(), time.Now(), maxAge)
+	if appLogger == nil || (s.total() == 0 && s.failed == 0) {
+		return
+	}
+	var counts []string
+	for _, pattern := range tempArtifactPatterns {
+		if n := s.removed[pattern]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%s: %d", pattern, n))
+		}
+	}
+	appLogger.Printf("temp sweep: removed %d leftovers older than %s (%s), %d failed",
+		s.total(), maxAge, strings.Join(counts, ", "), s.failed)
+}
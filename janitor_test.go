@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSweepTempArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	mk := func(name string, isDir bool, mtime time.Time) string {
+		p := filepath.Join(dir, name)
+		if isDir {
+			if err := os.MkdirAll(filepath.Join(p, ".git"), 0o750); err != nil {
+				t.Fatal(err)
+			}
+		} else if err := os.WriteFile(p, []byte("#cloud-config\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	oldClone := mk("passgo-cloudinit-123", true, old)
+	oldUserData := mk("passgo-userdata-456.yaml", false, old)
+	freshClone := mk("passgo-cloudinit-789", true, now)
+	unrelated := mk("other-cloudinit-1", true, old)
+
+	s := sweepTempArtifacts(dir, now, 24*time.Hour)
+	if s.total() != 2 || s.removed["passgo-cloudinit-*"] != 1 || s.removed["passgo-userdata-*.yaml"] != 1 {
+		t.Errorf("removed = %v", s.removed)
+	}
+	for _, p := range []string{oldClone, oldUserData} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed", p)
+		}
+	}
+	for _, p := range []string{freshClone, unrelated} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("%s should be kept: %v", p, err)
+		}
+	}
+	if s := sweepTempArtifacts(dir, now.Add(72*time.Hour), 0); s.total() != 0 {
+		t.Errorf("a zero max age should disable the sweep, removed %v", s.removed)
+	}
+}
+
+func TestSweepKeepsLiveOwnersArtifacts(t *testing.T) {
+	dead := exec.Command("true")
+	if err := dead.Run(); err != nil {
+		t.Skip(err)
+	}
+	dir := t.TempDir()
+	old := time.Now().Add(-48 * time.Hour)
+	mine, err := os.MkdirTemp(dir, ownedTempPattern("passgo-gallery-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphan := filepath.Join(dir, fmt.Sprintf("passgo-gallery-pid%d-123", dead.Process.Pid))
+	if err := os.Mkdir(orphan, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{mine, orphan} {
+		if err := os.Chtimes(p, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if pid, ok := tempOwner("passgo-gallery-*", filepath.Base(mine)); !ok || pid != os.Getpid() {
+		t.Fatalf("owner of %s = %d, %v", mine, pid, ok)
+	}
+	s := sweepTempArtifacts(dir, time.Now(), 24*time.Hour)
+	if s.total() != 1 {
+		t.Errorf("removed = %v", s.removed)
+	}
+	if _, err := os.Stat(mine); err != nil {
+		t.Errorf("a running passgo's directory should be kept: %v", err)
+	}
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Error("an exited passgo's directory should be removed")
+	}
+}
+
+func TestLoadTempMaxAge(t *testing.T) {
+	tests := map[string]time.Duration{"": defaultTempMaxAge, "2h": 2 * time.Hour, "off": 0, "bogus": defaultTempMaxAge}
+	for v, want := range tests {
+		lookup := func(string) (string, bool) { return v, v != "" }
+		if got := loadTempMaxAge(lookup); got != want {
+			t.Errorf("loadTempMaxAge(%q) = %v, want %v", v, got, want)
+		}
+	}
+}
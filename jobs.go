@@ -0,0 +1,336 @@
+// jobs.go - Cron jobs: snapshot, stop, start or run a script in a VM on a schedule
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// cronJob does one thing to a VM whenever its cron expression matches.
+// Configured in .config, one line per job:
+//
+//	job: {name: nightly-db, cron: 0 2 * * *, vm: db, do: snapshot, keep: 7}
+//	job: {name: weekend, cron: 0 20 * * fri, vm: ci, do: stop}
+//	job: {name: prune, cron: 30 3 * * 1-5, vm: web, do: exec, run: docker system prune -f}
+//	job: {name: backup, cron: @daily, vm: db, do: exec, script: ~/bin/backup.sh}
+//
+// Like power schedules, only the moments themselves count: a job whose
+// moment passed while nothing was running is not caught up.
+type cronJob struct {
+	name   string
+	spec   cronSpec
+	vm     string
+	do     string // "snapshot", "stop", "start" or "exec"
+	run    string // exec: a command for bash in the VM
+	script string // exec: a host file whose contents bash runs in the VM
+	keep   int    // snapshot: scheduled snapshots kept, counting the new one
+	stop   bool   // snapshot: stop a running VM for it and start it again
+}
+
+// jobRun is the outcome of a job's last run, kept in the state file.
+type jobRun struct {
+	Time   time.Time `json:"time"`
+	Output string    `json:"output,omitempty"` // what it did, or the command's last line
+	Error  string    `json:"error,omitempty"`
+}
+
+// jobCheckInterval is how often jobs are evaluated.
+const jobCheckInterval = 30 * time.Second
+
+// jobOutputLimit caps the output kept for a run, in runes.
+const jobOutputLimit = 200
+
+// configFields splits the body of a "{key: value, ...}" entry. A comma
+// followed by text without a colon belongs to the value before it, so
+// "cron: 0,30 * * * *" and "run: echo a, b" stay whole.
+func configFields(body string) []string {
+	var fields []string
+	for _, part := range strings.Split(body, ",") {
+		if n := len(fields); n > 0 && !strings.Contains(part, ":") {
+			fields[n-1] += "," + part
+			continue
+		}
+		fields = append(fields, part)
+	}
+	return fields
+}
+
+// parseCronJob parses "{name: nightly, cron: 0 2 * * *, vm: db, do: snapshot}".
+func parseCronJob(s string) (cronJob, error) {
+	job := cronJob{keep: 7}
+	body := strings.TrimSpace(s)
+	body = strings.TrimSuffix(strings.TrimPrefix(body, "{"), "}")
+	seen := make(map[string]bool)
+	for _, part := range configFields(body) {
+		key, val, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		seen[key] = true
+		var err error
+		switch key {
+		case "name":
+			job.name = val
+		case "cron":
+			job.spec, err = parseCron(val)
+		case "vm":
+			job.vm = val
+		case "do":
+			job.do = strings.ToLower(val)
+		case "run":
+			job.run = val
+		case "script":
+			job.script = val
+		case "keep":
+			job.keep, err = strconv.Atoi(val)
+			if err != nil || job.keep < 1 {
+				err = fmt.Errorf("keep must be at least 1")
+			}
+		case "stop":
+			job.stop = parseConfigBool(val)
+		default:
+			err = fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return job, fmt.Errorf("job %s: %w", s, err)
+		}
+	}
+	var err error
+	switch {
+	case job.spec.expr == "" || job.vm == "" || job.do == "":
+		err = errors.New("cron, vm and do are required")
+	case job.do == "exec" && (job.run == "") == (job.script == ""):
+		err = errors.New("exec needs one of run or script")
+	case job.do != "exec" && (seen["run"] || seen["script"]):
+		err = errors.New("run and script are for do: exec")
+	case job.do != "snapshot" && (seen["keep"] || seen["stop"]):
+		err = errors.New("keep and stop are for do: snapshot")
+	case job.do != "snapshot" && job.do != "stop" && job.do != "start" && job.do != "exec":
+		err = fmt.Errorf("unknown do %q (snapshot, stop, start or exec)", job.do)
+	}
+	if err != nil {
+		return job, fmt.Errorf("job %s: %w", s, err)
+	}
+	if job.name == "" {
+		job.name = job.do + " " + job.vm
+	}
+	return job, nil
+}
+
+// loadCronJobs parses every job entry, skipping invalid ones and later
+// jobs with a name already taken.
+func loadCronJobs(values []string) ([]cronJob, []error) {
+	var jobs []cronJob
+	var errs []error
+	names := make(map[string]bool)
+	for _, v := range values {
+		j, err := parseCronJob(v)
+		if err == nil && names[j.name] {
+			err = fmt.Errorf("job %s: another job is named %q", v, j.name)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		names[j.name] = true
+		jobs = append(jobs, j)
+	}
+	return jobs, errs
+}
+
+// describe says what the job does, e.g. "snapshot db (keep 7)".
+func (j cronJob) describe() string {
+	switch j.do {
+	case "snapshot":
+		s := fmt.Sprintf("snapshot %s (keep %d)", j.vm, j.keep)
+		if j.stop {
+			s += ", stopping it"
+		}
+		return s
+	case "exec":
+		if j.script != "" {
+			return "run " + j.script + " in " + j.vm
+		}
+		return "run `" + j.run + "` in " + j.vm
+	}
+	return j.do + " " + j.vm
+}
+
+// powerJobs lists power schedules as the jobs they amount to, so that
+// every schedule shows up in one place.
+func powerJobs(schedules []powerSchedule) []cronJob {
+	var jobs []cronJob
+	for _, s := range schedules {
+		for _, ev := range []struct {
+			do string
+			at clock
+		}{{"start", s.start}, {"stop", s.stop}} {
+			if ev.at.set {
+				jobs = append(jobs, cronJob{name: "power " + s.vm, spec: s.spec(ev.at), vm: s.vm, do: ev.do})
+			}
+		}
+	}
+	return jobs
+}
+
+// dueCronJobs returns the jobs whose moment fell in (after, now], for VMs
+// in states (by name) only.
+func dueCronJobs(jobs []cronJob, states map[string]string, after, now time.Time) []cronJob {
+	var due []cronJob
+	for _, j := range jobs {
+		if _, ok := states[j.vm]; ok && !j.spec.last(after, now).IsZero() {
+			due = append(due, j)
+		}
+	}
+	return due
+}
+
+// runCronJob runs j against its VM, currently in state, and returns what
+// it did. Starting a running VM or stopping a stopped one does nothing.
+func runCronJob(j cronJob, state string, now time.Time) (string, error) {
+	switch j.do {
+	case "start":
+		if state == "Running" {
+			return "already running", nil
+		}
+		_, err := StartVM(j.vm)
+		return "started", err
+	case "stop":
+		if state != "Running" {
+			return "not running", nil
+		}
+		_, err := StopVM(j.vm)
+		return "stopped", err
+	case "exec":
+		if state != "Running" {
+			return "", fmt.Errorf("%s is %s", j.vm, strings.ToLower(state))
+		}
+		command := j.run
+		if j.script != "" {
+			path, err := expandHomePath(j.script)
+			if err != nil {
+				return "", err
+			}
+			data, err := os.ReadFile(path) // #nosec G304 -- a script the user configured
+			if err != nil {
+				return "", err
+			}
+			command = string(data)
+		}
+		out, err := ExecInVM(j.vm, "bash", "-c", command)
+		return lastLine(out), err
+	case "snapshot":
+		r := runSnapshotSchedules([]snapshotSchedule{{vm: j.vm, keep: j.keep, stop: j.stop}}, map[string]string{j.vm: state}, now)
+		if len(r.errs) > 0 {
+			return strings.Join(r.created, ", "), errors.Join(r.errs...)
+		}
+		if len(r.skipped) > 0 {
+			return "", fmt.Errorf("%s is running (set stop: true to stop it for the snapshot)", j.vm)
+		}
+		out := "created " + strings.Join(r.created, ", ")
+		if len(r.pruned) > 0 {
+			out += fmt.Sprintf(", pruned %d", len(r.pruned))
+		}
+		return out, nil
+	}
+	return "", fmt.Errorf("unknown do %q", j.do)
+}
+
+// lastLine is the last non-empty line of out, shortened for the state file.
+func lastLine(out string) string {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	return truncateToRunes(strings.TrimSpace(lines[len(lines)-1]), jobOutputLimit)
+}
+
+// recordJobRun notes the outcome of a job's run.
+func (s *appState) recordJobRun(name string, at time.Time, output string, err error) {
+	if s.Jobs == nil {
+		s.Jobs = make(map[string]jobRun)
+	}
+	r := jobRun{Time: at.UTC(), Output: output}
+	if err != nil {
+		r.Error = shortError(err)
+	}
+	s.Jobs[name] = r
+}
+
+// ─── TUI ───────────────────────────────────────────────────────────────────────
+
+// cronJobResultMsg reports a finished job run.
+type cronJobResultMsg struct {
+	job    cronJob
+	at     time.Time
+	output string
+	err    error
+}
+
+func runCronJobCmd(j cronJob, state string, now time.Time) tea.Cmd {
+	return func() tea.Msg {
+		out, err := runCronJob(j, state, now)
+		return cronJobResultMsg{job: j, at: now, output: out, err: err}
+	}
+}
+
+// checkCronJobs runs the jobs whose moment passed since the last check.
+// VMs with an operation in flight, and jobs still running from before,
+// are left alone.
+func (m *rootModel) checkCronJobs(now time.Time) []tea.Cmd {
+	if len(m.cronJobs) == 0 {
+		return nil
+	}
+	if m.lastJobCheck.IsZero() {
+		m.lastJobCheck = now
+		return nil
+	}
+	if now.Sub(m.lastJobCheck) < jobCheckInterval {
+		return nil
+	}
+	after := m.lastJobCheck
+	m.lastJobCheck = now
+	states := make(map[string]string, len(m.table.vms))
+	for _, vm := range m.table.vms {
+		if _, busy := m.table.busyVMs[vm.info.Name]; !busy {
+			states[vm.info.Name] = vm.info.State
+		}
+	}
+	var cmds []tea.Cmd
+	for _, j := range dueCronJobs(m.cronJobs, states, after, now) {
+		if m.jobsRunning[j.name] {
+			continue
+		}
+		if m.jobsRunning == nil {
+			m.jobsRunning = make(map[string]bool)
+		}
+		m.jobsRunning[j.name] = true
+		cmds = append(cmds, runCronJobCmd(j, states[j.vm], now))
+	}
+	return cmds
+}
+
+// handleCronJobResult records a job's run and tells the user about it.
+func (m *rootModel) handleCronJobResult(msg cronJobResultMsg) tea.Cmd {
+	delete(m.jobsRunning, msg.job.name)
+	m.state.recordJobRun(msg.job.name, msg.at, msg.output, msg.err)
+	m.persistState()
+	if msg.err != nil {
+		if appLogger != nil {
+			appLogger.Printf("job %s: %v", msg.job.name, msg.err)
+		}
+		return m.table.addToastFor(fmt.Sprintf("✗ Job %s: %s", msg.job.name, shortError(msg.err)), "error", 8*time.Second)
+	}
+	if appLogger != nil {
+		appLogger.Printf("job %s: %s", msg.job.name, msg.output)
+	}
+	toast := m.table.addToast(fmt.Sprintf("⏰ Job %s: %s", msg.job.name, msg.output), "success")
+	if msg.job.do == "exec" {
+		return toast
+	}
+	return tea.Batch(toast, m.requestVMListFetch(true))
+}
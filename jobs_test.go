@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCronJob(t *testing.T) {
+	j, err := parseCronJob("{name: prune, cron: 0,30 3 * * 1-5, vm: web, do: exec, run: date +%H:%M; echo a, b}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.name != "prune" || j.spec.String() != "0,30 3 * * 1-5" || j.vm != "web" || j.run != "date +%H:%M; echo a, b" {
+		t.Fatalf("got %+v", j)
+	}
+	j, err = parseCronJob("{cron: @daily, vm: db, do: snapshot, stop: true}")
+	if err != nil || j.name != "snapshot db" || j.keep != 7 || !j.stop {
+		t.Fatalf("defaults: got %+v, %v", j, err)
+	}
+	if got := j.describe(); got != "snapshot db (keep 7), stopping it" {
+		t.Errorf("describe = %q", got)
+	}
+
+	for _, bad := range []string{
+		"{vm: db, do: stop}",
+		"{cron: 0 2 * *, vm: db, do: stop}",
+		"{cron: @daily, vm: db, do: reboot}",
+		"{cron: @daily, vm: db, do: exec}",
+		"{cron: @daily, vm: db, do: exec, run: ls, script: x.sh}",
+		"{cron: @daily, vm: db, do: stop, run: ls}",
+		"{cron: @daily, vm: db, do: start, keep: 3}",
+		"{cron: @daily, vm: db, do: snapshot, keep: 0}",
+		"{cron: @daily, vm: db, do: stop, when: now}",
+	} {
+		if _, err := parseCronJob(bad); err == nil {
+			t.Errorf("parseCronJob(%q) should fail", bad)
+		}
+	}
+
+	jobs, errs := loadCronJobs([]string{"{cron: @daily, vm: a, do: stop}", "{cron: @hourly, vm: a, do: stop}", "{name: b, cron: @daily, vm: b, do: start}"})
+	if len(jobs) != 2 || len(errs) != 1 || !strings.Contains(errs[0].Error(), `another job is named "stop a"`) {
+		t.Errorf("jobs %+v, errs %v", jobs, errs)
+	}
+}
+
+func TestRunCronJob(t *testing.T) {
+	f := &fakeRunner{outputs: map[string]string{"exec": "pruning\nreclaimed 2GB\n\n", "list": `{"errors": [], "info": {}}`}, fail: map[string]error{"stop": errors.New("exit status 2")}}
+	useFakeRunner(t, f)
+	now := time.Now()
+
+	if out, err := runCronJob(cronJob{vm: "web", do: "start"}, "Running", now); err != nil || out != "already running" || len(f.calls) != 0 {
+		t.Errorf("start a running VM = %q, %v, calls %v", out, err, f.calls)
+	}
+	if _, err := runCronJob(cronJob{vm: "web", do: "stop"}, "Running", now); err == nil {
+		t.Error("a failed stop must be reported")
+	}
+	if out, err := runCronJob(cronJob{vm: "web", do: "exec", run: "docker system prune -f"}, "Running", now); err != nil || out != "reclaimed 2GB" {
+		t.Errorf("exec = %q, %v", out, err)
+	}
+	if !slices.Equal(f.calls[len(f.calls)-1], []string{"exec", "web", "--", "bash", "-c", "docker system prune -f"}) {
+		t.Errorf("exec call = %v", f.calls[len(f.calls)-1])
+	}
+	if _, err := runCronJob(cronJob{vm: "web", do: "exec", run: "ls"}, "Stopped", now); err == nil {
+		t.Error("exec in a stopped VM should fail")
+	}
+
+	script := filepath.Join(t.TempDir(), "backup.sh")
+	if err := os.WriteFile(script, []byte("tar czf /tmp/db.tgz /var/lib/db\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runCronJob(cronJob{vm: "db", do: "exec", script: script}, "Running", now); err != nil {
+		t.Fatal(err)
+	}
+	if call := f.calls[len(f.calls)-1]; call[len(call)-1] != "tar czf /tmp/db.tgz /var/lib/db\n" {
+		t.Errorf("the script's contents should run, got %v", call)
+	}
+
+	f.calls = nil
+	if _, err := runCronJob(cronJob{vm: "db", do: "snapshot", keep: 3}, "Running", now); err == nil || !strings.Contains(err.Error(), "stop: true") {
+		t.Errorf("snapshot of a running VM = %v", err)
+	}
+	if out, err := runCronJob(cronJob{vm: "db", do: "snapshot", keep: 3}, "Stopped", now); err != nil || !strings.HasPrefix(out, "created db.auto-") {
+		t.Errorf("snapshot = %q, %v", out, err)
+	}
+}
+
+func TestCheckCronJobs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	f := &fakeRunner{}
+	useFakeRunner(t, f)
+	m := rootModel{table: newTableModel(), state: newAppState()}
+	m.cronJobs, _ = loadCronJobs([]string{"{cron: 0 9 * * *, vm: dev, do: start}", "{cron: 0 9 * * *, vm: busy, do: start}", "{cron: 0 10 * * *, vm: dev, do: stop}"})
+	m.table.vms = []vmData{{info: VMInfo{Name: "dev", State: "Stopped"}}, {info: VMInfo{Name: "busy", State: "Stopped"}}}
+	m.table.busyVMs["busy"] = busyInfo{operation: "Deleting"}
+	first := time.Date(2026, time.October, 15, 8, 59, 30, 0, time.Local)
+	if cmds := m.checkCronJobs(first); len(cmds) != 0 {
+		t.Fatal("the first check only marks the time")
+	}
+	cmds := m.checkCronJobs(first.Add(time.Minute))
+	if len(cmds) != 1 || !m.jobsRunning["start dev"] {
+		t.Fatalf("got %d commands, running %v", len(cmds), m.jobsRunning)
+	}
+	msg, ok := cmds[0]().(cronJobResultMsg)
+	if !ok || msg.err != nil || msg.output != "started" {
+		t.Fatalf("result = %+v", msg)
+	}
+	m.handleCronJobResult(msg)
+	if m.jobsRunning["start dev"] || m.state.Jobs["start dev"].Output != "started" {
+		t.Errorf("the run should be recorded: %+v", m.state.Jobs)
+	}
+
+	rows := m.jobRows(first)
+	if len(rows) != 3 || rows[0].last == nil || rows[2].last != nil || !rows[2].next.Equal(first.Add(30*time.Second+time.Hour)) {
+		t.Errorf("rows = %+v", rows)
+	}
+}
+
+func TestJobRunsSaved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	st := newAppState()
+	st.recordJobRun("nightly", time.Now(), "created db.auto-1", nil)
+	st.recordJobRun("prune", time.Now(), "", errors.New("exit status 1\nmore"))
+	if err := saveState(path, st); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Jobs["nightly"].Output != "created db.auto-1" || got.Jobs["prune"].Error != "exit status 1" {
+		t.Errorf("jobs = %+v", got.Jobs)
+	}
+}
@@ -0,0 +1,197 @@
+// lifecycle.go - VM lifetime (TTL), idle detection and auto-teardown
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ttlWarnBefore is how long before expiry the user is warned.
+const ttlWarnBefore = 10 * time.Minute
+
+// persistState writes passgo's state file, logging (not surfacing) failures.
+// While attached, the agent writes the file too: what the TUI changed since
+// it last read the file is merged into the file as it is now, under its
+// lock, and the TUI carries on with the result.
+func (m *rootModel) persistState() {
+	if m.statePath == "" {
+		return
+	}
+	if !m.agent {
+		if err := saveState(m.statePath, m.state); err != nil && appLogger != nil {
+			appLogger.Printf("failed to save state: %v", err)
+		}
+		return
+	}
+	st, err := updateState(m.statePath, func(disk *appState) bool {
+		*disk = mergeState(*disk, m.stateBase, m.state)
+		return true
+	})
+	if err != nil {
+		if appLogger != nil {
+			appLogger.Printf("failed to save state: %v", err)
+		}
+		return
+	}
+	m.useState(st)
+}
+
+// checkTTLs warns about VMs nearing their lifetime and tears down expired
+// ones. It runs on every auto-refresh tick.
+func (m *rootModel) checkTTLs(now time.Time) []tea.Cmd {
+	if len(m.state.VMs) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(m.state.VMs))
+	for name := range m.state.VMs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var cmds []tea.Cmd
+	changed := false
+	for _, name := range names {
+		meta := m.state.VMs[name]
+		if meta.Expires.IsZero() {
+			continue
+		}
+		if _, busy := m.table.busyVMs[name]; busy {
+			continue
+		}
+		vm, known := m.table.vmByName(name)
+		if !known {
+			continue
+		}
+		remaining := meta.Expires.Sub(now)
+
+		if remaining > 0 {
+			if remaining <= ttlWarnBefore && !m.ttlWarned[name] {
+				if m.ttlWarned == nil {
+					m.ttlWarned = make(map[string]bool)
+				}
+				m.ttlWarned[name] = true
+				cmds = append(cmds, m.table.addToastFor(
+					fmt.Sprintf("⏳ %s will be %s in %s (TTL)", name, ttlActionPastTense(meta.TTLAction), formatRemaining(remaining)),
+					"info", 8*time.Second))
+			}
+			continue
+		}
+
+		m.state.clearTTL(name)
+		delete(m.ttlWarned, name)
+		changed = true
+		switch {
+		case meta.TTLAction == "stop" && vm.State == "Running":
+			m.table.busyVMs[name] = busyInfo{operation: "Stopping", startTime: now}
+			cmds = append(cmds, stopVMCmd(name))
+		case meta.TTLAction != "stop" && vm.State != "Deleted":
+			m.table.busyVMs[name] = busyInfo{operation: "Deleting", startTime: now}
+			cmds = append(cmds, softDeleteVMCmd(name))
+		default:
+			continue
+		}
+		cmds = append(cmds, m.table.addToast(fmt.Sprintf("⏳ %s TTL expired", name), "info"))
+	}
+	if changed {
+		m.persistState()
+	}
+	return cmds
+}
+
+func ttlActionPastTense(action string) string {
+	if action == "stop" {
+		return "stopped"
+	}
+	return "deleted"
+}
+
+// ─── Idle Detection ────────────────────────────────────────────────────────────
+
+// idlePolicy flags or stops VMs whose load stays low for a while. It is
+// configured in .config (idle-minutes, idle-load, idle-action) and disabled
+// when idle-minutes is unset or zero.
+type idlePolicy struct {
+	after     time.Duration // how long load must stay low
+	threshold float64       // load per CPU considered idle
+	stop      bool          // stop idle VMs instead of only flagging them
+}
+
+const defaultIdleLoad = 0.05
+
+// loadIdlePolicy reads the idle policy using lookup (normally configValue).
+func loadIdlePolicy(lookup func(string) (string, bool)) idlePolicy {
+	var p idlePolicy
+	if v, ok := lookup("idle-minutes"); ok {
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && n > 0 {
+			p.after = time.Duration(n) * time.Minute
+		}
+	}
+	p.threshold = defaultIdleLoad
+	if v, ok := lookup("idle-load"); ok {
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil && f > 0 {
+			p.threshold = f
+		}
+	}
+	if v, ok := lookup("idle-action"); ok {
+		p.stop = strings.EqualFold(strings.TrimSpace(v), "stop")
+	}
+	return p
+}
+
+func (p idlePolicy) enabled() bool {
+	return p.after > 0
+}
+
+// sample updates since, when each VM's load went under the threshold, from
+// vms and returns the running VMs that have stayed idle for p.after, with
+// the time they went idle. The TUI and the agent both call it every poll.
+func (p idlePolicy) sample(since map[string]time.Time, vms []vmData, now time.Time) map[string]time.Time {
+	idle := make(map[string]time.Time)
+	for _, vm := range vms {
+		name := vm.info.Name
+		frac, ok := parseCPULoadFraction(vm.info.Load, vm.info.CPUs)
+		if vm.info.State != "Running" || !ok || frac >= p.threshold {
+			delete(since, name)
+			continue
+		}
+		start, tracked := since[name]
+		if !tracked {
+			since[name] = now
+			continue
+		}
+		if now.Sub(start) >= p.after {
+			idle[name] = start
+		}
+	}
+	return idle
+}
+
+// sampleIdle updates idle tracking from the latest VM list and returns stop
+// commands for VMs that crossed the idle threshold when the policy says so.
+// An attached agent does this itself.
+func (m *rootModel) sampleIdle(now time.Time) []tea.Cmd {
+	if !m.idle.enabled() {
+		return nil
+	}
+	if m.idleSince == nil {
+		m.idleSince = make(map[string]time.Time)
+	}
+	idle := make(map[string]bool)
+	var cmds []tea.Cmd
+	for name, since := range m.idle.sample(m.idleSince, m.table.vms, now) {
+		idle[name] = true
+		if _, busy := m.table.busyVMs[name]; m.idle.stop && !busy {
+			m.table.busyVMs[name] = busyInfo{operation: "Stopping", startTime: now}
+			delete(m.idleSince, name)
+			cmds = append(cmds, stopVMCmd(name),
+				m.table.addToast(fmt.Sprintf("💤 Stopping %s: idle for %s", name, formatRemaining(now.Sub(since))), "info"))
+		}
+	}
+	m.table.idle = idle
+	return cmds
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckTTLs(t *testing.T) {
+	now := time.Now()
+	m := rootModel{table: newTableModel(), state: newAppState()}
+	m.table.meta = m.state.VMs
+	m.table.setVMs([]vmData{
+		{info: VMInfo{Name: "expired", State: "Running"}},
+		{info: VMInfo{Name: "soon", State: "Running"}},
+		{info: VMInfo{Name: "later", State: "Running"}},
+	})
+	m.state.setTTL("expired", now.Add(-time.Second), "stop")
+	m.state.setTTL("soon", now.Add(5*time.Minute), "delete")
+	m.state.setTTL("later", now.Add(5*time.Hour), "delete")
+
+	cmds := m.checkTTLs(now)
+	if len(cmds) == 0 {
+		t.Fatalf("expected commands for expired and warned VMs")
+	}
+	if busy, ok := m.table.busyVMs["expired"]; !ok || busy.operation != "Stopping" {
+		t.Fatalf("expected expired VM to be stopping, got %+v", busy)
+	}
+	if _, ok := m.state.VMs["expired"]; ok {
+		t.Fatalf("expected expired TTL to be cleared")
+	}
+	if !m.ttlWarned["soon"] || m.ttlWarned["later"] {
+		t.Fatalf("expected only the VM near expiry to be warned, got %v", m.ttlWarned)
+	}
+
+	// A second pass must not warn again.
+	before := len(m.table.toasts)
+	m.checkTTLs(now)
+	if len(m.table.toasts) != before {
+		t.Fatalf("expected no repeated warning")
+	}
+}
+
+func TestSampleIdle(t *testing.T) {
+	values := map[string]string{"idle-minutes": "10", "idle-action": "stop"}
+	policy := loadIdlePolicy(func(k string) (string, bool) { v, ok := values[k]; return v, ok })
+	if !policy.enabled() || !policy.stop || policy.threshold != defaultIdleLoad {
+		t.Fatalf("unexpected policy: %+v", policy)
+	}
+
+	now := time.Now()
+	m := rootModel{table: newTableModel(), idle: policy}
+	m.table.setVMs([]vmData{
+		{info: VMInfo{Name: "quiet", State: "Running", Load: "0.00 0.00 0.00", CPUs: "2"}},
+		{info: VMInfo{Name: "busy", State: "Running", Load: "1.50 1.00 0.80", CPUs: "2"}},
+	})
+
+	if cmds := m.sampleIdle(now); len(cmds) != 0 {
+		t.Fatalf("expected first sample to only start tracking")
+	}
+	if _, ok := m.idleSince["busy"]; ok {
+		t.Fatalf("busy VM should not be tracked as idle")
+	}
+
+	cmds := m.sampleIdle(now.Add(11 * time.Minute))
+	if len(cmds) == 0 || !m.table.idle["quiet"] {
+		t.Fatalf("expected quiet VM to be flagged and stopped")
+	}
+	if busy, ok := m.table.busyVMs["quiet"]; !ok || busy.operation != "Stopping" {
+		t.Fatalf("expected quiet VM to be stopping, got %+v", busy)
+	}
+}
+
+func TestSampleIdleLeftToAgent(t *testing.T) {
+	policy := idlePolicy{after: 10 * time.Minute, threshold: defaultIdleLoad, stop: true}
+	m := rootModel{currentView: viewTable, table: newTableModel(), idle: policy, agent: true,
+		idleSince: map[string]time.Time{"quiet": time.Now().Add(-time.Hour)}}
+	model, _ := m.Update(vmListResultMsg{vms: []vmData{
+		{info: VMInfo{Name: "quiet", State: "Running", Load: "0.00 0.00 0.00", CPUs: "2"}},
+	}, background: true})
+	if _, busy := model.(rootModel).table.busyVMs["quiet"]; busy {
+		t.Error("the attached agent stops idle VMs, not the TUI")
+	}
+}
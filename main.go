@@ -2,16 +2,20 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -42,6 +46,27 @@ const (
 	viewMountManage
 	viewMountAdd
 	viewMountModify
+	viewBulk
+	viewBridge
+	viewBackup
+	viewSnapSearch
+	viewWizard
+	viewExport
+	viewExec
+	viewFiles
+	viewAdopt
+	viewReapply
+	viewExpose
+	viewSnapClone
+	viewSnapCompare
+	viewUserDataImport
+	viewIPs
+	viewStuck
+	viewGallery
+	viewGolden
+	viewJobs
+	viewPager
+	viewQuit
 )
 
 // ─── Root Model ────────────────────────────────────────────────────────────────
@@ -65,14 +90,120 @@ type rootModel struct {
 	mountManage mountManageModel
 	mountAdd    mountAddModel
 	mountModify mountModifyModel
+	bulk        bulkProgressModel
+	jobs        jobsModel
+	pager       pagerModel
+	bridge      bridgeSettingsModel
+	backup      backupModel
+	snapSearch  snapSearchModel
+	wizard      wizardModel
+	export      exportModel
+	exec        execModel
+	files       filesModel
+	adopt       adoptModel
+	reapply     reapplyModel
+	expose      exposeModel
+	snapClone   snapCloneModel
+	snapCompare snapCompareModel
+	userData    userDataImportModel
+	ips         ipsModel
+	stuck       stuckModel
+	gallery     galleryModel
+	golden      goldenModel
+	quit        quitModel
 
 	// Pending operation for confirm dialogs
 	pendingCmd tea.Cmd
+	// View to return to when a confirm dialog is cancelled (defaults to the table)
+	confirmReturnView viewState
+	// Single-VM operation behind the confirm dialog, if any
+	pendingRow pendingRowOp
+	// Which operations ask first (see confirm_policy.go)
+	confirmLevel confirmLevel
 
 	// Context for returning to sub-views after operations
 	lastMountVM string
 	lastSnapVM  string
 
+	// Persistent per-VM metadata (TTL etc.) and where it is saved
+	state     appState
+	statePath string
+	stateBase appState        // the file as last read or written, to merge into the agent's writes
+	ttlWarned map[string]bool // VMs already warned about an upcoming TTL
+
+	// Idle detection policy and when each VM was first seen idle
+	idle      idlePolicy
+	idleSince map[string]time.Time
+
+	// How long a VM may stay Starting or Unknown before it is flagged, and
+	// since when each VM has been in its listed state (see stuck.go)
+	stuckAfter  time.Duration
+	stateClocks map[string]stateClock
+
+	// Limits on total resources across instances
+	quota resourceQuota
+
+	// Disk usage alert threshold
+	diskAlert diskAlertPolicy
+	// Who launched each VM, shared with other users (see owners.go)
+	owners ownerPolicy
+
+	// Optional TCP reachability checks of running VMs (see reachability.go)
+	reach         reachPolicy
+	reachInFlight bool
+	reachRecheck  bool // discard the check in flight and start another (see wake.go)
+
+	// Wall clock time of the last refresh tick, to notice a host suspend
+	lastTick time.Time
+
+	// Settings of launches in progress, by VM name (see relaunch.go)
+	launching map[string]lastLaunch
+
+	// Set once the user has been told which instances run EOL releases
+	eolWarned bool
+
+	// Startup banner (see health.go), shown until the first key on the table
+	health      hostHealth
+	bannerShown bool
+
+	// Change detection on each VM list (see events.go)
+	watcher vmWatcher
+	events  eventPolicy
+	touched map[string]time.Time // last time passgo itself changed each VM
+
+	// Prompting to adopt VMs created outside passgo (see adopt.go)
+	adoptPrompt  bool
+	adoptSkipped map[string]bool // answered "skip" this session
+
+	// Host ports forwarded to VMs (see expose.go)
+	forwards portForwards
+
+	// Read-only observer mode: mutating keys and automations are disabled
+	readOnly bool
+
+	// Custom key-bound actions from .config (see hooks.go)
+	actions []customAction
+	// Saved exec dialog commands from .config (see snippets.go)
+	snippets []execSnippet
+
+	// Scheduled snapshots from .config (see snapshot_schedule.go)
+	schedules          []snapshotSchedule
+	lastScheduleRun    time.Time
+	scheduleInFlight   bool
+	scheduleSkipWarned map[string]bool
+
+	// Working-hours start/stop schedules from .config (see power.go)
+	power          []powerSchedule
+	lastPowerCheck time.Time
+
+	// Cron jobs from .config (see jobs.go)
+	cronJobs     []cronJob
+	lastJobCheck time.Time
+	jobsRunning  map[string]bool
+
+	// A `passgo agent` runs the automations instead (see agent.go)
+	agent bool
+
 	// VM list fetch coordination (prevents overlapping fetch commands).
 	vmListFetchInFlight     bool
 	vmListFetchPending      bool
@@ -108,16 +239,161 @@ func (m *rootModel) setChildSizes() {
 	m.mountAdd.height = m.height
 	m.mountModify.width = m.width
 	m.mountModify.height = m.height
+	m.bulk.width = m.width
+	m.bulk.height = m.height
+	m.jobs.width = m.width
+	m.jobs.height = m.height
+	m.pager.setSize(m.width, m.height)
+	m.bridge.width = m.width
+	m.bridge.height = m.height
+	m.backup.width = m.width
+	m.backup.height = m.height
+	m.snapSearch.width = m.width
+	m.snapSearch.height = m.height
+	m.wizard.width = m.width
+	m.export.width = m.width
+	m.export.height = m.height
+	m.exec.width = m.width
+	m.exec.height = m.height
+	m.files.width = m.width
+	m.files.height = m.height
+	m.adopt.width = m.width
+	m.adopt.height = m.height
+	m.reapply.width = m.width
+	m.reapply.height = m.height
+	m.expose.width = m.width
+	m.expose.height = m.height
+	m.snapClone.width = m.width
+	m.snapClone.height = m.height
+	m.snapCompare.width = m.width
+	m.snapCompare.height = m.height
+	m.userData.width = m.width
+	m.userData.height = m.height
+	m.ips.width = m.width
+	m.ips.height = m.height
+	m.stuck.width = m.width
+	m.stuck.height = m.height
+	m.gallery.width = m.width
+	m.golden.width = m.width
+	m.golden.height = m.height
+	m.quit.width = m.width
+	m.quit.height = m.height
+	m.gallery.height = m.height
+	m.wizard.height = m.height
 }
 
 func initialModel() rootModel {
-	return rootModel{
-		currentView: viewLoading,
-		table:       newTableModel(),
-		loading:     newLoadingModel("Loading VMs…"),
+	m := rootModel{
+		currentView:  viewLoading,
+		table:        newTableModel(),
+		loading:      newLoadingModel("Loading VMs…"),
+		state:        newAppState(),
+		idle:         loadIdlePolicy(configValue),
+		quota:        loadResourceQuota(configValue),
+		diskAlert:    loadDiskAlertPolicy(configValue),
+		stuckAfter:   loadStuckThreshold(configValue),
+		reach:        loadReachPolicy(configValue),
+		owners:       loadOwnerPolicy(configValue),
+		confirmLevel: loadConfirmLevel(configValue),
+		events:       loadEventPolicy(configValue),
+		adoptPrompt:  adoptPromptEnabled(configValue),
+		bannerShown:  startupBannerEnabled(configValue),
+		forwards:     make(portForwards),
 		// Init schedules fetchVMListCmd immediately.
 		vmListFetchInFlight: true,
 	}
+	if m.reach.port != 0 {
+		m.table.columns = append(m.table.columns, reachColumn)
+	}
+	if m.owners.dir != "" {
+		m.table.columns = append(m.table.columns, ownerColumn)
+	}
+	if path, err := stateFilePath(); err == nil {
+		m.statePath = path
+		if st, err := loadState(path); err == nil {
+			m.state = st
+			m.stateBase = st.clone()
+		} else {
+			// Don't save over a file we could not read.
+			m.statePath = ""
+			if appLogger != nil {
+				appLogger.Printf("failed to load state, not saving it this session: %v", err)
+			}
+		}
+	}
+	m.table.meta = m.state.VMs
+	m.table.groupBy = loadTableGrouping(configValue)
+	schedules, errs := loadSnapshotSchedules(configList("snapshots"))
+	m.schedules = append(schedules, stateSchedules(m.state, schedules)...)
+	for _, err := range errs {
+		if appLogger != nil {
+			appLogger.Printf("ignoring snapshot schedule: %v", err)
+		}
+	}
+	m.power, errs = loadPowerSchedules(configList("power"))
+	for _, err := range errs {
+		if appLogger != nil {
+			appLogger.Printf("ignoring power schedule: %v", err)
+		}
+	}
+	m.cronJobs, errs = loadCronJobs(configList("job"))
+	for _, err := range errs {
+		if appLogger != nil {
+			appLogger.Printf("ignoring job: %v", err)
+		}
+	}
+	actions, errs := loadCustomActions(configList("action"), configList("action-tty"))
+	m.actions = actions
+	for _, err := range errs {
+		if appLogger != nil {
+			appLogger.Printf("ignoring custom action: %v", err)
+		}
+	}
+	snippets, errs := loadExecSnippets(configList("exec-snippet"))
+	m.snippets = snippets
+	for _, err := range errs {
+		if appLogger != nil {
+			appLogger.Printf("ignoring exec snippet: %v", err)
+		}
+	}
+	return m
+}
+
+// readOnlyKeys lists, per view, the keys that change instances or settings.
+// They are ignored in read-only mode.
+var readOnlyKeys = map[viewState][]string{
+	viewTable:       {"c", "L", "a", "C", "[", "]", "p", "<", ">", "x", "u", "d", "r", "!", "s", "n", "e", "B", "X", "R", "D", "P", "T", "N", "K"},
+	viewSnapManage:  {"enter"}, // opens restore/delete
+	viewMountManage: {"enter", "a", "d", "e"},
+	viewBridge:      {"enter", "c"},
+	viewGolden:      {"enter", "p", "d"},
+}
+
+// setReadOnly switches read-only mode on: besides the blocked keys, TTL
+// teardown, power schedules, cron jobs, idle stops and scheduled snapshots
+// are turned off.
+func (m *rootModel) setReadOnly() {
+	m.readOnly = true
+	m.table.readOnly = true
+	m.idle.stop = false
+	m.schedules = nil
+	m.actions = nil
+}
+
+// readOnlyBlocks reports whether key is a mutating action in the current view.
+func (m rootModel) readOnlyBlocks(key string) bool {
+	if m.currentView == viewTable && m.table.filterFocused {
+		return false
+	}
+	return slices.Contains(readOnlyKeys[m.currentView], key)
+}
+
+// startWizard opens the first-run setup wizard instead of the VM list; the
+// list is fetched once the wizard is done.
+func (m *rootModel) startWizard(rerun bool) {
+	m.wizard = newWizardModel(rerun, m.width, m.height)
+	m.currentView = viewWizard
+	m.vmListFetchInFlight = false
 }
 
 func (m *rootModel) requestVMListFetch(background bool) tea.Cmd {
@@ -154,17 +430,35 @@ func (m *rootModel) dequeuePendingVMListFetch() tea.Cmd {
 }
 
 func (m rootModel) Init() tea.Cmd {
-	return tea.Batch(
-		m.loading.Init(),
-		m.table.spinner.Tick,
-		fetchVMListCmd(),
-		autoRefreshTickCmd(),
-	)
+	cmds := []tea.Cmd{m.loading.Init(), autoRefreshTickCmd()}
+	if m.currentView == viewWizard {
+		cmds = append(cmds, m.wizard.Init())
+	} else {
+		cmds = append(cmds, fetchVMListCmd())
+	}
+	if v, ok := configValue("release-refresh"); !ok || parseConfigBool(v) {
+		cmds = append(cmds, refreshReleasesCmd())
+	}
+	cmds = append(cmds, fetchDriverCmd())
+	if !accessibleMode {
+		cmds = append(cmds, m.table.spinner.Tick)
+	}
+	return tea.Batch(cmds...)
 }
 
 // ─── Update ────────────────────────────────────────────────────────────────────
 
 func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if root, ok := next.(rootModel); ok {
+		if quit := root.quitIfIdle(); quit != nil {
+			return root, tea.Batch(cmd, quit)
+		}
+	}
+	return next, cmd
+}
+
+func (m rootModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	// ── Window resize ──
@@ -202,9 +496,27 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	// ── Auto-refresh tick ──
 	case autoRefreshTickMsg:
-		// Only auto-refresh when we're on the table view
+		// Only auto-refresh when we're on the table view, or the host has
+		// just woken from sleep
 		cmds := []tea.Cmd{autoRefreshTickCmd()} // always reschedule
-		if m.currentView == viewTable {
+		woke, wakeCmd := m.checkWake(time.Time(msg))
+		if wakeCmd != nil {
+			cmds = append(cmds, wakeCmd)
+		}
+		if cmd := m.checkAgent(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if !m.readOnly && !m.agent {
+			cmds = append(cmds, m.checkTTLs(time.Time(msg))...)
+			cmds = append(cmds, m.checkPowerSchedules(time.Time(msg))...)
+			cmds = append(cmds, m.checkCronJobs(time.Time(msg))...)
+		}
+		if !m.agent {
+			if cmd := m.checkSnapshotSchedules(time.Time(msg)); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+		if m.currentView == viewTable || woke {
 			if cmd := m.requestVMListFetch(true); cmd != nil {
 				cmds = append(cmds, cmd)
 			}
@@ -216,20 +528,143 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.vmListFetchInFlight = false
 
 		if msg.err != nil {
-			if !msg.background {
-				m.errModal = newErrorModel("VM List Error", msg.err.Error())
+			if m.table.lastRefresh.IsZero() && !msg.background {
+				m.errModal = newErrorModelFor("VM List Error", msg.err, "")
 				m.setChildSizes()
 				m.currentView = viewError
+				return m, m.dequeuePendingVMListFetch()
+			}
+			// Keep showing the last list, marked stale, rather than an
+			// error modal on every tick.
+			reason := staleReason(msg.err)
+			if m.table.refreshErr == "" && appLogger != nil {
+				appLogger.Printf("VM list refresh failed, showing stale data: %v", msg.err)
+			}
+			m.table.refreshErr = reason
+			if !msg.background {
+				m.currentView = viewTable
+				return m, tea.Batch(m.table.addToast("✗ Refresh failed: "+reason, "error"), m.dequeuePendingVMListFetch())
 			}
 		} else {
+			if m.table.refreshErr != "" && appLogger != nil {
+				appLogger.Printf("VM list refresh recovered")
+			}
+			m.table.refreshErr = ""
+			if m.agent {
+				m.reloadState()
+			}
+			m.refreshOwners(msg.vms)
 			m.table.setVMs(msg.vms)
 			m.table.lastRefresh = time.Now()
 			if !msg.background {
 				m.currentView = viewTable
 			}
+			cmds := m.handleVMEvents(msg.vms, m.table.lastRefresh)
+			if closed := m.forwards.closeStopped(msg.vms); len(closed) > 0 {
+				cmds = append(cmds, m.table.addToast("Closed port forwards of "+strings.Join(closed, ", ")+" (not running)", "info"))
+			}
+			if !m.agent {
+				cmds = append(cmds, m.sampleIdle(m.table.lastRefresh)...)
+			}
+			if !m.agent && m.state.sampleUsage(msg.vms, m.table.lastRefresh) {
+				m.persistState()
+			}
+			cmds = append(cmds, m.checkDiskUsage()...)
+			cmds = append(cmds, m.checkStuck(m.table.lastRefresh)...)
+			m.updateBanner()
+			if cmd := m.startReachabilityCheck(); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			if cmd := m.maybePromptAdopt(); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			if !m.eolWarned {
+				m.eolWarned = true
+				if eol := eolInstances(msg.vms, m.table.lastRefresh); len(eol) > 0 {
+					cmds = append(cmds, m.table.addToastFor(fmt.Sprintf("⚠ %d instance(s) on EOL Ubuntu releases: %s",
+						len(eol), strings.Join(eol, ", ")), "error", 10*time.Second))
+				}
+			}
+			if len(cmds) > 0 {
+				return m, tea.Batch(append(cmds, m.dequeuePendingVMListFetch())...)
+			}
 		}
 		return m, m.dequeuePendingVMListFetch()
 
+	case snapshotScheduleResultMsg:
+		return m, m.handleSnapshotScheduleResult(msg.report)
+
+	case cronJobResultMsg:
+		return m, m.handleCronJobResult(msg)
+
+	case adoptDecisionMsg:
+		return m, m.handleAdoptDecision(msg)
+
+	case reachabilityMsg:
+		return m, m.applyReachability(msg.results)
+
+	case driverInfoMsg:
+		m.table.driver = msg.driver
+		m.health = hostHealth{known: true, client: msg.client, daemon: msg.daemon, driver: msg.driver}
+		m.updateBanner()
+		return m, nil
+
+	case releasesRefreshedMsg:
+		if msg.err != nil {
+			if appLogger != nil {
+				appLogger.Printf("release table refresh failed: %v", msg.err)
+			}
+			return m, nil
+		}
+		activeReleases = msg.releases
+		return m, nil
+
+	case bridgeSettingsResultMsg:
+		if msg.err != nil {
+			m.errModal = newErrorModelFor("Networks Error", msg.err, "")
+			m.setChildSizes()
+			m.currentView = viewError
+			return m, nil
+		}
+		m.bridge = newBridgeSettingsModel(msg.networks, msg.current, msg.driver)
+		m.setChildSizes()
+		m.currentView = viewBridge
+		return m, nil
+
+	case hyperVSwitchRequestMsg:
+		c := newConfirmModel(fmt.Sprintf("Create the external Hyper-V switch %s on %s and make it the default bridged network? The host's connection drops for a few seconds while Windows moves it to the switch.",
+			hyperVSwitchName(msg.adapter), msg.adapter))
+		return m, m.confirmFirst(true, c, createHyperVSwitchCmd(msg.adapter), viewBridge)
+
+	case bridgeSetResultMsg:
+		m.currentView = viewTable
+		if errors.Is(msg.err, errHyperVNeedsAdmin) {
+			m.errModal = newErrorModelFor("Hyper-V Switch", msg.err, "")
+			m.setChildSizes()
+			m.currentView = viewError
+			return m, nil
+		}
+		if msg.err != nil {
+			return m, m.table.addToast(fmt.Sprintf("✗ Setting bridged network failed: %s", msg.err.Error()), "error")
+		}
+		return m, m.table.addToast(fmt.Sprintf("✓ Default bridged network set to %s", msg.name), "success")
+
+	case vmNetworkResultMsg:
+		if m.currentView == viewInfo {
+			var cmd tea.Cmd
+			m.info, cmd = m.info.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case diagnosticsResultMsg:
+		if m.currentView == viewInfo {
+			var cmd tea.Cmd
+			m.info, cmd = m.info.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
 	case vmInfoResultMsg:
 		if m.currentView == viewInfo {
 			// Delegate to info model for live chart updates
@@ -237,13 +672,7 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.info, cmd = m.info.Update(msg)
 			return m, cmd
 		}
-		if msg.err != nil {
-			m.errModal = newErrorModel("Info Error", msg.err.Error())
-			m.setChildSizes()
-			m.currentView = viewError
-		} else {
-			m.info.setContent(msg.info)
-		}
+		// A background refresh that finished after the info view closed.
 		return m, nil
 
 	case vmOperationResultMsg:
@@ -253,26 +682,54 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			elapsed = time.Since(busy.startTime)
 		}
 		delete(m.table.busyVMs, msg.vmName)
+		m.touchVM(msg.vmName)
+		if m.bulk.active() && msg.operation == m.bulk.operation {
+			m.bulk.done = true
+			m.table.backgroundStatus = ""
+		}
+
+		// Record the operation, and forget what we recorded about VMs that
+		// failed to launch or were purged.
+		m.state.recordOp(msg.vmName, msg.operation, msg.trace, msg.err, time.Now())
+		if (msg.operation == "create" && msg.err != nil) || (msg.operation == "delete" && msg.err == nil) {
+			m.state.forget(msg.vmName)
+			m.owners.forget(msg.vmName)
+		}
+		if msg.operation == "create" {
+			m.settleLaunch(msg.vmName, msg.err == nil)
+		}
+		m.persistState()
 
 		if msg.err != nil {
-			// Toast the error too
+			// Toast the error too; l reopens it in full
+			m.errModal = newErrorModelFor("Operation Error", msg.err, msg.trace)
+			m.setChildSizes()
 			toastCmd := m.table.addToast(
-				fmt.Sprintf("✗ %s failed: %s", msg.operation, msg.err.Error()), "error")
+				fmt.Sprintf("✗ %s failed: %s (l: details)", msg.operation, shortError(msg.err)), "error")
+			if cmd := m.escalateStop(msg.vmName, msg.operation, msg.err); cmd != nil {
+				toastCmd = tea.Batch(toastCmd, cmd)
+			}
 			if msg.inline {
 				if refreshCmd := m.requestVMListFetch(true); refreshCmd != nil {
 					return m, tea.Batch(toastCmd, refreshCmd)
 				}
 				return m, toastCmd
 			}
-			m.errModal = newErrorModel("Operation Error", msg.err.Error())
-			m.setChildSizes()
 			m.currentView = viewError
 			return m, toastCmd
 		}
 
-		// Build toast message
-		toastMsg := operationToastMessage(msg.vmName, msg.operation, elapsed)
-		toastCmd := m.table.addToast(toastMsg, "success")
+		// Build toast message (soft deletes get an undo toast instead)
+		var toastCmd tea.Cmd
+		if msg.operation == "soft-delete" {
+			toastCmd = m.table.setUndo(msg.vmName)
+		} else {
+			toastMsg := operationToastMessage(msg.vmName, msg.operation, elapsed)
+			toastCmd = m.table.addToast(toastMsg, "success")
+		}
+		if pin := m.state.VMs[msg.vmName].Image; msg.operation == "create" && pin != nil && !pin.pinned() {
+			toastCmd = tea.Batch(toastCmd, pinImageCmd(msg.vmName, pin.Release))
+		}
 
 		// Inline operations: stay on table, refresh in background
 		if msg.inline {
@@ -305,9 +762,46 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, tea.Batch(m.loading.Init(), toastCmd)
 
+	case launchProgressMsg:
+		if busy, ok := m.table.busyVMs[msg.vmName]; ok {
+			busy.phase = msg.phase
+			busy.hasPhase = true
+			m.table.busyVMs[msg.vmName] = busy
+		}
+		return m, waitForEventCmd(msg.events)
+
+	case repairProgressMsg:
+		if busy, ok := m.table.busyVMs[msg.vmName]; ok {
+			busy.detail = msg.line
+			m.table.busyVMs[msg.vmName] = busy
+		}
+		return m, waitForEventCmd(msg.events)
+
+	case bulkStartMsg:
+		m.bulk = newBulkProgressModel(msg.operation, msg.names)
+		m.setChildSizes()
+		m.table.backgroundStatus = m.bulk.summary()
+		m.currentView = viewBulk
+		return m, bulkVMCmd(msg)
+
+	case bulkProgressMsg:
+		m.bulk.setStatus(msg.index, msg.status, msg.err)
+		if msg.index >= 0 && msg.index < len(m.bulk.items) {
+			name := m.bulk.items[msg.index].name
+			m.touchVM(name)
+			// As for a single purge, forget what was recorded about it.
+			if msg.status == bulkOK && m.bulk.operation == "purge-all" {
+				m.state.forget(name)
+				m.owners.forget(name)
+				m.persistState()
+			}
+		}
+		m.table.backgroundStatus = m.bulk.summary()
+		return m, waitForEventCmd(msg.events)
+
 	case snapshotListResultMsg:
 		if msg.err != nil {
-			m.errModal = newErrorModel("Snapshot Error", msg.err.Error())
+			m.errModal = newErrorModelFor("Snapshot Error", msg.err, "")
 			m.setChildSizes()
 			m.currentView = viewError
 		} else {
@@ -317,9 +811,27 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case allSnapshotsResultMsg:
+		if msg.err != nil {
+			m.errModal = newErrorModelFor("Snapshot Error", msg.err, "")
+			m.setChildSizes()
+			m.currentView = viewError
+			return m, nil
+		}
+		m.snapSearch = newSnapSearchModel(msg.snapshots, m.width, m.height)
+		m.currentView = viewSnapSearch
+		return m, m.snapSearch.Init()
+
+	case snapSearchOpenMsg:
+		m.lastSnapVM = msg.vmName
+		m.loading = newLoadingModel("Loading snapshots…")
+		m.setChildSizes()
+		m.currentView = viewLoading
+		return m, tea.Batch(m.loading.Init(), fetchSnapshotsCmd(msg.vmName))
+
 	case mountListResultMsg:
 		if msg.err != nil {
-			m.errModal = newErrorModel("Mount Error", msg.err.Error())
+			m.errModal = newErrorModelFor("Mount Error", msg.err, "")
 			m.setChildSizes()
 			m.currentView = viewError
 		} else {
@@ -329,6 +841,20 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case customActionResultMsg:
+		if msg.err != nil {
+			detail := msg.err.Error()
+			if msg.output != "" {
+				detail = msg.output
+			}
+			return m, m.table.addToastFor(msg.label+" failed: "+detail, "error", 8*time.Second)
+		}
+		text := msg.label + " done"
+		if msg.output != "" {
+			text += ": " + msg.output
+		}
+		return m, m.table.addToast(text, "success")
+
 	case shellFinishedMsg:
 		m.loading = newLoadingModel("Refreshing…")
 		m.setChildSizes()
@@ -339,50 +865,447 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, m.loading.Init()
 
 	case confirmResultMsg:
+		row := m.pendingRow
+		m.pendingRow = pendingRowOp{}
 		if msg.confirmed && m.pendingCmd != nil {
 			cmd := m.pendingCmd
 			m.pendingCmd = nil
+			m.confirmReturnView = viewTable
+			if row.vmName != "" {
+				m.table.busyVMs[row.vmName] = busyInfo{operation: row.op, startTime: time.Now()}
+				m.currentView = viewTable
+				return m, cmd
+			}
 			m.loading = newLoadingModel("Processing…")
 			m.setChildSizes()
 			m.currentView = viewLoading
 			return m, tea.Batch(m.loading.Init(), cmd)
 		}
 		m.pendingCmd = nil
+		m.currentView = m.confirmReturnView
+		m.confirmReturnView = viewTable
+		return m, nil
+
+	case imagePinMsg:
+		if msg.err != nil {
+			if appLogger != nil {
+				appLogger.Printf("could not record the image of %s: %v", msg.pin.VM, msg.err)
+			}
+			return m, nil
+		}
+		m.state.notePin(msg.pin)
+		m.persistState()
+		return m, nil
+
+	case imageDriftMsg:
+		if msg.warning == "" {
+			return m, nil
+		}
+		return m, m.table.addToastFor("⚠ "+msg.warning, "error", 8*time.Second)
+
+	case exportRequestMsg:
+		headers, rows := m.table.exportTable()
+		if err := writeExport(msg.path, msg.format, headers, rows); err != nil {
+			m.export.errMsg = err.Error()
+			return m, nil
+		}
+		m.currentView = viewTable
+		return m, m.table.addToast(fmt.Sprintf("✓ Exported %d VMs to %s", len(rows), msg.path), "success")
+
+	case backupRequestMsg:
+		m.table.busyVMs[msg.vmName] = busyInfo{operation: "Backing up", startTime: time.Now()}
+		m.currentView = viewTable
+		return m, backupVMCmd(msg.vmName, msg.paths, msg.dir)
+
+	case backupRestoreRequestMsg:
+		vm, _ := m.table.vmByName(msg.vmName)
+		details := append(destructiveContext(vm, 0), "Archive: "+msg.archive.Name)
+		if cmd, ok := m.guardOwner(msg.vmName, "Restore files on", restoreBackupCmd(msg.vmName, msg.archive.Path), pendingRowOp{}, viewBackup); ok {
+			return m, cmd
+		}
+		c := newTypedConfirmModel(
+			fmt.Sprintf("Restore '%s' from backup? Files in the archive will be overwritten.", msg.vmName),
+			msg.vmName, details)
+		return m, m.confirmFirst(true, c, restoreBackupCmd(msg.vmName, msg.archive.Path), viewBackup)
+
+	case reapplyRequestMsg:
+		what := "clean cloud-init and re-run every stage"
+		if len(msg.modules) > 0 {
+			what = "re-run " + strings.Join(msg.modules, ", ")
+		}
+		cmd := reapplyCloudInitCmd(msg.vmName, msg.template.Path, msg.modules, msg.cleanupDirs)
+		if cmd, ok := m.guardOwner(msg.vmName, "Re-apply cloud-init to", cmd, pendingRowOp{}, viewReapply); ok {
+			return m, cmd
+		}
+		c := newConfirmModel(fmt.Sprintf("Apply %s to '%s' and %s?", msg.template.Label, msg.vmName, what))
+		return m, m.confirmFirst(true, c, cmd, viewReapply)
+
+	case exposeRequestMsg:
+		f, err := startPortForward(msg.vmName, msg.ip, msg.vmPort, msg.hostPort)
+		if err != nil {
+			m.expose.errMsg = err.Error()
+			return m, nil
+		}
+		m.forwards.add(f)
+		m.currentView = viewTable
+		copied := ""
+		if clipboard.WriteAll(f.url()) == nil {
+			copied = " (copied)"
+		}
+		return m, m.table.addToastFor(fmt.Sprintf("✓ %s → %s:%d%s", f.url(), msg.vmName, msg.vmPort, copied), "success", 10*time.Second)
+
+	case unexposeRequestMsg:
+		m.forwards.remove(msg.vmName, msg.vmPort)
+		m.currentView = viewTable
+		return m, m.table.addToast(fmt.Sprintf("✓ Closed forward to %s:%d", msg.vmName, msg.vmPort), "success")
+
+	case galleryResultMsg:
+		if msg.err != nil {
+			m.errModal = newErrorModelFor("Gallery Error", msg.err, "")
+			m.setChildSizes()
+			m.currentView = viewError
+			return m, nil
+		}
+		m.gallery = newGalleryModel(msg.source, msg.index, m.width, m.height)
+		m.currentView = viewGallery
+		return m, nil
+
+	case galleryPickMsg:
+		m.loading = newLoadingModel("Downloading " + msg.entry.label() + "…")
+		m.setChildSizes()
+		m.currentView = viewLoading
+		return m, tea.Batch(m.loading.Init(), fetchGalleryTemplateCmd(msg.source, msg.indexName, msg.entry))
+
+	case galleryTemplateMsg:
+		if msg.err != nil {
+			m.errModal = newErrorModelFor("Gallery Error", msg.err, "")
+			m.setChildSizes()
+			m.currentView = viewError
+			return m, nil
+		}
+		m.advCreate = newAdvCreateModel(m.width, m.height, m.state.Recent, m.nameTaken)
+		m.advCreate.pins = m.state.Pins
+		m.advCreate.useGalleryTemplate(msg.source, msg.entry, msg.path, msg.dir)
+		m.currentView = viewAdvCreate
+		return m, m.advCreate.Init()
+
+	case dockerHostResultMsg:
+		delete(m.table.busyVMs, msg.vmName)
+		m.touchVM(msg.vmName)
+		refreshCmd := m.requestVMListFetch(true)
+		if msg.err != nil {
+			return m, tea.Batch(refreshCmd, m.table.addToastFor("✗ Docker host "+msg.vmName+": "+msg.err.Error(), "error", 8*time.Second))
+		}
+		return m, tea.Batch(refreshCmd, m.table.addToastFor(fmt.Sprintf("✓ docker now uses %s (context %s)", msg.vmName, msg.context), "success", 8*time.Second))
+
+	case backupResultMsg:
+		delete(m.table.busyVMs, msg.vmName)
+		m.currentView = viewTable
+		switch {
+		case msg.err != nil && msg.restore:
+			return m, m.table.addToastFor("✗ Restore of "+msg.vmName+" failed: "+msg.err.Error(), "error", 8*time.Second)
+		case msg.err != nil:
+			return m, m.table.addToastFor("✗ Backup of "+msg.vmName+" failed: "+msg.err.Error(), "error", 8*time.Second)
+		case msg.restore:
+			return m, m.table.addToast(fmt.Sprintf("✓ %s restored from %s", msg.vmName, filepath.Base(msg.archive)), "success")
+		}
+		return m, m.table.addToastFor("✓ Backed up "+msg.vmName+" to "+msg.archive, "success", 8*time.Second)
+
+	case snapRestoreRequestMsg:
+		vm, _ := m.table.vmByName(msg.vmName)
+		if vm.Name == "" {
+			vm.Name = msg.vmName
+		}
+		return m, restorePreviewCmd(vm, msg.snapName, m.snapManage.snapshots)
+
+	case snapCloneRequestMsg:
+		if vm, _ := m.table.vmByName(msg.vmName); vm.State != "Stopped" {
+			m.errModal = newErrorModel("Clone Error", fmt.Sprintf("VM '%s' must be stopped to clone it.", msg.vmName))
+			m.setChildSizes()
+			m.currentView = viewError
+			return m, nil
+		}
+		exists := func(name string) bool {
+			_, ok := m.table.vmByName(name)
+			return ok
+		}
+		m.snapClone = newSnapCloneModel(msg.vmName, msg.snapName, exists, loadNamingPolicy(configValue), m.width, m.height)
+		m.currentView = viewSnapClone
+		return m, m.snapClone.Init()
+
+	case snapCloneSubmitMsg:
+		// Each clone gets the source's resources.
+		source, _ := m.table.vmByName(msg.vmName)
+		req := allocatedResources([]vmData{{info: source}}, m.state.VMs).times(len(msg.names))
+		quotaCmd, ok := m.enforceQuota(req)
+		if !ok {
+			return m, nil
+		}
+		m.table.busyVMs[msg.vmName] = busyInfo{operation: "Cloning", startTime: time.Now()}
+		for _, name := range msg.names {
+			m.table.vms = append(m.table.vms, vmData{info: VMInfo{Name: name, State: "Creating"}})
+			m.table.busyVMs[name] = busyInfo{operation: "Cloning", startTime: time.Now()}
+		}
+		m.table.applyFilterAndSort()
+		m.lastSnapVM = ""
 		m.currentView = viewTable
+		return m, tea.Batch(cloneFromSnapshotCmd(msg.vmName, msg.snapName, msg.names), quotaCmd)
+
+	case snapCompareRequestMsg:
+		if vm, _ := m.table.vmByName(msg.vmName); vm.State != "Stopped" {
+			m.errModal = newErrorModel("Compare Error", fmt.Sprintf("VM '%s' must be stopped to compare its snapshots.", msg.vmName))
+			m.setChildSizes()
+			m.currentView = viewError
+			return m, nil
+		}
+		m.snapCompare = newSnapCompareModel(msg.vmName, msg.snapName, m.width, m.height)
+		m.currentView = viewSnapCompare
+		return m, m.snapCompare.Init()
+
+	case snapCompareSubmitMsg:
+		was, now, err := compareCloneNames(msg.vmName, loadNamingPolicy(configValue))
+		if err != nil {
+			return m, m.namingError(err)
+		}
+		for _, name := range []string{msg.vmName, was, now} {
+			m.table.busyVMs[name] = busyInfo{operation: "Comparing", startTime: time.Now()}
+		}
+		m.lastSnapVM = ""
+		m.currentView = viewTable
+		return m, tea.Batch(
+			compareSnapshotCmd(msg.vmName, msg.snapName, msg.paths, was, now),
+			m.table.addToast(fmt.Sprintf("Comparing %s@%s with now: this starts two temporary clones", msg.vmName, msg.snapName), "info"))
+
+	case snapCompareResultMsg:
+		delete(m.table.busyVMs, msg.diff.vm)
+		m.touchVM(msg.diff.vm)
+		for _, name := range msg.clones {
+			delete(m.table.busyVMs, name)
+			m.touchVM(name)
+		}
+		refreshCmd := m.requestVMListFetch(true)
+		if msg.err != nil {
+			return m, tea.Batch(refreshCmd, m.table.addToastFor("✗ Comparing "+msg.diff.vm+"@"+msg.diff.snap+" failed: "+msg.err.Error(), "error", 10*time.Second))
+		}
+		report := msg.diff.report()
+		if appLogger != nil {
+			appLogger.Printf("snapshot comparison:\n%s", report)
+		}
+		toast := m.table.addToast(fmt.Sprintf("✓ %s since %s: %s", msg.diff.vm, msg.diff.snap, msg.diff.summary()), "success")
+		if m.currentView != viewTable {
+			// Don't pull the user out of another view; the report is in the log.
+			return m, tea.Batch(refreshCmd, toast)
+		}
+		title := fmt.Sprintf("%s: %s vs now", msg.diff.vm, msg.diff.snap)
+		file := "passgo-" + msg.diff.vm + "-" + msg.diff.snap + "-diff.txt"
+		return m, tea.Batch(refreshCmd, toast, func() tea.Msg { return openPagerMsg{title: title, text: report, fileName: file} })
+
+	case stuckActionMsg:
+		m.currentView = viewTable
+		switch msg.action {
+		case stuckForceStop:
+			return m, m.confirmForceStop(msg.vmName, "")
+		case stuckTrash:
+			if cmd, ok := m.guardOwner(msg.vmName, "Delete", softDeleteVMCmd(msg.vmName), pendingRowOp{msg.vmName, "Deleting"}, viewTable); ok {
+				return m, cmd
+			}
+			return m, m.confirmRowOp(msg.vmName, "Deleting", fmt.Sprintf("Move '%s' to the trash?", msg.vmName), softDeleteVMCmd(msg.vmName))
+		}
+		return m, nil
+
+	case ipsCopiedMsg:
+		return m, m.table.addToast(fmt.Sprintf("✓ Copied %d VMs as %s", msg.count, msg.format), "success")
+
+	case userDataImportSubmitMsg:
+		m.currentView = viewTable
+		return m, importUserDataCmd(msg.vmName, msg.name)
+
+	case userDataImportedMsg:
+		if msg.err != nil {
+			return m, m.table.addToastFor("✗ Saving the cloud-init of "+msg.vmName+" failed: "+msg.err.Error(), "error", 10*time.Second)
+		}
+		if appLogger != nil {
+			appLogger.Printf("saved the user-data of %s as %s", msg.vmName, msg.path)
+		}
+		return m, m.table.addToast("✓ Saved the cloud-init of "+msg.vmName+" as "+msg.path, "success")
+
+	case relaunchErrMsg:
+		delete(m.launching, msg.name)
+		return m, m.table.addToastFor("✗ Repeat launch: "+msg.err.Error(), "error", 10*time.Second)
+
+	case goldenPromoteRequestMsg:
+		m.table.busyVMs[msg.vmName] = busyInfo{operation: "Promoting", startTime: time.Now()}
+		m.currentView = viewTable
+		return m, promoteGoldenCmd(msg.vmName, msg.name)
+
+	case goldenPromotedMsg:
+		delete(m.table.busyVMs, msg.golden.Source)
+		m.touchVM(msg.golden.Source)
+		refreshCmd := m.requestVMListFetch(true)
+		if msg.err != nil {
+			return m, tea.Batch(refreshCmd, m.table.addToastFor("✗ Promoting "+msg.golden.Source+" failed: "+msg.err.Error(), "error", 10*time.Second))
+		}
+		m.state.addGolden(msg.golden)
+		m.persistState()
+		return m, tea.Batch(refreshCmd, m.table.addToast(fmt.Sprintf("✓ %s is golden image %s; I to clone VMs from it", msg.golden.Source, msg.golden.Name), "success"))
+
+	case goldenRemoveRequestMsg:
+		q := fmt.Sprintf("Remove golden image '%s'? Snapshot %s.%s is deleted.", msg.golden.Name, msg.golden.Source, msg.golden.Snapshot)
+		if !msg.sourceExists {
+			q = fmt.Sprintf("Remove golden image '%s'? Its source %s is gone.", msg.golden.Name, msg.golden.Source)
+		}
+		return m, m.confirmFirst(true, newConfirmModel(q), removeGoldenCmd(msg.golden, msg.sourceExists), viewGolden)
+
+	case goldenRemovedMsg:
+		m.currentView = viewTable
+		if msg.err != nil {
+			return m, m.table.addToastFor("✗ Removing golden image "+msg.name+" failed: "+msg.err.Error(), "error", 10*time.Second)
+		}
+		m.state.removeGolden(msg.name)
+		m.persistState()
+		return m, tea.Batch(m.requestVMListFetch(true), m.table.addToast("✓ Removed golden image "+msg.name, "success"))
+
+	case cloneResultMsg:
+		delete(m.table.busyVMs, msg.source)
+		m.touchVM(msg.source)
+		for _, name := range msg.names {
+			delete(m.table.busyVMs, name)
+			m.touchVM(name)
+		}
+		refreshCmd := m.requestVMListFetch(true)
+		if msg.err != nil {
+			return m, tea.Batch(refreshCmd, m.table.addToastFor("✗ Clone of "+msg.source+" failed: "+msg.err.Error(), "error", 10*time.Second))
+		}
+		for _, name := range msg.names {
+			m.state.addNote(name, fmt.Sprintf("cloned from %s at snapshot %s", msg.source, msg.snap))
+			m.recordOwner(name)
+		}
+		m.persistState()
+		return m, tea.Batch(refreshCmd, m.table.addToast(fmt.Sprintf("✓ Cloned %s@%s into %s", msg.source, msg.snap, strings.Join(msg.names, ", ")), "success"))
+
+	case deletePreviewMsg:
+		if m.currentView != viewTable {
+			return m, nil
+		}
+		return m, m.confirmDelete(msg.vm, msg.uptime)
+
+	case restorePreviewMsg:
+		vm, _ := m.table.vmByName(msg.vmName)
+		details := append(destructiveContext(vm, 0), "Restore target: "+msg.snapName)
+		details = append(details, msg.lines...)
+		if cmd, ok := m.guardOwner(msg.vmName, "Restore", restoreSnapshotCmd(msg.vmName, msg.snapName), pendingRowOp{}, viewSnapManage); ok {
+			return m, cmd
+		}
+		c := newTypedConfirmModel(
+			fmt.Sprintf("Restore '%s' to snapshot '%s'? Current state will be discarded.", msg.vmName, msg.snapName),
+			msg.vmName, details)
+		return m, m.confirmFirst(true, c, restoreSnapshotCmd(msg.vmName, msg.snapName), viewSnapManage)
+
+	case shutdownSignalMsg:
+		if appLogger != nil {
+			appLogger.Printf("received %v", msg.sig)
+		}
+		return m.requestQuit()
+
+	case quitChoiceMsg:
+		switch {
+		case msg.abandon:
+			if appLogger != nil {
+				appLogger.Printf("quitting, abandoning: %s", strings.Join(m.inFlight(), "; "))
+			}
+			return m, tea.Quit
+		case msg.wait:
+			m.quit.waiting = true
+			return m, nil
+		}
+		m.currentView = m.quit.from
+		m.quit = quitModel{}
+		return m, nil
+
+	case agentJobsMsg:
+		if msg.err != nil {
+			return m, m.table.addToast("✗ Agent jobs: "+shortError(msg.err), "error")
+		}
+		m.showJobs(msg.jobs)
+		return m, nil
+
+	case jobsRefreshMsg:
+		return m, m.openJobs()
+
+	case openPagerMsg:
+		m.pager = newPagerModel(msg.title, msg.text, msg.fileName, m.currentView)
+		m.setChildSizes()
+		m.currentView = viewPager
+		return m, nil
+
+	case pagerClosedMsg:
+		m.currentView = m.pager.returnTo
 		return m, nil
 
 	case backToTableMsg:
 		m.lastMountVM = ""
 		m.lastSnapVM = ""
+		if m.currentView == viewBulk && m.bulk.active() {
+			m.currentView = viewTable
+			return m, m.table.addToast(m.bulk.operation+" continues in background — b to view", "info")
+		}
 		m.currentView = viewTable
 		return m, nil
 
 	case advCreateMsg:
+		req := resources{msg.cpus, msg.memoryMB, msg.diskGB}
+		quotaCmd, ok := m.enforceQuota(req)
+		if !ok {
+			return m, nil
+		}
 		// Return to table with placeholder row and busy animation
 		placeholder := vmData{info: VMInfo{Name: msg.name, State: "Creating"}}
 		m.table.vms = append(m.table.vms, placeholder)
 		m.table.applyFilterAndSort()
-		for i, vm := range m.table.filteredVMs {
-			if vm.info.Name == msg.name {
-				m.table.cursor = i
-				visible := m.table.visibleRows()
-				if m.table.cursor >= m.table.offset+visible {
-					m.table.offset = m.table.cursor - visible + 1
-				}
-				break
-			}
-		}
+		m.table.revealVM(msg.name)
 		m.table.busyVMs[msg.name] = busyInfo{operation: "Creating", startTime: time.Now()}
 		m.currentView = viewTable
-		return m, advancedCreateCmd(msg.name, msg.release, msg.cpus, msg.memoryMB, msg.diskGB, msg.cloudInitFile, msg.networkName)
+		m.state.noteLaunch(msg.release, msg.template)
+		m.noteLaunching(msg)
+		if m.state.VMs != nil {
+			m.state.setPendingPin(msg.name, msg.release)
+		}
+		if msg.projectDir != "" && m.state.VMs != nil {
+			m.state.setProject(msg.name, msg.projectDir, msg.projectTarget)
+		}
+		m.recordLaunch(msg.name, req, msg.ttl, msg.ttlAction)
+		if msg.provenance != "" && m.state.VMs != nil {
+			m.state.addNote(msg.name, "Template: "+msg.provenance)
+			m.persistState()
+		}
+		var driftCmd tea.Cmd
+		if msg.quick {
+			driftCmd = checkImageDriftCmd(msg.release, m.state.Pins)
+		}
+		return m, tea.Batch(advancedCreateCmd(msg.name, msg.release, msg.cpus, msg.memoryMB, msg.diskGB, msg.cloudInitFile, msg.networks,
+			msg.projectDir, msg.projectTarget), quotaCmd, driftCmd)
+
+	case wizardDoneMsg:
+		m.loading = newLoadingModel("Loading VMs…")
+		m.setChildSizes()
+		m.currentView = viewLoading
+		cmds := []tea.Cmd{m.loading.Init(), m.requestVMListFetch(false)}
+		if msg.skipped {
+			cmds = append(cmds, m.table.addToast("Setup skipped; run passgo -setup to configure later", "info"))
+		} else {
+			cmds = append(cmds, m.table.addToast("Saved settings to "+msg.path, "success"))
+		}
+		return m, tea.Batch(cmds...)
 
 	case mountAddRequestMsg:
 		m.mountAdd = newMountAddModel(msg.vmName, m.width, m.height)
+		m.mountAdd.opts.types = capsFor(m.table.driver).mountTypes()
 		m.currentView = viewMountAdd
 		return m, nil
 
 	case mountModifyRequestMsg:
 		m.mountModify = newMountModifyModel(msg.vmName, msg.mount, m.width, m.height)
+		m.mountModify.opts.types = capsFor(m.table.driver).mountTypes()
 		m.currentView = viewMountModify
 		return m, m.mountModify.Init()
 
@@ -391,8 +1314,10 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.setChildSizes()
 		m.currentView = viewLoading
 		return m, tea.Batch(m.loading.Init(), func() tea.Msg {
-			err := runMountModifyOperation(runMultipassCommand, msg.vmName, msg.oldTarget, msg.newSource, msg.newTarget)
-			return vmOperationResultMsg{vmName: msg.vmName, operation: "mount", err: err}
+			trace, err := traceOp("mount", []string{msg.vmName}, func() error {
+				return runMountModifyOperation(runMultipassCommand, msg.vmName, msg.oldTarget, msg.newSource, msg.newTarget, msg.opts)
+			})
+			return vmOperationResultMsg{vmName: msg.vmName, operation: "mount", err: err, trace: trace}
 		})
 	}
 
@@ -407,31 +1332,99 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case viewTable:
 		// Forward spinner ticks to the table for inline busy indicators
 		var cmd tea.Cmd
-		m.table, cmd = m.table.Update(msg)
+		m.table, cmd = m.table.Update(msg)
+		return m, cmd
+	case viewLoading:
+		var cmd tea.Cmd
+		m.loading, cmd = m.loading.Update(msg)
+		return m, cmd
+	case viewInfo:
+		var cmd tea.Cmd
+		m.info, cmd = m.info.Update(msg)
+		return m, cmd
+	case viewConfirm:
+		var cmd tea.Cmd
+		m.confirm, cmd = m.confirm.Update(msg)
+		return m, cmd
+	case viewAdvCreate:
+		var cmd tea.Cmd
+		m.advCreate, cmd = m.advCreate.Update(msg)
+		return m, cmd
+	case viewSnapCreate:
+		var cmd tea.Cmd
+		m.snapCreate, cmd = m.snapCreate.Update(msg)
+		return m, cmd
+	case viewMountAdd:
+		var cmd tea.Cmd
+		m.mountAdd, cmd = m.mountAdd.Update(msg)
+		return m, cmd
+	case viewMountModify:
+		var cmd tea.Cmd
+		m.mountModify, cmd = m.mountModify.Update(msg)
+		return m, cmd
+	case viewBackup:
+		var cmd tea.Cmd
+		m.backup, cmd = m.backup.Update(msg)
+		return m, cmd
+	case viewExport:
+		var cmd tea.Cmd
+		m.export, cmd = m.export.Update(msg)
+		return m, cmd
+	case viewExec:
+		var cmd tea.Cmd
+		m.exec, cmd = m.exec.Update(msg)
+		return m, cmd
+	case viewPager:
+		var cmd tea.Cmd
+		m.pager, cmd = m.pager.Update(msg)
 		return m, cmd
-	case viewLoading:
+	case viewFiles:
 		var cmd tea.Cmd
-		m.loading, cmd = m.loading.Update(msg)
+		m.files, cmd = m.files.Update(msg)
 		return m, cmd
-	case viewInfo:
+	case viewAdopt:
 		var cmd tea.Cmd
-		m.info, cmd = m.info.Update(msg)
+		m.adopt, cmd = m.adopt.Update(msg)
 		return m, cmd
-	case viewAdvCreate:
+	case viewReapply:
 		var cmd tea.Cmd
-		m.advCreate, cmd = m.advCreate.Update(msg)
+		m.reapply, cmd = m.reapply.Update(msg)
 		return m, cmd
-	case viewSnapCreate:
+	case viewExpose:
 		var cmd tea.Cmd
-		m.snapCreate, cmd = m.snapCreate.Update(msg)
+		m.expose, cmd = m.expose.Update(msg)
 		return m, cmd
-	case viewMountAdd:
+	case viewSnapClone:
 		var cmd tea.Cmd
-		m.mountAdd, cmd = m.mountAdd.Update(msg)
+		m.snapClone, cmd = m.snapClone.Update(msg)
 		return m, cmd
-	case viewMountModify:
+	case viewSnapCompare:
 		var cmd tea.Cmd
-		m.mountModify, cmd = m.mountModify.Update(msg)
+		m.snapCompare, cmd = m.snapCompare.Update(msg)
+		return m, cmd
+	case viewUserDataImport:
+		var cmd tea.Cmd
+		m.userData, cmd = m.userData.Update(msg)
+		return m, cmd
+	case viewIPs:
+		var cmd tea.Cmd
+		m.ips, cmd = m.ips.Update(msg)
+		return m, cmd
+	case viewStuck:
+		var cmd tea.Cmd
+		m.stuck, cmd = m.stuck.Update(msg)
+		return m, cmd
+	case viewGolden:
+		var cmd tea.Cmd
+		m.golden, cmd = m.golden.Update(msg)
+		return m, cmd
+	case viewSnapSearch:
+		var cmd tea.Cmd
+		m.snapSearch, cmd = m.snapSearch.Update(msg)
+		return m, cmd
+	case viewWizard:
+		var cmd tea.Cmd
+		m.wizard, cmd = m.wizard.Update(msg)
 		return m, cmd
 	}
 
@@ -441,10 +1434,23 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // ─── Key Handling ──────────────────────────────────────────────────────────────
 
 func (m rootModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.readOnly && m.readOnlyBlocks(msg.String()) {
+		return m, m.table.addToast("Read-only mode: "+msg.String()+" is disabled", "info")
+	}
+	if feature, gated := driverKeyFeatures[msg.String()]; gated && m.currentView == viewTable && !m.table.filterFocused && capsFor(m.table.driver).lacks(feature) {
+		return m, m.table.addToast(unsupportedMessage(m.table.driver, feature), "info")
+	}
+	if msg.String() == "ctrl+c" && m.currentView != viewWizard && m.currentView != viewQuit {
+		return m.requestQuit()
+	}
+
 	switch m.currentView {
 
 	// ── Main table ──
 	case viewTable:
+		if m.table.banner != "" {
+			m.dismissBanner()
+		}
 		if m.table.filterFocused {
 			var cmd tea.Cmd
 			m.table, cmd = m.table.Update(msg)
@@ -452,8 +1458,8 @@ func (m rootModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 		switch msg.String() {
-		case "q", "ctrl+c":
-			return m, tea.Quit
+		case "q":
+			return m.requestQuit()
 		case "esc":
 			if m.table.filterVisible {
 				m.table.filterText = ""
@@ -462,9 +1468,9 @@ func (m rootModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.table.applyFilterAndSort()
 				return m, nil
 			}
-			return m, tea.Quit
+			return m.requestQuit()
 		case "h":
-			m.help = newHelpModel()
+			m.help = newHelpModel(m.actions)
 			m.setChildSizes()
 			m.currentView = viewHelp
 			return m, nil
@@ -476,84 +1482,149 @@ func (m rootModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		case "i":
 			if vm, ok := m.table.selectedVM(); ok {
 				m.info = newInfoModel(vm.Name, m.width, m.height)
+				m.info.vmState, m.info.history = vm.State, m.state.History
 				m.currentView = viewInfo
-				return m, tea.Batch(fetchVMInfoCmd(vm.Name), infoRefreshTickCmd())
+				return m, tea.Batch(m.info.refresh(time.Now()), infoRefreshTickCmd())
 			}
 		case "c":
-			name := VMNamePrefix + randomString(VMNameRandomLength)
+			policy := loadNamingPolicy(configValue)
+			name := policy.generate(func() string { return VMNamePrefix + randomString(VMNameRandomLength) }, m.nameTaken)
+			if err := policy.check(name); err != nil {
+				return m, m.namingError(err)
+			}
+			quotaCmd, ok := m.enforceQuota(resources{MultipassDefaultCPUs, MultipassDefaultMemoryMB, MultipassDefaultDiskGB})
+			if !ok {
+				return m, nil
+			}
 			// Add placeholder row and busy animation
 			placeholder := vmData{info: VMInfo{Name: name, State: "Creating"}}
 			m.table.vms = append(m.table.vms, placeholder)
 			m.table.applyFilterAndSort()
 			// Move cursor to the new row
-			for i, vm := range m.table.filteredVMs {
-				if vm.info.Name == name {
-					m.table.cursor = i
-					visible := m.table.visibleRows()
-					if m.table.cursor >= m.table.offset+visible {
-						m.table.offset = m.table.cursor - visible + 1
-					}
-					break
-				}
-			}
+			m.table.revealVM(name)
 			m.table.busyVMs[name] = busyInfo{operation: "Creating", startTime: time.Now()}
-			return m, quickCreateCmd(name)
+			if m.state.VMs != nil {
+				m.state.setPendingPin(name, DefaultUbuntuRelease)
+			}
+			m.recordLaunch(name, resources{MultipassDefaultCPUs, MultipassDefaultMemoryMB, MultipassDefaultDiskGB}, 0, "")
+			return m, tea.Batch(quickCreateCmd(name), quotaCmd, checkImageDriftCmd(DefaultUbuntuRelease, m.state.Pins))
+		case "L":
+			// Quick launch: configured defaults and a pet name, no form
+			d := loadLaunchDefaults(configValue)
+			policy := loadNamingPolicy(configValue)
+			name := policy.generate(func() string { return petName(m.nameTaken) }, m.nameTaken)
+			if err := policy.check(name); err != nil {
+				return m, m.namingError(err)
+			}
+			return m, func() tea.Msg {
+				return advCreateMsg{name: name, release: d.release, cpus: d.cpus,
+					memoryMB: d.memoryMB, diskGB: d.diskGB, cloudInitFile: d.cloudInit, quick: true}
+			}
+		case "a":
+			return m, m.repeatLastLaunch()
 		case "C":
-			m.advCreate = newAdvCreateModel(m.width, m.height)
+			m.advCreate = newAdvCreateModel(m.width, m.height, m.state.Recent, m.nameTaken)
+			m.advCreate.pins = m.state.Pins
 			m.currentView = viewAdvCreate
 			return m, m.advCreate.Init()
 		case "[":
+			if key, ok := m.table.selectedGroup(); ok {
+				return m, m.groupBulk(key, "stop")
+			}
 			if vm, ok := m.table.selectedVM(); ok {
-				m.table.busyVMs[vm.Name] = busyInfo{operation: "Stopping", startTime: time.Now()}
-				return m, stopVMCmd(vm.Name)
+				if cmd, ok := m.guardOwner(vm.Name, "Stop", stopVMCmd(vm.Name), pendingRowOp{vm.Name, "Stopping"}, viewTable); ok {
+					return m, cmd
+				}
+				return m, m.confirmRowOp(vm.Name, "Stopping", fmt.Sprintf("Stop '%s'?", vm.Name), stopVMCmd(vm.Name))
 			}
 		case "]":
+			if key, ok := m.table.selectedGroup(); ok {
+				return m, m.groupBulk(key, "start")
+			}
 			if vm, ok := m.table.selectedVM(); ok {
-				m.table.busyVMs[vm.Name] = busyInfo{operation: "Starting", startTime: time.Now()}
-				return m, startVMCmd(vm.Name)
+				return m, m.confirmRowOp(vm.Name, "Starting", fmt.Sprintf("Start '%s'?", vm.Name), startVMCmd(vm.Name))
 			}
 		case "p":
 			if vm, ok := m.table.selectedVM(); ok {
-				m.table.busyVMs[vm.Name] = busyInfo{operation: "Suspending", startTime: time.Now()}
-				return m, suspendVMCmd(vm.Name)
+				if cmd, ok := m.guardOwner(vm.Name, "Suspend", suspendVMCmd(vm.Name), pendingRowOp{vm.Name, "Suspending"}, viewTable); ok {
+					return m, cmd
+				}
+				return m, m.confirmRowOp(vm.Name, "Suspending", fmt.Sprintf("Suspend '%s'?", vm.Name), suspendVMCmd(vm.Name))
 			}
 		case "<":
 			names := m.table.allVMNames()
+			if others := m.othersVMs(names); len(others) > 0 {
+				return m, m.overrideOwner("Stop ALL VMs, including other users'?", "override", others, stopAllVMsCmd(names), pendingRowOp{}, viewTable)
+			}
 			if len(names) > 0 {
-				m.confirm = newConfirmModel("Stop ALL VMs?")
-				m.setChildSizes()
-				m.pendingCmd = stopAllVMsCmd(names)
-				m.currentView = viewConfirm
+				// Bulk operations count as destructive
+				return m, m.confirmFirst(true, newConfirmModel("Stop ALL VMs?"), stopAllVMsCmd(names), viewTable)
 			}
 			return m, nil
 		case ">":
 			names := m.table.allVMNames()
 			if len(names) > 0 {
-				m.confirm = newConfirmModel("Start ALL VMs?")
-				m.setChildSizes()
-				m.pendingCmd = startAllVMsCmd(names)
-				m.currentView = viewConfirm
+				return m, m.confirmFirst(true, newConfirmModel("Start ALL VMs?"), startAllVMsCmd(names), viewTable)
 			}
 			return m, nil
 		case "d":
 			if vm, ok := m.table.selectedVM(); ok {
-				m.confirm = newConfirmModel(fmt.Sprintf("Delete VM '%s'? This will purge it.", vm.Name))
-				m.setChildSizes()
-				m.pendingCmd = deleteVMCmd(vm.Name)
-				m.currentView = viewConfirm
+				if cmd, ok := m.guardOwner(vm.Name, "Delete and purge", deleteVMCmd(vm.Name), pendingRowOp{}, viewTable); ok {
+					return m, cmd
+				}
+				if vm.State == "Running" && m.confirmLevel.asks(true) {
+					return m, deletePreviewCmd(vm)
+				}
+				return m, m.confirmDelete(vm, 0)
+			}
+			return m, nil
+		case "x":
+			if key, ok := m.table.selectedGroup(); ok {
+				return m, m.groupBulk(key, "delete")
+			}
+			if vm, ok := m.table.selectedVM(); ok && vm.State != "Deleted" {
+				if cmd, ok := m.guardOwner(vm.Name, "Delete", softDeleteVMCmd(vm.Name), pendingRowOp{vm.Name, "Deleting"}, viewTable); ok {
+					return m, cmd
+				}
+				return m, m.confirmRowOp(vm.Name, "Deleting", fmt.Sprintf("Move '%s' to the trash?", vm.Name), softDeleteVMCmd(vm.Name))
 			}
 			return m, nil
+		case "u":
+			if name, ok := m.table.takeUndo(); ok {
+				m.table.busyVMs[name] = busyInfo{operation: "Recovering", startTime: time.Now()}
+				return m, recoverVMCmd(name)
+			}
+			return m, m.table.addToast("Nothing to undo", "info")
 		case "r":
 			if vm, ok := m.table.selectedVM(); ok {
-				m.table.busyVMs[vm.Name] = busyInfo{operation: "Recovering", startTime: time.Now()}
-				return m, recoverVMCmd(vm.Name)
+				return m, m.confirmRowOp(vm.Name, "Recovering", fmt.Sprintf("Recover '%s'?", vm.Name), recoverVMCmd(vm.Name))
+			}
+		case "N":
+			if vm, ok := m.table.selectedVM(); ok {
+				if vm.State != "Running" {
+					m.errModal = newErrorModel("Network Repair", fmt.Sprintf("VM '%s' must be running to repair its networking.", vm.Name))
+					m.setChildSizes()
+					m.currentView = viewError
+					return m, nil
+				}
+				return m, m.confirmRowOp(vm.Name, "Repairing network", fmt.Sprintf("Repair networking in '%s'? It is restarted if the in-VM fixes fail.", vm.Name), repairNetworkCmd(vm.Name))
 			}
 		case "!":
-			m.confirm = newConfirmModel("PURGE ALL deleted VMs? This cannot be undone.")
-			m.setChildSizes()
-			m.pendingCmd = purgeAllVMsCmd()
-			m.currentView = viewConfirm
-			return m, nil
+			var deleted []string
+			for _, vm := range m.table.vms {
+				if vm.info.State == "Deleted" {
+					deleted = append(deleted, vm.info.Name)
+				}
+			}
+			if len(deleted) == 0 {
+				return m, m.table.addToast("No deleted VMs to purge", "info")
+			}
+			if others := m.othersVMs(deleted); len(others) > 0 {
+				return m, m.overrideOwner("PURGE ALL deleted VMs, including other users'? This cannot be undone.", "override", others, purgeAllVMsCmd(deleted), pendingRowOp{}, viewTable)
+			}
+			c := newTypedConfirmModel("PURGE ALL deleted VMs? This cannot be undone.",
+				"purge", deletedVMContext(m.table.vms))
+			return m, m.confirmFirst(true, c, purgeAllVMsCmd(deleted), viewTable)
 		case "1", "2", "3", "4", "5", "6", "7", "8", "9", "0":
 			idx := int(msg.String()[0] - '1') // '1'→0, '2'→1, ...
 			if msg.String() == "0" {
@@ -572,9 +1643,22 @@ func (m rootModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		case "f":
 			m.table.toggleFilter()
 			return m, nil
+		case "b":
+			if len(m.bulk.items) > 0 {
+				m.setChildSizes()
+				m.currentView = viewBulk
+			}
+			return m, nil
+		case "J":
+			return m, m.openJobs()
+		case "B":
+			m.loading = newLoadingModel("Loading networks…")
+			m.setChildSizes()
+			m.currentView = viewLoading
+			return m, tea.Batch(m.loading.Init(), fetchBridgeSettingsCmd())
 		case "s":
 			if vm, ok := m.table.selectedVM(); ok {
-				c := exec.Command("multipass", "shell", vm.Name) // #nosec G204 -- VM name from table selection
+				c := mpRunner.interactive([]string{"shell", vm.Name})
 				return m, tea.ExecProcess(c, func(err error) tea.Msg {
 					return shellFinishedMsg{err: err}
 				})
@@ -592,6 +1676,184 @@ func (m rootModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.currentView = viewError
 			}
 			return m, nil
+		case "E":
+			m.export = newExportModel(len(m.table.filteredVMs), m.width, m.height)
+			m.currentView = viewExport
+			return m, m.export.Init()
+		case "X":
+			if vm, ok := m.table.selectedVM(); ok {
+				if vm.State != "Running" {
+					m.errModal = newErrorModel("Exec Error", fmt.Sprintf("VM '%s' must be running to run a command.", vm.Name))
+					m.setChildSizes()
+					m.currentView = viewError
+					return m, nil
+				}
+				m.exec = newExecModel(vm, m.snippets, m.width, m.height)
+				m.currentView = viewExec
+				return m, m.exec.Init()
+			}
+			return m, nil
+		case "F":
+			if vm, ok := m.table.selectedVM(); ok {
+				if vm.State != "Running" {
+					m.errModal = newErrorModel("Files Error", fmt.Sprintf("VM '%s' must be running to browse its files.", vm.Name))
+					m.setChildSizes()
+					m.currentView = viewError
+					return m, nil
+				}
+				m.files = newFilesModel(vm, m.readOnly, m.width, m.height)
+				m.currentView = viewFiles
+				return m, m.files.Init()
+			}
+			return m, nil
+		case "e":
+			if vm, ok := m.table.selectedVM(); ok {
+				if vm.State != "Running" {
+					m.errModal = newErrorModel("Backup Error", fmt.Sprintf("VM '%s' must be running to back up or restore files.", vm.Name))
+					m.setChildSizes()
+					m.currentView = viewError
+					return m, nil
+				}
+				dir, err := backupDir()
+				var archives []backupArchive
+				if err == nil {
+					archives, err = listBackups(dir, vm.Name)
+				}
+				if err != nil {
+					m.errModal = newErrorModel("Backup Error", err.Error())
+					m.setChildSizes()
+					m.currentView = viewError
+					return m, nil
+				}
+				m.backup = newBackupModel(vm.Name, dir, archives, m.width, m.height)
+				m.currentView = viewBackup
+				return m, m.backup.Init()
+			}
+			return m, nil
+		case "D":
+			name := dockerVMName(configValue)
+			vm, exists := m.table.vmByName(name)
+			if exists && vm.State == "Deleted" {
+				m.errModal = newErrorModel("Docker Error", fmt.Sprintf("VM '%s' is deleted; recover or purge it first.", name))
+				m.setChildSizes()
+				m.currentView = viewError
+				return m, nil
+			}
+			var quotaCmd tea.Cmd
+			if !exists {
+				if err := loadNamingPolicy(configValue).check(name); err != nil {
+					return m, m.namingError(err)
+				}
+				d := loadLaunchDefaults(configValue)
+				req := resources{d.cpus, d.memoryMB, d.diskGB}
+				var ok bool
+				if quotaCmd, ok = m.enforceQuota(req); !ok {
+					return m, nil
+				}
+				m.table.vms = append(m.table.vms, vmData{info: VMInfo{Name: name, State: "Creating"}})
+				m.table.applyFilterAndSort()
+				m.recordLaunch(name, req, 0, "")
+			}
+			m.table.busyVMs[name] = busyInfo{operation: "Setting up Docker", startTime: time.Now()}
+			return m, tea.Batch(dockerHostCmd(name, !exists, exists && vm.State != "Running"), quotaCmd)
+		case "P":
+			if vm, ok := m.table.selectedVM(); ok {
+				if vm.State != "Running" {
+					m.errModal = newErrorModel("Expose Error", fmt.Sprintf("VM '%s' must be running to expose a port.", vm.Name))
+					m.setChildSizes()
+					m.currentView = viewError
+					return m, nil
+				}
+				ip, err := vmIPv4(vm)
+				if err != nil {
+					m.errModal = newErrorModel("Expose Error", err.Error())
+					m.setChildSizes()
+					m.currentView = viewError
+					return m, nil
+				}
+				m.expose = newExposeModel(vm.Name, ip, m.forwards[vm.Name], m.width, m.height)
+				m.currentView = viewExpose
+				return m, m.expose.Init()
+			}
+			return m, nil
+		case "I":
+			states := make(map[string]string, len(m.table.vms))
+			for _, vm := range m.table.vms {
+				states[vm.info.Name] = vm.info.State
+			}
+			vm, _ := m.table.selectedVM()
+			m.golden = newGoldenModel(m.state.Goldens, vm.Name, vm.State, states, m.width, m.height)
+			m.currentView = viewGolden
+			return m, nil
+		case "T":
+			m.loading = newLoadingModel("Loading template gallery…")
+			m.setChildSizes()
+			m.currentView = viewLoading
+			return m, tea.Batch(m.loading.Init(), fetchGalleryCmd())
+		case "R":
+			if vm, ok := m.table.selectedVM(); ok {
+				if vm.State != "Running" {
+					m.errModal = newErrorModel("Cloud-init Error", fmt.Sprintf("VM '%s' must be running to re-apply cloud-init.", vm.Name))
+					m.setChildSizes()
+					m.currentView = viewError
+					return m, nil
+				}
+				templates, cleanupDirs, _ := GetAllCloudInitTemplateOptions()
+				m.reapply = newReapplyModel(vm.Name, templates, cleanupDirs, m.width, m.height)
+				m.currentView = viewReapply
+				return m, m.reapply.Init()
+			}
+			return m, nil
+		case "z":
+			m.table.cycleGrouping()
+			if m.table.groupBy == groupNone {
+				return m, m.table.addToast("Grouping off", "info")
+			}
+			return m, m.table.addToast("Grouped by "+m.table.groupBy+" (Enter collapses a group, Z all of them)", "info")
+		case "Z":
+			if m.table.groupBy != groupNone {
+				m.table.toggleAllGroups()
+			}
+			return m, nil
+		case "enter", " ":
+			if key, ok := m.table.selectedGroup(); ok {
+				m.table.toggleGroup(key)
+			}
+			return m, nil
+		case "K":
+			if vm, ok := m.table.selectedVM(); ok {
+				since, stuck := m.table.stuck[vm.Name]
+				if !stuck {
+					return m, m.table.addToast(fmt.Sprintf("%s is not stuck (%s)", vm.Name, vm.State), "info")
+				}
+				m.stuck = newStuckModel(vm.Name, vm.State, time.Since(since), forceStopSupported(m.health.client), m.width, m.height)
+				m.currentView = viewStuck
+			}
+			return m, nil
+		case "Y":
+			m.ips = newIPsModel(vmAddresses(m.table.filteredVMs, nil), sshIdentityFile(), m.table.filterText != "", m.width, m.height)
+			m.currentView = viewIPs
+			return m, nil
+		case "U":
+			if vm, ok := m.table.selectedVM(); ok {
+				if vm.State != "Running" {
+					m.errModal = newErrorModel("Cloud-init Error", fmt.Sprintf("VM '%s' must be running to read its cloud-init.", vm.Name))
+					m.setChildSizes()
+					m.currentView = viewError
+					return m, nil
+				}
+				dir, err := userConfigDir()
+				if err != nil {
+					m.errModal = newErrorModelFor("Cloud-init Error", err, "")
+					m.setChildSizes()
+					m.currentView = viewError
+					return m, nil
+				}
+				m.userData = newUserDataImportModel(vm.Name, dir, m.width, m.height)
+				m.currentView = viewUserDataImport
+				return m, m.userData.Init()
+			}
+			return m, nil
 		case "m":
 			if vm, ok := m.table.selectedVM(); ok {
 				m.lastSnapVM = vm.Name
@@ -600,6 +1862,19 @@ func (m rootModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.currentView = viewLoading
 				return m, tea.Batch(m.loading.Init(), fetchSnapshotsCmd(vm.Name))
 			}
+		case "l":
+			if m.errModal.title == "" {
+				return m, m.table.addToast("No errors so far", "info")
+			}
+			m.errModal.expanded, m.errModal.offset, m.errModal.copied = false, 0, ""
+			m.setChildSizes()
+			m.currentView = viewError
+			return m, nil
+		case "S":
+			m.loading = newLoadingModel("Loading snapshots…")
+			m.setChildSizes()
+			m.currentView = viewLoading
+			return m, tea.Batch(m.loading.Init(), fetchAllSnapshotsCmd())
 		case "M":
 			if vm, ok := m.table.selectedVM(); ok {
 				if vm.State != "Running" {
@@ -616,6 +1891,16 @@ func (m rootModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		for _, a := range m.actions {
+			if msg.String() != a.key {
+				continue
+			}
+			if vm, ok := m.table.selectedVM(); ok {
+				return m, runCustomActionCmd(a, vm)
+			}
+			return m, nil
+		}
+
 		// Pass remaining keys to table for navigation
 		var cmd tea.Cmd
 		m.table, cmd = m.table.Update(msg)
@@ -633,6 +1918,8 @@ func (m rootModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "esc", "enter":
 			m.currentView = viewTable
+		default:
+			m.errModal = m.errModal.Update(msg)
 		}
 		return m, nil
 
@@ -676,6 +1963,107 @@ func (m rootModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		var cmd tea.Cmd
 		m.mountModify, cmd = m.mountModify.Update(msg)
 		return m, cmd
+
+	case viewBulk:
+		var cmd tea.Cmd
+		m.bulk, cmd = m.bulk.Update(msg)
+		return m, cmd
+
+	case viewJobs:
+		var cmd tea.Cmd
+		m.jobs, cmd = m.jobs.Update(msg)
+		return m, cmd
+
+	case viewBridge:
+		var cmd tea.Cmd
+		m.bridge, cmd = m.bridge.Update(msg)
+		return m, cmd
+
+	case viewBackup:
+		var cmd tea.Cmd
+		m.backup, cmd = m.backup.Update(msg)
+		return m, cmd
+
+	case viewExport:
+		var cmd tea.Cmd
+		m.export, cmd = m.export.Update(msg)
+		return m, cmd
+
+	case viewExec:
+		var cmd tea.Cmd
+		m.exec, cmd = m.exec.Update(msg)
+		return m, cmd
+
+	case viewPager:
+		var cmd tea.Cmd
+		m.pager, cmd = m.pager.Update(msg)
+		return m, cmd
+
+	case viewFiles:
+		var cmd tea.Cmd
+		m.files, cmd = m.files.Update(msg)
+		return m, cmd
+
+	case viewAdopt:
+		var cmd tea.Cmd
+		m.adopt, cmd = m.adopt.Update(msg)
+		return m, cmd
+	case viewReapply:
+		var cmd tea.Cmd
+		m.reapply, cmd = m.reapply.Update(msg)
+		return m, cmd
+	case viewExpose:
+		var cmd tea.Cmd
+		m.expose, cmd = m.expose.Update(msg)
+		return m, cmd
+	case viewSnapClone:
+		var cmd tea.Cmd
+		m.snapClone, cmd = m.snapClone.Update(msg)
+		return m, cmd
+	case viewSnapCompare:
+		var cmd tea.Cmd
+		m.snapCompare, cmd = m.snapCompare.Update(msg)
+		return m, cmd
+	case viewUserDataImport:
+		var cmd tea.Cmd
+		m.userData, cmd = m.userData.Update(msg)
+		return m, cmd
+	case viewIPs:
+		var cmd tea.Cmd
+		m.ips, cmd = m.ips.Update(msg)
+		return m, cmd
+	case viewStuck:
+		var cmd tea.Cmd
+		m.stuck, cmd = m.stuck.Update(msg)
+		return m, cmd
+
+	case viewGallery:
+		var cmd tea.Cmd
+		m.gallery, cmd = m.gallery.Update(msg)
+		return m, cmd
+
+	case viewGolden:
+		var cmd tea.Cmd
+		m.golden, cmd = m.golden.Update(msg)
+		return m, cmd
+
+	case viewQuit:
+		var cmd tea.Cmd
+		m.quit, cmd = m.quit.Update(msg)
+		return m, cmd
+
+	case viewSnapSearch:
+		var cmd tea.Cmd
+		m.snapSearch, cmd = m.snapSearch.Update(msg)
+		return m, cmd
+
+	case viewWizard:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		var cmd tea.Cmd
+		m.wizard, cmd = m.wizard.Update(msg)
+		return m, cmd
 	}
 
 	return m, nil
@@ -711,6 +2099,50 @@ func (m rootModel) View() string {
 		return m.mountAdd.View()
 	case viewMountModify:
 		return m.mountModify.View()
+	case viewBulk:
+		return m.bulk.View()
+	case viewJobs:
+		return m.jobs.View()
+	case viewBridge:
+		return m.bridge.View()
+	case viewBackup:
+		return m.backup.View()
+	case viewExport:
+		return m.export.View()
+	case viewPager:
+		return m.pager.View()
+	case viewExec:
+		return m.exec.View()
+	case viewFiles:
+		return m.files.View()
+	case viewAdopt:
+		return m.adopt.View()
+	case viewReapply:
+		return m.reapply.View()
+	case viewExpose:
+		return m.expose.View()
+	case viewSnapClone:
+		return m.snapClone.View()
+	case viewSnapCompare:
+		return m.snapCompare.View()
+	case viewUserDataImport:
+		return m.userData.View()
+	case viewIPs:
+		return m.ips.View()
+	case viewStuck:
+		return m.stuck.View()
+	case viewGallery:
+		return m.gallery.View()
+	case viewGolden:
+		return m.golden.View()
+	case viewQuit:
+		quit := m.quit
+		quit.ops = m.inFlight()
+		return quit.View()
+	case viewSnapSearch:
+		return m.snapSearch.View()
+	case viewWizard:
+		return m.wizard.View()
 	default:
 		return "Unknown view"
 	}
@@ -728,12 +2160,18 @@ func operationToastMessage(vmName, operation string, elapsed time.Duration) stri
 	switch operation {
 	case "stop":
 		return fmt.Sprintf("✓ %s stopped%s", vmName, timeStr)
+	case "force-stop":
+		return fmt.Sprintf("✓ %s force-stopped%s", vmName, timeStr)
 	case "start":
 		return fmt.Sprintf("✓ %s started%s", vmName, timeStr)
 	case "suspend":
 		return fmt.Sprintf("✓ %s suspended%s", vmName, timeStr)
 	case "recover":
 		return fmt.Sprintf("✓ %s recovered%s", vmName, timeStr)
+	case "repair-network":
+		return fmt.Sprintf("✓ Networking repaired on %s%s", vmName, timeStr)
+	case "restart":
+		return fmt.Sprintf("✓ %s restarted to repair networking%s", vmName, timeStr)
 	case "delete":
 		return fmt.Sprintf("✓ %s deleted%s", vmName, timeStr)
 	case "create":
@@ -748,17 +2186,67 @@ func operationToastMessage(vmName, operation string, elapsed time.Duration) stri
 		return fmt.Sprintf("✓ Mount added to %s%s", vmName, timeStr)
 	case "umount":
 		return fmt.Sprintf("✓ Mount removed from %s%s", vmName, timeStr)
+	case "cloud-init":
+		return fmt.Sprintf("✓ cloud-init re-applied on %s%s", vmName, timeStr)
 	case "stop-all":
 		return fmt.Sprintf("✓ All VMs stopped%s", timeStr)
 	case "start-all":
 		return fmt.Sprintf("✓ All VMs started%s", timeStr)
-	case "purge":
+	case "purge-all":
 		return fmt.Sprintf("✓ All deleted VMs purged%s", timeStr)
+	case "delete-many":
+		return fmt.Sprintf("✓ VMs moved to the trash (r recovers them)%s", timeStr)
 	default:
 		return fmt.Sprintf("✓ %s %s%s", vmName, operation, timeStr)
 	}
 }
 
+// ─── Confirm Helpers ───────────────────────────────────────────────────────────
+
+// destructiveContext summarises what a destructive operation on vm will
+// affect. uptime is left out when 0 (unknown, or the VM is not running).
+func destructiveContext(vm VMInfo, uptime time.Duration) []string {
+	if vm.Name == "" {
+		return nil
+	}
+	lines := []string{
+		"State: " + orDashes(vm.State),
+		"Snapshots: " + orDashes(vm.Snapshots),
+	}
+	if vm.State == "Running" && uptime > 0 {
+		lines = append(lines, "Uptime: "+formatRemaining(uptime))
+	}
+	if vm.Release != "" && vm.Release != "--" {
+		lines = append(lines, "Release: "+vm.Release)
+	}
+	if vm.DiskUsage != "" && vm.DiskUsage != "--" {
+		lines = append(lines, "Disk: "+vm.DiskUsage)
+	}
+	return lines
+}
+
+// confirmDelete asks to type vm's name before purging it.
+func (m *rootModel) confirmDelete(vm VMInfo, uptime time.Duration) tea.Cmd {
+	c := newTypedConfirmModel(
+		fmt.Sprintf("Delete VM '%s'? This will purge it.", vm.Name),
+		vm.Name, destructiveContext(vm, uptime))
+	return m.confirmFirst(true, c, deleteVMCmd(vm.Name), viewTable)
+}
+
+// deletedVMContext lists the VMs a purge will permanently remove.
+func deletedVMContext(vms []vmData) []string {
+	var names []string
+	for _, vm := range vms {
+		if vm.info.State == "Deleted" {
+			names = append(names, vm.info.Name)
+		}
+	}
+	if len(names) == 0 {
+		return []string{"No deleted VMs in the current list"}
+	}
+	return []string{fmt.Sprintf("Will purge %d VM(s): %s", len(names), strings.Join(names, ", "))}
+}
+
 // ─── Sort (moved from old main.go) ────────────────────────────────────────────
 
 func sortVMs(vms []vmData, column int, ascending bool) {
@@ -868,14 +2356,14 @@ func compareFloat64(a, b float64) int {
 	}
 }
 
-func runMountModifyOperation(runCmd func(args ...string) (string, error), vmName, oldTarget, newSource, newTarget string) error {
+func runMountModifyOperation(runCmd func(args ...string) (string, error), vmName, oldTarget, newSource, newTarget string, opts mountOptions) error {
 	oldMount := vmName + ":" + oldTarget
 	if _, err := runCmd("umount", oldMount); err != nil {
 		return fmt.Errorf("failed to unmount %s: %w", oldMount, err)
 	}
 
 	newMount := vmName + ":" + newTarget
-	if _, err := runCmd("mount", newSource, newMount); err != nil {
+	if _, err := runCmd(mountArgs(newSource, vmName, newTarget, opts)...); err != nil {
 		return fmt.Errorf("failed to mount %s to %s: %w", newSource, newMount, err)
 	}
 
@@ -905,14 +2393,78 @@ func initLogger() error {
 // ─── Entry Point ───────────────────────────────────────────────────────────────
 
 func main() {
+	if len(os.Args) > 1 {
+		subcommands := map[string]func([]string, io.Reader, io.Writer, io.Writer) int{
+			"run":             runCommand,
+			"launch":          launchCommand,
+			"snapshot-daemon": snapshotDaemonCommand,
+			"doctor":          doctorCommand,
+			"watch":           watchCommand,
+			"k8s-lab":         k8sLabCommand,
+			"repl":            replCommand,
+			"meta":            metaCommand,
+			"agent":           agentCommand,
+			"report":          reportCommand,
+			"snapshot-diff":   snapshotDiffCommand,
+			"ips":             ipsCommand,
+		}
+		if sub, ok := subcommands[os.Args[1]]; ok {
+			if err := initLogger(); err != nil {
+				log.Printf("logger init failed: %v", err)
+			}
+			applyProxyConfig(configValue)
+			multipassTimeouts = loadCommandTimeouts(configValue)
+			useMultipassRunner(configValue)
+			code := sub(os.Args[2:], os.Stdin, os.Stdout, os.Stderr)
+			tempArtifacts.removeAll()
+			os.Exit(code)
+		}
+	}
+
+	accessible := flag.Bool("accessible", false, "screen-reader-friendly output (no box drawing, no animations)")
+	setup := flag.Bool("setup", false, "run the first-run setup wizard even if a .config exists")
+	readOnly := flag.Bool("read-only", false, "observer mode: disable every action that changes VMs or settings")
+	flag.Parse()
+	if *accessible || configBool("accessible") {
+		setAccessibleMode(true)
+	}
+
 	if err := initLogger(); err != nil {
 		log.Printf("logger init failed: %v", err)
 	} else {
 		appLogger.Println("passgo starting up")
 	}
+	if applied := applyProxyConfig(configValue); len(applied) > 0 && appLogger != nil {
+		appLogger.Printf("proxy settings from .config: %s", strings.Join(applied, ", "))
+	}
+	multipassTimeouts = loadCommandTimeouts(configValue)
+	useMultipassRunner(configValue)
+	for _, err := range applyColorConfig(configValue, configList("theme-color")) {
+		if appLogger != nil {
+			appLogger.Printf("ignoring color setting: %v", err)
+		}
+	}
+	go sweepOldTempArtifacts()
 
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen(), tea.WithMouseCellMotion())
-	if _, err := p.Run(); err != nil {
+	model := initialModel()
+	if *setup || !configFileExists(appSearchDirs()) {
+		model.startWizard(*setup)
+	}
+	if *readOnly || configBool("read-only") {
+		model.setReadOnly()
+	}
+	model.attachAgent()
+	rec := &crashRecorder{}
+	p := tea.NewProgram(crashGuard{model: model, rec: rec}, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithoutSignalHandler())
+	rec.send = p.Send
+	stopSignals := forwardSignals(p)
+	_, err := p.Run()
+	stopSignals()
+	tempArtifacts.removeAll()
+	if value, _ := rec.crashed(); value != nil || errors.Is(err, tea.ErrProgramPanic) {
+		os.Exit(reportCrash(rec))
+	}
+	if err != nil && !errors.Is(err, tea.ErrProgramKilled) {
 		log.Fatalf("Error running program: %v", err)
 	}
 }
@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func TestSortVMsNumericColumns(t *testing.T) {
@@ -115,6 +117,43 @@ func TestRootModelDropsPendingBackgroundRefreshOffTable(t *testing.T) {
 	}
 }
 
+func TestRootModelKeepsStaleDataOnFailedRefresh(t *testing.T) {
+	m := rootModel{currentView: viewTable, table: newTableModel()}
+	m.table.setVMs([]vmData{{info: VMInfo{Name: "vm-1", State: "Running"}}})
+	m.table.lastRefresh = time.Now().Add(-time.Minute)
+
+	failure := errors.New("command failed: exit status 1\nStderr: cannot connect to the multipass socket\n")
+	model, _ := m.Update(vmListResultMsg{err: failure, background: true})
+	m1 := model.(rootModel)
+	if m1.currentView != viewTable || len(m1.table.vms) != 1 {
+		t.Fatalf("expected the table to keep its rows, view=%v rows=%d", m1.currentView, len(m1.table.vms))
+	}
+	if m1.table.refreshErr != "cannot connect to the multipass socket" {
+		t.Errorf("refreshErr = %q", m1.table.refreshErr)
+	}
+	if age, stale := m1.table.staleAge(); !stale || age != "1m0s" {
+		t.Errorf("staleAge = %q, %v; want 1m0s, true", age, stale)
+	}
+
+	model, _ = m1.Update(vmListResultMsg{err: failure})
+	if m2 := model.(rootModel); m2.currentView != viewTable {
+		t.Errorf("a manual refresh with data on screen should toast, not open an error modal")
+	}
+
+	model, _ = m1.Update(vmListResultMsg{vms: []vmData{{info: VMInfo{Name: "vm-1"}}}, background: true})
+	if m3 := model.(rootModel); m3.table.refreshErr != "" {
+		t.Errorf("a successful refresh should clear the stale mark")
+	}
+}
+
+func TestRootModelShowsErrorWhenFirstLoadFails(t *testing.T) {
+	m := rootModel{currentView: viewLoading, table: newTableModel()}
+	model, _ := m.Update(vmListResultMsg{err: errors.New("multipass not found")})
+	if m1 := model.(rootModel); m1.currentView != viewError {
+		t.Errorf("expected the error view when there is no data to fall back on, got %v", m1.currentView)
+	}
+}
+
 func TestRunMountModifyOperation(t *testing.T) {
 	t.Run("unmount failure short-circuits remount", func(t *testing.T) {
 		var calls [][]string
@@ -126,7 +165,7 @@ func TestRunMountModifyOperation(t *testing.T) {
 			return "", nil
 		}
 
-		err := runMountModifyOperation(runCmd, "vm1", "/old", "/new-src", "/new")
+		err := runMountModifyOperation(runCmd, "vm1", "/old", "/new-src", "/new", mountOptions{})
 		if err == nil || !strings.Contains(err.Error(), "failed to unmount") {
 			t.Fatalf("expected unmount failure, got: %v", err)
 		}
@@ -145,7 +184,7 @@ func TestRunMountModifyOperation(t *testing.T) {
 			return "", nil
 		}
 
-		err := runMountModifyOperation(runCmd, "vm1", "/old", "/new-src", "/new")
+		err := runMountModifyOperation(runCmd, "vm1", "/old", "/new-src", "/new", mountOptions{})
 		if err == nil || !strings.Contains(err.Error(), "failed to mount") {
 			t.Fatalf("expected mount failure, got: %v", err)
 		}
@@ -154,3 +193,32 @@ func TestRunMountModifyOperation(t *testing.T) {
 		}
 	})
 }
+
+func TestReadOnlyModeBlocksMutatingKeys(t *testing.T) {
+	m := rootModel{currentView: viewTable, table: newTableModel()}
+	m.table.vms = []vmData{{info: VMInfo{Name: "vm-1", State: "Running"}}}
+	m.table.applyFilterAndSort()
+	m.setReadOnly()
+
+	for _, key := range []string{"d", "[", "!", "s", "B"} {
+		model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		if got := model.(rootModel); got.currentView != viewTable || len(got.table.busyVMs) != 0 {
+			t.Fatalf("key %q should be ignored in read-only mode (view %v, busy %v)", key, got.currentView, got.table.busyVMs)
+		}
+	}
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	if model.(rootModel).currentView != viewHelp {
+		t.Fatalf("help should still open in read-only mode")
+	}
+
+	m.table.filterFocused = true
+	if m.readOnlyBlocks("d") {
+		t.Fatalf("typing into the filter must not be blocked")
+	}
+	m.table.filterFocused = false
+	m.currentView = viewMountManage
+	if !m.readOnlyBlocks("a") || m.readOnlyBlocks("esc") {
+		t.Fatalf("unexpected mount view blocking")
+	}
+}
@@ -4,6 +4,7 @@ package main
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -23,7 +24,8 @@ type vmOperationResultMsg struct {
 	vmName    string
 	operation string
 	err       error
-	inline    bool // true when the operation was inline (stay on table)
+	inline    bool   // true when the operation was inline (stay on table)
+	trace     string // operation ID in the log (see trace.go)
 }
 
 // vmInfoResultMsg carries raw info output for a single VM.
@@ -33,6 +35,37 @@ type vmInfoResultMsg struct {
 	err    error
 }
 
+// bridgeSettingsResultMsg carries bridge candidates and the current default.
+type bridgeSettingsResultMsg struct {
+	networks []NetworkInfo
+	current  string
+	driver   string
+	err      error
+}
+
+// bridgeSetResultMsg reports the outcome of changing local.bridged-network.
+type bridgeSetResultMsg struct {
+	name string
+	err  error
+}
+
+// backupResultMsg reports a finished backup (archive is the host file) or
+// restore (archive is the file that was restored).
+type backupResultMsg struct {
+	vmName  string
+	archive string
+	restore bool
+	err     error
+}
+
+// vmNetworkResultMsg carries guest interfaces for the info view.
+type vmNetworkResultMsg struct {
+	vmName     string
+	interfaces []VMInterface
+	hostBridge string // host interface the VM is bridged to, "" if NAT only
+	err        error
+}
+
 // snapshotListResultMsg carries parsed snapshots for a VM.
 type snapshotListResultMsg struct {
 	vmName    string
@@ -40,6 +73,25 @@ type snapshotListResultMsg struct {
 	err       error
 }
 
+// allSnapshotsResultMsg carries every instance's snapshots for search.
+type allSnapshotsResultMsg struct {
+	snapshots []SnapshotInfo
+	err       error
+}
+
+// restorePreviewMsg carries what a snapshot restore would discard.
+type restorePreviewMsg struct {
+	vmName   string
+	snapName string
+	lines    []string
+}
+
+// deletePreviewMsg carries the uptime shown when confirming a purge.
+type deletePreviewMsg struct {
+	vm     VMInfo
+	uptime time.Duration
+}
+
 // mountListResultMsg carries parsed mounts for a VM.
 type mountListResultMsg struct {
 	vmName string
@@ -47,6 +99,31 @@ type mountListResultMsg struct {
 	err    error
 }
 
+// launchProgressMsg reports a launch phase parsed from multipass output.
+// The root model re-arms waitForEventCmd on events to receive the next one.
+type launchProgressMsg struct {
+	vmName string
+	phase  launchPhase
+	events <-chan tea.Msg
+}
+
+// bulkStartMsg asks the root model to open the bulk progress view and run
+// verb against every VM in names.
+type bulkStartMsg struct {
+	operation string // result operation name, e.g. "stop-all"
+	verb      string // per-item verb for errors, e.g. "stop"
+	names     []string
+	run       func(string) (string, error)
+}
+
+// bulkProgressMsg reports a status change for one item of a bulk operation.
+type bulkProgressMsg struct {
+	index  int
+	status bulkItemStatus
+	err    error
+	events <-chan tea.Msg
+}
+
 // shellFinishedMsg is sent when an interactive shell exits.
 type shellFinishedMsg struct{ err error }
 
@@ -75,8 +152,26 @@ func autoRefreshTickCmd() tea.Cmd {
 
 // ─── Command Factories ─────────────────────────────────────────────────────────
 
-// doFetchVMList is the shared logic for fetching VMs.
+// doFetchVMList is the shared logic for fetching VMs. It uses a single
+// aggregated `multipass info --all` call and only falls back to one info call
+// per VM if the JSON fetch fails (e.g. older multipass versions).
 func doFetchVMList() ([]vmData, error) {
+	if output, err := GetAllVMInfoJSON(); err == nil {
+		if infos, err := parseVMInfoListJSON(output); err == nil {
+			vms := make([]vmData, 0, len(infos))
+			for _, info := range infos {
+				vms = append(vms, vmData{info: info})
+			}
+			return vms, nil
+		} else if appLogger != nil {
+			appLogger.Printf("aggregated info parse failed, falling back: %v", err)
+		}
+	}
+	return doFetchVMListPerVM()
+}
+
+// doFetchVMListPerVM lists VMs and then fetches info for each one individually.
+func doFetchVMListPerVM() ([]vmData, error) {
 	listOutput, err := ListVMs()
 	if err != nil {
 		return nil, err
@@ -96,10 +191,20 @@ func doFetchVMList() ([]vmData, error) {
 	return vms, nil
 }
 
+// staleReason shortens a failed list refresh to one line for the status
+// bar: multipass's own message when it printed one, else the error.
+func staleReason(err error) string {
+	s := err.Error()
+	if _, stderr, ok := strings.Cut(s, "Stderr: "); ok && strings.TrimSpace(stderr) != "" {
+		s = stderr
+	}
+	return firstLine(strings.TrimSpace(s))
+}
+
 // fetchVMListCmd fetches the full VM list with details.
 func fetchVMListCmd() tea.Cmd {
 	return func() tea.Msg {
-		vms, err := doFetchVMList()
+		vms, err := fetchVMList(true)
 		return vmListResultMsg{vms: vms, err: err}
 	}
 }
@@ -107,7 +212,7 @@ func fetchVMListCmd() tea.Cmd {
 // fetchVMListBackgroundCmd fetches VMs silently (for auto-refresh, stays on table).
 func fetchVMListBackgroundCmd() tea.Cmd {
 	return func() tea.Msg {
-		vms, err := doFetchVMList()
+		vms, err := fetchVMList(false)
 		return vmListResultMsg{vms: vms, err: err, background: true}
 	}
 }
@@ -120,99 +225,268 @@ func fetchVMInfoCmd(vmName string) tea.Cmd {
 	}
 }
 
+// fetchVMNetworkCmd fetches guest interfaces and the host bridge for a VM.
+func fetchVMNetworkCmd(vmName string) tea.Cmd {
+	return func() tea.Msg {
+		ifaces, err := GetVMInterfaces(vmName)
+		msg := vmNetworkResultMsg{vmName: vmName, interfaces: ifaces, err: err}
+		if bridged, err := GetSetting("local." + vmName + ".bridged"); err == nil && parseConfigBool(bridged) {
+			msg.hostBridge, _ = GetSetting("local.bridged-network")
+			if msg.hostBridge == "" {
+				msg.hostBridge = "bridged (default not set)"
+			}
+		}
+		return msg
+	}
+}
+
+// fetchBridgeSettingsCmd lists bridgeable interfaces and the current default.
+func fetchBridgeSettingsCmd() tea.Cmd {
+	return func() tea.Msg {
+		networks, err := ListNetworks()
+		if err != nil {
+			return bridgeSettingsResultMsg{err: err}
+		}
+		// An unset key is not an error worth surfacing here.
+		current, _ := GetBridgedNetwork()
+		driver, _ := GetDriver()
+		return bridgeSettingsResultMsg{networks: networks, current: current, driver: driver}
+	}
+}
+
+// setBridgedNetworkCmd sets local.bridged-network to name.
+func setBridgedNetworkCmd(name string) tea.Cmd {
+	return func() tea.Msg {
+		_, err := SetSetting("local.bridged-network", name)
+		return bridgeSetResultMsg{name: name, err: err}
+	}
+}
+
+// backupVMCmd archives paths in a VM to dir (inline — stays on table).
+func backupVMCmd(name string, paths []string, dir string) tea.Cmd {
+	return func() tea.Msg {
+		archive, err := BackupVM(name, paths, dir, time.Now())
+		return backupResultMsg{vmName: name, archive: archive, err: err}
+	}
+}
+
+// restoreBackupCmd extracts a backup archive into a VM (inline).
+func restoreBackupCmd(name, archive string) tea.Cmd {
+	return func() tea.Msg {
+		err := RestoreBackup(name, archive)
+		return backupResultMsg{vmName: name, archive: archive, restore: true, err: err}
+	}
+}
+
 // stopVMCmd stops a VM (inline — stays on table).
 func stopVMCmd(name string) tea.Cmd {
 	return func() tea.Msg {
-		_, err := StopVM(name)
-		return vmOperationResultMsg{vmName: name, operation: "stop", err: err, inline: true}
+		trace, err := traceVMOp("stop", name, func() (string, error) { return StopVM(name) })
+		return vmOperationResultMsg{vmName: name, operation: "stop", err: err, inline: true, trace: trace}
+	}
+}
+
+// forceStopVMCmd powers a VM off (inline — stays on table).
+func forceStopVMCmd(name string) tea.Cmd {
+	return func() tea.Msg {
+		trace, err := traceVMOp("force-stop", name, func() (string, error) { return ForceStopVM(name) })
+		return vmOperationResultMsg{vmName: name, operation: "force-stop", err: err, inline: true, trace: trace}
 	}
 }
 
 // startVMCmd starts a VM (inline — stays on table).
 func startVMCmd(name string) tea.Cmd {
 	return func() tea.Msg {
-		_, err := StartVM(name)
-		return vmOperationResultMsg{vmName: name, operation: "start", err: err, inline: true}
+		trace, err := traceVMOp("start", name, func() (string, error) { return StartVM(name) })
+		return vmOperationResultMsg{vmName: name, operation: "start", err: err, inline: true, trace: trace}
 	}
 }
 
 // suspendVMCmd suspends a VM (inline — stays on table).
 func suspendVMCmd(name string) tea.Cmd {
 	return func() tea.Msg {
-		_, err := runMultipassCommand("suspend", name)
-		return vmOperationResultMsg{vmName: name, operation: "suspend", err: err, inline: true}
+		trace, err := traceVMOp("suspend", name, func() (string, error) { return runMultipassCommand("suspend", name) })
+		return vmOperationResultMsg{vmName: name, operation: "suspend", err: err, inline: true, trace: trace}
 	}
 }
 
 // deleteVMCmd deletes a VM (with purge).
 func deleteVMCmd(name string) tea.Cmd {
 	return func() tea.Msg {
-		_, err := DeleteVM(name, true)
-		return vmOperationResultMsg{vmName: name, operation: "delete", err: err}
+		trace, err := traceVMOp("delete", name, func() (string, error) { return DeleteVM(name, true) })
+		return vmOperationResultMsg{vmName: name, operation: "delete", err: err, trace: trace}
+	}
+}
+
+// softDeleteVMCmd deletes a VM without purging, so it can still be recovered
+// (inline — stays on table).
+func softDeleteVMCmd(name string) tea.Cmd {
+	return func() tea.Msg {
+		trace, err := traceVMOp("soft-delete", name, func() (string, error) { return DeleteVM(name, false) })
+		return vmOperationResultMsg{vmName: name, operation: "soft-delete", err: err, inline: true, trace: trace}
 	}
 }
 
 // recoverVMCmd recovers a deleted VM (inline — stays on table).
 func recoverVMCmd(name string) tea.Cmd {
 	return func() tea.Msg {
-		_, err := RecoverVM(name)
-		return vmOperationResultMsg{vmName: name, operation: "recover", err: err, inline: true}
+		trace, err := traceVMOp("recover", name, func() (string, error) { return RecoverVM(name) })
+		return vmOperationResultMsg{vmName: name, operation: "recover", err: err, inline: true, trace: trace}
 	}
 }
 
 // quickCreateCmd creates a VM with default settings.
 func quickCreateCmd(name string) tea.Cmd {
-	return func() tea.Msg {
-		_, err := LaunchVM(name, DefaultUbuntuRelease)
-		return vmOperationResultMsg{vmName: name, operation: "create", err: err, inline: true}
-	}
+	return launchVMCmd(name, []string{"launch", "--name", name, DefaultUbuntuRelease}, nil)
 }
 
 // advancedCreateCmd creates a VM with custom settings.
-func advancedCreateCmd(name, release string, cpus, memoryMB, diskGB int, cloudInitFile, networkName string) tea.Cmd {
+// Secrets referenced by the cloud-init template are rendered into a private
+// temp file that is removed as soon as the launch returns.
+//
+// projectDir, when set, is mounted at projectTarget as part of the launch.
+func advancedCreateCmd(name, release string, cpus, memoryMB, diskGB int, cloudInitFile string, networks []NetworkSpec, projectDir, projectTarget string) tea.Cmd {
 	return func() tea.Msg {
-		var err error
-		if cloudInitFile == "" {
-			_, err = LaunchVMAdvanced(name, release, cpus, memoryMB, diskGB, networkName)
-		} else {
-			_, err = LaunchVMWithCloudInit(name, release, cpus, memoryMB, diskGB, cloudInitFile, networkName)
+		rendered, cleanup, err := prepareCloudInit(cloudInitFile)
+		if err != nil {
+			return vmOperationResultMsg{vmName: name, operation: "create", err: err, inline: true}
 		}
-		return vmOperationResultMsg{vmName: name, operation: "create", err: err, inline: true}
+		args := withProjectMount(launchVMArgs(name, release, cpus, memoryMB, diskGB, rendered, networks), projectDir, projectTarget)
+		return launchVMCmd(name, args, cleanup)()
 	}
 }
 
-// stopAllVMsCmd stops all running VMs.
+// launchVMCmd runs `multipass launch` with the given arguments, streaming a
+// launchProgressMsg for each phase change and finishing with the usual
+// vmOperationResultMsg (inline — stays on table). after, if set, runs once
+// multipass returns.
+func launchVMCmd(name string, args []string, after func()) tea.Cmd {
+	return func() tea.Msg {
+		events := make(chan tea.Msg, 16)
+		go func() {
+			defer close(events)
+			if after != nil {
+				defer after()
+			}
+			last := launchPhase{Step: -1, Percent: -1}
+			trace, err := traceOp("create", []string{name}, func() error {
+				return launchWithHooks(name, args, func(line string) {
+					phase, ok := parseLaunchPhase(line)
+					if !ok || phase == last {
+						return
+					}
+					last = phase
+					events <- launchProgressMsg{vmName: name, phase: phase, events: events}
+				})
+			})
+			events <- vmOperationResultMsg{vmName: name, operation: "create", err: err, inline: true, trace: trace}
+		}()
+		return <-events
+	}
+}
+
+// waitForEventCmd waits for the next event from a streaming operation (launch, bulk).
+func waitForEventCmd(events <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-events
+	}
+}
+
+// stopAllVMsCmd stops all running VMs (via the bulk progress view).
 func stopAllVMsCmd(names []string) tea.Cmd {
 	return func() tea.Msg {
-		err := runBulkVMOperation("stop", names, StopVM)
-		return vmOperationResultMsg{operation: "stop-all", err: err}
+		return bulkStartMsg{operation: "stop-all", verb: "stop", names: names, run: StopVM}
 	}
 }
 
-// startAllVMsCmd starts all stopped VMs.
+// startAllVMsCmd starts all stopped VMs (via the bulk progress view).
 func startAllVMsCmd(names []string) tea.Cmd {
 	return func() tea.Msg {
-		err := runBulkVMOperation("start", names, StartVM)
-		return vmOperationResultMsg{operation: "start-all", err: err}
+		return bulkStartMsg{operation: "start-all", verb: "start", names: names, run: StartVM}
 	}
 }
 
-// purgeAllVMsCmd purges all deleted VMs.
-func purgeAllVMsCmd() tea.Cmd {
+// bulkVMCmd runs a bulk operation item by item, streaming a bulkProgressMsg
+// for each status change and finishing with an inline vmOperationResultMsg.
+func bulkVMCmd(req bulkStartMsg) tea.Cmd {
 	return func() tea.Msg {
-		_, err := runMultipassCommand("purge")
-		return vmOperationResultMsg{operation: "purge", err: err}
+		events := make(chan tea.Msg, 2*len(req.names)+1)
+		go func() {
+			defer close(events)
+			trace, err := traceOp(req.operation, req.names, func() error {
+				return runBulkVMOperationWithProgress(req.verb, req.names, req.run, func(index int, status bulkItemStatus, err error) {
+					events <- bulkProgressMsg{index: index, status: status, err: err, events: events}
+				})
+			})
+			events <- vmOperationResultMsg{operation: req.operation, err: err, inline: true, trace: trace}
+		}()
+		return <-events
+	}
+}
+
+// deleteVMsCmd moves the VMs in names to the trash (via the bulk progress
+// view).
+func deleteVMsCmd(names []string) tea.Cmd {
+	return func() tea.Msg {
+		return bulkStartMsg{operation: "delete-many", verb: "delete", names: names,
+			run: func(name string) (string, error) { return DeleteVM(name, false) }}
+	}
+}
+
+// purgeAllVMsCmd purges the deleted VMs in names one by one (via the bulk
+// progress view), so each failure is listed.
+func purgeAllVMsCmd(names []string) tea.Cmd {
+	return func() tea.Msg {
+		return bulkStartMsg{operation: "purge-all", verb: "purge", names: names,
+			run: func(name string) (string, error) { return DeleteVM(name, true) }}
 	}
 }
 
 // fetchSnapshotsCmd fetches snapshots for a VM.
+// fetchAllSnapshotsCmd loads snapshots across all instances.
+func fetchAllSnapshotsCmd() tea.Cmd {
+	return func() tea.Msg {
+		snaps, err := fetchAllSnapshots()
+		return allSnapshotsResultMsg{snapshots: snaps, err: err}
+	}
+}
+
+// vmUptime reads the uptime of a running VM, or 0 when it is stopped or
+// cannot be read.
+func vmUptime(vm VMInfo) time.Duration {
+	if vm.State != "Running" {
+		return 0
+	}
+	out, err := ExecInVM(vm.Name, "cat", "/proc/uptime")
+	if err != nil {
+		return 0
+	}
+	uptime, _ := parseProcUptime(out)
+	return uptime
+}
+
+// restorePreviewCmd builds the restore preview, reading the uptime of a
+// running VM.
+func restorePreviewCmd(vm VMInfo, snapName string, snaps []SnapshotInfo) tea.Cmd {
+	return func() tea.Msg {
+		return restorePreviewMsg{vmName: vm.Name, snapName: snapName,
+			lines: restorePreview(vm, snapName, snaps, vmUptime(vm), time.Now())}
+	}
+}
+
+// deletePreviewCmd reads the uptime of vm for the purge confirmation.
+func deletePreviewCmd(vm VMInfo) tea.Cmd {
+	return func() tea.Msg {
+		return deletePreviewMsg{vm: vm, uptime: vmUptime(vm)}
+	}
+}
+
 func fetchSnapshotsCmd(vmName string) tea.Cmd {
 	return func() tea.Msg {
-		output, err := ListSnapshots()
+		all, err := fetchAllSnapshots()
 		if err != nil {
 			return snapshotListResultMsg{vmName: vmName, err: err}
 		}
-		all := parseSnapshots(output)
 		var filtered []SnapshotInfo
 		for _, s := range all {
 			if s.Instance == vmName {
@@ -226,24 +500,24 @@ func fetchSnapshotsCmd(vmName string) tea.Cmd {
 // createSnapshotCmd creates a snapshot.
 func createSnapshotCmd(vmName, snapName, comment string) tea.Cmd {
 	return func() tea.Msg {
-		_, err := CreateSnapshot(vmName, snapName, comment)
-		return vmOperationResultMsg{vmName: vmName, operation: "snapshot", err: err}
+		trace, err := traceVMOp("snapshot", vmName, func() (string, error) { return CreateSnapshot(vmName, snapName, comment) })
+		return vmOperationResultMsg{vmName: vmName, operation: "snapshot", err: err, trace: trace}
 	}
 }
 
 // restoreSnapshotCmd restores a snapshot.
 func restoreSnapshotCmd(vmName, snapName string) tea.Cmd {
 	return func() tea.Msg {
-		_, err := RestoreSnapshot(vmName, snapName)
-		return vmOperationResultMsg{vmName: vmName, operation: "restore", err: err}
+		trace, err := traceVMOp("restore", vmName, func() (string, error) { return RestoreSnapshot(vmName, snapName) })
+		return vmOperationResultMsg{vmName: vmName, operation: "restore", err: err, trace: trace}
 	}
 }
 
 // deleteSnapshotCmd deletes a snapshot.
 func deleteSnapshotCmd(vmName, snapName string) tea.Cmd {
 	return func() tea.Msg {
-		_, err := DeleteSnapshot(vmName, snapName)
-		return vmOperationResultMsg{vmName: vmName, operation: "delete-snapshot", err: err}
+		trace, err := traceVMOp("delete-snapshot", vmName, func() (string, error) { return DeleteSnapshot(vmName, snapName) })
+		return vmOperationResultMsg{vmName: vmName, operation: "delete-snapshot", err: err, trace: trace}
 	}
 }
 
@@ -256,27 +530,42 @@ func fetchMountsCmd(vmName string) tea.Cmd {
 }
 
 // mountCmd mounts a local directory to a VM.
-func mountCmd(source, vmName, target string) tea.Cmd {
+func mountCmd(source, vmName, target string, opts mountOptions) tea.Cmd {
 	return func() tea.Msg {
-		_, err := runMultipassCommand("mount", source, vmName+":"+target)
-		return vmOperationResultMsg{vmName: vmName, operation: "mount", err: err}
+		trace, err := traceVMOp("mount", vmName, func() (string, error) { return runMultipassCommand(mountArgs(source, vmName, target, opts)...) })
+		return vmOperationResultMsg{vmName: vmName, operation: "mount", err: err, trace: trace}
 	}
 }
 
 // umountCmd unmounts a directory from a VM.
 func umountCmd(vmName, target string) tea.Cmd {
 	return func() tea.Msg {
-		_, err := runMultipassCommand("umount", vmName+":"+target)
-		return vmOperationResultMsg{vmName: vmName, operation: "umount", err: err}
+		trace, err := traceVMOp("umount", vmName, func() (string, error) { return runMultipassCommand("umount", vmName+":"+target) })
+		return vmOperationResultMsg{vmName: vmName, operation: "umount", err: err, trace: trace}
 	}
 }
 
 func runBulkVMOperation(opName string, names []string, operation func(string) (string, error)) error {
+	return runBulkVMOperationWithProgress(opName, names, operation, nil)
+}
+
+// runBulkVMOperationWithProgress is runBulkVMOperation with a per-item status
+// callback (running, then ok or failed). onProgress may be nil.
+func runBulkVMOperationWithProgress(opName string, names []string, operation func(string) (string, error), onProgress func(int, bulkItemStatus, error)) error {
+	report := func(i int, status bulkItemStatus, err error) {
+		if onProgress != nil {
+			onProgress(i, status, err)
+		}
+	}
 	var opErrs []error
-	for _, name := range names {
+	for i, name := range names {
+		report(i, bulkRunning, nil)
 		if _, err := operation(name); err != nil {
 			opErrs = append(opErrs, fmt.Errorf("%s %s: %w", opName, name, err))
+			report(i, bulkFailed, err)
+			continue
 		}
+		report(i, bulkOK, nil)
 	}
 	return errors.Join(opErrs...)
 }
@@ -2,8 +2,11 @@ package main
 
 import (
 	"errors"
+	"slices"
 	"strings"
 	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func TestRunBulkVMOperation(t *testing.T) {
@@ -32,3 +35,93 @@ func TestRunBulkVMOperation(t *testing.T) {
 		}
 	})
 }
+
+func TestRunBulkVMOperationWithProgressReportsEachItem(t *testing.T) {
+	m := newBulkProgressModel("stop-all", []string{"vm1", "vm2"})
+	err := runBulkVMOperationWithProgress("stop", []string{"vm1", "vm2"}, func(name string) (string, error) {
+		if name == "vm2" {
+			return "", errors.New("boom")
+		}
+		return "", nil
+	}, m.setStatus)
+	if err == nil {
+		t.Fatalf("expected aggregated error")
+	}
+
+	if m.items[0].status != bulkOK || m.items[1].status != bulkFailed {
+		t.Fatalf("unexpected statuses: %+v", m.items)
+	}
+	if got := m.summary(); got != "stop-all 2/2 (1 failed)" {
+		t.Fatalf("unexpected summary %q", got)
+	}
+}
+
+// runBulk feeds the bulk operation started by msg through m until it
+// finishes, returning the final model.
+func runBulk(t *testing.T, m rootModel, msg bulkStartMsg) rootModel {
+	t.Helper()
+	model, cmd := m.Update(msg)
+	m = model.(rootModel)
+	if m.currentView != viewBulk {
+		t.Fatalf("the bulk view should open, got %v", m.currentView)
+	}
+	for cmd != nil {
+		next := cmd()
+		model, cmd = m.Update(next)
+		m = model.(rootModel)
+		if _, done := next.(vmOperationResultMsg); done {
+			break
+		}
+	}
+	return m
+}
+
+func TestPurgeAllShowsBulkProgress(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	f := &fakeRunner{}
+	useFakeRunner(t, f)
+	m := rootModel{table: newTableModel(), state: newAppState(), currentView: viewTable, confirmLevel: confirmNone}
+	m.table.setVMs([]vmData{
+		{info: VMInfo{Name: "old-1", State: "Deleted"}},
+		{info: VMInfo{Name: "web", State: "Running"}},
+		{info: VMInfo{Name: "old-2", State: "Deleted"}},
+	})
+	m.state.addNote("old-1", "gone soon")
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+	m = model.(rootModel)
+	start, ok := m.pendingCmd().(bulkStartMsg)
+	if !ok || start.operation != "purge-all" || strings.Join(start.names, " ") != "old-1 old-2" {
+		t.Fatalf("purge-all should run through the bulk view, got %#v", start)
+	}
+	m = runBulk(t, m, start)
+	for _, name := range []string{"old-1", "old-2"} {
+		if !slices.ContainsFunc(f.calls, func(c []string) bool { return slices.Equal(c, []string{"delete", name, "--purge"}) }) {
+			t.Errorf("%s was not purged: %v", name, f.calls)
+		}
+	}
+	if finished, failed := m.bulk.counts(); !m.bulk.done || finished != 2 || failed != 0 {
+		t.Fatalf("bulk = %+v", m.bulk)
+	}
+	if _, ok := m.state.VMs["old-1"]; ok {
+		t.Error("a purged VM's record should be forgotten")
+	}
+}
+
+func TestGroupDeleteShowsBulkProgress(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	f := &fakeRunner{fail: map[string]error{"delete": errors.New("instance is busy")}}
+	useFakeRunner(t, f)
+	m := rootModel{table: groupedTable(groupPrefix), state: newAppState(), currentView: viewTable, confirmLevel: confirmNone}
+	m.table.selectGroup("web")
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	m = model.(rootModel)
+	start, ok := m.pendingCmd().(bulkStartMsg)
+	if !ok || start.operation != "delete-many" || strings.Join(start.names, " ") != "web-1 web-2" {
+		t.Fatalf("deleting a group should run through the bulk view, got %#v", start)
+	}
+	m = runBulk(t, m, start)
+	if _, failed := m.bulk.counts(); failed != 2 || m.bulk.items[0].err == nil {
+		t.Fatalf("each failure should be listed: %+v", m.bulk.items)
+	}
+}
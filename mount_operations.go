@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
+	"unicode"
 )
 
 // MountInfo represents a mount point between local filesystem and VM.
@@ -23,7 +25,22 @@ type multipassInfoResponse struct {
 }
 
 type multipassVMInfoDetail struct {
-	Mounts map[string]multipassMountDetail `json:"mounts"`
+	State         string                          `json:"state"`
+	SnapshotCount jsonFlexNumber                  `json:"snapshot_count"`
+	IPv4          []string                        `json:"ipv4"`
+	Release       string                          `json:"release"`
+	ImageHash     string                          `json:"image_hash"`
+	CPUCount      jsonFlexNumber                  `json:"cpu_count"`
+	Load          []float64                       `json:"load"`
+	Disks         map[string]multipassUsageDetail `json:"disks"`
+	Memory        multipassUsageDetail            `json:"memory"`
+	Mounts        map[string]multipassMountDetail `json:"mounts"`
+}
+
+// multipassUsageDetail is a used/total byte pair (disks report strings, memory reports numbers).
+type multipassUsageDetail struct {
+	Total jsonFlexNumber `json:"total"`
+	Used  jsonFlexNumber `json:"used"`
 }
 
 type multipassMountDetail struct {
@@ -65,3 +82,57 @@ func getVMMounts(vmName string) ([]MountInfo, error) {
 
 	return mounts, nil
 }
+
+// ─── Mount Options ─────────────────────────────────────────────────────────────
+
+// mountTypes are the `multipass mount --type` choices; classic (SSHFS) is
+// multipass's default.
+var mountTypes = []string{"classic", "native"}
+
+// mountOptions are the optional flags of multipass mount.
+type mountOptions struct {
+	Type    string   // "" or "classic" for the default, "native" for the hypervisor's own sharing
+	UIDMaps []string // host:instance pairs for --uid-map
+	GIDMaps []string // host:instance pairs for --gid-map
+}
+
+// mountArgs builds the multipass mount arguments.
+func mountArgs(source, vmName, target string, opts mountOptions) []string {
+	args := []string{"mount"}
+	if opts.Type != "" && opts.Type != "classic" {
+		args = append(args, "--type", opts.Type)
+	}
+	for _, m := range opts.UIDMaps {
+		args = append(args, "--uid-map", m)
+	}
+	for _, m := range opts.GIDMaps {
+		args = append(args, "--gid-map", m)
+	}
+	return append(args, source, vmName+":"+target)
+}
+
+// parseIDMaps parses comma- or space-separated host:instance ID pairs,
+// e.g. "1000:1000, 1001:0".
+func parseIDMaps(s string) ([]string, error) {
+	var maps []string
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		host, instance, ok := strings.Cut(f, ":")
+		if !ok || !isDigits(host) || !isDigits(instance) {
+			return nil, fmt.Errorf("ID map %q: want HOST:INSTANCE, e.g. 1000:1000", f)
+		}
+		maps = append(maps, f)
+	}
+	return maps, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
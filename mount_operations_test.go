@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestMountArgs(t *testing.T) {
+	got := strings.Join(mountArgs("/src", "web", "/home/ubuntu/src", mountOptions{}), " ")
+	if got != "mount /src web:/home/ubuntu/src" {
+		t.Errorf("default = %q", got)
+	}
+	opts := mountOptions{Type: "native", UIDMaps: []string{"1000:1000"}, GIDMaps: []string{"1000:1000", "20:0"}}
+	got = strings.Join(mountArgs("/src", "web", "/mnt", opts), " ")
+	if got != "mount --type native --uid-map 1000:1000 --gid-map 1000:1000 --gid-map 20:0 /src web:/mnt" {
+		t.Errorf("with options = %q", got)
+	}
+}
+
+func TestParseIDMaps(t *testing.T) {
+	maps, err := parseIDMaps(" 1000:1000, 1001:0  501:1000")
+	if err != nil || strings.Join(maps, ",") != "1000:1000,1001:0,501:1000" {
+		t.Errorf("maps = %v, %v", maps, err)
+	}
+	if maps, err := parseIDMaps(""); err != nil || maps != nil {
+		t.Errorf("empty = %v, %v", maps, err)
+	}
+	for _, bad := range []string{"1000", "a:1", "1000:", "-1:0"} {
+		if _, err := parseIDMaps(bad); err == nil {
+			t.Errorf("parseIDMaps(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestMountModifyKeepsIDMapsAndSubmitsOptions(t *testing.T) {
+	m := newMountModifyModel("web", MountInfo{SourcePath: "/src", TargetPath: "/mnt", UIDMaps: []string{"1000:1000"}}, 100, 40)
+	m.cursor = 2
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	m.cursor = 5
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected a submit")
+	}
+	sub, ok := cmd().(mountModifySubmitMsg)
+	if !ok || sub.opts.Type != "native" || strings.Join(sub.opts.UIDMaps, ",") != "1000:1000" {
+		t.Errorf("submit = %+v", sub)
+	}
+
+	m.opts.gidInput.SetValue("oops")
+	if m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil || m.errMsg == "" {
+		t.Error("an invalid GID map should block the submit")
+	}
+}
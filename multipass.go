@@ -4,34 +4,108 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 )
 
-// runMultipassCommand executes multipass commands with variadic arguments
+// runMultipassCommand executes multipass commands with variadic arguments.
+// Commands that change an instance wait for any other such command on it
+// (see vmlock.go), and each command is killed once its class timeout
+// passes (see timeout.go).
 func runMultipassCommand(args ...string) (string, error) {
-	cmd := exec.Command("multipass", args...) // #nosec G204 -- multipass CLI wrapper
+	args = translatePathArgs(args, currentPathStyle())
+	defer lockForCommand(args)()
+	ctx, cancel := multipassContext(args)
+	defer cancel()
 	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	start := time.Now()
+	tag := logPrefix(args)
 	if appLogger != nil {
-		appLogger.Printf("exec: multipass %s", strings.Join(args, " "))
+		appLogger.Printf("%sexec: multipass %s", tag, strings.Join(redactExecEnv(args), " "))
 	}
-	err := cmd.Run()
+	err := timeoutError(ctx, args, mpRunner.run(ctx, args, nil, &stdout, &stderr))
 	if err != nil {
 		if appLogger != nil {
-			appLogger.Printf("exec error: %v; stderr: %s", err, strings.TrimSpace(stderr.String()))
+			appLogger.Printf("%sexec error: %v; stderr: %s", tag, err, strings.TrimSpace(stderr.String()))
 		}
-		return "", fmt.Errorf("command failed: %v\nStderr: %s", err, stderr.String())
+		return "", newCommandError(args, err, stderr.String(), time.Since(start))
 	}
 	return strings.TrimSpace(stdout.String()), nil
 }
 
+// runMultipassCommandStreaming runs a multipass command like runMultipassCommand,
+// but also calls onLine for every line written to stdout or stderr as it
+// arrives. Carriage returns count as line breaks so spinner updates are seen.
+func runMultipassCommandStreaming(onLine func(string), args ...string) (string, error) {
+	args = translatePathArgs(args, currentPathStyle())
+	defer lockForCommand(args)()
+	ctx, cancel := multipassContext(args)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	start := time.Now()
+	lines := &lineWriter{onLine: onLine}
+	tag := logPrefix(args)
+	if appLogger != nil {
+		appLogger.Printf("%sexec (streaming): multipass %s", tag, strings.Join(redactExecEnv(args), " "))
+	}
+	err := timeoutError(ctx, args, mpRunner.run(ctx, args, nil, io.MultiWriter(&stdout, lines), io.MultiWriter(&stderr, lines)))
+	lines.flush()
+	if err != nil {
+		if appLogger != nil {
+			appLogger.Printf("%sexec error: %v; stderr: %s", tag, err, strings.TrimSpace(stderr.String()))
+		}
+		return "", newCommandError(args, err, stderr.String(), time.Since(start))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// lineWriter splits written bytes on \n or \r and reports each non-empty line.
+// It is safe for concurrent use by the stdout and stderr copiers.
+type lineWriter struct {
+	mu     sync.Mutex
+	buf    []byte
+	onLine func(string)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		idx := bytes.IndexAny(w.buf, "\r\n")
+		if idx < 0 {
+			break
+		}
+		w.emit(string(w.buf[:idx]))
+		w.buf = w.buf[idx+1:]
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.emit(string(w.buf))
+	w.buf = nil
+}
+
+func (w *lineWriter) emit(line string) {
+	line = strings.TrimSpace(line)
+	if line != "" && w.onLine != nil {
+		w.onLine(line)
+	}
+}
+
 // NetworkInfo represents an interface from multipass networks.
 // Works on Linux (QEMU), Windows (Hyper-V/VirtualBox), macOS (QEMU/VirtualBox).
 type NetworkInfo struct {
@@ -65,6 +139,80 @@ func ListNetworks() ([]NetworkInfo, error) {
 	return resp.List, nil
 }
 
+// networkKind classifies a host interface for bridging purposes.
+type networkKind int
+
+const (
+	netKindOther networkKind = iota
+	netKindEthernet
+	netKindWifi
+	netKindHyperVSwitch
+	netKindVBoxHostOnly
+)
+
+func (k networkKind) String() string {
+	switch k {
+	case netKindEthernet:
+		return "Ethernet"
+	case netKindWifi:
+		return "Wi-Fi"
+	case netKindHyperVSwitch:
+		return "Hyper-V switch"
+	case netKindVBoxHostOnly:
+		return "VirtualBox host-only"
+	default:
+		return "Other"
+	}
+}
+
+// classifyNetwork derives the interface kind from the type and description
+// reported by `multipass networks`.
+func classifyNetwork(n NetworkInfo) networkKind {
+	typ := strings.ToLower(n.Type)
+	desc := strings.ToLower(n.Description)
+	switch {
+	case typ == "switch" || strings.Contains(desc, "virtual switch"):
+		return netKindHyperVSwitch
+	case strings.Contains(desc, "host-only"):
+		return netKindVBoxHostOnly
+	case typ == "wifi" || strings.Contains(desc, "wi-fi") || strings.Contains(desc, "wireless"):
+		return netKindWifi
+	case typ == "ethernet":
+		return netKindEthernet
+	}
+	return netKindOther
+}
+
+// bridgeWarning explains why driver probably cannot bridge n, or returns "".
+func bridgeWarning(driver string, n NetworkInfo) string {
+	kind := classifyNetwork(n)
+	switch strings.ToLower(driver) {
+	case "hyperv":
+		switch kind {
+		case netKindVBoxHostOnly:
+			return n.Name + " is a VirtualBox host-only adapter; Hyper-V cannot bridge it"
+		case netKindWifi:
+			return n.Name + " is Wi-Fi; Hyper-V external switches on Wi-Fi are often unreliable"
+		case netKindHyperVSwitch:
+			desc := strings.ToLower(n.Description)
+			if strings.Contains(desc, "internal") || strings.Contains(desc, "private") {
+				return n.Name + " is an internal/private switch; the VM will not reach the LAN"
+			}
+		}
+	case "virtualbox":
+		if kind == netKindHyperVSwitch {
+			return n.Name + " is a Hyper-V switch; the VirtualBox driver cannot use it"
+		}
+	}
+	return ""
+}
+
+// GetDriver returns the active multipass driver (local.driver), e.g. "hyperv".
+func GetDriver() (string, error) {
+	out, err := GetSetting("local.driver")
+	return strings.TrimSpace(out), err
+}
+
 // LaunchVM creates a new virtual machine with basic settings
 func LaunchVM(name, release string) (string, error) {
 	args := []string{"launch", "--name", name, release}
@@ -74,6 +222,91 @@ func LaunchVM(name, release string) (string, error) {
 // LaunchVMAdvanced creates VM with custom resource settings.
 // networkName: "" = NAT, "bridged" = --bridged (uses configured default), else --network <name>.
 func LaunchVMAdvanced(name, release string, cpus int, memoryMB int, diskGB int, networkName string) (string, error) {
+	return LaunchVMWithNetworks(name, release, cpus, memoryMB, diskGB, "", networksFromName(networkName))
+}
+
+// LaunchVMWithNetworks creates a VM with any number of extra network
+// interfaces, each passed as its own --network flag.
+func LaunchVMWithNetworks(name, release string, cpus, memoryMB, diskGB int, cloudInitFile string, networks []NetworkSpec) (string, error) {
+	return runMultipassCommand(launchVMArgs(name, release, cpus, memoryMB, diskGB, cloudInitFile, networks)...)
+}
+
+// NetworkSpec describes an extra network interface for `multipass launch`.
+type NetworkSpec struct {
+	Name string // host interface, or "bridged" for the configured default bridge
+	Mode string // "auto" or "manual"; "" leaves the multipass default (auto)
+	MAC  string // optional fixed MAC address for the guest interface
+}
+
+// networksFromName converts the legacy single network name ("" = NAT only).
+func networksFromName(networkName string) []NetworkSpec {
+	if networkName == "" {
+		return nil
+	}
+	return []NetworkSpec{{Name: networkName}}
+}
+
+// launchArgs returns the launch flags for this interface. A plain bridged
+// network uses --bridged; anything else uses --network with key=value options.
+func (n NetworkSpec) launchArgs() []string {
+	if n.Name == "" {
+		return nil
+	}
+	if n.Mode == "" && n.MAC == "" {
+		if n.Name == "bridged" {
+			return []string{"--bridged"}
+		}
+		return []string{"--network", n.Name}
+	}
+	opts := []string{"name=" + n.Name}
+	if n.Mode != "" {
+		opts = append(opts, "mode="+n.Mode)
+	}
+	if n.MAC != "" {
+		opts = append(opts, "mac="+n.MAC)
+	}
+	return []string{"--network", strings.Join(opts, ",")}
+}
+
+// parseNetworkSpec parses multipass's --network syntax: either a bare
+// interface name or comma-separated name=,mode=,mac= options.
+func parseNetworkSpec(s string) (NetworkSpec, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return NetworkSpec{}, fmt.Errorf("empty network")
+	}
+	if !strings.Contains(s, "=") {
+		return NetworkSpec{Name: s}, nil
+	}
+	var n NetworkSpec
+	for _, opt := range strings.Split(s, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(opt), "=")
+		if !ok {
+			return NetworkSpec{}, fmt.Errorf("invalid network option %q", opt)
+		}
+		switch key {
+		case "name":
+			n.Name = val
+		case "mode":
+			if val != "auto" && val != "manual" {
+				return NetworkSpec{}, fmt.Errorf("network mode must be auto or manual, got %q", val)
+			}
+			n.Mode = val
+		case "mac":
+			n.MAC = val
+		default:
+			return NetworkSpec{}, fmt.Errorf("unknown network option %q", key)
+		}
+	}
+	if n.Name == "" {
+		return NetworkSpec{}, fmt.Errorf("network %q has no name", s)
+	}
+	return n, nil
+}
+
+// launchVMArgs builds the argument list for an advanced `multipass launch`.
+// cloudInitFile is omitted when empty; no networks means NAT only.
+func launchVMArgs(name, release string, cpus, memoryMB, diskGB int, cloudInitFile string, networks []NetworkSpec) []string {
 	args := []string{
 		"launch",
 		"--name", name,
@@ -81,28 +314,56 @@ func LaunchVMAdvanced(name, release string, cpus int, memoryMB int, diskGB int,
 		"--memory", fmt.Sprintf("%dM", memoryMB),
 		"--disk", fmt.Sprintf("%dG", diskGB),
 	}
-	if networkName == "bridged" {
-		args = append(args, "--bridged")
-	} else if networkName != "" {
-		args = append(args, "--network", networkName)
+	if cloudInitFile != "" {
+		args = append(args, "--cloud-init", cloudInitFile)
 	}
-	args = append(args, release)
-	return runMultipassCommand(args...)
+	for _, n := range networks {
+		args = append(args, n.launchArgs()...)
+	}
+	return append(args, release)
 }
 
 func ListVMs() (string, error) {
 	return runMultipassCommand("list")
 }
 
+// StopVM stops a VM, then runs the post-stop hook.
 func StopVM(name string) (string, error) {
-	return runMultipassCommand("stop", name)
+	out, err := runMultipassCommand("stop", name)
+	if err == nil {
+		_ = runHook(hookPostStop, func() hookVM { return hookVM{name: name, state: "Stopped"} })
+	}
+	return out, err
+}
+
+// forceStopMinVersion is the first multipass whose stop takes --force.
+const forceStopMinVersion = "1.13.0"
+
+// forceStopSupported reports whether the multipass client version supports
+// `stop --force`; an unknown version does not.
+func forceStopSupported(client string) bool {
+	return client != "" && compareVersions(client, forceStopMinVersion) >= 0
+}
+
+// ForceStopVM powers name off without a clean shutdown, for instances that
+// hang or are stuck starting.
+func ForceStopVM(name string) (string, error) {
+	out, err := runMultipassCommand("stop", "--force", name)
+	if err == nil {
+		_ = runHook(hookPostStop, func() hookVM { return hookVM{name: name, state: "Stopped"} })
+	}
+	return out, err
 }
 
 func StartVM(name string) (string, error) {
 	return runMultipassCommand("start", name)
 }
 
+// DeleteVM deletes a VM unless the pre-delete hook fails.
 func DeleteVM(name string, purge bool) (string, error) {
+	if err := runHook(hookPreDelete, func() hookVM { return hookVMFromInfo(name) }); err != nil {
+		return "", err
+	}
 	args := []string{"delete", name}
 	if purge {
 		args = append(args, "--purge")
@@ -114,13 +375,85 @@ func RecoverVM(name string) (string, error) {
 	return runMultipassCommand("recover", name)
 }
 
+// TransferFromVM copies remotePath in the VM to localPath on the host.
+func TransferFromVM(vmName, remotePath, localPath string) (string, error) {
+	return runMultipassCommand("transfer", vmName+":"+remotePath, localPath)
+}
+
+// TransferToVM copies localPath on the host to remotePath in the VM.
+func TransferToVM(vmName, localPath, remotePath string) (string, error) {
+	return runMultipassCommand("transfer", localPath, vmName+":"+remotePath)
+}
+
 func ExecInVM(vmName string, commandArgs ...string) (string, error) {
+	return ExecInVMWith(vmName, execOptions{}, commandArgs...)
+}
+
+// execOptions adjust how a command is run by multipass exec.
+type execOptions struct {
+	WorkDir string   // directory in the VM to run in; "" means the home directory
+	Env     []string // NAME=VALUE pairs, set through env(1) in the VM
+	User    string   // run through sudo -u as this user; "" means the default user
+}
+
+// execArgs builds the multipass exec arguments for commandArgs in vmName.
+func execArgs(vmName string, opts execOptions, commandArgs []string) []string {
+	args := []string{"exec", vmName}
+	if opts.WorkDir != "" {
+		args = append(args, "--working-directory", opts.WorkDir)
+	}
+	args = append(args, "--")
+	if opts.User != "" {
+		args = append(args, "sudo", "-u", opts.User)
+	}
+	if len(opts.Env) > 0 {
+		args = append(append(args, "env"), opts.Env...)
+	}
+	return append(args, commandArgs...)
+}
+
+// redactExecEnv hides the values of env(1) assignments in multipass exec
+// arguments, which may hold tokens, before they are logged.
+func redactExecEnv(args []string) []string {
+	if len(args) == 0 || args[0] != "exec" {
+		return args
+	}
+	out := slices.Clone(args)
+	i := slices.Index(out, "--") + 1
+	if i > 0 && i+2 < len(out) && out[i] == "sudo" && out[i+1] == "-u" {
+		i += 3
+	}
+	if i == 0 || i >= len(out) || out[i] != "env" {
+		return out
+	}
+	for i++; i < len(out); i++ {
+		name, _, ok := strings.Cut(out[i], "=")
+		if !ok {
+			break
+		}
+		out[i] = name + "=***"
+	}
+	return out
+}
+
+// ExecInVMWith runs a command in the VM like ExecInVM, with opts applied.
+func ExecInVMWith(vmName string, opts execOptions, commandArgs ...string) (string, error) {
+	return runMultipassCommand(execArgs(vmName, opts, commandArgs)...)
+}
+
+// ExecInVMAttached runs a command in the VM with its output streamed to
+// stdout/stderr and stdin (if non-nil) fed to it. The returned error is an
+// *exec.ExitError when the command exits non-zero.
+func ExecInVMAttached(vmName string, stdin io.Reader, stdout, stderr io.Writer, commandArgs ...string) error {
 	args := append([]string{"exec", vmName, "--"}, commandArgs...)
-	return runMultipassCommand(args...)
+	if appLogger != nil {
+		appLogger.Printf("%sexec (attached): multipass %s", logPrefix(args), strings.Join(args, " "))
+	}
+	return mpRunner.run(context.Background(), args, stdin, stdout, stderr)
 }
 
 func ShellVM(vmName string) error {
-	cmd := exec.Command("multipass", "shell", vmName) // #nosec G204 -- VM name from user selection
+	cmd := mpRunner.interactive([]string{"shell", vmName})
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -131,6 +464,36 @@ func GetVMInfo(name string) (string, error) {
 	return runMultipassCommand("info", name)
 }
 
+// GetVMInterfaces lists the guest's network interfaces (running VMs only).
+func GetVMInterfaces(vmName string) ([]VMInterface, error) {
+	output, err := ExecInVM(vmName, "ip", "-j", "addr", "show")
+	if err != nil {
+		return nil, err
+	}
+	return parseIPAddrJSON(output)
+}
+
+// GetSetting reads a multipass setting, e.g. "local.bridged-network".
+func GetSetting(key string) (string, error) {
+	return runMultipassCommand("get", key)
+}
+
+// SetSetting changes a multipass setting, e.g. "local.bridged-network".
+func SetSetting(key, value string) (string, error) {
+	return runMultipassCommand("set", key+"="+value)
+}
+
+// GetBridgedNetwork returns the default bridge used by --bridged ("" if unset).
+func GetBridgedNetwork() (string, error) {
+	out, err := GetSetting("local.bridged-network")
+	return strings.TrimSpace(out), err
+}
+
+// GetAllVMInfoJSON fetches details for every instance in a single call.
+func GetAllVMInfoJSON() (string, error) {
+	return runMultipassCommand("info", "--all", "--format", "json")
+}
+
 func CreateSnapshot(vmName, snapshotName, description string) (string, error) {
 	args := []string{"snapshot", "--name", snapshotName, "--comment", description, vmName}
 	return runMultipassCommand(args...)
@@ -140,6 +503,12 @@ func ListSnapshots() (string, error) {
 	return runMultipassCommand("list", "--snapshots")
 }
 
+// GetSnapshotDetailsJSON returns every instance's snapshots with creation
+// times (`multipass info --all --snapshots --format json`).
+func GetSnapshotDetailsJSON() (string, error) {
+	return runMultipassCommand("info", "--all", "--snapshots", "--format", "json")
+}
+
 func RestoreSnapshot(vmName, snapshotName string) (string, error) {
 	snapshotID := vmName + "." + snapshotName
 	args := []string{"restore", "--destructive", snapshotID}
@@ -154,7 +523,7 @@ func DeleteSnapshot(vmName, snapshotName string) (string, error) {
 
 // ScanCloudInitFiles finds YAML files with "#cloud-config" header for VM configuration
 func ScanCloudInitFiles() ([]string, error) {
-	options, err := scanCloudInitTemplateOptions(appSearchDirs())
+	options, err := scanCloudInitTemplateOptions(appSearchDirs(), configList("template-ignore"))
 	if err != nil {
 		return nil, err
 	}
@@ -169,27 +538,48 @@ func ScanCloudInitFiles() ([]string, error) {
 // LaunchVMWithCloudInit creates VM with cloud-init.
 // networkName: "" = NAT, "bridged" = --bridged, else --network <name>.
 func LaunchVMWithCloudInit(name, release string, cpus int, memoryMB int, diskGB int, cloudInitFile, networkName string) (string, error) {
-	args := []string{
-		"launch",
-		"--name", name,
-		"--cpus", fmt.Sprintf("%d", cpus),
-		"--memory", fmt.Sprintf("%dM", memoryMB),
-		"--disk", fmt.Sprintf("%dG", diskGB),
-		"--cloud-init", cloudInitFile,
-	}
-	if networkName == "bridged" {
-		args = append(args, "--bridged")
-	} else if networkName != "" {
-		args = append(args, "--network", networkName)
-	}
-	args = append(args, release)
-	return runMultipassCommand(args...)
+	return LaunchVMWithNetworks(name, release, cpus, memoryMB, diskGB, cloudInitFile, networksFromName(networkName))
 }
 
 // TemplateOption represents a selectable cloud-init template
 type TemplateOption struct {
-	Label string
-	Path  string
+	Label   string
+	Path    string
+	Warning string // set when a repo template failed verification (see template_verify.go)
+	Source  string // directory of a local template, or repo name for a repo one
+	Commit  string // repo commit SHA; empty for local templates
+	// From a sibling <name>.md or the template's leading comments (see template_describe.go)
+	Description string
+}
+
+// Provenance describes where the template came from, e.g.
+// "repo/web.yaml from github.com/team/templates@3f2a…" or
+// "web.yaml from /home/me/templates". shortSHA shortens the commit.
+func (o TemplateOption) Provenance(shortSHA bool) string {
+	if o.Source == "" {
+		return o.Label
+	}
+	from := o.Source
+	if o.Commit != "" {
+		commit := o.Commit
+		if shortSHA && len(commit) > 12 {
+			commit = commit[:12]
+		}
+		from += "@" + commit
+	}
+	return o.Label + " from " + from
+}
+
+// repoDisplayName shortens a clone URL to host/owner/name.
+func repoDisplayName(repoURL string) string {
+	name := strings.TrimSuffix(strings.TrimSuffix(repoURL, "/"), ".git")
+	if _, rest, ok := strings.Cut(name, "://"); ok {
+		return rest
+	}
+	if _, rest, ok := strings.Cut(name, "@"); ok {
+		return strings.Replace(rest, ":", "/", 1) // git@github.com:team/repo
+	}
+	return name
 }
 
 var errConfigRepoNotFound = errors.New("github-cloud-init-repo not found")
@@ -197,10 +587,13 @@ var errConfigRepoNotFound = errors.New("github-cloud-init-repo not found")
 func appSearchDirs() []string {
 	exePath, _ := os.Executable()
 	cwd, _ := os.Getwd()
-	return appSearchDirsFrom(exePath, cwd)
+	dir, _ := userConfigDir()
+	return appSearchDirsFrom(exePath, cwd, dir)
 }
 
-func appSearchDirsFrom(exePath, cwd string) []string {
+// appSearchDirsFrom returns the executable's directory, the working
+// directory and any extra directories, without duplicates.
+func appSearchDirsFrom(exePath, cwd string, extra ...string) []string {
 	var dirs []string
 
 	if exePath != "" {
@@ -209,6 +602,7 @@ func appSearchDirsFrom(exePath, cwd string) []string {
 	if cwd != "" {
 		dirs = append(dirs, cwd)
 	}
+	dirs = append(dirs, extra...)
 
 	seen := make(map[string]struct{}, len(dirs))
 	var deduped []string
@@ -256,7 +650,10 @@ func hasCloudConfigHeader(filePath string) bool {
 	return false
 }
 
-func scanCloudInitTemplateOptions(searchDirs []string) ([]TemplateOption, error) {
+// scanCloudInitTemplateOptions lists the cloud-config YAMLs directly in
+// searchDirs, except those matching the ignore patterns (see
+// template_ignore.go).
+func scanCloudInitTemplateOptions(searchDirs, ignore []string) ([]TemplateOption, error) {
 	seenPaths := make(map[string]struct{})
 	seenLabels := make(map[string]string)
 	var options []TemplateOption
@@ -271,12 +668,13 @@ func scanCloudInitTemplateOptions(searchDirs []string) ([]TemplateOption, error)
 			continue
 		}
 
+		ig := loadTemplateIgnore(dir, ignore)
 		for _, entry := range entries {
 			if entry.IsDir() {
 				continue
 			}
 			fileName := entry.Name()
-			if !isYAMLFileName(fileName) {
+			if !isYAMLFileName(fileName) || ig.ignored(fileName, false) {
 				continue
 			}
 
@@ -299,7 +697,7 @@ func scanCloudInitTemplateOptions(searchDirs []string) ([]TemplateOption, error)
 
 			seenPaths[filePath] = struct{}{}
 			seenLabels[label] = filePath
-			options = append(options, TemplateOption{Label: label, Path: filePath})
+			options = append(options, TemplateOption{Label: label, Path: filePath, Source: dir})
 		}
 	}
 
@@ -389,52 +787,59 @@ func CloneRepoAndScanYAMLs(repoURL string) ([]TemplateOption, string, error) {
 		return nil, "", fmt.Errorf("empty repo URL")
 	}
 
-	tmpDir, err := os.MkdirTemp("", "passgo-cloudinit-*")
+	tmpDir, err := os.MkdirTemp("", ownedTempPattern("passgo-cloudinit-*"))
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to create temp dir: %v", err)
 	}
+	tempArtifacts.add(tmpDir)
+	var paths []string
+	if v, ok := configValue("template-repo-paths"); ok {
+		if paths, err = parseRepoPaths(v); err != nil && appLogger != nil {
+			appLogger.Printf("ignoring template-repo-paths: %v", err)
+		}
+	}
 	if appLogger != nil {
-		appLogger.Printf("cloning repo %s into %s", repoURL, tmpDir)
+		appLogger.Printf("cloning repo %s into %s (paths: %s)", repoURL, tmpDir, orDashes(strings.Join(paths, ", ")))
 	}
 
-	// Shallow clone
-	cmd := exec.Command("git", "clone", "--depth", "1", repoURL, tmpDir) // #nosec G204 -- repo URL from user .config
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	// Shallow clone, sparse when paths are set
+	if err := cloneTemplateRepo(repoURL, tmpDir, paths); err != nil {
 		if appLogger != nil {
-			appLogger.Printf("git clone failed: %v; %s", err, strings.TrimSpace(stderr.String()))
+			appLogger.Printf("%v", err)
 		}
-		_ = os.RemoveAll(tmpDir)
-		return nil, "", fmt.Errorf("git clone failed: %v; %s", err, stderr.String())
+		tempArtifacts.remove(tmpDir)
+		return nil, "", err
 	}
 
-	// Walk repo and collect all .yml/.yaml files (no header requirement)
-	var options []TemplateOption
-	err = filepath.WalkDir(tmpDir, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		if d.IsDir() {
-			return nil
-		}
-		lower := strings.ToLower(d.Name())
-		if !strings.HasSuffix(lower, ".yml") && !strings.HasSuffix(lower, ".yaml") {
-			return nil
-		}
-		rel, _ := filepath.Rel(tmpDir, path)
-		label := "repo/" + rel
-		options = append(options, TemplateOption{Label: label, Path: path})
-		return nil
-	})
+	// Record the commit for provenance
+	var commit string
+	if out, err := exec.Command("git", "-C", tmpDir, "rev-parse", "HEAD").Output(); err == nil { // #nosec G204 -- temp dir created above
+		commit = strings.TrimSpace(string(out))
+	}
+	source := repoDisplayName(repoURL)
+
+	// Collect the .yml/.yaml files, keeping those that look like
+	// cloud-config unless template-repo-all is set
+	options, err := scanRepoYAMLs(tmpDir, paths, configList("template-ignore"), source, commit)
 	if err != nil {
-		_ = os.RemoveAll(tmpDir)
+		tempArtifacts.remove(tmpDir)
 		return nil, "", fmt.Errorf("failed to scan repo: %v", err)
 	}
+	if !configBool("template-repo-all") {
+		options = filterCloudConfigs(options)
+	}
 	if appLogger != nil {
 		appLogger.Printf("found %d yaml templates in repo", len(options))
 	}
 
+	signingKey, _ := configValue("template-signing-key")
+	options, problems := verifyRepoTemplates(tmpDir, options, templateVerifyMode(configValue), signingKey)
+	for _, p := range problems {
+		if appLogger != nil {
+			appLogger.Printf("template verification: %s", p)
+		}
+	}
+
 	return options, tmpDir, nil
 }
 
@@ -445,7 +850,7 @@ func GetAllCloudInitTemplateOptions() ([]TemplateOption, []string, error) {
 	var cleanupDirs []string
 
 	// Local templates (preferred search dirs)
-	local, err := scanCloudInitTemplateOptions(appSearchDirs())
+	local, err := scanCloudInitTemplateOptions(appSearchDirs(), configList("template-ignore"))
 	if err == nil {
 		all = append(all, local...)
 		if appLogger != nil {
@@ -468,6 +873,7 @@ func GetAllCloudInitTemplateOptions() ([]TemplateOption, []string, error) {
 		}
 	}
 
+	describeTemplates(all)
 	return all, cleanupDirs, nil
 }
 
@@ -480,6 +886,6 @@ func CleanupTempDirs(dirs []string) {
 		if appLogger != nil {
 			appLogger.Printf("cleanup temp dir: %s", d)
 		}
-		_ = os.RemoveAll(d)
+		tempArtifacts.remove(d)
 	}
 }
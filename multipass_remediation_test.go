@@ -3,6 +3,7 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -47,7 +48,7 @@ func TestScanCloudInitTemplateOptionsUsesBothDirsAndDedupes(t *testing.T) {
 	write(filepath.Join(cwdDir, "cwd-only.yaml"), "#cloud-config\npackages: []\n")
 	write(filepath.Join(cwdDir, "ignored.yml"), "not-cloud-config\n")
 
-	opts, err := scanCloudInitTemplateOptions([]string{execDir, cwdDir, cwdDir})
+	opts, err := scanCloudInitTemplateOptions([]string{execDir, cwdDir, cwdDir}, nil)
 	if err != nil {
 		t.Fatalf("scanCloudInitTemplateOptions returned error: %v", err)
 	}
@@ -123,3 +124,83 @@ func TestReadConfigGithubRepoFromDirsFallsBackToCWD(t *testing.T) {
 		t.Fatalf("unexpected repo URL: got %q want %q", got, want)
 	}
 }
+
+func TestNetworkSpecLaunchArgs(t *testing.T) {
+	tests := []struct {
+		spec NetworkSpec
+		want string
+	}{
+		{NetworkSpec{}, ""},
+		{NetworkSpec{Name: "bridged"}, "--bridged"},
+		{NetworkSpec{Name: "eth0"}, "--network eth0"},
+		{NetworkSpec{Name: "eth0", MAC: "52:54:00:12:34:56"}, "--network name=eth0,mac=52:54:00:12:34:56"},
+		{NetworkSpec{Name: "bridged", Mode: "manual"}, "--network name=bridged,mode=manual"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(tt.spec.launchArgs(), " "); got != tt.want {
+			t.Errorf("launchArgs(%+v) = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseNetworkSpec(t *testing.T) {
+	got, err := parseNetworkSpec(" name=eth1,mode=manual,mac=52:54:00:00:00:01 ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := NetworkSpec{Name: "eth1", Mode: "manual", MAC: "52:54:00:00:00:01"}
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	if got, _ := parseNetworkSpec("eth2"); got.Name != "eth2" {
+		t.Fatalf("expected bare name, got %+v", got)
+	}
+	for _, bad := range []string{"", "mode=auto", "name=eth1,mode=dhcp", "name=eth1,vlan=3", "name=eth1,mac"} {
+		if _, err := parseNetworkSpec(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestLaunchVMArgsMultipleNetworks(t *testing.T) {
+	args := launchVMArgs("lab", "24.04", 2, 2048, 10, "", []NetworkSpec{
+		{Name: "eth0"},
+		{Name: "eth1", Mode: "manual"},
+	})
+	got := strings.Join(args, " ")
+	want := "launch --name lab --cpus 2 --memory 2048M --disk 10G --network eth0 --network name=eth1,mode=manual 24.04"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestBridgeWarning(t *testing.T) {
+	wifi := NetworkInfo{Name: "Wi-Fi", Type: "wifi", Description: "Intel Wireless"}
+	hostOnly := NetworkInfo{Name: "vbox0", Type: "ethernet", Description: "VirtualBox Host-Only Ethernet Adapter"}
+	internal := NetworkInfo{Name: "Default Switch", Type: "switch", Description: "Virtual Switch with internal networking"}
+	external := NetworkInfo{Name: "ExtSwitch", Type: "switch", Description: "Virtual Switch with external networking via \"Ethernet\""}
+	eth := NetworkInfo{Name: "Ethernet", Type: "ethernet", Description: "Realtek PCIe"}
+
+	if got := classifyNetwork(hostOnly); got != netKindVBoxHostOnly {
+		t.Fatalf("expected host-only, got %v", got)
+	}
+	tests := []struct {
+		driver string
+		n      NetworkInfo
+		warn   bool
+	}{
+		{"hyperv", wifi, true},
+		{"hyperv", hostOnly, true},
+		{"hyperv", internal, true},
+		{"hyperv", external, false},
+		{"hyperv", eth, false},
+		{"virtualbox", external, true},
+		{"virtualbox", wifi, false},
+		{"qemu", hostOnly, false},
+	}
+	for _, tt := range tests {
+		if got := bridgeWarning(tt.driver, tt.n) != ""; got != tt.warn {
+			t.Errorf("bridgeWarning(%s, %s) warned=%v, want %v", tt.driver, tt.n.Name, got, tt.warn)
+		}
+	}
+}
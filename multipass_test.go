@@ -613,6 +613,32 @@ func buildExecArgs(vmName string, command ...string) []string {
 	return args
 }
 
+func TestTemplateProvenance(t *testing.T) {
+	repo := TemplateOption{Label: "repo/web.yaml", Source: "github.com/team/templates", Commit: "3f2a9c1d8e7b6a5f4e3d2c1b0a9f8e7d6c5b4a39"}
+	if got, want := repo.Provenance(true), "repo/web.yaml from github.com/team/templates@3f2a9c1d8e7b"; got != want {
+		t.Errorf("Provenance(true) = %q, want %q", got, want)
+	}
+	if got := repo.Provenance(false); !strings.HasSuffix(got, "@"+repo.Commit) {
+		t.Errorf("Provenance(false) = %q, want the full commit", got)
+	}
+	local := TemplateOption{Label: "web.yaml", Source: "/home/me/templates"}
+	if got, want := local.Provenance(true), "web.yaml from /home/me/templates"; got != want {
+		t.Errorf("local Provenance = %q, want %q", got, want)
+	}
+}
+
+func TestRepoDisplayName(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://github.com/team/templates":      "github.com/team/templates",
+		"https://github.com/team/templates.git/": "github.com/team/templates",
+		"git@github.com:team/templates.git":      "github.com/team/templates",
+	} {
+		if got := repoDisplayName(in); got != want {
+			t.Errorf("repoDisplayName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
 // Integration test placeholder - these would require running actual multipass commands
 // or using a mock/stub framework
 
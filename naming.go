@@ -0,0 +1,190 @@
+// naming.go - Naming templates and policies for new instances on shared hosts
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// namingPolicy is how new instances are named, from .config:
+//
+//	name-template=dev-{user}-{pet}
+//	name-prefix=dev-, ci-
+//	name-max-length=24
+//	name-forbidden=prod, test
+//
+// The template only names instances passgo makes up a name for; the rules
+// apply to every new instance, typed or generated.
+type namingPolicy struct {
+	template  string
+	prefixes  []string // a name must start with one of them
+	maxLength int      // 0 means no limit
+	forbidden []string // words a name must not contain, in any case
+}
+
+// loadNamingPolicy reads the name-* settings through lookup.
+func loadNamingPolicy(lookup func(string) (string, bool)) namingPolicy {
+	var p namingPolicy
+	p.template, _ = lookup("name-template")
+	p.template = strings.TrimSpace(p.template)
+	if v, ok := lookup("name-prefix"); ok {
+		p.prefixes = splitNameList(v)
+	}
+	if v, ok := lookup("name-max-length"); ok {
+		p.maxLength, _ = strconv.Atoi(strings.TrimSpace(v))
+	}
+	if v, ok := lookup("name-forbidden"); ok {
+		p.forbidden = splitNameList(v)
+	}
+	return p
+}
+
+// splitNameList splits a list of prefixes or words on commas and spaces.
+func splitNameList(v string) []string {
+	return strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// check reports why name breaks the policy, or nil.
+func (p namingPolicy) check(name string) error {
+	if !instanceNameRe.MatchString(name) {
+		return fmt.Errorf("invalid instance name %q: use letters, digits and dashes, starting with a letter", name)
+	}
+	if p.maxLength > 0 && len(name) > p.maxLength {
+		return fmt.Errorf("%q is %d characters; the naming policy allows %d", name, len(name), p.maxLength)
+	}
+	if len(p.prefixes) > 0 && !p.hasPrefix(name) {
+		return fmt.Errorf("%q must start with %s (naming policy)", name, strings.Join(p.prefixes, " or "))
+	}
+	lower := strings.ToLower(name)
+	for _, w := range p.forbidden {
+		if strings.Contains(lower, strings.ToLower(w)) {
+			return fmt.Errorf("%q contains %q, which the naming policy forbids", name, w)
+		}
+	}
+	return nil
+}
+
+func (p namingPolicy) hasPrefix(name string) bool {
+	for _, prefix := range p.prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// nameFieldRe matches a placeholder in a name template.
+var nameFieldRe = regexp.MustCompile(`\{(user|pet|rand|date|n)\}`)
+
+// validateNameTemplate checks that name-template gives valid names.
+func validateNameTemplate(v string) error {
+	sample := strings.NewReplacer("{user}", "user", "{pet}", "plucky-wombat", "{rand}", "0a1b", "{date}", "20060102", "{n}", "1").Replace(v)
+	if i := strings.IndexAny(sample, "{}"); i >= 0 {
+		return fmt.Errorf("unknown placeholder in %q; use {user}, {pet}, {rand}, {date} or {n}", v)
+	}
+	if !instanceNameRe.MatchString(sample) {
+		return fmt.Errorf("%q gives names like %q; use letters, digits and dashes, starting with a letter", v, sample)
+	}
+	return nil
+}
+
+// validateNamePrefixes checks that each name-prefix can start a name.
+func validateNamePrefixes(v string) error {
+	for _, prefix := range splitNameList(v) {
+		if !namePrefixRe.MatchString(prefix) {
+			return fmt.Errorf("%q cannot start an instance name", prefix)
+		}
+	}
+	return nil
+}
+
+var namePrefixRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*$`)
+
+// generate makes up a free name. Without a template it takes fallback(),
+// with the first required prefix put in front. A template can use {user},
+// {pet}, {rand}, {date} and {n}, the lowest number giving a free name;
+// without {n}, -2, -3… are added to a name that is taken.
+func (p namingPolicy) generate(fallback func() string, taken func(string) bool) string {
+	if p.template == "" {
+		name := fallback()
+		if len(p.prefixes) > 0 && !p.hasPrefix(name) {
+			name = p.prefixes[0] + name
+		}
+		return name
+	}
+	pet, random := petName(taken), randomString(VMNameRandomLength)
+	expand := func(n int) string {
+		return nameFieldRe.ReplaceAllStringFunc(p.template, func(f string) string {
+			switch f {
+			case "{user}":
+				return nameUser()
+			case "{pet}":
+				return pet
+			case "{rand}":
+				return random
+			case "{date}":
+				return time.Now().Format("20060102")
+			}
+			return strconv.Itoa(n)
+		})
+	}
+	numbered := strings.Contains(p.template, "{n}")
+	base := expand(1)
+	for n := 1; ; n++ {
+		name := base
+		switch {
+		case numbered:
+			name = expand(n)
+		case n > 1:
+			name = fmt.Sprintf("%s-%d", base, n)
+		}
+		if !taken(name) {
+			return name
+		}
+	}
+}
+
+// nameTaken reports whether an instance is listed or being launched as n.
+func (m *rootModel) nameTaken(n string) bool {
+	if _, ok := m.table.vmByName(n); ok {
+		return true
+	}
+	_, launching := m.launching[n]
+	return launching
+}
+
+// namingError explains a generated name the naming policy refuses.
+func (m *rootModel) namingError(err error) tea.Cmd {
+	return m.table.addToastFor("✗ Naming policy: "+err.Error(), "error", 8*time.Second)
+}
+
+// nameUser is the login name, reduced to what an instance name can hold.
+func nameUser() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil && name == "" {
+		name = u.Username
+	}
+	if i := strings.LastIndexAny(name, `\/`); i >= 0 {
+		name = name[i+1:] // DOMAIN\user on Windows
+	}
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '-' || r == '_' || r == '.':
+			b.WriteByte('-')
+		}
+	}
+	if s := strings.Trim(b.String(), "-"); s != "" {
+		return s
+	}
+	return "user"
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNamingPolicyCheck(t *testing.T) {
+	config := map[string]string{"name-prefix": "dev-, ci-", "name-max-length": "16", "name-forbidden": "prod"}
+	p := loadNamingPolicy(func(k string) (string, bool) { v, ok := config[k]; return v, ok })
+	for name, ok := range map[string]bool{
+		"dev-web":           true,
+		"ci-runner-1":       true,
+		"web":               false, // no prefix
+		"dev-a-very-long-1": false, // 17 characters
+		"dev-PROD-copy":     false,
+		"dev-web_1":         false,
+	} {
+		if err := p.check(name); (err == nil) != ok {
+			t.Errorf("check(%q) = %v", name, err)
+		}
+	}
+	if _, err := parseCloneNames("dev-a ci-b web", func(string) bool { return false }, p); err == nil || !strings.Contains(err.Error(), `"web" must start with dev- or ci-`) {
+		t.Errorf("clone names should follow the policy, got %v", err)
+	}
+}
+
+func TestNamingPolicyGenerate(t *testing.T) {
+	t.Setenv("USER", "Jo.Smith")
+	taken := map[string]bool{"dev-jo-smith-1": true, "dev-jo-smith-2": true, "box": true}
+	isTaken := func(n string) bool { return taken[n] }
+
+	if got := (namingPolicy{template: "dev-{user}-{n}"}).generate(nil, isTaken); got != "dev-jo-smith-3" {
+		t.Errorf("numbered template gave %q", got)
+	}
+	if got := (namingPolicy{template: "box"}).generate(nil, isTaken); got != "box-2" {
+		t.Errorf("a taken name without {n} gave %q", got)
+	}
+	if got := (namingPolicy{prefixes: []string{"dev-"}}).generate(func() string { return "plucky-wombat" }, isTaken); got != "dev-plucky-wombat" {
+		t.Errorf("generated names should get the required prefix, got %q", got)
+	}
+
+	for v, ok := range map[string]bool{"dev-{user}-{pet}": true, "vm-{date}-{rand}": true, "{date}-{rand}": false, "dev-{host}": false, "dev_{n}": false} {
+		if err := validateNameTemplate(v); (err == nil) != ok {
+			t.Errorf("validateNameTemplate(%q) = %v", v, err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// netrepair.go - Repairing a VM's networking: netplan and DHCP inside, restart as a fallback
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// repairNetworkScript runs the usual fixes for a VM that lost its address,
+// most often after the host slept: netplan apply, then a DHCP renewal. It
+// exits 0 once the VM has a global address and a default route.
+const repairNetworkScript = `has_net() { ip -4 addr show scope global | grep -q inet && ip -4 route show default | grep -q .; }
+echo "Applying netplan"
+sudo netplan apply || true
+sleep 2
+if has_net; then echo "Network is up"; exit 0; fi
+echo "Renewing DHCP leases"
+for dev in $(ls /sys/class/net); do
+  [ "$dev" = lo ] && continue
+  if command -v dhclient >/dev/null; then
+    sudo dhclient -r "$dev" 2>/dev/null; sudo dhclient "$dev" || true
+  else
+    sudo networkctl renew "$dev" || true
+  fi
+done
+sleep 3
+if has_net; then echo "Network is up"; exit 0; fi
+echo "Still no address or default route"
+exit 1`
+
+// repairExecTimeout bounds the in-VM fixes. multipass exec reaches the VM
+// over its network, so when that is broken it hangs rather than fails.
+const repairExecTimeout = 90 * time.Second
+
+// runRepairScript runs repairNetworkScript in vmName, passing each output
+// line to onLine.
+func runRepairScript(vmName string, onLine func(string)) error {
+	args := []string{"exec", vmName, "--", "bash", "-c", repairNetworkScript}
+	ctx, cancel := context.WithTimeout(context.Background(), repairExecTimeout)
+	defer cancel()
+	if appLogger != nil {
+		appLogger.Printf("%sexec (streaming): multipass exec %s -- bash -c <network repair script>", logPrefix(args), vmName)
+	}
+	lines := &lineWriter{onLine: onLine}
+	err := mpRunner.run(ctx, args, nil, lines, lines)
+	lines.flush()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s did not answer within %s", vmName, repairExecTimeout)
+	}
+	return err
+}
+
+// RepairNetworking runs the in-VM fixes and, if they fail, restarts the
+// VM. restarted reports whether the restart was needed.
+func RepairNetworking(vmName string, onLine func(string)) (restarted bool, err error) {
+	err = runRepairScript(vmName, onLine)
+	if err == nil {
+		return false, nil
+	}
+	if appLogger != nil {
+		appLogger.Printf("network repair in %s failed: %v", vmName, err)
+	}
+	onLine(fmt.Sprintf("In-VM fixes failed (%s); restarting %s", strings.TrimSpace(err.Error()), vmName))
+	if _, err := runMultipassCommand("restart", vmName); err != nil {
+		return true, fmt.Errorf("restart after failed network repair: %w", err)
+	}
+	onLine("Restarted " + vmName)
+	return true, nil
+}
+
+// repairProgressMsg carries one output line of a network repair.
+type repairProgressMsg struct {
+	vmName string
+	line   string
+	events <-chan tea.Msg
+}
+
+// repairNetworkCmd repairs vmName's networking, streaming its output to the
+// table row and finishing with an inline vmOperationResultMsg: operation
+// "repair-network" when the in-VM fixes worked, "restart" when it took a
+// restart.
+func repairNetworkCmd(vmName string) tea.Cmd {
+	return func() tea.Msg {
+		events := make(chan tea.Msg, 16)
+		go func() {
+			defer close(events)
+			op := "repair-network"
+			trace, err := traceOp(op, []string{vmName}, func() error {
+				restarted, err := RepairNetworking(vmName, func(line string) {
+					if line = strings.TrimSpace(line); line != "" {
+						events <- repairProgressMsg{vmName: vmName, line: line, events: events}
+					}
+				})
+				if restarted {
+					op = "restart"
+				}
+				return err
+			})
+			events <- vmOperationResultMsg{vmName: vmName, operation: op, err: err, inline: true, trace: trace}
+		}()
+		return <-events
+	}
+}
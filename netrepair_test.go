@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRepairNetworking(t *testing.T) {
+	f := &fakeRunner{outputs: map[string]string{"exec": "Applying netplan\nNetwork is up\n"}}
+	useFakeRunner(t, f)
+	var lines []string
+	restarted, err := RepairNetworking("web", func(l string) { lines = append(lines, l) })
+	if err != nil || restarted {
+		t.Fatalf("restarted %v, err %v", restarted, err)
+	}
+	if len(f.calls) != 1 || f.calls[0][1] != "web" || !strings.Contains(f.calls[0][len(f.calls[0])-1], "netplan apply") {
+		t.Errorf("calls = %v", f.calls)
+	}
+	if strings.Join(lines, "|") != "Applying netplan|Network is up" {
+		t.Errorf("lines = %q", lines)
+	}
+
+	f = &fakeRunner{fail: map[string]error{"exec": errors.New("exit status 1")}}
+	useFakeRunner(t, f)
+	lines = nil
+	restarted, err = RepairNetworking("web", func(l string) { lines = append(lines, l) })
+	if err != nil || !restarted {
+		t.Fatalf("fallback: restarted %v, err %v", restarted, err)
+	}
+	if last := f.calls[len(f.calls)-1]; last[0] != "restart" || last[1] != "web" {
+		t.Errorf("expected a restart, calls = %v", f.calls)
+	}
+	if !strings.Contains(strings.Join(lines, "\n"), "restarting web") {
+		t.Errorf("the fallback should be announced: %q", lines)
+	}
+
+	f.fail["restart"] = errors.New("exit status 2")
+	if _, err := RepairNetworking("web", func(string) {}); err == nil {
+		t.Error("a failed restart should be reported")
+	}
+}
+
+func TestRepairNetworkCmdStreams(t *testing.T) {
+	useFakeRunner(t, &fakeRunner{outputs: map[string]string{"exec": "Applying netplan\n"}})
+	msg := repairNetworkCmd("web")()
+	progress, ok := msg.(repairProgressMsg)
+	if !ok || progress.line != "Applying netplan" {
+		t.Fatalf("first message = %#v", msg)
+	}
+	res, ok := waitForEventCmd(progress.events)().(vmOperationResultMsg)
+	if !ok || res.operation != "repair-network" || res.err != nil || !res.inline {
+		t.Fatalf("result = %#v", res)
+	}
+}
@@ -0,0 +1,263 @@
+// owners.go - Who launched each VM on a shared host, and guarding their VMs from others
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ownerPolicy is owner tracking, from .config:
+//
+//	owner-dir=/srv/passgo/owners   # shared by everyone using this multipass
+//	owner-only=true                # others' VMs need an override
+//
+// Each user's state file records the VMs they launched; owner-dir is where
+// passgo instances of different users see each other's. It holds a file
+// per VM, named after it and containing the owner's login name. Create it
+// once with the sticky bit (mode 1777), so users cannot overwrite or remove
+// each other's entries.
+type ownerPolicy struct {
+	dir  string // "" when owners are not tracked
+	only bool   // destructive actions on others' VMs need an override
+}
+
+// loadOwnerPolicy reads owner-dir and owner-only through lookup.
+func loadOwnerPolicy(lookup func(string) (string, bool)) ownerPolicy {
+	var p ownerPolicy
+	if v, ok := lookup("owner-dir"); ok {
+		p.dir = strings.TrimSpace(v)
+	}
+	if v, ok := lookup("owner-only"); ok && p.dir != "" {
+		p.only = parseConfigBool(v)
+	}
+	return p
+}
+
+// validateOwnerDir checks that owner-dir is a directory every user can
+// write to without replacing the others' entries.
+func validateOwnerDir(v string) error {
+	info, err := os.Stat(v)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", v)
+	}
+	if runtime.GOOS != "windows" && info.Mode()&(os.ModeSticky|0o002) != os.ModeSticky|0o002 {
+		return fmt.Errorf("%s should be world-writable with the sticky bit: chmod 1777 %s", v, v)
+	}
+	return nil
+}
+
+// ownerColumn is the table column added when owners are tracked.
+var ownerColumn = tableColumn{title: "Owner", width: 10, minWidth: 6, priority: 5}
+
+// currentOwner is the login name of the user running passgo.
+func currentOwner() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
+// record writes owner as the owner of vmName in the shared directory.
+func (p ownerPolicy) record(vmName, owner string) error {
+	if p.dir == "" || owner == "" {
+		return nil
+	}
+	f, err := os.CreateTemp(p.dir, ".owner-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(owner + "\n"); err != nil {
+		f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	_ = os.Chmod(f.Name(), 0o644) // #nosec G302 -- readable by the other users of the host
+	if err := os.Rename(f.Name(), filepath.Join(p.dir, vmName)); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// forget removes the shared entry of a purged VM.
+func (p ownerPolicy) forget(vmName string) {
+	if p.dir == "" {
+		return
+	}
+	if err := os.Remove(filepath.Join(p.dir, vmName)); err != nil && !os.IsNotExist(err) && appLogger != nil {
+		appLogger.Printf("owner: could not remove the entry of %s: %v", vmName, err)
+	}
+}
+
+// read returns the owners recorded in the shared directory, by VM name.
+func (p ownerPolicy) read() map[string]string {
+	owners := make(map[string]string)
+	if p.dir == "" {
+		return owners
+	}
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return owners
+	}
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(p.dir, e.Name())) // #nosec G304 -- entry of the configured owner-dir
+		if err == nil && len(data) > 0 {
+			owners[e.Name()] = firstLine(strings.TrimSpace(string(data)))
+		}
+	}
+	return owners
+}
+
+// setOwner records owner as the user who launched vmName.
+func (s appState) setOwner(vmName, owner string) {
+	meta := s.VMs[vmName]
+	meta.Owner = owner
+	s.VMs[vmName] = meta
+}
+
+// recordOwner notes the current user as the owner of a VM just launched
+// from the TUI.
+func (m *rootModel) recordOwner(vmName string) {
+	owner := currentOwner()
+	if m.state.VMs != nil {
+		m.state.setOwner(vmName, owner)
+	}
+	if err := m.owners.record(vmName, owner); err != nil && appLogger != nil {
+		appLogger.Printf("owner: could not record %s as the owner of %s: %v", owner, vmName, err)
+	}
+}
+
+// recordLaunchOwner notes the current user as the owner of a VM launched
+// outside the TUI.
+func recordLaunchOwner(vmName string) error {
+	owner := currentOwner()
+	path, err := stateFilePath()
+	if err != nil {
+		return err
+	}
+	if _, err := updateState(path, func(st *appState) bool {
+		st.setOwner(vmName, owner)
+		return true
+	}); err != nil {
+		return err
+	}
+	return loadOwnerPolicy(configValue).record(vmName, owner)
+}
+
+// refreshOwners gives the table the owner of each VM in vms: the shared
+// entry, or else what this user's state file says. This user's entries for
+// VMs that are gone, e.g. purged with !, are removed.
+func (m *rootModel) refreshOwners(vms []vmData) {
+	if m.owners.dir == "" {
+		return
+	}
+	owners := m.owners.read()
+	listed := make(map[string]bool, len(vms))
+	for _, vm := range vms {
+		listed[vm.info.Name] = true
+	}
+	me := currentOwner()
+	for name, owner := range owners {
+		_, busy := m.table.busyVMs[name]
+		if _, launching := m.launching[name]; !listed[name] && !busy && !launching && owner == me {
+			m.owners.forget(name)
+			delete(owners, name)
+		}
+	}
+	m.table.owners = mergeOwners(owners, m.state.VMs)
+}
+
+// mergeOwners adds to the shared entries the owners recorded in this user's
+// state file for VMs the shared directory does not know.
+func mergeOwners(shared map[string]string, meta map[string]vmMeta) map[string]string {
+	for name, mt := range meta {
+		if _, ok := shared[name]; !ok && mt.Owner != "" {
+			shared[name] = mt.Owner
+		}
+	}
+	return shared
+}
+
+// othersVM returns the owner of vmName when owner-only applies to it: the
+// VM belongs to someone else. VMs without a known owner are anyone's.
+func (m *rootModel) othersVM(vmName string) (string, bool) {
+	if !m.owners.only {
+		return "", false
+	}
+	owner := m.table.owners[vmName]
+	return owner, owner != "" && owner != currentOwner()
+}
+
+// othersVMs lists the VMs among names that belong to someone else.
+func (m *rootModel) othersVMs(names []string) []string {
+	var others []string
+	for _, name := range names {
+		if owner, ok := m.othersVM(name); ok {
+			others = append(others, name+" ("+owner+")")
+		}
+	}
+	return others
+}
+
+// overrideOwner asks for the typed override before action runs cmd on VMs
+// of other users: the owner's name for a single VM, "override" for several.
+// It always asks, whatever the confirm level; row is the busy row shown
+// once it is accepted, if any, and a cancelled dialog returns to returnView.
+func (m *rootModel) overrideOwner(question, token string, others []string, cmd tea.Cmd, row pendingRowOp, returnView viewState) tea.Cmd {
+	m.pendingCmd = cmd
+	m.pendingRow = row
+	m.confirmReturnView = returnView
+	details := append([]string{"owner-only is set: type " + token + " to act on another user's VM"}, others...)
+	m.confirm = newTypedConfirmModel(question, token, details)
+	m.setChildSizes()
+	m.currentView = viewConfirm
+	return m.confirm.Init()
+}
+
+// guardOwner runs a destructive single-VM action through overrideOwner
+// when vmName belongs to someone else, reporting whether it did.
+func (m *rootModel) guardOwner(vmName, action string, cmd tea.Cmd, row pendingRowOp, returnView viewState) (tea.Cmd, bool) {
+	owner, ok := m.othersVM(vmName)
+	if !ok {
+		return nil, false
+	}
+	return m.overrideOwner(fmt.Sprintf("%s belongs to %s. %s it anyway?", vmName, owner, action), owner, nil, cmd, row, returnView), true
+}
+
+// sortByOwner orders the table by owner, VMs without one last.
+func (m *tableModel) sortByOwner() {
+	sort.SliceStable(m.filteredVMs, func(i, j int) bool {
+		a, b := m.owners[m.filteredVMs[i].info.Name], m.owners[m.filteredVMs[j].info.Name]
+		if (a == "") != (b == "") {
+			return b == ""
+		}
+		cmp := compareStringsFold(a, b)
+		if cmp == 0 {
+			cmp = compareStringsFold(m.filteredVMs[i].info.Name, m.filteredVMs[j].info.Name)
+		}
+		if m.sortAscending {
+			return cmp < 0
+		}
+		return cmp > 0
+	})
+}
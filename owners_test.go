@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestOwnerDir(t *testing.T) {
+	p := ownerPolicy{dir: t.TempDir()}
+	if err := p.record("web", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.record("db", "bob"); err != nil {
+		t.Fatal(err)
+	}
+	p.forget("db")
+	if got := p.read(); len(got) != 1 || got["web"] != "alice" {
+		t.Errorf("owners = %v", got)
+	}
+	if err := validateOwnerDir(p.dir); err == nil {
+		t.Error("a private directory should not pass as owner-dir")
+	}
+	if err := os.Chmod(p.dir, 0o777|os.ModeSticky); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateOwnerDir(p.dir); err != nil {
+		t.Errorf("mode 1777: %v", err)
+	}
+}
+
+func TestOwnerOnlyNeedsOverride(t *testing.T) {
+	m := rootModel{table: newTableModel(), confirmLevel: confirmNone, owners: ownerPolicy{dir: t.TempDir(), only: true}}
+	m.table.vms = []vmData{{info: VMInfo{Name: "web", State: "Running"}}, {info: VMInfo{Name: "mine", State: "Running"}}}
+	m.table.owners = map[string]string{"web": "someone-else", "mine": currentOwner()}
+	m.table.applyFilterAndSort()
+
+	m.table.selectVMByName("mine")
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("[")})
+	if m := model.(rootModel); m.currentView == viewConfirm || cmd == nil || m.table.busyVMs["mine"].operation != "Stopping" {
+		t.Fatal("your own VM should stop without asking")
+	}
+
+	m.table.selectVMByName("web")
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("[")})
+	m = model.(rootModel)
+	if m.currentView != viewConfirm || m.confirm.token != "someone-else" || m.pendingRow.vmName != "web" {
+		t.Fatalf("stopping another user's VM should ask for the owner's name, got view %v token %q", m.currentView, m.confirm.token)
+	}
+	if _, busy := m.table.busyVMs["web"]; busy {
+		t.Error("nothing should run before the override")
+	}
+}
+
+func TestRefreshOwnersPrunesGoneVMs(t *testing.T) {
+	m := rootModel{table: newTableModel(), state: newAppState(), owners: ownerPolicy{dir: t.TempDir()}}
+	for name, owner := range map[string]string{"web": currentOwner(), "gone": currentOwner(), "theirs": "someone-else"} {
+		if err := m.owners.record(name, owner); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m.refreshOwners([]vmData{{info: VMInfo{Name: "web"}}})
+	got := m.owners.read()
+	if _, ok := got["gone"]; ok || got["web"] == "" || got["theirs"] == "" {
+		t.Errorf("only your entries of VMs that are gone should be removed: %v", got)
+	}
+}
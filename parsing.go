@@ -1,7 +1,14 @@
 // parsing.go - Data structures and parsing functions for VM and snapshot information
 package main
 
-import "strings"
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // VMInfo represents information about a virtual machine
 type VMInfo struct {
@@ -23,6 +30,7 @@ type SnapshotInfo struct {
 	Name     string
 	Parent   string
 	Comment  string
+	Created  time.Time // zero when only `list --snapshots` was available
 }
 
 // parseVMInfo parses VM info output from multipass info command
@@ -134,3 +142,287 @@ func parseSnapshotLine(line string) (SnapshotInfo, bool) {
 
 	return snapshot, true
 }
+
+// jsonFlexNumber accepts both quoted ("2") and bare (2) numbers, since
+// multipass info --format json mixes the two.
+type jsonFlexNumber string
+
+func (n *jsonFlexNumber) UnmarshalJSON(data []byte) error {
+	raw := strings.Trim(strings.TrimSpace(string(data)), `"`)
+	if raw == "null" {
+		raw = ""
+	}
+	*n = jsonFlexNumber(raw)
+	return nil
+}
+
+// float returns the numeric value, or false if empty or malformed.
+func (n jsonFlexNumber) float() (float64, bool) {
+	if n == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(string(n), 64)
+	return v, err == nil
+}
+
+// parseVMInfoListJSON parses `multipass info --all --format json` into one
+// VMInfo per instance, sorted by name. Field values are formatted like the
+// plain-text info output so existing table parsing keeps working.
+func parseVMInfoListJSON(output string) ([]VMInfo, error) {
+	var response multipassInfoResponse
+	if err := json.Unmarshal([]byte(output), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse VM info JSON: %w", err)
+	}
+
+	vms := make([]VMInfo, 0, len(response.Info))
+	for name, detail := range response.Info {
+		vms = append(vms, vmInfoFromJSON(name, detail))
+	}
+	sort.Slice(vms, func(i, j int) bool {
+		return vms[i].Name < vms[j].Name
+	})
+	return vms, nil
+}
+
+// vmInfoFromJSON converts one instance entry from the JSON info response.
+func vmInfoFromJSON(name string, d multipassVMInfoDetail) VMInfo {
+	vm := VMInfo{
+		Name:      name,
+		State:     d.State,
+		Snapshots: orDashes(string(d.SnapshotCount)),
+		IPv4:      "--",
+		Release:   orDashes(d.Release),
+		CPUs:      orDashes(string(d.CPUCount)),
+		Load:      "--",
+		DiskUsage: "--",
+		Mounts:    "--",
+	}
+	if len(d.IPv4) > 0 {
+		vm.IPv4 = d.IPv4[0]
+	}
+	if len(d.Load) > 0 {
+		parts := make([]string, len(d.Load))
+		for i, l := range d.Load {
+			parts[i] = fmt.Sprintf("%.2f", l)
+		}
+		vm.Load = strings.Join(parts, " ")
+	}
+
+	var diskUsed, diskTotal float64
+	for _, disk := range d.Disks {
+		used, okUsed := disk.Used.float()
+		total, okTotal := disk.Total.float()
+		if okUsed && okTotal {
+			diskUsed += used
+			diskTotal += total
+		}
+	}
+	if diskTotal > 0 {
+		vm.DiskUsage = formatBytesIEC(diskUsed) + " out of " + formatBytesIEC(diskTotal)
+	}
+	vm.MemoryUsage = "--"
+	if used, ok := d.Memory.Used.float(); ok {
+		if total, ok := d.Memory.Total.float(); ok && total > 0 {
+			vm.MemoryUsage = formatBytesIEC(used) + " out of " + formatBytesIEC(total)
+		}
+	}
+
+	if len(d.Mounts) > 0 {
+		targets := make([]string, 0, len(d.Mounts))
+		for target := range d.Mounts {
+			targets = append(targets, target)
+		}
+		sort.Strings(targets)
+		var mounts []string
+		for _, target := range targets {
+			mounts = append(mounts, d.Mounts[target].SourcePath+" => "+target)
+		}
+		vm.Mounts = strings.Join(mounts, ", ")
+	}
+	return vm
+}
+
+// formatBytesIEC renders a byte count the way multipass does, e.g. "1.8GiB".
+func formatBytesIEC(bytes float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	i := 0
+	for bytes >= 1024 && i < len(units)-1 {
+		bytes /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f%s", bytes, units[i])
+	}
+	return fmt.Sprintf("%.1f%s", bytes, units[i])
+}
+
+// orDashes substitutes multipass's "--" placeholder for empty values.
+func orDashes(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "--"
+	}
+	return s
+}
+
+// launchPhase is a step of `multipass launch` parsed from its progress output.
+type launchPhase struct {
+	Step    int    // index into launchPhaseLabels
+	Label   string // human-readable phase name
+	Percent int    // download percentage, or -1 when not reported
+}
+
+// launchPhaseLabels lists the launch phases in the order multipass reports them.
+var launchPhaseLabels = []string{
+	"Retrieving image",
+	"Preparing image",
+	"Configuring",
+	"Starting",
+	"Waiting for init",
+}
+
+// parseLaunchPhase maps a line of launch output to a phase. Lines that don't
+// describe a known phase (blank lines, spinner glyphs) return false.
+func parseLaunchPhase(line string) (launchPhase, bool) {
+	lower := strings.ToLower(strings.TrimSpace(line))
+	step := -1
+	switch {
+	case strings.Contains(lower, "retrieving image"), strings.Contains(lower, "downloading"):
+		step = 0
+	case strings.Contains(lower, "preparing"), strings.Contains(lower, "verifying"),
+		strings.Contains(lower, "extracting"):
+		step = 1
+	case strings.Contains(lower, "configuring"):
+		step = 2
+	case strings.Contains(lower, "starting"):
+		step = 3
+	case strings.Contains(lower, "waiting for initialization"):
+		step = 4
+	default:
+		return launchPhase{}, false
+	}
+
+	phase := launchPhase{Step: step, Label: launchPhaseLabels[step], Percent: -1}
+	if idx := strings.LastIndex(lower, "%"); idx > 0 {
+		start := idx
+		for start > 0 && lower[start-1] >= '0' && lower[start-1] <= '9' {
+			start--
+		}
+		if pct, err := strconv.Atoi(lower[start:idx]); err == nil && pct >= 0 && pct <= 100 {
+			phase.Percent = pct
+		}
+	}
+	return phase, true
+}
+
+// VMInterface is a guest network interface as reported by `ip -j addr`.
+type VMInterface struct {
+	Name string
+	MAC  string
+	IPv4 []string
+}
+
+type ipAddrEntry struct {
+	IfName   string `json:"ifname"`
+	Address  string `json:"address"`
+	AddrInfo []struct {
+		Family string `json:"family"`
+		Local  string `json:"local"`
+	} `json:"addr_info"`
+}
+
+// parseIPAddrJSON parses `ip -j addr show` output, skipping the loopback.
+func parseIPAddrJSON(output string) ([]VMInterface, error) {
+	var entries []ipAddrEntry
+	if err := json.Unmarshal([]byte(output), &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse interface JSON: %w", err)
+	}
+	var ifaces []VMInterface
+	for _, e := range entries {
+		if e.IfName == "lo" {
+			continue
+		}
+		iface := VMInterface{Name: e.IfName, MAC: e.Address}
+		for _, a := range e.AddrInfo {
+			if a.Family == "inet" {
+				iface.IPv4 = append(iface.IPv4, a.Local)
+			}
+		}
+		ifaces = append(ifaces, iface)
+	}
+	return ifaces, nil
+}
+
+// parseTTL parses a lifetime such as "30m", "4h" or "2d" (days are not
+// supported by time.ParseDuration). An empty string means no TTL.
+func parseTTL(s string) (time.Duration, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid TTL %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid TTL %q (use e.g. 30m, 4h, 2d)", s)
+	}
+	return d, nil
+}
+
+// sizeUnitsMB are the multipliers of the size suffixes, in MiB. Sizes are
+// binary whatever the suffix says: 1G is 1024M, as multipass takes it.
+var sizeUnitsMB = map[string]float64{"k": 1.0 / 1024, "m": 1, "g": 1024, "t": 1024 * 1024}
+
+// parseSizeMB parses a size typed by the user, such as "8G", "512M",
+// "1.5T" or "2GiB", into whole MiB. A bare number is in bareUnitMB units
+// (1 for memory, 1024 for disk).
+func parseSizeMB(s string, bareUnitMB int) (int, error) {
+	t := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), " ", ""))
+	t = strings.TrimSuffix(strings.TrimSuffix(t, "ib"), "b")
+	mult := float64(bareUnitMB)
+	if n := len(t); n > 0 {
+		if u, ok := sizeUnitsMB[t[n-1:]]; ok {
+			t, mult = t[:n-1], u
+		}
+	}
+	v, err := strconv.ParseFloat(t, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 512M, 8G, 1.5T)", s)
+	}
+	return int(v*mult + 0.5), nil
+}
+
+// formatSizeMB renders MiB the way parseSizeMB reads it back: "512M",
+// "2G", "1.5G", "1T".
+func formatSizeMB(mb int) string {
+	switch {
+	case mb >= 1024*1024 && mb%(1024*1024) == 0:
+		return fmt.Sprintf("%dT", mb/(1024*1024))
+	case mb >= 1024 && mb%1024 == 0:
+		return fmt.Sprintf("%dG", mb/1024)
+	case mb >= 1024 && mb*10%1024 == 0:
+		return strconv.FormatFloat(float64(mb)/1024, 'f', 1, 64) + "G"
+	}
+	return fmt.Sprintf("%dM", mb)
+}
+
+// formatRemaining renders a countdown compactly, e.g. "2d3h", "3h12m", "45m", "30s".
+func formatRemaining(d time.Duration) string {
+	if d <= 0 {
+		return "0s"
+	}
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+}
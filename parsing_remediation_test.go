@@ -1,6 +1,11 @@
 package main
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
 
 func TestParseSnapshotsPreservesMultiWordComments(t *testing.T) {
 	input := `Instance    Snapshot    Parent    Comment
@@ -29,3 +34,164 @@ func TestParseSnapshotLineRequiresMinimumFields(t *testing.T) {
 		t.Fatalf("expected malformed line to fail parsing")
 	}
 }
+
+func TestParseVMInfoListJSON(t *testing.T) {
+	input := `{
+  "errors": [],
+  "info": {
+    "vm2": {"state": "Stopped", "snapshot_count": "0", "ipv4": [], "load": [], "disks": {}, "memory": {}, "cpu_count": ""},
+    "vm1": {
+      "state": "Running",
+      "snapshot_count": "2",
+      "ipv4": ["10.0.0.5", "172.17.0.1"],
+      "release": "Ubuntu 24.04.1 LTS",
+      "cpu_count": "2",
+      "load": [0.5, 0.25, 0.1],
+      "disks": {"sda1": {"total": "5368709120", "used": "1073741824"}},
+      "memory": {"total": 2147483648, "used": 536870912},
+      "mounts": {"/home/ubuntu/src": {"source_path": "/src"}}
+    }
+  }
+}`
+
+	got, err := parseVMInfoListJSON(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "vm1" || got[1].Name != "vm2" {
+		t.Fatalf("expected vm1, vm2 sorted by name, got %+v", got)
+	}
+
+	vm1 := got[0]
+	if vm1.IPv4 != "10.0.0.5" || vm1.CPUs != "2" || vm1.Snapshots != "2" {
+		t.Fatalf("unexpected scalar fields: %+v", vm1)
+	}
+	if vm1.DiskUsage != "1.0GiB out of 5.0GiB" {
+		t.Fatalf("unexpected disk usage: %q", vm1.DiskUsage)
+	}
+	if vm1.MemoryUsage != "512.0MiB out of 2.0GiB" {
+		t.Fatalf("unexpected memory usage: %q", vm1.MemoryUsage)
+	}
+	if frac, ok := parseCPULoadFraction(vm1.Load, vm1.CPUs); !ok || frac != 0.25 {
+		t.Fatalf("expected load to round-trip through table parsing, got %v %v", frac, ok)
+	}
+
+	vm2 := got[1]
+	if vm2.IPv4 != "--" || vm2.DiskUsage != "--" || vm2.MemoryUsage != "--" || vm2.CPUs != "--" {
+		t.Fatalf("expected placeholders for stopped VM, got %+v", vm2)
+	}
+}
+
+func TestParseLaunchPhase(t *testing.T) {
+	tests := []struct {
+		line    string
+		step    int
+		percent int
+		ok      bool
+	}{
+		{"Retrieving image: 45%", 0, 45, true},
+		{"Preparing image for vm1", 1, -1, true},
+		{"Configuring vm1", 2, -1, true},
+		{"Starting vm1", 3, -1, true},
+		{"Waiting for initialization to complete", 4, -1, true},
+		{"Launched: vm1", 0, 0, false},
+		{"⠋", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseLaunchPhase(tt.line)
+		if ok != tt.ok {
+			t.Fatalf("%q: ok=%v, want %v", tt.line, ok, tt.ok)
+		}
+		if !ok {
+			continue
+		}
+		if got.Step != tt.step || got.Percent != tt.percent {
+			t.Fatalf("%q: got step %d percent %d, want %d %d", tt.line, got.Step, got.Percent, tt.step, tt.percent)
+		}
+	}
+}
+
+func TestParseIPAddrJSON(t *testing.T) {
+	output := `[
+ {"ifname":"lo","address":"00:00:00:00:00:00","addr_info":[{"family":"inet","local":"127.0.0.1"}]},
+ {"ifname":"ens3","address":"52:54:00:aa:bb:cc","addr_info":[{"family":"inet","local":"10.0.0.5"},{"family":"inet6","local":"fe80::1"}]},
+ {"ifname":"ens4","address":"52:54:00:dd:ee:ff","addr_info":[]}
+]`
+	ifaces, err := parseIPAddrJSON(output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ifaces) != 2 {
+		t.Fatalf("expected loopback to be skipped, got %d interfaces", len(ifaces))
+	}
+	if ifaces[0].Name != "ens3" || ifaces[0].MAC != "52:54:00:aa:bb:cc" || len(ifaces[0].IPv4) != 1 || ifaces[0].IPv4[0] != "10.0.0.5" {
+		t.Fatalf("unexpected first interface: %+v", ifaces[0])
+	}
+	if len(ifaces[1].IPv4) != 0 {
+		t.Fatalf("expected no IPv4 on ens4, got %v", ifaces[1].IPv4)
+	}
+
+	if _, err := parseIPAddrJSON("not json"); err == nil {
+		t.Fatal("expected error for invalid JSON")
+	}
+}
+
+func TestParseTTL(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":    0,
+		"30m": 30 * time.Minute,
+		"4h":  4 * time.Hour,
+		"2d":  48 * time.Hour,
+	}
+	for in, want := range tests {
+		got, err := parseTTL(in)
+		if err != nil || got != want {
+			t.Errorf("parseTTL(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"soon", "-1h", "0d", "xd"} {
+		if _, err := parseTTL(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+	if got := formatRemaining(3*time.Hour + 12*time.Minute); got != "3h12m" {
+		t.Errorf("formatRemaining = %q", got)
+	}
+}
+
+func TestParseSizeMB(t *testing.T) {
+	for in, want := range map[string]int{
+		"8G":    8192,
+		"8 GiB": 8192,
+		"512M":  512,
+		"512mb": 512,
+		"1.5T":  1536 * 1024,
+		"1.5g":  1536,
+		"2048":  2048,
+	} {
+		if got, err := parseSizeMB(in, 1); err != nil || got != want {
+			t.Errorf("parseSizeMB(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	if got, _ := parseSizeMB("20", 1024); got != 20*1024 {
+		t.Errorf("a bare disk size should be GB, got %dM", got)
+	}
+	for _, bad := range []string{"", "big", "8X", "-2G", "0", "8i"} {
+		if _, err := parseSizeMB(bad, 1); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+	for mb, want := range map[int]string{512: "512M", 2048: "2G", 1536: "1.5G", 1024 * 1024: "1T", 1000: "1000M"} {
+		if got := formatSizeMB(mb); got != want {
+			t.Errorf("formatSizeMB(%d) = %q, want %q", mb, got, want)
+		}
+	}
+
+	f := advField{label: "Disk", input: textinput.New(), isNumeric: true, bareUnitMB: 1024}
+	f.input.SetValue("10G")
+	f.snap([]int{8 << 10, 12 << 10, 16 << 10}, snapNext)
+	if f.input.Value() != "12G" {
+		t.Errorf("snapping 10G up gave %q", f.input.Value())
+	}
+}
@@ -0,0 +1,101 @@
+// paste.go - Bracketed paste into the form text inputs
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pasteKind says how a field takes pasted text. Left to itself textinput
+// turns newlines into spaces, so "cd src\nmake" would become "cd src make",
+// and cuts text at the field's limit without a word.
+type pasteKind int
+
+const (
+	pasteLine    pasteKind = iota // a single line; more are refused
+	pastePath                     // a single path or URL, without surrounding quotes
+	pasteCommand                  // shell lines, joined into one command
+	pasteWords                    // a list separated by spaces, e.g. NAME=VALUE pairs from a .env file
+)
+
+// cleanPaste turns pasted text into what goes in a field of kind, or
+// explains why it cannot.
+func cleanPaste(text string, kind pasteKind) (string, error) {
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	text = strings.TrimSpace(text)
+	lines := strings.Split(text, "\n")
+	switch kind {
+	case pastePath:
+		if len(lines) > 1 {
+			return "", errors.New("paste a single path or URL")
+		}
+		if n := len(text); n >= 2 && (text[0] == '"' || text[0] == '\'') && text[n-1] == text[0] {
+			text = strings.TrimSpace(text[1 : n-1])
+		}
+		return text, nil
+	case pasteCommand:
+		return joinCommandLines(lines)
+	case pasteWords:
+		var words []string
+		for _, line := range lines {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				words = append(words, strings.Fields(strings.TrimPrefix(line, "export "))...)
+			}
+		}
+		return strings.Join(words, " "), nil
+	}
+	if len(lines) > 1 {
+		return "", errors.New("paste a single line")
+	}
+	return text, nil
+}
+
+// joinCommandLines joins shell lines with "; ", or a space after a
+// backslash continuation or a trailing &&, || or |. Blank lines and a
+// leading "$ " prompt are dropped.
+// Heredocs cannot survive the join and are refused.
+func joinCommandLines(lines []string) (string, error) {
+	var b strings.Builder
+	continued := false
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(lines) > 1 && strings.Contains(strings.ReplaceAll(line, "<<<", ""), "<<") {
+			return "", errors.New("a pasted heredoc cannot run as one line; put it in a script in the VM")
+		}
+		line = strings.TrimLeft(line, " \t")
+		switch {
+		case continued || strings.HasSuffix(b.String(), "&&") || strings.HasSuffix(b.String(), "|"):
+			b.WriteString(" ")
+		case b.Len() > 0:
+			b.WriteString("; ")
+		}
+		if !continued {
+			line = strings.TrimPrefix(line, "$ ")
+		}
+		line, continued = strings.CutSuffix(line, `\`)
+		b.WriteString(strings.TrimRight(line, " \t"))
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// pasteInto inserts the bracketed paste msg into in at the cursor, cleaned
+// for kind. errMsg explains a refused paste, which leaves in as it was.
+func pasteInto(in *textinput.Model, msg tea.KeyMsg, kind pasteKind) (cmd tea.Cmd, errMsg string) {
+	text, err := cleanPaste(string(msg.Runes), kind)
+	if err != nil {
+		return nil, "Paste refused: " + err.Error()
+	}
+	if n := utf8.RuneCountInString(text); in.CharLimit > 0 && utf8.RuneCountInString(in.Value())+n > in.CharLimit {
+		return nil, fmt.Sprintf("Paste refused: %d characters do not fit; the field takes %d", n, in.CharLimit)
+	}
+	*in, cmd = in.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text), Paste: true})
+	return cmd, ""
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCleanPaste(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		kind pasteKind
+		want string // "" with err for a refusal
+		err  bool
+	}{
+		{"  ubuntu-dev \n", pasteLine, "ubuntu-dev", false},
+		{"web\ndb", pasteLine, "", true},
+		{"'/home/me/My Templates/web.yaml'\r\n", pastePath, "/home/me/My Templates/web.yaml", false},
+		{`"https://example.com/noble.img"`, pastePath, "https://example.com/noble.img", false},
+		{"a.yaml\nb.yaml", pastePath, "", true},
+		{"$ cd src\r\n\r\nmake test\n", pasteCommand, "cd src; make test", false},
+		{"apt-get update &&\n  apt-get install -y git", pasteCommand, "apt-get update && apt-get install -y git", false},
+		{"docker run \\\n  --rm \\\n  alpine", pasteCommand, "docker run --rm alpine", false},
+		{"ps aux |\ngrep nginx", pasteCommand, "ps aux | grep nginx", false},
+		{"cat <<EOF > x\nhi\nEOF", pasteCommand, "", true},
+		{"grep x <<< \"$y\"", pasteCommand, `grep x <<< "$y"`, false},
+		{"# app\nexport A=1\n\nB=2 C=3\n", pasteWords, "A=1 B=2 C=3", false},
+	} {
+		got, err := cleanPaste(tc.text, tc.kind)
+		if (err != nil) != tc.err || got != tc.want {
+			t.Errorf("cleanPaste(%q, %d) = %q, %v", tc.text, tc.kind, got, err)
+		}
+	}
+}
+
+func TestExecPasteDoesNotRun(t *testing.T) {
+	m := newExecModel(VMInfo{Name: "web"}, nil, 100, 40)
+	m.cursor = execFieldCommand
+	m.syncFocus()
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("cd /srv\nls -la\n"), Paste: true})
+	if m.running || m.ran != "" {
+		t.Fatal("a paste ending in a newline must not run the command")
+	}
+	if cmd != nil {
+		if _, ok := cmd().(execResultMsg); ok {
+			t.Fatal("a paste must not start the command")
+		}
+	}
+	if got := m.commandInput.Value(); got != "cd /srv; ls -la" {
+		t.Errorf("command = %q", got)
+	}
+
+	m.commandInput.CharLimit = 10
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" && make"), Paste: true})
+	if m.commandInput.Value() != "cd /srv; ls -la" || !strings.HasPrefix(m.errMsg, "Paste refused") {
+		t.Errorf("an oversized paste should be refused whole: %q, %q", m.commandInput.Value(), m.errMsg)
+	}
+}
@@ -0,0 +1,45 @@
+// petname.go - Multipass-style adjective-animal names for quick launches
+package main
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+var petAdjectives = []string{
+	"agile", "amiable", "brave", "bright", "calm", "careful", "cheerful", "clever",
+	"cosmic", "daring", "eager", "fair", "fearless", "gentle", "glad", "golden",
+	"happy", "honest", "jolly", "keen", "kind", "lively", "lucky", "mellow",
+	"merry", "nimble", "noble", "patient", "plucky", "polite", "proud", "quick",
+	"quiet", "rapid", "serene", "shiny", "smart", "steady", "sturdy", "sunny",
+	"swift", "tidy", "tranquil", "trusty", "vivid", "warm", "willing", "witty",
+}
+
+var petAnimals = []string{
+	"albatross", "badger", "beaver", "bison", "bobcat", "caribou", "cheetah", "coyote",
+	"dingo", "dolphin", "eagle", "ferret", "finch", "gazelle", "gecko", "heron",
+	"ibex", "impala", "jackal", "kestrel", "koala", "lemur", "lynx", "marmot",
+	"meerkat", "mongoose", "narwhal", "ocelot", "osprey", "otter", "panda", "pelican",
+	"puffin", "quokka", "raven", "salmon", "seal", "sparrow", "tapir", "tern",
+	"toucan", "turtle", "vole", "wallaby", "walrus", "weasel", "wombat", "yak",
+}
+
+func randomIndex(n int) int {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0
+	}
+	return int(i.Int64())
+}
+
+// petName returns a name like "plucky-wombat" that taken reports as free,
+// falling back to a random suffix if every attempt collides.
+func petName(taken func(string) bool) string {
+	for range 20 {
+		name := petAdjectives[randomIndex(len(petAdjectives))] + "-" + petAnimals[randomIndex(len(petAnimals))]
+		if !taken(name) {
+			return name
+		}
+	}
+	return petAdjectives[randomIndex(len(petAdjectives))] + "-" + randomString(VMNameRandomLength)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestPetName(t *testing.T) {
+	re := regexp.MustCompile(`^[a-z]+-[a-z0-9]+$`)
+	seen := map[string]bool{}
+	for range 50 {
+		name := petName(func(n string) bool { return seen[n] })
+		if !re.MatchString(name) {
+			t.Fatalf("unexpected pet name %q", name)
+		}
+		if seen[name] {
+			t.Fatalf("petName returned taken name %q", name)
+		}
+		seen[name] = true
+	}
+
+	// Every adjective-animal pair taken: falls back to a random suffix.
+	name := petName(func(n string) bool { return true })
+	if !re.MatchString(name) {
+		t.Fatalf("unexpected fallback name %q", name)
+	}
+}
@@ -0,0 +1,296 @@
+// power.go - Working-hours schedules: start VMs in the morning, stop them at night
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// powerSchedule starts and/or stops a VM at fixed local times on some days
+// of the week. Configured in .config, one line per VM (or several):
+//
+//	power: {vm: dev, days: weekdays, start: 09:00, stop: 19:00}
+//	power: {vm: ci, stop: 23:30}
+//
+// Only the moments themselves count: a VM stopped by hand at noon is not
+// started again until the next start time.
+type powerSchedule struct {
+	vm    string
+	days  [7]bool // by time.Weekday; every day unless days is given
+	start clock   // zero: no scheduled start
+	stop  clock
+}
+
+// clock is a time of day; the zero value means unset.
+type clock struct {
+	set          bool
+	hour, minute int
+}
+
+func (c clock) String() string { return fmt.Sprintf("%02d:%02d", c.hour, c.minute) }
+
+// powerCheckInterval is how often schedules are evaluated in the TUI.
+const powerCheckInterval = 30 * time.Second
+
+// dayNames are the accepted day abbreviations, by time.Weekday.
+var dayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// parsePowerSchedule parses "{vm: dev, days: mon-fri, start: 09:00, stop: 19:00}".
+func parsePowerSchedule(s string) (powerSchedule, error) {
+	var sched powerSchedule
+	for i := range sched.days {
+		sched.days[i] = true
+	}
+	body := strings.TrimSpace(s)
+	body = strings.TrimSuffix(strings.TrimPrefix(body, "{"), "}")
+	for _, part := range strings.Split(body, ",") {
+		key, val, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		var err error
+		switch key {
+		case "vm":
+			sched.vm = val
+		case "days":
+			sched.days, err = parseDays(val)
+		case "start":
+			sched.start, err = parseClock(val)
+		case "stop":
+			sched.stop, err = parseClock(val)
+		default:
+			err = fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return sched, fmt.Errorf("power %s: %w", s, err)
+		}
+	}
+	switch {
+	case sched.vm == "":
+		return sched, fmt.Errorf("power %s: vm is required", s)
+	case !sched.start.set && !sched.stop.set:
+		return sched, fmt.Errorf("power %s: give start, stop or both", s)
+	case sched.start.set && sched.start == sched.stop:
+		return sched, fmt.Errorf("power %s: start and stop are both %s", s, sched.start)
+	}
+	return sched, nil
+}
+
+// parseClock parses a 24-hour time such as "09:00" or "19:30".
+func parseClock(s string) (clock, error) {
+	h, m, ok := strings.Cut(s, ":")
+	hour, err1 := strconv.Atoi(h)
+	minute, err2 := strconv.Atoi(m)
+	if !ok || err1 != nil || err2 != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return clock{}, fmt.Errorf("invalid time %q (use HH:MM, e.g. 09:00)", s)
+	}
+	return clock{set: true, hour: hour, minute: minute}, nil
+}
+
+// parseDays parses "weekdays", "weekends", "daily", or day names and
+// ranges separated by spaces, such as "mon-fri" or "mon wed fri".
+func parseDays(s string) ([7]bool, error) {
+	var days [7]bool
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) == 0 {
+		return days, fmt.Errorf("days is empty")
+	}
+	for _, f := range fields {
+		switch f {
+		case "daily":
+			f = "sun-sat"
+		case "weekdays":
+			f = "mon-fri"
+		case "weekends":
+			f = "sat-sun"
+		}
+		from, to, isRange := strings.Cut(f, "-")
+		if !isRange {
+			to = from
+		}
+		a, b := dayIndex(from), dayIndex(to)
+		if a < 0 || b < 0 {
+			return days, fmt.Errorf("invalid days %q (use weekdays, weekends, daily, or e.g. mon-fri)", s)
+		}
+		for d := a; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == b {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// dayIndex returns the time.Weekday of a day name ("mon", "monday"), or -1.
+func dayIndex(name string) int {
+	for i := range dayNames {
+		if len(name) >= 3 && strings.HasPrefix(strings.ToLower(time.Weekday(i).String()), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// loadPowerSchedules parses every power entry, skipping invalid ones.
+func loadPowerSchedules(values []string) ([]powerSchedule, []error) {
+	var schedules []powerSchedule
+	var errs []error
+	for _, v := range values {
+		s, err := parsePowerSchedule(v)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, errs
+}
+
+// due returns "start" or "stop" for the latest of the schedule's moments
+// in (after, now], in now's time zone, or "" when none fell in it.
+func (s powerSchedule) due(after, now time.Time) string {
+	var action string
+	var latest time.Time
+	for _, ev := range []struct {
+		action string
+		at     clock
+	}{{"start", s.start}, {"stop", s.stop}} {
+		if !ev.at.set {
+			continue
+		}
+		if t := s.spec(ev.at).last(after, now); !t.IsZero() && t.After(latest) {
+			action, latest = ev.action, t
+		}
+	}
+	return action
+}
+
+// spec is the cron expression for the schedule's moment at c.
+func (s powerSchedule) spec(c clock) cronSpec {
+	days := "*"
+	if describeDays(s.days) != "daily" {
+		var list []string
+		for d, on := range s.days {
+			if on {
+				list = append(list, strconv.Itoa(d))
+			}
+		}
+		days = strings.Join(list, ",")
+	}
+	spec, _ := parseCron(fmt.Sprintf("%d %d * * %s", c.minute, c.hour, days))
+	return spec
+}
+
+// powerAction is what a due schedule action does to a VM in state: start a
+// stopped or suspended VM, stop a running one, or nothing.
+func powerAction(action, state string) string {
+	switch {
+	case action == "start" && (state == "Stopped" || state == "Suspended"):
+		return "start"
+	case action == "stop" && state == "Running":
+		return "stop"
+	}
+	return ""
+}
+
+// scheduledOp is a multipass operation an automation decided on: "start",
+// "stop" or "soft-delete", and why, for the toast or log line.
+type scheduledOp struct {
+	vm     string
+	op     string
+	reason string
+}
+
+// powerOps returns what schedules do to the VMs in states (by name) for
+// the moments in (after, now].
+func powerOps(schedules []powerSchedule, states map[string]string, after, now time.Time) []scheduledOp {
+	var ops []scheduledOp
+	for _, s := range schedules {
+		state, ok := states[s.vm]
+		if !ok {
+			continue
+		}
+		if op := powerAction(s.due(after, now), state); op != "" {
+			ops = append(ops, scheduledOp{vm: s.vm, op: op, reason: "schedule " + s.describe()})
+		}
+	}
+	return ops
+}
+
+// describe says when the schedule acts, e.g. "weekdays: start 09:00, stop 19:00".
+func (s powerSchedule) describe() string {
+	var parts []string
+	if s.start.set {
+		parts = append(parts, "start "+s.start.String())
+	}
+	if s.stop.set {
+		parts = append(parts, "stop "+s.stop.String())
+	}
+	return describeDays(s.days) + ": " + strings.Join(parts, ", ")
+}
+
+// describeDays names a set of days the way they are usually written.
+func describeDays(days [7]bool) string {
+	switch days {
+	case [7]bool{true, true, true, true, true, true, true}:
+		return "daily"
+	case [7]bool{false, true, true, true, true, true, false}:
+		return "weekdays"
+	case [7]bool{true, false, false, false, false, false, true}:
+		return "weekends"
+	}
+	var names []string
+	for i, on := range days {
+		if on {
+			names = append(names, dayNames[i])
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// ─── TUI ───────────────────────────────────────────────────────────────────────
+
+// checkPowerSchedules starts and stops VMs whose scheduled moment passed
+// since the last check. Moments missed while passgo was closed are not
+// caught up, except across a sleep of the host. VMs with an operation in
+// flight are left alone.
+func (m *rootModel) checkPowerSchedules(now time.Time) []tea.Cmd {
+	if len(m.power) == 0 {
+		return nil
+	}
+	if m.lastPowerCheck.IsZero() {
+		m.lastPowerCheck = now
+		return nil
+	}
+	if now.Sub(m.lastPowerCheck) < powerCheckInterval {
+		return nil
+	}
+	after := m.lastPowerCheck
+	m.lastPowerCheck = now
+	states := make(map[string]string, len(m.table.vms))
+	for _, vm := range m.table.vms {
+		if _, busy := m.table.busyVMs[vm.info.Name]; !busy {
+			states[vm.info.Name] = vm.info.State
+		}
+	}
+	var cmds []tea.Cmd
+	for _, op := range powerOps(m.power, states, after, now) {
+		switch op.op {
+		case "start":
+			m.table.busyVMs[op.vm] = busyInfo{operation: "Starting", startTime: now}
+			cmds = append(cmds, startVMCmd(op.vm),
+				m.table.addToast(fmt.Sprintf("⏰ Starting %s (%s)", op.vm, op.reason), "info"))
+		case "stop":
+			m.table.busyVMs[op.vm] = busyInfo{operation: "Stopping", startTime: now}
+			cmds = append(cmds, stopVMCmd(op.vm),
+				m.table.addToast(fmt.Sprintf("⏰ Stopping %s (%s)", op.vm, op.reason), "info"))
+		}
+	}
+	return cmds
+}
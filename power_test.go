@@ -0,0 +1,112 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePowerSchedule(t *testing.T) {
+	s, err := parsePowerSchedule("{vm: dev, days: weekdays, start: 09:00, stop: 19:30}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := powerSchedule{vm: "dev", days: [7]bool{false, true, true, true, true, true, false},
+		start: clock{true, 9, 0}, stop: clock{true, 19, 30}}
+	if s != want {
+		t.Fatalf("got %+v, want %+v", s, want)
+	}
+	if got := s.describe(); got != "weekdays: start 09:00, stop 19:30" {
+		t.Errorf("describe = %q", got)
+	}
+
+	s, err = parsePowerSchedule("{vm: ci, stop: 23:00}")
+	if err != nil || s.start.set || describeDays(s.days) != "daily" {
+		t.Fatalf("defaults: got %+v, %v", s, err)
+	}
+	for days, want := range map[string]string{
+		"mon wed fri": "mon wed fri",
+		"fri-mon":     "sun mon fri sat",
+		"Saturday":    "sat",
+		"sat-sun":     "weekends",
+	} {
+		s, err := parsePowerSchedule("{vm: a, stop: 18:00, days: " + days + "}")
+		if err != nil || describeDays(s.days) != want {
+			t.Errorf("days %q = %q, %v; want %q", days, describeDays(s.days), err, want)
+		}
+	}
+
+	for _, bad := range []string{
+		"{start: 09:00}",
+		"{vm: dev}",
+		"{vm: dev, start: 9am}",
+		"{vm: dev, start: 24:00}",
+		"{vm: dev, start: 09:00, stop: 09:00}",
+		"{vm: dev, stop: 19:00, days: workdays}",
+		"{vm: dev, stop: 19:00, when: always}",
+	} {
+		if _, err := parsePowerSchedule(bad); err == nil {
+			t.Errorf("parsePowerSchedule(%q) should fail", bad)
+		}
+	}
+}
+
+func TestPowerScheduleDue(t *testing.T) {
+	s, err := parsePowerSchedule("{vm: dev, days: weekdays, start: 09:00, stop: 19:00}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.October, day, hour, minute, 0, 0, time.UTC) // the 15th is a Thursday
+	}
+	for _, c := range []struct {
+		after, now time.Time
+		want       string
+	}{
+		{at(15, 8, 59), at(15, 9, 0), "start"},
+		{at(15, 9, 0), at(15, 9, 1), ""},
+		{at(15, 18, 30), at(15, 19, 0), "stop"},
+		{at(15, 20, 0), at(16, 8, 0), ""},
+		{at(15, 18, 0), at(16, 9, 30), "start"}, // asleep overnight: the latest moment wins
+		{at(17, 8, 0), at(17, 10, 0), ""},       // Saturday
+		{at(16, 20, 0), at(19, 9, 0), "start"},  // Monday
+	} {
+		if got := s.due(c.after, c.now); got != c.want {
+			t.Errorf("due(%s, %s) = %q, want %q", c.after.Format("Mon 15:04"), c.now.Format("Mon 15:04"), got, c.want)
+		}
+	}
+
+	for _, c := range []struct{ action, state, want string }{
+		{"start", "Stopped", "start"},
+		{"start", "Suspended", "start"},
+		{"start", "Running", ""},
+		{"stop", "Running", "stop"},
+		{"stop", "Stopped", ""},
+		{"", "Running", ""},
+	} {
+		if got := powerAction(c.action, c.state); got != c.want {
+			t.Errorf("powerAction(%q, %q) = %q", c.action, c.state, got)
+		}
+	}
+}
+
+func TestCheckPowerSchedules(t *testing.T) {
+	m := rootModel{table: newTableModel()}
+	m.power, _ = loadPowerSchedules([]string{"{vm: dev, start: 09:00}", "{vm: busy, start: 09:00}", "{vm: up, start: 09:00}"})
+	m.table.vms = []vmData{
+		{info: VMInfo{Name: "dev", State: "Stopped"}},
+		{info: VMInfo{Name: "busy", State: "Stopped"}},
+		{info: VMInfo{Name: "up", State: "Running"}},
+	}
+	m.table.busyVMs["busy"] = busyInfo{operation: "Deleting"}
+	first := time.Date(2026, time.October, 15, 8, 59, 0, 0, time.Local)
+	if cmds := m.checkPowerSchedules(first); len(cmds) != 0 {
+		t.Fatal("the first check only marks the time")
+	}
+	cmds := m.checkPowerSchedules(first.Add(time.Minute))
+	if len(cmds) != 2 || m.table.busyVMs["dev"].operation != "Starting" {
+		t.Fatalf("got %d commands, busy %+v", len(cmds), m.table.busyVMs)
+	}
+	if m.table.busyVMs["busy"].operation != "Deleting" {
+		t.Error("a busy VM must be left alone")
+	}
+}
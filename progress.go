@@ -0,0 +1,42 @@
+// progress.go - Progress of long CLI operations as text or JSON lines
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// progressEvent is one line of `--progress json` output.
+type progressEvent struct {
+	Time    time.Time `json:"time"`
+	Phase   string    `json:"phase"`   // launch phase label, or launch, mount, warning, done…
+	Percent int       `json:"percent"` // of the VM's operation, -1 when unknown
+	VM      string    `json:"vm,omitempty"`
+	Message string    `json:"message"`
+}
+
+// progressReporter writes progress events: as "passgo: message" lines, or
+// as JSON lines when json is set.
+type progressReporter struct {
+	w    io.Writer
+	json bool
+}
+
+// say reports an event whose message is built from format and args.
+func (p progressReporter) say(vm, phase string, percent int, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if !p.json {
+		fmt.Fprintf(p.w, "passgo: %s\n", msg)
+		return
+	}
+	_ = json.NewEncoder(p.w).Encode(progressEvent{Time: time.Now().UTC(), Phase: phase, Percent: percent, VM: vm, Message: msg})
+}
+
+// launchPercent is how far a launch is in phase, spreading the phases
+// evenly and the download percentage within the first one.
+func launchPercent(phase launchPhase) int {
+	pct := max(phase.Percent, 0)
+	return (phase.Step*100 + pct) / len(launchPhaseLabels)
+}
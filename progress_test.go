@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestProgressReporter(t *testing.T) {
+	var buf bytes.Buffer
+	progressReporter{w: &buf}.say("web", "launch", 0, "launching %s…", "web")
+	if buf.String() != "passgo: launching web…\n" {
+		t.Errorf("text progress = %q", buf.String())
+	}
+
+	buf.Reset()
+	progressReporter{w: &buf, json: true}.say("web", "Retrieving image", 10, "[1/5] Retrieving image…")
+	var e progressEvent
+	if err := json.Unmarshal(buf.Bytes(), &e); err != nil {
+		t.Fatalf("json progress %q: %v", buf.String(), err)
+	}
+	if e.VM != "web" || e.Phase != "Retrieving image" || e.Percent != 10 || e.Message != "[1/5] Retrieving image…" || e.Time.IsZero() {
+		t.Errorf("event = %+v", e)
+	}
+}
+
+func TestLaunchPercent(t *testing.T) {
+	tests := []struct {
+		phase launchPhase
+		want  int
+	}{
+		{launchPhase{Step: 0, Percent: -1}, 0},
+		{launchPhase{Step: 0, Percent: 50}, 10},
+		{launchPhase{Step: 2, Percent: -1}, 40},
+		{launchPhase{Step: 4, Percent: -1}, 80},
+	}
+	for _, tt := range tests {
+		if got := launchPercent(tt.phase); got != tt.want {
+			t.Errorf("launchPercent(%+v) = %d, want %d", tt.phase, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// quota.go - Resource quotas across all instances (vCPUs, memory, disk)
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// resources is an amount of vCPUs, memory and disk.
+type resources struct {
+	cpus     int
+	memoryMB int
+	diskGB   int
+}
+
+// resourceQuota caps the total resources passgo will allocate. Zero fields
+// are unlimited. Configured in .config with quota-cpus, quota-memory,
+// quota-disk and quota-mode (refuse or warn).
+type resourceQuota struct {
+	limit    resources
+	warnOnly bool
+}
+
+// loadResourceQuota reads the quota using lookup (normally configValue).
+func loadResourceQuota(lookup func(string) (string, bool)) resourceQuota {
+	var q resourceQuota
+	if v, ok := lookup("quota-cpus"); ok {
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && n > 0 {
+			q.limit.cpus = n
+		}
+	}
+	if v, ok := lookup("quota-memory"); ok {
+		q.limit.memoryMB = parseQuotaSizeMB(v, 1)
+	}
+	if v, ok := lookup("quota-disk"); ok {
+		q.limit.diskGB = parseQuotaSizeMB(v, 1024) / 1024
+	}
+	if v, ok := lookup("quota-mode"); ok {
+		q.warnOnly = strings.EqualFold(strings.TrimSpace(v), "warn")
+	}
+	return q
+}
+
+// parseQuotaSizeMB parses "16G", "16GiB", "512M" or a bare number in
+// bareUnitMB units, returning MiB (0 when invalid).
+func parseQuotaSizeMB(s string, bareUnitMB int) int {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		return max(0, n*bareUnitMB)
+	}
+	return max(0, int(parseSize(s)))
+}
+
+// times is r for each of n instances.
+func (r resources) times(n int) resources {
+	return resources{r.cpus * n, r.memoryMB * n, r.diskGB * n}
+}
+
+func (q resourceQuota) enabled() bool {
+	return q.limit.cpus > 0 || q.limit.memoryMB > 0 || q.limit.diskGB > 0
+}
+
+// allocatedResources totals what existing instances hold. Sizes recorded by
+// passgo at launch take precedence; otherwise what multipass reports is used
+// (stopped VMs not launched by passgo may report nothing).
+func allocatedResources(vms []vmData, meta map[string]vmMeta) resources {
+	var total resources
+	for _, vm := range vms {
+		if vm.info.State == "Deleted" {
+			continue
+		}
+		if mt, ok := meta[vm.info.Name]; ok && mt.CPUs > 0 {
+			total.cpus += mt.CPUs
+			total.memoryMB += mt.MemoryMB
+			total.diskGB += mt.DiskGB
+			continue
+		}
+		if n, err := strconv.Atoi(vm.info.CPUs); err == nil {
+			total.cpus += n
+		}
+		total.memoryMB += int(usageTotalMB(vm.info.MemoryUsage))
+		total.diskGB += int(usageTotalMB(vm.info.DiskUsage) / 1024)
+	}
+	return total
+}
+
+// usageTotalMB returns the "out of" part of a usage string in MiB.
+func usageTotalMB(usage string) float64 {
+	parts := strings.SplitN(usage, " out of ", 2)
+	if len(parts) != 2 {
+		return 0
+	}
+	return parseSize(parts[1])
+}
+
+// exceeded lists each limit that used+req would exceed; nil when within quota.
+func (q resourceQuota) exceeded(used, req resources) []string {
+	var over []string
+	if q.limit.cpus > 0 && used.cpus+req.cpus > q.limit.cpus {
+		over = append(over, fmt.Sprintf("vCPUs %d+%d > %d", used.cpus, req.cpus, q.limit.cpus))
+	}
+	if q.limit.memoryMB > 0 && used.memoryMB+req.memoryMB > q.limit.memoryMB {
+		over = append(over, fmt.Sprintf("memory %dM+%dM > %dM", used.memoryMB, req.memoryMB, q.limit.memoryMB))
+	}
+	if q.limit.diskGB > 0 && used.diskGB+req.diskGB > q.limit.diskGB {
+		over = append(over, fmt.Sprintf("disk %dG+%dG > %dG", used.diskGB, req.diskGB, q.limit.diskGB))
+	}
+	return over
+}
+
+// enforceQuota checks a launch of req against the quota. When the launch
+// must not proceed it shows an error and returns false; in warn mode it
+// returns a warning toast and true.
+func (m *rootModel) enforceQuota(req resources) (tea.Cmd, bool) {
+	if !m.quota.enabled() {
+		return nil, true
+	}
+	over := m.quota.exceeded(allocatedResources(m.table.vms, m.state.VMs), req)
+	if len(over) == 0 {
+		return nil, true
+	}
+	if m.quota.warnOnly {
+		return m.table.addToastFor("⚠ Over quota: "+strings.Join(over, ", "), "error", 8*time.Second), true
+	}
+	m.errModal = newErrorModel("Quota Exceeded",
+		"This launch would exceed the configured quota:\n  "+strings.Join(over, "\n  ")+
+			"\n\nFree resources or raise quota-* in .config (quota-mode=warn to only warn).")
+	m.setChildSizes()
+	m.currentView = viewError
+	return nil, false
+}
+
+// checkQuota checks a launch of req from the command line against q, using
+// the state file and the current instances. It returns an error when the
+// launch must not proceed and, in warn mode, a warning to print.
+func checkQuota(q resourceQuota, req resources) (string, error) {
+	if !q.enabled() {
+		return "", nil
+	}
+	path, err := stateFilePath()
+	if err != nil {
+		return "", err
+	}
+	st, err := loadState(path)
+	if err != nil {
+		return "", err
+	}
+	vms, err := doFetchVMList()
+	if err != nil {
+		return "", err
+	}
+	over := q.exceeded(allocatedResources(vms, st.VMs), req)
+	if len(over) == 0 {
+		return "", nil
+	}
+	if q.warnOnly {
+		return "over quota: " + strings.Join(over, ", "), nil
+	}
+	return "", fmt.Errorf("launch would exceed the quota (%s); free resources or raise quota-* in .config (quota-mode=warn to only warn)",
+		strings.Join(over, ", "))
+}
+
+// recordLaunch remembers the resources (and optional TTL) of a VM passgo is
+// launching, for quota accounting and auto-teardown.
+func (m *rootModel) recordLaunch(name string, req resources, ttl time.Duration, ttlAction string) {
+	if m.state.VMs == nil {
+		return
+	}
+	m.state.setAllocation(name, req)
+	m.recordOwner(name)
+	if ttl > 0 {
+		m.state.setTTL(name, time.Now().Add(ttl), ttlAction)
+	}
+	m.persistState()
+}
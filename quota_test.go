@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResourceQuota(t *testing.T) {
+	values := map[string]string{"quota-cpus": "4", "quota-memory": "4G", "quota-disk": "50"}
+	q := loadResourceQuota(func(k string) (string, bool) { v, ok := values[k]; return v, ok })
+	if q.limit != (resources{cpus: 4, memoryMB: 4096, diskGB: 50}) || q.warnOnly {
+		t.Fatalf("unexpected quota: %+v", q)
+	}
+
+	vms := []vmData{
+		{info: VMInfo{Name: "tracked", State: "Stopped"}},
+		{info: VMInfo{Name: "external", State: "Running", CPUs: "1", MemoryUsage: "200.0MiB out of 1.0GiB", DiskUsage: "2.0GiB out of 10.0GiB"}},
+		{info: VMInfo{Name: "gone", State: "Deleted", CPUs: "8"}},
+	}
+	meta := map[string]vmMeta{"tracked": {CPUs: 2, MemoryMB: 2048, DiskGB: 20}}
+
+	used := allocatedResources(vms, meta)
+	if used != (resources{cpus: 3, memoryMB: 3072, diskGB: 30}) {
+		t.Fatalf("unexpected allocation: %+v", used)
+	}
+
+	if over := q.exceeded(used, resources{cpus: 1, memoryMB: 1024, diskGB: 20}); over != nil {
+		t.Fatalf("expected launch to fit, got %v", over)
+	}
+	if over := q.exceeded(used, resources{cpus: 2, memoryMB: 2048, diskGB: 5}); len(over) != 2 {
+		t.Fatalf("expected cpu and memory to be exceeded, got %v", over)
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useFakeRunner(t, &fakeRunner{outputs: map[string]string{"info": `{"info": {
+		"web": {"state": "Running", "cpu_count": "2", "memory": {"total": 2147483648}, "disks": {"sda1": {"total": "10737418240"}}}}}`}})
+
+	q := resourceQuota{limit: resources{cpus: 4}}
+	if warn, err := checkQuota(q, resources{cpus: 2}); warn != "" || err != nil {
+		t.Fatalf("a launch within quota: %q, %v", warn, err)
+	}
+	if _, err := checkQuota(q, resources{cpus: 1}.times(3)); err == nil || !strings.Contains(err.Error(), "vCPUs 2+3 > 4") {
+		t.Fatalf("three more vCPUs should be refused, got %v", err)
+	}
+	q.warnOnly = true
+	if warn, err := checkQuota(q, resources{cpus: 3}); err != nil || !strings.Contains(warn, "vCPUs 2+3 > 4") {
+		t.Fatalf("warn mode: %q, %v", warn, err)
+	}
+}
+
+func TestCloneEnforcesQuota(t *testing.T) {
+	m := rootModel{table: newTableModel(), state: newAppState(), quota: resourceQuota{limit: resources{cpus: 4}}}
+	m.table.setVMs([]vmData{{info: VMInfo{Name: "base", State: "Stopped"}}})
+	m.state.setAllocation("base", resources{cpus: 2, memoryMB: 1024, diskGB: 10})
+
+	model, _ := m.Update(snapCloneSubmitMsg{vmName: "base", snapName: "s1", names: []string{"a", "b"}})
+	m = model.(rootModel)
+	if m.currentView != viewError {
+		t.Fatalf("two 2-vCPU clones on top of base should exceed 4 vCPUs (view %v)", m.currentView)
+	}
+	if _, busy := m.table.busyVMs["base"]; busy {
+		t.Fatal("a refused clone should not mark the source busy")
+	}
+}
@@ -0,0 +1,189 @@
+// reachability.go - Checking that running VMs answer on their IP
+package main
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// reachPolicy configures the optional Net column, which dials a TCP port
+// on each running VM's IP. It catches VMs multipass reports as Running
+// whose networking broke, typically after the host slept. Set in .config
+// with reachability: off (the default), on (port 22) or a port number.
+type reachPolicy struct {
+	port int // 0 = disabled
+}
+
+const defaultReachPort = 22
+
+// reachTimeout bounds each dial; a healthy VM answers well within it.
+const reachTimeout = 2 * time.Second
+
+// loadReachPolicy reads the policy using lookup (normally configValue).
+func loadReachPolicy(lookup func(string) (string, bool)) reachPolicy {
+	v, ok := lookup("reachability")
+	if !ok {
+		return reachPolicy{}
+	}
+	v = strings.TrimSpace(v)
+	if n, err := strconv.Atoi(v); err == nil && n > 0 && n < 65536 {
+		return reachPolicy{port: n}
+	}
+	if parseConfigBool(v) {
+		return reachPolicy{port: defaultReachPort}
+	}
+	return reachPolicy{}
+}
+
+// reachColumn is the table column added when the policy is on. It comes
+// after the built-in columns, at index reachColumnIdx.
+var reachColumn = tableColumn{title: "Net", width: 8, minWidth: 6, priority: 4}
+
+const reachColumnIdx = 7
+
+// reachDial opens a TCP connection; tests replace it.
+var reachDial = func(addr string, timeout time.Duration) error {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// firstIPv4 returns the first address of a VM's IPv4 field, or "".
+func firstIPv4(field string) string {
+	for _, ip := range strings.Fields(field) {
+		if net.ParseIP(ip) != nil {
+			return ip
+		}
+	}
+	return ""
+}
+
+// reachTargets maps running VMs with an address to that address.
+func reachTargets(vms []vmData) map[string]string {
+	targets := make(map[string]string)
+	for _, vm := range vms {
+		if vm.info.State != "Running" {
+			continue
+		}
+		if ip := firstIPv4(vm.info.IPv4); ip != "" {
+			targets[vm.info.Name] = ip
+		}
+	}
+	return targets
+}
+
+// reachabilityMsg carries which VMs answered on the port.
+type reachabilityMsg struct {
+	results map[string]bool
+}
+
+// checkReachabilityCmd dials port on every target at once.
+func checkReachabilityCmd(targets map[string]string, port int) tea.Cmd {
+	return func() tea.Msg {
+		results := make(map[string]bool, len(targets))
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for name, ip := range targets {
+			wg.Add(1)
+			go func(name, ip string) {
+				defer wg.Done()
+				err := reachDial(net.JoinHostPort(ip, strconv.Itoa(port)), reachTimeout)
+				mu.Lock()
+				results[name] = err == nil
+				mu.Unlock()
+			}(name, ip)
+		}
+		wg.Wait()
+		return reachabilityMsg{results: results}
+	}
+}
+
+// startReachabilityCheck probes the running VMs after a refresh, unless
+// the policy is off or the previous check has not finished.
+func (m *rootModel) startReachabilityCheck() tea.Cmd {
+	if m.reach.port == 0 || m.reachInFlight {
+		return nil
+	}
+	targets := reachTargets(m.table.vms)
+	if len(targets) == 0 {
+		m.table.reach = nil
+		return nil
+	}
+	m.reachInFlight = true
+	return checkReachabilityCmd(targets, m.reach.port)
+}
+
+// applyReachability stores the results and toasts once for each VM that
+// has stopped answering.
+func (m *rootModel) applyReachability(results map[string]bool) tea.Cmd {
+	m.reachInFlight = false
+	if m.reachRecheck {
+		m.reachRecheck = false
+		return m.startReachabilityCheck()
+	}
+	var lost []string
+	for name, ok := range results {
+		if prev, seen := m.table.reach[name]; !ok && (!seen || prev) {
+			lost = append(lost, name)
+		}
+	}
+	m.table.reach = results
+	if m.table.sortColumn == m.table.columnIndex(reachColumn.title) {
+		m.table.applyFilterAndSort()
+	}
+	if len(lost) == 0 {
+		return nil
+	}
+	sort.Strings(lost)
+	if appLogger != nil {
+		appLogger.Printf("reachability: no answer on port %d from %s", m.reach.port, strings.Join(lost, ", "))
+	}
+	return m.table.addToastFor(fmt.Sprintf("⚠ Running but unreachable on port %d: %s", m.reach.port, strings.Join(lost, ", ")), "error", 8*time.Second)
+}
+
+// reachLabel is the Net cell for a VM, "" when it was not checked.
+func (m tableModel) reachLabel(name string) string {
+	ok, checked := m.reach[name]
+	switch {
+	case !checked:
+		return ""
+	case ok:
+		return "✓ up"
+	}
+	return "✗ down"
+}
+
+// sortByReach orders the filtered rows by the Net column: unreachable,
+// then reachable, then unchecked, by name within each.
+func (m *tableModel) sortByReach() {
+	rank := func(vm vmData) int {
+		ok, checked := m.reach[vm.info.Name]
+		switch {
+		case !checked:
+			return 2
+		case ok:
+			return 1
+		}
+		return 0
+	}
+	sort.SliceStable(m.filteredVMs, func(i, j int) bool {
+		a, b := m.filteredVMs[i], m.filteredVMs[j]
+		cmp := rank(a) - rank(b)
+		if cmp == 0 {
+			cmp = compareStringsFold(a.info.Name, b.info.Name)
+		}
+		if m.sortAscending {
+			return cmp < 0
+		}
+		return cmp > 0
+	})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadReachPolicy(t *testing.T) {
+	for v, want := range map[string]int{"on": 22, "true": 22, "2222": 2222, "off": 0, "99999": 0, "nope": 0} {
+		lookup := func(string) (string, bool) { return v, true }
+		if got := loadReachPolicy(lookup).port; got != want {
+			t.Errorf("reachability=%s: port %d, want %d", v, got, want)
+		}
+	}
+	if loadReachPolicy(func(string) (string, bool) { return "", false }).port != 0 {
+		t.Error("reachability checks should be off by default")
+	}
+}
+
+func TestReachabilityChecks(t *testing.T) {
+	old := reachDial
+	defer func() { reachDial = old }()
+	var dialed []string
+	reachDial = func(addr string, _ time.Duration) error {
+		dialed = append(dialed, addr)
+		if strings.HasPrefix(addr, "10.0.0.2:") {
+			return errors.New("timeout")
+		}
+		return nil
+	}
+
+	m := rootModel{table: newTableModel(), reach: reachPolicy{port: 22}}
+	m.table.columns = append(m.table.columns, reachColumn)
+	m.table.width, m.table.height = 160, 40
+	m.table.setVMs([]vmData{
+		{info: VMInfo{Name: "web", State: "Running", IPv4: "10.0.0.1 172.17.0.1"}},
+		{info: VMInfo{Name: "db", State: "Running", IPv4: "10.0.0.2"}},
+		{info: VMInfo{Name: "old", State: "Stopped", IPv4: "--"}},
+	})
+
+	cmd := m.startReachabilityCheck()
+	if cmd == nil || !m.reachInFlight {
+		t.Fatal("expected a check to start")
+	}
+	if m.startReachabilityCheck() != nil {
+		t.Error("a second check should wait for the first")
+	}
+	msg := cmd().(reachabilityMsg)
+	if len(dialed) != 2 || !msg.results["web"] || msg.results["db"] {
+		t.Fatalf("dialed %v, results %v", dialed, msg.results)
+	}
+	if m.applyReachability(msg.results) == nil {
+		t.Error("db going unreachable should raise a toast")
+	}
+	if m.applyReachability(msg.results) != nil {
+		t.Error("a VM that stays unreachable should not toast again")
+	}
+	if m.table.reachLabel("web") != "✓ up" || m.table.reachLabel("db") != "✗ down" || m.table.reachLabel("old") != "" {
+		t.Error("unexpected Net labels")
+	}
+	if !strings.Contains(m.table.View(), "✗ down") {
+		t.Error("the Net column should be rendered")
+	}
+
+	m.table.sortColumn = reachColumnIdx
+	m.table.applyFilterAndSort()
+	if m.table.filteredVMs[0].info.Name != "db" {
+		t.Errorf("sorting by Net should put unreachable VMs first, got %s", m.table.filteredVMs[0].info.Name)
+	}
+	headers, rows := m.table.exportTable()
+	if headers[len(headers)-1] != "Net" || rows[0][len(headers)-1] != "✗ down" {
+		t.Errorf("export: %v %v", headers, rows[0])
+	}
+}
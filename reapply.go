@@ -0,0 +1,88 @@
+// reapply.go - Pushing an updated cloud-config to an existing VM and re-running cloud-init
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// reapplyUploadPath is where the cloud-config is copied in the VM.
+const reapplyUploadPath = "/tmp/passgo-reapply.yaml"
+
+// reapplyConfigPath is where a full re-run installs the cloud-config, so it is
+// merged over the seed user-data the VM was launched with.
+const reapplyConfigPath = "/etc/cloud/cloud.cfg.d/99-passgo-reapply.cfg"
+
+// cloudInitModuleRe matches a cloud-init module name such as "runcmd" or
+// "cc_write_files".
+var cloudInitModuleRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// parseReapplyModules splits a list of cloud-init modules separated by spaces
+// or commas. An empty list or "all" means a full re-run (nil).
+func parseReapplyModules(s string) ([]string, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(fields) == 0 || (len(fields) == 1 && fields[0] == "all") {
+		return nil, nil
+	}
+	var modules []string
+	for _, f := range fields {
+		if !cloudInitModuleRe.MatchString(f) {
+			return nil, fmt.Errorf("invalid cloud-init module %q", f)
+		}
+		modules = append(modules, f)
+	}
+	return modules, nil
+}
+
+// reapplyScript is the shell script run in the VM after the upload. Without
+// modules, cloud-init state and logs are cleaned and every stage runs again
+// with the new config; otherwise only the named modules run, each with
+// frequency "always" so earlier runs do not skip them.
+func reapplyScript(modules []string) string {
+	lines := []string{"set -e"}
+	if len(modules) == 0 {
+		lines = append(lines,
+			fmt.Sprintf("sudo install -m 0644 %s %s", reapplyUploadPath, reapplyConfigPath),
+			"sudo cloud-init clean --logs",
+			"sudo cloud-init init",
+			"sudo cloud-init modules --mode=config",
+			"sudo cloud-init modules --mode=final")
+	} else {
+		for _, mod := range modules {
+			lines = append(lines, fmt.Sprintf("sudo cloud-init single --name %s --frequency always --file %s", mod, reapplyUploadPath))
+		}
+	}
+	lines = append(lines, "rm -f "+reapplyUploadPath)
+	return strings.Join(lines, "\n")
+}
+
+// ReapplyCloudInit uploads the cloud-config at path (with secrets rendered)
+// to a running VM and re-runs cloud-init there; see reapplyScript.
+func ReapplyCloudInit(vmName, path string, modules []string) (string, error) {
+	if !hasCloudConfigHeader(path) {
+		return "", errors.New(path + " is not a cloud-config file (missing #cloud-config header)")
+	}
+	rendered, cleanup, err := prepareCloudInit(path)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+	if _, err := TransferToVM(vmName, rendered, reapplyUploadPath); err != nil {
+		return "", err
+	}
+	return ExecInVM(vmName, "bash", "-c", reapplyScript(modules))
+}
+
+// reapplyCloudInitCmd re-applies a cloud-config, then removes any cloned
+// template repos.
+func reapplyCloudInitCmd(vmName, path string, modules []string, cleanupDirs []string) tea.Cmd {
+	return func() tea.Msg {
+		defer CleanupTempDirs(cleanupDirs)
+		trace, err := traceVMOp("cloud-init", vmName, func() (string, error) { return ReapplyCloudInit(vmName, path, modules) })
+		return vmOperationResultMsg{vmName: vmName, operation: "cloud-init", err: err, trace: trace}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseReapplyModules(t *testing.T) {
+	for _, s := range []string{"", "  ", "all"} {
+		if got, err := parseReapplyModules(s); err != nil || got != nil {
+			t.Fatalf("%q: got %v, %v; want full re-run", s, got, err)
+		}
+	}
+	got, err := parseReapplyModules("write_files, runcmd cc_apt_configure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"write_files", "runcmd", "cc_apt_configure"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if _, err := parseReapplyModules("runcmd; reboot"); err == nil {
+		t.Fatal("expected an error for a module name with shell characters")
+	}
+}
+
+func TestReapplyScript(t *testing.T) {
+	full := reapplyScript(nil)
+	for _, want := range []string{reapplyConfigPath, "cloud-init clean --logs", "cloud-init init", "--mode=config", "--mode=final"} {
+		if !strings.Contains(full, want) {
+			t.Errorf("full re-run script missing %q:\n%s", want, full)
+		}
+	}
+	if strings.Contains(full, "cloud-init single") {
+		t.Errorf("full re-run should not run single modules:\n%s", full)
+	}
+
+	single := reapplyScript([]string{"write_files", "runcmd"})
+	if strings.Contains(single, "clean") {
+		t.Errorf("module re-run should not clean state:\n%s", single)
+	}
+	for _, mod := range []string{"write_files", "runcmd"} {
+		if !strings.Contains(single, "cloud-init single --name "+mod+" --frequency always --file "+reapplyUploadPath) {
+			t.Errorf("script does not run %s:\n%s", mod, single)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// relaunch.go - Remembering the last successful launch and repeating it under a new name
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// lastLaunch is what the last successful launch from the TUI asked for.
+// Templates are kept by their picker label and looked up again when the
+// launch is repeated, since repo clones are temporary.
+type lastLaunch struct {
+	Name          string        `json:"name"`
+	Release       string        `json:"release"`
+	CPUs          int           `json:"cpus"`
+	MemoryMB      int           `json:"memory_mb"`
+	DiskGB        int           `json:"disk_gb"`
+	Template      string        `json:"template,omitempty"`   // picker label
+	CloudInit     string        `json:"cloud_init,omitempty"` // file used when there is no label (quick launch default)
+	Networks      []NetworkSpec `json:"networks,omitempty"`
+	TTL           time.Duration `json:"ttl,omitempty"`
+	TTLAction     string        `json:"ttl_action,omitempty"`
+	ProjectDir    string        `json:"project_dir,omitempty"`
+	ProjectTarget string        `json:"project_target,omitempty"`
+}
+
+// launchFromMsg records the settings of msg.
+func launchFromMsg(msg advCreateMsg) lastLaunch {
+	l := lastLaunch{Name: msg.name, Release: msg.release, CPUs: msg.cpus, MemoryMB: msg.memoryMB, DiskGB: msg.diskGB,
+		Template: msg.template, Networks: msg.networks, TTL: msg.ttl, TTLAction: msg.ttlAction,
+		ProjectDir: msg.projectDir, ProjectTarget: msg.projectTarget}
+	if l.Template == "" {
+		l.CloudInit = msg.cloudInitFile
+	}
+	return l
+}
+
+// describe summarises the launch for the toast that starts a repeat.
+func (l lastLaunch) describe() string {
+	s := fmt.Sprintf("%s, %d CPU, %s, %dG", l.Release, l.CPUs, formatSizeMB(l.MemoryMB), l.DiskGB)
+	if l.Template != "" {
+		s += ", " + l.Template
+	}
+	return s
+}
+
+var nameSeqRe = regexp.MustCompile(`^(.+)-(\d+)$`)
+
+// nextLaunchName derives a free name from the last one: web-1 gives web-2,
+// dev gives dev-2, skipping names that exist.
+func nextLaunchName(last string, exists func(string) bool) string {
+	base, n := last, 1
+	if m := nameSeqRe.FindStringSubmatch(last); m != nil {
+		base = m[1]
+		n, _ = strconv.Atoi(m[2])
+	}
+	for {
+		n++
+		if name := fmt.Sprintf("%s-%d", base, n); !exists(name) {
+			return name
+		}
+	}
+}
+
+// relaunchErrMsg reports a repeat that could not be prepared.
+type relaunchErrMsg struct {
+	name string
+	err  error
+}
+
+// relaunchCmd turns l into the launch of a new VM called name. A template
+// is looked up again by its label and copied to a temp file, so a repo
+// clone can be removed before multipass reads it.
+func relaunchCmd(l lastLaunch, name string) tea.Cmd {
+	return func() tea.Msg {
+		msg := advCreateMsg{name: name, release: l.Release, cpus: l.CPUs, memoryMB: l.MemoryMB, diskGB: l.DiskGB,
+			cloudInitFile: l.CloudInit, networks: l.Networks, ttl: l.TTL, ttlAction: l.TTLAction,
+			template: l.Template, projectDir: l.ProjectDir, projectTarget: l.ProjectTarget, quick: true}
+		if l.Template == "" {
+			return msg
+		}
+		options, cleanupDirs, _ := GetAllCloudInitTemplateOptions()
+		defer CleanupTempDirs(cleanupDirs)
+		for _, opt := range options {
+			if opt.Label != l.Template {
+				continue
+			}
+			content, err := os.ReadFile(opt.Path) // #nosec G304 -- template found by the scan
+			if err != nil {
+				return relaunchErrMsg{name: name, err: err}
+			}
+			path, _, err := writeTempCloudInit(content) // removed when passgo exits
+			if err != nil {
+				return relaunchErrMsg{name: name, err: err}
+			}
+			msg.cloudInitFile, msg.provenance = path, opt.Provenance(false)
+			return msg
+		}
+		return relaunchErrMsg{name: name, err: fmt.Errorf("template %s is no longer available; launch from Advanced Create (C)", l.Template)}
+	}
+}
+
+// noteLaunching keeps the settings of a launch until it finishes.
+func (m *rootModel) noteLaunching(msg advCreateMsg) {
+	if m.launching == nil {
+		m.launching = make(map[string]lastLaunch)
+	}
+	m.launching[msg.name] = launchFromMsg(msg)
+}
+
+// settleLaunch makes a successful launch of vmName the one a repeats.
+func (m *rootModel) settleLaunch(vmName string, ok bool) {
+	l, tracked := m.launching[vmName]
+	if !tracked {
+		return
+	}
+	delete(m.launching, vmName)
+	if ok {
+		m.state.LastLaunch = &l
+	}
+}
+
+// repeatLastLaunch launches another VM like the last one, named after it.
+func (m *rootModel) repeatLastLaunch() tea.Cmd {
+	l := m.state.LastLaunch
+	if l == nil {
+		return m.table.addToast("Nothing launched yet: a launches another VM like the last one", "info")
+	}
+	name := nextLaunchName(l.Name, m.nameTaken)
+	if err := loadNamingPolicy(configValue).check(name); err != nil {
+		return m.namingError(err)
+	}
+	// Reserve the name while the template is looked up
+	m.noteLaunching(advCreateMsg{name: name})
+	return tea.Batch(m.table.addToast(fmt.Sprintf("Launching %s like %s (%s)", name, l.Name, l.describe()), "info"), relaunchCmd(*l, name))
+}
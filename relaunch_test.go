@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNextLaunchName(t *testing.T) {
+	taken := map[string]bool{"web-2": true}
+	exists := func(n string) bool { return taken[n] }
+	for last, want := range map[string]string{
+		"web-1":       "web-3",
+		"dev":         "dev-2",
+		"brave-otter": "brave-otter-2",
+		"db-9":        "db-10",
+	} {
+		if got := nextLaunchName(last, exists); got != want {
+			t.Errorf("nextLaunchName(%q) = %q, want %q", last, got, want)
+		}
+	}
+}
+
+func TestRepeatLastLaunch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := rootModel{table: newTableModel(), state: newAppState()}
+	if cmd := m.repeatLastLaunch(); cmd == nil || len(m.launching) != 0 {
+		t.Fatal("with nothing launched, a should only explain itself")
+	}
+
+	launch := advCreateMsg{name: "web-1", release: "24.04", cpus: 2, memoryMB: 4096, diskGB: 20,
+		cloudInitFile: "/tmp/default.yaml", ttl: time.Hour, ttlAction: "stop"}
+	m.noteLaunching(launch)
+	m.settleLaunch("web-1", false)
+	if m.state.LastLaunch != nil {
+		t.Fatal("a failed launch must not be remembered")
+	}
+	m.noteLaunching(launch)
+	m.settleLaunch("web-1", true)
+	if l := m.state.LastLaunch; l == nil || l.CloudInit != "/tmp/default.yaml" || l.TTL != time.Hour {
+		t.Fatalf("last launch = %+v", l)
+	}
+
+	m.table.vms = []vmData{{info: VMInfo{Name: "web-1"}}}
+	if cmd := m.repeatLastLaunch(); cmd == nil {
+		t.Fatal("a should launch")
+	}
+	if _, reserved := m.launching["web-2"]; !reserved {
+		t.Fatal("the new name should be reserved")
+	}
+	got := relaunchCmd(*m.state.LastLaunch, "web-2")()
+	want := advCreateMsg{name: "web-2", release: "24.04", cpus: 2, memoryMB: 4096, diskGB: 20,
+		cloudInitFile: "/tmp/default.yaml", ttl: time.Hour, ttlAction: "stop", quick: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("repeat = %+v, want %+v", got, want)
+	}
+}
+
+func TestRelaunchMissingTemplate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile(filepath.Join(dir, "web.yaml"), []byte("#cloud-config\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	msg := relaunchCmd(lastLaunch{Name: "a", Template: "gallery/web"}, "a-2")()
+	if e, ok := msg.(relaunchErrMsg); !ok || e.name != "a-2" || e.err == nil {
+		t.Errorf("a template that is gone should fail the repeat, got %#v", msg)
+	}
+
+	msg = relaunchCmd(lastLaunch{Name: "a", Template: "web.yaml"}, "a-2")()
+	launch, ok := msg.(advCreateMsg)
+	if !ok {
+		t.Fatalf("got %#v", msg)
+	}
+	t.Cleanup(func() { tempArtifacts.remove(launch.cloudInitFile) })
+	if data, err := os.ReadFile(launch.cloudInitFile); err != nil || string(data) != "#cloud-config\n" {
+		t.Errorf("template copy: %q, %v", data, err)
+	}
+}
@@ -0,0 +1,246 @@
+// releases.go - Ubuntu release support status (LTS, interim, EOL)
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ubuntuRelease is one row of the release support table.
+type ubuntuRelease struct {
+	Version     string    `json:"version"` // e.g. "24.04"
+	Codename    string    `json:"codename"`
+	LTS         bool      `json:"lts"`
+	EOL         time.Time `json:"eol,omitempty"`         // end of standard support
+	Unsupported bool      `json:"unsupported,omitempty"` // marked unsupported by a refresh
+}
+
+func releaseDate(year int, month time.Month) time.Time {
+	return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// bundledReleases is the built-in table; refreshReleasesCmd can extend it.
+var bundledReleases = []ubuntuRelease{
+	{Version: "18.04", Codename: "bionic", LTS: true, EOL: releaseDate(2023, time.June)},
+	{Version: "20.04", Codename: "focal", LTS: true, EOL: releaseDate(2025, time.June)},
+	{Version: "22.04", Codename: "jammy", LTS: true, EOL: releaseDate(2027, time.June)},
+	{Version: "23.10", Codename: "mantic", EOL: releaseDate(2024, time.July)},
+	{Version: "24.04", Codename: "noble", LTS: true, EOL: releaseDate(2029, time.June)},
+	{Version: "24.10", Codename: "oracular", EOL: releaseDate(2025, time.July)},
+	{Version: "25.04", Codename: "plucky", EOL: releaseDate(2026, time.February)},
+	{Version: "25.10", Codename: "questing", EOL: releaseDate(2026, time.July)},
+	{Version: "26.04", Codename: "resolute", LTS: true, EOL: releaseDate(2031, time.June)},
+}
+
+// activeReleases is the table in use: bundled, merged with any refresh.
+var activeReleases = bundledReleases
+
+// releaseStatus is the support state of a release.
+type releaseStatus int
+
+const (
+	releaseUnknown releaseStatus = iota
+	releaseLTS
+	releaseInterim
+	releaseEOL
+)
+
+func (s releaseStatus) String() string {
+	switch s {
+	case releaseLTS:
+		return "LTS"
+	case releaseInterim:
+		return "interim"
+	case releaseEOL:
+		return "EOL"
+	}
+	return ""
+}
+
+var releaseVersionRe = regexp.MustCompile(`\b(\d{2}\.\d{2})`)
+
+// lookupRelease finds a release by version ("22.04"), codename ("jammy")
+// or multipass's release string ("Ubuntu 22.04.5 LTS").
+func lookupRelease(s string) (ubuntuRelease, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	version := ""
+	if m := releaseVersionRe.FindStringSubmatch(s); m != nil {
+		version = m[1]
+	}
+	for _, r := range activeReleases {
+		if r.Version == version || r.Codename == s {
+			return r, true
+		}
+	}
+	return ubuntuRelease{}, false
+}
+
+// releaseStatusOf classifies s at time now.
+func releaseStatusOf(s string, now time.Time) releaseStatus {
+	r, ok := lookupRelease(s)
+	switch {
+	case !ok:
+		return releaseUnknown
+	case r.Unsupported || (!r.EOL.IsZero() && !now.Before(r.EOL)):
+		return releaseEOL
+	case r.LTS:
+		return releaseLTS
+	}
+	return releaseInterim
+}
+
+// releaseWarning explains why launching release is a bad idea, or returns "".
+func releaseWarning(release string, now time.Time) string {
+	if releaseStatusOf(release, now) != releaseEOL {
+		return ""
+	}
+	return fmt.Sprintf("Ubuntu %s is end-of-life and no longer gets security updates", release)
+}
+
+// eolInstances returns the names of non-deleted VMs running EOL releases.
+func eolInstances(vms []vmData, now time.Time) []string {
+	var names []string
+	for _, vm := range vms {
+		if vm.info.State == "Deleted" {
+			continue
+		}
+		if releaseStatusOf(vm.info.Release, now) == releaseEOL {
+			names = append(names, vm.info.Name)
+		}
+	}
+	return names
+}
+
+// ─── Refresh ───────────────────────────────────────────────────────────────────
+
+// metaReleaseURL lists every Ubuntu release with its support flag.
+const metaReleaseURL = "https://changelogs.ubuntu.com/meta-release"
+
+// releaseCacheMaxAge is how long a refreshed table is used before refetching.
+const releaseCacheMaxAge = 7 * 24 * time.Hour
+
+type releaseCache struct {
+	Fetched  time.Time       `json:"fetched"`
+	Releases []ubuntuRelease `json:"releases"`
+}
+
+// releasesRefreshedMsg carries a refreshed (or cached) release table.
+type releasesRefreshedMsg struct {
+	releases []ubuntuRelease
+	err      error
+}
+
+func releaseCachePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".passgo", "releases.json"), nil
+}
+
+// parseMetaRelease parses Ubuntu's meta-release stanzas (Dist, Version,
+// Supported…) into releases, without EOL dates.
+func parseMetaRelease(data string) []ubuntuRelease {
+	var out []ubuntuRelease
+	var cur ubuntuRelease
+	supported := true
+	flush := func() {
+		if cur.Version != "" {
+			cur.Unsupported = !supported
+			out = append(out, cur)
+		}
+		cur, supported = ubuntuRelease{}, true
+	}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			flush()
+			continue
+		}
+		key, val, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		val = strings.TrimSpace(val)
+		switch key {
+		case "Dist":
+			cur.Codename = strings.ToLower(val)
+		case "Version":
+			if m := releaseVersionRe.FindStringSubmatch(val); m != nil {
+				cur.Version = m[1]
+			}
+			cur.LTS = strings.Contains(val, "LTS")
+		case "Supported":
+			supported = val == "1"
+		}
+	}
+	flush()
+	return out
+}
+
+// mergeReleases overlays refreshed rows onto base, keeping base EOL dates.
+func mergeReleases(base, refreshed []ubuntuRelease) []ubuntuRelease {
+	merged := append([]ubuntuRelease(nil), base...)
+	for _, r := range refreshed {
+		found := false
+		for i := range merged {
+			if merged[i].Version == r.Version {
+				merged[i].Unsupported = r.Unsupported
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, r)
+		}
+	}
+	return merged
+}
+
+// refreshReleasesCmd loads the cached release table, refetching it from
+// Ubuntu when the cache is older than releaseCacheMaxAge.
+func refreshReleasesCmd() tea.Cmd {
+	return func() tea.Msg {
+		path, err := releaseCachePath()
+		if err != nil {
+			return releasesRefreshedMsg{err: err}
+		}
+		var cache releaseCache
+		if data, err := os.ReadFile(path); err == nil { // #nosec G304 -- path under the user's home dir
+			_ = json.Unmarshal(data, &cache)
+		}
+		if time.Since(cache.Fetched) < releaseCacheMaxAge && len(cache.Releases) > 0 {
+			return releasesRefreshedMsg{releases: mergeReleases(bundledReleases, cache.Releases)}
+		}
+
+		client := &http.Client{Timeout: 10 * time.Second}
+		resp, err := client.Get(metaReleaseURL)
+		if err != nil {
+			return releasesRefreshedMsg{err: err}
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return releasesRefreshedMsg{err: fmt.Errorf("meta-release: %s", resp.Status)}
+		}
+		var b strings.Builder
+		if _, err := bufio.NewReader(resp.Body).WriteTo(&b); err != nil {
+			return releasesRefreshedMsg{err: err}
+		}
+		cache = releaseCache{Fetched: time.Now(), Releases: parseMetaRelease(b.String())}
+		if data, err := json.MarshalIndent(cache, "", "  "); err == nil {
+			_ = os.MkdirAll(filepath.Dir(path), 0o750)
+			_ = os.WriteFile(path, data, 0o600)
+		}
+		return releasesRefreshedMsg{releases: mergeReleases(bundledReleases, cache.Releases)}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReleaseStatusOf(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		release string
+		want    releaseStatus
+	}{
+		{"24.04", releaseLTS},
+		{"noble", releaseLTS},
+		{"Ubuntu 22.04.5 LTS", releaseLTS},
+		{"Ubuntu 20.04.6 LTS", releaseEOL},
+		{"18.04", releaseEOL},
+		{"25.10", releaseInterim},
+		{"25.04", releaseEOL},
+		{"daily", releaseUnknown},
+		{"", releaseUnknown},
+	}
+	for _, tt := range tests {
+		if got := releaseStatusOf(tt.release, now); got != tt.want {
+			t.Errorf("releaseStatusOf(%q) = %v, want %v", tt.release, got, tt.want)
+		}
+	}
+	if releaseWarning("24.04", now) != "" || releaseWarning("20.04", now) == "" {
+		t.Error("releaseWarning should only warn for EOL releases")
+	}
+}
+
+func TestParseMetaReleaseAndMerge(t *testing.T) {
+	data := `Dist: focal
+Name: Focal Fossa
+Version: 20.04.6 LTS
+Supported: 0
+
+Dist: zesty
+Name: Zesty Zapus
+Version: 17.04
+Supported: 0
+
+Dist: resolute
+Version: 26.04 LTS
+Supported: 1
+`
+	got := parseMetaRelease(data)
+	if len(got) != 3 {
+		t.Fatalf("parsed %d releases, want 3: %+v", len(got), got)
+	}
+	if got[0].Version != "20.04" || !got[0].LTS || !got[0].Unsupported {
+		t.Errorf("focal = %+v", got[0])
+	}
+	if got[2].Codename != "resolute" || got[2].Unsupported {
+		t.Errorf("resolute = %+v", got[2])
+	}
+
+	base := []ubuntuRelease{{Version: "20.04", Codename: "focal", LTS: true, EOL: releaseDate(2025, time.June)}}
+	merged := mergeReleases(base, got)
+	if len(merged) != 3 || !merged[0].Unsupported || merged[0].EOL.IsZero() {
+		t.Errorf("merged = %+v", merged)
+	}
+	if base[0].Unsupported {
+		t.Error("mergeReleases modified its input")
+	}
+}
+
+func TestEOLInstances(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	vms := []vmData{
+		{info: VMInfo{Name: "old", State: "Running", Release: "Ubuntu 20.04.6 LTS"}},
+		{info: VMInfo{Name: "gone", State: "Deleted", Release: "Ubuntu 18.04.6 LTS"}},
+		{info: VMInfo{Name: "new", State: "Stopped", Release: "Ubuntu 24.04.1 LTS"}},
+	}
+	got := eolInstances(vms, now)
+	if len(got) != 1 || got[0] != "old" {
+		t.Errorf("eolInstances = %v, want [old]", got)
+	}
+}
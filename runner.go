@@ -0,0 +1,201 @@
+// runner.go - How the multipass CLI is reached: locally, over SSH, or inside WSL
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// multipassRunner starts the multipass CLI. Every multipass command passgo
+// runs goes through mpRunner, so tests can swap in a fake and users can
+// point passgo at a multipass that is not on this machine's PATH.
+type multipassRunner interface {
+	// run runs `multipass args...` under ctx. Nil streams are empty or
+	// discarded.
+	run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error
+	// interactive returns a command for a terminal session (multipass shell).
+	interactive(args []string) *exec.Cmd
+	// program is the local binary the transport needs on PATH.
+	program() string
+	// String describes the transport for logs and diagnostics.
+	String() string
+}
+
+// mpRunner is the transport in use, set from multipass-runner in .config.
+var mpRunner multipassRunner = localRunner{}
+
+// localRunner runs the multipass binary on this machine.
+type localRunner struct{}
+
+func (localRunner) run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	cmd := exec.CommandContext(ctx, "multipass", args...) // #nosec G204 -- multipass CLI wrapper
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, stdout, stderr
+	return cmd.Run()
+}
+
+func (localRunner) interactive(args []string) *exec.Cmd {
+	return exec.Command("multipass", args...) // #nosec G204 -- multipass CLI wrapper
+}
+
+func (localRunner) program() string { return "multipass" }
+func (localRunner) String() string  { return "local" }
+
+// sshRunner runs multipass on another host with `ssh HOST multipass …`,
+// using the user's own ssh setup (keys, agent, ~/.ssh/config). Paths in
+// commands refer to that host, except that a --cloud-init file is sent
+// over stdin.
+type sshRunner struct {
+	host string // [user@]host or an alias from ~/.ssh/config
+}
+
+func (r sshRunner) run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	args, userData, err := cloudInitOverStdin(args)
+	if err != nil {
+		return err
+	}
+	if userData != nil {
+		if stdin != nil {
+			return errors.New("cannot send a --cloud-init file and other input over ssh at once")
+		}
+		defer userData.Close()
+		stdin = userData
+	}
+	cmd := exec.CommandContext(ctx, "ssh", "-o", "BatchMode=yes", r.host, shellJoin("multipass", args)) // #nosec G204 -- host from the user's .config
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, stdout, stderr
+	return cmd.Run()
+}
+
+func (r sshRunner) interactive(args []string) *exec.Cmd {
+	return exec.Command("ssh", "-t", r.host, shellJoin("multipass", args)) // #nosec G204 -- host from the user's .config
+}
+
+func (sshRunner) program() string  { return "ssh" }
+func (r sshRunner) String() string { return "ssh:" + r.host }
+
+// wslRunner runs multipass inside a WSL distribution, for Windows users
+// who installed multipass there.
+type wslRunner struct {
+	distro string // "" for the default distribution
+}
+
+func (r wslRunner) command(ctx context.Context, args []string) *exec.Cmd {
+	wslArgs := []string{}
+	if r.distro != "" {
+		wslArgs = append(wslArgs, "-d", r.distro)
+	}
+	wslArgs = append(append(wslArgs, "--exec", "multipass"), args...)
+	return exec.CommandContext(ctx, "wsl.exe", wslArgs...) // #nosec G204 -- multipass CLI wrapper
+}
+
+func (r wslRunner) run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	cmd := r.command(ctx, args)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, stdout, stderr
+	return cmd.Run()
+}
+
+func (r wslRunner) interactive(args []string) *exec.Cmd { return r.command(context.Background(), args) }
+func (wslRunner) program() string                       { return "wsl.exe" }
+
+func (r wslRunner) String() string {
+	if r.distro == "" {
+		return "wsl"
+	}
+	return "wsl:" + r.distro
+}
+
+// parseMultipassRunner parses multipass-runner: local (the default),
+// ssh:[user@]host, wsl or wsl:DISTRO.
+func parseMultipassRunner(v string) (multipassRunner, error) {
+	v = strings.TrimSpace(v)
+	kind, arg, _ := strings.Cut(v, ":")
+	switch strings.ToLower(kind) {
+	case "", "local":
+		if arg != "" {
+			return nil, fmt.Errorf("multipass-runner %q: local takes no argument", v)
+		}
+		return localRunner{}, nil
+	case "ssh":
+		if arg == "" || strings.HasPrefix(arg, "-") || strings.ContainsAny(arg, " \t") {
+			return nil, fmt.Errorf("multipass-runner %q: want ssh:[user@]host", v)
+		}
+		return sshRunner{host: arg}, nil
+	case "wsl":
+		if strings.HasPrefix(arg, "-") || strings.ContainsAny(arg, " \t") {
+			return nil, fmt.Errorf("multipass-runner %q: want wsl or wsl:DISTRO", v)
+		}
+		return wslRunner{distro: arg}, nil
+	}
+	return nil, fmt.Errorf("multipass-runner %q: want local, ssh:HOST or wsl[:DISTRO]", v)
+}
+
+// loadMultipassRunner reads multipass-runner using lookup. An invalid value
+// keeps the local runner and is returned as an error to log.
+func loadMultipassRunner(lookup func(string) (string, bool)) (multipassRunner, error) {
+	v, ok := lookup("multipass-runner")
+	if !ok {
+		return localRunner{}, nil
+	}
+	r, err := parseMultipassRunner(v)
+	if err != nil {
+		return localRunner{}, err
+	}
+	return r, nil
+}
+
+// cloudInitOverStdin replaces the file after --cloud-init with "-" and
+// opens the file, so a remote multipass reads it from stdin. URLs and "-"
+// are left alone.
+func cloudInitOverStdin(args []string) ([]string, io.ReadCloser, error) {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] != "--cloud-init" {
+			continue
+		}
+		path := args[i+1]
+		if path == "-" || isHTTPURL(path) {
+			return args, nil, nil
+		}
+		f, err := os.Open(path) // #nosec G304 -- cloud-init file chosen by the user
+		if err != nil {
+			return nil, nil, err
+		}
+		out := append([]string(nil), args...)
+		out[i+1] = "-"
+		return out, f, nil
+	}
+	return args, nil, nil
+}
+
+// shellJoin quotes name and args for a POSIX shell, as ssh passes the
+// command line to the remote user's shell.
+func shellJoin(name string, args []string) string {
+	parts := []string{name}
+	for _, a := range args {
+		parts = append(parts, shellQuote(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuote single-quotes s unless it is made only of safe characters.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@,+") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// useMultipassRunner sets mpRunner from .config, logging a bad value.
+func useMultipassRunner(lookup func(string) (string, bool)) {
+	r, err := loadMultipassRunner(lookup)
+	if err != nil && appLogger != nil {
+		appLogger.Printf("ignoring %v; running multipass locally", err)
+	}
+	mpRunner = r
+	if _, local := r.(localRunner); !local && appLogger != nil {
+		appLogger.Printf("running multipass via %s", r)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeRunner answers multipass commands from a table instead of running them.
+type fakeRunner struct {
+	calls   [][]string
+	outputs map[string]string // first arg → stdout
+	fail    map[string]error
+}
+
+func (f *fakeRunner) run(_ context.Context, args []string, _ io.Reader, stdout, stderr io.Writer) error {
+	f.calls = append(f.calls, args)
+	if err := f.fail[args[0]]; err != nil {
+		_, _ = io.WriteString(stderr, "boom\n")
+		return err
+	}
+	if stdout != nil {
+		_, _ = io.WriteString(stdout, f.outputs[args[0]])
+	}
+	return nil
+}
+
+func (f *fakeRunner) interactive(args []string) *exec.Cmd { return exec.Command("true") }
+func (f *fakeRunner) program() string                     { return "fake" }
+func (f *fakeRunner) String() string                      { return "fake" }
+
+// useFakeRunner swaps mpRunner for the duration of a test.
+func useFakeRunner(t *testing.T, f *fakeRunner) {
+	t.Helper()
+	old := mpRunner
+	mpRunner = f
+	t.Cleanup(func() { mpRunner = old })
+}
+
+func TestRunMultipassCommandUsesRunner(t *testing.T) {
+	f := &fakeRunner{outputs: map[string]string{"version": "multipass 1.14.0\n"}, fail: map[string]error{"stop": errors.New("exit status 2")}}
+	useFakeRunner(t, f)
+
+	out, err := runMultipassCommand("version")
+	if err != nil || out != "multipass 1.14.0" {
+		t.Fatalf("version = %q, %v", out, err)
+	}
+	_, err = runMultipassCommand("stop", "web")
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("stop error = %v, want stderr in it", err)
+	}
+	var lines []string
+	if _, err := runMultipassCommandStreaming(func(l string) { lines = append(lines, l) }, "version"); err != nil || len(lines) != 1 {
+		t.Errorf("streaming lines = %v, %v", lines, err)
+	}
+	if want := [][]string{{"version"}, {"stop", "web"}, {"version"}}; !reflect.DeepEqual(f.calls, want) {
+		t.Errorf("calls = %v, want %v", f.calls, want)
+	}
+}
+
+func TestParseMultipassRunner(t *testing.T) {
+	tests := []struct {
+		in   string
+		want multipassRunner
+	}{
+		{"", localRunner{}},
+		{"local", localRunner{}},
+		{"ssh:admin@build-box", sshRunner{host: "admin@build-box"}},
+		{"wsl", wslRunner{}},
+		{"WSL:Ubuntu-24.04", wslRunner{distro: "Ubuntu-24.04"}},
+	}
+	for _, tt := range tests {
+		if got, err := parseMultipassRunner(tt.in); err != nil || got != tt.want {
+			t.Errorf("parseMultipassRunner(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"ssh:", "ssh:-oProxyCommand=x", "ssh:a b", "local:x", "docker"} {
+		if _, err := parseMultipassRunner(bad); err == nil {
+			t.Errorf("parseMultipassRunner(%q) should fail", bad)
+		}
+	}
+	if r, err := loadMultipassRunner(func(string) (string, bool) { return "bogus", true }); err == nil || r != (localRunner{}) {
+		t.Errorf("invalid value should fall back to local with an error, got %v, %v", r, err)
+	}
+}
+
+func TestShellJoin(t *testing.T) {
+	got := shellJoin("multipass", []string{"exec", "web", "--", "bash", "-c", "echo 'hi' $HOME"})
+	want := `multipass exec web -- bash -c 'echo '\''hi'\'' $HOME'`
+	if got != want {
+		t.Errorf("shellJoin = %s, want %s", got, want)
+	}
+	if got := shellQuote(""); got != "''" {
+		t.Errorf("shellQuote(\"\") = %s", got)
+	}
+}
+
+func TestCloudInitOverStdin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "user-data.yaml")
+	if err := os.WriteFile(path, []byte("#cloud-config\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	args, r, err := cloudInitOverStdin([]string{"launch", "--name", "web", "--cloud-init", path, "24.04"})
+	if err != nil || r == nil {
+		t.Fatalf("cloudInitOverStdin: %v", err)
+	}
+	defer r.Close()
+	if want := []string{"launch", "--name", "web", "--cloud-init", "-", "24.04"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+	if data, _ := io.ReadAll(r); string(data) != "#cloud-config\n" {
+		t.Errorf("stdin = %q", data)
+	}
+	for _, keep := range [][]string{{"launch", "--cloud-init", "https://example.com/u.yaml"}, {"list"}} {
+		if got, r, err := cloudInitOverStdin(keep); err != nil || r != nil || !reflect.DeepEqual(got, keep) {
+			t.Errorf("cloudInitOverStdin(%v) = %v, %v, %v", keep, got, r, err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// secrets.go - Injecting secrets into cloud-init templates at launch
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// secretPlaceholderRe matches ${secret:NAME} in a cloud-init template.
+var secretPlaceholderRe = regexp.MustCompile(`\$\{secret:([A-Za-z0-9_.-]+)\}`)
+
+// secretEnvPrefix is the environment fallback for secrets not in .config,
+// e.g. ${secret:github_token} reads PASSGO_SECRET_GITHUB_TOKEN.
+const secretEnvPrefix = "PASSGO_SECRET_"
+
+// resolveSecret looks up a secret by name. Sources are declared only in the
+// local .config, never in templates, so a shared template repo cannot make
+// passgo run commands or read arbitrary environment variables:
+//
+//	secret.github_token=env:GITHUB_TOKEN
+//	secret.db_password=cmd:pass show lab/db
+func resolveSecret(name string, lookup func(string) (string, bool)) (string, error) {
+	source, ok := lookup("secret." + name)
+	if !ok {
+		env := secretEnvPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(name))
+		if v, ok := os.LookupEnv(env); ok {
+			return v, nil
+		}
+		return "", fmt.Errorf("secret %q is not defined (add secret.%s to .config or set %s)", name, name, env)
+	}
+	kind, arg, _ := strings.Cut(source, ":")
+	switch kind {
+	case "env":
+		v, ok := os.LookupEnv(arg)
+		if !ok {
+			return "", fmt.Errorf("secret %q: environment variable %s is not set", name, arg)
+		}
+		return v, nil
+	case "cmd":
+		return runSecretCommand(arg)
+	default:
+		return "", fmt.Errorf("secret %q: source must start with env: or cmd:", name)
+	}
+}
+
+// runSecretCommand runs a secret helper (pass, op, vault…) through the shell
+// and returns its output without the trailing newline.
+func runSecretCommand(command string) (string, error) {
+	cmd := shellCommand(context.Background(), command)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// Never include stdout: it may hold a partial secret.
+		return "", fmt.Errorf("secret command failed: %v; %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+// renderSecrets replaces every ${secret:NAME} in content. Each secret is
+// resolved once. It reports whether any placeholder was found.
+func renderSecrets(content []byte, resolve func(string) (string, error)) ([]byte, bool, error) {
+	matches := secretPlaceholderRe.FindAllSubmatch(content, -1)
+	if len(matches) == 0 {
+		return content, false, nil
+	}
+	values := make(map[string]string)
+	for _, m := range matches {
+		name := string(m[1])
+		if _, done := values[name]; done {
+			continue
+		}
+		v, err := resolve(name)
+		if err != nil {
+			return nil, true, err
+		}
+		values[name] = v
+	}
+	out := secretPlaceholderRe.ReplaceAllFunc(content, func(match []byte) []byte {
+		name := string(secretPlaceholderRe.FindSubmatch(match)[1])
+		return []byte(values[name])
+	})
+	return out, true, nil
+}
+
+// prepareCloudInit renders secrets into a cloud-init file for launch. Files
+// without placeholders are used as-is. Otherwise the rendered copy goes to a
+// private (0600) temp file that cleanup removes; the template itself is never
+// modified.
+//
+// Without a template, the ssh-key from .config (if any) is authorized for the
+// default user through a generated user-data file.
+func prepareCloudInit(path string) (string, func(), error) {
+	if path == "" {
+		return sshKeyCloudInit(configValue)
+	}
+	content, err := os.ReadFile(path) // #nosec G304 -- cloud-init path chosen by the user
+	if err != nil {
+		return "", func() {}, fmt.Errorf("failed to read cloud-init file: %w", err)
+	}
+	rendered, found, err := renderSecrets(content, func(name string) (string, error) {
+		return resolveSecret(name, configValue)
+	})
+	if err != nil {
+		return "", func() {}, err
+	}
+	if !found {
+		return path, func() {}, nil
+	}
+	return writeTempCloudInit(rendered)
+}
+
+// sshKeyCloudInit writes user-data authorizing the ssh-key public key file
+// named in .config. It returns "" when no key is configured.
+func sshKeyCloudInit(lookup func(string) (string, bool)) (string, func(), error) {
+	keyPath, ok := lookup("ssh-key")
+	if !ok || strings.TrimSpace(keyPath) == "" {
+		return "", func() {}, nil
+	}
+	key, err := os.ReadFile(strings.TrimSpace(keyPath)) // #nosec G304 -- key path from the user's .config
+	if err != nil {
+		return "", func() {}, fmt.Errorf("failed to read ssh-key: %w", err)
+	}
+	userData := fmt.Sprintf("#cloud-config\nssh_authorized_keys:\n  - %s\n", strings.TrimSpace(string(key)))
+	return writeTempCloudInit([]byte(userData))
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderSecrets(t *testing.T) {
+	calls := 0
+	resolve := func(name string) (string, error) {
+		calls++
+		if name == "missing" {
+			return "", errors.New("not defined")
+		}
+		return "s3cr3t-" + name, nil
+	}
+
+	in := []byte("token: ${secret:api}\nagain: ${secret:api}\nhome: ${HOME}\n")
+	out, found, err := renderSecrets(in, resolve)
+	if err != nil || !found {
+		t.Fatalf("unexpected result: found=%v err=%v", found, err)
+	}
+	want := "token: s3cr3t-api\nagain: s3cr3t-api\nhome: ${HOME}\n"
+	if string(out) != want {
+		t.Fatalf("got %q, want %q", out, want)
+	}
+	if calls != 1 {
+		t.Fatalf("expected each secret to be resolved once, got %d calls", calls)
+	}
+
+	if _, found, _ := renderSecrets([]byte("plain: yes\n"), resolve); found {
+		t.Fatalf("expected no placeholders")
+	}
+	if _, _, err := renderSecrets([]byte("${secret:missing}"), resolve); err == nil {
+		t.Fatalf("expected error for unresolved secret")
+	}
+}
+
+func TestResolveSecret(t *testing.T) {
+	t.Setenv("LAB_TOKEN", "from-env")
+	t.Setenv("PASSGO_SECRET_DB_PASSWORD", "fallback")
+	config := map[string]string{
+		"secret.token": "env:LAB_TOKEN",
+		"secret.bad":   "file:/etc/shadow",
+	}
+	lookup := func(k string) (string, bool) { v, ok := config[k]; return v, ok }
+
+	if v, err := resolveSecret("token", lookup); err != nil || v != "from-env" {
+		t.Fatalf("expected env secret, got %q %v", v, err)
+	}
+	if v, err := resolveSecret("db-password", lookup); err != nil || v != "fallback" {
+		t.Fatalf("expected PASSGO_SECRET_ fallback, got %q %v", v, err)
+	}
+	if _, err := resolveSecret("bad", lookup); err == nil || !strings.Contains(err.Error(), "env: or cmd:") {
+		t.Fatalf("expected unsupported source error, got %v", err)
+	}
+	if _, err := resolveSecret("nope", lookup); err == nil {
+		t.Fatalf("expected undefined secret error")
+	}
+}
+
+func TestSSHKeyCloudInit(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519.pub")
+	if err := os.WriteFile(keyFile, []byte("ssh-ed25519 AAAA you@host\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path, cleanup, err := sshKeyCloudInit(func(k string) (string, bool) { return keyFile, k == "ssh-key" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "ssh_authorized_keys:\n  - ssh-ed25519 AAAA you@host\n") {
+		t.Fatalf("unexpected user-data %q", data)
+	}
+
+	if path, _, err := sshKeyCloudInit(func(string) (string, bool) { return "", false }); path != "" || err != nil {
+		t.Fatalf("no key configured: got %q, %v", path, err)
+	}
+}
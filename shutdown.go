@@ -0,0 +1,131 @@
+// shutdown.go - Quitting with operations in flight, signals and temp file cleanup
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tempTracker remembers the temp files and directories passgo created
+// (repo clones, rendered templates, gallery downloads), so whatever is still
+// around when passgo exits is removed even if the code that made it never
+// got to its cleanup.
+type tempTracker struct {
+	mu    sync.Mutex
+	paths map[string]struct{}
+}
+
+// tempArtifacts is the process-wide tracker.
+var tempArtifacts = &tempTracker{paths: make(map[string]struct{})}
+
+// add records path for removal at exit.
+func (t *tempTracker) add(path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.paths[path] = struct{}{}
+}
+
+// remove deletes path (recursively) and forgets it.
+func (t *tempTracker) remove(path string) {
+	t.mu.Lock()
+	delete(t.paths, path)
+	t.mu.Unlock()
+	_ = os.RemoveAll(path)
+}
+
+// removeAll deletes everything still recorded and returns the paths.
+func (t *tempTracker) removeAll() []string {
+	t.mu.Lock()
+	paths := make([]string, 0, len(t.paths))
+	for p := range t.paths {
+		paths = append(paths, p)
+	}
+	t.paths = make(map[string]struct{})
+	t.mu.Unlock()
+	sort.Strings(paths)
+	for _, p := range paths {
+		if appLogger != nil {
+			appLogger.Printf("cleanup temp path at exit: %s", p)
+		}
+		_ = os.RemoveAll(p)
+	}
+	return paths
+}
+
+// shutdownSignalMsg is sent when passgo gets SIGINT or SIGTERM.
+type shutdownSignalMsg struct{ sig os.Signal }
+
+// forwardSignals turns the first SIGINT or SIGTERM into a
+// shutdownSignalMsg, so quitting goes through the same in-flight check as
+// pressing q. A second signal kills the program.
+func forwardSignals(p *tea.Program) func() {
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		asked := false
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-sigs:
+				if asked {
+					p.Kill()
+					return
+				}
+				asked = true
+				p.Send(shutdownSignalMsg{sig: sig})
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
+
+// inFlight describes the operations that have not finished yet.
+func (m rootModel) inFlight() []string {
+	var ops []string
+	for name, b := range m.table.busyVMs {
+		ops = append(ops, fmt.Sprintf("%s %s (%s)", b.operation, name, time.Since(b.startTime).Round(time.Second)))
+	}
+	sort.Strings(ops)
+	if m.bulk.active() {
+		ops = append(ops, fmt.Sprintf("%s of %d VMs", m.bulk.operation, len(m.bulk.items)))
+	}
+	if m.currentView == viewLoading || (m.currentView == viewQuit && m.quit.from == viewLoading) {
+		ops = append(ops, m.loading.message)
+	}
+	return ops
+}
+
+// requestQuit quits now when nothing is running, and otherwise asks
+// whether to wait for the running operations or abandon them.
+func (m rootModel) requestQuit() (tea.Model, tea.Cmd) {
+	if m.currentView == viewQuit {
+		return m, nil
+	}
+	if len(m.inFlight()) == 0 {
+		return m, tea.Quit
+	}
+	m.quit = newQuitModel(m.currentView)
+	m.setChildSizes()
+	m.currentView = viewQuit
+	return m, nil
+}
+
+// quitIfIdle quits once the operations the user chose to wait for are done.
+func (m rootModel) quitIfIdle() tea.Cmd {
+	if m.quit.waiting && len(m.inFlight()) == 0 {
+		return tea.Quit
+	}
+	return nil
+}
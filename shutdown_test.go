@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTempTracker(t *testing.T) {
+	tr := &tempTracker{paths: make(map[string]struct{})}
+	dir := t.TempDir()
+	kept := filepath.Join(dir, "kept")
+	done := filepath.Join(dir, "done")
+	for _, p := range []string{kept, done} {
+		if err := os.MkdirAll(filepath.Join(p, "sub"), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		tr.add(p)
+	}
+	tr.remove(done)
+	if _, err := os.Stat(done); !os.IsNotExist(err) {
+		t.Errorf("remove left %s", done)
+	}
+	if got := tr.removeAll(); !reflect.DeepEqual(got, []string{kept}) {
+		t.Errorf("removeAll = %v, want [%s]", got, kept)
+	}
+	if _, err := os.Stat(kept); !os.IsNotExist(err) {
+		t.Errorf("removeAll left %s", kept)
+	}
+	if got := tr.removeAll(); len(got) != 0 {
+		t.Errorf("second removeAll = %v", got)
+	}
+}
+
+func TestRequestQuit(t *testing.T) {
+	m := rootModel{table: newTableModel(), state: newAppState()}
+	if _, cmd := m.requestQuit(); cmd == nil {
+		t.Fatal("an idle passgo should quit at once")
+	}
+
+	m.table.busyVMs["web"] = busyInfo{operation: "Stopping", startTime: time.Now()}
+	next, cmd := m.requestQuit()
+	m = next.(rootModel)
+	if cmd != nil || m.currentView != viewQuit || m.quit.from != viewTable {
+		t.Fatalf("expected the quit prompt, got view %v", m.currentView)
+	}
+	if ops := m.inFlight(); len(ops) != 1 {
+		t.Errorf("inFlight = %v", ops)
+	}
+
+	next, _ = m.Update(quitChoiceMsg{wait: true})
+	m = next.(rootModel)
+	if !m.quit.waiting {
+		t.Fatal("wait should be recorded")
+	}
+	delete(m.table.busyVMs, "web")
+	_, cmd = m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	if cmd == nil {
+		t.Fatal("expected to quit once idle")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Errorf("expected tea.Quit")
+	}
+
+	m.quit = newQuitModel(viewInfo)
+	m.currentView = viewQuit
+	next, _ = m.Update(quitChoiceMsg{})
+	if got := next.(rootModel).currentView; got != viewInfo {
+		t.Errorf("cancel went to view %v, want the previous view", got)
+	}
+}
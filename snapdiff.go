@@ -0,0 +1,221 @@
+// snapdiff.go - Compare a snapshot with a VM's current state, file by file
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultComparePaths are the directories compared unless others are given.
+var defaultComparePaths = []string{"/etc", "/home", "/opt", "/usr/local"}
+
+// compareManifestScript prints "<sha256>  <path>" for every regular file
+// under the paths given as arguments, staying on their file systems.
+// Unreadable and missing paths are skipped.
+const compareManifestScript = `for p in "$@"; do
+  sudo find "$p" -xdev -type f -print0 2>/dev/null | sudo xargs -0 -r sha256sum 2>/dev/null
+done
+true`
+
+// parseComparePaths splits the paths to compare (spaces or commas) and
+// rejects relative ones.
+func parseComparePaths(s string) ([]string, error) {
+	paths := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(paths) == 0 {
+		return nil, errors.New("enter at least one path")
+	}
+	for _, p := range paths {
+		if !strings.HasPrefix(p, "/") {
+			return nil, fmt.Errorf("%q is not an absolute path", p)
+		}
+	}
+	return paths, nil
+}
+
+// snapshotDiff is what changed in the compared paths since a snapshot.
+type snapshotDiff struct {
+	vm, snap string
+	paths    []string
+	added    []string // files that exist now but not at the snapshot
+	removed  []string
+	changed  []string
+	files    int // files looked at, in either state
+}
+
+// empty reports whether nothing changed.
+func (d snapshotDiff) empty() bool {
+	return len(d.added) == 0 && len(d.removed) == 0 && len(d.changed) == 0
+}
+
+// summary is a one-line count, e.g. "3 changed, 1 added, 0 removed".
+func (d snapshotDiff) summary() string {
+	return fmt.Sprintf("%d changed, %d added, %d removed", len(d.changed), len(d.added), len(d.removed))
+}
+
+// report lists every changed file, git-style: M, A or D and the path.
+func (d snapshotDiff) report() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: snapshot %s vs now, in %s\n", d.vm, d.snap, strings.Join(d.paths, " "))
+	fmt.Fprintf(&b, "%s (of %d files)\n", d.summary(), d.files)
+	type entry struct{ mark, path string }
+	var entries []entry
+	for _, p := range d.changed {
+		entries = append(entries, entry{"M", p})
+	}
+	for _, p := range d.added {
+		entries = append(entries, entry{"A", p})
+	}
+	for _, p := range d.removed {
+		entries = append(entries, entry{"D", p})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].path < entries[j].path })
+	if len(entries) > 0 {
+		b.WriteString("\n")
+	}
+	for _, e := range entries {
+		fmt.Fprintf(&b, "%s %s\n", e.mark, e.path)
+	}
+	return b.String()
+}
+
+// parseManifest reads compareManifestScript output into path → checksum.
+func parseManifest(out string) map[string]string {
+	files := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		sum, path, ok := strings.Cut(line, "  ")
+		if ok && len(sum) == 64 {
+			files[path] = sum
+		}
+	}
+	return files
+}
+
+// diffManifests compares the files at a snapshot with the files now.
+func diffManifests(before, after map[string]string) (added, removed, changed []string) {
+	for path, sum := range after {
+		old, ok := before[path]
+		switch {
+		case !ok:
+			added = append(added, path)
+		case old != sum:
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			removed = append(removed, path)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+	return added, removed, changed
+}
+
+// compareCloneNames returns the names of the temporary clones holding vm
+// as it was at the snapshot and as it is now, if policy allows them.
+func compareCloneNames(vm string, policy namingPolicy) (was, now string, err error) {
+	suffix := randomString(4)
+	was, now = vm+"-was-"+suffix, vm+"-now-"+suffix
+	for _, name := range []string{was, now} {
+		if err := policy.check(name); err != nil {
+			return "", "", fmt.Errorf("temporary clone: %w", err)
+		}
+	}
+	return was, now, nil
+}
+
+// CompareSnapshot reports which files under paths differ between the
+// stopped VM vm at snapshot snap and now. vm is cloned twice: first as it
+// is, then as it was at the snapshot, for which it is briefly restored to
+// snap and put back from a holding snapshot (see CloneFromSnapshot). Both
+// clones are started and their files checksummed, and the clones are
+// purged afterwards. step, if set, is told what is happening.
+func CompareSnapshot(vm, snap string, paths []string, was, now string, step func(string)) (diff snapshotDiff, err error) {
+	diff = snapshotDiff{vm: vm, snap: snap, paths: paths}
+	info, err := GetVMInfo(vm)
+	if err != nil {
+		return diff, err
+	}
+	if state := parseVMInfo(info).State; state != "Stopped" {
+		return diff, fmt.Errorf("%s is %s: stop it to compare its snapshots", vm, strings.ToLower(state))
+	}
+	say := func(format string, args ...any) {
+		if step != nil {
+			step(fmt.Sprintf(format, args...))
+		}
+	}
+	var clones []string
+	defer func() {
+		for _, name := range clones {
+			say("deleting %s", name)
+			if _, derr := runMultipassCommand("delete", "--purge", name); derr != nil {
+				err = errors.Join(err, fmt.Errorf("deleting the temporary clone %s: %w", name, derr))
+			}
+		}
+	}()
+
+	// The current state is cloned before vm is touched, so a failure while
+	// it is restored to snap cannot leak into the "now" side.
+	say("cloning %s as it is now", vm)
+	if _, err := runMultipassCommand("clone", vm, "--name", now); err != nil {
+		// A failed clone may still have left the instance behind.
+		if instanceExists(now) {
+			clones = append(clones, now)
+		}
+		return diff, fmt.Errorf("cloning %s: %w", vm, err)
+	}
+	clones = append(clones, now)
+	say("cloning %s at %s (restoring it to %s and back)", vm, snap, snap)
+	if err = CloneFromSnapshot(vm, snap, []string{was}, time.Now()); err != nil {
+		if instanceExists(was) {
+			clones = append(clones, was)
+		}
+		return diff, err
+	}
+	clones = append(clones, was)
+
+	manifests := make([]map[string]string, 2)
+	for i, name := range []string{was, now} {
+		say("starting %s", name)
+		if _, err := StartVM(name); err != nil {
+			return diff, fmt.Errorf("starting %s: %w", name, err)
+		}
+		say("checksumming files in %s", name)
+		out, err := ExecInVM(name, append([]string{"bash", "-c", compareManifestScript, "passgo-compare"}, paths...)...)
+		if err != nil {
+			return diff, fmt.Errorf("listing files in %s: %w", name, err)
+		}
+		manifests[i] = parseManifest(out)
+	}
+	diff.added, diff.removed, diff.changed = diffManifests(manifests[0], manifests[1])
+	diff.files = len(manifests[1]) + len(diff.removed)
+	return diff, nil
+}
+
+// instanceExists reports whether multipass knows an instance called name.
+func instanceExists(name string) bool {
+	_, err := GetVMInfo(name)
+	return err == nil
+}
+
+// ─── TUI ───────────────────────────────────────────────────────────────────────
+
+// snapCompareResultMsg reports a finished comparison.
+type snapCompareResultMsg struct {
+	diff   snapshotDiff
+	clones []string
+	err    error
+}
+
+func compareSnapshotCmd(vm, snap string, paths []string, was, now string) tea.Cmd {
+	return func() tea.Msg {
+		diff, err := CompareSnapshot(vm, snap, paths, was, now, nil)
+		return snapCompareResultMsg{diff: diff, clones: []string{was, now}, err: err}
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const (
+	sumA = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	sumB = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+func TestParseManifest(t *testing.T) {
+	out := sumA + "  /etc/hosts\n" + sumB + "  /etc/my file.conf\nsha256sum: /etc/shadow: Permission denied\n\n"
+	got := parseManifest(out)
+	if len(got) != 2 || got["/etc/hosts"] != sumA || got["/etc/my file.conf"] != sumB {
+		t.Fatalf("parseManifest = %v", got)
+	}
+}
+
+func TestDiffManifests(t *testing.T) {
+	before := map[string]string{"/etc/hosts": sumA, "/etc/gone": sumA, "/etc/same": sumB}
+	after := map[string]string{"/etc/hosts": sumB, "/etc/new": sumA, "/etc/same": sumB}
+	added, removed, changed := diffManifests(before, after)
+	if strings.Join(added, ",") != "/etc/new" || strings.Join(removed, ",") != "/etc/gone" || strings.Join(changed, ",") != "/etc/hosts" {
+		t.Fatalf("added %v, removed %v, changed %v", added, removed, changed)
+	}
+}
+
+func TestSnapshotDiffReport(t *testing.T) {
+	d := snapshotDiff{vm: "db", snap: "s1", paths: []string{"/etc"}, added: []string{"/etc/b"}, removed: []string{"/etc/c"}, changed: []string{"/etc/a"}, files: 9}
+	want := "db: snapshot s1 vs now, in /etc\n1 changed, 1 added, 1 removed (of 9 files)\n\nM /etc/a\nA /etc/b\nD /etc/c\n"
+	if got := d.report(); got != want {
+		t.Fatalf("report =\n%s\nwant\n%s", got, want)
+	}
+	if d.empty() || !(snapshotDiff{}).empty() {
+		t.Fatal("empty is wrong")
+	}
+}
+
+func TestParseComparePaths(t *testing.T) {
+	got, err := parseComparePaths("/etc, /home /srv/app")
+	if err != nil || strings.Join(got, " ") != "/etc /home /srv/app" {
+		t.Fatalf("got %v, %v", got, err)
+	}
+	for _, s := range []string{"", " , ", "/etc etc"} {
+		if _, err := parseComparePaths(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestCompareSnapshot(t *testing.T) {
+	f := &fakeRunner{outputs: map[string]string{
+		"info": "Name: db\nState: Stopped\n",
+		"exec": sumA + "  /etc/hosts\n",
+	}}
+	useFakeRunner(t, f)
+
+	var steps []string
+	diff, err := CompareSnapshot("db", "s1", []string{"/etc"}, "db-was-x", "db-now-x", func(s string) { steps = append(steps, s) })
+	if err != nil || !diff.empty() || diff.files != 1 {
+		t.Fatalf("diff = %+v, %v", diff, err)
+	}
+	var got []string
+	for _, c := range f.calls {
+		got = append(got, strings.Join(c, " "))
+	}
+	joined := strings.Join(got, "\n")
+	for _, want := range []string{"clone db --name db-was-x", "clone db --name db-now-x", "start db-was-x", "start db-now-x", "delete --purge db-was-x", "delete --purge db-now-x"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("missing %q in calls:\n%s", want, joined)
+		}
+	}
+	if !strings.HasPrefix(got[len(got)-1], "delete --purge") {
+		t.Errorf("the clones should be deleted last, calls:\n%s", joined)
+	}
+	nowClone := slices.Index(got, "clone db --name db-now-x")
+	hold := slices.IndexFunc(got, func(c string) bool { return strings.HasPrefix(c, "snapshot ") })
+	if nowClone < 0 || hold < 0 || nowClone > hold {
+		t.Errorf("db should be cloned as it is before it is restored, calls:\n%s", joined)
+	}
+	if len(steps) == 0 {
+		t.Error("no progress steps reported")
+	}
+}
+
+func TestCompareCloneNames(t *testing.T) {
+	was, now, err := compareCloneNames("db", namingPolicy{})
+	if err != nil || !strings.HasPrefix(was, "db-was-") || !strings.HasPrefix(now, "db-now-") {
+		t.Fatalf("names %q %q, %v", was, now, err)
+	}
+	values := map[string]string{"name-max-length": "10"}
+	policy := loadNamingPolicy(func(k string) (string, bool) { v, ok := values[k]; return v, ok })
+	if _, _, err := compareCloneNames("database", policy); err == nil {
+		t.Error("clone names longer than name-max-length should be refused")
+	}
+}
+
+func TestCompareSnapshotCleansUpAfterFailure(t *testing.T) {
+	f := &fakeRunner{
+		outputs: map[string]string{"info": "Name: db\nState: Stopped\n"},
+		fail:    map[string]error{"exec": errors.New("exit status 1")},
+	}
+	useFakeRunner(t, f)
+
+	if _, err := CompareSnapshot("db", "s1", []string{"/etc"}, "db-was-x", "db-now-x", nil); err == nil {
+		t.Fatal("expected the exec failure to be reported")
+	}
+	deleted := 0
+	for _, c := range f.calls {
+		if c[0] == "delete" && strings.HasPrefix(c[len(c)-1], "db-") {
+			deleted++
+		}
+	}
+	if deleted != 2 {
+		t.Fatalf("deleted %d clones, want 2; calls %v", deleted, f.calls)
+	}
+}
+
+func TestCompareSnapshotNeedsStoppedVM(t *testing.T) {
+	f := &fakeRunner{outputs: map[string]string{"info": "Name: db\nState: Running\n"}}
+	useFakeRunner(t, f)
+
+	_, err := CompareSnapshot("db", "s1", []string{"/etc"}, "db-was-x", "db-now-x", nil)
+	if err == nil || !strings.Contains(err.Error(), "stop it") {
+		t.Fatalf("err = %v", err)
+	}
+	if len(f.calls) != 1 {
+		t.Fatalf("calls = %v, want only info", f.calls)
+	}
+}
@@ -1,2 +1,215 @@
 // snapshot_operations.go - Snapshot data logic (no UI code)
 package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// snapshotDetailsJSON is the shape of `multipass info --snapshots --format json`.
+type snapshotDetailsJSON struct {
+	Info map[string]struct {
+		Snapshots map[string]struct {
+			Comment string `json:"comment"`
+			Created string `json:"created"`
+			Parent  string `json:"parent"`
+		} `json:"snapshots"`
+	} `json:"info"`
+}
+
+// parseSnapshotDetailsJSON parses snapshot details for all instances,
+// sorted by instance and then creation time.
+func parseSnapshotDetailsJSON(output string) ([]SnapshotInfo, error) {
+	var details snapshotDetailsJSON
+	if err := json.Unmarshal([]byte(output), &details); err != nil {
+		return nil, err
+	}
+	var snaps []SnapshotInfo
+	for instance, info := range details.Info {
+		for name, s := range info.Snapshots {
+			snap := SnapshotInfo{Instance: instance, Name: name, Parent: s.Parent, Comment: s.Comment}
+			if t, err := time.Parse(time.RFC3339Nano, s.Created); err == nil {
+				snap.Created = t
+			}
+			snaps = append(snaps, snap)
+		}
+	}
+	sort.Slice(snaps, func(i, j int) bool {
+		if snaps[i].Instance != snaps[j].Instance {
+			return snaps[i].Instance < snaps[j].Instance
+		}
+		if !snaps[i].Created.Equal(snaps[j].Created) {
+			return snaps[i].Created.Before(snaps[j].Created)
+		}
+		return snaps[i].Name < snaps[j].Name
+	})
+	return snaps, nil
+}
+
+// fetchAllSnapshots returns every instance's snapshots, with creation times
+// when multipass provides them.
+func fetchAllSnapshots() ([]SnapshotInfo, error) {
+	if output, err := GetSnapshotDetailsJSON(); err == nil {
+		if snaps, err := parseSnapshotDetailsJSON(output); err == nil {
+			return snaps, nil
+		} else if appLogger != nil {
+			appLogger.Printf("snapshot details parse failed, falling back: %v", err)
+		}
+	}
+	output, err := ListSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	return parseSnapshots(output), nil
+}
+
+// ─── Search ────────────────────────────────────────────────────────────────────
+
+// snapshotQuery filters snapshots. Free words must all appear (case
+// insensitively) in the VM name, snapshot name or comment; vm:, after: and
+// before: narrow by instance and creation date (YYYY-MM-DD, local time).
+type snapshotQuery struct {
+	words  []string
+	vm     string
+	after  time.Time // inclusive
+	before time.Time // exclusive (start of the given day)
+}
+
+const snapshotQueryDate = "2006-01-02"
+
+// parseSnapshotQuery parses e.g. `postgres vm:db after:2026-01-01`.
+func parseSnapshotQuery(s string) (snapshotQuery, error) {
+	var q snapshotQuery
+	for _, tok := range strings.Fields(s) {
+		key, val, ok := strings.Cut(tok, ":")
+		switch {
+		case ok && key == "vm":
+			q.vm = strings.ToLower(val)
+		case ok && (key == "after" || key == "before"):
+			t, err := time.ParseInLocation(snapshotQueryDate, val, time.Local)
+			if err != nil {
+				return q, fmt.Errorf("%s: use a date like %s", key, snapshotQueryDate)
+			}
+			if key == "after" {
+				q.after = t
+			} else {
+				q.before = t
+			}
+		default:
+			q.words = append(q.words, strings.ToLower(tok))
+		}
+	}
+	return q, nil
+}
+
+// dated reports whether the query filters by creation date.
+func (q snapshotQuery) dated() bool {
+	return !q.after.IsZero() || !q.before.IsZero()
+}
+
+// matches reports whether s satisfies the query. Snapshots without a
+// creation time never match a date filter.
+func (q snapshotQuery) matches(s SnapshotInfo) bool {
+	if q.vm != "" && !strings.Contains(strings.ToLower(s.Instance), q.vm) {
+		return false
+	}
+	if q.dated() {
+		if s.Created.IsZero() ||
+			(!q.after.IsZero() && s.Created.Before(q.after)) ||
+			(!q.before.IsZero() && !s.Created.Before(q.before)) {
+			return false
+		}
+	}
+	haystack := strings.ToLower(s.Instance + " " + s.Name + " " + s.Comment)
+	for _, w := range q.words {
+		if !strings.Contains(haystack, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterSnapshots returns the snapshots matching q, in order.
+func filterSnapshots(snaps []SnapshotInfo, q snapshotQuery) []SnapshotInfo {
+	var out []SnapshotInfo
+	for _, s := range snaps {
+		if q.matches(s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// ─── Restore Preview ───────────────────────────────────────────────────────────
+
+// restorePreview describes what restoring vm to target discards: the
+// snapshot's age, later snapshots (kept, but no longer the current line),
+// how much unsnapshotted work is lost and, if known, the current uptime.
+func restorePreview(vm VMInfo, target string, snaps []SnapshotInfo, uptime time.Duration, now time.Time) []string {
+	var lines []string
+	var snap SnapshotInfo
+	var latest SnapshotInfo
+	found := false
+	for _, s := range snaps {
+		if s.Name == target {
+			snap, found = s, true
+		}
+		if s.Created.After(latest.Created) {
+			latest = s
+		}
+	}
+	if found && !snap.Created.IsZero() {
+		lines = append(lines, fmt.Sprintf("Snapshot taken: %s (%s ago)",
+			snap.Created.Local().Format("2006-01-02 15:04"), formatRemaining(now.Sub(snap.Created))))
+	}
+
+	var children, newer []string
+	for _, s := range snaps {
+		if s.Parent == target {
+			children = append(children, s.Name)
+		}
+		if found && !snap.Created.IsZero() && s.Created.After(snap.Created) {
+			newer = append(newer, s.Name)
+		}
+	}
+	if len(children) > 0 {
+		lines = append(lines, "Descends from it: "+strings.Join(children, ", "))
+	}
+	if len(newer) > 0 {
+		lines = append(lines, fmt.Sprintf("Newer snapshots (kept): %s", strings.Join(newer, ", ")))
+	}
+
+	switch {
+	case latest.Created.IsZero():
+		lines = append(lines, "Lost: all changes since the snapshot")
+	case latest.Name == target:
+		lines = append(lines, fmt.Sprintf("Lost: changes made in the last %s (since this snapshot)", formatRemaining(now.Sub(latest.Created))))
+	default:
+		lines = append(lines, fmt.Sprintf("Lost: changes made in the last %s (since snapshot %s)", formatRemaining(now.Sub(latest.Created)), latest.Name))
+	}
+
+	if vm.State == "Running" {
+		if uptime > 0 {
+			lines = append(lines, "Uptime: "+formatRemaining(uptime))
+		}
+		lines = append(lines, "VM is running: multipass only restores stopped instances")
+	}
+	return lines
+}
+
+// parseProcUptime parses /proc/uptime ("12345.67 54321.00").
+func parseProcUptime(s string) (time.Duration, bool) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return 0, false
+	}
+	secs, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(secs * float64(time.Second)), true
+}
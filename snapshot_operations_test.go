@@ -0,0 +1,130 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+const snapshotDetailsSample = `{
+    "errors": [],
+    "info": {
+        "db": {
+            "snapshots": {
+                "pre-upgrade": {"comment": "before the postgres upgrade", "created": "2026-02-10T09:00:00.000Z", "parent": ""},
+                "nightly": {"comment": "", "created": "2026-03-01T02:00:00.000Z", "parent": "pre-upgrade"}
+            }
+        },
+        "web": {
+            "snapshots": {
+                "clean": {"comment": "fresh install", "created": "2026-01-05T12:00:00.000Z", "parent": ""}
+            }
+        }
+    }
+}`
+
+func TestParseSnapshotDetailsJSON(t *testing.T) {
+	snaps, err := parseSnapshotDetailsJSON(snapshotDetailsSample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 3 {
+		t.Fatalf("got %d snapshots, want 3", len(snaps))
+	}
+	if snaps[0].Instance != "db" || snaps[0].Name != "pre-upgrade" || snaps[1].Parent != "pre-upgrade" || snaps[2].Instance != "web" {
+		t.Fatalf("unexpected order or fields: %+v", snaps)
+	}
+	if want := time.Date(2026, time.February, 10, 9, 0, 0, 0, time.UTC); !snaps[0].Created.Equal(want) {
+		t.Fatalf("Created = %v, want %v", snaps[0].Created, want)
+	}
+}
+
+func TestFilterSnapshots(t *testing.T) {
+	snaps, err := parseSnapshotDetailsJSON(snapshotDetailsSample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := func(query string) []string {
+		q, err := parseSnapshotQuery(query)
+		if err != nil {
+			t.Fatalf("parseSnapshotQuery(%q): %v", query, err)
+		}
+		var out []string
+		for _, s := range filterSnapshots(snaps, q) {
+			out = append(out, s.Instance+"."+s.Name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"db.pre-upgrade", "db.nightly", "web.clean"}},
+		{"Postgres", []string{"db.pre-upgrade"}},
+		{"vm:web", []string{"web.clean"}},
+		{"after:2026-02-01", []string{"db.pre-upgrade", "db.nightly"}},
+		{"after:2026-02-01 before:2026-03-01", []string{"db.pre-upgrade"}},
+		{"vm:db fresh", nil},
+	}
+	for _, tt := range tests {
+		got := names(tt.query)
+		if len(got) != len(tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.query, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%q: got %v, want %v", tt.query, got, tt.want)
+				break
+			}
+		}
+	}
+
+	if _, err := parseSnapshotQuery("after:yesterday"); err == nil {
+		t.Error("expected an error for a malformed date")
+	}
+	undated := SnapshotInfo{Instance: "old", Name: "x"}
+	if q, _ := parseSnapshotQuery("after:2020-01-01"); q.matches(undated) {
+		t.Error("snapshots without a creation time should not match date filters")
+	}
+}
+
+func TestRestorePreview(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	snaps := []SnapshotInfo{
+		{Instance: "db", Name: "base", Created: now.Add(-72 * time.Hour)},
+		{Instance: "db", Name: "pre-upgrade", Parent: "base", Created: now.Add(-24 * time.Hour)},
+		{Instance: "db", Name: "nightly", Parent: "pre-upgrade", Created: now.Add(-2 * time.Hour)},
+	}
+	lines := restorePreview(VMInfo{Name: "db", State: "Stopped"}, "base", snaps, 0, now)
+	want := []string{
+		"Descends from it: pre-upgrade",
+		"Newer snapshots (kept): pre-upgrade, nightly",
+		"Lost: changes made in the last 2h0m (since snapshot nightly)",
+	}
+	for _, w := range want {
+		if !containsLine(lines, w) {
+			t.Errorf("missing %q in %q", w, lines)
+		}
+	}
+
+	lines = restorePreview(VMInfo{Name: "db", State: "Running"}, "nightly", snaps, 90*time.Minute, now)
+	for _, w := range []string{"Lost: changes made in the last 2h0m (since this snapshot)", "Uptime: 1h30m"} {
+		if !containsLine(lines, w) {
+			t.Errorf("missing %q in %q", w, lines)
+		}
+	}
+
+	if d, ok := parseProcUptime("5400.25 10000.00\n"); !ok || d != 5400250*time.Millisecond {
+		t.Errorf("parseProcUptime = %v, %v", d, ok)
+	}
+}
+
+func containsLine(lines []string, want string) bool {
+	for _, l := range lines {
+		if l == want {
+			return true
+		}
+	}
+	return false
+}
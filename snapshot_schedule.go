@@ -0,0 +1,357 @@
+// snapshot_schedule.go - Scheduled snapshots with pruning, from .config
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// snapshotSchedule snapshots a VM every interval and keeps the newest keep
+// scheduled snapshots. Configured in .config, one line per VM:
+//
+//	snapshots: {vm: db-test, every: 24h, keep: 7}
+//	snapshots: {vm: web, every: 6h, keep: 4, stop: true}
+//
+// Multipass only snapshots stopped instances; with stop: true a running VM
+// is stopped for the snapshot and started again, otherwise it is skipped
+// until it is next stopped.
+type snapshotSchedule struct {
+	vm    string
+	every time.Duration
+	keep  int
+	stop  bool
+}
+
+// scheduledSnapshotPrefix marks snapshots owned by a schedule; only these
+// are ever pruned.
+const scheduledSnapshotPrefix = "auto-"
+
+// scheduledSnapshotLayout timestamps scheduled snapshot names (UTC).
+const scheduledSnapshotLayout = "20060102-1504"
+
+// snapshotScheduleInterval is how often schedules are evaluated.
+const snapshotScheduleInterval = time.Minute
+
+// parseSnapshotSchedule parses "{vm: db-test, every: 24h, keep: 7}".
+func parseSnapshotSchedule(s string) (snapshotSchedule, error) {
+	sched := snapshotSchedule{keep: 7}
+	body := strings.TrimSpace(s)
+	body = strings.TrimSuffix(strings.TrimPrefix(body, "{"), "}")
+	for _, part := range strings.Split(body, ",") {
+		key, val, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		switch key {
+		case "vm":
+			sched.vm = val
+		case "every":
+			d, err := parseTTL(val)
+			if err != nil || d <= 0 {
+				return sched, fmt.Errorf("snapshots %s: invalid every %q", s, val)
+			}
+			sched.every = d
+		case "keep":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return sched, fmt.Errorf("snapshots %s: keep must be at least 1", s)
+			}
+			sched.keep = n
+		case "stop":
+			sched.stop = parseConfigBool(val)
+		default:
+			return sched, fmt.Errorf("snapshots %s: unknown key %q", s, key)
+		}
+	}
+	if sched.vm == "" || sched.every == 0 {
+		return sched, fmt.Errorf("snapshots %s: vm and every are required", s)
+	}
+	return sched, nil
+}
+
+// loadSnapshotSchedules parses every snapshots entry, skipping invalid ones.
+func loadSnapshotSchedules(values []string) ([]snapshotSchedule, []error) {
+	var schedules []snapshotSchedule
+	var errs []error
+	for _, v := range values {
+		s, err := parseSnapshotSchedule(v)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, errs
+}
+
+// scheduledSnapshotTime returns when a scheduled snapshot was taken.
+func scheduledSnapshotTime(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, scheduledSnapshotPrefix) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(scheduledSnapshotLayout, strings.TrimPrefix(name, scheduledSnapshotPrefix))
+	return t, err == nil
+}
+
+// planSchedule decides whether s is due and which of the VM's scheduled
+// snapshots to prune so that, counting a new one if due, keep remain.
+func planSchedule(s snapshotSchedule, snaps []SnapshotInfo, now time.Time) (due bool, prune []string) {
+	type dated struct {
+		name string
+		at   time.Time
+	}
+	var owned []dated
+	for _, snap := range snaps {
+		if snap.Instance != s.vm {
+			continue
+		}
+		if at, ok := scheduledSnapshotTime(snap.Name); ok {
+			owned = append(owned, dated{snap.Name, at})
+		}
+	}
+	sort.Slice(owned, func(i, j int) bool { return owned[i].at.After(owned[j].at) })
+
+	due = len(owned) == 0 || now.Sub(owned[0].at) >= s.every
+	keep := s.keep
+	if due {
+		keep--
+	}
+	for i := keep; i < len(owned); i++ {
+		prune = append(prune, owned[i].name)
+	}
+	return due, prune
+}
+
+// snapshotScheduleReport summarises one evaluation of all schedules.
+type snapshotScheduleReport struct {
+	created []string // "vm.snapshot"
+	pruned  []string
+	skipped []string // VMs that were due but running (without stop: true)
+	errs    []error
+}
+
+// runSnapshotSchedules evaluates schedules against the VMs' current states
+// (by name), creating due snapshots and pruning old ones.
+func runSnapshotSchedules(schedules []snapshotSchedule, states map[string]string, now time.Time) snapshotScheduleReport {
+	var r snapshotScheduleReport
+	output, err := ListSnapshots()
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("list snapshots: %w", err))
+		return r
+	}
+	snaps := parseSnapshots(output)
+
+	for _, s := range schedules {
+		state, ok := states[s.vm]
+		if !ok || state == "Deleted" {
+			continue
+		}
+		due, prune := planSchedule(s, snaps, now)
+		if due {
+			created := false
+			switch {
+			case state == "Stopped":
+				created = r.snapshot(s.vm, now)
+			case state == "Running" && s.stop:
+				if _, err := StopVM(s.vm); err != nil {
+					r.errs = append(r.errs, fmt.Errorf("stop %s for snapshot: %w", s.vm, err))
+					continue
+				}
+				created = r.snapshot(s.vm, now)
+				if _, err := StartVM(s.vm); err != nil {
+					r.errs = append(r.errs, fmt.Errorf("restart %s after snapshot: %w", s.vm, err))
+				}
+			default:
+				r.skipped = append(r.skipped, s.vm)
+			}
+			// planSchedule made room for the new snapshot; without one, prune
+			// only what is beyond keep.
+			if !created && len(prune) > 0 {
+				prune = prune[1:]
+			}
+		}
+		for _, name := range prune {
+			if _, err := DeleteSnapshot(s.vm, name); err != nil {
+				r.errs = append(r.errs, fmt.Errorf("prune %s.%s: %w", s.vm, name, err))
+				continue
+			}
+			r.pruned = append(r.pruned, s.vm+"."+name)
+		}
+	}
+	return r
+}
+
+// snapshot takes a scheduled snapshot of vm and reports whether it exists.
+func (r *snapshotScheduleReport) snapshot(vm string, now time.Time) bool {
+	name := scheduledSnapshotPrefix + now.UTC().Format(scheduledSnapshotLayout)
+	if _, err := CreateSnapshot(vm, name, "passgo scheduled snapshot"); err != nil {
+		r.errs = append(r.errs, fmt.Errorf("snapshot %s: %w", vm, err))
+		return false
+	}
+	r.created = append(r.created, vm+"."+name)
+	return true
+}
+
+// ─── TUI ───────────────────────────────────────────────────────────────────────
+
+// snapshotScheduleResultMsg reports a background schedule evaluation.
+type snapshotScheduleResultMsg struct {
+	report snapshotScheduleReport
+}
+
+func runSnapshotSchedulesCmd(schedules []snapshotSchedule, states map[string]string, now time.Time) tea.Cmd {
+	return func() tea.Msg {
+		return snapshotScheduleResultMsg{report: runSnapshotSchedules(schedules, states, now)}
+	}
+}
+
+// checkSnapshotSchedules starts a schedule evaluation at most once per
+// snapshotScheduleInterval. VMs with an operation in flight are left alone.
+func (m *rootModel) checkSnapshotSchedules(now time.Time) tea.Cmd {
+	if len(m.schedules) == 0 || m.scheduleInFlight || now.Sub(m.lastScheduleRun) < snapshotScheduleInterval {
+		return nil
+	}
+	if len(m.table.vms) == 0 {
+		return nil
+	}
+	states := make(map[string]string, len(m.table.vms))
+	for _, vm := range m.table.vms {
+		if _, busy := m.table.busyVMs[vm.info.Name]; !busy {
+			states[vm.info.Name] = vm.info.State
+		}
+	}
+	m.scheduleInFlight = true
+	m.lastScheduleRun = now
+	return runSnapshotSchedulesCmd(m.schedules, states, now)
+}
+
+// handleSnapshotScheduleResult surfaces what a schedule evaluation did.
+// A skipped VM is reported once per session.
+func (m *rootModel) handleSnapshotScheduleResult(r snapshotScheduleReport) tea.Cmd {
+	m.scheduleInFlight = false
+	if appLogger != nil {
+		for _, s := range r.created {
+			appLogger.Printf("scheduled snapshot created: %s", s)
+		}
+		for _, s := range r.pruned {
+			appLogger.Printf("scheduled snapshot pruned: %s", s)
+		}
+		for _, err := range r.errs {
+			appLogger.Printf("snapshot schedule: %v", err)
+		}
+	}
+	var cmds []tea.Cmd
+	if len(r.created) > 0 {
+		cmds = append(cmds, m.table.addToast("📸 Scheduled snapshot: "+strings.Join(r.created, ", "), "success"))
+	}
+	if len(r.errs) > 0 {
+		cmds = append(cmds, m.table.addToastFor("Snapshot schedule: "+r.errs[0].Error(), "error", 8*time.Second))
+	}
+	for _, vm := range r.skipped {
+		if m.scheduleSkipWarned[vm] {
+			continue
+		}
+		if m.scheduleSkipWarned == nil {
+			m.scheduleSkipWarned = make(map[string]bool)
+		}
+		m.scheduleSkipWarned[vm] = true
+		cmds = append(cmds, m.table.addToastFor(
+			fmt.Sprintf("Snapshot of %s is due; it will be taken when the VM is stopped (or set stop: true)", vm),
+			"info", 8*time.Second))
+	}
+	if len(r.created) > 0 || len(r.pruned) > 0 {
+		cmds = append(cmds, m.requestVMListFetch(true))
+	}
+	return tea.Batch(cmds...)
+}
+
+// ─── Daemon ────────────────────────────────────────────────────────────────────
+
+const snapshotDaemonUsage = `Usage: passgo snapshot-daemon [--once]
+
+Evaluates the snapshots entries in .config, plus the schedules chosen
+when adopting VMs, every minute, creating and pruning scheduled snapshots
+without the TUI running.
+
+Flags:
+`
+
+// snapshotDaemonCommand implements `passgo snapshot-daemon`.
+func snapshotDaemonCommand(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("snapshot-daemon", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, snapshotDaemonUsage)
+		fs.PrintDefaults()
+	}
+	once := fs.Bool("once", false, "evaluate the schedules once and exit")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	schedules, errs := loadSnapshotSchedules(configList("snapshots"))
+	for _, err := range errs {
+		fmt.Fprintf(stderr, "passgo snapshot-daemon: %v\n", err)
+	}
+	if path, err := stateFilePath(); err == nil {
+		if st, err := loadState(path); err == nil {
+			schedules = append(schedules, stateSchedules(st, schedules)...)
+		}
+	}
+	if len(schedules) == 0 {
+		fmt.Fprintln(stderr, "passgo snapshot-daemon: no valid snapshots entries in .config")
+		return exitUsage
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	defer signal.Stop(sigs)
+
+	for {
+		now := time.Now()
+		vms, err := doFetchVMList()
+		if err != nil {
+			fmt.Fprintf(stderr, "%s list VMs: %v\n", now.Format(time.DateTime), err)
+		} else {
+			states := make(map[string]string, len(vms))
+			for _, vm := range vms {
+				states[vm.info.Name] = vm.info.State
+			}
+			r := runSnapshotSchedules(schedules, states, now)
+			for _, s := range r.created {
+				fmt.Fprintf(stdout, "%s created %s\n", now.Format(time.DateTime), s)
+			}
+			for _, s := range r.pruned {
+				fmt.Fprintf(stdout, "%s pruned %s\n", now.Format(time.DateTime), s)
+			}
+			for _, vm := range r.skipped {
+				fmt.Fprintf(stdout, "%s skipped %s (running)\n", now.Format(time.DateTime), vm)
+			}
+			for _, err := range r.errs {
+				fmt.Fprintf(stderr, "%s %v\n", now.Format(time.DateTime), err)
+			}
+		}
+		if *once {
+			return exitOK
+		}
+		select {
+		case <-sigs:
+			return exitOK
+		case <-time.After(snapshotScheduleInterval):
+		}
+	}
+}
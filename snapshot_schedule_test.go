@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSnapshotSchedule(t *testing.T) {
+	s, err := parseSnapshotSchedule("{vm: db-test, every: 24h, keep: 3, stop: true}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := snapshotSchedule{vm: "db-test", every: 24 * time.Hour, keep: 3, stop: true}
+	if s != want {
+		t.Fatalf("got %+v, want %+v", s, want)
+	}
+
+	s, err = parseSnapshotSchedule("{vm: web, every: 2d}")
+	if err != nil || s.keep != 7 || s.every != 48*time.Hour {
+		t.Fatalf("defaults: got %+v, %v", s, err)
+	}
+
+	for _, bad := range []string{
+		"{every: 24h}",
+		"{vm: db}",
+		"{vm: db, every: soon}",
+		"{vm: db, every: 1h, keep: 0}",
+		"{vm: db, every: 1h, often: yes}",
+	} {
+		if _, err := parseSnapshotSchedule(bad); err == nil {
+			t.Errorf("parseSnapshotSchedule(%q) should fail", bad)
+		}
+	}
+
+	schedules, errs := loadSnapshotSchedules([]string{"{vm: a, every: 1h}", "{vm: b}"})
+	if len(schedules) != 1 || len(errs) != 1 {
+		t.Fatalf("loadSnapshotSchedules: %d schedules, %d errors", len(schedules), len(errs))
+	}
+}
+
+func TestPlanSchedule(t *testing.T) {
+	now := time.Date(2026, time.October, 15, 12, 0, 0, 0, time.UTC)
+	snap := func(vm string, at time.Time) SnapshotInfo {
+		return SnapshotInfo{Instance: vm, Name: scheduledSnapshotPrefix + at.Format(scheduledSnapshotLayout)}
+	}
+	snaps := []SnapshotInfo{
+		snap("db", now.Add(-26*time.Hour)),
+		snap("db", now.Add(-50*time.Hour)),
+		snap("db", now.Add(-74*time.Hour)),
+		{Instance: "db", Name: "before-upgrade"}, // manual snapshots are never pruned
+		snap("web", now.Add(-time.Hour)),
+	}
+	s := snapshotSchedule{vm: "db", every: 24 * time.Hour, keep: 3}
+
+	due, prune := planSchedule(s, snaps, now)
+	if !due || !reflect.DeepEqual(prune, []string{snaps[2].Name}) {
+		t.Fatalf("due=%v prune=%v", due, prune)
+	}
+
+	due, prune = planSchedule(s, snaps, now.Add(-3*time.Hour))
+	if due || len(prune) != 0 {
+		t.Fatalf("not yet due: due=%v prune=%v", due, prune)
+	}
+
+	due, _ = planSchedule(snapshotSchedule{vm: "new", every: time.Hour, keep: 1}, snaps, now)
+	if !due {
+		t.Fatal("a VM without scheduled snapshots should be due")
+	}
+}
+
+func TestRunSnapshotSchedulesKeepsOldOnFailure(t *testing.T) {
+	now := time.Date(2026, time.October, 15, 12, 0, 0, 0, time.UTC)
+	name := func(age time.Duration) string {
+		return scheduledSnapshotPrefix + now.Add(-age).Format(scheduledSnapshotLayout)
+	}
+	list := "Instance   Snapshot   Parent   Comment\n" +
+		"db   " + name(26*time.Hour) + "   --   --\n" +
+		"db   " + name(50*time.Hour) + "   --   --\n"
+	f := &fakeRunner{
+		outputs: map[string]string{"list": list},
+		fail:    map[string]error{"snapshot": errors.New("exit status 1")},
+	}
+	useFakeRunner(t, f)
+
+	s := snapshotSchedule{vm: "db", every: 24 * time.Hour, keep: 2}
+	r := runSnapshotSchedules([]snapshotSchedule{s}, map[string]string{"db": "Stopped"}, now)
+	if len(r.created) != 0 || len(r.errs) != 1 {
+		t.Fatalf("created=%v errs=%v", r.created, r.errs)
+	}
+	for _, call := range f.calls {
+		if call[0] == "delete" {
+			t.Fatalf("pruned after a failed snapshot: %s", strings.Join(call, " "))
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// snippets.go - Saved exec dialog commands and their environment variables
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// envNameRe matches an environment variable name.
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parseEnvAssignments parses space-separated NAME=VALUE pairs. Values
+// cannot contain spaces.
+func parseEnvAssignments(s string) ([]string, error) {
+	var env []string
+	for _, f := range strings.Fields(s) {
+		name, _, ok := strings.Cut(f, "=")
+		if !ok || !envNameRe.MatchString(name) {
+			return nil, fmt.Errorf("environment %q: want NAME=VALUE", f)
+		}
+		env = append(env, f)
+	}
+	return env, nil
+}
+
+// resolveEnvSecrets replaces ${secret:NAME} placeholders in env values, so
+// tokens can come from .config secrets instead of being written inline.
+func resolveEnvSecrets(env []string, resolve func(string) (string, error)) ([]string, error) {
+	out := make([]string, len(env))
+	for i, kv := range env {
+		v, _, err := renderSecrets([]byte(kv), resolve)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = string(v)
+	}
+	return out, nil
+}
+
+// execSnippet is a saved command for the exec dialog. Configured in
+// .config, one line per snippet, with an optional environment:
+//
+//	exec-snippet: Tests | | make test
+//	exec-snippet: Deploy | ENV=staging TOKEN=${secret:deploy_token} | ./deploy.sh
+type execSnippet struct {
+	label   string
+	env     []string
+	command string
+}
+
+// parseExecSnippet parses "LABEL | ENV | COMMAND". The command may itself
+// contain pipes.
+func parseExecSnippet(s string) (execSnippet, error) {
+	parts := strings.SplitN(s, "|", 3)
+	if len(parts) != 3 {
+		return execSnippet{}, fmt.Errorf("snippet %q: want LABEL | ENV | COMMAND (ENV may be empty)", s)
+	}
+	sn := execSnippet{
+		label:   strings.TrimSpace(parts[0]),
+		command: strings.TrimSpace(parts[2]),
+	}
+	if sn.command == "" {
+		return sn, fmt.Errorf("snippet %q: command is empty", s)
+	}
+	env, err := parseEnvAssignments(parts[1])
+	if err != nil {
+		return sn, fmt.Errorf("snippet %q: %w", s, err)
+	}
+	sn.env = env
+	if sn.label == "" {
+		sn.label = sn.command
+	}
+	return sn, nil
+}
+
+// loadExecSnippets parses the exec-snippet entries, skipping invalid ones.
+func loadExecSnippets(values []string) ([]execSnippet, []error) {
+	var out []execSnippet
+	var errs []error
+	for _, v := range values {
+		sn, err := parseExecSnippet(v)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		out = append(out, sn)
+	}
+	return out, errs
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseExecSnippet(t *testing.T) {
+	sn, err := parseExecSnippet("Tests | | make test")
+	if err != nil || sn.label != "Tests" || sn.command != "make test" || len(sn.env) != 0 {
+		t.Errorf("got %+v, %v", sn, err)
+	}
+	sn, err = parseExecSnippet(" | A=1 B=two | grep x | wc -l")
+	if err != nil || sn.label != "grep x | wc -l" || strings.Join(sn.env, ",") != "A=1,B=two" {
+		t.Errorf("got %+v, %v", sn, err)
+	}
+	for _, bad := range []string{"Tests | make test", "Tests | A=1 |", "Tests | 1A=x | ls", "Tests | A | ls"} {
+		if _, err := parseExecSnippet(bad); err == nil {
+			t.Errorf("parseExecSnippet(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestResolveEnvSecrets(t *testing.T) {
+	resolve := func(name string) (string, error) {
+		if name == "token" {
+			return "abc", nil
+		}
+		return "", errors.New("unknown secret")
+	}
+	env, err := resolveEnvSecrets([]string{"TOKEN=${secret:token}", "PLAIN=1"}, resolve)
+	if err != nil || strings.Join(env, " ") != "TOKEN=abc PLAIN=1" {
+		t.Errorf("env = %v, %v", env, err)
+	}
+	if _, err := resolveEnvSecrets([]string{"X=${secret:missing}"}, resolve); err == nil {
+		t.Error("expected an error for an unknown secret")
+	}
+}
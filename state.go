@@ -0,0 +1,502 @@
+// state.go - Persistent per-VM metadata tracked by passgo (~/.passgo/state.json)
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// vmMeta is what passgo remembers about a VM beyond what multipass reports.
+type vmMeta struct {
+	// ID is given by passgo when it first lists or records the VM (multipass
+	// has no instance ID of its own, only the name). It keys the VM's entry,
+	// history and usage samples in the state file, so they are not inherited
+	// by a later VM with the same name.
+	ID string `json:"-"`
+
+	// Lifetime: when set, the VM is stopped or deleted once Expires passes.
+	Expires   time.Time `json:"expires,omitempty"`
+	TTLAction string    `json:"ttl_action,omitempty"` // "delete" or "stop"
+
+	// Resources requested at launch, used for quota accounting.
+	CPUs     int `json:"cpus,omitempty"`
+	MemoryMB int `json:"memory_mb,omitempty"`
+	DiskGB   int `json:"disk_gb,omitempty"`
+
+	// Project workspace mounted at launch (see workspace.go).
+	ProjectDir    string `json:"project_dir,omitempty"`
+	ProjectTarget string `json:"project_target,omitempty"`
+
+	// Set when a VM created outside passgo was adopted (see adopt.go).
+	Adopted       time.Time `json:"adopted,omitempty"`
+	Tags          []string  `json:"tags,omitempty"`
+	Notes         string    `json:"notes,omitempty"`
+	SnapshotEvery string    `json:"snapshot_every,omitempty"` // schedule chosen on adoption
+	SnapshotKeep  int       `json:"snapshot_keep,omitempty"`
+
+	// Image the VM was launched from (see imagepin.go)
+	Image *imagePin `json:"image,omitempty"`
+
+	// Login name of the user who launched the VM (see owners.go)
+	Owner string `json:"owner,omitempty"`
+}
+
+// empty reports whether the entry carries no information and can be dropped.
+// Every persisted field counts; the ID alone is kept in appState.Seen.
+func (v vmMeta) empty() bool {
+	return v.Expires.IsZero() && v.TTLAction == "" &&
+		v.CPUs == 0 && v.MemoryMB == 0 && v.DiskGB == 0 &&
+		v.ProjectDir == "" && v.ProjectTarget == "" &&
+		v.Adopted.IsZero() && len(v.Tags) == 0 && v.Notes == "" && v.SnapshotEvery == "" && v.SnapshotKeep == 0 &&
+		v.Image == nil && v.Owner == ""
+}
+
+// appState is everything passgo persists. VMs are keyed by name here and
+// by ID on disk (see stateFile).
+type appState struct {
+	VMs    map[string]vmMeta
+	Recent recentLaunches
+	// VMs created outside passgo that the user chose not to adopt
+	Ignored []string
+	// Finished operations, oldest first, at most historyLimit
+	History []opRecord
+	// Resource usage samples per VM ID, oldest first, at most usageSampleLimit
+	Usage map[string][]usageSample
+	// IDs of listed VMs passgo has no entry in VMs for, by name
+	Seen map[string]string
+	// Latest launch of each release, for image drift warnings
+	Pins map[string]imagePin
+	// VMs promoted to launch sources (see golden.go)
+	Goldens []goldenImage
+	// Settings of the last successful launch, repeated with a (see relaunch.go)
+	LastLaunch *lastLaunch
+	// Last run of each cron job, by job name (see jobs.go)
+	Jobs map[string]jobRun
+}
+
+// opRecord is one finished operation on a VM.
+type opRecord struct {
+	Time  time.Time `json:"time"`
+	ID    string    `json:"id,omitempty"` // empty for VMs passgo has not listed yet
+	VM    string    `json:"vm"`
+	Op    string    `json:"op"`
+	Trace string    `json:"trace,omitempty"` // operation ID in the log
+	Error string    `json:"error,omitempty"`
+}
+
+// usageSample is a VM's load and memory and disk use at one time. Fractions
+// are -1 when multipass did not report them.
+type usageSample struct {
+	Time   time.Time `json:"time"`
+	Load   float64   `json:"load"` // 1-minute load average
+	Memory float64   `json:"memory"`
+	Disk   float64   `json:"disk"`
+}
+
+const (
+	historyLimit        = 500
+	usageSampleLimit    = 288 // a day at usageSampleInterval
+	usageSampleInterval = 5 * time.Minute
+)
+
+// stateFile is the on-disk form of appState.
+type stateFile struct {
+	Version   int                       `json:"version"`
+	Instances map[string]instanceRecord `json:"instances"`
+	Recent    recentLaunches            `json:"recent"`
+	Ignored   []string                  `json:"ignored,omitempty"`
+	History   []opRecord                `json:"history,omitempty"`
+	Usage     map[string][]usageSample  `json:"usage,omitempty"`
+	Seen      map[string]string         `json:"seen,omitempty"`
+	Pins      map[string]imagePin       `json:"image_pins,omitempty"`
+	Goldens   []goldenImage             `json:"golden_images,omitempty"`
+	Last      *lastLaunch               `json:"last_launch,omitempty"`
+	Jobs      map[string]jobRun         `json:"job_runs,omitempty"`
+
+	// Version 0 kept VMs by name.
+	LegacyVMs map[string]vmMeta `json:"vms,omitempty"`
+}
+
+// instanceRecord is a VM's entry in the state file, keyed by its ID.
+type instanceRecord struct {
+	Name string `json:"name"`
+	vmMeta
+}
+
+// stateMigrations upgrade a state file one version at a time: entry i
+// turns version i into i+1. Append new migrations; never change old ones.
+var stateMigrations = []func(*stateFile){
+	// 0 → 1: entries keyed by name become instances keyed by a new ID.
+	func(f *stateFile) {
+		for name, meta := range f.LegacyVMs {
+			f.Instances[newInstanceID()] = instanceRecord{Name: name, vmMeta: meta}
+		}
+		f.LegacyVMs = nil
+	},
+	// 1 → 2: seen holds the IDs of VMs without an entry. Nothing to convert.
+	func(*stateFile) {},
+}
+
+// stateVersion is the version this build reads and writes.
+var stateVersion = len(stateMigrations)
+
+// newInstanceID returns a random 16-hex-digit ID.
+func newInstanceID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// recentLimit is how many recent releases and templates are remembered.
+const recentLimit = 5
+
+// recentLaunches lists what was launched recently, most recent first.
+type recentLaunches struct {
+	Releases  []string `json:"releases,omitempty"`
+	Templates []string `json:"templates,omitempty"` // cloud-init picker labels
+	Images    []string `json:"images,omitempty"`    // custom image URLs (file://, https://)
+}
+
+func newAppState() appState {
+	return appState{VMs: make(map[string]vmMeta), Usage: make(map[string][]usageSample), Seen: make(map[string]string)}
+}
+
+// stateFilePath returns ~/.passgo/state.json.
+func stateFilePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".passgo", "state.json"), nil
+}
+
+// loadState reads the state file; a missing file yields an empty state.
+// Files from older versions are migrated, keeping the original next to it
+// as state.json.v<N>.bak. A file from a newer passgo is an error, and must
+// not be saved over.
+func loadState(path string) (appState, error) {
+	st := newAppState()
+	data, err := os.ReadFile(path) // #nosec G304 -- path under the user's home dir
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return st, nil
+		}
+		return st, err
+	}
+	f, err := decodeStateFile(data)
+	if err != nil {
+		return st, err
+	}
+	if f.Version < stateVersion {
+		backup := path + ".v" + strconv.Itoa(f.Version) + ".bak"
+		if _, err := os.Stat(backup); errors.Is(err, os.ErrNotExist) {
+			if err := os.WriteFile(backup, data, 0o600); err != nil {
+				return st, fmt.Errorf("backing up the state file before migrating it: %w", err)
+			}
+		}
+		for v := f.Version; v < stateVersion; v++ {
+			stateMigrations[v](&f)
+		}
+		f.Version = stateVersion
+	}
+	return f.state(), nil
+}
+
+// state is the in-memory form of a current-version state file.
+func (f stateFile) state() appState {
+	st := newAppState()
+	for id, rec := range f.Instances {
+		rec.vmMeta.ID = id
+		st.VMs[rec.Name] = rec.vmMeta
+	}
+	st.Recent, st.Ignored, st.History, st.Pins, st.Goldens, st.LastLaunch, st.Jobs = f.Recent, f.Ignored, f.History, f.Pins, f.Goldens, f.Last, f.Jobs
+	if f.Usage != nil {
+		st.Usage = f.Usage
+	}
+	for name, id := range f.Seen {
+		if _, ok := st.VMs[name]; !ok {
+			st.Seen[name] = id
+		}
+	}
+	return st
+}
+
+// decodeStateFile parses a state file of any known version.
+func decodeStateFile(data []byte) (stateFile, error) {
+	f := stateFile{Instances: make(map[string]instanceRecord)}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return f, err
+	}
+	if f.Version > stateVersion {
+		return f, fmt.Errorf("state file version %d is newer than this passgo supports (%d); upgrade passgo", f.Version, stateVersion)
+	}
+	if f.Instances == nil {
+		f.Instances = make(map[string]instanceRecord)
+	}
+	return f, nil
+}
+
+// saveState writes the state file atomically (temp file + rename). VMs
+// recorded since the last save get their IDs here.
+func saveState(path string, st appState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(st.file(), "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".state-*.json")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// file is st's on-disk form. VMs recorded since the last save get their
+// IDs here.
+func (st appState) file() stateFile {
+	f := stateFile{
+		Version:   stateVersion,
+		Instances: make(map[string]instanceRecord, len(st.VMs)),
+		Recent:    st.Recent,
+		Ignored:   st.Ignored,
+		History:   st.History,
+		Usage:     st.Usage,
+		Seen:      st.Seen,
+		Pins:      st.Pins,
+		Goldens:   st.Goldens,
+		Last:      st.LastLaunch,
+		Jobs:      st.Jobs,
+	}
+	for name, meta := range st.VMs {
+		if meta.ID == "" {
+			meta.ID = st.instanceID(name)
+		}
+		f.Instances[meta.ID] = instanceRecord{Name: name, vmMeta: meta}
+	}
+	return f
+}
+
+// setTTL records that vmName should be stopped or deleted at expires.
+func (s appState) setTTL(vmName string, expires time.Time, action string) {
+	meta := s.VMs[vmName]
+	meta.Expires = expires
+	meta.TTLAction = action
+	s.VMs[vmName] = meta
+}
+
+// addNote appends a line to vmName's notes.
+func (s appState) addNote(vmName, note string) {
+	meta := s.VMs[vmName]
+	if meta.Notes != "" {
+		meta.Notes += "\n"
+	}
+	meta.Notes += note
+	s.VMs[vmName] = meta
+}
+
+// setAllocation records the resources vmName was launched with.
+func (s appState) setAllocation(vmName string, r resources) {
+	meta := s.VMs[vmName]
+	meta.CPUs = r.cpus
+	meta.MemoryMB = r.memoryMB
+	meta.DiskGB = r.diskGB
+	s.VMs[vmName] = meta
+}
+
+// noteLaunch moves release and template (either may be empty) to the front
+// of the recent lists. Image URLs are kept apart from release names.
+func (s *appState) noteLaunch(release, template string) {
+	if isImageURL(release) {
+		s.Recent.Images = pushRecent(s.Recent.Images, release)
+	} else {
+		s.Recent.Releases = pushRecent(s.Recent.Releases, release)
+	}
+	s.Recent.Templates = pushRecent(s.Recent.Templates, template)
+}
+
+// pushRecent puts item first in list, dropping duplicates and old entries.
+func pushRecent(list []string, item string) []string {
+	if item == "" {
+		return list
+	}
+	out := []string{item}
+	for _, v := range list {
+		if v != item && len(out) < recentLimit {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// recentFirst reorders options so entries of recent come first, in recency
+// order. Recent entries missing from options are only added when addMissing
+// is set (releases can be any image name; templates must exist).
+func recentFirst(options, recent []string, addMissing bool) []string {
+	out := make([]string, 0, len(options)+len(recent))
+	seen := make(map[string]bool)
+	for _, r := range recent {
+		if seen[r] {
+			continue
+		}
+		for _, o := range options {
+			if o == r {
+				out = append(out, r)
+				seen[r] = true
+				break
+			}
+		}
+		if !seen[r] && addMissing {
+			out = append(out, r)
+			seen[r] = true
+		}
+	}
+	for _, o := range options {
+		if !seen[o] {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// instanceID returns vmName's ID, giving it one if it has none yet. A VM
+// with an entry keeps its ID there, any other in Seen.
+func (s appState) instanceID(vmName string) string {
+	if meta, ok := s.VMs[vmName]; ok {
+		if meta.ID == "" {
+			meta.ID = s.Seen[vmName]
+			if meta.ID == "" {
+				meta.ID = newInstanceID()
+			}
+			s.VMs[vmName] = meta
+			delete(s.Seen, vmName)
+		}
+		return meta.ID
+	}
+	id := s.Seen[vmName]
+	if id == "" && s.Seen != nil {
+		id = newInstanceID()
+		s.Seen[vmName] = id
+	}
+	return id
+}
+
+// lookupID returns vmName's ID, or "" if it has none.
+func (s appState) lookupID(vmName string) string {
+	if id := s.VMs[vmName].ID; id != "" {
+		return id
+	}
+	return s.Seen[vmName]
+}
+
+// forget drops everything recorded about vmName except its history.
+func (s appState) forget(vmName string) {
+	if id := s.lookupID(vmName); id != "" {
+		delete(s.Usage, id)
+	}
+	delete(s.VMs, vmName)
+	delete(s.Seen, vmName)
+}
+
+// recordOp appends a finished operation to the history.
+func (s *appState) recordOp(vmName, op, trace string, err error, now time.Time) {
+	r := opRecord{Time: now.UTC(), ID: s.lookupID(vmName), VM: vmName, Op: op, Trace: trace}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	s.History = append(s.History, r)
+	if n := len(s.History) - historyLimit; n > 0 {
+		s.History = append([]opRecord(nil), s.History[n:]...)
+	}
+}
+
+// sampleUsage records the usage of running VMs that were not sampled
+// within usageSampleInterval, giving IDs to VMs seen for the first time.
+// vms is the full list: VMs without an entry that are no longer listed are
+// forgotten, so a later VM of the same name starts afresh. It reports
+// whether it changed anything.
+func (s *appState) sampleUsage(vms []vmData, now time.Time) bool {
+	if s.Usage == nil {
+		s.Usage = make(map[string][]usageSample)
+	}
+	if s.Seen == nil {
+		s.Seen = make(map[string]string)
+	}
+	changed := false
+	listed := make(map[string]bool, len(vms))
+	for _, vm := range vms {
+		listed[vm.info.Name] = true
+	}
+	for name := range s.Seen {
+		if !listed[name] {
+			s.forget(name)
+			changed = true
+		}
+	}
+	for _, vm := range vms {
+		if vm.info.State != "Running" {
+			continue
+		}
+		id := s.instanceID(vm.info.Name)
+		samples := s.Usage[id]
+		if n := len(samples); n > 0 && now.Sub(samples[n-1].Time) < usageSampleInterval {
+			continue
+		}
+		sample := usageSample{Time: now.UTC(), Load: -1, Memory: -1, Disk: -1}
+		if f := strings.Fields(vm.info.Load); len(f) > 0 {
+			if load, err := strconv.ParseFloat(f[0], 64); err == nil {
+				sample.Load = load
+			}
+		}
+		if frac, ok := parseUsageFraction(vm.info.MemoryUsage); ok {
+			sample.Memory = frac
+		}
+		if frac, ok := parseUsageFraction(vm.info.DiskUsage); ok {
+			sample.Disk = frac
+		}
+		samples = append(samples, sample)
+		if n := len(samples) - usageSampleLimit; n > 0 {
+			samples = append([]usageSample(nil), samples[n:]...)
+		}
+		s.Usage[id] = samples
+		changed = true
+	}
+	return changed
+}
+
+// clearTTL forgets any lifetime recorded for vmName.
+func (s appState) clearTTL(vmName string) {
+	meta, ok := s.VMs[vmName]
+	if !ok {
+		return
+	}
+	meta.Expires = time.Time{}
+	meta.TTLAction = ""
+	if meta.empty() {
+		// Keep the ID: the VM's usage samples are filed under it.
+		if meta.ID != "" && s.Seen != nil {
+			s.Seen[vmName] = meta.ID
+		}
+		delete(s.VMs, vmName)
+		return
+	}
+	s.VMs[vmName] = meta
+}
@@ -0,0 +1,157 @@
+// state_sync.go - Sharing the state file between passgo processes
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stateLockWait is how long a writer waits for another to finish with the
+// state file. Writers hold the lock only to load, change and save it.
+const stateLockWait = 5 * time.Second
+
+// lockStateFile takes the state file's lock, state.json.lock holding the
+// owner's PID, and returns its release. A lock left by a process that has
+// exited is taken over.
+func lockStateFile(path string) (func(), error) {
+	lock := path + ".lock"
+	deadline := time.Now().Add(stateLockWait)
+	for {
+		f, err := os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600) // #nosec G304 -- next to the state file
+		if err == nil {
+			_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { _ = os.Remove(lock) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if data, err := os.ReadFile(lock); err == nil { // #nosec G304 -- next to the state file
+			if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && !processAlive(pid) {
+				_ = os.Remove(lock)
+				continue
+			}
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("state file is locked by another passgo (%s)", lock)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// updateState loads the state file under its lock, applies change and
+// saves the result when change reports that it changed something. It
+// returns the state as saved (or loaded).
+func updateState(path string, change func(*appState) bool) (appState, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return appState{}, err
+	}
+	unlock, err := lockStateFile(path)
+	if err != nil {
+		return appState{}, err
+	}
+	defer unlock()
+	st, err := loadState(path)
+	if err != nil {
+		return st, err // not saved over: it may be from a newer passgo
+	}
+	if change(&st) {
+		if err := saveState(path, st); err != nil {
+			return st, fmt.Errorf("save state: %w", err)
+		}
+	}
+	return st, nil
+}
+
+// clone returns a deep copy of s, with IDs given to VMs that had none.
+func (s appState) clone() appState {
+	data, err := json.Marshal(s.file())
+	if err != nil {
+		return newAppState()
+	}
+	f, err := decodeStateFile(data)
+	if err != nil {
+		return newAppState()
+	}
+	return f.state()
+}
+
+// mergeState applies what mine changed since base onto disk, which holds
+// what another process wrote meanwhile: an entry mine left alone keeps
+// disk's version, one it changed or removed takes mine's. base must come
+// from clone.
+func mergeState(disk, base, mine appState) appState {
+	mine = mine.clone() // times as they read back from the file, to compare with base
+	disk.VMs = mergeMap(disk.VMs, base.VMs, mine.VMs)
+	disk.Seen = mergeMap(disk.Seen, base.Seen, mine.Seen)
+	disk.Usage = mergeMap(disk.Usage, base.Usage, mine.Usage)
+	disk.Pins = mergeMap(disk.Pins, base.Pins, mine.Pins)
+	disk.Jobs = mergeMap(disk.Jobs, base.Jobs, mine.Jobs)
+	for name := range disk.VMs {
+		disk.instanceID(name) // an entry takes over its VM's ID from Seen
+	}
+
+	mergeValue(&disk.Recent, base.Recent, mine.Recent)
+	mergeValue(&disk.Ignored, base.Ignored, mine.Ignored)
+	mergeValue(&disk.Goldens, base.Goldens, mine.Goldens)
+	mergeValue(&disk.LastLaunch, base.LastLaunch, mine.LastLaunch)
+
+	// History only grows: add mine's new records to disk's.
+	type opKey struct {
+		at               int64
+		vm, op, trace, e string
+	}
+	key := func(r opRecord) opKey { return opKey{r.Time.UnixNano(), r.VM, r.Op, r.Trace, r.Error} }
+	known := make(map[opKey]bool, len(base.History)+len(disk.History))
+	for _, r := range slices.Concat(base.History, disk.History) {
+		known[key(r)] = true
+	}
+	added := false
+	for _, r := range mine.History {
+		if !known[key(r)] {
+			disk.History = append(disk.History, r)
+			added = true
+		}
+	}
+	if added {
+		sort.SliceStable(disk.History, func(i, j int) bool { return disk.History[i].Time.Before(disk.History[j].Time) })
+		if n := len(disk.History) - historyLimit; n > 0 {
+			disk.History = append([]opRecord(nil), disk.History[n:]...)
+		}
+	}
+	return disk
+}
+
+// mergeMap is mergeState for one map, by key.
+func mergeMap[V any](disk, base, mine map[string]V) map[string]V {
+	if disk == nil {
+		disk = make(map[string]V)
+	}
+	for k, v := range mine {
+		if b, ok := base[k]; !ok || !reflect.DeepEqual(b, v) {
+			disk[k] = v
+		}
+	}
+	for k := range base {
+		if _, ok := mine[k]; !ok {
+			delete(disk, k)
+		}
+	}
+	return disk
+}
+
+// mergeValue is mergeState for a value replaced as a whole.
+func mergeValue[T any](disk *T, base, mine T) {
+	if !reflect.DeepEqual(base, mine) {
+		*disk = mine
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMergeState(t *testing.T) {
+	now := time.Now()
+	start := newAppState()
+	start.VMs["db"] = vmMeta{Tags: []string{"team-a"}, Expires: now.Add(-time.Minute), TTLAction: "stop"}
+	start.addNote("web", "frontend")
+	start.instanceID("db")
+	start.instanceID("web")
+	base := start.clone()
+
+	// The agent clears db's expired TTL and records the stop.
+	disk := base.clone()
+	disk.clearTTL("db")
+	disk.recordOp("db", "stop", "", nil, now)
+
+	// Meanwhile the TUI notes web, sets a TTL on cache and records a start.
+	mine := base.clone()
+	mine.addNote("web", "owned by ci")
+	mine.setTTL("cache", now.Add(time.Hour), "delete")
+	mine.recordOp("cache", "start", "", nil, now.Add(time.Second))
+
+	got := mergeState(disk, base, mine)
+	if !got.VMs["db"].Expires.IsZero() || len(got.VMs["db"].Tags) != 1 {
+		t.Errorf("db = %+v, want the agent's cleared TTL and the tags", got.VMs["db"])
+	}
+	if got.VMs["web"].Notes != "frontend\nowned by ci" {
+		t.Errorf("web notes = %q", got.VMs["web"].Notes)
+	}
+	if got.VMs["cache"].TTLAction != "delete" || got.VMs["cache"].ID == "" {
+		t.Errorf("cache = %+v", got.VMs["cache"])
+	}
+	if len(got.History) != 2 || got.History[0].VM != "db" || got.History[1].VM != "cache" {
+		t.Errorf("history = %+v", got.History)
+	}
+
+	// What the TUI removed goes, what it left alone stays.
+	mine = got.clone()
+	delete(mine.VMs, "web")
+	got = mergeState(got.clone(), got.clone(), mine)
+	if _, ok := got.VMs["web"]; ok || got.VMs["db"].ID == "" {
+		t.Errorf("VMs = %+v", got.VMs)
+	}
+}
+
+func TestLockStateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	unlock, err := lockStateFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+	if _, err := os.Stat(path + ".lock"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("lock not released: %v", err)
+	}
+
+	dead := exec.Command("true")
+	if err := dead.Run(); err != nil {
+		t.Skip(err)
+	}
+	if err := os.WriteFile(path+".lock", fmt.Appendf(nil, "%d\n", dead.Process.Pid), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	unlock, err = lockStateFile(path)
+	if err != nil {
+		t.Fatalf("a lock of an exited process should be taken over: %v", err)
+	}
+	unlock()
+}
+
+func TestPersistStateMergesAgentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	st := newAppState()
+	st.addNote("web", "frontend")
+	if err := saveState(path, st); err != nil {
+		t.Fatal(err)
+	}
+	m := rootModel{table: newTableModel(), statePath: path, agent: true}
+	m.reloadState()
+
+	// The agent records an operation after the TUI last read the file.
+	if _, err := updateState(path, func(st *appState) bool {
+		st.recordOp("web", "stop", "", nil, time.Now())
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	m.state.setTTL("web", time.Now().Add(time.Hour), "stop")
+	m.persistState()
+
+	st, err := loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.History) != 1 || st.VMs["web"].TTLAction != "stop" || st.VMs["web"].Notes != "frontend" {
+		t.Errorf("state = %+v, want the agent's history and the TUI's TTL", st)
+	}
+	if len(m.state.History) != 1 {
+		t.Error("the TUI should carry on with the merged state")
+	}
+}
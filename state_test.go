@@ -0,0 +1,250 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+
+	st, err := loadState(path)
+	if err != nil || len(st.VMs) != 0 {
+		t.Fatalf("expected empty state for missing file, got %v %v", st, err)
+	}
+
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	st.setTTL("vm-a", expires, "stop")
+	if err := saveState(path, st); err != nil {
+		t.Fatalf("saveState: %v", err)
+	}
+
+	loaded, err := loadState(path)
+	if err != nil {
+		t.Fatalf("loadState: %v", err)
+	}
+	if got := loaded.VMs["vm-a"]; !got.Expires.Equal(expires) || got.TTLAction != "stop" {
+		t.Fatalf("unexpected meta after round trip: %+v", got)
+	}
+
+	loaded.clearTTL("vm-a")
+	if _, ok := loaded.VMs["vm-a"]; ok {
+		t.Fatalf("expected empty entry to be dropped")
+	}
+}
+
+func TestRecentLaunches(t *testing.T) {
+	st := newAppState()
+	for _, r := range []string{"22.04", "24.04", "22.04", "a", "b", "c", "d"} {
+		st.noteLaunch(r, "")
+	}
+	want := []string{"d", "c", "b", "a", "22.04"}
+	if !reflect.DeepEqual(st.Recent.Releases, want) {
+		t.Fatalf("Releases = %v, want %v", st.Recent.Releases, want)
+	}
+	if len(st.Recent.Templates) != 0 {
+		t.Fatalf("empty templates should not be recorded: %v", st.Recent.Templates)
+	}
+
+	got := recentFirst([]string{"22.04", "20.04", "24.04"}, []string{"24.04", "jammy", "24.04"}, true)
+	if want := []string{"24.04", "jammy", "22.04", "20.04"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("recentFirst(addMissing) = %v, want %v", got, want)
+	}
+	got = recentFirst([]string{"web.yaml", "db.yaml"}, []string{"db.yaml", "gone.yaml"}, false)
+	if want := []string{"db.yaml", "web.yaml"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("recentFirst = %v, want %v", got, want)
+	}
+}
+
+func TestAddNote(t *testing.T) {
+	st := newAppState()
+	st.addNote("web", "Template: web.yaml from /srv/templates")
+	if st.VMs["web"].empty() {
+		t.Fatal("a VM with only notes should be kept")
+	}
+	st.addNote("web", "second")
+	if got, want := st.VMs["web"].Notes, "Template: web.yaml from /srv/templates\nsecond"; got != want {
+		t.Errorf("Notes = %q, want %q", got, want)
+	}
+}
+
+func TestStateMigratesNameKeyedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	old := `{"vms": {"web": {"ttl_action": "stop", "tags": ["db"]}}, "recent": {"releases": ["24.04"]}}`
+	if err := os.WriteFile(path, []byte(old), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	st, err := loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	web := st.VMs["web"]
+	if web.ID == "" || web.TTLAction != "stop" || !reflect.DeepEqual(web.Tags, []string{"db"}) {
+		t.Fatalf("migrated entry = %+v", web)
+	}
+	if !reflect.DeepEqual(st.Recent.Releases, []string{"24.04"}) {
+		t.Errorf("Recent = %+v", st.Recent)
+	}
+	if data, err := os.ReadFile(path + ".v0.bak"); err != nil || string(data) != old {
+		t.Errorf("backup = %q, %v", data, err)
+	}
+
+	if err := saveState(path, st); err != nil {
+		t.Fatal(err)
+	}
+	again, err := loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.VMs["web"].ID != web.ID {
+		t.Errorf("ID changed across save: %q → %q", web.ID, again.VMs["web"].ID)
+	}
+}
+
+func TestStateRejectsNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(`{"version": 999, "instances": {}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadState(path); err == nil {
+		t.Fatal("expected an error for a newer state file")
+	}
+}
+
+func TestStateIDsAssignedOnSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	st := newAppState()
+	st.addNote("web", "hello")
+	if err := saveState(path, st); err != nil {
+		t.Fatal(err)
+	}
+	id := st.VMs["web"].ID
+	if id == "" {
+		t.Fatal("saveState should assign an ID")
+	}
+	st.recordOp("web", "stop", "", nil, time.Now())
+	st.recordOp("web", "start", "op-abc123", errors.New("boom"), time.Now())
+	if len(st.History) != 2 || st.History[0].ID != id || st.History[1].Error != "boom" || st.History[1].Trace != "op-abc123" {
+		t.Fatalf("History = %+v", st.History)
+	}
+	for i := 0; i < historyLimit+10; i++ {
+		st.recordOp("web", "stop", "", nil, time.Now())
+	}
+	if len(st.History) != historyLimit {
+		t.Errorf("History has %d records, want %d", len(st.History), historyLimit)
+	}
+}
+
+func TestSampleUsage(t *testing.T) {
+	st := newAppState()
+	st.VMs["web"] = vmMeta{ID: "abc", Notes: "x"}
+	vms := []vmData{
+		{info: VMInfo{Name: "web", State: "Running", Load: "0.50 0.40 0.30", MemoryUsage: "512.0MiB out of 1.0GiB"}},
+		{info: VMInfo{Name: "untracked", State: "Running", Load: "1.00 1.00 1.00"}},
+	}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if !st.sampleUsage(vms, now) {
+		t.Fatal("expected a sample")
+	}
+	id := st.Seen["untracked"]
+	if len(st.Usage) != 2 || id == "" || len(st.Usage[id]) != 1 {
+		t.Fatalf("every running VM should be sampled: %+v", st.Usage)
+	}
+	got := st.Usage["abc"][0]
+	if got.Load != 0.5 || got.Memory != 0.5 || got.Disk != -1 {
+		t.Errorf("sample = %+v", got)
+	}
+	if st.sampleUsage(vms, now.Add(time.Minute)) {
+		t.Error("sampled again within the interval")
+	}
+	if !st.sampleUsage(vms, now.Add(usageSampleInterval)) || len(st.Usage["abc"]) != 2 {
+		t.Errorf("expected a second sample, got %+v", st.Usage["abc"])
+	}
+	st.forget("web")
+	if _, ok := st.Usage["abc"]; ok {
+		t.Error("forget should drop usage samples")
+	}
+
+	// Gaining an entry keeps the ID, and with it the samples.
+	st.setTTL("untracked", now.Add(time.Hour), "stop")
+	if got := st.instanceID("untracked"); got != id {
+		t.Fatalf("ID changed from %s to %s", id, got)
+	}
+	st.clearTTL("untracked")
+	if st.lookupID("untracked") != id {
+		t.Fatal("dropping the entry should keep the ID")
+	}
+
+	// A VM that is no longer listed is forgotten, so a new one of the same
+	// name starts afresh.
+	st.sampleUsage(nil, now.Add(2*usageSampleInterval))
+	if _, ok := st.Usage[id]; ok || st.lookupID("untracked") != "" {
+		t.Fatalf("the unlisted VM should be forgotten: %+v", st.Usage)
+	}
+}
+
+func TestSeenIDsPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	st := newAppState()
+	st.sampleUsage([]vmData{{info: VMInfo{Name: "ext", State: "Running"}}}, time.Now())
+	id := st.lookupID("ext")
+	if err := saveState(path, st); err != nil {
+		t.Fatal(err)
+	}
+	st, err := loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.lookupID("ext") != id || len(st.Usage[id]) != 1 {
+		t.Fatalf("seen ID = %q, usage %+v", st.lookupID("ext"), st.Usage)
+	}
+	if _, tracked := st.VMs["ext"]; tracked {
+		t.Fatal("a sampled VM should not gain an entry (it would not be offered for adoption)")
+	}
+}
+
+func TestClearTTLKeepsMetadata(t *testing.T) {
+	st := newAppState()
+	st.VMs["db"] = vmMeta{
+		ID: "a1", Expires: time.Now(), TTLAction: "stop",
+		Tags: []string{"team-a"}, SnapshotEvery: "24h", SnapshotKeep: 7,
+	}
+	st.clearTTL("db")
+	got, ok := st.VMs["db"]
+	if !ok || !reflect.DeepEqual(got.Tags, []string{"team-a"}) || got.SnapshotEvery != "24h" || got.SnapshotKeep != 7 {
+		t.Fatalf("db = %+v, %v; want tags and schedule kept", got, ok)
+	}
+	if !got.Expires.IsZero() || got.TTLAction != "" {
+		t.Errorf("TTL not cleared: %+v", got)
+	}
+
+	// Any persisted field keeps an entry.
+	typ := reflect.TypeOf(vmMeta{})
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+		if f.Tag.Get("json") == "-" {
+			continue
+		}
+		var meta vmMeta
+		v := reflect.ValueOf(&meta).Elem().Field(i)
+		switch v.Kind() {
+		case reflect.Slice:
+			v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		case reflect.Pointer:
+			v.Set(reflect.New(v.Type().Elem()))
+		case reflect.String:
+			v.SetString("x")
+		case reflect.Int:
+			v.SetInt(1)
+		case reflect.Struct:
+			v.Set(reflect.ValueOf(time.Now()))
+		}
+		if meta.empty() {
+			t.Errorf("an entry with only %s set counts as empty", f.Name)
+		}
+	}
+}
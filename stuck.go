@@ -0,0 +1,115 @@
+// stuck.go - Spotting instances stuck starting or in an unknown state
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// stuckStates are the transient states an instance should not stay in.
+var stuckStates = map[string]bool{"Starting": true, "Restarting": true, "Unknown": true}
+
+const defaultStuckMinutes = 5
+
+// loadStuckThreshold reads stuck-minutes from .config using lookup: how
+// long an instance may stay Starting, Restarting or Unknown before it is
+// flagged (default 5, 0 or off to disable).
+func loadStuckThreshold(lookup func(string) (string, bool)) time.Duration {
+	v, ok := lookup("stuck-minutes")
+	if !ok {
+		return defaultStuckMinutes * time.Minute
+	}
+	v = strings.TrimSpace(v)
+	if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+		return time.Duration(n) * time.Minute
+	}
+	if strings.EqualFold(v, "off") {
+		return 0
+	}
+	return defaultStuckMinutes * time.Minute
+}
+
+// stateClock is the state a VM was last listed in and since when.
+type stateClock struct {
+	state string
+	since time.Time
+}
+
+// trackStates updates clocks from the latest VM list: a VM whose state
+// changed starts a new clock at now, and VMs no longer listed are dropped.
+func trackStates(clocks map[string]stateClock, vms []vmData, now time.Time) map[string]stateClock {
+	next := make(map[string]stateClock, len(vms))
+	for _, vm := range vms {
+		c, ok := clocks[vm.info.Name]
+		if !ok || c.state != vm.info.State {
+			c = stateClock{state: vm.info.State, since: now}
+		}
+		next[vm.info.Name] = c
+	}
+	return next
+}
+
+// stuckVMs returns the VMs that have been in a stuck state for at least
+// after, with the time they entered it. VMs in busy are left out: passgo
+// is still waiting on them.
+func stuckVMs(clocks map[string]stateClock, busy map[string]busyInfo, after time.Duration, now time.Time) map[string]time.Time {
+	stuck := make(map[string]time.Time)
+	for name, c := range clocks {
+		if _, isBusy := busy[name]; isBusy || !stuckStates[c.state] || now.Sub(c.since) < after {
+			continue
+		}
+		stuck[name] = c.since
+	}
+	return stuck
+}
+
+// checkStuck updates the table's stuck markers from the latest VM list. A
+// VM becoming stuck raises a toast once; it can again after leaving the
+// state.
+func (m *rootModel) checkStuck(now time.Time) []tea.Cmd {
+	m.stateClocks = trackStates(m.stateClocks, m.table.vms, now)
+	if m.stuckAfter <= 0 {
+		m.table.stuck = nil
+		return nil
+	}
+	stuck := stuckVMs(m.stateClocks, m.table.busyVMs, m.stuckAfter, now)
+	var cmds []tea.Cmd
+	for name, since := range stuck {
+		if _, seen := m.table.stuck[name]; seen {
+			continue
+		}
+		msg := fmt.Sprintf("⚠ %s has been %s for %s: press K for recovery options",
+			name, m.stateClocks[name].state, formatRemaining(now.Sub(since)))
+		cmds = append(cmds, m.table.addToastFor(msg, "error", 10*time.Second))
+	}
+	m.table.stuck = stuck
+	return cmds
+}
+
+// escalateStop offers a force stop when a stop of vmName timed out, if
+// multipass supports one and the table is showing. It returns nil
+// otherwise, and the failure is reported as usual.
+func (m *rootModel) escalateStop(vmName, operation string, err error) tea.Cmd {
+	if operation != "stop" || !errors.Is(err, errCommandTimeout) || !forceStopSupported(m.health.client) || m.currentView != viewTable {
+		return nil
+	}
+	why := fmt.Sprintf("'%s' did not stop within %s.", vmName, multipassTimeouts.forArgs([]string{"stop"}))
+	return m.confirmForceStop(vmName, why)
+}
+
+// daemonRestartHint says how to restart multipassd on goos, for the
+// recovery menu and the clipboard.
+func daemonRestartHint(goos string) (how, command string) {
+	switch goos {
+	case "darwin":
+		return "in a terminal", "sudo launchctl kickstart -k system/com.canonical.multipassd"
+	case "windows":
+		return "in an administrator PowerShell", "Restart-Service Multipass"
+	}
+	return "in a terminal (snap install)", "sudo snap restart multipass.multipassd"
+}
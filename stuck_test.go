@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadStuckThreshold(t *testing.T) {
+	lookup := func(values map[string]string) func(string) (string, bool) {
+		return func(k string) (string, bool) { v, ok := values[k]; return v, ok }
+	}
+	for _, tc := range []struct {
+		value string
+		set   bool
+		want  time.Duration
+	}{
+		{"", false, 5 * time.Minute},
+		{"12", true, 12 * time.Minute},
+		{"0", true, 0},
+		{"off", true, 0},
+		{"soon", true, 5 * time.Minute},
+	} {
+		values := map[string]string{}
+		if tc.set {
+			values["stuck-minutes"] = tc.value
+		}
+		if got := loadStuckThreshold(lookup(values)); got != tc.want {
+			t.Errorf("stuck-minutes %q: got %v, want %v", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestCheckStuck(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := rootModel{table: newTableModel(), stuckAfter: 5 * time.Minute}
+	m.table.setVMs([]vmData{
+		{info: VMInfo{Name: "boot", State: "Starting"}},
+		{info: VMInfo{Name: "lost", State: "Unknown"}},
+		{info: VMInfo{Name: "busy", State: "Starting"}},
+		{info: VMInfo{Name: "web", State: "Running"}},
+	})
+	m.table.busyVMs["busy"] = busyInfo{operation: "Starting", startTime: start}
+
+	if cmds := m.checkStuck(start); len(cmds) != 0 || len(m.table.stuck) != 0 {
+		t.Fatalf("nothing should be stuck yet: %v", m.table.stuck)
+	}
+	if cmds := m.checkStuck(start.Add(6 * time.Minute)); len(cmds) != 2 {
+		t.Fatalf("expected two alerts, got %d", len(cmds))
+	}
+	var names []string
+	for name, since := range m.table.stuck {
+		names = append(names, name)
+		if !since.Equal(start) {
+			t.Errorf("%s stuck since %v, want %v", name, since, start)
+		}
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"boot", "lost"}) {
+		t.Fatalf("stuck = %v", names)
+	}
+	if cmds := m.checkStuck(start.Add(7 * time.Minute)); len(cmds) != 0 {
+		t.Fatalf("expected no repeat alert, got %d", len(cmds))
+	}
+
+	// Leaving the state clears the flag and restarts its clock.
+	delete(m.table.busyVMs, "busy")
+	m.table.setVMs([]vmData{
+		{info: VMInfo{Name: "boot", State: "Running"}},
+		{info: VMInfo{Name: "lost", State: "Unknown"}},
+	})
+	m.checkStuck(start.Add(8 * time.Minute))
+	if _, ok := m.table.stuck["boot"]; ok {
+		t.Fatal("boot is running and should no longer be stuck")
+	}
+	if _, ok := m.stateClocks["busy"]; ok {
+		t.Fatal("the clock of an unlisted VM should be dropped")
+	}
+
+	m.stuckAfter = 0
+	if m.checkStuck(start.Add(9 * time.Minute)); m.table.stuck != nil {
+		t.Fatalf("disabled detection should clear the flags: %v", m.table.stuck)
+	}
+}
+
+func TestForceStopVM(t *testing.T) {
+	f := &fakeRunner{}
+	useFakeRunner(t, f)
+	t.Setenv("HOME", t.TempDir())
+	if _, err := ForceStopVM("web"); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.calls) != 1 || !slices.Equal(f.calls[0], []string{"stop", "--force", "web"}) {
+		t.Fatalf("calls = %v", f.calls)
+	}
+}
+
+func TestForceStopSupported(t *testing.T) {
+	for client, want := range map[string]bool{"": false, "1.12.2": false, "1.13.0": true, "1.14.1+mac": true} {
+		if got := forceStopSupported(client); got != want {
+			t.Errorf("forceStopSupported(%q) = %v, want %v", client, got, want)
+		}
+	}
+}
+
+func TestEscalateStop(t *testing.T) {
+	timedOut := fmt.Errorf("%w after 5m0s", errCommandTimeout)
+	newModel := func(client string) rootModel {
+		m := rootModel{table: newTableModel(), currentView: viewTable, confirmLevel: confirmNone}
+		m.health = hostHealth{known: true, client: client, daemon: client}
+		return m
+	}
+
+	m := newModel("1.14.1")
+	if cmd := m.escalateStop("web", "stop", timedOut); m.currentView != viewConfirm {
+		t.Fatalf("a timed-out stop should ask to force stop, even with confirm=none (cmd %v)", cmd)
+	}
+	if m.pendingRow != (pendingRowOp{vmName: "web", op: "Force stopping"}) || m.pendingCmd == nil {
+		t.Fatalf("pending = %+v", m.pendingRow)
+	}
+	if !strings.Contains(m.confirm.question, "did not stop within") {
+		t.Fatalf("question = %q", m.confirm.question)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		client    string
+		operation string
+		err       error
+	}{
+		{"old multipass", "1.12.2", "stop", timedOut},
+		{"unknown version", "", "stop", timedOut},
+		{"other failure", "1.14.1", "stop", errors.New("instance is busy")},
+		{"other operation", "1.14.1", "start", timedOut},
+	} {
+		m := newModel(tc.client)
+		if cmd := m.escalateStop("web", tc.operation, tc.err); cmd != nil || m.currentView != viewTable {
+			t.Errorf("%s: should not offer a force stop", tc.name)
+		}
+	}
+}
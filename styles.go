@@ -17,14 +17,27 @@ var (
 	stoppedClr  lipgloss.Color
 	suspendClr  lipgloss.Color
 	deletedClr  lipgloss.Color
+	warnClr     lipgloss.Color
+	criticalClr lipgloss.Color
 )
 
+// accessibleMode switches to screen-reader-friendly output: linear table
+// rendering, no box-drawing characters and no animations.
+var accessibleMode bool
+
+// setAccessibleMode toggles accessible mode and rebuilds all styles.
+func setAccessibleMode(on bool) {
+	accessibleMode = on
+	rebuildStyles()
+}
+
 // ─── Title / App ───────────────────────────────────────────────────────────────
 
 var (
 	titleBarStyle     lipgloss.Style
 	titleVMCountStyle lipgloss.Style
 	titleLiveStyle    lipgloss.Style
+	titleStaleStyle   lipgloss.Style
 )
 
 // ─── Table ─────────────────────────────────────────────────────────────────────
@@ -113,6 +126,12 @@ func init() {
 func rebuildStyles() {
 	t := currentTheme()
 
+	// Accessible mode draws no box-drawing characters (blank borders instead).
+	border := lipgloss.RoundedBorder()
+	if accessibleMode {
+		border = lipgloss.HiddenBorder()
+	}
+
 	// Color aliases
 	accent = t.Accent
 	accentLight = t.AccentLight
@@ -124,6 +143,13 @@ func rebuildStyles() {
 	stoppedClr = t.Stopped
 	suspendClr = t.Suspended
 	deletedClr = t.Deleted
+	warnClr, criticalClr = t.Warn, t.Critical
+	if warnClr == "" {
+		warnClr = suspendClr
+	}
+	if criticalClr == "" {
+		criticalClr = stoppedClr
+	}
 
 	// ── Title ──
 	titleBarStyle = lipgloss.NewStyle().
@@ -141,6 +167,11 @@ func rebuildStyles() {
 		Background(accent).
 		Bold(true)
 
+	titleStaleStyle = lipgloss.NewStyle().
+		Foreground(suspendClr).
+		Background(accent).
+		Bold(true)
+
 	// ── Table ──
 	tableHeaderStyle = lipgloss.NewStyle().
 		Bold(true).
@@ -166,7 +197,7 @@ func rebuildStyles() {
 		PaddingLeft(3)
 
 	tableBorderStyle = lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(border).
 		BorderForeground(accent)
 
 	tableColDivStyle = lipgloss.NewStyle().
@@ -203,7 +234,7 @@ func rebuildStyles() {
 
 	// ── Modal ──
 	modalStyle = lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(border).
 		BorderForeground(accent).
 		Padding(1, 3).
 		MaxWidth(80)
@@ -229,7 +260,7 @@ func rebuildStyles() {
 		Foreground(t.TextMuted)
 
 	infoBorderStyle = lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(border).
 		BorderForeground(accent).
 		Padding(1, 2)
 
@@ -287,7 +318,7 @@ func rebuildStyles() {
 		MarginBottom(1)
 
 	detailPanelStyle = lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(border).
 		BorderForeground(subtle).
 		Padding(1, 2)
 
@@ -0,0 +1,104 @@
+// template_describe.go - One-paragraph descriptions of templates for the pickers
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxDescriptionRunes bounds a description; the pickers show two lines.
+const maxDescriptionRunes = 240
+
+// templateDescription describes the template at path: the first paragraph
+// of a sibling <name>.md, or else the comment block at the top of the
+// template (after #cloud-config). It returns "" when there is neither.
+func templateDescription(path string) string {
+	md := strings.TrimSuffix(path, filepath.Ext(path)) + ".md"
+	if d := firstParagraph(md, markdownLine); d != "" {
+		return d
+	}
+	return firstParagraph(path, commentLine)
+}
+
+// markdownLine yields a line of a README paragraph: headings and badges
+// are skipped, and emphasis markers are left as they are.
+func markdownLine(line string) (text string, skip bool) {
+	if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[![") || strings.HasPrefix(line, "![") {
+		return "", true
+	}
+	return line, false
+}
+
+// commentLine yields the text of a leading YAML comment; anything else
+// ends the block.
+func commentLine(line string) (text string, skip bool) {
+	if strings.EqualFold(line, "#cloud-config") || strings.HasPrefix(line, "# yaml-language-server:") {
+		return "", true
+	}
+	if !strings.HasPrefix(line, "#") {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimLeft(line, "#")), false
+}
+
+// firstParagraph joins the lines of the first paragraph of file into one,
+// using next to extract each line. A blank line ends the paragraph once it
+// has started; for comments, so does the first line that is not one.
+func firstParagraph(file string, next func(string) (string, bool)) string {
+	f, err := os.Open(file) // #nosec G304 -- template or its README, from a template dir or cloned repo
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		text, skip := next(line)
+		if skip {
+			if len(words) > 0 {
+				break
+			}
+			continue
+		}
+		if text == "" {
+			if len(words) > 0 || (line != "" && !strings.HasPrefix(line, "#")) {
+				break
+			}
+			continue
+		}
+		words = append(words, strings.Fields(text)...)
+	}
+	return truncateToRunes(strings.Join(words, " "), maxDescriptionRunes)
+}
+
+// describeTemplates fills in the Description of each option.
+func describeTemplates(options []TemplateOption) {
+	for i := range options {
+		if options[i].Description == "" {
+			options[i].Description = templateDescription(options[i].Path)
+		}
+	}
+}
+
+// renderDescription wraps a description to two lines of width w, indented
+// to sit under the template's provenance line.
+func renderDescription(d string, w int) string {
+	w = max(w-2, 20)
+	lines := strings.Split(lipgloss.NewStyle().Width(w).Render(d), "\n")
+	if len(lines) > 2 {
+		lines = lines[:2]
+		if lines[1] = truncateToRunes(strings.TrimRight(lines[1], " "), w-1); !strings.HasSuffix(lines[1], "…") {
+			lines[1] += "…"
+		}
+	}
+	for i, l := range lines {
+		lines[i] = "  " + strings.TrimRight(l, " ")
+	}
+	return formHintStyle.Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTemplateDescription(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	commented := write("k8s-worker.yaml", "#cloud-config\n# Kubernetes worker node\n# joined with kubeadm.\n#\n# Needs 2 CPUs.\npackages: [kubeadm]\n")
+	if got := templateDescription(commented); got != "Kubernetes worker node joined with kubeadm." {
+		t.Errorf("comment description = %q", got)
+	}
+
+	withReadme := write("k8s-worker-gpu.yaml", "#cloud-config\n# Worker\npackages: [kubeadm]\n")
+	write("k8s-worker-gpu.md", "# k8s-worker-gpu\n\n[![ci](badge.svg)](ci)\n\nWorker with the NVIDIA driver\nand container toolkit.\n\n## Usage\n")
+	if got := templateDescription(withReadme); got != "Worker with the NVIDIA driver and container toolkit." {
+		t.Errorf("README description = %q", got)
+	}
+
+	bare := write("bare.yaml", "#cloud-config\npackages: [git]\n# not a description\n")
+	if got := templateDescription(bare); got != "" {
+		t.Errorf("a template without leading comments has description %q", got)
+	}
+
+	long := renderDescription(strings.Repeat("word ", 100), 40)
+	if lines := strings.Split(long, "\n"); len(lines) != 2 || !strings.Contains(lines[1], "…") {
+		t.Errorf("long description should be cut to two lines:\n%s", long)
+	}
+}
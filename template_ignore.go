@@ -0,0 +1,113 @@
+// template_ignore.go - .passgoignore and template-ignore patterns for the template scan
+package main
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// templateIgnoreFile lists patterns of YAML files that are not templates,
+// in a local template directory or at the root of the template repo.
+const templateIgnoreFile = ".passgoignore"
+
+// defaultTemplateIgnore keeps CI configuration out of the picker. A
+// pattern can be brought back with !, e.g. !.github/.
+var defaultTemplateIgnore = []string{
+	".github/", ".gitlab/", ".circleci/", ".gitlab-ci.yml", ".travis.yml",
+	"azure-pipelines.yml", "bitbucket-pipelines.yml", ".pre-commit-config.yaml",
+}
+
+// ignoreRule is one pattern, in a subset of .gitignore syntax: a glob
+// (path.Match) that matches the file or directory name, or the path from
+// the root when it contains a slash. A trailing / matches directories
+// only, and a leading ! re-includes what earlier patterns ignored.
+type ignoreRule struct {
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// templateIgnore is an ordered rule list; the last matching rule wins.
+type templateIgnore []ignoreRule
+
+// parseIgnoreRules parses patterns, skipping blank lines and # comments.
+func parseIgnoreRules(lines []string) templateIgnore {
+	var rules templateIgnore
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var r ignoreRule
+		if strings.HasPrefix(line, "!") {
+			r.negate, line = true, line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly, line = true, strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			r.anchored, line = true, strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		r.pattern = line
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// ignored reports whether rel, a slash-separated path from the scan root,
+// is excluded. Directories are checked as the scan reaches them, so
+// anything under an ignored directory is never seen.
+func (ig templateIgnore) ignored(rel string, isDir bool) bool {
+	out := false
+	for _, r := range ig {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		name := path.Base(rel)
+		if r.anchored {
+			name = rel
+		}
+		if ok, _ := path.Match(r.pattern, name); ok {
+			out = !r.negate
+		}
+	}
+	return out
+}
+
+// loadTemplateIgnore combines the defaults, the template-ignore patterns
+// from .config and root's .passgoignore, in that order.
+func loadTemplateIgnore(root string, patterns []string) templateIgnore {
+	lines := append(append([]string(nil), defaultTemplateIgnore...), splitIgnorePatterns(patterns)...)
+	if f, err := os.Open(filepath.Join(root, templateIgnoreFile)); err == nil { // #nosec G304 -- template dir or cloned repo
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		_ = f.Close()
+	}
+	return parseIgnoreRules(lines)
+}
+
+// splitIgnorePatterns splits template-ignore values, each of which may
+// hold several patterns separated by commas or spaces.
+func splitIgnorePatterns(values []string) []string {
+	var patterns []string
+	for _, v := range values {
+		patterns = append(patterns, strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })...)
+	}
+	return patterns
+}
+
+// isHelmChart reports whether dir is a Helm chart, whose values and
+// templates are YAML but never cloud-init.
+func isHelmChart(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "Chart.yaml"))
+	return err == nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestTemplateIgnoreRules(t *testing.T) {
+	ig := parseIgnoreRules(append(append([]string(nil), defaultTemplateIgnore...),
+		"# comment", "", "*.ci.yml", "deploy/*.yaml", "build/", "!.github/"))
+	for rel, want := range map[string]bool{
+		"web.yaml":            false,
+		"lint.ci.yml":         true,
+		"sub/lint.ci.yml":     true,
+		"deploy/app.yaml":     true,
+		"sub/deploy/app.yaml": false,
+		".gitlab-ci.yml":      true,
+		"build":               false, // a file, not the directory
+	} {
+		if got := ig.ignored(rel, false); got != want {
+			t.Errorf("ignored(%q) = %v, want %v", rel, got, want)
+		}
+	}
+	if !ig.ignored("build", true) || !ig.ignored(".circleci", true) {
+		t.Error("build/ and .circleci/ should be ignored")
+	}
+	if ig.ignored(".github", true) {
+		t.Error("!.github/ should re-include the default")
+	}
+}
+
+func TestTemplateScansRespectIgnore(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"web.yaml":                     "#cloud-config\n",
+		"old.yaml":                     "#cloud-config\n",
+		"db.yaml":                      "#cloud-config\n",
+		".passgoignore":                "# not templates\nold.yaml\nwip/\n",
+		".github/workflows/ci.yml":     "on: push\n",
+		"wip/draft.yaml":               "#cloud-config\n",
+		"charts/app/Chart.yaml":        "apiVersion: v2\n",
+		"charts/app/values.yaml":       "replicas: 2\n",
+		"charts/app/templates/svc.yml": "kind: Service\n",
+		"nested/vm.yaml":               "#cloud-config\n",
+	} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	labels := func(opts []TemplateOption) []string {
+		var l []string
+		for _, o := range opts {
+			l = append(l, filepath.ToSlash(o.Label))
+		}
+		slices.Sort(l)
+		return l
+	}
+
+	local, err := scanCloudInitTemplateOptions([]string{dir}, []string{"db.yaml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := labels(local); !slices.Equal(got, []string{"web.yaml"}) {
+		t.Errorf("local templates = %v", got)
+	}
+
+	repo, err := scanRepoYAMLs(dir, nil, []string{"db.yaml"}, "repo", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := labels(repo); !slices.Equal(got, []string{"repo/nested/vm.yaml", "repo/web.yaml"}) {
+		t.Errorf("repo templates = %v", got)
+	}
+}
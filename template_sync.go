@@ -0,0 +1,188 @@
+// template_sync.go - Cloning the template repo, optionally only the directories that hold templates
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// parseRepoPaths parses template-repo-paths: directories of the template
+// repo, separated by commas or spaces, e.g. "cloud-init, k8s/templates".
+// Only those are checked out and scanned, so a large monorepo need not be
+// cloned whole just to list its YAML files.
+func parseRepoPaths(s string) ([]string, error) {
+	var paths []string
+	for _, p := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		p = path.Clean(strings.ReplaceAll(p, `\`, "/"))
+		if path.IsAbs(p) || p == "." || p == ".." || strings.HasPrefix(p, "../") {
+			return nil, fmt.Errorf("template repo path %q must be a directory inside the repo", p)
+		}
+		paths = append(paths, p)
+	}
+	if len(paths) == 0 {
+		return nil, errors.New("no template repo paths given")
+	}
+	return paths, nil
+}
+
+// runGit runs git with args, returning its stderr in the error.
+func runGit(args ...string) error {
+	cmd := exec.Command("git", args...) // #nosec G204 -- repo URL and paths from user .config
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s failed: %v; %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// cloneTemplateRepo makes a shallow clone of repoURL in dir, an empty
+// directory. With paths it is a sparse, blobless clone that only downloads
+// the files under paths (and the repo root); when git or the server cannot
+// do that, it falls back to a whole shallow clone.
+func cloneTemplateRepo(repoURL, dir string, paths []string) error {
+	if len(paths) > 0 {
+		err := runGit("clone", "--depth", "1", "--filter=blob:none", "--sparse", repoURL, dir)
+		if err == nil {
+			err = runGit(append([]string{"-C", dir, "sparse-checkout", "set", "--"}, paths...)...)
+		}
+		if err == nil {
+			return nil
+		}
+		if appLogger != nil {
+			appLogger.Printf("sparse clone of %s failed, cloning it whole: %v", repoURL, err)
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+		if err := os.Mkdir(dir, 0o700); err != nil {
+			return err
+		}
+	}
+	return runGit("clone", "--depth", "1", repoURL, dir)
+}
+
+// scanRepoYAMLs lists the .yml/.yaml files of a cloned repo, under paths
+// when given, as templates labelled repo/<path in repo>. A path missing
+// from the repo is logged and skipped. Files matching the ignore patterns
+// (see template_ignore.go) and Helm charts are left out.
+func scanRepoYAMLs(repoDir string, paths, ignore []string, source, commit string) ([]TemplateOption, error) {
+	ig := loadTemplateIgnore(repoDir, ignore)
+	roots := []string{repoDir}
+	if len(paths) > 0 {
+		roots = roots[:0]
+		for _, p := range paths {
+			root := filepath.Join(repoDir, filepath.FromSlash(p))
+			if info, err := os.Stat(root); err != nil || !info.IsDir() {
+				if appLogger != nil {
+					appLogger.Printf("template repo path %s not found in %s", p, source)
+				}
+				continue
+			}
+			roots = append(roots, root)
+		}
+	}
+
+	var options []TemplateOption
+	seen := make(map[string]bool)
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			rel, _ := filepath.Rel(repoDir, path)
+			if d.IsDir() {
+				if path != root && (d.Name() == ".git" || ig.ignored(filepath.ToSlash(rel), true) || isHelmChart(path)) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !isYAMLFileName(d.Name()) || seen[path] || ig.ignored(filepath.ToSlash(rel), false) {
+				return nil
+			}
+			seen[path] = true
+			options = append(options, TemplateOption{Label: "repo/" + rel, Path: path, Source: source, Commit: commit})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
+// cloudConfigKeys are top-level keys of common cloud-init modules. A repo
+// YAML without the #cloud-config header still counts as a template when it
+// sets one of them and none of notCloudConfigKeys.
+var cloudConfigKeys = map[string]bool{
+	"users": true, "groups": true, "packages": true, "package_update": true,
+	"package_upgrade": true, "package_reboot_if_required": true, "runcmd": true,
+	"bootcmd": true, "write_files": true, "ssh_authorized_keys": true,
+	"ssh_pwauth": true, "ssh_keys": true, "chpasswd": true, "hostname": true,
+	"fqdn": true, "timezone": true, "locale": true, "apt": true, "snap": true,
+	"mounts": true, "swap": true, "disk_setup": true, "fs_setup": true,
+	"ntp": true, "final_message": true, "power_state": true, "ca_certs": true,
+	"growpart": true, "manage_etc_hosts": true, "ansible": true, "yum_repos": true,
+}
+
+// notCloudConfigKeys mark Kubernetes manifests, CI pipelines and Compose
+// files, which can share keys such as users or packages.
+var notCloudConfigKeys = map[string]bool{
+	"apiVersion": true, "kind": true, "jobs": true, "stages": true,
+	"steps": true, "pipeline": true, "services": true,
+}
+
+// looksLikeCloudConfig reports whether the YAML at filePath is cloud-config:
+// it starts with the #cloud-config header, or its top-level keys pass the
+// heuristic above.
+func looksLikeCloudConfig(filePath string) bool {
+	if hasCloudConfigHeader(filePath) {
+		return true
+	}
+	f, err := os.Open(filePath) // #nosec G304 -- file in the cloned template repo
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	found := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' || line[0] == '-' {
+			continue
+		}
+		key, _, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.Trim(key, `"'`)
+		if notCloudConfigKeys[key] {
+			return false
+		}
+		found = found || cloudConfigKeys[key]
+	}
+	return found
+}
+
+// filterCloudConfigs keeps the options that look like cloud-config,
+// logging how many were hidden.
+func filterCloudConfigs(options []TemplateOption) []TemplateOption {
+	var kept []TemplateOption
+	for _, opt := range options {
+		if looksLikeCloudConfig(opt.Path) {
+			kept = append(kept, opt)
+		}
+	}
+	if hidden := len(options) - len(kept); hidden > 0 && appLogger != nil {
+		appLogger.Printf("hid %d repo yaml file(s) that are not cloud-config (template-repo-all=true shows them)", hidden)
+	}
+	return kept
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseRepoPaths(t *testing.T) {
+	paths, err := parseRepoPaths("cloud-init, k8s/templates/ ./web")
+	if err != nil || !slices.Equal(paths, []string{"cloud-init", "k8s/templates", "web"}) {
+		t.Errorf("parseRepoPaths = %v, %v", paths, err)
+	}
+	for _, bad := range []string{"", " , ", "/etc", "..", "../x", "."} {
+		if _, err := parseRepoPaths(bad); err == nil {
+			t.Errorf("parseRepoPaths(%q) should fail", bad)
+		}
+	}
+}
+
+// makeTemplateRepo commits files into a new git repo and returns its path.
+func makeTemplateRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	for name, body := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "templates"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v; %s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestCloneTemplateRepoPaths(t *testing.T) {
+	repo := makeTemplateRepo(t, map[string]string{
+		"root.yaml":             "#cloud-config\n",
+		"cloud-init/web.yaml":   "#cloud-config\n",
+		"cloud-init/db/pg.yml":  "#cloud-config\n",
+		"charts/app/values.yml": "replicas: 2\n",
+	})
+	labels := func(opts []TemplateOption) []string {
+		var l []string
+		for _, o := range opts {
+			l = append(l, filepath.ToSlash(o.Label))
+		}
+		slices.Sort(l)
+		return l
+	}
+
+	dir := t.TempDir()
+	if err := cloneTemplateRepo("file://"+repo, dir, []string{"cloud-init", "missing"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "charts")); err == nil {
+		t.Error("charts/ should not be checked out")
+	}
+	opts, err := scanRepoYAMLs(dir, []string{"cloud-init", "missing"}, nil, "repo", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := labels(opts); !slices.Equal(got, []string{"repo/cloud-init/db/pg.yml", "repo/cloud-init/web.yaml"}) {
+		t.Errorf("labels with paths = %v", got)
+	}
+
+	whole := t.TempDir()
+	if err := cloneTemplateRepo("file://"+repo, whole, nil); err != nil {
+		t.Fatal(err)
+	}
+	opts, err = scanRepoYAMLs(whole, nil, nil, "repo", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := labels(opts); len(got) != 4 {
+		t.Errorf("labels of the whole repo = %v", got)
+	}
+}
+
+func TestLooksLikeCloudConfig(t *testing.T) {
+	dir := t.TempDir()
+	for body, want := range map[string]bool{
+		"#cloud-config\nfoo: 1\n":                                 true,
+		"packages:\n  - nginx\nruncmd:\n  - [systemctl, start]\n": true,
+		"\"users\":\n  - default\n":                               true,
+		"apiVersion: v1\nkind: ConfigMap\ndata:\n  users: x\n":    false,
+		"users:\n  - a\njobs:\n  build: {}\n":                     false,
+		"replicas: 2\nimage: nginx\n":                             false,
+		"# users: commented out\nname: ci\n":                      false,
+	} {
+		p := filepath.Join(dir, "t.yaml")
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := looksLikeCloudConfig(p); got != want {
+			t.Errorf("looksLikeCloudConfig(%q) = %v, want %v", body, got, want)
+		}
+	}
+}
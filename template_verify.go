@@ -0,0 +1,205 @@
+// template_verify.go - Checksum and signature checks for repo templates
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Template repos may publish checksums of their templates in SHA256SUMS at
+// the repo root, in `sha256sum` format, optionally signed with a detached
+// GPG signature:
+//
+//	sha256sum *.yaml > SHA256SUMS
+//	gpg --armor --detach-sign SHA256SUMS   # writes SHA256SUMS.asc
+const templateSumsFile = "SHA256SUMS"
+
+// templateSigFiles are the detached signatures checked, first match wins.
+var templateSigFiles = []string{"SHA256SUMS.asc", "SHA256SUMS.sig"}
+
+// Template verification modes (template-verify in .config).
+const (
+	templateVerifyOff     = "off"     // no checks
+	templateVerifyWarn    = "warn"    // checks only if the repo publishes SHA256SUMS; failures need a second Enter
+	templateVerifyRequire = "require" // templates that do not verify are not offered
+)
+
+// templateVerifyMode reads template-verify from .config, defaulting to warn.
+func templateVerifyMode(lookup func(string) (string, bool)) string {
+	v, _ := lookup("template-verify")
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case templateVerifyOff:
+		return templateVerifyOff
+	case templateVerifyRequire:
+		return templateVerifyRequire
+	}
+	return templateVerifyWarn
+}
+
+// parseChecksums parses sha256sum output into repo-relative path -> hex
+// digest. Both text ("hash  path") and binary ("hash *path") lines are
+// accepted.
+func parseChecksums(data string) (map[string]string, error) {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		digest, name, ok := strings.Cut(line, " ")
+		name = strings.TrimPrefix(strings.TrimLeft(name, " "), "*")
+		if _, err := hex.DecodeString(digest); !ok || err != nil || len(digest) != sha256.Size*2 || name == "" {
+			return nil, fmt.Errorf("%s line %d: want \"<sha256>  <path>\"", templateSumsFile, n)
+		}
+		sums[path.Clean(strings.TrimPrefix(name, "./"))] = strings.ToLower(digest)
+	}
+	return sums, scanner.Err()
+}
+
+// fileSHA256 returns the hex SHA-256 digest of a file.
+func fileSHA256(filePath string) (string, error) {
+	f, err := os.Open(filePath) // #nosec G304 -- template found in the cloned repo
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// gpgVerify checks a detached signature and returns gpg's status output
+// (--status-fd). A variable so tests can stub it.
+var gpgVerify = func(sigPath, dataPath string) (string, error) {
+	cmd := exec.Command("gpg", "--batch", "--status-fd", "1", "--verify", sigPath, dataPath) // #nosec G204 -- files in the cloned template repo
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return stdout.String(), errors.New(msg)
+	}
+	return stdout.String(), nil
+}
+
+// signedBy reports whether gpg status output holds a good signature from
+// fingerprint (case and spaces ignored; a long key ID suffix also matches).
+func signedBy(status, fingerprint string) bool {
+	want := strings.ToUpper(strings.ReplaceAll(fingerprint, " ", ""))
+	for _, line := range strings.Split(status, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] != "[GNUPG:]" || fields[1] != "VALIDSIG" {
+			continue
+		}
+		// VALIDSIG <signing key fpr> ... <primary key fpr>
+		for _, fpr := range []string{fields[2], fields[len(fields)-1]} {
+			if strings.HasSuffix(strings.ToUpper(fpr), want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// repoChecksums loads and, when signed (or signingKey is set), authenticates
+// the repo's SHA256SUMS. It returns nil sums without error when the repo
+// publishes none.
+func repoChecksums(repoDir, signingKey string) (map[string]string, error) {
+	sumsPath := filepath.Join(repoDir, templateSumsFile)
+	data, err := os.ReadFile(sumsPath) // #nosec G304 -- file in the cloned template repo
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sums, err := parseChecksums(string(data))
+	if err != nil {
+		return nil, err
+	}
+	sigPath := ""
+	for _, name := range templateSigFiles {
+		if _, err := os.Stat(filepath.Join(repoDir, name)); err == nil {
+			sigPath = filepath.Join(repoDir, name)
+			break
+		}
+	}
+	if sigPath == "" {
+		if signingKey != "" {
+			return nil, fmt.Errorf("%s is not signed (template-signing-key is set)", templateSumsFile)
+		}
+		return sums, nil
+	}
+	status, err := gpgVerify(sigPath, sumsPath)
+	if err != nil {
+		return nil, fmt.Errorf("bad signature on %s: %v", templateSumsFile, err)
+	}
+	if signingKey != "" && !signedBy(status, signingKey) {
+		return nil, fmt.Errorf("%s is not signed by %s", templateSumsFile, signingKey)
+	}
+	return sums, nil
+}
+
+// verifyRepoTemplates checks the templates cloned into repoDir against the
+// repo's checksums. In warn mode, templates that fail keep a Warning; in
+// require mode they are dropped. It returns the options to offer and one
+// message per problem, for the log.
+func verifyRepoTemplates(repoDir string, options []TemplateOption, mode, signingKey string) ([]TemplateOption, []string) {
+	if mode == templateVerifyOff {
+		return options, nil
+	}
+	sums, repoErr := repoChecksums(repoDir, signingKey)
+	if repoErr == nil && sums == nil {
+		if mode != templateVerifyRequire {
+			return options, nil
+		}
+		repoErr = fmt.Errorf("no %s in the template repo", templateSumsFile)
+	}
+
+	var kept []TemplateOption
+	var problems []string
+	for _, opt := range options {
+		problem := ""
+		if repoErr != nil {
+			problem = repoErr.Error()
+		} else {
+			rel, _ := filepath.Rel(repoDir, opt.Path)
+			want, listed := sums[filepath.ToSlash(rel)]
+			got, err := fileSHA256(opt.Path)
+			switch {
+			case !listed:
+				problem = "not listed in " + templateSumsFile
+			case err != nil:
+				problem = err.Error()
+			case got != want:
+				problem = "checksum mismatch"
+			}
+		}
+		if problem == "" {
+			kept = append(kept, opt)
+			continue
+		}
+		problems = append(problems, opt.Label+": "+problem)
+		if mode == templateVerifyRequire {
+			continue
+		}
+		opt.Warning = "Template " + opt.Label + " failed verification: " + problem
+		kept = append(kept, opt)
+	}
+	return kept, problems
+}
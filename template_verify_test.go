@@ -0,0 +1,140 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// templateRepo writes files into a temp dir and returns it with the
+// template options for its YAML files.
+func templateRepo(t *testing.T, files map[string]string) (string, []TemplateOption) {
+	t.Helper()
+	dir := t.TempDir()
+	var opts []TemplateOption
+	for name, body := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasSuffix(name, ".yaml") {
+			opts = append(opts, TemplateOption{Label: "repo/" + name, Path: p})
+		}
+	}
+	return dir, opts
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestParseChecksums(t *testing.T) {
+	a, b := sha256Hex("a"), sha256Hex("b")
+	sums, err := parseChecksums(a + "  web.yaml\n" + strings.ToUpper(b) + " *./k8s/node.yaml\n\n# comment\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sums["web.yaml"] != a || sums["k8s/node.yaml"] != b {
+		t.Errorf("sums = %v", sums)
+	}
+	for _, bad := range []string{"abc  web.yaml", a, "zz" + a[2:] + "  web.yaml"} {
+		if _, err := parseChecksums(bad); err == nil {
+			t.Errorf("parseChecksums(%q) accepted", bad)
+		}
+	}
+}
+
+func TestVerifyRepoTemplates(t *testing.T) {
+	good, bad := "#cloud-config\npackages: [nginx]\n", "#cloud-config\nruncmd: [evil]\n"
+	dir, opts := templateRepo(t, map[string]string{
+		"web.yaml":       good,
+		"db.yaml":        bad,
+		"extra/x.yaml":   good,
+		templateSumsFile: sha256Hex(good) + "  web.yaml\n" + sha256Hex(good) + "  db.yaml\n",
+	})
+
+	kept, problems := verifyRepoTemplates(dir, opts, templateVerifyWarn, "")
+	if len(kept) != 3 || len(problems) != 2 {
+		t.Fatalf("warn: kept %d, problems %v", len(kept), problems)
+	}
+	for _, o := range kept {
+		switch o.Label {
+		case "repo/web.yaml":
+			if o.Warning != "" {
+				t.Errorf("web.yaml warned: %s", o.Warning)
+			}
+		case "repo/db.yaml":
+			if !strings.Contains(o.Warning, "checksum mismatch") {
+				t.Errorf("db.yaml warning = %q", o.Warning)
+			}
+		case "repo/extra/x.yaml":
+			if !strings.Contains(o.Warning, "not listed") {
+				t.Errorf("x.yaml warning = %q", o.Warning)
+			}
+		}
+	}
+
+	kept, _ = verifyRepoTemplates(dir, opts, templateVerifyRequire, "")
+	if len(kept) != 1 || kept[0].Label != "repo/web.yaml" {
+		t.Errorf("require kept %+v, want only web.yaml", kept)
+	}
+
+	if kept, problems := verifyRepoTemplates(dir, opts, templateVerifyOff, ""); len(kept) != 3 || problems != nil {
+		t.Errorf("off: kept %d, problems %v", len(kept), problems)
+	}
+}
+
+func TestVerifyRepoTemplatesWithoutSums(t *testing.T) {
+	dir, opts := templateRepo(t, map[string]string{"web.yaml": "#cloud-config\n"})
+	if kept, problems := verifyRepoTemplates(dir, opts, templateVerifyWarn, ""); len(kept) != 1 || kept[0].Warning != "" || problems != nil {
+		t.Errorf("warn without sums: kept %+v, problems %v", kept, problems)
+	}
+	if kept, problems := verifyRepoTemplates(dir, opts, templateVerifyRequire, ""); len(kept) != 0 || len(problems) != 1 {
+		t.Errorf("require without sums: kept %+v, problems %v", kept, problems)
+	}
+}
+
+func TestVerifyRepoTemplatesSignature(t *testing.T) {
+	body := "#cloud-config\n"
+	dir, opts := templateRepo(t, map[string]string{
+		"web.yaml":       body,
+		templateSumsFile: sha256Hex(body) + "  web.yaml\n",
+		"SHA256SUMS.asc": "sig",
+	})
+	orig := gpgVerify
+	t.Cleanup(func() { gpgVerify = orig })
+
+	const fpr = "0123456789ABCDEF0123456789ABCDEF01234567"
+	gpgVerify = func(sig, data string) (string, error) {
+		return "[GNUPG:] GOODSIG 89ABCDEF01234567 Team\n[GNUPG:] VALIDSIG " + fpr + " 2026-01-01 0 4 0 1 10 00 " + fpr + "\n", nil
+	}
+	if kept, problems := verifyRepoTemplates(dir, opts, templateVerifyRequire, "89ab cdef 0123 4567"); len(kept) != 1 || problems != nil {
+		t.Errorf("good signature: kept %+v, problems %v", kept, problems)
+	}
+	if kept, _ := verifyRepoTemplates(dir, opts, templateVerifyRequire, "FFFFFFFFFFFFFFFF"); len(kept) != 0 {
+		t.Error("signature from another key was accepted")
+	}
+
+	gpgVerify = func(sig, data string) (string, error) { return "", errors.New("BAD signature") }
+	kept, _ := verifyRepoTemplates(dir, opts, templateVerifyWarn, "")
+	if len(kept) != 1 || !strings.Contains(kept[0].Warning, "bad signature") {
+		t.Errorf("bad signature: kept %+v", kept)
+	}
+}
+
+func TestTemplateVerifyMode(t *testing.T) {
+	for v, want := range map[string]string{"": templateVerifyWarn, "Require": templateVerifyRequire, "off": templateVerifyOff, "bogus": templateVerifyWarn} {
+		got := templateVerifyMode(func(string) (string, bool) { return v, v != "" })
+		if got != want {
+			t.Errorf("template-verify=%q -> %q, want %q", v, got, want)
+		}
+	}
+}
@@ -18,6 +18,8 @@ type theme struct {
 	Stopped     lipgloss.Color
 	Suspended   lipgloss.Color
 	Deleted     lipgloss.Color
+	Warn        lipgloss.Color // usage bars past usage-warn; Suspended when unset
+	Critical    lipgloss.Color // usage bars past usage-critical; Stopped when unset
 }
 
 // themes is the list of available themes, selectable via keys 1–0.
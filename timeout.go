@@ -0,0 +1,121 @@
+// timeout.go - Per-command-class deadlines for multipass commands
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// commandClass groups multipass verbs by how long they normally take.
+type commandClass int
+
+const (
+	classFast   commandClass = iota // list, info, get, version…
+	classMedium                     // start, stop, snapshot, mount…
+	classSlow                       // launch, restore, transfer, exec
+)
+
+func (c commandClass) String() string {
+	return [...]string{"fast", "medium", "slow"}[c]
+}
+
+// classOf returns the class of a multipass verb. Unknown verbs are medium.
+func classOf(verb string) commandClass {
+	switch verb {
+	case "list", "info", "get", "version", "networks", "find", "aliases":
+		return classFast
+	case "launch", "restore", "clone", "transfer", "exec":
+		return classSlow
+	}
+	return classMedium
+}
+
+// commandTimeouts is the deadline for each class; zero means none.
+// Configured in .config as timeout-fast, timeout-medium and timeout-slow,
+// e.g. timeout-slow=45m, or off to disable.
+type commandTimeouts [3]time.Duration
+
+var defaultCommandTimeouts = commandTimeouts{
+	classFast:   30 * time.Second,
+	classMedium: 5 * time.Minute,
+	classSlow:   30 * time.Minute,
+}
+
+// multipassTimeouts is applied by runMultipassCommand; main loads it from .config.
+var multipassTimeouts = defaultCommandTimeouts
+
+// parseCommandTimeout parses a timeout-* value: a duration such as 90s or
+// 10m, a bare number of seconds, or off/0 for no timeout.
+func parseCommandTimeout(v string) (time.Duration, error) {
+	v = strings.TrimSpace(v)
+	if strings.EqualFold(v, "off") || v == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		d, err = time.ParseDuration(v + "s")
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("want a duration such as 90s or 10m, or off")
+	}
+	return d, nil
+}
+
+// loadCommandTimeouts reads the timeouts using lookup (normally configValue).
+// Invalid values keep the default.
+func loadCommandTimeouts(lookup func(string) (string, bool)) commandTimeouts {
+	t := defaultCommandTimeouts
+	for c := classFast; c <= classSlow; c++ {
+		if v, ok := lookup("timeout-" + c.String()); ok {
+			if d, err := parseCommandTimeout(v); err == nil {
+				t[c] = d
+			}
+		}
+	}
+	return t
+}
+
+// forArgs returns the deadline for a multipass argument list.
+func (t commandTimeouts) forArgs(args []string) time.Duration {
+	if len(args) == 0 {
+		return t[classMedium]
+	}
+	return t[classOf(args[0])]
+}
+
+// multipassContext returns the context a multipass command runs under.
+// It is created after the VM lock is taken, so time spent queued does not
+// count against the deadline.
+func multipassContext(args []string) (context.Context, context.CancelFunc) {
+	if d := multipassTimeouts.forArgs(args); d > 0 {
+		return context.WithTimeout(context.Background(), d)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// errCommandTimeout is wrapped by the errors of commands stopped by their
+// deadline.
+var errCommandTimeout = errors.New("timed out")
+
+// timeoutError replaces the "signal: killed" error of a command stopped by
+// its deadline with one that says what happened.
+func timeoutError(ctx context.Context, args []string, err error) error {
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%w after %s (raise timeout-%s in .config if multipass needs longer)",
+			errCommandTimeout, multipassTimeouts.forArgs(args), classOf(args[0]))
+	}
+	return err
+}
+
+// operationClass maps a busy-row operation ("Creating", "Stopping"…) to the
+// class of the command behind it.
+func operationClass(operation string) commandClass {
+	switch operation {
+	case "Creating", "Backing up":
+		return classSlow
+	}
+	return classMedium
+}
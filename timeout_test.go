@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadCommandTimeouts(t *testing.T) {
+	values := map[string]string{"timeout-fast": "10s", "timeout-medium": "off", "timeout-slow": "bogus"}
+	got := loadCommandTimeouts(func(k string) (string, bool) { v, ok := values[k]; return v, ok })
+	want := commandTimeouts{classFast: 10 * time.Second, classMedium: 0, classSlow: defaultCommandTimeouts[classSlow]}
+	if got != want {
+		t.Errorf("loadCommandTimeouts = %v, want %v", got, want)
+	}
+}
+
+func TestParseCommandTimeout(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"90s", 90 * time.Second, false},
+		{"10m", 10 * time.Minute, false},
+		{"45", 45 * time.Second, false},
+		{"OFF", 0, false},
+		{"0", 0, false},
+		{"-5s", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseCommandTimeout(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseCommandTimeout(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestCommandTimeoutForArgs(t *testing.T) {
+	timeouts := commandTimeouts{classFast: 1, classMedium: 2, classSlow: 3}
+	tests := map[string]time.Duration{"list": 1, "info": 1, "stop": 2, "snapshot": 2, "launch": 3, "restore": 3, "exec": 3}
+	for verb, want := range tests {
+		if got := timeouts.forArgs([]string{verb, "vm"}); got != want {
+			t.Errorf("forArgs(%s) = %v, want %v", verb, got, want)
+		}
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	err := timeoutError(ctx, []string{"launch"}, errors.New("signal: killed"))
+	if err == nil || !strings.Contains(err.Error(), "timeout-slow") {
+		t.Errorf("timeoutError = %v, want a hint about timeout-slow", err)
+	}
+
+	plain := errors.New("exit status 1")
+	if err := timeoutError(context.Background(), []string{"stop"}, plain); err != plain {
+		t.Errorf("timeoutError changed a normal failure: %v", err)
+	}
+}
+
+func TestBusyInfoStillWorking(t *testing.T) {
+	b := busyInfo{operation: "Stopping", startTime: time.Now().Add(-time.Minute)}
+	if msg := b.phaseMessage(); !strings.HasPrefix(msg, "Still working…") || !strings.Contains(msg, "gives up in") {
+		t.Errorf("phaseMessage after a minute = %q", msg)
+	}
+}
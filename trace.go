@@ -0,0 +1,110 @@
+// trace.go - Operation IDs tying UI actions to the multipass commands they run
+package main
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// opTraceTable knows which operations are running on which instances, so
+// the exec layer can tag each multipass command's log lines with the
+// operation that ran it without every helper passing the ID along.
+type opTraceTable struct {
+	mu  sync.Mutex
+	ops map[string][]string // instance → IDs of operations running on it
+}
+
+// opTraces is the process-wide table used by the exec log lines.
+var opTraces = &opTraceTable{ops: make(map[string][]string)}
+
+// newOpID returns a short ID such as "op-k3x9a2".
+func newOpID() string {
+	return "op-" + randomString(6)
+}
+
+func (t *opTraceTable) add(id string, names []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, name := range names {
+		t.ops[name] = append(t.ops[name], id)
+	}
+}
+
+func (t *opTraceTable) remove(id string, names []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, name := range names {
+		ids := slices.DeleteFunc(t.ops[name], func(s string) bool { return s == id })
+		if len(ids) == 0 {
+			delete(t.ops, name)
+		} else {
+			t.ops[name] = ids
+		}
+	}
+}
+
+// forArgs returns the IDs of operations running on an instance named in a
+// multipass command line, comma separated, or "" for none. Two operations
+// queued on one instance are both listed.
+func (t *opTraceTable) forArgs(args []string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.ops) == 0 {
+		return ""
+	}
+	var ids []string
+	for _, a := range args {
+		if a == "--" {
+			break
+		}
+		// vm, vm.snapshot and vm:path all name vm.
+		name, _, _ := strings.Cut(a, ":")
+		name, _, _ = strings.Cut(name, ".")
+		for _, id := range t.ops[name] {
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return strings.Join(ids, ",")
+}
+
+// traceOp runs fn as operation op on names under a new operation ID,
+// logging its start and end, and returns the ID with fn's error.
+func traceOp(op string, names []string, fn func() error) (string, error) {
+	id := newOpID()
+	opTraces.add(id, names)
+	defer opTraces.remove(id, names)
+	if appLogger != nil {
+		appLogger.Printf("[%s] %s %s: started", id, op, strings.Join(names, " "))
+	}
+	start := time.Now()
+	err := fn()
+	if appLogger != nil {
+		if err != nil {
+			appLogger.Printf("[%s] %s failed after %s: %v", id, op, time.Since(start).Round(time.Millisecond), err)
+		} else {
+			appLogger.Printf("[%s] %s done in %s", id, op, time.Since(start).Round(time.Millisecond))
+		}
+	}
+	return id, err
+}
+
+// logPrefix is the tag exec log lines start with for args.
+func logPrefix(args []string) string {
+	if id := opTraces.forArgs(args); id != "" {
+		return "[" + id + "] "
+	}
+	return ""
+}
+
+// traceVMOp is traceOp for a helper acting on one instance whose output is
+// not needed.
+func traceVMOp(op, name string, fn func() (string, error)) (string, error) {
+	return traceOp(op, []string{name}, func() error {
+		_, err := fn()
+		return err
+	})
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestOpTracesForArgs(t *testing.T) {
+	tbl := &opTraceTable{ops: make(map[string][]string)}
+	tbl.add("op-a", []string{"web"})
+	tbl.add("op-b", []string{"web", "db"})
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"stop", "web"}, "op-a,op-b"},
+		{[]string{"restore", "db.snap1"}, "op-b"},
+		{[]string{"umount", "db:/home/ubuntu/src"}, "op-b"},
+		{[]string{"exec", "api", "--", "echo", "web"}, ""},
+		{[]string{"list", "--format", "json"}, ""},
+	}
+	for _, tt := range tests {
+		if got := tbl.forArgs(tt.args); got != tt.want {
+			t.Errorf("forArgs(%v) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	tbl.remove("op-b", []string{"web", "db"})
+	if got := tbl.forArgs([]string{"stop", "web", "db"}); got != "op-a" {
+		t.Errorf("after remove = %q, want op-a", got)
+	}
+	tbl.remove("op-a", []string{"web"})
+	if len(tbl.ops) != 0 {
+		t.Errorf("table not emptied: %v", tbl.ops)
+	}
+}
+
+func TestTraceOpLogs(t *testing.T) {
+	var buf bytes.Buffer
+	old := appLogger
+	appLogger = log.New(&buf, "", 0)
+	defer func() { appLogger = old }()
+
+	var inside string
+	id, err := traceOp("stop", []string{"web"}, func() error {
+		inside = logPrefix([]string{"stop", "web"})
+		return errors.New("boom")
+	})
+	if err == nil || !strings.HasPrefix(id, "op-") {
+		t.Fatalf("traceOp = %q, %v", id, err)
+	}
+	if inside != "["+id+"] " {
+		t.Errorf("prefix inside the operation = %q", inside)
+	}
+	if got := logPrefix([]string{"stop", "web"}); got != "" {
+		t.Errorf("prefix after the operation = %q", got)
+	}
+	if lines := strings.Count(buf.String(), "["+id+"]"); lines != 2 || !strings.Contains(buf.String(), "failed") {
+		t.Errorf("log = %q", buf.String())
+	}
+}
@@ -0,0 +1,113 @@
+// userdata.go - Saving the cloud-init user-data a VM was launched with as a local template
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// userDataPath is where cloud-init keeps the user-data of the current
+// instance. Only root can read it.
+const userDataPath = "/var/lib/cloud/instance/user-data.txt"
+
+// FetchUserData reads the user-data the running VM vm was launched with.
+func FetchUserData(vm string) (string, error) {
+	return ExecInVM(vm, "sudo", "cat", userDataPath)
+}
+
+// userDataTemplate turns the user-data of vm into a local template: it must
+// be a cloud-config, and a comment saying where it came from is added below
+// the header, where Advanced Create picks up template descriptions.
+func userDataTemplate(data, vm string, now time.Time) (string, error) {
+	data = strings.TrimSpace(data)
+	header, body, _ := strings.Cut(data, "\n")
+	switch header = strings.TrimSpace(header); {
+	case data == "":
+		return "", fmt.Errorf("%s was launched without user-data", vm)
+	case strings.HasPrefix(strings.ToLower(header), "content-type: multipart"):
+		return "", fmt.Errorf("%s has multi-part user-data; only a single #cloud-config can be saved as a template", vm)
+	case header != "#cloud-config":
+		return "", fmt.Errorf("the user-data of %s is not a cloud-config (it starts with %q)", vm, truncateToRunes(header, 40))
+	}
+	note := fmt.Sprintf("# Imported from VM %s on %s.", vm, now.Format("2006-01-02"))
+	return header + "\n" + note + "\n" + body + "\n", nil
+}
+
+// userDataTemplateName checks a template file name, adding .yaml when it
+// has no YAML extension.
+func userDataTemplateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return "", errors.New("enter a file name")
+	case strings.ContainsAny(name, `/\`) || name == "." || name == "..":
+		return "", fmt.Errorf("%q must be a file name, not a path", name)
+	case strings.HasPrefix(name, "."):
+		return "", fmt.Errorf("%q would be a hidden file", name)
+	}
+	if !isYAMLFileName(name) {
+		name += ".yaml"
+	}
+	return name, nil
+}
+
+// saveUserDataTemplate writes text to name in dir, which passgo searches
+// for templates. An existing file is never overwritten. The file is
+// private: the user-data may hold rendered secrets.
+func saveUserDataTemplate(dir, name, text string) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600) // #nosec G304 -- name checked by userDataTemplateName
+	if errors.Is(err, os.ErrExist) {
+		return "", fmt.Errorf("%s already exists", path)
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		_ = os.Remove(path)
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// ImportUserData saves the user-data of the running VM vm as the template
+// name in the passgo config directory and returns its path.
+func ImportUserData(vm, name string, now time.Time) (string, error) {
+	dir, err := userConfigDir()
+	if err != nil {
+		return "", err
+	}
+	data, err := FetchUserData(vm)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", userDataPath, err)
+	}
+	text, err := userDataTemplate(data, vm, now)
+	if err != nil {
+		return "", err
+	}
+	return saveUserDataTemplate(dir, name, text)
+}
+
+// userDataImportedMsg reports a finished import.
+type userDataImportedMsg struct {
+	vmName string
+	path   string
+	err    error
+}
+
+func importUserDataCmd(vm, name string) tea.Cmd {
+	return func() tea.Msg {
+		path, err := ImportUserData(vm, name, time.Now())
+		return userDataImportedMsg{vmName: vm, path: path, err: err}
+	}
+}
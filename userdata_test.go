@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUserDataTemplate(t *testing.T) {
+	now := time.Date(2026, time.October, 15, 9, 0, 0, 0, time.UTC)
+	got, err := userDataTemplate("#cloud-config\npackages:\n  - nginx\n", "web", now)
+	want := "#cloud-config\n# Imported from VM web on 2026-10-15.\npackages:\n  - nginx\n"
+	if err != nil || got != want {
+		t.Fatalf("got %q, %v", got, err)
+	}
+	for _, data := range []string{"", "  \n", "#!/bin/bash\necho hi", "Content-Type: multipart/mixed; boundary=x\n\n--x"} {
+		if _, err := userDataTemplate(data, "web", now); err == nil {
+			t.Errorf("expected error for %q", data)
+		}
+	}
+}
+
+func TestUserDataTemplateName(t *testing.T) {
+	for in, want := range map[string]string{"web": "web.yaml", " web.yml ": "web.yml", "web.YAML": "web.YAML", "web.conf": "web.conf.yaml"} {
+		if got, err := userDataTemplateName(in); err != nil || got != want {
+			t.Errorf("userDataTemplateName(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "../web", "dir/web", `dir\web`, ".hidden", ".."} {
+		if _, err := userDataTemplateName(in); err == nil {
+			t.Errorf("expected error for %q", in)
+		}
+	}
+}
+
+func TestImportUserData(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	f := &fakeRunner{outputs: map[string]string{"exec": "#cloud-config\nruncmd:\n  - echo hi\n"}}
+	useFakeRunner(t, f)
+
+	path, err := ImportUserData("web", "web.yaml", time.Now())
+	if err != nil || path != filepath.Join(home, ".passgo", "web.yaml") {
+		t.Fatalf("ImportUserData = %q, %v", path, err)
+	}
+	if got := strings.Join(f.calls[0], " "); !strings.HasSuffix(got, "sudo cat "+userDataPath) {
+		t.Errorf("exec call = %q", got)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("saved file: %v, %v", info, err)
+	}
+	opts, err := scanCloudInitTemplateOptions([]string{filepath.Dir(path)}, nil)
+	if err != nil || len(opts) != 1 || opts[0].Label != "web.yaml" {
+		t.Fatalf("template not listed: %v, %v", opts, err)
+	}
+
+	if _, err := ImportUserData("web", "web.yaml", time.Now()); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("second import error = %v, want already exists", err)
+	}
+}
@@ -3,6 +3,9 @@ package main
 
 import (
 	"crypto/rand"
+	"os"
+	"path/filepath"
+	"strings"
 	"unicode/utf8"
 )
 
@@ -25,6 +28,19 @@ func truncateToRunes(s string, maxRunes int) string {
 	return s + "…"
 }
 
+// expandHomePath replaces a leading "~/" in path with the home directory.
+func expandHomePath(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, rest), nil
+}
+
 // randomString generates random VM names like "VM-a1b2"
 func randomString(length int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
@@ -0,0 +1,171 @@
+// view_adopt.go - Dialog offering to adopt a VM created outside passgo
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Cursor positions of the adopt dialog.
+const (
+	adoptFieldTags = iota
+	adoptFieldNotes
+	adoptFieldEvery
+	adoptFieldKeep
+	adoptFieldAdopt
+	adoptFieldSkip
+	adoptFieldIgnore
+)
+
+// adoptModel asks whether to adopt one unknown VM.
+type adoptModel struct {
+	vm         VMInfo
+	remaining  []string // further unknown VMs after this one
+	tagsInput  textinput.Model
+	notesInput textinput.Model
+	everyInput textinput.Model
+	keepInput  textinput.Model
+	cursor     int
+	errMsg     string
+	width      int
+	height     int
+}
+
+func newAdoptModel(vm VMInfo, remaining []string, w, h int) adoptModel {
+	ti := textinput.New()
+	ti.Placeholder = "e.g. team-a, ci"
+	ti.CharLimit = 200
+	ni := textinput.New()
+	ni.Placeholder = "optional"
+	ni.CharLimit = 500
+	ei := textinput.New()
+	ei.Placeholder = "none (e.g. 24h, 7d)"
+	ei.CharLimit = 10
+	ki := textinput.New()
+	ki.Placeholder = "7"
+	ki.CharLimit = 3
+	m := adoptModel{vm: vm, remaining: remaining, tagsInput: ti, notesInput: ni, everyInput: ei, keepInput: ki, width: w, height: h}
+	m.syncFocus()
+	return m
+}
+
+func (m adoptModel) Init() tea.Cmd { return textinput.Blink }
+
+func (m adoptModel) decide(action string, a adoption) tea.Cmd {
+	msg := adoptDecisionMsg{vm: m.vm, action: action, adoption: a, remaining: m.remaining}
+	return func() tea.Msg { return msg }
+}
+
+func (m adoptModel) Update(msg tea.Msg) (adoptModel, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			return m, m.decide("skip", adoption{})
+		case "tab", "down":
+			m.cursor = min(m.cursor+1, adoptFieldIgnore)
+			m.syncFocus()
+			return m, nil
+		case "shift+tab", "up":
+			m.cursor = max(m.cursor-1, 0)
+			m.syncFocus()
+			return m, nil
+		case "left", "right":
+			if m.cursor >= adoptFieldAdopt {
+				if key.String() == "left" {
+					m.cursor = max(m.cursor-1, adoptFieldAdopt)
+				} else {
+					m.cursor = min(m.cursor+1, adoptFieldIgnore)
+				}
+				return m, nil
+			}
+		case "enter":
+			switch m.cursor {
+			case adoptFieldSkip:
+				return m, m.decide("skip", adoption{})
+			case adoptFieldIgnore:
+				return m, m.decide("ignore", adoption{})
+			}
+			every, keep, err := parseAdoptSchedule(m.everyInput.Value(), m.keepInput.Value())
+			if err != nil {
+				m.errMsg = err.Error()
+				return m, nil
+			}
+			return m, m.decide("adopt", adoption{
+				tags:          parseTags(m.tagsInput.Value()),
+				notes:         strings.TrimSpace(m.notesInput.Value()),
+				snapshotEvery: every,
+				snapshotKeep:  keep,
+			})
+		}
+	}
+	var cmd tea.Cmd
+	switch m.cursor {
+	case adoptFieldTags:
+		m.tagsInput, cmd = m.tagsInput.Update(msg)
+	case adoptFieldNotes:
+		m.notesInput, cmd = m.notesInput.Update(msg)
+	case adoptFieldEvery:
+		m.everyInput, cmd = m.everyInput.Update(msg)
+		m.errMsg = ""
+	case adoptFieldKeep:
+		m.keepInput, cmd = m.keepInput.Update(msg)
+		m.errMsg = ""
+	}
+	return m, cmd
+}
+
+func (m *adoptModel) syncFocus() {
+	inputs := []*textinput.Model{&m.tagsInput, &m.notesInput, &m.everyInput, &m.keepInput}
+	for i, in := range inputs {
+		if i == m.cursor {
+			in.Focus()
+		} else {
+			in.Blur()
+		}
+	}
+}
+
+func (m adoptModel) View() string {
+	title := formTitleStyle.Render("Adopt " + m.vm.Name + "?")
+	intro := fmt.Sprintf("%s was created outside passgo (%s, %s).\nAdopt it to track its resources in quotas and attach tags, notes and snapshots.",
+		m.vm.Name, orDashes(m.vm.State), orDashes(m.vm.Release))
+
+	field := func(idx int, label string, input textinput.Model) string {
+		l := formLabelStyle.Width(16).Render(label)
+		v := formValueStyle.Render(input.Value())
+		if input.Value() == "" {
+			v = lipgloss.NewStyle().Foreground(subtle).Render(input.Placeholder)
+		}
+		if m.cursor == idx {
+			l = formActiveLabelStyle.Width(16).Render(label)
+			v = input.View()
+		}
+		return "  " + l + v + "\n"
+	}
+	button := func(idx int, label string) string {
+		if m.cursor == idx {
+			return formActiveButtonStyle.Render(label)
+		}
+		return formButtonStyle.Render(label)
+	}
+
+	content := title + "\n\n" + lipgloss.NewStyle().Foreground(subtle).Render(intro) + "\n\n" +
+		field(adoptFieldTags, "Tags:", m.tagsInput) +
+		field(adoptFieldNotes, "Notes:", m.notesInput) +
+		field(adoptFieldEvery, "Snapshot every:", m.everyInput) +
+		field(adoptFieldKeep, "Keep:", m.keepInput) +
+		"\n  " + button(adoptFieldAdopt, "[ Adopt ]") + "  " + button(adoptFieldSkip, "[ Skip ]") + "  " +
+		button(adoptFieldIgnore, fmt.Sprintf("[ Ignore all (%d) ]", len(m.remaining)+1)) + "\n"
+	if len(m.remaining) > 0 {
+		content += "\n" + lipgloss.NewStyle().Foreground(subtle).Render(fmt.Sprintf("%d more unknown VM(s) after this one", len(m.remaining))) + "\n"
+	}
+	content += "\n" + formHintStyle.Render("Tab/↑↓: navigate  ←→: buttons  Enter: choose  Esc: skip")
+	if m.errMsg != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(stoppedClr).Render(m.errMsg)
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalStyle.Render(content))
+}
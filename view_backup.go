@@ -0,0 +1,145 @@
+// view_backup.go - Backup paths from a VM and restore earlier archives
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// backupModel edits the paths to back up and lists the VM's archives.
+// Cursor 0 is the paths input, 1 the Back up button, 2+ the archives.
+type backupModel struct {
+	vmName     string
+	dir        string
+	pathsInput textinput.Model
+	archives   []backupArchive
+	cursor     int
+	errMsg     string
+	width      int
+	height     int
+}
+
+// backupRequestMsg asks the root model to start a backup.
+type backupRequestMsg struct {
+	vmName string
+	paths  []string
+	dir    string
+}
+
+// backupRestoreRequestMsg asks the root model to confirm and run a restore.
+type backupRestoreRequestMsg struct {
+	vmName  string
+	archive backupArchive
+}
+
+func newBackupModel(vmName, dir string, archives []backupArchive, w, h int) backupModel {
+	pi := textinput.New()
+	pi.Placeholder = "/home/ubuntu /etc/nginx"
+	pi.CharLimit = 200
+	paths := defaultBackupPaths
+	if v, ok := configValue("backup-paths"); ok && strings.TrimSpace(v) != "" {
+		paths = strings.TrimSpace(v)
+	}
+	pi.SetValue(paths)
+	pi.Focus()
+	return backupModel{vmName: vmName, dir: dir, pathsInput: pi, archives: archives, width: w, height: h}
+}
+
+func (m backupModel) Init() tea.Cmd { return textinput.Blink }
+
+func (m backupModel) Update(msg tea.Msg) (backupModel, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		m.pathsInput, cmd = m.pathsInput.Update(msg)
+		return m, cmd
+	}
+	last := 1 + len(m.archives)
+	switch key.String() {
+	case "esc":
+		return m, func() tea.Msg { return backToTableMsg{} }
+	case "tab", "down":
+		m.cursor = min(m.cursor+1, last)
+		m.syncFocus()
+		return m, nil
+	case "shift+tab", "up":
+		m.cursor = max(m.cursor-1, 0)
+		m.syncFocus()
+		return m, nil
+	case "enter":
+		if m.cursor >= 2 {
+			archive := m.archives[m.cursor-2]
+			return m, func() tea.Msg { return backupRestoreRequestMsg{vmName: m.vmName, archive: archive} }
+		}
+		paths, err := parseBackupPaths(m.pathsInput.Value())
+		if err != nil {
+			m.errMsg = err.Error()
+			return m, nil
+		}
+		req := backupRequestMsg{vmName: m.vmName, paths: paths, dir: m.dir}
+		return m, func() tea.Msg { return req }
+	}
+	if m.cursor == 0 {
+		var cmd tea.Cmd
+		m.pathsInput, cmd = m.pathsInput.Update(msg)
+		m.errMsg = ""
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m *backupModel) syncFocus() {
+	if m.cursor == 0 {
+		m.pathsInput.Focus()
+	} else {
+		m.pathsInput.Blur()
+	}
+}
+
+func (m backupModel) View() string {
+	title := formTitleStyle.Render(fmt.Sprintf("Backup: %s", m.vmName))
+
+	pathsLabel := formLabelStyle.Render("Paths:")
+	pathsVal := formValueStyle.Render(m.pathsInput.Value())
+	if m.cursor == 0 {
+		pathsLabel = formActiveLabelStyle.Render("Paths:")
+		pathsVal = m.pathsInput.View()
+	}
+	button := formButtonStyle.Render("[ Back up ]")
+	if m.cursor == 1 {
+		button = formActiveButtonStyle.Render("[ Back up ]")
+	}
+
+	var rows []string
+	if len(m.archives) == 0 {
+		rows = append(rows, tableEmptyStyle.Render("  No backups yet"))
+	}
+	for i, a := range m.archives {
+		line := fmt.Sprintf("%-19s %10s  %s", a.Created.Format("2006-01-02 15:04:05"), formatBytesIEC(float64(a.Size)), a.Name)
+		style := listItemStyle
+		prefix := "  "
+		if m.cursor == i+2 {
+			style = listSelectedItemStyle
+			prefix = tableCursorStyle.Render("▎ ")
+		}
+		rows = append(rows, prefix+style.Render(line))
+	}
+
+	content := title + "\n\n" +
+		fmt.Sprintf("  %s  %s\n", lipgloss.NewStyle().Width(8).Render(pathsLabel), pathsVal) +
+		formHintStyle.Render("  Absolute paths inside the VM, separated by spaces or commas") + "\n\n" +
+		"  " + button + "\n\n" +
+		detailKeyStyle.Render("Restore from ") + detailValStyle.Render(m.dir) + "\n" +
+		strings.Join(rows, "\n") + "\n\n" +
+		formHintStyle.Render("Tab/↑↓: navigate  Enter: back up / restore selected  Esc: cancel")
+	if m.errMsg != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(stoppedClr).Render(m.errMsg)
+	}
+
+	box := modalStyle.Render(content)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,128 @@
+// view_bulk.go - Per-item progress view for bulk VM operations
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// bulkItemStatus is the state of one VM within a bulk operation.
+type bulkItemStatus int
+
+const (
+	bulkPending bulkItemStatus = iota
+	bulkRunning
+	bulkOK
+	bulkFailed
+)
+
+type bulkItem struct {
+	name   string
+	status bulkItemStatus
+	err    error
+}
+
+type bulkProgressModel struct {
+	operation string // e.g. "stop-all"
+	items     []bulkItem
+	done      bool
+	width     int
+	height    int
+}
+
+func newBulkProgressModel(operation string, names []string) bulkProgressModel {
+	items := make([]bulkItem, len(names))
+	for i, n := range names {
+		items[i] = bulkItem{name: n}
+	}
+	return bulkProgressModel{operation: operation, items: items}
+}
+
+// active reports whether a bulk operation is still running.
+func (m bulkProgressModel) active() bool {
+	return len(m.items) > 0 && !m.done
+}
+
+func (m *bulkProgressModel) setStatus(index int, status bulkItemStatus, err error) {
+	if index < 0 || index >= len(m.items) {
+		return
+	}
+	m.items[index].status = status
+	m.items[index].err = err
+}
+
+// counts returns how many items have finished and how many failed.
+func (m bulkProgressModel) counts() (finished, failed int) {
+	for _, it := range m.items {
+		switch it.status {
+		case bulkOK:
+			finished++
+		case bulkFailed:
+			finished++
+			failed++
+		}
+	}
+	return finished, failed
+}
+
+// summary is a one-line status, e.g. "stop-all 3/5 (1 failed)".
+func (m bulkProgressModel) summary() string {
+	finished, failed := m.counts()
+	s := fmt.Sprintf("%s %d/%d", m.operation, finished, len(m.items))
+	if failed > 0 {
+		s += fmt.Sprintf(" (%d failed)", failed)
+	}
+	return s
+}
+
+func (m bulkProgressModel) Update(msg tea.Msg) (bulkProgressModel, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc", "enter", "b", "q":
+			// Closing while running leaves the work going in the background.
+			return m, func() tea.Msg { return backToTableMsg{} }
+		}
+	}
+	return m, nil
+}
+
+func (m bulkProgressModel) View() string {
+	title := modalTitleStyle.Render(fmt.Sprintf("Bulk: %s", m.operation))
+
+	var lines []string
+	for _, it := range m.items {
+		var icon string
+		switch it.status {
+		case bulkPending:
+			icon = lipgloss.NewStyle().Foreground(subtle).Render("○ pending")
+		case bulkRunning:
+			icon = lipgloss.NewStyle().Foreground(accent).Bold(true).Render("◐ running")
+		case bulkOK:
+			icon = lipgloss.NewStyle().Foreground(runningClr).Render("✓ ok     ")
+		case bulkFailed:
+			icon = lipgloss.NewStyle().Foreground(stoppedClr).Bold(true).Render("✗ failed ")
+		}
+		line := "  " + icon + "  " + modalTextStyle.Render(it.name)
+		if it.err != nil {
+			errLine := strings.SplitN(strings.TrimSpace(it.err.Error()), "\n", 2)[0]
+			line += "  " + formHintStyle.Render(truncateToRunes(errLine, 40))
+		}
+		lines = append(lines, line)
+	}
+
+	finished, _ := m.counts()
+	bar := renderProgressBar(float64(finished)/float64(max(1, len(m.items))), 30)
+	status := bar + " " + formHintStyle.Render(m.summary())
+
+	hint := formHintStyle.Render("Esc/b: continue in background")
+	if m.done {
+		hint = formHintStyle.Render("Done — Enter/Esc: close")
+	}
+
+	content := title + "\n" + strings.Join(lines, "\n") + "\n\n" + status + "\n\n" + hint
+	box := modalStyle.Render(content)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
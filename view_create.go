@@ -3,8 +3,10 @@ package main
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -19,7 +21,14 @@ type advCreateMsg struct {
 	memoryMB      int
 	diskGB        int
 	cloudInitFile string
-	networkName   string // "" = NAT, "bridged" = --bridged, else --network <name>
+	networks      []NetworkSpec // empty = NAT only
+	ttl           time.Duration // 0 = no lifetime
+	ttlAction     string        // "delete" or "stop" when ttl expires
+	template      string        // cloud-init picker label, remembered as recent
+	provenance    string        // where the template came from, recorded in the VM's notes
+	projectDir    string        // workspace to mount at projectTarget, "" for none
+	projectTarget string
+	quick         bool // sent by quick launch, without the form's warnings
 }
 
 type advCreateModel struct {
@@ -28,15 +37,35 @@ type advCreateModel struct {
 	width    int
 	height   int
 	releases []string
+	recent   map[string]bool // recently launched releases and template labels
+	// Image remotes (see image.go)
+	imageNotes    map[string]string   // image name -> description, for remote lists
+	loadingImages string              // remote being listed, "" when idle
+	hostImages    []ImageInfo         // default remote's images on arm64 hosts, nil until listed (see arch.go)
+	pins          map[string]imagePin // image of each release's last launch (see imagepin.go)
 	// Cloud-init
-	cloudInitOptions []string // display labels
-	cloudInitPaths   []string // actual file paths (aligned with options)
+	cloudInitOptions []string         // display labels
+	cloudInitPaths   []string         // actual file paths (aligned with options)
+	cloudInitFrom    []TemplateOption // source and verification warning (aligned with options)
 	cleanupDirs      []string
 	// Network
 	networkOptions []string // display labels
 	networkNames   []string // actual names for --network or "bridged" (aligned with options)
+	bridgedNetwork string   // current local.bridged-network ("" if unset)
+	networkWarns   []string // per-option bridging warnings for the active driver (aligned with options)
+	warnAcked      bool     // user pressed Create again after seeing a warning
+	errMsg         string   // validation error shown under the form
+	// Project workspace offered for mounting ("" when not in one)
+	projectDir    string
+	projectTarget string
+	naming        namingPolicy // name-* settings the name must follow (see naming.go)
 }
 
+// ttlActions are what happens to a VM when its TTL expires.
+var ttlActions = []string{"delete", "stop"}
+
+var macAddressRe = regexp.MustCompile(`^([0-9a-fA-F]{2}:){5}[0-9a-fA-F]{2}$`)
+
 type advField struct {
 	label       string
 	input       textinput.Model
@@ -44,88 +73,203 @@ type advField struct {
 	options     []string
 	optionIdx   int
 	isNumeric   bool
+	bareUnitMB  int // for sizes typed with units (see parseSizeMB): MiB meant by a bare number
 	isSubmit    bool
 	isCancel    bool
 	placeholder string
 }
 
-func newAdvCreateModel(width, height int) advCreateModel {
+// newAdvCreateModel builds the form. Recently launched releases and
+// templates are listed first, and the most recent release is preselected.
+// With a name-template set, the name starts as the next one it gives that
+// taken reports as free.
+func newAdvCreateModel(width, height int, recent recentLaunches, taken func(string) bool) advCreateModel {
 	// Collect cloud-init templates
 	templateOptions, cleanupDirs, _ := GetAllCloudInitTemplateOptions()
+	templatePaths := make(map[string]string)
+	templateFrom := make(map[string]TemplateOption)
+	var templateLabels []string
+	for _, opt := range templateOptions {
+		if _, dup := templatePaths[opt.Label]; !dup {
+			templateLabels = append(templateLabels, opt.Label)
+		}
+		templatePaths[opt.Label] = opt.Path
+		templateFrom[opt.Label] = opt
+	}
 	cloudInitLabels := []string{"None"}
 	cloudInitPaths := []string{""}
-	for _, opt := range templateOptions {
-		cloudInitLabels = append(cloudInitLabels, opt.Label)
-		cloudInitPaths = append(cloudInitPaths, opt.Path)
+	cloudInitFrom := []TemplateOption{{}}
+	for _, label := range recentFirst(templateLabels, recent.Templates, false) {
+		cloudInitLabels = append(cloudInitLabels, label)
+		cloudInitPaths = append(cloudInitPaths, templatePaths[label])
+		cloudInitFrom = append(cloudInitFrom, templateFrom[label])
+	}
+
+	defaults := loadLaunchDefaults(configValue)
+	releases := recentFirst(UbuntuReleases, recent.Releases, true)
+	releaseIdx := 0
+	if len(recent.Releases) == 0 {
+		for i, r := range releases {
+			if r == defaults.release {
+				releaseIdx = i
+			}
+		}
+	}
+	// Recent image URLs follow the releases, then the entry for a new URL.
+	releases = append(append(releases, recent.Images...), customImageOption)
+	recentSet := make(map[string]bool)
+	for _, list := range [][]string{recent.Releases, recent.Templates, recent.Images} {
+		for _, r := range list {
+			recentSet[r] = true
+		}
 	}
 
 	// Build network options from multipass networks (cross-platform)
+	bridgedNetwork, _ := GetBridgedNetwork()
+	driver, _ := GetDriver()
 	networkOptions := []string{"Default (NAT)"}
 	networkNames := []string{""}
-	if nets, err := ListNetworks(); err == nil && len(nets) > 0 {
+	networkWarns := []string{""}
+	var nets []NetworkInfo
+	if capsFor(driver).networks {
+		nets, _ = ListNetworks()
+	}
+	if len(nets) > 0 {
 		for _, n := range nets {
-			label := fmt.Sprintf("Bridged: %s (%s)", n.Name, n.Description)
+			label := fmt.Sprintf("Bridged: %s [%s] (%s)", n.Name, classifyNetwork(n), n.Description)
 			if len(label) > 50 {
 				label = truncateToRunes(label, 47) + "..."
 			}
 			networkOptions = append(networkOptions, label)
 			networkNames = append(networkNames, n.Name)
+			networkWarns = append(networkWarns, bridgeWarning(driver, n))
 		}
 	} else {
 		// Fallback when multipass networks unsupported (e.g. Linux LXD)
-		networkOptions = append(networkOptions, "Bridged (default)")
+		label := "Bridged (default not set)"
+		if bridgedNetwork != "" {
+			label = fmt.Sprintf("Bridged (default: %s)", bridgedNetwork)
+		}
+		networkOptions = append(networkOptions, label)
 		networkNames = append(networkNames, "bridged")
+		networkWarns = append(networkWarns, "")
 	}
 
 	nameInput := textinput.New()
 	nameInput.Placeholder = "my-vm"
 	nameInput.Focus()
 	nameInput.CharLimit = 40
+	naming := loadNamingPolicy(configValue)
+	if naming.template != "" {
+		nameInput.SetValue(naming.generate(nil, taken))
+	} else if len(naming.prefixes) > 0 {
+		nameInput.Placeholder = naming.prefixes[0] + "my-vm"
+	}
 
 	cpuInput := textinput.New()
-	cpuInput.SetValue(fmt.Sprintf("%d", DefaultCPUCores))
+	cpuInput.SetValue(fmt.Sprintf("%d", defaults.cpus))
 	cpuInput.CharLimit = 4
 
 	ramInput := textinput.New()
-	ramInput.SetValue(fmt.Sprintf("%d", DefaultRAMMB))
+	ramInput.SetValue(formatSizeMB(defaults.memoryMB))
 	ramInput.CharLimit = 8
 
 	diskInput := textinput.New()
-	diskInput.SetValue(fmt.Sprintf("%d", DefaultDiskGB))
-	diskInput.CharLimit = 6
+	diskInput.SetValue(formatSizeMB(defaults.diskGB * 1024))
+	diskInput.CharLimit = 8
+
+	macInput := textinput.New()
+	macInput.Placeholder = "auto (bridged only)"
+	macInput.CharLimit = 17
+
+	extraNetInput := textinput.New()
+	extraNetInput.Placeholder = "eth1; name=eth2,mode=manual"
+	extraNetInput.CharLimit = 200
+
+	imageInput := textinput.New()
+	imageInput.Placeholder = "file:///path/image.img or https://…"
+	imageInput.CharLimit = 500
+
+	ttlInput := textinput.New()
+	ttlInput.Placeholder = "none (e.g. 30m, 4h, 2d)"
+	ttlInput.CharLimit = 10
 
 	fields := []advField{
 		{label: "Instance Name", input: nameInput},
-		{label: "Release", isSelect: true, options: UbuntuReleases, optionIdx: DefaultReleaseIndex},
+		{label: "Image source", isSelect: true, options: imageSources, optionIdx: 0},
+		{label: "Release", isSelect: true, options: releases, optionIdx: releaseIdx},
+		{label: "Image URL", input: imageInput},
 		{label: "CPU Cores", input: cpuInput, isNumeric: true},
-		{label: "RAM (MB)", input: ramInput, isNumeric: true},
-		{label: "Disk (GB)", input: diskInput, isNumeric: true},
+		{label: "Memory", input: ramInput, isNumeric: true, bareUnitMB: 1},
+		{label: "Disk", input: diskInput, isNumeric: true, bareUnitMB: 1024},
 		{label: "Network", isSelect: true, options: networkOptions, optionIdx: 0},
+		{label: "Mode", isSelect: true, options: []string{"auto", "manual"}, optionIdx: 0},
+		{label: "MAC Address", input: macInput},
+		{label: "Extra Networks", input: extraNetInput},
 		{label: "Cloud-init", isSelect: true, options: cloudInitLabels, optionIdx: 0},
+		{label: "TTL", input: ttlInput},
+		{label: "On expiry", isSelect: true, options: ttlActions, optionIdx: 0},
+	}
+	projectDir, inWorkspace := currentWorkspace()
+	projectTarget := projectMountTarget(configValue)
+	if inWorkspace {
+		fields = append(fields, advField{label: "Mount project", isSelect: true, optionIdx: 0,
+			options: []string{truncateTailToRunes(projectDir, 30) + " → " + projectTarget, "No"}})
+	}
+	fields = append(fields, []advField{
 		{label: "[ Create ]", isSubmit: true},
 		{label: "[ Cancel ]", isCancel: true},
-	}
+	}...)
 
 	return advCreateModel{
 		fields:           fields,
 		width:            width,
 		height:           height,
-		releases:         UbuntuReleases,
+		releases:         releases,
+		recent:           recentSet,
 		cloudInitOptions: cloudInitLabels,
 		cloudInitPaths:   cloudInitPaths,
+		cloudInitFrom:    cloudInitFrom,
 		cleanupDirs:      cleanupDirs,
 		networkOptions:   networkOptions,
 		networkNames:     networkNames,
+		bridgedNetwork:   bridgedNetwork,
+		networkWarns:     networkWarns,
+		projectDir:       projectDir,
+		projectTarget:    projectTarget,
+		naming:           naming,
 	}
 }
 
 func (m advCreateModel) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, fetchHostImagesCmd(len(m.pins) > 0))
 }
 
 func (m advCreateModel) Update(msg tea.Msg) (advCreateModel, tea.Cmd) {
 	switch msg := msg.(type) {
+	case imageListResultMsg:
+		m.setImages(msg)
+		return m, nil
+
+	case hostImagesMsg:
+		if msg.err == nil {
+			m.hostImages = msg.images
+		}
+		return m, nil
+
 	case tea.KeyMsg:
+		if f := &m.fields[m.cursor]; msg.Paste && !f.isSelect && !f.isSubmit && !f.isCancel {
+			kind := pasteLine
+			if f.label == "Image URL" {
+				kind = pastePath
+			}
+			cmd, errMsg := pasteInto(&f.input, msg, kind)
+			m.errMsg = errMsg
+			if f.label == "Image URL" && strings.TrimSpace(f.input.Value()) != "" {
+				m.selectCustomImage()
+			}
+			return m, cmd
+		}
 		switch msg.String() {
 		case "esc":
 			CleanupTempDirs(m.cleanupDirs)
@@ -147,12 +291,13 @@ func (m advCreateModel) Update(msg tea.Msg) (advCreateModel, tea.Cmd) {
 			f := &m.fields[m.cursor]
 			if f.isSelect && f.optionIdx > 0 {
 				f.optionIdx--
-			} else if f.isNumeric {
-				if v, err := strconv.Atoi(f.input.Value()); err == nil {
-					if vals := m.niceValues(m.cursor); vals != nil {
-						f.input.SetValue(fmt.Sprintf("%d", snapPrev(v, vals)))
-					}
+				m.warnAcked = false
+				m.errMsg = ""
+				if f.label == "Image source" {
+					return m, m.switchImageSource()
 				}
+			} else if f.isNumeric {
+				f.snap(m.niceValues(m.cursor), snapPrev)
 			}
 			return m, nil
 
@@ -160,12 +305,13 @@ func (m advCreateModel) Update(msg tea.Msg) (advCreateModel, tea.Cmd) {
 			f := &m.fields[m.cursor]
 			if f.isSelect && f.optionIdx < len(f.options)-1 {
 				f.optionIdx++
-			} else if f.isNumeric {
-				if v, err := strconv.Atoi(f.input.Value()); err == nil {
-					if vals := m.niceValues(m.cursor); vals != nil {
-						f.input.SetValue(fmt.Sprintf("%d", snapNext(v, vals)))
-					}
+				m.warnAcked = false
+				m.errMsg = ""
+				if f.label == "Image source" {
+					return m, m.switchImageSource()
 				}
+			} else if f.isNumeric {
+				f.snap(m.niceValues(m.cursor), snapNext)
 			}
 			return m, nil
 
@@ -176,7 +322,14 @@ func (m advCreateModel) Update(msg tea.Msg) (advCreateModel, tea.Cmd) {
 				return m, func() tea.Msg { return backToTableMsg{} }
 			}
 			if f.isSubmit {
-				return m, m.submit()
+				if warn := m.launchWarning(); warn != "" && !m.warnAcked {
+					m.warnAcked = true
+					m.errMsg = warn + " — press Enter again to launch anyway"
+					return m, nil
+				}
+				cmd, errMsg := m.submit()
+				m.errMsg = errMsg
+				return m, cmd
 			}
 			// Move to next field
 			m.blurCurrent()
@@ -190,6 +343,9 @@ func (m advCreateModel) Update(msg tea.Msg) (advCreateModel, tea.Cmd) {
 		if !f.isSelect && !f.isSubmit && !f.isCancel {
 			var cmd tea.Cmd
 			f.input, cmd = f.input.Update(msg)
+			if f.label == "Image URL" && strings.TrimSpace(f.input.Value()) != "" {
+				m.selectCustomImage()
+			}
 			return m, cmd
 		}
 	}
@@ -205,6 +361,98 @@ func (m advCreateModel) Update(msg tea.Msg) (advCreateModel, tea.Cmd) {
 	return m, nil
 }
 
+// selectCustomImage points the Release picker at the Image URL field.
+func (m *advCreateModel) selectCustomImage() {
+	f := m.fieldPtr("Release")
+	if last := len(f.options) - 1; f.options[last] == customImageOption {
+		f.optionIdx = last
+	}
+}
+
+// useGalleryTemplate adds a downloaded gallery template to the Cloud-init
+// picker, selects it, and fills in the resources the gallery asks for.
+// dir is removed with the form's other temp dirs.
+func (m *advCreateModel) useGalleryTemplate(source string, e galleryEntry, path, dir string) {
+	label := "gallery/" + e.Name
+	m.cloudInitOptions = append(m.cloudInitOptions, label)
+	m.cloudInitPaths = append(m.cloudInitPaths, path)
+	m.cloudInitFrom = append(m.cloudInitFrom, TemplateOption{Label: label, Path: path, Source: source, Description: e.Description})
+	m.cleanupDirs = append(m.cleanupDirs, dir)
+	ci := m.fieldPtr("Cloud-init")
+	ci.options = m.cloudInitOptions
+	ci.optionIdx = len(ci.options) - 1
+
+	setMin := func(label string, want int) {
+		f := m.fieldPtr(label)
+		if have, err := f.value(); want > 0 && (err != nil || have < want) {
+			f.setValue(want)
+		}
+	}
+	setMin("CPU Cores", e.CPUs)
+	setMin("Memory", e.memoryMB())
+	setMin("Disk", e.diskGB()*1024)
+	if e.Release != "" {
+		release := m.fieldPtr("Release")
+		for i, r := range release.options {
+			if r == e.Release {
+				release.optionIdx = i
+			}
+		}
+	}
+}
+
+// fieldPtr returns the form field with the given label for editing.
+func (m *advCreateModel) fieldPtr(label string) *advField {
+	for i := range m.fields {
+		if m.fields[i].label == label {
+			return &m.fields[i]
+		}
+	}
+	panic("advCreateModel: no field " + label)
+}
+
+// switchImageSource refills the Release picker for the chosen image
+// source: the curated releases, or the images of a multipass remote, which
+// are fetched in the background.
+func (m *advCreateModel) switchImageSource() tea.Cmd {
+	src := m.field("Image source")
+	remote := src.options[src.optionIdx]
+	release := m.fieldPtr("Release")
+	m.imageNotes = nil
+	if src.optionIdx == 0 {
+		m.loadingImages = ""
+		release.options, release.optionIdx = m.releases, 0
+		return nil
+	}
+	m.loadingImages = remote
+	release.options, release.optionIdx = []string{"Loading " + remote + " images…"}, 0
+	return fetchImagesCmd(remote)
+}
+
+// setImages shows the images of a remote once they are listed.
+func (m *advCreateModel) setImages(msg imageListResultMsg) {
+	if msg.remote != m.loadingImages {
+		return // the user has picked another source since
+	}
+	m.loadingImages = ""
+	release := m.fieldPtr("Release")
+	if msg.err != nil || len(msg.images) == 0 {
+		m.errMsg = "No images found on " + msg.remote
+		if msg.err != nil {
+			m.errMsg = "Could not list " + msg.remote + " images: " + staleReason(msg.err)
+		}
+		release.options, release.optionIdx = []string{customImageOption}, 0
+		return
+	}
+	m.imageNotes = make(map[string]string, len(msg.images))
+	options := make([]string, 0, len(msg.images)+1)
+	for _, img := range msg.images {
+		options = append(options, img.Name)
+		m.imageNotes[img.Name] = img.describe()
+	}
+	release.options, release.optionIdx = append(options, customImageOption), 0
+}
+
 func (m *advCreateModel) blurCurrent() {
 	f := &m.fields[m.cursor]
 	if !f.isSelect && !f.isSubmit && !f.isCancel {
@@ -219,10 +467,34 @@ func (m *advCreateModel) focusCurrent() {
 	}
 }
 
+// value reads a numeric field: a count, or a size in MiB.
+func (f advField) value() (int, error) {
+	if f.bareUnitMB > 0 {
+		return parseSizeMB(f.input.Value(), f.bareUnitMB)
+	}
+	return strconv.Atoi(strings.TrimSpace(f.input.Value()))
+}
+
+// setValue writes v as value reads it back.
+func (f *advField) setValue(v int) {
+	if f.bareUnitMB > 0 {
+		f.input.SetValue(formatSizeMB(v))
+		return
+	}
+	f.input.SetValue(strconv.Itoa(v))
+}
+
+// snap moves a numeric field to the neighbouring nice value.
+func (f *advField) snap(vals []int, next func(int, []int) int) {
+	if v, err := f.value(); err == nil && vals != nil {
+		f.setValue(next(v, vals))
+	}
+}
+
 // Predefined "nice" values for numeric fields.
 var (
 	niceRAMValues  = []int{256, 512, 768, 1024, 1536, 2048, 3072, 4096, 6144, 8192, 10240, 12288, 16384, 24576, 32768, 49152, 65536}
-	niceDiskValues = []int{4, 8, 12, 16, 24, 32, 48, 64, 96, 128, 192, 256, 384, 512}
+	niceDiskValues = []int{4, 8, 12, 16, 24, 32, 48, 64, 96, 128, 192, 256, 384, 512} // GiB
 	niceCPUValues  = []int{1, 2, 4, 6, 8, 12, 16, 24, 32, 48, 64}
 )
 
@@ -246,13 +518,17 @@ func snapPrev(currentVal int, values []int) int {
 	return values[0]
 }
 
-// niceValues returns the appropriate value list for a field.
+// niceValues returns the appropriate value list for a field, sizes in MiB.
 func (m advCreateModel) niceValues(fieldIdx int) []int {
 	switch m.fields[fieldIdx].label {
-	case "RAM (MB)":
+	case "Memory":
 		return niceRAMValues
-	case "Disk (GB)":
-		return niceDiskValues
+	case "Disk":
+		mb := make([]int, len(niceDiskValues))
+		for i, gb := range niceDiskValues {
+			mb[i] = gb * 1024
+		}
+		return mb
 	case "CPU Cores":
 		return niceCPUValues
 	default:
@@ -260,39 +536,78 @@ func (m advCreateModel) niceValues(fieldIdx int) []int {
 	}
 }
 
-func (m advCreateModel) submit() tea.Cmd {
-	name := m.fields[0].input.Value()
+// submit validates the form and returns the create command, or a
+// validation error to display.
+func (m advCreateModel) submit() (tea.Cmd, string) {
+	name := m.field("Instance Name").input.Value()
 	if name == "" {
-		return nil // TODO: show validation error
+		return nil, "Instance name is required"
+	}
+	if err := m.naming.check(name); err != nil {
+		return nil, err.Error()
 	}
 
-	release := m.fields[1].options[m.fields[1].optionIdx]
+	if m.loadingImages != "" {
+		return nil, "Still listing " + m.loadingImages + " images"
+	}
+	releaseField := m.field("Release")
+	release := releaseField.options[releaseField.optionIdx]
+	if release == customImageOption {
+		release = strings.TrimSpace(m.field("Image URL").input.Value())
+		if release == "" {
+			return nil, "Enter an image URL, or pick a release"
+		}
+	}
+	if isImageURL(release) {
+		if err := validateImageURL(release); err != nil {
+			return nil, err.Error()
+		}
+	}
 
-	cpus, err := strconv.Atoi(m.fields[2].input.Value())
+	cpus, err := strconv.Atoi(m.field("CPU Cores").input.Value())
 	if err != nil || cpus < MinCPUCores {
 		cpus = DefaultCPUCores
 	}
 
-	ram, err := strconv.Atoi(m.fields[3].input.Value())
-	if err != nil || ram < MinRAMMB {
-		ram = DefaultRAMMB
+	ram, err := m.field("Memory").value()
+	if err != nil {
+		return nil, "Memory: " + err.Error()
+	}
+	if ram < MinRAMMB {
+		return nil, fmt.Sprintf("Memory must be at least %s", formatSizeMB(MinRAMMB))
 	}
 
-	disk, err := strconv.Atoi(m.fields[4].input.Value())
-	if err != nil || disk < MinDiskGB {
-		disk = DefaultDiskGB
+	diskMB, err := m.field("Disk").value()
+	if err != nil {
+		return nil, "Disk: " + err.Error()
+	}
+	disk := (diskMB + 1023) / 1024 // multipass is given whole GB
+	if disk < MinDiskGB {
+		return nil, fmt.Sprintf("Disk must be at least %dG", MinDiskGB)
 	}
 
-	networkIdx := m.fields[5].optionIdx
-	networkName := ""
-	if networkIdx > 0 && networkIdx < len(m.networkNames) {
-		networkName = m.networkNames[networkIdx]
+	networks, errMsg := m.networks()
+	if errMsg != "" {
+		return nil, errMsg
 	}
 
-	cloudInitIdx := m.fields[6].optionIdx
-	cloudInitFile := ""
+	cloudInitIdx := m.field("Cloud-init").optionIdx
+	cloudInitFile, template, provenance := "", "", ""
 	if cloudInitIdx > 0 && cloudInitIdx < len(m.cloudInitPaths) {
 		cloudInitFile = m.cloudInitPaths[cloudInitIdx]
+		template = m.cloudInitOptions[cloudInitIdx]
+		provenance = m.cloudInitFrom[cloudInitIdx].Provenance(false)
+	}
+
+	ttl, err := parseTTL(m.field("TTL").input.Value())
+	if err != nil {
+		return nil, err.Error()
+	}
+	expiry := m.field("On expiry")
+
+	projectDir := ""
+	if m.projectDir != "" && m.field("Mount project").optionIdx == 0 {
+		projectDir = m.projectDir
 	}
 
 	CleanupTempDirs(m.cleanupDirs)
@@ -305,9 +620,92 @@ func (m advCreateModel) submit() tea.Cmd {
 			memoryMB:      ram,
 			diskGB:        disk,
 			cloudInitFile: cloudInitFile,
-			networkName:   networkName,
+			networks:      networks,
+			ttl:           ttl,
+			ttlAction:     expiry.options[expiry.optionIdx],
+			template:      template,
+			provenance:    provenance,
+			projectDir:    projectDir,
+			projectTarget: m.projectTarget,
+		}
+	}, ""
+}
+
+// field returns the form field with the given label.
+func (m advCreateModel) field(label string) advField {
+	for _, f := range m.fields {
+		if f.label == label {
+			return f
+		}
+	}
+	return advField{}
+}
+
+// launchWarning returns the warning for the selected release, template or
+// network, if any. Launching anyway takes a second Enter.
+func (m advCreateModel) launchWarning() string {
+	releaseField := m.field("Release")
+	release := releaseField.options[releaseField.optionIdx]
+	if release == customImageOption {
+		release = strings.TrimSpace(m.field("Image URL").input.Value())
+	}
+	if warn := releaseWarning(release, time.Now()); warn != "" {
+		return warn
+	}
+	if warn := archWarning(release, hostArch(), m.hostImages); warn != "" {
+		return warn
+	}
+	if warn := pinDrift(release, m.pins, m.hostImages); warn != "" {
+		return warn
+	}
+	if idx := m.field("Cloud-init").optionIdx; idx < len(m.cloudInitFrom) && m.cloudInitFrom[idx].Warning != "" {
+		return m.cloudInitFrom[idx].Warning
+	}
+	idx := m.field("Network").optionIdx
+	if idx < len(m.networkWarns) {
+		return m.networkWarns[idx]
+	}
+	return ""
+}
+
+// networks collects the primary network (with its mode and MAC) followed by
+// any extra interfaces, or returns a validation error.
+func (m advCreateModel) networks() ([]NetworkSpec, string) {
+	var networks []NetworkSpec
+
+	networkIdx := m.field("Network").optionIdx
+	mac := strings.TrimSpace(m.field("MAC Address").input.Value())
+	if networkIdx > 0 && networkIdx < len(m.networkNames) {
+		primary := NetworkSpec{Name: m.networkNames[networkIdx]}
+		if mode := m.field("Mode"); mode.optionIdx > 0 {
+			primary.Mode = mode.options[mode.optionIdx]
 		}
+		primary.MAC = strings.ToLower(mac)
+		networks = append(networks, primary)
+	} else if mac != "" {
+		return nil, "A MAC address needs a bridged network"
 	}
+
+	for _, part := range strings.Split(m.field("Extra Networks").input.Value(), ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		n, err := parseNetworkSpec(part)
+		if err != nil {
+			return nil, err.Error()
+		}
+		networks = append(networks, n)
+	}
+
+	for _, n := range networks {
+		if n.MAC != "" && !macAddressRe.MatchString(n.MAC) {
+			return nil, "MAC address must look like 52:54:00:12:34:56"
+		}
+		if n.Name == "bridged" && m.bridgedNetwork == "" {
+			return nil, "No default bridged network set; press B on the table to choose one"
+		}
+	}
+	return networks, ""
 }
 
 func (m advCreateModel) View() string {
@@ -376,6 +774,20 @@ func (m advCreateModel) View() string {
 		var value string
 		if f.isSelect {
 			opt := f.options[f.optionIdx]
+			if f.label == "Release" {
+				if status := releaseStatusOf(opt, time.Now()); status != releaseUnknown {
+					opt += " (" + status.String() + ")"
+				}
+			}
+			if isImageURL(opt) {
+				opt = shortImageLabel(opt, valueW-8)
+			}
+			if note := m.imageNotes[opt]; f.label == "Release" && note != "" {
+				opt += " (" + note + ")"
+			}
+			if (f.label == "Release" || f.label == "Cloud-init") && m.recent[f.options[f.optionIdx]] {
+				opt = "↺ " + opt
+			}
 			left := "  "
 			right := "  "
 			if f.optionIdx > 0 {
@@ -418,7 +830,20 @@ func (m advCreateModel) View() string {
 	// Hints
 	hint := formHintStyle.Render("  Tab/↑↓: navigate  ←→: adjust values  Enter: submit  Esc: cancel")
 
-	content := titleText + "\n" + tableBox + "\n" + buttonRow + "\n\n" + hint
+	content := titleText + "\n" + tableBox + "\n"
+	if idx := m.field("Cloud-init").optionIdx; idx > 0 && idx < len(m.cloudInitFrom) {
+		from := truncateTailToRunes(m.cloudInitFrom[idx].Provenance(true), w)
+		content += lipgloss.NewStyle().Foreground(subtle).Render("  Template: "+from) + "\n"
+		if d := m.cloudInitFrom[idx].Description; d != "" {
+			content += renderDescription(d, w) + "\n"
+		}
+	}
+	content += buttonRow + "\n\n" + hint
+	if m.errMsg != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(stoppedClr).Render("  "+m.errMsg)
+	} else if warn := m.launchWarning(); warn != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(suspendClr).Render("  ⚠ "+warn)
+	}
 
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
 }
@@ -0,0 +1,437 @@
+// view_exec.go - Dialog for running a command in a VM
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Cursor positions of the exec dialog.
+const (
+	execFieldSnippet = iota
+	execFieldCommand
+	execFieldUser
+	execFieldEnv
+	execFieldWorkDir
+	execFieldRun
+)
+
+// Run as choices of the exec dialog; other prompts for a user name.
+const (
+	runAsDefault = iota
+	runAsRoot
+	runAsOther
+)
+
+var runAsLabels = []string{"Default user", "root", "Other user:"}
+
+// userNameRe matches a Linux user name.
+var userNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// execModel runs a shell command in one VM and shows its output.
+type execModel struct {
+	vmName       string
+	snippets     []execSnippet
+	snippetIdx   int // 0 is a custom command, i+1 is snippets[i]
+	commandInput textinput.Model
+	runAs        int
+	userInput    textinput.Model
+	envInput     textinput.Model
+	workDirInput textinput.Model
+	cursor       int
+	running      bool
+	ran          string // command of the last result
+	ranAs        string // its user; "" is the default user
+	output       string
+	err          error
+	errMsg       string
+	width        int
+	height       int
+}
+
+// execResultMsg carries the output of a command run from the exec dialog.
+type execResultMsg struct {
+	vmName  string
+	command string
+	user    string
+	output  string
+	err     error
+}
+
+// defaultWorkDir returns the target of the VM's first mount, so commands
+// start in the mounted project rather than the home directory. mounts is
+// VMInfo.Mounts ("src => target, ...").
+func defaultWorkDir(mounts string) string {
+	first, _, _ := strings.Cut(mounts, ", ")
+	if _, target, ok := strings.Cut(first, " => "); ok {
+		return strings.TrimSpace(target)
+	}
+	return ""
+}
+
+func newExecModel(vm VMInfo, snippets []execSnippet, w, h int) execModel {
+	ci := textinput.New()
+	ci.Placeholder = "e.g. make test"
+	ci.CharLimit = 1000
+	ui := textinput.New()
+	ui.Placeholder = "user name"
+	ui.CharLimit = 32
+	ei := textinput.New()
+	ei.Placeholder = "NAME=VALUE …"
+	ei.CharLimit = 1000
+	wi := textinput.New()
+	wi.Placeholder = "home directory"
+	wi.CharLimit = 260
+	wi.SetValue(defaultWorkDir(vm.Mounts))
+	m := execModel{vmName: vm.Name, snippets: snippets, commandInput: ci, userInput: ui, envInput: ei, workDirInput: wi, width: w, height: h}
+	m.cursor = m.firstField()
+	m.syncFocus()
+	return m
+}
+
+func (m execModel) Init() tea.Cmd { return textinput.Blink }
+
+// firstField is the snippet picker when snippets are configured, else the
+// command.
+func (m execModel) firstField() int {
+	if len(m.snippets) > 0 {
+		return execFieldSnippet
+	}
+	return execFieldCommand
+}
+
+// selectSnippet picks snippet idx (0 is a custom command) and fills the
+// command and environment from it.
+func (m *execModel) selectSnippet(idx int) {
+	m.snippetIdx = idx
+	if idx == 0 {
+		return
+	}
+	sn := m.snippets[idx-1]
+	m.commandInput.SetValue(sn.command)
+	m.envInput.SetValue(strings.Join(sn.env, " "))
+}
+
+func (m execModel) snippetLabel() string {
+	if m.snippetIdx == 0 {
+		return "Custom command"
+	}
+	return m.snippets[m.snippetIdx-1].label
+}
+
+// options returns the exec options set in the dialog.
+func (m execModel) options() (execOptions, error) {
+	env, err := parseEnvAssignments(m.envInput.Value())
+	if err != nil {
+		return execOptions{}, err
+	}
+	opts := execOptions{WorkDir: strings.TrimSpace(m.workDirInput.Value()), Env: env}
+	switch m.runAs {
+	case runAsRoot:
+		opts.User = "root"
+	case runAsOther:
+		opts.User = strings.TrimSpace(m.userInput.Value())
+		if !userNameRe.MatchString(opts.User) {
+			return execOptions{}, fmt.Errorf("run as: %q is not a valid user name", opts.User)
+		}
+	}
+	return opts, nil
+}
+
+// runExecCmd runs command through bash in vmName. ${secret:NAME}
+// placeholders in the environment are resolved first.
+func runExecCmd(vmName string, opts execOptions, command string) tea.Cmd {
+	return func() tea.Msg {
+		env, err := resolveEnvSecrets(opts.Env, func(name string) (string, error) {
+			return resolveSecret(name, configValue)
+		})
+		if err != nil {
+			return execResultMsg{vmName: vmName, command: command, user: opts.User, err: err}
+		}
+		opts.Env = env
+		out, err := ExecInVMWith(vmName, opts, "bash", "-c", command)
+		return execResultMsg{vmName: vmName, command: command, user: opts.User, output: out, err: err}
+	}
+}
+
+func (m execModel) Update(msg tea.Msg) (execModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case execResultMsg:
+		if msg.vmName != m.vmName {
+			return m, nil
+		}
+		m.running = false
+		m.ran, m.ranAs, m.output, m.err = msg.command, msg.user, msg.output, msg.err
+		if m.overflows() {
+			return m, m.openPager()
+		}
+		return m, nil
+	case tea.KeyMsg:
+		if msg.Paste {
+			return m.paste(msg)
+		}
+		switch msg.String() {
+		case "esc":
+			return m, func() tea.Msg { return backToTableMsg{} }
+		case "ctrl+o":
+			if m.ran != "" && !m.running {
+				return m, m.openPager()
+			}
+			return m, nil
+		case "tab", "down":
+			m.cursor = min(m.cursor+1, execFieldRun)
+			m.syncFocus()
+			return m, nil
+		case "shift+tab", "up":
+			m.cursor = max(m.cursor-1, m.firstField())
+			m.syncFocus()
+			return m, nil
+		case "left", "right":
+			if m.cursor == execFieldSnippet {
+				n := len(m.snippets) + 1
+				delta := 1
+				if msg.String() == "left" {
+					delta = n - 1
+				}
+				m.selectSnippet((m.snippetIdx + delta) % n)
+				return m, nil
+			}
+			if m.cursor == execFieldUser {
+				delta := 1
+				if msg.String() == "left" {
+					delta = len(runAsLabels) - 1
+				}
+				m.runAs = (m.runAs + delta) % len(runAsLabels)
+				m.errMsg = ""
+				m.syncFocus()
+				return m, nil
+			}
+		case "enter":
+			if m.running {
+				return m, nil
+			}
+			command := strings.TrimSpace(m.commandInput.Value())
+			if command == "" {
+				m.errMsg = "Enter a command"
+				return m, nil
+			}
+			opts, err := m.options()
+			if err != nil {
+				m.errMsg = err.Error()
+				return m, nil
+			}
+			m.running = true
+			m.errMsg = ""
+			if appLogger != nil {
+				appLogger.Printf("exec dialog on %s: %s", m.vmName, command)
+			}
+			return m, runExecCmd(m.vmName, opts, command)
+		}
+	}
+	var cmd tea.Cmd
+	switch m.cursor {
+	case execFieldCommand:
+		m.commandInput, cmd = m.commandInput.Update(msg)
+	case execFieldUser:
+		if m.runAs == runAsOther {
+			m.userInput, cmd = m.userInput.Update(msg)
+			m.errMsg = ""
+		}
+	case execFieldEnv:
+		m.envInput, cmd = m.envInput.Update(msg)
+		m.errMsg = ""
+	case execFieldWorkDir:
+		m.workDirInput, cmd = m.workDirInput.Update(msg)
+	}
+	return m, cmd
+}
+
+// paste handles a bracketed paste into the focused field (see paste.go).
+func (m execModel) paste(msg tea.KeyMsg) (execModel, tea.Cmd) {
+	var in *textinput.Model
+	kind := pasteLine
+	switch m.cursor {
+	case execFieldCommand:
+		in, kind = &m.commandInput, pasteCommand
+	case execFieldUser:
+		if m.runAs == runAsOther {
+			in = &m.userInput
+		}
+	case execFieldEnv:
+		in, kind = &m.envInput, pasteWords
+	case execFieldWorkDir:
+		in, kind = &m.workDirInput, pastePath
+	}
+	if in == nil {
+		return m, nil
+	}
+	cmd, errMsg := pasteInto(in, msg, kind)
+	m.errMsg = errMsg
+	return m, cmd
+}
+
+func (m *execModel) syncFocus() {
+	m.commandInput.Blur()
+	m.userInput.Blur()
+	m.envInput.Blur()
+	m.workDirInput.Blur()
+	switch m.cursor {
+	case execFieldCommand:
+		m.commandInput.Focus()
+	case execFieldUser:
+		if m.runAs == runAsOther {
+			m.userInput.Focus()
+		}
+	case execFieldEnv:
+		m.envInput.Focus()
+	case execFieldWorkDir:
+		m.workDirInput.Focus()
+	}
+}
+
+// permissionDenied reports whether the last command failed for lack of
+// privileges while running as the default user.
+func (m execModel) permissionDenied() bool {
+	if m.err == nil || m.ranAs != "" {
+		return false
+	}
+	s := strings.ToLower(m.err.Error())
+	return strings.Contains(s, "permission denied") || strings.Contains(s, "must be root") ||
+		strings.Contains(s, "are you root")
+}
+
+// resultText is what the last command printed, or its error.
+func (m execModel) resultText() string {
+	if m.err != nil {
+		return m.err.Error()
+	}
+	return m.output
+}
+
+// outputLimits are how many lines, and runes per line, the dialog shows.
+func (m execModel) outputLimits() (lines, width int) {
+	return max(m.height-18, 3), max(m.width-12, 20)
+}
+
+// overflows reports whether the result does not fit in the dialog.
+func (m execModel) overflows() bool {
+	maxLines, width := m.outputLimits()
+	lines := strings.Split(strings.TrimRight(m.resultText(), "\n"), "\n")
+	if len(lines) > maxLines {
+		return true
+	}
+	for _, l := range lines {
+		if utf8.RuneCountInString(l) > width {
+			return true
+		}
+	}
+	return false
+}
+
+// openPager shows the whole result in the pager.
+func (m execModel) openPager() tea.Cmd {
+	msg := openPagerMsg{title: m.vmName + ": " + m.ran, text: m.resultText(), fileName: pagerFileName(m.vmName, time.Now())}
+	return func() tea.Msg { return msg }
+}
+
+// outputLines returns the last lines of the result that fit in the dialog.
+func (m execModel) outputLines() []string {
+	text := m.resultText()
+	if strings.TrimSpace(text) == "" {
+		return []string{"(no output)"}
+	}
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	maxLines, width := m.outputLimits()
+	if len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
+	}
+	for i, l := range lines {
+		lines[i] = truncateToRunes(l, width)
+	}
+	return lines
+}
+
+func (m execModel) View() string {
+	title := formTitleStyle.Render("Run in " + m.vmName)
+
+	field := func(idx int, label string, input textinput.Model) string {
+		l := formLabelStyle.Width(13).Render(label)
+		v := formValueStyle.Render(input.Value())
+		if input.Value() == "" {
+			v = lipgloss.NewStyle().Foreground(subtle).Render(input.Placeholder)
+		}
+		if m.cursor == idx {
+			l = formActiveLabelStyle.Width(13).Render(label)
+			v = input.View()
+		}
+		return "  " + l + v + "\n"
+	}
+	button := formButtonStyle.Render("[ Run ]")
+	if m.cursor == execFieldRun {
+		button = formActiveButtonStyle.Render("[ Run ]")
+	}
+
+	content := title + "\n\n"
+	if len(m.snippets) > 0 {
+		l := formLabelStyle.Width(13).Render("Snippet:")
+		v := "  " + lipgloss.NewStyle().Foreground(subtle).Render(m.snippetLabel())
+		if m.cursor == execFieldSnippet {
+			l = formActiveLabelStyle.Width(13).Render("Snippet:")
+			v = lipgloss.NewStyle().Foreground(accent).Render("◀ ") + formValueStyle.Render(m.snippetLabel()) +
+				lipgloss.NewStyle().Foreground(accent).Render(" ▶")
+		}
+		content += "  " + l + v + "\n"
+	}
+	runAsLabel := formLabelStyle.Width(13).Render("Run as:")
+	runAsVal := "  " + lipgloss.NewStyle().Foreground(subtle).Render(runAsLabels[m.runAs])
+	if m.cursor == execFieldUser {
+		runAsLabel = formActiveLabelStyle.Width(13).Render("Run as:")
+		runAsVal = lipgloss.NewStyle().Foreground(accent).Render("◀ ") + formValueStyle.Render(runAsLabels[m.runAs]) +
+			lipgloss.NewStyle().Foreground(accent).Render(" ▶")
+	}
+	if m.runAs == runAsOther {
+		if m.cursor == execFieldUser {
+			runAsVal += " " + m.userInput.View()
+		} else {
+			runAsVal += " " + formValueStyle.Render(m.userInput.Value())
+		}
+	}
+	content += field(execFieldCommand, "Command:", m.commandInput) +
+		"  " + runAsLabel + runAsVal + "\n" +
+		field(execFieldEnv, "Environment:", m.envInput) +
+		field(execFieldWorkDir, "Working dir:", m.workDirInput) +
+		"\n  " + button + "\n"
+
+	switch {
+	case m.running:
+		content += "\n" + lipgloss.NewStyle().Foreground(accent).Render("Running…") + "\n"
+	case m.ran != "":
+		ran := m.ran
+		if m.ranAs != "" {
+			ran += " (as " + m.ranAs + ")"
+		}
+		status := lipgloss.NewStyle().Foreground(runningClr).Render("✓ " + ran)
+		if m.err != nil {
+			status = lipgloss.NewStyle().Foreground(stoppedClr).Render("✗ " + ran)
+		}
+		content += "\n" + status + "\n" + strings.Join(m.outputLines(), "\n") + "\n"
+		if m.overflows() {
+			content += formHintStyle.Render("Output cut to fit: ctrl+o opens it in the pager") + "\n"
+		}
+		if m.permissionDenied() {
+			content += formHintStyle.Render("Commands run as the default ubuntu user; set Run as to root for admin tasks") + "\n"
+		}
+	}
+	content += "\n" + formHintStyle.Render("Tab/↑↓: navigate  ←→: snippet, run as  Enter: run  Esc: close")
+	if m.errMsg != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(stoppedClr).Render(m.errMsg)
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalStyle.Render(content))
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestExecArgs(t *testing.T) {
+	got := strings.Join(execArgs("web", execOptions{}, []string{"ls", "-la"}), " ")
+	if got != "exec web -- ls -la" {
+		t.Errorf("no options: %q", got)
+	}
+	got = strings.Join(execArgs("web", execOptions{WorkDir: "/home/ubuntu/repo"}, []string{"make"}), " ")
+	if got != "exec web --working-directory /home/ubuntu/repo -- make" {
+		t.Errorf("with working dir: %q", got)
+	}
+	got = strings.Join(execArgs("web", execOptions{Env: []string{"A=1", "B=x"}}, []string{"bash", "-c", "env"}), " ")
+	if got != "exec web -- env A=1 B=x bash -c env" {
+		t.Errorf("with env: %q", got)
+	}
+	got = strings.Join(execArgs("web", execOptions{User: "root", Env: []string{"A=1"}}, []string{"apt", "update"}), " ")
+	if got != "exec web -- sudo -u root env A=1 apt update" {
+		t.Errorf("as root: %q", got)
+	}
+}
+
+func TestRedactExecEnv(t *testing.T) {
+	args := execArgs("web", execOptions{Env: []string{"TOKEN=s3cret"}}, []string{"make", "X=1"})
+	got := strings.Join(redactExecEnv(args), " ")
+	if got != "exec web -- env TOKEN=*** make X=1" {
+		t.Errorf("redacted = %q", got)
+	}
+	if args[4] != "TOKEN=s3cret" {
+		t.Error("redactExecEnv must not modify its input")
+	}
+	args = execArgs("web", execOptions{User: "deploy", Env: []string{"TOKEN=s3cret"}}, []string{"make"})
+	if got := strings.Join(redactExecEnv(args), " "); got != "exec web -- sudo -u deploy env TOKEN=*** make" {
+		t.Errorf("redacted with sudo = %q", got)
+	}
+}
+
+func TestExecModelRunAs(t *testing.T) {
+	m := newExecModel(VMInfo{Name: "web"}, nil, 100, 40)
+	m.cursor = execFieldUser
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if opts, err := m.options(); err != nil || opts.User != "root" {
+		t.Errorf("user = %q, %v; want root", opts.User, err)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if _, err := m.options(); err == nil {
+		t.Error("expected an error for an empty user name")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("deploy")})
+	if opts, err := m.options(); err != nil || opts.User != "deploy" {
+		t.Errorf("user = %q, %v; want deploy", opts.User, err)
+	}
+
+	m, _ = m.Update(execResultMsg{vmName: "web", command: "apt update", err: errors.New("E: Could not open lock file - open (13: Permission denied)")})
+	if !m.permissionDenied() {
+		t.Error("expected the run-as hint for a permission error as the default user")
+	}
+	m, _ = m.Update(execResultMsg{vmName: "web", command: "apt update", user: "root", err: errors.New("Permission denied")})
+	if m.permissionDenied() {
+		t.Error("no hint when the command already ran as another user")
+	}
+}
+
+func TestExecModelSnippetFillsCommandAndEnv(t *testing.T) {
+	snippets, errs := loadExecSnippets([]string{"Deploy | ENV=staging | ./deploy.sh | tee log", "broken"})
+	if len(snippets) != 1 || len(errs) != 1 {
+		t.Fatalf("snippets = %v, errs = %v", snippets, errs)
+	}
+	m := newExecModel(VMInfo{Name: "web"}, snippets, 100, 40)
+	if m.cursor != execFieldSnippet {
+		t.Fatalf("cursor = %d, want the snippet picker", m.cursor)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	opts, err := m.options()
+	if err != nil || m.commandInput.Value() != "./deploy.sh | tee log" || strings.Join(opts.Env, " ") != "ENV=staging" {
+		t.Errorf("command = %q, env = %v (%v)", m.commandInput.Value(), opts.Env, err)
+	}
+	m.envInput.SetValue("not-an-assignment")
+	if m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter}); m.errMsg == "" || m.running {
+		t.Error("an invalid environment should block the run")
+	}
+}
+
+func TestDefaultWorkDir(t *testing.T) {
+	cases := map[string]string{
+		"":                             "",
+		"--":                           "",
+		"/src/app => /home/ubuntu/app": "/home/ubuntu/app",
+		"/src/a => /home/ubuntu/a, /src/b => /home/ubuntu/b": "/home/ubuntu/a",
+	}
+	for mounts, want := range cases {
+		if got := defaultWorkDir(mounts); got != want {
+			t.Errorf("defaultWorkDir(%q) = %q, want %q", mounts, got, want)
+		}
+	}
+}
+
+func TestExecModelRunAndResult(t *testing.T) {
+	m := newExecModel(VMInfo{Name: "web", Mounts: "/src => /home/ubuntu/src"}, nil, 100, 40)
+	if opts, _ := m.options(); opts.WorkDir != "/home/ubuntu/src" {
+		t.Fatalf("work dir = %q", opts.WorkDir)
+	}
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || m.errMsg == "" {
+		t.Fatal("an empty command should not run")
+	}
+
+	m.commandInput.SetValue("make test")
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || !m.running {
+		t.Fatal("expected the command to start")
+	}
+
+	m, _ = m.Update(execResultMsg{vmName: "other", command: "x"})
+	if !m.running {
+		t.Error("a result for another VM should be ignored")
+	}
+	m, _ = m.Update(execResultMsg{vmName: "web", command: "make test", err: errors.New("command failed: exit status 2\nStderr: boom")})
+	if m.running || !strings.Contains(m.View(), "boom") {
+		t.Error("expected the failure output to be shown")
+	}
+}
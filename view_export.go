@@ -0,0 +1,136 @@
+// view_export.go - Dialog for exporting the VM table
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// exportModel picks a format and a file for the table export.
+// Cursor 0 is the format, 1 the path input, 2 the Export button.
+type exportModel struct {
+	formatIdx int
+	pathInput textinput.Model
+	rowCount  int
+	cursor    int
+	errMsg    string
+	width     int
+	height    int
+}
+
+// exportRequestMsg asks the root model to write the export.
+type exportRequestMsg struct {
+	format string
+	path   string
+}
+
+func newExportModel(rowCount, w, h int) exportModel {
+	pi := textinput.New()
+	pi.CharLimit = 260
+	pi.SetValue(exportFileName(exportFormats[0], time.Now()))
+	return exportModel{pathInput: pi, rowCount: rowCount, width: w, height: h}
+}
+
+func (m exportModel) Init() tea.Cmd { return textinput.Blink }
+
+func (m exportModel) format() string { return exportFormats[m.formatIdx] }
+
+// setFormat switches format and keeps the path's extension in step.
+func (m *exportModel) setFormat(idx int) {
+	old := exportExtension(m.format())
+	m.formatIdx = idx
+	if path := m.pathInput.Value(); strings.HasSuffix(path, old) {
+		m.pathInput.SetValue(strings.TrimSuffix(path, old) + exportExtension(m.format()))
+	}
+}
+
+func (m exportModel) Update(msg tea.Msg) (exportModel, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		m.pathInput, cmd = m.pathInput.Update(msg)
+		return m, cmd
+	}
+	switch key.String() {
+	case "esc":
+		return m, func() tea.Msg { return backToTableMsg{} }
+	case "tab", "down":
+		m.cursor = min(m.cursor+1, 2)
+		m.syncFocus()
+		return m, nil
+	case "shift+tab", "up":
+		m.cursor = max(m.cursor-1, 0)
+		m.syncFocus()
+		return m, nil
+	case "left", "right":
+		if m.cursor == 0 {
+			delta := 1
+			if key.String() == "left" {
+				delta = len(exportFormats) - 1
+			}
+			m.setFormat((m.formatIdx + delta) % len(exportFormats))
+			return m, nil
+		}
+	case "enter":
+		path := strings.TrimSpace(m.pathInput.Value())
+		if path == "" {
+			m.errMsg = "Enter a file name"
+			return m, nil
+		}
+		req := exportRequestMsg{format: m.format(), path: path}
+		return m, func() tea.Msg { return req }
+	}
+	if m.cursor == 1 {
+		var cmd tea.Cmd
+		m.pathInput, cmd = m.pathInput.Update(msg)
+		m.errMsg = ""
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m *exportModel) syncFocus() {
+	if m.cursor == 1 {
+		m.pathInput.Focus()
+	} else {
+		m.pathInput.Blur()
+	}
+}
+
+func (m exportModel) View() string {
+	title := formTitleStyle.Render(fmt.Sprintf("Export %d VMs", m.rowCount))
+
+	formatLabel := formLabelStyle.Width(8).Render("Format:")
+	formatVal := "  " + lipgloss.NewStyle().Foreground(subtle).Render(m.format())
+	if m.cursor == 0 {
+		formatLabel = formActiveLabelStyle.Width(8).Render("Format:")
+		formatVal = lipgloss.NewStyle().Foreground(accent).Render("◀ ") + formValueStyle.Render(m.format()) +
+			lipgloss.NewStyle().Foreground(accent).Render(" ▶")
+	}
+	pathLabel := formLabelStyle.Width(8).Render("File:")
+	pathVal := formValueStyle.Render(m.pathInput.Value())
+	if m.cursor == 1 {
+		pathLabel = formActiveLabelStyle.Width(8).Render("File:")
+		pathVal = m.pathInput.View()
+	}
+	button := formButtonStyle.Render("[ Export ]")
+	if m.cursor == 2 {
+		button = formActiveButtonStyle.Render("[ Export ]")
+	}
+
+	content := title + "\n\n" +
+		"  " + formatLabel + formatVal + "\n" +
+		"  " + pathLabel + pathVal + "\n" +
+		formHintStyle.Render("  The filtered rows and visible columns, in the current sort order") + "\n\n" +
+		"  " + button + "\n\n" +
+		formHintStyle.Render("Tab/↑↓: navigate  ←→: format  Enter: export  Esc: cancel")
+	if m.errMsg != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(stoppedClr).Render(m.errMsg)
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalStyle.Render(content))
+}
@@ -0,0 +1,161 @@
+// view_expose.go - Dialog for forwarding a host port to a VM
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// exposeModel picks the VM and host ports of a new forward and lists the
+// VM's open forwards. Cursor 0 is the VM port, 1 the host port, 2 the Expose
+// button, 3+ the open forwards.
+type exposeModel struct {
+	vmName   string
+	ip       string
+	forwards []*portForward
+	vmPort   textinput.Model
+	hostPort textinput.Model
+	cursor   int
+	errMsg   string
+	width    int
+	height   int
+}
+
+// exposeRequestMsg asks the root model to open a forward.
+type exposeRequestMsg struct {
+	vmName   string
+	ip       string
+	vmPort   int
+	hostPort int // 0 picks a free port
+}
+
+// unexposeRequestMsg asks the root model to close a forward.
+type unexposeRequestMsg struct {
+	vmName string
+	vmPort int
+}
+
+func newExposeModel(vmName, ip string, forwards []*portForward, w, h int) exposeModel {
+	vp := textinput.New()
+	vp.CharLimit = 5
+	vp.SetValue("80")
+	vp.Focus()
+	hp := textinput.New()
+	hp.Placeholder = "any free port"
+	hp.CharLimit = 5
+	return exposeModel{vmName: vmName, ip: ip, forwards: forwards, vmPort: vp, hostPort: hp, width: w, height: h}
+}
+
+func (m exposeModel) Init() tea.Cmd { return textinput.Blink }
+
+func (m exposeModel) Update(msg tea.Msg) (exposeModel, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	last := 2 + len(m.forwards)
+	switch key.String() {
+	case "esc":
+		return m, func() tea.Msg { return backToTableMsg{} }
+	case "tab", "down":
+		m.cursor = min(m.cursor+1, last)
+		m.syncFocus()
+		return m, nil
+	case "shift+tab", "up":
+		m.cursor = max(m.cursor-1, 0)
+		m.syncFocus()
+		return m, nil
+	case "enter":
+		if m.cursor >= 3 {
+			req := unexposeRequestMsg{vmName: m.vmName, vmPort: m.forwards[m.cursor-3].vmPort}
+			return m, func() tea.Msg { return req }
+		}
+		vmPort, err := parsePort(m.vmPort.Value(), false)
+		if err != nil {
+			m.errMsg = "VM port: " + err.Error()
+			return m, nil
+		}
+		hostPort, err := parsePort(m.hostPort.Value(), true)
+		if err != nil {
+			m.errMsg = "Host port: " + err.Error()
+			return m, nil
+		}
+		req := exposeRequestMsg{vmName: m.vmName, ip: m.ip, vmPort: vmPort, hostPort: hostPort}
+		return m, func() tea.Msg { return req }
+	}
+	var cmd tea.Cmd
+	switch m.cursor {
+	case 0:
+		m.vmPort, cmd = m.vmPort.Update(msg)
+	case 1:
+		m.hostPort, cmd = m.hostPort.Update(msg)
+	}
+	m.errMsg = ""
+	return m, cmd
+}
+
+func (m *exposeModel) syncFocus() {
+	m.vmPort.Blur()
+	m.hostPort.Blur()
+	switch m.cursor {
+	case 0:
+		m.vmPort.Focus()
+	case 1:
+		m.hostPort.Focus()
+	}
+}
+
+func (m exposeModel) View() string {
+	title := formTitleStyle.Render(fmt.Sprintf("Expose: %s (%s)", m.vmName, m.ip))
+
+	field := func(idx int, label string, in textinput.Model) string {
+		l := formLabelStyle.Render(label)
+		v := formValueStyle.Render(in.Value())
+		if in.Value() == "" {
+			v = formHintStyle.Render(in.Placeholder)
+		}
+		if m.cursor == idx {
+			l = formActiveLabelStyle.Render(label)
+			v = in.View()
+		}
+		return fmt.Sprintf("  %s  %s\n", lipgloss.NewStyle().Width(11).Render(l), v)
+	}
+	button := formButtonStyle.Render("[ Expose ]")
+	if m.cursor == 2 {
+		button = formActiveButtonStyle.Render("[ Expose ]")
+	}
+
+	var rows []string
+	if len(m.forwards) == 0 {
+		rows = append(rows, tableEmptyStyle.Render("  No open forwards"))
+	}
+	for i, f := range m.forwards {
+		line := fmt.Sprintf("%-24s → %d", f.url(), f.vmPort)
+		style := listItemStyle
+		prefix := "  "
+		if m.cursor == i+3 {
+			style = listSelectedItemStyle
+			prefix = tableCursorStyle.Render("▎ ")
+		}
+		rows = append(rows, prefix+style.Render(line))
+	}
+
+	content := title + "\n\n" +
+		field(0, "VM port:", m.vmPort) +
+		field(1, "Host port:", m.hostPort) +
+		formHintStyle.Render("  Listens on localhost only; closed when the VM stops or passgo exits") + "\n\n" +
+		"  " + button + "\n\n" +
+		detailKeyStyle.Render("Open forwards") + "\n" +
+		strings.Join(rows, "\n") + "\n\n" +
+		formHintStyle.Render("Tab/↑↓: navigate  Enter: expose / close selected  Esc: back")
+	if m.errMsg != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(stoppedClr).Render(m.errMsg)
+	}
+
+	box := modalStyle.Render(content)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
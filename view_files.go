@@ -0,0 +1,262 @@
+// view_files.go - File browser for a VM's filesystem
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// File browser prompts.
+const (
+	filesPromptNone = iota
+	filesPromptDownload
+	filesPromptUpload
+)
+
+// filesModel browses one VM's directories and moves files to and from the
+// host. Upload is hidden in read-only mode.
+type filesModel struct {
+	vmName       string
+	dir          string
+	entries      []remoteEntry
+	cursor       int
+	selectAfter  string // entry to highlight once the next listing arrives
+	loading      bool
+	transferring bool
+	prompt       int
+	promptTarget string // remote file to download, or remote directory to upload into
+	input        textinput.Model
+	status       string
+	errMsg       string
+	readOnly     bool
+	width        int
+	height       int
+}
+
+func newFilesModel(vm VMInfo, readOnly bool, w, h int) filesModel {
+	in := textinput.New()
+	in.CharLimit = 260
+	dir := defaultWorkDir(vm.Mounts)
+	if dir == "" {
+		dir = "." // the default user's home directory
+	}
+	return filesModel{vmName: vm.Name, dir: dir, loading: true, input: in, readOnly: readOnly, width: w, height: h}
+}
+
+func (m filesModel) Init() tea.Cmd { return fetchRemoteDirCmd(m.vmName, m.dir) }
+
+// open lists dir, highlighting selectAfter when it is in the listing.
+func (m *filesModel) open(dir, selectAfter string) tea.Cmd {
+	m.loading = true
+	m.errMsg = ""
+	m.selectAfter = selectAfter
+	return fetchRemoteDirCmd(m.vmName, dir)
+}
+
+func (m filesModel) selected() (remoteEntry, bool) {
+	if m.cursor < len(m.entries) {
+		return m.entries[m.cursor], true
+	}
+	return remoteEntry{}, false
+}
+
+// uploadDir is where an upload goes: the highlighted directory, else the
+// current one.
+func (m filesModel) uploadDir() string {
+	if e, ok := m.selected(); ok && e.isDir() {
+		return e.Path
+	}
+	return m.dir
+}
+
+func (m filesModel) Update(msg tea.Msg) (filesModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case remoteDirResultMsg:
+		if msg.vmName != m.vmName {
+			return m, nil
+		}
+		m.loading = false
+		if msg.err != nil {
+			// Keep showing the last listing.
+			m.errMsg = staleReason(msg.err)
+			return m, nil
+		}
+		m.dir, m.entries, m.cursor = msg.dir, msg.entries, 0
+		for i, e := range m.entries {
+			if e.Name == m.selectAfter {
+				m.cursor = i
+			}
+		}
+		return m, nil
+
+	case fileTransferResultMsg:
+		if msg.vmName != m.vmName {
+			return m, nil
+		}
+		m.transferring = false
+		if msg.err != nil {
+			m.errMsg = staleReason(msg.err)
+			return m, nil
+		}
+		m.status = "✓ " + msg.done
+		var sel string
+		if e, ok := m.selected(); ok {
+			sel = e.Name
+		}
+		return m, m.open(m.dir, sel)
+
+	case tea.KeyMsg:
+		if m.prompt != filesPromptNone {
+			return m.updatePrompt(msg)
+		}
+		switch msg.String() {
+		case "esc", "q":
+			return m, func() tea.Msg { return backToTableMsg{} }
+		case "up", "k":
+			m.cursor = max(m.cursor-1, 0)
+		case "down", "j":
+			m.cursor = min(m.cursor+1, max(len(m.entries)-1, 0))
+		case "enter", "right", "l":
+			if e, ok := m.selected(); ok && e.isDir() && !m.loading {
+				return m, m.open(e.Path, "")
+			}
+		case "backspace", "left", "h":
+			if m.dir != "/" && !m.loading {
+				return m, m.open(path.Dir(m.dir), path.Base(m.dir))
+			}
+		case "r":
+			if !m.loading {
+				var sel string
+				if e, ok := m.selected(); ok {
+					sel = e.Name
+				}
+				return m, m.open(m.dir, sel)
+			}
+		case "d":
+			if e, ok := m.selected(); ok && !e.isDir() && !m.transferring {
+				m.prompt, m.promptTarget = filesPromptDownload, e.Path
+				m.input.Placeholder = "local path"
+				m.input.SetValue(e.Name)
+				m.input.CursorEnd()
+				return m, m.input.Focus()
+			}
+		case "u":
+			if !m.readOnly && !m.transferring {
+				m.prompt, m.promptTarget = filesPromptUpload, m.uploadDir()
+				m.input.Placeholder = "local file to upload"
+				m.input.SetValue("")
+				return m, m.input.Focus()
+			}
+		}
+		return m, nil
+	}
+	if m.prompt != filesPromptNone {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// updatePrompt handles keys while the download/upload path is being typed.
+func (m filesModel) updatePrompt(key tea.KeyMsg) (filesModel, tea.Cmd) {
+	switch key.String() {
+	case "esc":
+		m.prompt = filesPromptNone
+		m.input.Blur()
+		return m, nil
+	case "enter":
+		local := strings.TrimSpace(m.input.Value())
+		if local == "" {
+			return m, nil
+		}
+		prompt := m.prompt
+		m.prompt = filesPromptNone
+		m.input.Blur()
+		m.transferring = true
+		m.errMsg, m.status = "", ""
+		if prompt == filesPromptDownload {
+			return m, downloadFileCmd(m.vmName, m.promptTarget, local)
+		}
+		return m, uploadFileCmd(m.vmName, local, m.promptTarget)
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(key)
+	return m, cmd
+}
+
+// entryLine renders one row of the listing.
+func (m filesModel) entryLine(e remoteEntry, nameWidth int) string {
+	name := e.Name
+	size := formatBytesIEC(float64(e.Size))
+	switch e.Kind {
+	case "dir":
+		name += "/"
+		size = ""
+	case "link":
+		name += "@"
+	}
+	modified := ""
+	if !e.ModTime.IsZero() {
+		modified = e.ModTime.Format("2006-01-02 15:04")
+	}
+	return fmt.Sprintf("%-*s %10s  %s", nameWidth, truncateToRunes(name, nameWidth), size, modified)
+}
+
+func (m filesModel) View() string {
+	title := formTitleStyle.Render("Files in " + m.vmName)
+	dirLine := detailKeyStyle.Render("Directory: ") + detailValStyle.Render(m.dir)
+	if m.loading {
+		dirLine += lipgloss.NewStyle().Foreground(subtle).Render("  loading…")
+	}
+
+	nameWidth := min(max(m.width-50, 20), 50)
+	visible := max(m.height-16, 5)
+	start := 0
+	if m.cursor >= visible {
+		start = m.cursor - visible + 1
+	}
+	var rows []string
+	for i := start; i < len(m.entries) && i < start+visible; i++ {
+		style, prefix := listItemStyle, "  "
+		if i == m.cursor {
+			style, prefix = listSelectedItemStyle, tableCursorStyle.Render("▎ ")
+		}
+		rows = append(rows, prefix+style.Render(m.entryLine(m.entries[i], nameWidth)))
+	}
+	listing := strings.Join(rows, "\n")
+	if len(m.entries) == 0 && !m.loading {
+		listing = tableEmptyStyle.Render("Empty directory")
+	}
+
+	content := title + "\n\n" + dirLine + "\n\n" + listing + "\n"
+	switch m.prompt {
+	case filesPromptDownload:
+		content += "\n" + formActiveLabelStyle.Render("Save "+path.Base(m.promptTarget)+" to: ") + m.input.View() + "\n"
+	case filesPromptUpload:
+		content += "\n" + formActiveLabelStyle.Render("Upload to "+m.promptTarget+": ") + m.input.View() + "\n"
+	}
+	switch {
+	case m.transferring:
+		content += "\n" + lipgloss.NewStyle().Foreground(accent).Render("Transferring…") + "\n"
+	case m.errMsg != "":
+		content += "\n" + lipgloss.NewStyle().Foreground(stoppedClr).Render(m.errMsg) + "\n"
+	case m.status != "":
+		content += "\n" + lipgloss.NewStyle().Foreground(runningClr).Render(m.status) + "\n"
+	}
+
+	hint := "↑↓: select  Enter/→: open  ←/Backspace: up  d: download  u: upload  r: refresh  Esc: close"
+	if m.readOnly {
+		hint = "↑↓: select  Enter/→: open  ←/Backspace: up  d: download  r: refresh  Esc: close"
+	}
+	if m.prompt != filesPromptNone {
+		hint = "Enter: start  Esc: cancel"
+	}
+	content += "\n" + formHintStyle.Render(hint)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalStyle.Render(content))
+}
@@ -0,0 +1,140 @@
+// view_gallery.go - Browsable gallery of curated templates from a registry index
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// galleryModel lists the templates of an index, with the selected one's
+// description and requirements underneath.
+type galleryModel struct {
+	source string // index location, for resolving template URLs
+	index  galleryIndex
+	cursor int
+	offset int
+	width  int
+	height int
+	arch   string // host architecture, for flagging templates that do not run here
+	// warnAcked is set by Enter on a template for another architecture;
+	// a second Enter opens it anyway.
+	warnAcked bool
+}
+
+// galleryPickMsg asks the root model to download a template and open the
+// create form with it.
+type galleryPickMsg struct {
+	source    string
+	indexName string
+	entry     galleryEntry
+}
+
+func newGalleryModel(source string, index galleryIndex, w, h int) galleryModel {
+	return galleryModel{source: source, index: index, width: w, height: h, arch: hostArch()}
+}
+
+// visibleRows is how many entries fit above the detail panel.
+func (m galleryModel) visibleRows() int {
+	return max(m.height-18, 3)
+}
+
+func (m galleryModel) Update(msg tea.Msg) (galleryModel, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "esc", "q":
+		return m, func() tea.Msg { return backToTableMsg{} }
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+		m.warnAcked = false
+	case "down", "j":
+		m.cursor = min(m.cursor+1, max(len(m.index.Templates)-1, 0))
+		m.warnAcked = false
+	case "enter":
+		if m.cursor < len(m.index.Templates) {
+			if !m.index.Templates[m.cursor].runsOn(m.arch) && !m.warnAcked {
+				m.warnAcked = true
+				return m, nil
+			}
+			pick := galleryPickMsg{source: m.source, indexName: m.index.Name, entry: m.index.Templates[m.cursor]}
+			return m, func() tea.Msg { return pick }
+		}
+	}
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if visible := m.visibleRows(); m.cursor >= m.offset+visible {
+		m.offset = m.cursor - visible + 1
+	}
+	return m, nil
+}
+
+func (m galleryModel) View() string {
+	name := m.index.Name
+	if name == "" {
+		name = m.source
+	}
+	title := formTitleStyle.Render("Template Gallery: " + name)
+	w := max(min(m.width-12, 76), 30)
+
+	var rows []string
+	if len(m.index.Templates) == 0 {
+		rows = append(rows, tableEmptyStyle.Render("  The index lists no templates"))
+	}
+	end := min(m.offset+m.visibleRows(), len(m.index.Templates))
+	for i := m.offset; i < end; i++ {
+		e := m.index.Templates[i]
+		icon := e.Icon
+		if icon == "" {
+			icon = "•"
+		}
+		if !e.runsOn(m.arch) {
+			icon = "⚠"
+		}
+		line := truncateToRunes(fmt.Sprintf("%s %s", icon, e.label()), w-24)
+		line = fmt.Sprintf("%-*s %s", w-22, line, e.resources())
+		style := listItemStyle
+		prefix := "  "
+		if i == m.cursor {
+			style = listSelectedItemStyle
+			prefix = tableCursorStyle.Render("▎ ")
+		}
+		rows = append(rows, prefix+style.Render(line))
+	}
+
+	content := title + "\n\n" + strings.Join(rows, "\n") + "\n"
+	if m.cursor < len(m.index.Templates) {
+		e := m.index.Templates[m.cursor]
+		content += "\n" + detailKeyStyle.Render(e.label()) + "\n"
+		if e.Description != "" {
+			content += lipgloss.NewStyle().Width(w).Foreground(subtle).Render(e.Description) + "\n"
+		}
+		detail := func(k, v string) {
+			if v != "" {
+				content += detailKeyStyle.Render(fmt.Sprintf("  %-10s", k)) + detailValStyle.Render(v) + "\n"
+			}
+		}
+		detail("Requires", e.resources())
+		detail("Release", e.Release)
+		detail("Tags", strings.Join(e.Tags, ", "))
+		detail("Arch", strings.Join(e.Arch, ", "))
+		if e.SHA256 == "" {
+			detail("Checksum", "none in index (not verified)")
+		}
+	}
+	if m.cursor < len(m.index.Templates) && !m.index.Templates[m.cursor].runsOn(m.arch) {
+		warn := fmt.Sprintf("⚠ This template is not made for %s hosts", m.arch)
+		if m.warnAcked {
+			warn += " — press Enter again to use it anyway"
+		}
+		content += "\n" + lipgloss.NewStyle().Width(w).Foreground(suspendClr).Render(warn) + "\n"
+	}
+	content += "\n" + formHintStyle.Render("↑↓: browse  Enter: create a VM from this template  Esc: back")
+
+	box := modalStyle.Render(content)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
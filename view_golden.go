@@ -0,0 +1,143 @@
+// view_golden.go - Golden image list: promote the selected VM, clone new VMs from an image
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// goldenModel lists the golden images. p names a new one made from the VM
+// selected in the table; Enter opens the clone form for the highlighted one.
+type goldenModel struct {
+	goldens  []goldenImage
+	cursor   int
+	vmName   string            // VM selected in the table, "" for none
+	vmState  string            // its state; promoting needs it stopped
+	vmStates map[string]string // state of each instance, for the sources
+	naming   bool              // typing the name of a new golden image
+	input    textinput.Model
+	errMsg   string
+	width    int
+	height   int
+}
+
+func newGoldenModel(goldens []goldenImage, vmName, vmState string, vmStates map[string]string, w, h int) goldenModel {
+	ti := textinput.New()
+	ti.Placeholder = "base"
+	ti.CharLimit = 40
+	return goldenModel{goldens: goldens, vmName: vmName, vmState: vmState, vmStates: vmStates, input: ti, width: w, height: h}
+}
+
+func (m goldenModel) Update(msg tea.Msg) (goldenModel, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if m.naming {
+		if ok {
+			switch key.String() {
+			case "esc":
+				m.naming, m.errMsg = false, ""
+				m.input.Blur()
+				return m, nil
+			case "enter":
+				name := strings.TrimSpace(m.input.Value())
+				if err := validateGoldenName(name, m.goldens); err != nil {
+					m.errMsg = err.Error()
+					return m, nil
+				}
+				req := goldenPromoteRequestMsg{vmName: m.vmName, name: name}
+				return m, func() tea.Msg { return req }
+			}
+			m.errMsg = ""
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	if !ok {
+		return m, nil
+	}
+	m.errMsg = ""
+	switch key.String() {
+	case "esc", "q":
+		return m, func() tea.Msg { return backToTableMsg{} }
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, max(len(m.goldens)-1, 0))
+	case "p":
+		switch {
+		case m.vmName == "":
+			m.errMsg = "Select a VM in the table to promote it."
+		case m.vmState != "Stopped":
+			m.errMsg = fmt.Sprintf("Stop %s first: multipass snapshots stopped VMs only.", m.vmName)
+		default:
+			m.naming = true
+			m.input.SetValue(m.vmName)
+			m.input.CursorEnd()
+			return m, m.input.Focus()
+		}
+	case "enter":
+		if m.cursor >= len(m.goldens) {
+			return m, nil
+		}
+		g := m.goldens[m.cursor]
+		if _, ok := m.vmStates[g.Source]; !ok {
+			m.errMsg = g.Name + ": " + errGoldenSourceGone.Error()
+			return m, nil
+		}
+		return m, func() tea.Msg { return snapCloneRequestMsg{vmName: g.Source, snapName: g.Snapshot} }
+	case "d":
+		if m.cursor >= len(m.goldens) {
+			return m, nil
+		}
+		g := m.goldens[m.cursor]
+		_, exists := m.vmStates[g.Source]
+		return m, func() tea.Msg { return goldenRemoveRequestMsg{golden: g, sourceExists: exists} }
+	}
+	return m, nil
+}
+
+func (m goldenModel) View() string {
+	title := formTitleStyle.Render("Golden Images")
+	w := max(min(m.width-12, 76), 30)
+
+	var rows []string
+	if len(m.goldens) == 0 {
+		rows = append(rows, tableEmptyStyle.Render("  No golden images yet. Stop a VM you have set up, select it and press p."))
+	}
+	for i, g := range m.goldens {
+		source := g.Source
+		if _, ok := m.vmStates[g.Source]; !ok {
+			source = "⚠ " + g.Source + " (gone)"
+		}
+		line := fmt.Sprintf("%-20s %-24s %s", truncateToRunes(g.Name, 20), truncateToRunes(source, 24), g.Created.Local().Format("2006-01-02"))
+		style := listItemStyle
+		prefix := "  "
+		if i == m.cursor {
+			style = listSelectedItemStyle
+			prefix = tableCursorStyle.Render("▎ ")
+		}
+		rows = append(rows, prefix+style.Render(truncateToRunes(line, w)))
+	}
+
+	content := title + "\n\n" + strings.Join(rows, "\n") + "\n"
+	if m.naming {
+		content += "\n" + fmt.Sprintf("  %s  %s\n", formActiveLabelStyle.Render("Name:"), m.input.View()) +
+			formHintStyle.Render(fmt.Sprintf("  Snapshots %s; new VMs are cloned from it as it is now.", m.vmName)) + "\n\n" +
+			formHintStyle.Render("Enter: promote  Esc: cancel")
+	} else {
+		hint := "↑↓: browse  Enter: clone new VMs  d: remove  Esc: back"
+		if m.vmName != "" {
+			hint = "p: promote " + m.vmName + "  " + hint
+		}
+		content += "\n" + formHintStyle.Render(hint)
+	}
+	if m.errMsg != "" {
+		content += "\n" + lipgloss.NewStyle().Width(w).Foreground(stoppedClr).Render(m.errMsg)
+	}
+	box := modalStyle.Render(content)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
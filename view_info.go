@@ -12,8 +12,15 @@ import (
 )
 
 const (
-	infoRefreshInterval = 1 * time.Second
-	sparkHistoryLen     = 40 // number of data points in the sparkline
+	infoRefreshInterval    = 1 * time.Second
+	networkRefreshInterval = 5 * time.Second // guest interfaces cost an exec, refresh less often
+	sparkHistoryLen        = 40              // number of data points in the sparkline
+)
+
+// Tabs of the info view.
+const (
+	infoTabDetails = iota
+	infoTabDiagnostics
 )
 
 // sparkline characters from lowest to highest (8 levels).
@@ -39,6 +46,22 @@ type infoModel struct {
 	lastLoad    string
 	lastDiskRaw string
 	lastMemRaw  string
+
+	// Guest interfaces, rendered below the multipass info block
+	network string
+
+	// Background refresh: at most one info and one network fetch in flight,
+	// ticks that arrive meanwhile are dropped like the table's.
+	infoInFlight    bool
+	networkInFlight bool
+	lastNetwork     time.Time
+	loadErr         string // first fetch failed, nothing to show yet
+
+	// Diagnostics tab (see diagnostics.go), collected when first shown
+	tab         int
+	history     []opRecord // passgo's operation history, for failed operations
+	diag        string     // rendered sections, "" until collected
+	diagLoading bool
 }
 
 func newInfoModel(vmName string, width, height int) infoModel {
@@ -52,6 +75,21 @@ func newInfoModel(vmName string, width, height int) infoModel {
 	}
 }
 
+// refresh starts the info and network fetches that are due and not
+// already running.
+func (m *infoModel) refresh(now time.Time) tea.Cmd {
+	var cmds []tea.Cmd
+	if !m.infoInFlight {
+		m.infoInFlight = true
+		cmds = append(cmds, fetchVMInfoCmd(m.vmName))
+	}
+	if !m.networkInFlight && now.Sub(m.lastNetwork) >= networkRefreshInterval {
+		m.networkInFlight = true
+		cmds = append(cmds, fetchVMNetworkCmd(m.vmName))
+	}
+	return tea.Batch(cmds...)
+}
+
 func (m *infoModel) setContent(raw string) {
 	// Parse resource data from the raw info
 	var cpus, load, diskRaw, memRaw, state string
@@ -110,9 +148,17 @@ func (m *infoModel) setContent(raw string) {
 		}
 	}
 	m.content = b.String()
+	m.refreshViewport()
+}
 
+// refreshViewport sizes the viewport and sets its content to the info
+// block followed by the network section.
+func (m *infoModel) refreshViewport() {
 	vpWidth := min(m.width-6, 76)
 	vpHeight := m.height - 18 // leave room for charts at top
+	if m.tab == infoTabDiagnostics {
+		vpHeight = m.height - 10
+	}
 	if vpHeight < 5 {
 		vpHeight = 5
 	}
@@ -124,7 +170,83 @@ func (m *infoModel) setContent(raw string) {
 		m.viewport.Width = vpWidth
 		m.viewport.Height = vpHeight
 	}
-	m.viewport.SetContent(m.content)
+	if m.tab == infoTabDiagnostics {
+		m.viewport.SetContent(m.diagContent())
+		return
+	}
+	m.viewport.SetContent(m.content + m.network)
+}
+
+// diagContent is the Diagnostics tab's viewport content.
+func (m infoModel) diagContent() string {
+	if m.diagLoading && m.diag == "" {
+		return loadingMsgStyle.Render("Collecting logs…")
+	}
+	return m.diag
+}
+
+// renderDiagnostics formats the sections for a viewport width wide.
+func renderDiagnostics(sections []diagSection, width int) string {
+	var b strings.Builder
+	for i, s := range sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(infoKeyStyle.Render(s.title) + "\n")
+		if s.note != "" {
+			b.WriteString(lipgloss.NewStyle().Foreground(subtle).Render("  "+s.note) + "\n")
+		}
+		for _, l := range s.lines {
+			b.WriteString(infoValStyle.Render("  "+truncateToRunes(l, max(width-4, 20))) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// switchTab shows the other tab, collecting diagnostics the first time.
+func (m *infoModel) switchTab() tea.Cmd {
+	m.tab = 1 - m.tab
+	m.refreshViewport()
+	m.viewport.GotoTop()
+	if m.tab == infoTabDiagnostics && m.diag == "" && !m.diagLoading {
+		return m.collectDiagnostics()
+	}
+	return nil
+}
+
+// collectDiagnostics starts a (re-)collection of the Diagnostics tab.
+func (m *infoModel) collectDiagnostics() tea.Cmd {
+	m.diagLoading = true
+	if m.tab == infoTabDiagnostics {
+		m.refreshViewport()
+	}
+	return fetchDiagnosticsCmd(m.vmName, m.vmState, m.history)
+}
+
+// setNetwork renders the guest interface list for the info viewport.
+func (m *infoModel) setNetwork(msg vmNetworkResultMsg) {
+	var b strings.Builder
+	b.WriteString("\n" + infoKeyStyle.Render("Network") + "\n")
+	switch {
+	case msg.err != nil:
+		b.WriteString(infoValStyle.Render("  unavailable (VM must be running)") + "\n")
+	case len(msg.interfaces) == 0:
+		b.WriteString(infoValStyle.Render("  no interfaces reported") + "\n")
+	}
+	for _, iface := range msg.interfaces {
+		ips := strings.Join(iface.IPv4, ", ")
+		b.WriteString(infoKeyStyle.Render("  "+iface.Name+":") +
+			infoValStyle.Render(fmt.Sprintf(" %s  %s", orDashes(iface.MAC), orDashes(ips))) + "\n")
+	}
+	bridge := "none (NAT only)"
+	if msg.hostBridge != "" {
+		bridge = msg.hostBridge
+	}
+	b.WriteString(infoKeyStyle.Render("  Host bridge:") + infoValStyle.Render(" "+bridge) + "\n")
+	m.network = b.String()
+	if m.ready {
+		m.refreshViewport()
+	}
 }
 
 func appendHistory(history []float64, val float64) []float64 {
@@ -141,22 +263,49 @@ func (m infoModel) Update(msg tea.Msg) (infoModel, tea.Cmd) {
 		switch msg.String() {
 		case "esc", "enter", "q":
 			return m, func() tea.Msg { return backToTableMsg{} }
+		case "tab":
+			return m, m.switchTab()
+		case "r":
+			if m.tab == infoTabDiagnostics && !m.diagLoading {
+				return m, m.collectDiagnostics()
+			}
+			return m, nil
 		}
 
-	case infoRefreshTickMsg:
-		// Only refresh if on a running VM
-		if m.vmState == "Running" {
-			return m, tea.Batch(
-				infoRefreshTickCmd(),
-				fetchVMInfoCmd(m.vmName),
-			)
+	case diagnosticsResultMsg:
+		if msg.vmName == m.vmName {
+			m.diagLoading = false
+			m.diag = renderDiagnostics(msg.sections, min(m.width-6, 76))
+			if m.tab == infoTabDiagnostics {
+				m.refreshViewport()
+			}
 		}
-		// Still schedule the next tick in case state changes
-		return m, infoRefreshTickCmd()
+		return m, nil
+
+	case infoRefreshTickMsg:
+		// Refresh whatever the state, so a booting VM's IPs and disk
+		// usage appear without reopening the view.
+		return m, tea.Batch(infoRefreshTickCmd(), m.refresh(time.Time(msg)))
 
 	case vmInfoResultMsg:
-		if msg.err == nil {
+		if msg.vmName != m.vmName {
+			return m, nil
+		}
+		m.infoInFlight = false
+		switch {
+		case msg.err == nil:
+			m.loadErr = ""
 			m.setContent(msg.info)
+		case !m.ready:
+			m.loadErr = firstLine(msg.err.Error())
+		}
+		return m, nil
+
+	case vmNetworkResultMsg:
+		if msg.vmName == m.vmName {
+			m.networkInFlight = false
+			m.lastNetwork = time.Now()
+			m.setNetwork(msg)
 		}
 		return m, nil
 	}
@@ -177,14 +326,32 @@ func infoRefreshTickCmd() tea.Cmd {
 
 func (m infoModel) View() string {
 	title := modalTitleStyle.Render(fmt.Sprintf("Info: %s", m.vmName))
+	tabs := []string{"Details", "Diagnostics"}
+	for i, t := range tabs {
+		if i == m.tab {
+			tabs[i] = formActiveLabelStyle.Render("[" + t + "]")
+		} else {
+			tabs[i] = formLabelStyle.Render(" " + t + " ")
+		}
+	}
+	title += "  " + strings.Join(tabs, " ")
 
 	// Live resource charts
 	charts := m.renderCharts()
+	if m.tab == infoTabDiagnostics {
+		charts = ""
+	}
 
 	var body string
-	if !m.ready {
+	switch {
+	case m.tab == infoTabDiagnostics && m.ready:
+		body = m.viewport.View()
+	case !m.ready && m.loadErr != "":
+		body = lipgloss.NewStyle().Foreground(stoppedClr).Render("Could not load info: "+m.loadErr) + "\n" +
+			formHintStyle.Render("Retrying…")
+	case !m.ready:
 		body = loadingMsgStyle.Render("Loading…")
-	} else {
+	default:
 		body = m.viewport.View()
 	}
 
@@ -195,7 +362,10 @@ func (m infoModel) View() string {
 			fmt.Sprintf(" %.0f%%", pct*100))
 	}
 
-	hint := formHintStyle.Render("↑↓ scroll  Esc close") + scrollHint
+	hint := formHintStyle.Render("Tab: details/diagnostics  ↑↓ scroll  Esc close") + scrollHint
+	if m.tab == infoTabDiagnostics {
+		hint = formHintStyle.Render("Tab: details/diagnostics  r: collect again  ↑↓ scroll  Esc close") + scrollHint
+	}
 	content := title + "\n\n" + charts + "\n" + body + "\n\n" + hint
 
 	box := infoBorderStyle.Render(content)
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInfoRefreshCoalescesInFlightFetches(t *testing.T) {
+	m := newInfoModel("web", 100, 40)
+	now := time.Now()
+	if cmd := m.refresh(now); cmd == nil || !m.infoInFlight || !m.networkInFlight {
+		t.Fatal("first refresh should fetch info and network")
+	}
+	if cmd := m.refresh(now.Add(time.Second)); cmd != nil {
+		t.Error("refresh while both fetches are in flight should do nothing")
+	}
+
+	m, _ = m.Update(vmInfoResultMsg{vmName: "web", info: "State: Starting\nIPv4: --"})
+	m, _ = m.Update(vmNetworkResultMsg{vmName: "web"})
+	if m.infoInFlight || m.networkInFlight {
+		t.Fatal("results should clear the in-flight flags")
+	}
+	m.refresh(time.Now())
+	if !m.infoInFlight || m.networkInFlight {
+		t.Error("info should refresh every tick, network only every networkRefreshInterval")
+	}
+}
+
+func TestInfoRefreshIgnoresOtherVMAndKeepsData(t *testing.T) {
+	m := newInfoModel("web", 100, 40)
+	m.refresh(time.Now())
+	m, _ = m.Update(vmInfoResultMsg{vmName: "db", info: "State: Running"})
+	if !m.infoInFlight || m.ready {
+		t.Fatal("a result for another VM must not be applied")
+	}
+
+	m, _ = m.Update(vmInfoResultMsg{vmName: "web", err: errors.New("daemon busy")})
+	if m.loadErr != "daemon busy" {
+		t.Errorf("loadErr = %q, want the first fetch error", m.loadErr)
+	}
+	m, _ = m.Update(vmInfoResultMsg{vmName: "web", info: "State: Running"})
+	m, _ = m.Update(vmInfoResultMsg{vmName: "web", err: errors.New("daemon busy")})
+	if m.vmState != "Running" || m.loadErr != "" {
+		t.Errorf("a failed refresh should keep the last data, got state %q err %q", m.vmState, m.loadErr)
+	}
+}
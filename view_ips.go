@@ -0,0 +1,109 @@
+// view_ips.go - Dialog for copying the running VMs' IPs in a chosen format
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ipPreviewLines caps the preview shown in the dialog.
+const ipPreviewLines = 12
+
+// ipsModel previews the IP export of the filtered rows and copies it.
+type ipsModel struct {
+	addrs     []vmAddress
+	identity  string
+	formatIdx int
+	filtered  bool // the table filter narrowed the rows
+	status    string
+	width     int
+	height    int
+}
+
+func newIPsModel(addrs []vmAddress, identity string, filtered bool, w, h int) ipsModel {
+	return ipsModel{addrs: addrs, identity: identity, filtered: filtered, width: w, height: h}
+}
+
+func (m ipsModel) format() string { return ipFormats[m.formatIdx] }
+
+// text is the export in the selected format.
+func (m ipsModel) text() string {
+	data, _ := renderIPs(m.format(), m.addrs, m.identity)
+	return string(data)
+}
+
+func (m ipsModel) Update(msg tea.Msg) (ipsModel, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "esc", "q", "Y":
+		return m, func() tea.Msg { return backToTableMsg{} }
+	case "left", "h":
+		m.formatIdx = (m.formatIdx + len(ipFormats) - 1) % len(ipFormats)
+		m.status = ""
+	case "right", "l", "tab":
+		m.formatIdx = (m.formatIdx + 1) % len(ipFormats)
+		m.status = ""
+	case "enter", "c":
+		if len(m.addrs) == 0 {
+			return m, nil
+		}
+		if err := clipboard.WriteAll(m.text()); err != nil {
+			m.status = "✗ Copy failed: " + err.Error()
+			return m, nil
+		}
+		n := len(m.addrs)
+		return m, tea.Batch(
+			func() tea.Msg { return backToTableMsg{} },
+			func() tea.Msg { return ipsCopiedMsg{count: n, format: ipFormatLabels[m.format()]} })
+	}
+	return m, nil
+}
+
+// ipsCopiedMsg reports that the IPs were copied, for a toast.
+type ipsCopiedMsg struct {
+	count  int
+	format string
+}
+
+func (m ipsModel) View() string {
+	scope := "running VMs"
+	if m.filtered {
+		scope = "running VMs matching the filter"
+	}
+	title := formTitleStyle.Render(fmt.Sprintf("IPs of %d %s", len(m.addrs), scope))
+
+	var tabs []string
+	for i, f := range ipFormats {
+		style := formButtonStyle
+		if i == m.formatIdx {
+			style = formActiveButtonStyle
+		}
+		tabs = append(tabs, style.Render(" "+ipFormatLabels[f]+" "))
+	}
+
+	var preview string
+	if len(m.addrs) == 0 {
+		preview = tableEmptyStyle.Render("No running VMs with an IP address")
+	} else {
+		lines := strings.Split(strings.TrimRight(m.text(), "\n"), "\n")
+		if len(lines) > ipPreviewLines {
+			more := len(lines) - ipPreviewLines
+			lines = append(lines[:ipPreviewLines], formHintStyle.Render(fmt.Sprintf("… %d more lines", more)))
+		}
+		preview = modalTextStyle.Render(strings.Join(lines, "\n"))
+	}
+
+	content := title + "\n\n " + strings.Join(tabs, "  ") + "\n\n" + preview + "\n\n"
+	if m.status != "" {
+		content += lipgloss.NewStyle().Foreground(stoppedClr).Render(m.status) + "\n"
+	}
+	content += formHintStyle.Render("←→: format  Enter: copy to clipboard  Esc: close")
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalStyle.Render(content))
+}
@@ -0,0 +1,160 @@
+// view_jobs.go - Jobs view: every schedule's next run and last result, and the agent's history
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// jobRow is one scheduled job as the Jobs view shows it.
+type jobRow struct {
+	job  cronJob
+	next time.Time
+	last *jobRun // nil: never ran, or not recorded (power schedules)
+}
+
+// jobRows lists the cron jobs and power schedules with their next runs.
+func (m *rootModel) jobRows(now time.Time) []jobRow {
+	var rows []jobRow
+	for _, j := range m.cronJobs {
+		row := jobRow{job: j, next: j.spec.next(now)}
+		if r, ok := m.state.Jobs[j.name]; ok {
+			row.last = &r
+		}
+		rows = append(rows, row)
+	}
+	for _, j := range powerJobs(m.power) {
+		rows = append(rows, jobRow{job: j, next: j.spec.next(now)})
+	}
+	return rows
+}
+
+// jobsModel lists the scheduled jobs and, when attached to an agent, what
+// the agent did, newest first.
+type jobsModel struct {
+	rows    []jobRow
+	history []agentJob // oldest first, as the agent sends them
+	agent   bool
+	now     time.Time
+	offset  int // lines scrolled past at the top
+	width   int
+	height  int
+}
+
+func newJobsModel(rows []jobRow, history []agentJob, agent bool, now time.Time) jobsModel {
+	return jobsModel{rows: rows, history: history, agent: agent, now: now}
+}
+
+// visibleLines is how many lines fit in the modal.
+func (m jobsModel) visibleLines() int {
+	return max(5, m.height-10)
+}
+
+// jobsRefreshMsg asks for the Jobs view to be built again.
+type jobsRefreshMsg struct{}
+
+func (m jobsModel) Update(msg tea.Msg) (jobsModel, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc", "enter", "q", "J":
+			return m, func() tea.Msg { return backToTableMsg{} }
+		case "r":
+			return m, func() tea.Msg { return jobsRefreshMsg{} }
+		case "up", "k":
+			m.offset = max(0, m.offset-1)
+		case "down", "j":
+			m.offset = min(max(0, len(m.lines())-m.visibleLines()), m.offset+1)
+		}
+	}
+	return m, nil
+}
+
+// lines renders both sections, unclipped.
+func (m jobsModel) lines() []string {
+	ok := lipgloss.NewStyle().Foreground(runningClr)
+	failed := lipgloss.NewStyle().Foreground(stoppedClr).Bold(true)
+
+	lines := []string{modalTextStyle.Bold(true).Render("Scheduled")}
+	if len(m.rows) == 0 {
+		lines = append(lines, formHintStyle.Render("  None: add job: or power: entries to .config"))
+	}
+	for _, r := range m.rows {
+		next := "never"
+		if !r.next.IsZero() {
+			next = r.next.Format("Mon 15:04") + " (in " + formatRemaining(r.next.Sub(m.now)) + ")"
+		}
+		line := "  " + modalTextStyle.Render(fmt.Sprintf("%-18s", truncateToRunes(r.job.name, 18))) +
+			"  " + formHintStyle.Render(fmt.Sprintf("%-16s", truncateToRunes(r.job.spec.String(), 16))) +
+			"  " + modalTextStyle.Render(fmt.Sprintf("%-30s", truncateToRunes(r.job.describe(), 30))) +
+			"  " + formHintStyle.Render(fmt.Sprintf("next %-22s", next))
+		switch {
+		case r.last == nil:
+		case r.last.Error != "":
+			line += "  " + failed.Render("✗ "+formatRemaining(m.now.Sub(r.last.Time))+" ago: "+truncateToRunes(r.last.Error, 40))
+		default:
+			line += "  " + ok.Render("✓ "+formatRemaining(m.now.Sub(r.last.Time))+" ago") +
+				" " + formHintStyle.Render(truncateToRunes(r.last.Output, 40))
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "", modalTextStyle.Bold(true).Render("Agent history"))
+	if !m.agent {
+		lines = append(lines, formHintStyle.Render("  No agent attached: start one with passgo agent"))
+	} else if len(m.history) == 0 {
+		lines = append(lines, formHintStyle.Render("  Nothing yet: the agent records what it and attached TUIs change."))
+	}
+	for i := len(m.history) - 1; i >= 0; i-- {
+		j := m.history[i]
+		icon := ok.Render("✓")
+		if j.Error != "" {
+			icon = failed.Render("✗")
+		}
+		line := "  " + icon + "  " + formHintStyle.Render(j.Time.Local().Format("Jan 02 15:04")) +
+			"  " + modalTextStyle.Render(truncateToRunes(j.Command, 32)) +
+			"  " + formHintStyle.Render(truncateToRunes(j.Reason, 36))
+		if j.Error != "" {
+			line += "  " + lipgloss.NewStyle().Foreground(stoppedClr).Render(truncateToRunes(j.Error, 40))
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (m jobsModel) View() string {
+	title := modalTitleStyle.Render("Jobs")
+	lines := m.lines()
+	offset := min(m.offset, len(lines)-1)
+	end := min(len(lines), offset+m.visibleLines())
+	hint := formHintStyle.Render("↑/↓: scroll  r: refresh  Esc: close")
+	content := title + "\n" + strings.Join(lines[offset:end], "\n") + "\n\n" + hint
+	box := modalStyle.Render(content)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// openJobs opens (or refreshes) the Jobs view, fetching the history from
+// the agent first when attached.
+func (m *rootModel) openJobs() tea.Cmd {
+	if m.agent {
+		return fetchAgentJobsCmd()
+	}
+	m.showJobs(nil)
+	return nil
+}
+
+// showJobs shows the Jobs view with history, keeping the scroll position
+// of a refresh.
+func (m *rootModel) showJobs(history []agentJob) {
+	offset := 0
+	if m.currentView == viewJobs {
+		offset = m.jobs.offset
+	}
+	m.jobs = newJobsModel(m.jobRows(time.Now()), history, m.agent, time.Now())
+	m.jobs.offset = offset
+	m.setChildSizes()
+	m.currentView = viewJobs
+}
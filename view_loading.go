@@ -2,6 +2,8 @@
 package main
 
 import (
+	"time"
+
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -10,6 +12,7 @@ import (
 type loadingModel struct {
 	spinner spinner.Model
 	message string
+	started time.Time
 	width   int
 	height  int
 }
@@ -18,10 +21,13 @@ func newLoadingModel(message string) loadingModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = spinnerStyle
-	return loadingModel{spinner: s, message: message}
+	return loadingModel{spinner: s, message: message, started: time.Now()}
 }
 
 func (m loadingModel) Init() tea.Cmd {
+	if accessibleMode {
+		return nil
+	}
 	return m.spinner.Tick
 }
 
@@ -35,8 +41,19 @@ func (m loadingModel) Update(msg tea.Msg) (loadingModel, tea.Cmd) {
 	return m, nil
 }
 
+// stillWorkingAfter is when the loading view starts saying multipass is
+// slow rather than looking stuck.
+const stillWorkingAfter = 5 * time.Second
+
 func (m loadingModel) View() string {
-	content := m.spinner.View() + loadingMsgStyle.Render(m.message)
+	message := m.message
+	if !m.started.IsZero() && time.Since(m.started) > stillWorkingAfter {
+		message += " still working…"
+	}
+	content := m.spinner.View() + loadingMsgStyle.Render(message)
+	if accessibleMode {
+		content = loadingMsgStyle.Render("Busy: " + message)
+	}
 
 	box := modalStyle.Render(content)
 
@@ -1,50 +1,107 @@
-// view_modals.go - Help, version, error, and confirm modal views
+// view_modals.go - Help, version, error, confirm and quit modal views
 package main
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/atotto/clipboard"
+
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 // ─── Help Modal ────────────────────────────────────────────────────────────────
 
+// tableKey is a built-in shortcut of the VM table. The help lists it, and
+// custom actions may not use any of its keys (see parseCustomAction).
+type tableKey struct {
+	keys  string // every character the shortcut binds
+	label string // how the help shows the keys; keys when empty
+	desc  string // "" leaves it out of the help
+}
+
+// tableKeys are the table's built-in shortcuts, in the order of the help.
+var tableKeys = []tableKey{
+	{keys: "h", desc: "Help"},
+	{keys: "i", desc: "VM Info"},
+	{keys: "c", desc: "Quick Create"},
+	{keys: "L", desc: "Quick launch from defaults"},
+	{keys: "a", desc: "Launch another like the last one"},
+	{keys: "C", desc: "Advanced Create (cloud-init)"},
+	{keys: "[", desc: "Stop selected VM"},
+	{keys: "]", desc: "Start selected VM"},
+	{keys: "p", desc: "Suspend selected VM"},
+	{keys: "<", desc: "Stop ALL VMs"},
+	{keys: ">", desc: "Start ALL VMs"},
+	{keys: "b", desc: "Show bulk operation progress"},
+	{keys: "J", desc: "Jobs: schedules, next runs, last results"},
+	{keys: "x", desc: "Delete selected VM, or every VM of a group (undoable)"},
+	{keys: "u", desc: "Undo last delete"},
+	{keys: "d", desc: "Delete and purge selected VM"},
+	{keys: "r", desc: "Recover deleted VM"},
+	{keys: "N", desc: "Repair VM networking (restarts if needed)"},
+	{keys: "!", desc: "Purge ALL deleted VMs"},
+	{keys: "/", desc: "Refresh VM list"},
+	{keys: "f", desc: "Filter VMs by name"},
+	{keys: "s", desc: "Shell (interactive session)"},
+	{keys: "X", desc: "Run a command in the VM"},
+	{keys: "F", desc: "Browse VM files (download / upload)"},
+	{keys: "n", desc: "Create snapshot"},
+	{keys: "m", desc: "Manage snapshots"},
+	{keys: "S", desc: "Search snapshots of all VMs"},
+	{keys: "M", desc: "Manage mounts"},
+	{keys: "e", desc: "Back up / restore files"},
+	{keys: "P", desc: "Expose a VM port on localhost"},
+	{keys: "T", desc: "Template gallery"},
+	{keys: "I", desc: "Golden images"},
+	{keys: "R", desc: "Re-apply cloud-init to the VM"},
+	{keys: "U", desc: "Save the VM's cloud-init as a template"},
+	{keys: "D", desc: "Docker host VM and host docker context"},
+	{keys: "E", desc: "Export table (CSV, Markdown, JSON)"},
+	{keys: "Y", desc: "Copy running VMs' IPs (name ip, JSON, ssh_config)"},
+	{keys: "K", desc: "Recovery options for a VM stuck starting or unknown"},
+	{keys: "z", desc: "Group the table by name prefix, tag, or not at all"},
+	{keys: " ", label: "Enter", desc: "Collapse or expand the selected group (or Space)"},
+	{keys: "Z", desc: "Collapse or expand all groups"},
+	{keys: "B", desc: "Default bridged network"},
+	{keys: "l", desc: "Show the last error in full"},
+	{keys: "v", desc: "Version"},
+	{keys: "1234567890", label: "1-0", desc: "Switch theme (1-9, 0)"},
+	{keys: "q", desc: "Quit"},
+	// Cursor movement, shown in the table footer.
+	{keys: "kjgG"},
+}
+
 type helpModel struct {
-	width  int
-	height int
+	actions []customAction // custom actions from .config
+	width   int
+	height  int
 }
 
-func newHelpModel() helpModel {
-	return helpModel{}
+func newHelpModel(actions []customAction) helpModel {
+	return helpModel{actions: actions}
 }
 
 func (m helpModel) View() string {
 	title := modalTitleStyle.Render("Keyboard Shortcuts")
 
-	shortcuts := []struct{ key, desc string }{
-		{"h", "Help"},
-		{"i", "VM Info"},
-		{"c", "Quick Create"},
-		{"C", "Advanced Create (cloud-init)"},
-		{"[", "Stop selected VM"},
-		{"]", "Start selected VM"},
-		{"p", "Suspend selected VM"},
-		{"<", "Stop ALL VMs"},
-		{">", "Start ALL VMs"},
-		{"d", "Delete selected VM"},
-		{"r", "Recover deleted VM"},
-		{"!", "Purge ALL deleted VMs"},
-		{"/", "Refresh VM list"},
-		{"f", "Filter VMs by name"},
-		{"s", "Shell (interactive session)"},
-		{"n", "Create snapshot"},
-		{"m", "Manage snapshots"},
-		{"M", "Manage mounts"},
-		{"v", "Version"},
-		{"1-0", "Switch theme (1-9, 0)"},
-		{"q", "Quit"},
+	var shortcuts []struct{ key, desc string }
+	for _, k := range tableKeys {
+		if k.desc == "" {
+			continue
+		}
+		label := k.label
+		if label == "" {
+			label = k.keys
+		}
+		shortcuts = append(shortcuts, struct{ key, desc string }{label, k.desc})
+	}
+	for _, a := range m.actions {
+		shortcuts = append(shortcuts, struct{ key, desc string }{a.key, a.label + " (custom)"})
 	}
 
 	var lines []string
@@ -117,23 +174,124 @@ type errorModel struct {
 	message string
 	width   int
 	height  int
+
+	// Set for errors from a multipass command (see errdetail.go): d expands
+	// the dialog to the command, duration and full stderr, c copies them.
+	err      error
+	trace    string
+	expanded bool
+	offset   int    // first stderr line shown when expanded
+	copied   string // result of the last copy
 }
 
 func newErrorModel(title, message string) errorModel {
 	return errorModel{title: title, message: message}
 }
 
+// newErrorModelFor shows err, started by operation trace when it is set.
+// The message is cut to one line when details can be expanded.
+func newErrorModelFor(title string, err error, trace string) errorModel {
+	m := errorModel{title: title, message: err.Error(), err: err, trace: trace}
+	var ce *commandError
+	if errors.As(err, &ce) {
+		m.message = shortError(err)
+	} else {
+		m.err = nil
+	}
+	if trace != "" {
+		m.message += "\n\nOperation " + trace + " in ~/.passgo/passgo.log"
+	}
+	return m
+}
+
+// Update handles the detail keys; Esc and Enter are left to the caller.
+func (m errorModel) Update(msg tea.KeyMsg) errorModel {
+	if m.err == nil {
+		return m
+	}
+	switch msg.String() {
+	case "d":
+		m.expanded, m.offset = !m.expanded, 0
+	case "c":
+		if err := clipboard.WriteAll(errorReport(m.title, m.err, m.trace)); err != nil {
+			m.copied = "Could not copy: " + err.Error()
+		} else {
+			m.copied = "Copied to clipboard"
+		}
+	case "down", "j":
+		if m.expanded {
+			m.offset = min(m.offset+1, max(len(m.stderrLines())-m.stderrRows(), 0))
+		}
+	case "up", "k":
+		m.offset = max(m.offset-1, 0)
+	}
+	return m
+}
+
+// stderrLines is the command's stderr wrapped to the dialog.
+func (m errorModel) stderrLines() []string {
+	var ce *commandError
+	if !errors.As(m.err, &ce) || strings.TrimSpace(ce.stderr) == "" {
+		return nil
+	}
+	w := min(m.width-12, 72)
+	return strings.Split(lipgloss.NewStyle().Width(max(w, 20)).Render(strings.TrimSpace(ce.stderr)), "\n")
+}
+
+// stderrRows is how many stderr lines fit under the details.
+func (m errorModel) stderrRows() int {
+	return max(m.height-20, 3)
+}
+
 func (m errorModel) View() string {
 	t := errorTitleStyle.Render(m.title)
 	body := modalTextStyle.Render(m.message)
-	hint := "\n\n" + formHintStyle.Render("Press Esc or Enter to close")
+	if m.expanded {
+		body += "\n\n" + m.details()
+	}
+	hint := "Press Esc or Enter to close"
+	if m.err != nil {
+		hint = "d: details · c: copy · Esc/Enter: close"
+		if m.expanded {
+			hint = "d: hide details · ↑↓: scroll · c: copy · Esc/Enter: close"
+		}
+	}
+	if m.copied != "" {
+		hint = m.copied + " · " + hint
+	}
 
-	content := t + "\n\n" + body + hint
+	content := t + "\n\n" + body + "\n\n" + formHintStyle.Render(hint)
 	box := modalStyle.Render(content)
 
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
 }
 
+// details lists the failed command and the part of its stderr in view.
+func (m errorModel) details() string {
+	var ce *commandError
+	errors.As(m.err, &ce)
+	w := max(min(m.width-12, 72), 20)
+	row := func(k, v string) string {
+		return infoKeyStyle.Render(k+": ") + modalTextStyle.Width(w-len(k)-2).Render(v)
+	}
+	lines := []string{
+		row("Command", ce.command),
+		row("Error", ce.err.Error()),
+		row("Duration", ce.duration.Round(time.Millisecond).String()),
+	}
+	stderr := m.stderrLines()
+	if len(stderr) == 0 {
+		return strings.Join(append(lines, formHintStyle.Render("(nothing on stderr)")), "\n")
+	}
+	lines = append(lines, "", infoKeyStyle.Render("Stderr:"))
+	end := min(m.offset+m.stderrRows(), len(stderr))
+	lines = append(lines, modalTextStyle.Render(strings.Join(stderr[m.offset:end], "\n")))
+	if len(stderr) > m.stderrRows() {
+		lines = append(lines, formHintStyle.Render(fmt.Sprintf("lines %d-%d of %d", m.offset+1, end, len(stderr))))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // ─── Confirm Modal ─────────────────────────────────────────────────────────────
 
 type confirmModel struct {
@@ -141,13 +299,39 @@ type confirmModel struct {
 	cursor   int // 0=Yes, 1=No
 	width    int
 	height   int
+
+	// Typed confirmation for destructive operations: when token is set the
+	// user must type it exactly; y/n shortcuts are disabled.
+	token   string
+	details []string // context lines shown above the prompt
+	input   textinput.Model
 }
 
 func newConfirmModel(question string) confirmModel {
 	return confirmModel{question: question}
 }
 
+// newTypedConfirmModel builds a confirm dialog that only accepts once the
+// user has typed token, so an accidental double-Enter can't trigger it.
+func newTypedConfirmModel(question, token string, details []string) confirmModel {
+	ti := textinput.New()
+	ti.Placeholder = token
+	ti.CharLimit = 64
+	ti.Focus()
+	return confirmModel{question: question, token: token, details: details, input: ti}
+}
+
+func (m confirmModel) Init() tea.Cmd {
+	if m.token != "" {
+		return textinput.Blink
+	}
+	return nil
+}
+
 func (m confirmModel) Update(msg tea.Msg) (confirmModel, tea.Cmd) {
+	if m.token != "" {
+		return m.updateTyped(msg)
+	}
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
@@ -167,7 +351,33 @@ func (m confirmModel) Update(msg tea.Msg) (confirmModel, tea.Cmd) {
 	return m, nil
 }
 
+func (m confirmModel) updateTyped(msg tea.Msg) (confirmModel, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			return m, func() tea.Msg { return confirmResultMsg{confirmed: false} }
+		case "enter":
+			if m.tokenMatches() {
+				return m, func() tea.Msg { return confirmResultMsg{confirmed: true} }
+			}
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// tokenMatches reports whether the typed text matches the required token.
+func (m confirmModel) tokenMatches() bool {
+	return strings.TrimSpace(m.input.Value()) == m.token
+}
+
 func (m confirmModel) View() string {
+	if m.token != "" {
+		return m.viewTyped()
+	}
+
 	title := modalTitleStyle.Render("Confirm")
 	body := modalTextStyle.Render(m.question)
 
@@ -187,3 +397,106 @@ func (m confirmModel) View() string {
 
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
 }
+
+func (m confirmModel) viewTyped() string {
+	title := errorTitleStyle.Render("Confirm destructive action")
+	body := modalTextStyle.Render(m.question)
+
+	var details string
+	if len(m.details) > 0 {
+		var lines []string
+		for _, d := range m.details {
+			lines = append(lines, detailValStyle.Render(d))
+		}
+		details = "\n\n" + detailPanelStyle.Render(strings.Join(lines, "\n"))
+	}
+
+	prompt := formLabelStyle.Render("Type ") + formActiveLabelStyle.Render(m.token) +
+		formLabelStyle.Render(" to confirm:")
+	status := formHintStyle.Render("Enter: confirm  Esc: cancel")
+	if m.tokenMatches() {
+		status = lipgloss.NewStyle().Foreground(stoppedClr).Bold(true).Render("Press Enter to confirm") +
+			"  " + formHintStyle.Render("Esc: cancel")
+	}
+
+	content := title + "\n\n" + body + details +
+		"\n\n" + prompt + "\n" + m.input.View() + "\n\n" + status
+	box := modalStyle.Render(content)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// ─── Quit Modal ────────────────────────────────────────────────────────────────
+
+// quitModel asks what to do about running operations when the user quits.
+type quitModel struct {
+	from    viewState // view to return to on cancel
+	cursor  int       // 0=Wait, 1=Abandon
+	waiting bool      // the user chose to wait; quit once nothing is running
+	ops     []string  // refreshed by the root model before rendering
+	width   int
+	height  int
+}
+
+// quitChoiceMsg carries the answer: wait, abandon or neither (cancel).
+type quitChoiceMsg struct{ wait, abandon bool }
+
+func newQuitModel(from viewState) quitModel {
+	return quitModel{from: from}
+}
+
+func (m quitModel) Update(msg tea.Msg) (quitModel, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "left", "h":
+		m.cursor = 0
+	case "right", "l":
+		m.cursor = 1
+	case "w":
+		return m, func() tea.Msg { return quitChoiceMsg{wait: true} }
+	case "a", "ctrl+c":
+		return m, func() tea.Msg { return quitChoiceMsg{abandon: true} }
+	case "esc":
+		return m, func() tea.Msg { return quitChoiceMsg{} }
+	case "enter":
+		if m.waiting {
+			return m, nil
+		}
+		choice := quitChoiceMsg{wait: m.cursor == 0, abandon: m.cursor == 1}
+		return m, func() tea.Msg { return choice }
+	}
+	return m, nil
+}
+
+func (m quitModel) View() string {
+	title := modalTitleStyle.Render("Operations still running")
+	var lines []string
+	for _, op := range m.ops {
+		lines = append(lines, detailValStyle.Render("• "+op))
+	}
+	body := detailPanelStyle.Render(strings.Join(lines, "\n"))
+
+	var footer string
+	if m.waiting {
+		footer = loadingMsgStyle.Render("Waiting for them to finish, then quitting…") + "\n\n" +
+			formHintStyle.Render("a: abandon and quit now  Esc: keep passgo open")
+	} else {
+		waitStyle, abandonStyle := formButtonStyle, formButtonStyle
+		if m.cursor == 0 {
+			waitStyle = formActiveButtonStyle
+		} else {
+			abandonStyle = formActiveButtonStyle
+		}
+		footer = modalTextStyle.Render("Abandoned operations may still finish in multipass, but passgo will not record their results.") + "\n\n" +
+			waitStyle.Render(" Wait ") + "  " + abandonStyle.Render(" Abandon ") + "\n\n" +
+			formHintStyle.Render("w: wait  a: abandon  ←→ + Enter  Esc: cancel")
+	}
+
+	content := title + "\n\n" + body + "\n\n" + footer
+	box := modalStyle.Render(content)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTypedConfirmRequiresToken(t *testing.T) {
+	m := newTypedConfirmModel("Delete VM 'vm1'?", "vm1", nil)
+
+	// Enter and "y" must not confirm before the token is typed.
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyEnter}, {Type: tea.KeyRunes, Runes: []rune("y")}} {
+		var cmd tea.Cmd
+		m, cmd = m.Update(key)
+		if cmd != nil {
+			if res, ok := cmd().(confirmResultMsg); ok && res.confirmed {
+				t.Fatalf("confirmed without typing the token (key %q)", key.String())
+			}
+		}
+	}
+
+	m.input.SetValue("vm1")
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatalf("expected confirm command once token matches")
+	}
+	if res, ok := cmd().(confirmResultMsg); !ok || !res.confirmed {
+		t.Fatalf("expected confirmed result, got %#v", res)
+	}
+}
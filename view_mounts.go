@@ -161,6 +161,12 @@ func (m mountManageModel) View() string {
 		mount := m.mounts[m.cursor]
 		detail = detailKeyStyle.Render("Source: ") + detailValStyle.Render(mount.SourcePath) + "\n" +
 			detailKeyStyle.Render("Target: ") + detailValStyle.Render(mount.TargetPath)
+		if len(mount.UIDMaps) > 0 {
+			detail += "\n" + detailKeyStyle.Render("UID map: ") + detailValStyle.Render(strings.Join(mount.UIDMaps, ", "))
+		}
+		if len(mount.GIDMaps) > 0 {
+			detail += "\n" + detailKeyStyle.Render("GID map: ") + detailValStyle.Render(strings.Join(mount.GIDMaps, ", "))
+		}
 	}
 
 	// Actions
@@ -189,6 +195,111 @@ func (m mountManageModel) View() string {
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
 }
 
+// ─── Mount Options ─────────────────────────────────────────────────────────────
+
+// mountFormRows is the number of cursor positions in the add and modify
+// forms: source, target, the three option rows, and two buttons.
+const mountFormRows = 7
+
+// mountOptionFields are the Type, UID map and GID map rows shared by the
+// add and modify forms. Rows are numbered 0-2 from the form's cursor 2.
+type mountOptionFields struct {
+	types    []string // offered mount types; nil means all of mountTypes
+	typeIdx  int
+	uidInput textinput.Model
+	gidInput textinput.Model
+}
+
+func newMountOptionFields(mount MountInfo) mountOptionFields {
+	ui := textinput.New()
+	ui.Placeholder = "host:vm, e.g. 1000:1000"
+	ui.CharLimit = 200
+	ui.SetValue(strings.Join(mount.UIDMaps, ", "))
+	gi := textinput.New()
+	gi.Placeholder = "host:vm, e.g. 1000:1000"
+	gi.CharLimit = 200
+	gi.SetValue(strings.Join(mount.GIDMaps, ", "))
+	return mountOptionFields{uidInput: ui, gidInput: gi}
+}
+
+// typeList returns the mount types the Type row cycles through.
+func (f mountOptionFields) typeList() []string {
+	if len(f.types) == 0 {
+		return mountTypes
+	}
+	return f.types
+}
+
+// options returns the mount flags set in the form.
+func (f mountOptionFields) options() (mountOptions, error) {
+	uids, err := parseIDMaps(f.uidInput.Value())
+	if err != nil {
+		return mountOptions{}, err
+	}
+	gids, err := parseIDMaps(f.gidInput.Value())
+	if err != nil {
+		return mountOptions{}, err
+	}
+	return mountOptions{Type: f.typeList()[f.typeIdx], UIDMaps: uids, GIDMaps: gids}, nil
+}
+
+// focus focuses option row (-1 blurs both inputs).
+func (f *mountOptionFields) focus(row int) {
+	f.uidInput.Blur()
+	f.gidInput.Blur()
+	switch row {
+	case 1:
+		f.uidInput.Focus()
+	case 2:
+		f.gidInput.Focus()
+	}
+}
+
+func (f mountOptionFields) update(row int, msg tea.Msg) (mountOptionFields, tea.Cmd) {
+	var cmd tea.Cmd
+	switch row {
+	case 0:
+		if key, ok := msg.(tea.KeyMsg); ok && (key.String() == "left" || key.String() == "right" || key.String() == " ") {
+			f.typeIdx = (f.typeIdx + 1) % len(f.typeList())
+		}
+	case 1:
+		f.uidInput, cmd = f.uidInput.Update(msg)
+	case 2:
+		f.gidInput, cmd = f.gidInput.Update(msg)
+	}
+	return f, cmd
+}
+
+// view renders the option rows; active is the focused row or -1.
+func (f mountOptionFields) view(active int) string {
+	label := func(row int, s string) string {
+		if row == active {
+			return lipgloss.NewStyle().Width(16).Render(formActiveLabelStyle.Render(s))
+		}
+		return lipgloss.NewStyle().Width(16).Render(formLabelStyle.Render(s))
+	}
+	input := func(row int, in textinput.Model) string {
+		switch {
+		case row == active:
+			return in.View()
+		case in.Value() == "":
+			return lipgloss.NewStyle().Foreground(subtle).Render("none")
+		}
+		return formValueStyle.Render(in.Value())
+	}
+	typeVal := formValueStyle.Render(f.typeList()[f.typeIdx])
+	if active == 0 {
+		typeVal = lipgloss.NewStyle().Foreground(accent).Render("◀ ") + typeVal + lipgloss.NewStyle().Foreground(accent).Render(" ▶")
+	}
+	out := fmt.Sprintf("  %s  %s\n", label(0, "Type:"), typeVal) +
+		fmt.Sprintf("  %s  %s\n", label(1, "UID map:"), input(1, f.uidInput)) +
+		fmt.Sprintf("  %s  %s\n", label(2, "GID map:"), input(2, f.gidInput))
+	if f.typeList()[f.typeIdx] == "native" {
+		out += formHintStyle.Render("  Native mounts need the VM stopped and a driver that supports them") + "\n"
+	}
+	return out
+}
+
 // ─── Mount Add (File picker + Target form) ─────────────────────────────────────
 
 type mountAddModel struct {
@@ -203,7 +314,9 @@ type mountAddModel struct {
 	// Target form
 	sourceInput textinput.Model
 	targetInput textinput.Model
-	formCursor  int // 0=source, 1=target, 2=mount, 3=cancel
+	opts        mountOptionFields
+	formCursor  int // 0=source, 1=target, 2-4=options, 5=mount, 6=cancel
+	errMsg      string
 	width       int
 	height      int
 }
@@ -226,6 +339,7 @@ func newMountAddModel(vmName string, w, h int) mountAddModel {
 		currentDir:  homeDir,
 		sourceInput: si,
 		targetInput: ti,
+		opts:        newMountOptionFields(MountInfo{}),
 		width:       w,
 		height:      h,
 	}
@@ -344,33 +458,39 @@ func (m mountAddModel) updateTargetForm(msg tea.KeyMsg) (mountAddModel, tea.Cmd)
 		return m, nil
 	case "tab", "down":
 		m.blurForm()
-		m.formCursor = (m.formCursor + 1) % 4
+		m.formCursor = (m.formCursor + 1) % mountFormRows
 		m.focusForm()
 		return m, nil
 	case "shift+tab", "up":
 		m.blurForm()
-		m.formCursor = (m.formCursor - 1 + 4) % 4
+		m.formCursor = (m.formCursor - 1 + mountFormRows) % mountFormRows
 		m.focusForm()
 		return m, nil
 	case "enter":
-		if m.formCursor == 3 { // cancel
+		if m.formCursor == 6 { // cancel
 			m.phase = 0
 			return m, nil
 		}
-		if m.formCursor == 2 { // mount
+		if m.formCursor == 5 { // mount
 			source := m.sourceInput.Value()
 			target := m.targetInput.Value()
 			if source == "" || target == "" {
 				return m, nil
 			}
-			return m, mountCmd(source, m.vmName, target)
+			opts, err := m.opts.options()
+			if err != nil {
+				m.errMsg = err.Error()
+				return m, nil
+			}
+			return m, mountCmd(source, m.vmName, target, opts)
 		}
 		m.blurForm()
-		m.formCursor = (m.formCursor + 1) % 4
+		m.formCursor = (m.formCursor + 1) % mountFormRows
 		m.focusForm()
 		return m, nil
 	}
 
+	m.errMsg = ""
 	switch m.formCursor {
 	case 0:
 		var cmd tea.Cmd
@@ -380,6 +500,10 @@ func (m mountAddModel) updateTargetForm(msg tea.KeyMsg) (mountAddModel, tea.Cmd)
 		var cmd tea.Cmd
 		m.targetInput, cmd = m.targetInput.Update(msg)
 		return m, cmd
+	case 2, 3, 4:
+		var cmd tea.Cmd
+		m.opts, cmd = m.opts.update(m.formCursor-2, msg)
+		return m, cmd
 	}
 	return m, nil
 }
@@ -387,6 +511,7 @@ func (m mountAddModel) updateTargetForm(msg tea.KeyMsg) (mountAddModel, tea.Cmd)
 func (m *mountAddModel) blurForm() {
 	m.sourceInput.Blur()
 	m.targetInput.Blur()
+	m.opts.focus(-1)
 }
 
 func (m *mountAddModel) focusForm() {
@@ -395,6 +520,8 @@ func (m *mountAddModel) focusForm() {
 		m.sourceInput.Focus()
 	case 1:
 		m.targetInput.Focus()
+	case 2, 3, 4:
+		m.opts.focus(m.formCursor - 2)
 	}
 }
 
@@ -468,19 +595,23 @@ func (m mountAddModel) viewTargetForm() string {
 
 	mountStyle := formButtonStyle
 	cancelStyle := formButtonStyle
-	if m.formCursor == 2 {
+	if m.formCursor == 5 {
 		mountStyle = formActiveButtonStyle
 	}
-	if m.formCursor == 3 {
+	if m.formCursor == 6 {
 		cancelStyle = formActiveButtonStyle
 	}
 
-	hint := formHintStyle.Render("Tab: navigate  Enter: submit  Esc: back to browser")
+	hint := formHintStyle.Render("Tab: navigate  ←→: type  Enter: submit  Esc: back to browser")
 
 	content := title + "\n\n" +
 		fmt.Sprintf("  %s  %s\n", lipgloss.NewStyle().Width(16).Render(srcLabel), srcVal) +
-		fmt.Sprintf("  %s  %s\n\n", lipgloss.NewStyle().Width(16).Render(tgtLabel), tgtVal) +
+		fmt.Sprintf("  %s  %s\n", lipgloss.NewStyle().Width(16).Render(tgtLabel), tgtVal) +
+		m.opts.view(m.formCursor-2) + "\n" +
 		"  " + mountStyle.Render("[ Mount ]") + "  " + cancelStyle.Render("[ Cancel ]") + "\n\n" + hint
+	if m.errMsg != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(stoppedClr).Render(m.errMsg)
+	}
 
 	box := modalStyle.Render(content)
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
@@ -493,7 +624,9 @@ type mountModifyModel struct {
 	oldMount    MountInfo
 	sourceInput textinput.Model
 	targetInput textinput.Model
-	cursor      int // 0=source, 1=target, 2=save, 3=cancel
+	opts        mountOptionFields
+	cursor      int // 0=source, 1=target, 2-4=options, 5=save, 6=cancel
+	errMsg      string
 	width       int
 	height      int
 }
@@ -513,6 +646,7 @@ func newMountModifyModel(vmName string, mount MountInfo, w, h int) mountModifyMo
 		oldMount:    mount,
 		sourceInput: si,
 		targetInput: ti,
+		opts:        newMountOptionFields(mount),
 		width:       w,
 		height:      h,
 	}
@@ -524,6 +658,7 @@ type mountModifySubmitMsg struct {
 	oldTarget string
 	newSource string
 	newTarget string
+	opts      mountOptions
 }
 
 func (m mountModifyModel) Init() tea.Cmd { return textinput.Blink }
@@ -536,40 +671,51 @@ func (m mountModifyModel) Update(msg tea.Msg) (mountModifyModel, tea.Cmd) {
 			return m, func() tea.Msg { return backToTableMsg{} }
 		case "tab", "down":
 			m.blur()
-			m.cursor = (m.cursor + 1) % 4
+			m.cursor = (m.cursor + 1) % mountFormRows
 			m.focus()
 			return m, nil
 		case "shift+tab", "up":
 			m.blur()
-			m.cursor = (m.cursor - 1 + 4) % 4
+			m.cursor = (m.cursor - 1 + mountFormRows) % mountFormRows
 			m.focus()
 			return m, nil
 		case "enter":
-			if m.cursor == 3 {
+			if m.cursor == 6 {
 				return m, func() tea.Msg { return backToTableMsg{} }
 			}
-			if m.cursor == 2 {
+			if m.cursor == 5 {
 				src := m.sourceInput.Value()
 				tgt := m.targetInput.Value()
 				if src == "" || tgt == "" {
 					return m, nil
 				}
+				opts, err := m.opts.options()
+				if err != nil {
+					m.errMsg = err.Error()
+					return m, nil
+				}
 				return m, func() tea.Msg {
 					return mountModifySubmitMsg{
 						vmName:    m.vmName,
 						oldTarget: m.oldMount.TargetPath,
 						newSource: src,
 						newTarget: tgt,
+						opts:      opts,
 					}
 				}
 			}
 			m.blur()
-			m.cursor = (m.cursor + 1) % 4
+			m.cursor = (m.cursor + 1) % mountFormRows
 			m.focus()
 			return m, nil
 		}
 
+		m.errMsg = ""
 		switch m.cursor {
+		case 2, 3, 4:
+			var cmd tea.Cmd
+			m.opts, cmd = m.opts.update(m.cursor-2, msg)
+			return m, cmd
 		case 0:
 			var cmd tea.Cmd
 			m.sourceInput, cmd = m.sourceInput.Update(msg)
@@ -597,6 +743,7 @@ func (m mountModifyModel) Update(msg tea.Msg) (mountModifyModel, tea.Cmd) {
 func (m *mountModifyModel) blur() {
 	m.sourceInput.Blur()
 	m.targetInput.Blur()
+	m.opts.focus(-1)
 }
 
 func (m *mountModifyModel) focus() {
@@ -605,6 +752,8 @@ func (m *mountModifyModel) focus() {
 		m.sourceInput.Focus()
 	case 1:
 		m.targetInput.Focus()
+	case 2, 3, 4:
+		m.opts.focus(m.cursor - 2)
 	}
 }
 
@@ -634,19 +783,23 @@ func (m mountModifyModel) View() string {
 
 	saveStyle := formButtonStyle
 	cancelStyle := formButtonStyle
-	if m.cursor == 2 {
+	if m.cursor == 5 {
 		saveStyle = formActiveButtonStyle
 	}
-	if m.cursor == 3 {
+	if m.cursor == 6 {
 		cancelStyle = formActiveButtonStyle
 	}
 
-	hint := formHintStyle.Render("Tab: navigate  Enter: submit  Esc: cancel")
+	hint := formHintStyle.Render("Tab: navigate  ←→: type  Enter: submit  Esc: cancel")
 
 	content := title + "\n\n" +
 		fmt.Sprintf("  %s  %s\n", lipgloss.NewStyle().Width(16).Render(srcLabel), srcVal) +
-		fmt.Sprintf("  %s  %s\n\n", lipgloss.NewStyle().Width(16).Render(tgtLabel), tgtVal) +
+		fmt.Sprintf("  %s  %s\n", lipgloss.NewStyle().Width(16).Render(tgtLabel), tgtVal) +
+		m.opts.view(m.cursor-2) + "\n" +
 		"  " + saveStyle.Render("[ Save ]") + "  " + cancelStyle.Render("[ Cancel ]") + "\n\n" + hint
+	if m.errMsg != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(stoppedClr).Render(m.errMsg)
+	}
 
 	box := modalStyle.Render(content)
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
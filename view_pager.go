@@ -0,0 +1,345 @@
+// view_pager.go - Full-screen pager for long command output: search, wrap, save
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Input modes of the pager's bottom line.
+const (
+	pagerBrowse = iota
+	pagerSearch
+	pagerSave
+)
+
+// pagerRow is one screen line: a whole output line, or part of one when
+// wrapping.
+type pagerRow struct {
+	line int // index into lines
+	text string
+}
+
+// pagerModel shows text a screen at a time. It returns to returnTo when
+// closed.
+type pagerModel struct {
+	title    string
+	text     string // as given, for saving
+	lines    []string
+	rows     []pagerRow
+	returnTo viewState
+	fileName string // suggested name for s
+	top      int    // first row shown
+	left     int    // columns scrolled right when not wrapping
+	wrap     bool
+	query    string // last search, matched case-insensitively
+	matches  []int  // lines containing query
+	mode     int
+	input    textinput.Model
+	status   string // result of the last search or save
+	width    int
+	height   int
+}
+
+// openPagerMsg asks the root model to show text in the pager.
+type openPagerMsg struct {
+	title    string
+	text     string
+	fileName string
+}
+
+// pagerClosedMsg returns from the pager to the view that opened it.
+type pagerClosedMsg struct{}
+
+// pagerSavedMsg reports the outcome of saving the pager's text.
+type pagerSavedMsg struct {
+	path string
+	err  error
+}
+
+func newPagerModel(title, text, fileName string, returnTo viewState) pagerModel {
+	shown := strings.ReplaceAll(strings.TrimRight(text, "\n"), "\t", "    ")
+	in := textinput.New()
+	in.CharLimit = 260
+	return pagerModel{title: title, text: text, lines: strings.Split(shown, "\n"), fileName: fileName, returnTo: returnTo, input: in}
+}
+
+// setSize lays the rows out for a w×h screen.
+func (m *pagerModel) setSize(w, h int) {
+	m.width, m.height = w, h
+	m.layout()
+}
+
+// page is how many rows fit between the title and the bottom line.
+func (m pagerModel) page() int {
+	return max(1, m.height-3)
+}
+
+// layout splits lines into rows, keeping the top line in place.
+func (m *pagerModel) layout() {
+	topLine := 0
+	if m.top < len(m.rows) {
+		topLine = m.rows[m.top].line
+	}
+	width := max(10, m.width)
+	m.rows = nil
+	for i, l := range m.lines {
+		runes := []rune(l)
+		if !m.wrap || len(runes) <= width {
+			m.rows = append(m.rows, pagerRow{i, l})
+			continue
+		}
+		for len(runes) > 0 {
+			n := min(width, len(runes))
+			m.rows = append(m.rows, pagerRow{i, string(runes[:n])})
+			runes = runes[n:]
+		}
+	}
+	m.top = 0
+	m.gotoLine(topLine)
+	m.left = 0
+}
+
+// gotoLine scrolls so that output line i is at the top, as far as the end
+// allows.
+func (m *pagerModel) gotoLine(i int) {
+	for r, row := range m.rows {
+		if row.line >= i {
+			m.scrollTo(r)
+			return
+		}
+	}
+}
+
+func (m *pagerModel) scrollTo(row int) {
+	m.top = max(0, min(row, len(m.rows)-m.page()))
+}
+
+// search finds the lines containing query and jumps to the first one at
+// or after the top.
+func (m *pagerModel) search(query string) {
+	m.query = query
+	m.matches = nil
+	if query == "" {
+		m.status = ""
+		return
+	}
+	q := strings.ToLower(query)
+	for i, l := range m.lines {
+		if strings.Contains(strings.ToLower(l), q) {
+			m.matches = append(m.matches, i)
+		}
+	}
+	if len(m.matches) == 0 {
+		m.status = fmt.Sprintf("No match for %q", query)
+		return
+	}
+	m.jump(1, true)
+}
+
+// jump moves to the next (dir 1) or previous (dir -1) match, wrapping
+// around. With inclusive, a match on the top line counts.
+func (m *pagerModel) jump(dir int, inclusive bool) {
+	if len(m.matches) == 0 {
+		return
+	}
+	current := 0
+	if m.top < len(m.rows) {
+		current = m.rows[m.top].line
+	}
+	target := -1
+	for k := range m.matches {
+		i := k
+		if dir < 0 {
+			i = len(m.matches) - 1 - k
+		}
+		line := m.matches[i]
+		if (dir > 0 && (line > current || inclusive && line == current)) || (dir < 0 && line < current) {
+			target = i
+			break
+		}
+	}
+	if target < 0 { // wrap around
+		target = 0
+		if dir < 0 {
+			target = len(m.matches) - 1
+		}
+	}
+	m.gotoLine(m.matches[target])
+	m.status = fmt.Sprintf("Match %d of %d for %q", target+1, len(m.matches), m.query)
+}
+
+// savePagerCmd writes text to path.
+func savePagerCmd(path, text string) tea.Cmd {
+	return func() tea.Msg {
+		full, err := expandHomePath(path)
+		if err == nil {
+			err = os.WriteFile(full, []byte(text), 0o600)
+		}
+		return pagerSavedMsg{path: full, err: err}
+	}
+}
+
+func (m pagerModel) Update(msg tea.Msg) (pagerModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case pagerSavedMsg:
+		if msg.err != nil {
+			m.status = "✗ Save failed: " + msg.err.Error()
+		} else {
+			m.status = "✓ Saved to " + msg.path
+		}
+		return m, nil
+	case tea.KeyMsg:
+		if m.mode != pagerBrowse {
+			return m.updateInput(msg)
+		}
+		switch msg.String() {
+		case "esc", "q":
+			return m, func() tea.Msg { return pagerClosedMsg{} }
+		case "up", "k":
+			m.scrollTo(m.top - 1)
+		case "down", "j", "enter":
+			m.scrollTo(m.top + 1)
+		case "pgup", "b":
+			m.scrollTo(m.top - m.page())
+		case "pgdown", " ", "f":
+			m.scrollTo(m.top + m.page())
+		case "home", "g":
+			m.scrollTo(0)
+		case "end", "G":
+			m.scrollTo(len(m.rows))
+		case "left", "h":
+			m.left = max(0, m.left-8)
+		case "right", "l":
+			if !m.wrap {
+				m.left += 8
+			}
+		case "w":
+			m.wrap = !m.wrap
+			m.layout()
+		case "/":
+			m.mode = pagerSearch
+			m.input.SetValue(m.query)
+			m.input.CursorEnd()
+			m.input.Focus()
+			return m, textinput.Blink
+		case "n":
+			m.jump(1, false)
+		case "N":
+			m.jump(-1, false)
+		case "s":
+			m.mode = pagerSave
+			m.input.SetValue(m.fileName)
+			m.input.CursorEnd()
+			m.input.Focus()
+			return m, textinput.Blink
+		}
+		return m, nil
+	}
+	if m.mode != pagerBrowse {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// updateInput handles keys while the search or save prompt is open.
+func (m pagerModel) updateInput(msg tea.KeyMsg) (pagerModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = pagerBrowse
+		m.input.Blur()
+		return m, nil
+	case "enter":
+		value := strings.TrimSpace(m.input.Value())
+		mode := m.mode
+		m.mode = pagerBrowse
+		m.input.Blur()
+		if mode == pagerSearch {
+			m.search(value)
+			return m, nil
+		}
+		if value == "" {
+			return m, nil
+		}
+		m.status = "Saving…"
+		return m, savePagerCmd(value, m.text)
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// highlightMatches marks the occurrences of query in s.
+func highlightMatches(s, query string, style lipgloss.Style) string {
+	lower, q := strings.ToLower(s), strings.ToLower(query)
+	if q == "" || len(lower) != len(s) { // case mapping changed the byte offsets
+		return s
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, q)
+		if i < 0 {
+			break
+		}
+		b.WriteString(s[:i])
+		b.WriteString(style.Render(s[i : i+len(q)]))
+		s, lower = s[i+len(q):], lower[i+len(q):]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+func (m pagerModel) View() string {
+	match := lipgloss.NewStyle().Background(highlight).Foreground(lipgloss.Color("0"))
+	end := min(len(m.rows), m.top+m.page())
+	var body []string
+	for _, row := range m.rows[m.top:end] {
+		text := row.text
+		if !m.wrap {
+			runes := []rune(text)
+			text = string(runes[min(m.left, len(runes)):])
+			text = truncateToRunes(text, max(10, m.width-1))
+		}
+		body = append(body, highlightMatches(text, m.query, match))
+	}
+	for len(body) < m.page() {
+		body = append(body, lipgloss.NewStyle().Foreground(subtle).Render("~"))
+	}
+
+	position := "empty"
+	if len(m.rows) > 0 {
+		position = fmt.Sprintf("lines %d-%d of %d", m.rows[m.top].line+1, m.rows[max(m.top, end-1)].line+1, len(m.lines))
+	}
+	header := modalTitleStyle.Render(truncateToRunes(m.title, max(10, m.width-30))) + "  " + formHintStyle.Render(position)
+
+	var footer string
+	switch m.mode {
+	case pagerSearch:
+		footer = "/" + m.input.View()
+	case pagerSave:
+		footer = "Save to: " + m.input.View()
+	default:
+		wrap := "wrap"
+		if m.wrap {
+			wrap = "no wrap"
+		}
+		footer = formHintStyle.Render("↑↓ PgUp/PgDn g/G: scroll  ←→: pan  /: search  n/N: next/prev  w: " + wrap + "  s: save  Esc: close")
+		if m.status != "" {
+			footer = formHintStyle.Render(m.status) + "  " + footer
+		}
+	}
+	return header + "\n" + strings.Join(body, "\n") + "\n" + footer
+}
+
+// pagerFileName suggests a file name for output saved from vm at now.
+func pagerFileName(vm string, now time.Time) string {
+	return "passgo-" + vm + "-" + now.Format("20060102-150405") + ".log"
+}
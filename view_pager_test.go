@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func pagerKeys(m pagerModel, keys ...string) pagerModel {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		m, _ = m.Update(msg)
+	}
+	return m
+}
+
+func TestPagerSearchAndWrap(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 100; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	lines[41] = "Oct 15 sshd[812]: ERROR " + strings.Repeat("x", 50)
+	lines[79] = "kernel: error again"
+	m := newPagerModel("web: journalctl", strings.Join(lines, "\n")+"\n", "out.log", viewExec)
+	m.setSize(40, 13) // 10 rows
+
+	m = pagerKeys(m, "/", "e", "r", "r", "o", "r", "enter")
+	if m.top != 41 || len(m.matches) != 2 || m.status != `Match 1 of 2 for "error"` {
+		t.Fatalf("top %d, matches %v, status %q", m.top, m.matches, m.status)
+	}
+	m = pagerKeys(m, "n")
+	if m.top != 79 {
+		t.Errorf("n: top = %d, want 79", m.top)
+	}
+	m = pagerKeys(m, "n")
+	if m.top != 41 {
+		t.Errorf("n wraps around: top = %d", m.top)
+	}
+
+	m = pagerKeys(m, "w")
+	if len(m.rows) != 101 || m.rows[m.top].line != 41 || m.rows[m.top+1].line != 41 {
+		t.Errorf("wrapped: %d rows, top row of line %d", len(m.rows), m.rows[m.top].line)
+	}
+	m = pagerKeys(m, "G")
+	if m.top != len(m.rows)-m.page() {
+		t.Errorf("G: top = %d", m.top)
+	}
+	if view := m.View(); !strings.Contains(view, "lines 91-100 of 100") {
+		t.Errorf("view lacks the position:\n%s", view)
+	}
+
+	m.search("")
+	if m.query != "" || len(m.matches) != 0 {
+		t.Error("an empty search clears the highlight")
+	}
+}
+
+func TestPagerSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	m := newPagerModel("web: ls", "a\tb\nc\n", path, viewExec)
+	m.setSize(80, 24)
+	m = pagerKeys(m, "s")
+	var cmd tea.Cmd
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("enter should save")
+	}
+	m, _ = m.Update(cmd())
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "a\tb\nc\n" || !strings.HasPrefix(m.status, "✓ Saved") {
+		t.Errorf("saved %q, %v; status %q", data, err, m.status)
+	}
+	if _, cmd := pagerKeys(m, "q").Update(tea.KeyMsg{Type: tea.KeyEsc}); cmd == nil {
+		t.Fatal("esc should close")
+	} else if _, ok := cmd().(pagerClosedMsg); !ok {
+		t.Error("esc should close the pager")
+	}
+}
+
+func TestExecOpensPager(t *testing.T) {
+	m := execModel{vmName: "web", width: 80, height: 30}
+	m, cmd := m.Update(execResultMsg{vmName: "web", command: "uptime", output: "up 3 days\n"})
+	if cmd != nil {
+		t.Error("short output stays in the dialog")
+	}
+	m, cmd = m.Update(execResultMsg{vmName: "web", command: "journalctl", output: strings.Repeat("log line\n", 50)})
+	if cmd == nil {
+		t.Fatal("long output should open the pager")
+	}
+	msg, ok := cmd().(openPagerMsg)
+	if !ok || msg.title != "web: journalctl" || strings.Count(msg.text, "\n") != 50 {
+		t.Errorf("msg = %+v", msg)
+	}
+
+	root := rootModel{table: newTableModel(), currentView: viewExec, exec: m, width: 80, height: 30}
+	next, _ := root.Update(msg)
+	root = next.(rootModel)
+	if root.currentView != viewPager || len(root.pager.rows) != 50 {
+		t.Fatalf("view %d, rows %d", root.currentView, len(root.pager.rows))
+	}
+	next, _ = root.Update(pagerClosedMsg{})
+	if next.(rootModel).currentView != viewExec {
+		t.Error("closing the pager returns to the exec dialog")
+	}
+}
@@ -0,0 +1,241 @@
+// view_reapply.go - Dialog for re-applying a cloud-config to an existing VM
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Cursor positions of the re-apply dialog.
+const (
+	reapplyFieldTemplate = iota
+	reapplyFieldModules
+	reapplyFieldApply
+)
+
+// reapplyModel picks a cloud-init template and the modules to re-run.
+type reapplyModel struct {
+	vmName       string
+	templates    []TemplateOption
+	templateIdx  int
+	cleanupDirs  []string
+	modulesInput textinput.Model
+	cursor       int
+	errMsg       string
+	width        int
+	height       int
+}
+
+// reapplyRequestMsg asks the root model to confirm and re-apply a template.
+type reapplyRequestMsg struct {
+	vmName      string
+	template    TemplateOption
+	modules     []string // nil re-runs every stage
+	cleanupDirs []string
+}
+
+func newReapplyModel(vmName string, templates []TemplateOption, cleanupDirs []string, w, h int) reapplyModel {
+	mi := textinput.New()
+	mi.Placeholder = "all, or e.g. write_files runcmd"
+	mi.CharLimit = 200
+	return reapplyModel{vmName: vmName, templates: templates, cleanupDirs: cleanupDirs, modulesInput: mi, width: w, height: h}
+}
+
+func (m reapplyModel) Init() tea.Cmd { return textinput.Blink }
+
+func (m reapplyModel) Update(msg tea.Msg) (reapplyModel, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		m.modulesInput, cmd = m.modulesInput.Update(msg)
+		return m, cmd
+	}
+	switch key.String() {
+	case "esc":
+		CleanupTempDirs(m.cleanupDirs)
+		return m, func() tea.Msg { return backToTableMsg{} }
+	case "tab", "down":
+		m.cursor = min(m.cursor+1, reapplyFieldApply)
+		m.syncFocus()
+		return m, nil
+	case "shift+tab", "up":
+		m.cursor = max(m.cursor-1, 0)
+		m.syncFocus()
+		return m, nil
+	case "left":
+		if m.cursor == reapplyFieldTemplate && m.templateIdx > 0 {
+			m.templateIdx--
+			return m, nil
+		}
+	case "right":
+		if m.cursor == reapplyFieldTemplate && m.templateIdx < len(m.templates)-1 {
+			m.templateIdx++
+			return m, nil
+		}
+	case "enter":
+		if len(m.templates) == 0 {
+			m.errMsg = "No cloud-init templates found"
+			return m, nil
+		}
+		modules, err := parseReapplyModules(m.modulesInput.Value())
+		if err != nil {
+			m.errMsg = err.Error()
+			return m, nil
+		}
+		req := reapplyRequestMsg{vmName: m.vmName, template: m.templates[m.templateIdx], modules: modules, cleanupDirs: m.cleanupDirs}
+		return m, func() tea.Msg { return req }
+	}
+	if m.cursor == reapplyFieldModules {
+		var cmd tea.Cmd
+		m.modulesInput, cmd = m.modulesInput.Update(msg)
+		m.errMsg = ""
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m *reapplyModel) syncFocus() {
+	if m.cursor == reapplyFieldModules {
+		m.modulesInput.Focus()
+	} else {
+		m.modulesInput.Blur()
+	}
+}
+
+func (m reapplyModel) View() string {
+	title := formTitleStyle.Render(fmt.Sprintf("Re-apply cloud-init: %s", m.vmName))
+	labelWidth := lipgloss.NewStyle().Width(11)
+
+	templateLabel := formLabelStyle.Render("Template:")
+	templateVal := lipgloss.NewStyle().Foreground(subtle).Render("(none found)")
+	from := ""
+	if len(m.templates) > 0 {
+		t := m.templates[m.templateIdx]
+		from = t.Provenance(true)
+		if t.Warning != "" {
+			from += "  ⚠ " + t.Warning
+		}
+		templateVal = lipgloss.NewStyle().Foreground(subtle).Render(t.Label)
+		if m.cursor == reapplyFieldTemplate {
+			left, right := "  ", "  "
+			if m.templateIdx > 0 {
+				left = lipgloss.NewStyle().Foreground(accent).Render("◀ ")
+			}
+			if m.templateIdx < len(m.templates)-1 {
+				right = lipgloss.NewStyle().Foreground(accent).Render(" ▶")
+			}
+			templateVal = left + formValueStyle.Render(t.Label) + right
+		}
+	}
+	if m.cursor == reapplyFieldTemplate {
+		templateLabel = formActiveLabelStyle.Render("Template:")
+	}
+
+	modulesLabel := formLabelStyle.Render("Modules:")
+	modulesVal := formValueStyle.Render(m.modulesInput.Value())
+	if m.modulesInput.Value() == "" {
+		modulesVal = formValueStyle.Render("all")
+	}
+	if m.cursor == reapplyFieldModules {
+		modulesLabel = formActiveLabelStyle.Render("Modules:")
+		modulesVal = m.modulesInput.View()
+	}
+
+	button := formButtonStyle.Render("[ Apply ]")
+	if m.cursor == reapplyFieldApply {
+		button = formActiveButtonStyle.Render("[ Apply ]")
+	}
+
+	content := title + "\n\n" +
+		fmt.Sprintf("  %s  %s\n", labelWidth.Render(templateLabel), templateVal)
+	if from != "" {
+		content += formHintStyle.Render("  "+from) + "\n"
+	}
+	if len(m.templates) > 0 && m.templates[m.templateIdx].Description != "" {
+		content += renderDescription(m.templates[m.templateIdx].Description, 70) + "\n"
+	}
+	content += fmt.Sprintf("  %s  %s\n", labelWidth.Render(modulesLabel), modulesVal) +
+		formHintStyle.Render("  all: clean and re-run every stage; or name modules to run just those") + "\n\n" +
+		"  " + button + "\n\n" +
+		formHintStyle.Render("Tab/↑↓: navigate  ←→: template  Enter: apply  Esc: cancel")
+	if m.errMsg != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(stoppedClr).Render(m.errMsg)
+	}
+
+	box := modalStyle.Render(content)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// ─── User-data Import Form ─────────────────────────────────────────────────────
+
+// userDataImportModel names the template a VM's user-data is saved as.
+type userDataImportModel struct {
+	vmName    string
+	dir       string // where the template is saved
+	nameInput textinput.Model
+	errMsg    string
+	width     int
+	height    int
+}
+
+// userDataImportSubmitMsg asks root to save vmName's user-data as name.
+type userDataImportSubmitMsg struct {
+	vmName string
+	name   string
+}
+
+func newUserDataImportModel(vmName, dir string, w, h int) userDataImportModel {
+	ni := textinput.New()
+	ni.Placeholder = vmName + ".yaml"
+	ni.CharLimit = 100
+	ni.SetValue(vmName + ".yaml")
+	ni.Focus()
+	return userDataImportModel{vmName: vmName, dir: dir, nameInput: ni, width: w, height: h}
+}
+
+func (m userDataImportModel) Init() tea.Cmd { return textinput.Blink }
+
+func (m userDataImportModel) Update(msg tea.Msg) (userDataImportModel, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			return m, func() tea.Msg { return backToTableMsg{} }
+		case "enter":
+			name, err := userDataTemplateName(m.nameInput.Value())
+			if err == nil {
+				if _, statErr := os.Stat(filepath.Join(m.dir, name)); statErr == nil {
+					err = fmt.Errorf("%s already exists in %s", name, m.dir)
+				}
+			}
+			if err != nil {
+				m.errMsg = err.Error()
+				return m, nil
+			}
+			req := userDataImportSubmitMsg{vmName: m.vmName, name: name}
+			return m, func() tea.Msg { return req }
+		}
+		m.errMsg = ""
+	}
+	var cmd tea.Cmd
+	m.nameInput, cmd = m.nameInput.Update(msg)
+	return m, cmd
+}
+
+func (m userDataImportModel) View() string {
+	title := formTitleStyle.Render(fmt.Sprintf("Save cloud-init of %s as a template", m.vmName))
+	content := title + "\n\n" +
+		fmt.Sprintf("  %s  %s\n", formActiveLabelStyle.Render("File name:"), m.nameInput.View()) +
+		formHintStyle.Render("  Saved in "+m.dir+", where Advanced Create and R find templates.") + "\n" +
+		formHintStyle.Render("  Secrets rendered into the user-data at launch are saved as they are.") + "\n\n" +
+		formHintStyle.Render("Enter: save  Esc: cancel")
+	if m.errMsg != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(stoppedClr).Render(m.errMsg)
+	}
+	box := modalStyle.Render(content)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,122 @@
+// view_settings.go - Multipass settings views (default bridged network)
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ─── Bridged Network ───────────────────────────────────────────────────────────
+
+// bridgeSettingsModel lists bridge candidates and sets local.bridged-network.
+type bridgeSettingsModel struct {
+	networks []NetworkInfo
+	current  string // current local.bridged-network ("" if unset)
+	driver   string // active multipass driver, used for bridging warnings
+	cursor   int
+
+	// Windows with the hyperv driver: offer to create an external switch
+	canCreateSwitch bool
+	width           int
+	height          int
+}
+
+func newBridgeSettingsModel(networks []NetworkInfo, current, driver string) bridgeSettingsModel {
+	m := bridgeSettingsModel{networks: networks, current: current, driver: driver, canCreateSwitch: hostCanCreateHyperVSwitch(driver)}
+	for i, n := range networks {
+		if n.Name == current {
+			m.cursor = i
+		}
+	}
+	return m
+}
+
+func (m bridgeSettingsModel) Update(msg tea.Msg) (bridgeSettingsModel, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc", "q":
+			return m, func() tea.Msg { return backToTableMsg{} }
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.networks)-1 {
+				m.cursor++
+			}
+		case "enter":
+			if m.cursor < len(m.networks) {
+				return m, setBridgedNetworkCmd(m.networks[m.cursor].Name)
+			}
+		case "c":
+			if m.canCreateSwitch && m.cursor < len(m.networks) && switchableAdapter(m.networks[m.cursor]) {
+				req := hyperVSwitchRequestMsg{adapter: m.networks[m.cursor].Name}
+				return m, func() tea.Msg { return req }
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m bridgeSettingsModel) View() string {
+	title := formTitleStyle.Render("Default Bridged Network")
+
+	current := m.current
+	if current == "" {
+		current = "not set"
+	}
+	currentLine := detailKeyStyle.Render("Current: ") + detailValStyle.Render(current)
+
+	if len(m.networks) == 0 {
+		content := title + "\n\n" + currentLine + "\n\n" +
+			tableEmptyStyle.Render("No bridgeable interfaces reported by multipass networks") + "\n\n" +
+			formHintStyle.Render("Esc: return")
+		box := modalStyle.Render(content)
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+	}
+
+	var rows []string
+	for i, n := range m.networks {
+		mark := "  "
+		if n.Name == m.current {
+			mark = lipgloss.NewStyle().Foreground(runningClr).Render("● ")
+		}
+		if bridgeWarning(m.driver, n) != "" {
+			mark = lipgloss.NewStyle().Foreground(suspendClr).Render("⚠ ")
+		}
+		line := fmt.Sprintf("%-14s %-20s %s", n.Name, classifyNetwork(n), truncateToRunes(n.Description, 40))
+		style := listItemStyle
+		prefix := "  "
+		if i == m.cursor {
+			style = listSelectedItemStyle
+			prefix = tableCursorStyle.Render("▎ ")
+		}
+		rows = append(rows, prefix+mark+style.Render(line))
+	}
+
+	var warnLine string
+	if m.cursor < len(m.networks) {
+		if warn := bridgeWarning(m.driver, m.networks[m.cursor]); warn != "" {
+			warnLine = "\n\n" + lipgloss.NewStyle().Foreground(suspendClr).Render("⚠ "+warn)
+		}
+	}
+
+	var switchLine string
+	hintText := "↑↓: select  Enter: set as default  Esc: return"
+	if m.canCreateSwitch {
+		if !hasExternalSwitch(m.networks) {
+			switchLine = "\n\n" + formHintStyle.Render("No external Hyper-V switch yet: select your network adapter and press c to create one.")
+		}
+		if m.cursor < len(m.networks) && switchableAdapter(m.networks[m.cursor]) {
+			hintText = "↑↓: select  Enter: set as default  c: create external switch  Esc: return"
+		}
+	}
+
+	hint := formHintStyle.Render(hintText)
+	content := title + "\n\n" + currentLine + "\n\n" + strings.Join(rows, "\n") + warnLine + switchLine + "\n\n" + hint
+	box := modalStyle.Render(content)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,14 @@
+package main
+
+import "testing"
+
+func TestBridgeSettingsCursorStartsOnCurrent(t *testing.T) {
+	networks := []NetworkInfo{{Name: "eth0"}, {Name: "wlan0"}, {Name: "br0"}}
+	m := newBridgeSettingsModel(networks, "wlan0", "")
+	if m.cursor != 1 {
+		t.Fatalf("expected cursor on current bridge, got %d", m.cursor)
+	}
+	if m := newBridgeSettingsModel(networks, "", ""); m.cursor != 0 {
+		t.Fatalf("expected cursor at top when unset, got %d", m.cursor)
+	}
+}
@@ -3,6 +3,7 @@ package main
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -162,6 +163,139 @@ func (m snapCreateModel) View() string {
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
 }
 
+// ─── Snapshot Clone Form ───────────────────────────────────────────────────────
+
+// snapCloneModel names the instances to clone from a snapshot.
+type snapCloneModel struct {
+	vmName     string
+	snapName   string
+	namesInput textinput.Model
+	exists     func(string) bool // whether an instance name is taken
+	policy     namingPolicy
+	errMsg     string
+	width      int
+	height     int
+}
+
+// snapCloneSubmitMsg asks root to clone vmName at snapName into names.
+type snapCloneSubmitMsg struct {
+	vmName   string
+	snapName string
+	names    []string
+}
+
+func newSnapCloneModel(vmName, snapName string, exists func(string) bool, policy namingPolicy, w, h int) snapCloneModel {
+	ni := textinput.New()
+	ni.Placeholder = vmName + "-1 " + vmName + "-2"
+	ni.CharLimit = 200
+	ni.SetValue(vmName + "-" + snapName)
+	ni.Focus()
+	return snapCloneModel{vmName: vmName, snapName: snapName, namesInput: ni, exists: exists, policy: policy, width: w, height: h}
+}
+
+func (m snapCloneModel) Init() tea.Cmd { return textinput.Blink }
+
+func (m snapCloneModel) Update(msg tea.Msg) (snapCloneModel, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			return m, func() tea.Msg { return backToTableMsg{} }
+		case "enter":
+			names, err := parseCloneNames(m.namesInput.Value(), m.exists, m.policy)
+			if err != nil {
+				m.errMsg = err.Error()
+				return m, nil
+			}
+			req := snapCloneSubmitMsg{vmName: m.vmName, snapName: m.snapName, names: names}
+			return m, func() tea.Msg { return req }
+		}
+		m.errMsg = ""
+	}
+	var cmd tea.Cmd
+	m.namesInput, cmd = m.namesInput.Update(msg)
+	return m, cmd
+}
+
+func (m snapCloneModel) View() string {
+	title := formTitleStyle.Render(fmt.Sprintf("Clone %s at %s", m.vmName, m.snapName))
+	content := title + "\n\n" +
+		fmt.Sprintf("  %s  %s\n", formActiveLabelStyle.Render("New names:"), m.namesInput.View()) +
+		formHintStyle.Render("  One instance per name, separated by spaces or commas.") + "\n" +
+		formHintStyle.Render("  "+m.vmName+" is reverted to the snapshot while cloning, then put back.") + "\n\n" +
+		formHintStyle.Render("Enter: clone  Esc: cancel")
+	if m.errMsg != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(stoppedClr).Render(m.errMsg)
+	}
+	box := modalStyle.Render(content)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// ─── Snapshot Compare Form ─────────────────────────────────────────────────────
+
+// snapCompareModel picks the paths to compare between a snapshot and now.
+type snapCompareModel struct {
+	vmName     string
+	snapName   string
+	pathsInput textinput.Model
+	errMsg     string
+	width      int
+	height     int
+}
+
+// snapCompareSubmitMsg asks root to compare paths in vmName at snapName with now.
+type snapCompareSubmitMsg struct {
+	vmName   string
+	snapName string
+	paths    []string
+}
+
+func newSnapCompareModel(vmName, snapName string, w, h int) snapCompareModel {
+	pi := textinput.New()
+	pi.Placeholder = "/etc /home"
+	pi.CharLimit = 400
+	pi.SetValue(strings.Join(defaultComparePaths, " "))
+	pi.Focus()
+	return snapCompareModel{vmName: vmName, snapName: snapName, pathsInput: pi, width: w, height: h}
+}
+
+func (m snapCompareModel) Init() tea.Cmd { return textinput.Blink }
+
+func (m snapCompareModel) Update(msg tea.Msg) (snapCompareModel, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			return m, func() tea.Msg { return backToTableMsg{} }
+		case "enter":
+			paths, err := parseComparePaths(m.pathsInput.Value())
+			if err != nil {
+				m.errMsg = err.Error()
+				return m, nil
+			}
+			req := snapCompareSubmitMsg{vmName: m.vmName, snapName: m.snapName, paths: paths}
+			return m, func() tea.Msg { return req }
+		}
+		m.errMsg = ""
+	}
+	var cmd tea.Cmd
+	m.pathsInput, cmd = m.pathsInput.Update(msg)
+	return m, cmd
+}
+
+func (m snapCompareModel) View() string {
+	title := formTitleStyle.Render(fmt.Sprintf("Compare %s at %s with now", m.vmName, m.snapName))
+	content := title + "\n\n" +
+		fmt.Sprintf("  %s  %s\n", formActiveLabelStyle.Render("Paths:"), m.pathsInput.View()) +
+		formHintStyle.Render("  Directories or files in the VM, separated by spaces.") + "\n" +
+		formHintStyle.Render("  Two temporary clones are started and checksummed. To clone "+m.vmName+" as it was,") + "\n" +
+		formHintStyle.Render("  it is restored to "+m.snapName+" and then put back from a holding snapshot.") + "\n\n" +
+		formHintStyle.Render("Enter: compare  Esc: cancel")
+	if m.errMsg != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(stoppedClr).Render(m.errMsg)
+	}
+	box := modalStyle.Render(content)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
+
 // ─── Snapshot Manager ──────────────────────────────────────────────────────────
 
 // snapTreeNode represents a snapshot in a tree structure.
@@ -173,9 +307,10 @@ type snapTreeNode struct {
 type snapManageModel struct {
 	vmName    string
 	snapshots []SnapshotInfo
-	tree      []snapTreeEntry // snapshots in tree display order
+	tree      []snapTreeEntry // snapshots in display order (tree, or oldest first)
+	byAge     bool            // list oldest first instead of as a tree
 	cursor    int
-	action    int // -1 = list, 0=revert, 1=delete, 2=cancel (when in actions mode)
+	action    int // -1 = list, 0=revert, 1=clone, 2=compare, 3=delete, 4=cancel (when in actions mode)
 	inActions bool
 	width     int
 	height    int
@@ -185,10 +320,52 @@ func newSnapManageModel(vmName string, w, h int) snapManageModel {
 	return snapManageModel{vmName: vmName, cursor: 0, action: -1, width: w, height: h}
 }
 
-// setSnapshots stores the snapshots and pre-computes the tree order.
+// snapshotStaleAfter is the age from which a snapshot's age is highlighted.
+const snapshotStaleAfter = 30 * 24 * time.Hour
+
+// setSnapshots stores the snapshots and pre-computes the display order.
 func (m *snapManageModel) setSnapshots(snaps []SnapshotInfo) {
 	m.snapshots = snaps
-	m.tree = buildSnapTree(snaps)
+	m.rebuild()
+}
+
+// rebuild recomputes the display order: the parent tree, or a flat list
+// sorted oldest first (undated snapshots last) when byAge is set.
+func (m *snapManageModel) rebuild() {
+	if !m.byAge {
+		m.tree = buildSnapTree(m.snapshots)
+		return
+	}
+	sorted := append([]SnapshotInfo(nil), m.snapshots...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].Created, sorted[j].Created
+		if a.IsZero() != b.IsZero() {
+			return b.IsZero()
+		}
+		return a.Before(b)
+	})
+	m.tree = make([]snapTreeEntry, len(sorted))
+	for i, s := range sorted {
+		m.tree[i] = snapTreeEntry{snap: s}
+	}
+}
+
+// hasDates reports whether creation times are known for any snapshot.
+func (m snapManageModel) hasDates() bool {
+	for _, s := range m.snapshots {
+		if !s.Created.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshotAge renders how long ago a snapshot was taken, or "--".
+func snapshotAge(created, now time.Time) string {
+	if created.IsZero() {
+		return "--"
+	}
+	return formatRemaining(now.Sub(created))
 }
 
 // snapTreeEntry is a flattened tree row with its display prefix and depth.
@@ -245,6 +422,24 @@ func buildSnapTree(snapshots []SnapshotInfo) []snapTreeEntry {
 	return entries
 }
 
+// snapRestoreRequestMsg asks root to confirm and then restore a snapshot.
+type snapRestoreRequestMsg struct {
+	vmName   string
+	snapName string
+}
+
+// snapCloneRequestMsg asks root to open the clone form for a snapshot.
+type snapCloneRequestMsg struct {
+	vmName   string
+	snapName string
+}
+
+// snapCompareRequestMsg asks root to open the compare form for a snapshot.
+type snapCompareRequestMsg struct {
+	vmName   string
+	snapName string
+}
+
 func (m snapManageModel) Update(msg tea.Msg) (snapManageModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -262,6 +457,12 @@ func (m snapManageModel) Update(msg tea.Msg) (snapManageModel, tea.Cmd) {
 			if m.cursor < len(m.tree)-1 {
 				m.cursor++
 			}
+		case "a":
+			if m.hasDates() {
+				m.byAge = !m.byAge
+				m.rebuild()
+				m.cursor = 0
+			}
 		case "enter":
 			if len(m.tree) > 0 {
 				m.inActions = true
@@ -282,18 +483,25 @@ func (m snapManageModel) updateActions(msg tea.KeyMsg) (snapManageModel, tea.Cmd
 			m.action--
 		}
 	case "right", "l":
-		if m.action < 2 {
+		if m.action < 4 {
 			m.action++
 		}
 	case "enter":
 		snap := m.tree[m.cursor].snap
 		m.inActions = false
 		switch m.action {
-		case 0: // revert
-			return m, restoreSnapshotCmd(m.vmName, snap.Name)
-		case 1: // delete
+		case 0: // revert (root asks for typed confirmation first)
+			vmName := m.vmName
+			return m, func() tea.Msg { return snapRestoreRequestMsg{vmName: vmName, snapName: snap.Name} }
+		case 1: // clone into new instances
+			vmName := m.vmName
+			return m, func() tea.Msg { return snapCloneRequestMsg{vmName: vmName, snapName: snap.Name} }
+		case 2: // compare with the current state
+			vmName := m.vmName
+			return m, func() tea.Msg { return snapCompareRequestMsg{vmName: vmName, snapName: snap.Name} }
+		case 3: // delete
 			return m, deleteSnapshotCmd(m.vmName, snap.Name)
-		case 2: // cancel
+		case 4: // cancel
 			return m, nil
 		}
 	}
@@ -334,10 +542,21 @@ func (m snapManageModel) View() string {
 	commentColW := avail - nameColW - 1 // -1 for divider
 	showComment := commentColW >= 8
 
+	// Age column between name and comment when creation times are known
+	ageColW := 0
+	if showComment && m.hasDates() && commentColW-9 >= 8 {
+		ageColW = 8
+		commentColW -= ageColW + 1
+	}
+	now := time.Now()
+
 	var headerRow string
 	if showComment {
-		headerRow = " " + tableHeaderStyle.Width(nameColW).Render("Snapshot") +
-			headerDiv + tableHeaderStyle.Width(commentColW).Render("Comment")
+		headerRow = " " + tableHeaderStyle.Width(nameColW).Render("Snapshot") + headerDiv
+		if ageColW > 0 {
+			headerRow += tableHeaderStyle.Width(ageColW).Render("Age") + headerDiv
+		}
+		headerRow += tableHeaderStyle.Width(commentColW).Render("Comment")
 	} else {
 		headerRow = " " + tableHeaderStyle.Width(avail).Render("Snapshot")
 		nameColW = avail
@@ -347,9 +566,11 @@ func (m snapManageModel) View() string {
 	dashStyle := lipgloss.NewStyle().Foreground(dimmed)
 	var sepRow string
 	if showComment {
-		sepRow = " " + dashStyle.Render(strings.Repeat("─", nameColW)) +
-			tableColDivStyle.Render("┼") +
-			dashStyle.Render(strings.Repeat("─", commentColW))
+		sepRow = " " + dashStyle.Render(strings.Repeat("─", nameColW)) + tableColDivStyle.Render("┼")
+		if ageColW > 0 {
+			sepRow += dashStyle.Render(strings.Repeat("─", ageColW)) + tableColDivStyle.Render("┼")
+		}
+		sepRow += dashStyle.Render(strings.Repeat("─", commentColW))
 	} else {
 		sepRow = " " + dashStyle.Render(strings.Repeat("─", avail))
 	}
@@ -412,7 +633,15 @@ func (m snapManageModel) View() string {
 					comment = truncateToRunes(comment, commentColW-2)
 				}
 			}
-			row = cursor + nameContent + div + cellStyle(commentColW).Render(comment)
+			row = cursor + nameContent + div
+			if ageColW > 0 {
+				ageStyle := cellStyle(ageColW)
+				if c := entry.snap.Created; !c.IsZero() && now.Sub(c) >= snapshotStaleAfter {
+					ageStyle = ageStyle.Foreground(suspendClr)
+				}
+				row += ageStyle.Render(snapshotAge(entry.snap.Created, now)) + div
+			}
+			row += cellStyle(commentColW).Render(comment)
 		} else {
 			row = cursor + nameContent
 		}
@@ -424,7 +653,7 @@ func (m snapManageModel) View() string {
 	// ── Actions overlay ──
 	var actionsLine string
 	if m.inActions {
-		actions := []string{"Revert", "Delete", "Cancel"}
+		actions := []string{"Revert", "Clone", "Compare", "Delete", "Cancel"}
 		var buttons []string
 		for i, a := range actions {
 			style := formButtonStyle
@@ -438,13 +667,143 @@ func (m snapManageModel) View() string {
 
 	// ── Footer hints ──
 	hint := footerKeyStyle.Render("↑↓") + " " + footerDescStyle.Render("navigate") + "  " +
-		footerKeyStyle.Render("Enter") + " " + footerDescStyle.Render("actions") + "  " +
-		footerKeyStyle.Render("Esc") + " " + footerDescStyle.Render("return")
+		footerKeyStyle.Render("Enter") + " " + footerDescStyle.Render("actions") + "  "
+	if m.hasDates() {
+		order := "oldest first"
+		if m.byAge {
+			order = "tree"
+		}
+		hint += footerKeyStyle.Render("a") + " " + footerDescStyle.Render(order) + "  "
+	}
+	hint += footerKeyStyle.Render("Esc") + " " + footerDescStyle.Render("return")
+
+	// Details of the selected snapshot
+	var detail string
+	if m.cursor < len(tree) {
+		if c := tree[m.cursor].snap.Created; !c.IsZero() {
+			detail = "\n" + detailKeyStyle.Render(" Created: ") +
+				detailValStyle.Render(c.Local().Format("2006-01-02 15:04")+" ("+snapshotAge(c, now)+" ago)")
+		}
+	}
 
 	content := title + "\n" +
-		tableContent +
+		tableContent + detail +
 		actionsLine + "\n\n" + hint
 
 	box := modalStyle.Render(content)
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
 }
+
+// ─── Snapshot Search ───────────────────────────────────────────────────────────
+
+// snapSearchModel searches snapshots across all instances as you type.
+type snapSearchModel struct {
+	input   textinput.Model
+	all     []SnapshotInfo
+	results []SnapshotInfo
+	errMsg  string
+	cursor  int
+	width   int
+	height  int
+}
+
+// snapSearchOpenMsg opens the snapshot manager for a search result's VM.
+type snapSearchOpenMsg struct {
+	vmName string
+}
+
+func newSnapSearchModel(snaps []SnapshotInfo, w, h int) snapSearchModel {
+	in := textinput.New()
+	in.Placeholder = "postgres vm:db after:2026-01-01 before:2026-02-01"
+	in.CharLimit = 120
+	in.Focus()
+	return snapSearchModel{input: in, all: snaps, results: snaps, width: w, height: h}
+}
+
+func (m snapSearchModel) Init() tea.Cmd { return textinput.Blink }
+
+func (m snapSearchModel) Update(msg tea.Msg) (snapSearchModel, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			return m, func() tea.Msg { return backToTableMsg{} }
+		case "up":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil
+		case "down":
+			if m.cursor < len(m.results)-1 {
+				m.cursor++
+			}
+			return m, nil
+		case "enter":
+			if m.cursor < len(m.results) {
+				vmName := m.results[m.cursor].Instance
+				return m, func() tea.Msg { return snapSearchOpenMsg{vmName: vmName} }
+			}
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	before := m.input.Value()
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != before {
+		m.search()
+	}
+	return m, cmd
+}
+
+// search re-filters the results from the current query.
+func (m *snapSearchModel) search() {
+	q, err := parseSnapshotQuery(m.input.Value())
+	if err != nil {
+		m.errMsg = err.Error()
+		return
+	}
+	m.errMsg = ""
+	m.results = filterSnapshots(m.all, q)
+	m.cursor = 0
+}
+
+func (m snapSearchModel) View() string {
+	title := modalTitleStyle.Render("Search Snapshots")
+
+	// Leave room for the title, input, hints and modal chrome.
+	visible := max(3, m.height-14)
+	start := 0
+	if m.cursor >= visible {
+		start = m.cursor - visible + 1
+	}
+
+	var rows []string
+	if len(m.results) == 0 {
+		rows = append(rows, tableEmptyStyle.Render("No matching snapshots"))
+	}
+	for i := start; i < len(m.results) && i < start+visible; i++ {
+		s := m.results[i]
+		created := "--"
+		if !s.Created.IsZero() {
+			created = s.Created.Local().Format("2006-01-02 15:04")
+		}
+		line := fmt.Sprintf("%-16s %-20s %-16s %s",
+			truncateToRunes(s.Instance, 16), truncateToRunes(s.Name, 20), created, truncateToRunes(s.Comment, 30))
+		style := listItemStyle
+		prefix := "  "
+		if i == m.cursor {
+			style = listSelectedItemStyle
+			prefix = tableCursorStyle.Render("▎ ")
+		}
+		rows = append(rows, prefix+style.Render(line))
+	}
+
+	status := formHintStyle.Render(fmt.Sprintf("%d of %d snapshots", len(m.results), len(m.all)))
+	if m.errMsg != "" {
+		status = lipgloss.NewStyle().Foreground(stoppedClr).Render(m.errMsg)
+	}
+	content := title + "\n\n" + "  " + m.input.View() + "\n" + status + "\n\n" +
+		strings.Join(rows, "\n") + "\n\n" +
+		formHintStyle.Render("Type to filter  ↑↓: select  Enter: manage VM's snapshots  Esc: return")
+	box := modalStyle.Render(content)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSnapManageSortByAge(t *testing.T) {
+	base := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	m := newSnapManageModel("db", 100, 40)
+	m.setSnapshots([]SnapshotInfo{
+		{Instance: "db", Name: "root", Created: base},
+		{Instance: "db", Name: "child", Parent: "root", Created: base.Add(48 * time.Hour)},
+		{Instance: "db", Name: "older", Created: base.Add(-24 * time.Hour)},
+		{Instance: "db", Name: "undated"},
+	})
+	if m.tree[1].snap.Name != "child" || m.tree[1].depth != 1 {
+		t.Fatalf("expected tree order by default, got %+v", m.tree)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	var got []string
+	for _, e := range m.tree {
+		got = append(got, e.snap.Name)
+		if e.prefix != "" {
+			t.Fatalf("age order should be flat, got prefix %q", e.prefix)
+		}
+	}
+	want := []string{"older", "root", "child", "undated"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("age order = %v, want %v", got, want)
+		}
+	}
+
+	if age := snapshotAge(base, base.Add(50*time.Hour)); age != "2d2h" {
+		t.Fatalf("snapshotAge = %q", age)
+	}
+	if age := snapshotAge(time.Time{}, base); age != "--" {
+		t.Fatalf("snapshotAge(zero) = %q", age)
+	}
+}
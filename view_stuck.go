@@ -0,0 +1,133 @@
+// view_stuck.go - Recovery menu for an instance stuck starting or unknown
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Choices of the recovery menu, in the order shown.
+const (
+	stuckForceStop = iota
+	stuckTrash
+	stuckDaemon
+	stuckCancel
+)
+
+// stuckModel offers ways out for a VM stuck in a transient state.
+type stuckModel struct {
+	vmName    string
+	state     string
+	stuckFor  time.Duration
+	forceStop bool // the installed multipass supports stop --force
+	cursor    int
+	status    string
+	width     int
+	height    int
+}
+
+func newStuckModel(vmName, state string, stuckFor time.Duration, forceStop bool, w, h int) stuckModel {
+	m := stuckModel{vmName: vmName, state: state, stuckFor: stuckFor, forceStop: forceStop, width: w, height: h}
+	if !forceStop {
+		m.cursor = stuckTrash
+	}
+	return m
+}
+
+// stuckActionMsg asks the root model to run a recovery action on a VM.
+type stuckActionMsg struct {
+	vmName string
+	action int
+}
+
+func (m stuckModel) enabled(choice int) bool {
+	return choice != stuckForceStop || m.forceStop
+}
+
+func (m stuckModel) Update(msg tea.Msg) (stuckModel, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "esc", "q", "K":
+		return m, func() tea.Msg { return backToTableMsg{} }
+	case "up", "k":
+		for c := m.cursor - 1; c >= 0; c-- {
+			if m.enabled(c) {
+				m.cursor = c
+				break
+			}
+		}
+		m.status = ""
+	case "down", "j", "tab":
+		m.cursor = min(m.cursor+1, stuckCancel)
+		m.status = ""
+	case "enter":
+		switch m.cursor {
+		case stuckCancel:
+			return m, func() tea.Msg { return backToTableMsg{} }
+		case stuckDaemon:
+			_, command := daemonRestartHint(runtime.GOOS)
+			if err := clipboard.WriteAll(command); err != nil {
+				m.status = "✗ Copy failed: " + err.Error()
+			} else {
+				m.status = "✓ Copied to the clipboard"
+			}
+			return m, nil
+		}
+		msg := stuckActionMsg{vmName: m.vmName, action: m.cursor}
+		return m, func() tea.Msg { return msg }
+	}
+	return m, nil
+}
+
+func (m stuckModel) View() string {
+	title := formTitleStyle.Render(m.vmName + " looks stuck")
+	intro := fmt.Sprintf("%s has been %s for %s. multipass may be waiting on a VM that\nwill not boot, or the daemon may have lost track of it.",
+		m.vmName, m.state, formatRemaining(m.stuckFor))
+
+	how, command := daemonRestartHint(runtime.GOOS)
+	choices := []struct{ label, detail string }{
+		{"Force stop", "Power it off without a clean shutdown; ] starts it again"},
+		{"Move to trash", "Delete it; r recovers it from the trash"},
+		{"Restart multipassd", "Run " + how + ": " + command + "  (Enter copies it)"},
+		{"Cancel", ""},
+	}
+	if !m.forceStop {
+		choices[stuckForceStop].detail = fmt.Sprintf("Needs multipass %s or newer", forceStopMinVersion)
+	}
+	disabled := lipgloss.NewStyle().Foreground(subtle)
+	var lines []string
+	for i, c := range choices {
+		label := formButtonStyle.Render(" " + c.label + " ")
+		switch {
+		case !m.enabled(i):
+			label = disabled.Render("  " + c.label + " ")
+		case i == m.cursor:
+			label = formActiveButtonStyle.Render(" " + c.label + " ")
+		}
+		line := " " + label
+		if c.detail != "" {
+			line += "  " + formHintStyle.Render(c.detail)
+		}
+		lines = append(lines, line)
+	}
+
+	content := title + "\n\n" + modalTextStyle.Render(intro) + "\n\n" + strings.Join(lines, "\n") + "\n\n"
+	if m.status != "" {
+		clr := runningClr
+		if strings.HasPrefix(m.status, "✗") {
+			clr = stoppedClr
+		}
+		content += lipgloss.NewStyle().Foreground(clr).Render(m.status) + "\n"
+	}
+	content += formHintStyle.Render("↑↓: choose  Enter: run  Esc: close")
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalStyle.Render(content))
+}
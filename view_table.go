@@ -3,6 +3,8 @@ package main
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -23,12 +25,31 @@ type tableColumn struct {
 
 // busyInfo tracks an in-flight inline operation for a VM.
 type busyInfo struct {
-	operation string    // "Stopping", "Starting", "Suspending", "Recovering"
+	operation string    // "Stopping", "Starting", "Suspending", "Recovering", "Repairing network"
 	startTime time.Time // when the operation began
+
+	// Real launch progress, when multipass reports it (see launchProgressMsg).
+	phase    launchPhase
+	hasPhase bool
+
+	// Latest output line of a streamed operation (see repairProgressMsg)
+	detail string
 }
 
-// phaseMessage returns a context-aware status message based on elapsed time.
+// phaseMessage returns a context-aware status message based on elapsed time,
+// or the reported launch phase when one is known.
 func (b busyInfo) phaseMessage() string {
+	if b.detail != "" {
+		return b.detail
+	}
+	if b.hasPhase {
+		msg := fmt.Sprintf("[%d/%d] %s…", b.phase.Step+1, len(launchPhaseLabels), b.phase.Label)
+		if b.phase.Percent >= 0 {
+			msg += fmt.Sprintf(" %d%%", b.phase.Percent)
+		}
+		return msg
+	}
+
 	elapsed := time.Since(b.startTime)
 
 	// Creating takes much longer, use different thresholds
@@ -42,8 +63,10 @@ func (b busyInfo) phaseMessage() string {
 			return "Configuring VM…"
 		case elapsed < 50*time.Second:
 			return "Almost ready…"
-		default:
+		case elapsed < 2*time.Minute:
 			return "Hang tight…"
+		default:
+			return b.stillWorking(elapsed)
 		}
 	}
 
@@ -54,9 +77,22 @@ func (b busyInfo) phaseMessage() string {
 		return "Working on it…"
 	case elapsed < 15*time.Second:
 		return "Almost there…"
-	default:
+	case elapsed < 30*time.Second:
 		return "Hang tight…"
+	default:
+		return b.stillWorking(elapsed)
+	}
+}
+
+// stillWorking is the message for an operation that is taking unusually
+// long, with how long is left before its command times out.
+func (b busyInfo) stillWorking(elapsed time.Duration) string {
+	limit := multipassTimeouts[operationClass(b.operation)]
+	if limit <= 0 {
+		return "Still working…"
 	}
+	left := max(limit-elapsed, 0).Round(time.Second)
+	return "Still working… (gives up in " + left.String() + ")"
 }
 
 // elapsed returns the seconds since the operation started.
@@ -67,7 +103,17 @@ func (b busyInfo) elapsed() string {
 
 // progressFraction returns a fake progress (0.0–0.95) using a log curve.
 // It approaches but never reaches 1.0 until the real operation completes.
+// Once a launch phase is reported, progress is stepped by phase instead.
 func (b busyInfo) progressFraction() float64 {
+	if b.hasPhase {
+		steps := float64(len(launchPhaseLabels))
+		p := float64(b.phase.Step) / steps
+		if b.phase.Percent >= 0 {
+			p += float64(b.phase.Percent) / 100 / steps
+		}
+		return min(p, 0.95)
+	}
+
 	secs := time.Since(b.startTime).Seconds()
 	// Creating takes longer, use a slower curve
 	divisor := 5.0
@@ -83,14 +129,24 @@ func (b busyInfo) progressFraction() float64 {
 
 // toast represents a brief auto-dismissing notification.
 type toast struct {
-	message string
-	style   string // "success", "error", "info"
-	created time.Time
+	message  string
+	style    string // "success", "error", "info"
+	created  time.Time
+	duration time.Duration
 }
 
 // toastDuration is how long a toast stays visible.
 const toastDuration = 4 * time.Second
 
+// undoWindow is how long a soft delete can be undone with "u".
+const undoWindow = 10 * time.Second
+
+// pendingUndo tracks the most recent soft delete that can still be undone.
+type pendingUndo struct {
+	vmName  string
+	expires time.Time
+}
+
 type tableModel struct {
 	vms         []vmData
 	filteredVMs []vmData
@@ -107,6 +163,12 @@ type tableModel struct {
 	sortColumn    int
 	sortAscending bool
 
+	// Grouped mode (see group.go): the cursor moves over rows, which hold
+	// group headers and the VMs of expanded groups
+	groupBy   string
+	collapsed map[string]bool
+	rows      []tableRow
+
 	columns []tableColumn
 
 	// Inline operation tracking
@@ -115,21 +177,84 @@ type tableModel struct {
 
 	// Auto-refresh
 	lastRefresh time.Time
+	refreshErr  string // why the last refresh failed; the shown data is stale
 
 	// Toast notifications
 	toasts []toast
+
+	// Undo for the last soft delete (nil when nothing can be undone)
+	undo *pendingUndo
+
+	// Per-VM metadata from passgo's state file, shared with rootModel
+	meta map[string]vmMeta
+
+	// VMs whose disk is above the alert threshold (see checkDiskUsage)
+	diskFull map[string]bool
+
+	// VMs stuck in a transient state and since when (see checkStuck)
+	stuck map[string]time.Time
+
+	// Startup health summary under the title bar (see health.go); "" hides it
+	banner      string
+	bannerLevel int
+
+	// VMs flagged idle by the idle policy (see sampleIdle)
+	idle map[string]bool
+
+	// Whether running VMs answered on the reachability port (see
+	// reachability.go); shown in the Net column when it is enabled
+	reach map[string]bool
+
+	// Who launched each VM, when owners are tracked (see owners.go); shown
+	// in the Owner column
+	owners map[string]string
+
+	// Summary of a bulk operation running in the background ("" when idle)
+	backgroundStatus string
+
+	// Read-only mode: the footer lists only non-mutating shortcuts
+	readOnly bool
+	// A `passgo agent` runs the automations; shown in the status line
+	agent bool
+	// Active multipass driver ("" until known); the footer hides the
+	// shortcuts it does not support (see driver.go)
+	driver string
 }
 
 // addToast adds a toast notification and returns a command to dismiss it later.
 func (m *tableModel) addToast(message string, style string) tea.Cmd {
-	t := toast{message: message, style: style, created: time.Now()}
+	return m.addToastFor(message, style, toastDuration)
+}
+
+// addToastFor is addToast with a custom display duration.
+func (m *tableModel) addToastFor(message string, style string, d time.Duration) tea.Cmd {
+	t := toast{message: message, style: style, created: time.Now(), duration: d}
 	m.toasts = append(m.toasts, t)
 	created := t.created
-	return tea.Tick(toastDuration, func(_ time.Time) tea.Msg {
+	return tea.Tick(d, func(_ time.Time) tea.Msg {
 		return toastExpireMsg{created: created}
 	})
 }
 
+// setUndo records a soft-deleted VM and shows the undo toast.
+func (m *tableModel) setUndo(vmName string) tea.Cmd {
+	m.undo = &pendingUndo{vmName: vmName, expires: time.Now().Add(undoWindow)}
+	return m.addToastFor(
+		fmt.Sprintf("🗑 %s deleted — press u to undo (%ds)", vmName, int(undoWindow.Seconds())),
+		"info", undoWindow)
+}
+
+// takeUndo returns the VM that can still be recovered and clears the undo.
+func (m *tableModel) takeUndo() (string, bool) {
+	if m.undo == nil || time.Now().After(m.undo.expires) {
+		m.undo = nil
+		return "", false
+	}
+	name := m.undo.vmName
+	m.undo = nil
+	return name, true
+}
+
 // toastExpireMsg signals that a toast should be dismissed.
 type toastExpireMsg struct {
 	created time.Time
@@ -169,14 +294,108 @@ func newTableModel() tableModel {
 
 // ─── Data ──────────────────────────────────────────────────────────────────────
 
+// setVMs merges a freshly fetched VM list into the table. Unchanged rows are
+// kept as-is and the cursor stays on the same VM (and screen row) whenever
+// that VM is still present, so background refreshes don't make it jump.
 func (m *tableModel) setVMs(vms []vmData) {
-	m.vms = vms
+	merged, changed := mergeVMList(m.vms, vms, m.busyVMs)
+	if !changed {
+		return
+	}
+
+	selectedName := ""
+	if vm, ok := m.selectedVM(); ok {
+		selectedName = vm.Name
+	}
+	selectedGroup, onHeader := m.selectedGroup()
+	screenRow := m.cursor - m.offset
+
+	m.vms = merged
 	m.applyFilterAndSort()
-	if m.cursor >= len(m.filteredVMs) {
-		m.cursor = max(0, len(m.filteredVMs)-1)
+
+	if (selectedName != "" && m.selectVMByName(selectedName)) || (onHeader && m.selectGroup(selectedGroup)) {
+		// Keep the selected row at the same on-screen position when possible.
+		m.offset = max(0, m.cursor-screenRow)
+		if maxOffset := max(0, m.rowCount()-m.visibleRows()); m.offset > maxOffset {
+			m.offset = maxOffset
+		}
+		return
+	}
+	if m.cursor >= m.rowCount() {
+		m.cursor = max(0, m.rowCount()-1)
+	}
+	if m.offset > m.cursor {
+		m.offset = m.cursor
 	}
 }
 
+// selectVMByName moves the cursor to the named VM. Returns false if the VM
+// isn't in the filtered list, or is in a collapsed group.
+func (m *tableModel) selectVMByName(name string) bool {
+	for i := range m.rowCount() {
+		if vm, ok := m.rowVM(i); ok && vm.info.Name == name {
+			m.cursor = i
+			return true
+		}
+	}
+	return false
+}
+
+// mergeVMList diffs next against prev and returns the list to display along
+// with whether anything changed. Existing VMs keep their position, new VMs are
+// appended, and rows for VMs with an in-flight operation (e.g. the "Creating"
+// placeholder) are retained until multipass reports them.
+func mergeVMList(prev, next []vmData, busy map[string]busyInfo) ([]vmData, bool) {
+	nextByName := make(map[string]vmData, len(next))
+	for _, vm := range next {
+		nextByName[vm.info.Name] = vm
+	}
+
+	merged := make([]vmData, 0, len(next))
+	seen := make(map[string]struct{}, len(next))
+	changed := false
+	for _, old := range prev {
+		name := old.info.Name
+		updated, ok := nextByName[name]
+		if !ok {
+			if _, isBusy := busy[name]; isBusy {
+				merged = append(merged, old)
+				seen[name] = struct{}{}
+				continue
+			}
+			changed = true
+			continue
+		}
+		if !vmDataEqual(old, updated) {
+			changed = true
+			merged = append(merged, updated)
+		} else {
+			merged = append(merged, old)
+		}
+		seen[name] = struct{}{}
+	}
+	for _, vm := range next {
+		if _, ok := seen[vm.info.Name]; ok {
+			continue
+		}
+		changed = true
+		merged = append(merged, vm)
+		seen[vm.info.Name] = struct{}{}
+	}
+	return merged, changed
+}
+
+// vmDataEqual reports whether two rows would render identically.
+func vmDataEqual(a, b vmData) bool {
+	if a.info != b.info {
+		return false
+	}
+	if (a.err == nil) != (b.err == nil) {
+		return false
+	}
+	return a.err == nil || a.err.Error() == b.err.Error()
+}
+
 func (m *tableModel) applyFilterAndSort() {
 	filter := strings.ToLower(m.filterText)
 	m.filteredVMs = nil
@@ -185,12 +404,73 @@ func (m *tableModel) applyFilterAndSort() {
 			m.filteredVMs = append(m.filteredVMs, vm)
 		}
 	}
-	sortVMs(m.filteredVMs, m.sortColumn, m.sortAscending)
+	switch m.sortColumn {
+	case m.columnIndex(reachColumn.title):
+		m.sortByReach()
+	case m.columnIndex(ownerColumn.title):
+		m.sortByOwner()
+	default:
+		sortVMs(m.filteredVMs, m.sortColumn, m.sortAscending)
+	}
+
+	// Deleted VMs form their own section at the bottom of the table.
+	sort.SliceStable(m.filteredVMs, func(i, j int) bool {
+		return m.filteredVMs[i].info.State != "Deleted" && m.filteredVMs[j].info.State == "Deleted"
+	})
+	m.buildRows()
+}
+
+// deletedSectionStart returns the index of the first deleted VM when the
+// table has both live and deleted VMs, or -1 if no divider is needed. A
+// grouped table has a Deleted group instead.
+func (m tableModel) deletedSectionStart() int {
+	if m.groupBy != groupNone {
+		return -1
+	}
+	for i, vm := range m.filteredVMs {
+		if vm.info.State == "Deleted" {
+			if i == 0 {
+				return -1
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// visibleWindow returns the range of filteredVMs rows to draw and the row
+// index the "Deleted" divider is drawn above (-1 when not shown). The divider
+// takes one line, so it is only shown when it doesn't push the cursor off.
+func (m tableModel) visibleWindow() (start, end, divider int) {
+	visible := m.visibleRows()
+	start = m.offset
+	end = min(start+visible, m.rowCount())
+	divider = m.deletedSectionStart()
+	if divider <= start || divider >= end {
+		return start, end, -1
+	}
+	if end-start == visible {
+		if m.cursor >= end-1 {
+			return start, end, -1
+		}
+		end--
+	}
+	return start, end, divider
 }
 
 func (m *tableModel) selectedVM() (VMInfo, bool) {
-	if m.cursor >= 0 && m.cursor < len(m.filteredVMs) {
-		return m.filteredVMs[m.cursor].info, true
+	if vm, ok := m.rowVM(m.cursor); ok {
+		return vm.info, true
+	}
+	return VMInfo{}, false
+}
+
+// vmByName returns the row for the named VM, if it is in the list.
+func (m *tableModel) vmByName(name string) (VMInfo, bool) {
+	for _, vm := range m.vms {
+		if vm.info.Name == name {
+			return vm.info, true
+		}
 	}
 	return VMInfo{}, false
 }
@@ -259,7 +539,7 @@ func (m tableModel) Update(msg tea.Msg) (tableModel, tea.Cmd) {
 				}
 			}
 		case tea.MouseWheelDown:
-			if m.cursor < len(m.filteredVMs)-1 {
+			if m.cursor < m.rowCount()-1 {
 				m.cursor++
 				visible := m.visibleRows()
 				if m.cursor >= m.offset+visible {
@@ -275,8 +555,15 @@ func (m tableModel) Update(msg tea.Msg) (tableModel, tea.Cmd) {
 			}
 			row := msg.Y - headerLines
 			if row >= 0 {
-				idx := m.offset + row
-				if idx >= 0 && idx < len(m.filteredVMs) {
+				start, _, divider := m.visibleWindow()
+				idx := start + row
+				if divider >= 0 && idx >= divider {
+					if idx == divider {
+						return m, nil // clicked the "Deleted" divider
+					}
+					idx--
+				}
+				if idx >= 0 && idx < m.rowCount() {
 					m.cursor = idx
 				}
 			}
@@ -299,8 +586,8 @@ func (m tableModel) Update(msg tea.Msg) (tableModel, tea.Cmd) {
 				m.filterInput, cmd = m.filterInput.Update(msg)
 				m.filterText = m.filterInput.Value()
 				m.applyFilterAndSort()
-				if m.cursor >= len(m.filteredVMs) {
-					m.cursor = max(0, len(m.filteredVMs)-1)
+				if m.cursor >= m.rowCount() {
+					m.cursor = max(0, m.rowCount()-1)
 				}
 				return m, cmd
 			}
@@ -316,7 +603,7 @@ func (m tableModel) Update(msg tea.Msg) (tableModel, tea.Cmd) {
 				}
 			}
 		case "down", "j":
-			if m.cursor < len(m.filteredVMs)-1 {
+			if m.cursor < m.rowCount()-1 {
 				m.cursor++
 				if m.cursor >= m.offset+visible {
 					m.offset = m.cursor - visible + 1
@@ -326,7 +613,7 @@ func (m tableModel) Update(msg tea.Msg) (tableModel, tea.Cmd) {
 			m.cursor = 0
 			m.offset = 0
 		case "end", "G":
-			m.cursor = max(0, len(m.filteredVMs)-1)
+			m.cursor = max(0, m.rowCount()-1)
 			if m.cursor >= visible {
 				m.offset = m.cursor - visible + 1
 			}
@@ -336,7 +623,7 @@ func (m tableModel) Update(msg tea.Msg) (tableModel, tea.Cmd) {
 				m.offset = m.cursor
 			}
 		case "pgdown":
-			m.cursor = min(len(m.filteredVMs)-1, m.cursor+visible)
+			m.cursor = min(m.rowCount()-1, m.cursor+visible)
 			if m.cursor >= m.offset+visible {
 				m.offset = m.cursor - visible + 1
 			}
@@ -355,6 +642,9 @@ func (m tableModel) visibleRows() int {
 	if m.filterVisible {
 		used++
 	}
+	if m.banner != "" {
+		used++
+	}
 	// Toast lines
 	used += len(m.toasts)
 	// Footer lines vary by width
@@ -368,9 +658,22 @@ func (m tableModel) visibleRows() int {
 	return max(1, m.height-used)
 }
 
+// staleAge reports whether the last refresh failed and, if so, how old the
+// data on screen is.
+func (m tableModel) staleAge() (string, bool) {
+	if m.refreshErr == "" || m.lastRefresh.IsZero() {
+		return "", false
+	}
+	return time.Since(m.lastRefresh).Truncate(time.Second).String(), true
+}
+
 // ─── View ──────────────────────────────────────────────────────────────────────
 
 func (m tableModel) View() string {
+	if accessibleMode {
+		return m.viewAccessible()
+	}
+
 	var b strings.Builder
 
 	// ── Title bar (full-width accent background, never wraps) ──
@@ -380,7 +683,10 @@ func (m tableModel) View() string {
 	if vmCount != totalCount {
 		countText = fmt.Sprintf(" %d/%d VMs", vmCount, totalCount)
 	}
-	liveIndicator := " ● LIVE"
+	liveIndicator, liveStyle := " ● LIVE", titleLiveStyle
+	if age, stale := m.staleAge(); stale {
+		liveIndicator, liveStyle = " ◌ STALE "+age, titleStaleStyle
+	}
 	themeName := " ◈ " + currentTheme().Name + " "
 
 	w := m.width
@@ -410,7 +716,7 @@ func (m tableModel) View() string {
 		leftWidth += countWidth
 	}
 	if showLive {
-		leftParts += titleLiveStyle.Render(liveIndicator)
+		leftParts += liveStyle.Render(liveIndicator)
 		leftWidth += liveWidth
 	}
 
@@ -433,6 +739,11 @@ func (m tableModel) View() string {
 	}
 	b.WriteString(titleText + "\n")
 
+	// ── Startup banner ──
+	if m.banner != "" {
+		b.WriteString(m.renderBanner(w) + "\n")
+	}
+
 	// ── Filter bar ──
 	if m.filterVisible {
 		if m.filterFocused {
@@ -501,9 +812,16 @@ func (m tableModel) View() string {
 	if len(m.filteredVMs) == 0 {
 		rows = append(rows, tableEmptyStyle.Render(" No VMs found"))
 	} else {
-		end := min(m.offset+visible, len(m.filteredVMs))
-		for i := m.offset; i < end; i++ {
-			vm := m.filteredVMs[i]
+		start, end, divider := m.visibleWindow()
+		for i := start; i < end; i++ {
+			if i == divider {
+				rows = append(rows, m.renderSectionDivider("Deleted", cols))
+			}
+			vm, ok := m.rowVM(i)
+			if !ok {
+				rows = append(rows, m.renderGroupHeader(m.rows[i].group, cols, i == m.cursor))
+				continue
+			}
 			selected := i == m.cursor
 			rows = append(rows, m.renderRow(vm, cols, selected, div))
 		}
@@ -547,6 +865,138 @@ func (m tableModel) View() string {
 	return b.String()
 }
 
+// renderBanner draws the startup banner, cut to width w.
+func (m tableModel) renderBanner(w int) string {
+	style := formHintStyle
+	switch m.bannerLevel {
+	case bannerWarn:
+		style = lipgloss.NewStyle().Foreground(suspendClr)
+	case bannerError:
+		style = lipgloss.NewStyle().Foreground(stoppedClr).Bold(true)
+	}
+	return style.Render(truncateToRunes(" "+m.banner, max(10, w-1)))
+}
+
+// viewAccessible renders the table as plain lines for screen readers: one
+// sentence per VM, an explicit announcement of the selected row, and no
+// box drawing or animation.
+func (m tableModel) viewAccessible() string {
+	var b strings.Builder
+
+	dir := "ascending"
+	if !m.sortAscending {
+		dir = "descending"
+	}
+	fmt.Fprintf(&b, "Multipass: %d of %d VMs, sorted by %s %s\n",
+		len(m.filteredVMs), len(m.vms), m.columns[m.sortColumn].title, dir)
+	if age, stale := m.staleAge(); stale {
+		fmt.Fprintf(&b, "Stale: last updated %s ago, refresh failed: %s\n", age, m.refreshErr)
+	}
+	if m.banner != "" {
+		b.WriteString(m.banner + "\n")
+	}
+	if m.filterVisible {
+		if m.filterFocused {
+			b.WriteString(m.filterInput.View() + "\n")
+		} else {
+			b.WriteString("Filter: " + m.filterText + "\n")
+		}
+	}
+	b.WriteString("\n")
+
+	if len(m.filteredVMs) == 0 {
+		b.WriteString("No VMs found\n")
+	} else {
+		start, end, _ := m.visibleWindow()
+		for i := start; i < end; i++ {
+			marker := "  "
+			if i == m.cursor {
+				marker = "> "
+			}
+			vm, ok := m.rowVM(i)
+			if !ok {
+				fmt.Fprintf(&b, "%s%d. %s\n", marker, i+1, m.describeGroup(m.rows[i].group))
+				continue
+			}
+			fmt.Fprintf(&b, "%s%d. %s\n", marker, i+1, m.describeVM(vm))
+		}
+	}
+	b.WriteString("\n")
+
+	for _, t := range m.toasts {
+		b.WriteString("Notice: " + t.message + "\n")
+	}
+
+	if vm, ok := m.selectedVM(); ok {
+		fmt.Fprintf(&b, "Selected: row %d of %d, %s, %s\n", m.cursor+1, m.rowCount(), vm.Name, vm.State)
+	} else if key, ok := m.selectedGroup(); ok {
+		fmt.Fprintf(&b, "Selected: row %d of %d, group %s\n", m.cursor+1, m.rowCount(), m.groupLabel(key))
+	}
+	if m.backgroundStatus != "" {
+		b.WriteString("Background: " + m.backgroundStatus + "\n")
+	}
+	b.WriteString("Keys: c create, [ stop, ] start, i info, s shell, f filter, h help, q quit\n")
+	return b.String()
+}
+
+// ttlRemaining returns the time left before a VM's TTL expires, or "".
+func (m tableModel) ttlRemaining(vmName string) string {
+	meta, ok := m.meta[vmName]
+	if !ok || meta.Expires.IsZero() {
+		return ""
+	}
+	return formatRemaining(time.Until(meta.Expires))
+}
+
+// describeVM summarises a row as a single sentence.
+func (m tableModel) describeVM(vm vmData) string {
+	if busy, ok := m.busyVMs[vm.info.Name]; ok {
+		return fmt.Sprintf("%s, %s (%s)", vm.info.Name, busy.phaseMessage(), busy.elapsed())
+	}
+	parts := []string{vm.info.Name, orDashes(vm.info.State)}
+	if vm.info.IPv4 != "" && vm.info.IPv4 != "--" {
+		parts = append(parts, "IP "+vm.info.IPv4)
+	}
+	if vm.info.CPUs != "" && vm.info.CPUs != "--" {
+		parts = append(parts, vm.info.CPUs+" CPUs")
+	}
+	if frac, ok := parseUsageFraction(vm.info.DiskUsage); ok {
+		parts = append(parts, fmt.Sprintf("disk %d%% used", int(frac*100)))
+	}
+	if frac, ok := parseUsageFraction(vm.info.MemoryUsage); ok {
+		parts = append(parts, fmt.Sprintf("memory %d%% used", int(frac*100)))
+	}
+	if vm.info.Snapshots != "" && vm.info.Snapshots != "--" && vm.info.Snapshots != "0" {
+		parts = append(parts, vm.info.Snapshots+" snapshots")
+	}
+	if ttl := m.ttlRemaining(vm.info.Name); ttl != "" {
+		parts = append(parts, ttl+" left before TTL expiry")
+	}
+	if tags := m.meta[vm.info.Name].Tags; len(tags) > 0 {
+		parts = append(parts, "tags "+strings.Join(tags, " "))
+	}
+	if notes := m.meta[vm.info.Name].Notes; notes != "" {
+		first, _, _ := strings.Cut(notes, "\n")
+		parts = append(parts, "note: "+truncateToRunes(first, 40))
+	}
+	if m.idle[vm.info.Name] {
+		parts = append(parts, "idle")
+	}
+	if m.diskFull[vm.info.Name] {
+		parts = append(parts, "disk almost full")
+	}
+	if since, ok := m.stuck[vm.info.Name]; ok {
+		parts = append(parts, fmt.Sprintf("stuck %s for %s", vm.info.State, formatRemaining(time.Since(since))))
+	}
+	if ok, checked := m.reach[vm.info.Name]; checked && !ok {
+		parts = append(parts, "not reachable on its IP")
+	}
+	if releaseStatusOf(vm.info.Release, time.Now()) == releaseEOL {
+		parts = append(parts, "end-of-life release "+vm.info.Release)
+	}
+	return strings.Join(parts, ", ")
+}
+
 func (m tableModel) computeColumnWidths() []tableColumn {
 	cols := make([]tableColumn, len(m.columns))
 	copy(cols, m.columns)
@@ -670,6 +1120,9 @@ func (m tableModel) renderRow(vm vmData, cols []tableColumn, selected bool, div
 		}
 
 		phase := busy.phaseMessage()
+		if holder, waiting := vmLocks.status(vm.info.Name); waiting > 0 && holder != "" {
+			phase = "Waiting for " + holder + "…"
+		}
 		elapsed := busy.elapsed()
 		barAvail := progressWidth - lipgloss.Width(phase) - lipgloss.Width(elapsed) - 6
 		if barAvail < 4 {
@@ -686,14 +1139,34 @@ func (m tableModel) renderRow(vm vmData, cols []tableColumn, selected bool, div
 	}
 
 	// ── Normal row ──
+	name := vm.info.Name
+	if ttl := m.ttlRemaining(vm.info.Name); ttl != "" {
+		name += " ⏳" + ttl
+	}
+	if m.idle[vm.info.Name] {
+		name += " 💤"
+	}
+	if m.diskFull[vm.info.Name] {
+		name += " ⚠disk"
+	}
+	if _, ok := m.stuck[vm.info.Name]; ok {
+		name += " ⚠stuck"
+	}
+	if releaseStatusOf(vm.info.Release, time.Now()) == releaseEOL {
+		name += " ⚠EOL"
+	}
 	values := []string{
-		vm.info.Name,
+		name,
 		vm.info.State,
 		vm.info.Snapshots,
 		vm.info.IPv4,
 		"", // CPU
 		"", // Disk
 		"", // Memory
+		m.reachLabel(vm.info.Name),
+	}
+	if i := m.columnIndex(ownerColumn.title); i >= 0 {
+		values = append(values[:i], m.owners[vm.info.Name])
 	}
 
 	var cells []string
@@ -767,7 +1240,26 @@ func (m tableModel) renderRow(vm vmData, cols []tableColumn, selected bool, div
 			continue
 		}
 
+		// Net column (index 7, when reachability checks are on)
+		if i == m.columnIndex(reachColumn.title) {
+			clr := subtle
+			if ok, checked := m.reach[vm.info.Name]; checked {
+				clr = runningClr
+				if !ok {
+					clr = stoppedClr
+				}
+			}
+			if !selected {
+				style = style.Foreground(clr)
+			}
+			cells = append(cells, cellDiv+style.Render(val))
+			continue
+		}
+
 		// Default: truncate and render (by runes to avoid cutting UTF-8 mid-rune)
+		if clr := rowTextColor(vm.info.State); clr != "" && !selected {
+			style = style.Foreground(clr)
+		}
 		visibleLen := lipgloss.Width(val)
 		if visibleLen > cols[i].width-2 && cols[i].width > 4 {
 			val = truncateToRunes(val, cols[i].width-4)
@@ -778,6 +1270,33 @@ func (m tableModel) renderRow(vm vmData, cols []tableColumn, selected bool, div
 	return prefix + strings.Join(cells, "")
 }
 
+// columnIndex returns the index of the optional column titled title, or
+// -1 when the table does not have it.
+func (m tableModel) columnIndex(title string) int {
+	for i := reachColumnIdx; i < len(m.columns); i++ {
+		if m.columns[i].title == title {
+			return i
+		}
+	}
+	return -1
+}
+
+// renderSectionDivider draws a labelled rule spanning the visible columns.
+func (m tableModel) renderSectionDivider(label string, cols []tableColumn) string {
+	width := 0
+	visibleCols := 0
+	for _, c := range cols {
+		if !c.hidden {
+			width += c.width
+			visibleCols++
+		}
+	}
+	width += max(0, visibleCols-1)
+	text := "─ " + label + " "
+	rule := strings.Repeat("─", max(0, width-lipgloss.Width(text)))
+	return " " + lipgloss.NewStyle().Foreground(deletedClr).Italic(true).Render(text+rule)
+}
+
 // ─── Usage Bars ─────────────────────────────────────────────────────────────────
 
 // parseUsageFraction parses "X.XGiB out of Y.YGiB" or "X.XMiB out of Y.YMiB" into 0.0–1.0.
@@ -872,15 +1391,16 @@ func renderSparkBar(fraction float64, barWidth int, clr lipgloss.Color) string {
 		lipgloss.NewStyle().Foreground(subtle).Render(pctStr)
 }
 
-// usageBarColor returns a color based on usage percentage (green→amber→red).
+// usageBarColor returns a color based on usage percentage (green→amber→red),
+// switching at usage-warn and usage-critical.
 func usageBarColor(fraction float64) lipgloss.Color {
 	switch {
-	case fraction < 0.6:
+	case fraction < usageWarnAt:
 		return runningClr // green
-	case fraction < 0.85:
-		return suspendClr // amber
+	case fraction < usageCriticalAt:
+		return warnClr // amber
 	default:
-		return stoppedClr // red
+		return criticalClr // red
 	}
 }
 
@@ -934,7 +1454,11 @@ func (m tableModel) renderToasts() string {
 	for _, t := range m.toasts {
 		// Calculate fade: toasts fade out in the last second
 		age := time.Since(t.created)
-		remaining := toastDuration - age
+		duration := t.duration
+		if duration == 0 {
+			duration = toastDuration
+		}
+		remaining := duration - age
 
 		var style lipgloss.Style
 		switch t.style {
@@ -967,24 +1491,44 @@ func (m tableModel) renderFooter() string {
 
 	// Group shortcuts by category
 	vmOps := []struct{ key, desc string }{
-		{"c", "Create"}, {"C", "Adv Create"}, {"[", "Stop"}, {"]", "Start"},
-		{"p", "Suspend"}, {"d", "Delete"}, {"r", "Recover"},
+		{"c", "Create"}, {"L", "Launch"}, {"C", "Adv Create"}, {"[", "Stop"}, {"]", "Start"},
+		{"p", "Suspend"}, {"x", "Trash"}, {"d", "Delete"}, {"r", "Recover"}, {"N", "Fix Net"},
 	}
 	bulkOps := []struct{ key, desc string }{
 		{"<", "StopAll"}, {">", "StartAll"}, {"!", "Purge"},
 	}
 	navOps := []struct{ key, desc string }{
-		{"i", "Info"}, {"s", "Shell"}, {"n", "Snap"}, {"m", "Snaps"}, {"M", "Mount"},
+		{"i", "Info"}, {"s", "Shell"}, {"X", "Exec"}, {"F", "Files"}, {"n", "Snap"}, {"m", "Snaps"}, {"S", "Find Snap"}, {"M", "Mount"}, {"e", "Backup"},
 	}
 	appOps := []struct{ key, desc string }{
-		{"f", "Filter"}, {"/", "Refresh"}, {"1-0", "Theme"}, {"h", "Help"}, {"q", "Quit"},
+		{"f", "Filter"}, {"/", "Refresh"}, {"E", "Export"}, {"B", "Bridge"}, {"1-0", "Theme"}, {"h", "Help"}, {"q", "Quit"},
 	}
 
+	caps := capsFor(m.driver)
+	supported := func(ops []struct{ key, desc string }) []struct{ key, desc string } {
+		return slices.DeleteFunc(ops, func(op struct{ key, desc string }) bool {
+			feature, gated := driverKeyFeatures[op.key]
+			return gated && caps.lacks(feature)
+		})
+	}
+	if m.readOnly {
+		vmOps, bulkOps = nil, nil
+		navOps = []struct{ key, desc string }{
+			{"i", "Info"}, {"F", "Files"}, {"m", "Snaps"}, {"S", "Find Snap"}, {"M", "Mount"},
+		}
+		appOps = []struct{ key, desc string }{
+			{"f", "Filter"}, {"/", "Refresh"}, {"E", "Export"}, {"1-0", "Theme"}, {"h", "Help"}, {"q", "Quit"},
+		}
+	}
+	vmOps, navOps, appOps = supported(vmOps), supported(navOps), supported(appOps)
+
 	divider := footerSepStyle.Render("  │  ")
 
 	// Responsive footer: adjust based on terminal width
 	var footerLines string
-	if m.width >= 100 {
+	if m.readOnly {
+		footerLines = renderShortcutLine(navOps) + divider + renderShortcutLine(appOps)
+	} else if m.width >= 100 {
 		// Two lines with groups
 		line1 := renderShortcutLine(vmOps) + divider + renderShortcutLine(bulkOps)
 		line2 := renderShortcutLine(navOps) + divider + renderShortcutLine(appOps)
@@ -1014,15 +1558,31 @@ func (m tableModel) renderFooter() string {
 	if m.width >= 60 {
 		statusContent = fmt.Sprintf("  Tab: sort by %s  Shift+Tab: %s",
 			m.columns[m.sortColumn].title, sortDir)
-		if !m.lastRefresh.IsZero() {
+		if !m.lastRefresh.IsZero() && m.refreshErr == "" {
 			ago := time.Since(m.lastRefresh).Truncate(time.Second)
 			statusContent += fmt.Sprintf("  ·  ↻ %s ago", ago)
 		}
+		if m.backgroundStatus != "" {
+			statusContent += "  ·  ⧗ " + m.backgroundStatus + " (b)"
+		}
+		if m.agent {
+			statusContent += "  ·  ⚙ agent"
+		}
+		if n := len(eolInstances(m.vms, time.Now())); n > 0 {
+			statusContent += fmt.Sprintf("  ·  ⚠ %d on EOL release", n)
+		}
 	} else {
 		statusContent = fmt.Sprintf("  Sort: %s %s",
 			m.columns[m.sortColumn].title, sortDir)
 	}
 	statusLine := formHintStyle.Render(statusContent)
+	if age, stale := m.staleAge(); stale {
+		statusLine += lipgloss.NewStyle().Foreground(suspendClr).Render(
+			fmt.Sprintf("  ·  ⚠ stale, updated %s ago: %s", age, m.refreshErr))
+	}
+	if m.readOnly {
+		statusLine = lipgloss.NewStyle().Foreground(suspendClr).Bold(true).Render("  READ-ONLY") + formHintStyle.Render(statusContent)
+	}
 
 	return sep + "\n" + footerStyle.Render(footerLines+"\n"+statusLine)
 }
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeVMList(t *testing.T) {
+	prev := []vmData{
+		{info: VMInfo{Name: "vm-a", State: "Running"}},
+		{info: VMInfo{Name: "vm-b", State: "Stopped"}},
+	}
+
+	t.Run("identical list reports no change", func(t *testing.T) {
+		next := []vmData{
+			{info: VMInfo{Name: "vm-b", State: "Stopped"}},
+			{info: VMInfo{Name: "vm-a", State: "Running"}},
+		}
+		merged, changed := mergeVMList(prev, next, nil)
+		if changed {
+			t.Fatalf("expected no change")
+		}
+		if merged[0].info.Name != "vm-a" || merged[1].info.Name != "vm-b" {
+			t.Fatalf("expected previous order to be kept, got %v", merged)
+		}
+	})
+
+	t.Run("updates changed rows and appends new VMs", func(t *testing.T) {
+		next := []vmData{
+			{info: VMInfo{Name: "vm-c", State: "Running"}},
+			{info: VMInfo{Name: "vm-a", State: "Stopped"}},
+		}
+		merged, changed := mergeVMList(prev, next, nil)
+		if !changed {
+			t.Fatalf("expected change")
+		}
+		if len(merged) != 2 || merged[0].info.State != "Stopped" || merged[1].info.Name != "vm-c" {
+			t.Fatalf("unexpected merge result: %v", merged)
+		}
+	})
+
+	t.Run("keeps busy rows missing from the listing", func(t *testing.T) {
+		busy := map[string]busyInfo{"vm-b": {operation: "Creating", startTime: time.Now()}}
+		next := []vmData{{info: VMInfo{Name: "vm-a", State: "Running"}}}
+		merged, _ := mergeVMList(prev, next, busy)
+		if len(merged) != 2 || merged[1].info.Name != "vm-b" {
+			t.Fatalf("expected busy row to be retained, got %v", merged)
+		}
+	})
+}
+
+func TestSetVMsKeepsCursorOnSelectedVM(t *testing.T) {
+	m := newTableModel()
+	m.height = 40
+	m.setVMs([]vmData{
+		{info: VMInfo{Name: "vm-a"}},
+		{info: VMInfo{Name: "vm-c"}},
+	})
+	m.cursor = 1 // vm-c
+
+	m.setVMs([]vmData{
+		{info: VMInfo{Name: "vm-a"}},
+		{info: VMInfo{Name: "vm-b"}},
+		{info: VMInfo{Name: "vm-c"}},
+	})
+
+	if vm, ok := m.selectedVM(); !ok || vm.Name != "vm-c" {
+		t.Fatalf("expected cursor to stay on vm-c, got %q", vm.Name)
+	}
+}
+
+func TestDeletedVMsFormTrailingSection(t *testing.T) {
+	m := newTableModel()
+	m.height = 40
+	m.setVMs([]vmData{
+		{info: VMInfo{Name: "vm-a", State: "Deleted"}},
+		{info: VMInfo{Name: "vm-b", State: "Running"}},
+		{info: VMInfo{Name: "vm-c", State: "Stopped"}},
+	})
+
+	if got := m.filteredVMs[2].info.Name; got != "vm-a" {
+		t.Fatalf("expected deleted VM last, got %q", got)
+	}
+	if _, _, divider := m.visibleWindow(); divider != 2 {
+		t.Fatalf("expected divider above row 2, got %d", divider)
+	}
+}
+
+func TestTakeUndoExpires(t *testing.T) {
+	m := newTableModel()
+	m.setUndo("vm-a")
+	if name, ok := m.takeUndo(); !ok || name != "vm-a" {
+		t.Fatalf("expected undo for vm-a, got %q %v", name, ok)
+	}
+	if _, ok := m.takeUndo(); ok {
+		t.Fatalf("expected undo to be consumed")
+	}
+
+	m.undo = &pendingUndo{vmName: "vm-b", expires: time.Now().Add(-time.Second)}
+	if _, ok := m.takeUndo(); ok {
+		t.Fatalf("expected expired undo to be ignored")
+	}
+}
@@ -0,0 +1,262 @@
+// view_wizard.go - First-run setup wizard view
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// wizardModel collects launch defaults, a template repo and an SSH key on
+// first launch and writes them to ~/.passgo/.config.
+type wizardModel struct {
+	check   *multipassCheck // nil while checking
+	rerun   bool            // started with -setup; replace any existing config
+	fields  []advField
+	sshKeys []string // aligned with the SSH key options after "None"
+	cursor  int
+	errMsg  string
+	width   int
+	height  int
+}
+
+// multipassCheckMsg carries the result of checkMultipass.
+type multipassCheckMsg multipassCheck
+
+// wizardDoneMsg is sent once the wizard has written (or skipped) the config.
+type wizardDoneMsg struct {
+	path    string
+	skipped bool
+}
+
+func newWizardModel(rerun bool, w, h int) wizardModel {
+	numeric := func(v string) textinput.Model {
+		in := textinput.New()
+		in.SetValue(v)
+		in.CharLimit = 8
+		return in
+	}
+	repoInput := textinput.New()
+	repoInput.Placeholder = "optional, e.g. https://github.com/you/cloud-init-templates"
+	repoInput.CharLimit = 200
+
+	keyOptions := []string{"None"}
+	var keys []string
+	if home, err := os.UserHomeDir(); err == nil {
+		keys = findSSHPublicKeys(home)
+	}
+	for _, k := range keys {
+		keyOptions = append(keyOptions, filepath.Base(k))
+	}
+	keyIdx := 0
+	if len(keys) > 0 {
+		keyIdx = 1
+	}
+
+	releaseIdx := 0
+	for i, r := range UbuntuReleases {
+		if r == DefaultUbuntuRelease {
+			releaseIdx = i
+		}
+	}
+
+	fields := []advField{
+		{label: "Default release", isSelect: true, options: UbuntuReleases, optionIdx: releaseIdx},
+		{label: "CPU Cores", input: numeric(strconv.Itoa(DefaultCPUCores)), isNumeric: true},
+		{label: "Memory", input: numeric(formatSizeMB(DefaultRAMMB)), isNumeric: true, bareUnitMB: 1},
+		{label: "Disk", input: numeric(formatSizeMB(DefaultDiskGB * 1024)), isNumeric: true, bareUnitMB: 1024},
+		{label: "Template repo", input: repoInput},
+		{label: "SSH key", isSelect: true, options: keyOptions, optionIdx: keyIdx},
+		{label: "[ Save ]", isSubmit: true},
+		{label: "[ Skip ]", isCancel: true},
+	}
+	return wizardModel{rerun: rerun, fields: fields, sshKeys: keys, width: w, height: h}
+}
+
+func (m wizardModel) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, func() tea.Msg { return multipassCheckMsg(checkMultipass()) })
+}
+
+func (m wizardModel) Update(msg tea.Msg) (wizardModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case multipassCheckMsg:
+		check := multipassCheck(msg)
+		m.check = &check
+		return m, nil
+
+	case tea.KeyMsg:
+		f := &m.fields[m.cursor]
+		switch msg.String() {
+		case "esc":
+			return m, m.finish(true)
+		case "tab", "down":
+			m.move(1)
+			return m, nil
+		case "shift+tab", "up":
+			m.move(-1)
+			return m, nil
+		case "left":
+			if f.isSelect && f.optionIdx > 0 {
+				f.optionIdx--
+			}
+			return m, nil
+		case "right":
+			if f.isSelect && f.optionIdx < len(f.options)-1 {
+				f.optionIdx++
+			}
+			return m, nil
+		case "enter":
+			switch {
+			case f.isSubmit:
+				return m, m.finish(false)
+			case f.isCancel:
+				return m, m.finish(true)
+			}
+			m.move(1)
+			return m, nil
+		}
+		if !f.isSelect && !f.isSubmit && !f.isCancel {
+			var cmd tea.Cmd
+			f.input, cmd = f.input.Update(msg)
+			m.errMsg = ""
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+// move focuses the field delta steps away.
+func (m *wizardModel) move(delta int) {
+	m.fields[m.cursor].input.Blur()
+	m.cursor = (m.cursor + delta + len(m.fields)) % len(m.fields)
+	f := &m.fields[m.cursor]
+	if !f.isSelect && !f.isSubmit && !f.isCancel {
+		f.input.Focus()
+	}
+}
+
+// choices validates the form.
+func (m wizardModel) choices() (setupChoices, error) {
+	var c setupChoices
+	c.defaults.release = m.fields[0].options[m.fields[0].optionIdx]
+	limits := []struct {
+		dst  *int
+		min  int    // in the field's unit: cores or MiB
+		show string // min as typed
+		div  int    // MiB per unit of dst
+	}{
+		{&c.defaults.cpus, MinCPUCores, strconv.Itoa(MinCPUCores), 1},
+		{&c.defaults.memoryMB, MinRAMMB, formatSizeMB(MinRAMMB), 1},
+		{&c.defaults.diskGB, MinDiskGB * 1024, formatSizeMB(MinDiskGB * 1024), 1024},
+	}
+	for i, l := range limits {
+		f := m.fields[i+1]
+		n, err := f.value()
+		if err != nil || n < l.min {
+			return c, fmt.Errorf("%s must be at least %s", f.label, l.show)
+		}
+		*l.dst = (n + l.div - 1) / l.div
+	}
+	c.repoURL = strings.TrimSpace(m.fields[4].input.Value())
+	if idx := m.fields[5].optionIdx; idx > 0 {
+		c.sshKey = m.sshKeys[idx-1]
+	}
+	return c, nil
+}
+
+// finish writes the config (or the skip marker) and reports back to root.
+// Skipping a rerun leaves the existing config alone.
+func (m *wizardModel) finish(skip bool) tea.Cmd {
+	if skip && m.rerun {
+		return func() tea.Msg { return wizardDoneMsg{skipped: true} }
+	}
+	content := skippedConfig
+	if !skip {
+		c, err := m.choices()
+		if err != nil {
+			m.errMsg = err.Error()
+			return nil
+		}
+		content = c.renderConfig()
+	}
+	dir, err := userConfigDir()
+	if err != nil {
+		m.errMsg = err.Error()
+		return nil
+	}
+	path, err := writeConfigFile(dir, content, m.rerun)
+	if err != nil {
+		m.errMsg = err.Error()
+		return nil
+	}
+	return func() tea.Msg { return wizardDoneMsg{path: path, skipped: skip} }
+}
+
+func (m wizardModel) View() string {
+	title := formTitleStyle.Render("Welcome to passgo — first-run setup")
+
+	var status string
+	switch {
+	case m.check == nil:
+		status = formHintStyle.Render("Checking multipass…")
+	case m.check.path == "":
+		status = lipgloss.NewStyle().Foreground(stoppedClr).Render("✗ multipass not found on PATH — install it from https://multipass.run/install")
+	case m.check.err != nil:
+		status = lipgloss.NewStyle().Foreground(suspendClr).Render("⚠ multipass found at " + m.check.path + " but the daemon did not respond")
+	default:
+		status = lipgloss.NewStyle().Foreground(runningClr).Render("✓ " + m.check.version + " (" + m.check.path + ")")
+	}
+
+	var rows []string
+	for i, f := range m.fields {
+		if f.isSubmit || f.isCancel {
+			continue
+		}
+		label := formLabelStyle.Width(16).Render(f.label)
+		prefix := "  "
+		if i == m.cursor {
+			label = formActiveLabelStyle.Width(16).Render(f.label)
+			prefix = tableCursorStyle.Render("▎ ")
+		}
+		var value string
+		switch {
+		case f.isSelect && i == m.cursor:
+			value = lipgloss.NewStyle().Foreground(accent).Render("◀ ") + formValueStyle.Render(f.options[f.optionIdx]) +
+				lipgloss.NewStyle().Foreground(accent).Render(" ▶")
+		case f.isSelect:
+			value = "  " + lipgloss.NewStyle().Foreground(subtle).Render(f.options[f.optionIdx])
+		case i == m.cursor:
+			value = f.input.View()
+		default:
+			value = lipgloss.NewStyle().Foreground(subtle).Render(f.input.Value())
+		}
+		rows = append(rows, prefix+label+value)
+	}
+
+	var buttons []string
+	for i, f := range m.fields {
+		if !f.isSubmit && !f.isCancel {
+			continue
+		}
+		style := formButtonStyle
+		if i == m.cursor {
+			style = formActiveButtonStyle
+		}
+		buttons = append(buttons, style.Render(f.label))
+	}
+
+	dir, _ := userConfigDir()
+	content := title + "\n\n" + status + "\n\n" + strings.Join(rows, "\n") + "\n\n" +
+		"  " + strings.Join(buttons, "  ") + "\n\n" +
+		formHintStyle.Render("Saved to "+filepath.Join(dir, ".config")+"  ·  Tab/↑↓: navigate  ←→: choose  Esc: skip")
+	if m.errMsg != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(stoppedClr).Render(m.errMsg)
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalStyle.Render(content))
+}
@@ -0,0 +1,170 @@
+// vmlock.go - Per-VM serialisation of mutating multipass commands
+package main
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// vmLockTable lets only one mutating multipass command run per instance at
+// a time; others queue instead of failing with "instance is busy" style
+// errors. Read-only commands (list, info, exec…) never wait.
+type vmLockTable struct {
+	mu    sync.Mutex
+	locks map[string]*vmLock
+}
+
+type vmLock struct {
+	token   chan struct{} // holds one token while the lock is free
+	pending int           // commands holding or waiting for the lock
+	holder  string        // verb of the running command, "" while handing over
+}
+
+// vmLocks is the process-wide lock table used by runMultipassCommand.
+var vmLocks = &vmLockTable{locks: make(map[string]*vmLock)}
+
+func (t *vmLockTable) get(name string) *vmLock {
+	l, ok := t.locks[name]
+	if !ok {
+		l = &vmLock{token: make(chan struct{}, 1)}
+		l.token <- struct{}{}
+		t.locks[name] = l
+	}
+	return l
+}
+
+// acquire blocks until op may run on name and returns the release func.
+func (t *vmLockTable) acquire(name, op string) func() {
+	t.mu.Lock()
+	l := t.get(name)
+	l.pending++
+	t.mu.Unlock()
+
+	<-l.token
+	t.mu.Lock()
+	l.holder = op
+	t.mu.Unlock()
+
+	return func() {
+		t.mu.Lock()
+		l.pending--
+		l.holder = ""
+		if l.pending == 0 {
+			delete(t.locks, name)
+		}
+		t.mu.Unlock()
+		l.token <- struct{}{}
+	}
+}
+
+// acquireAll locks every name in a fixed order, so two multi-VM commands
+// cannot deadlock.
+func (t *vmLockTable) acquireAll(names []string, op string) func() {
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	releases := make([]func(), 0, len(sorted))
+	for i, name := range sorted {
+		if i > 0 && name == sorted[i-1] {
+			continue
+		}
+		releases = append(releases, t.acquire(name, op))
+	}
+	return func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+}
+
+// status reports the verb running on name and how many commands are queued
+// behind it.
+func (t *vmLockTable) status(name string) (holder string, waiting int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	l, ok := t.locks[name]
+	if !ok {
+		return "", 0
+	}
+	waiting = l.pending
+	if l.holder != "" {
+		waiting--
+	}
+	return l.holder, waiting
+}
+
+// lockTargets returns the instances a multipass command changes, or nil
+// for commands that only read.
+func lockTargets(args []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	var positional []string
+	for i := 1; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "--name" && i+1 < len(args):
+			switch args[0] {
+			case "launch":
+				return []string{args[i+1]}
+			case "clone":
+				positional = append(positional, args[i+1])
+			}
+			i++
+		case a == "--":
+			i = len(args)
+		case strings.HasPrefix(a, "-"):
+			// Flags passgo passes with a separate value.
+			switch a {
+			case "--cpus", "--memory", "--disk", "--cloud-init", "--network", "--comment", "-c", "--uid-map", "--gid-map", "--type", "-t", "--mount":
+				i++
+			}
+		default:
+			positional = append(positional, a)
+		}
+	}
+
+	var names []string
+	switch args[0] {
+	case "start", "stop", "suspend", "restart", "recover", "clone":
+		names = positional
+	case "snapshot":
+		if len(positional) > 0 {
+			names = positional[:1]
+		}
+	case "delete", "restore":
+		// vm or vm.snapshot; instance names cannot contain '.'.
+		for _, p := range positional {
+			vm, _, _ := strings.Cut(p, ".")
+			names = append(names, vm)
+		}
+	case "transfer", "mount", "umount":
+		for _, p := range positional {
+			// vm:path; a single letter before ':' is a Windows drive.
+			if vm, _, ok := strings.Cut(p, ":"); ok && len(vm) > 1 {
+				names = append(names, vm)
+			} else if args[0] == "umount" && !ok {
+				names = append(names, p)
+			}
+		}
+	case "set":
+		// local.<vm>.cpus=4 and friends
+		for _, p := range positional {
+			key, _, _ := strings.Cut(p, "=")
+			if parts := strings.Split(key, "."); len(parts) == 3 && parts[0] == "local" {
+				names = append(names, parts[1])
+			}
+		}
+	}
+	return names
+}
+
+// lockForCommand takes the locks a multipass command needs; the returned
+// func releases them.
+func lockForCommand(args []string) func() {
+	names := lockTargets(args)
+	if len(names) == 0 {
+		return func() {}
+	}
+	return vmLocks.acquireAll(names, args[0])
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLockTargets(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"launch", "--name", "web", "--cpus", "2", "24.04"}, []string{"web"}},
+		{[]string{"stop", "a", "b"}, []string{"a", "b"}},
+		{[]string{"clone", "golden", "--name", "web-1"}, []string{"golden", "web-1"}},
+		{[]string{"snapshot", "--name", "s1", "--comment", "before upgrade", "web"}, []string{"web"}},
+		{[]string{"restore", "--destructive", "web.s1"}, []string{"web"}},
+		{[]string{"restore", "web.auto-20261015-1200"}, []string{"web"}},
+		{[]string{"delete", "--purge", "web.s1"}, []string{"web"}},
+		{[]string{"delete", "web.s1", "db.s2", "cache"}, []string{"web", "db", "cache"}},
+		{[]string{"delete", "--purge", "web", "db"}, []string{"web", "db"}},
+		{[]string{"transfer", "web:/etc/hosts", "/tmp/hosts"}, []string{"web"}},
+		{[]string{"mount", `C:\src`, "web:/src"}, []string{"web"}},
+		{[]string{"umount", "web"}, []string{"web"}},
+		{[]string{"set", "local.web.cpus=4"}, []string{"web"}},
+		{[]string{"list", "--format", "csv"}, nil},
+		{[]string{"info", "web"}, nil},
+		{[]string{"exec", "web", "--", "uptime"}, nil},
+		{nil, nil},
+	}
+	for _, tt := range tests {
+		if got := lockTargets(tt.args); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lockTargets(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestVMLockQueuesSecondCommand(t *testing.T) {
+	locks := &vmLockTable{locks: make(map[string]*vmLock)}
+	release := locks.acquire("web", "stop")
+
+	acquired := make(chan func())
+	go func() { acquired <- locks.acquire("web", "snapshot") }()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if holder, waiting := locks.status("web"); holder == "stop" && waiting == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("second command never queued behind the first")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-acquired:
+		t.Fatal("second command ran while the first held the lock")
+	default:
+	}
+
+	release()
+	second := <-acquired
+	if holder, waiting := locks.status("web"); holder != "snapshot" || waiting != 0 {
+		t.Errorf("status = %q, %d; want snapshot, 0", holder, waiting)
+	}
+	second()
+	if len(locks.locks) != 0 {
+		t.Errorf("lock entries left behind: %v", locks.locks)
+	}
+}
+
+func TestVMLockAcquireAllDeduplicates(t *testing.T) {
+	locks := &vmLockTable{locks: make(map[string]*vmLock)}
+	done := make(chan struct{})
+	go func() {
+		locks.acquireAll([]string{"b", "a", "b"}, "stop")()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("acquireAll deadlocked on a repeated name")
+	}
+}
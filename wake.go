@@ -0,0 +1,49 @@
+// wake.go - Noticing that the host slept, and refreshing everything when it wakes
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// wakeGap is how much longer than the refresh interval the wall clock may
+// move between two ticks before passgo assumes the host was suspended. It
+// is well above any stall of a busy machine.
+const wakeGap = 30 * time.Second
+
+// sleptBetween reports how long the host was away between two ticks, or
+// false when the gap is an ordinary one. Go's monotonic clock stops while
+// the host is suspended, so the times are compared by the wall clock.
+func sleptBetween(prev, now time.Time, interval time.Duration) (time.Duration, bool) {
+	if prev.IsZero() {
+		return 0, false
+	}
+	gap := now.Round(0).Sub(prev.Round(0))
+	if gap <= interval+wakeGap {
+		return 0, false
+	}
+	return gap - interval, true
+}
+
+// checkWake runs on every tick and reports whether the host has just woken.
+// The caller then refreshes the list, whatever view is open, since VM states
+// and IPs are almost always stale by then; the refresh starts a new
+// reachability check, so the old results are dropped here.
+func (m *rootModel) checkWake(now time.Time) (bool, tea.Cmd) {
+	prev := m.lastTick
+	m.lastTick = now
+	away, slept := sleptBetween(prev, now, autoRefreshInterval)
+	if !slept {
+		return false, nil
+	}
+	if appLogger != nil {
+		appLogger.Printf("host woke after %s; refreshing VM list", away.Round(time.Second))
+	}
+	m.table.reach = nil
+	if m.reachInFlight {
+		m.reachRecheck = true // its dials started before the suspend
+	}
+	return true, m.table.addToast(fmt.Sprintf("Host woke after %s; refreshing", formatRemaining(away)), "info")
+}
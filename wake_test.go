@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSleptBetween(t *testing.T) {
+	prev := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	if _, slept := sleptBetween(time.Time{}, prev, time.Second); slept {
+		t.Error("the first tick cannot follow a sleep")
+	}
+	if _, slept := sleptBetween(prev, prev.Add(5*time.Second), time.Second); slept {
+		t.Error("a few seconds late is a stall, not a sleep")
+	}
+	away, slept := sleptBetween(prev, prev.Add(2*time.Hour+time.Second), time.Second)
+	if !slept || away != 2*time.Hour {
+		t.Errorf("sleptBetween = %v, %v; want 2h, true", away, slept)
+	}
+}
+
+func TestTickAfterWakeRefreshes(t *testing.T) {
+	start := time.Now()
+	m := rootModel{currentView: viewInfo, table: newTableModel(), reachInFlight: true}
+	m.table.reach = map[string]bool{"web": true}
+
+	model, _ := m.Update(autoRefreshTickMsg(start))
+	m = model.(rootModel)
+	if m.vmListFetchInFlight {
+		t.Fatal("an ordinary tick off the table should not refresh")
+	}
+	model, _ = m.Update(autoRefreshTickMsg(start.Add(time.Hour)))
+	m = model.(rootModel)
+	if !m.vmListFetchInFlight || m.table.reach != nil || !m.reachRecheck {
+		t.Fatalf("after waking: fetching %v, reach %v, recheck %v", m.vmListFetchInFlight, m.table.reach, m.reachRecheck)
+	}
+
+	// The check that was in flight during the sleep is discarded.
+	m.reach = reachPolicy{port: 22}
+	m.table.vms = []vmData{{info: VMInfo{Name: "web", State: "Running", IPv4: "10.0.0.2"}}}
+	if cmd := m.applyReachability(map[string]bool{"web": true}); cmd == nil || m.table.reach != nil || !m.reachInFlight {
+		t.Errorf("stale results should start a new check: reach %v, in flight %v", m.table.reach, m.reachInFlight)
+	}
+}
@@ -0,0 +1,116 @@
+// wizard.go - First-run setup: environment checks and writing .config
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// userConfigDir is ~/.passgo, the last place .config is looked for and
+// where the setup wizard writes it.
+func userConfigDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".passgo"), nil
+}
+
+// configFileExists reports whether any search directory has a .config.
+func configFileExists(searchDirs []string) bool {
+	for _, dir := range searchDirs {
+		if _, err := os.Stat(filepath.Join(dir, ".config")); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// multipassCheck is the result of looking for a working multipass.
+type multipassCheck struct {
+	path    string // "" when not on PATH
+	version string // first line of `multipass version`
+	err     error  // set when the binary exists but the daemon did not answer
+}
+
+// checkMultipass looks for the multipass binary and asks it for its version,
+// which also proves the daemon is reachable.
+func checkMultipass() multipassCheck {
+	path, err := exec.LookPath(mpRunner.program())
+	if err != nil {
+		return multipassCheck{}
+	}
+	out, err := runMultipassCommand("version")
+	if err != nil {
+		return multipassCheck{path: path, err: err}
+	}
+	line, _, _ := strings.Cut(out, "\n")
+	return multipassCheck{path: path, version: strings.TrimSpace(line)}
+}
+
+// findSSHPublicKeys lists ~/.ssh/*.pub, preferring ed25519 keys.
+func findSSHPublicKeys(home string) []string {
+	keys, _ := filepath.Glob(filepath.Join(home, ".ssh", "*.pub"))
+	sort.SliceStable(keys, func(i, j int) bool {
+		return strings.Contains(keys[i], "ed25519") && !strings.Contains(keys[j], "ed25519")
+	})
+	return keys
+}
+
+// setupChoices are the answers collected by the setup wizard.
+type setupChoices struct {
+	defaults launchDefaults
+	repoURL  string // github-cloud-init-repo, optional
+	sshKey   string // public key file, optional
+}
+
+// renderConfig renders choices as a .config file.
+func (c setupChoices) renderConfig() string {
+	var b strings.Builder
+	b.WriteString("# passgo configuration (written by the setup wizard; see README)\n")
+	fmt.Fprintf(&b, "default-release=%s\n", c.defaults.release)
+	fmt.Fprintf(&b, "default-cpus=%d\n", c.defaults.cpus)
+	fmt.Fprintf(&b, "default-memory=%d\n", c.defaults.memoryMB)
+	fmt.Fprintf(&b, "default-disk=%d\n", c.defaults.diskGB)
+	if c.repoURL != "" {
+		fmt.Fprintf(&b, "github-cloud-init-repo=%s\n", c.repoURL)
+	}
+	if c.sshKey != "" {
+		fmt.Fprintf(&b, "ssh-key=%s\n", c.sshKey)
+	}
+	return b.String()
+}
+
+// skippedConfig is written when the wizard is skipped so it is not shown again.
+const skippedConfig = "# passgo configuration (setup wizard skipped; see README for settings)\n"
+
+// writeConfigFile creates dir/.config with content. An existing file is
+// only replaced when replace is set, and is kept as .config.bak.
+func writeConfigFile(dir, content string, replace bool) (string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, ".config")
+	if replace {
+		if err := os.Rename(path, path+".bak"); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600) // #nosec G304 -- path under the user's config dir
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return path, fmt.Errorf("%s already exists", path)
+		}
+		return "", err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetupChoicesRenderConfig(t *testing.T) {
+	c := setupChoices{
+		defaults: launchDefaults{release: "22.04", cpus: 4, memoryMB: 4096, diskGB: 20},
+		repoURL:  "https://github.com/you/templates",
+		sshKey:   "/home/you/.ssh/id_ed25519.pub",
+	}
+	lookup := func(content string) func(string) (string, bool) {
+		values := map[string]string{}
+		for _, line := range strings.Split(content, "\n") {
+			if k, v, ok := strings.Cut(line, "="); ok {
+				values[k] = v
+			}
+		}
+		return func(k string) (string, bool) { v, ok := values[k]; return v, ok }
+	}
+
+	got := loadLaunchDefaults(lookup(c.renderConfig()))
+	if got != c.defaults {
+		t.Fatalf("round trip: got %+v, want %+v", got, c.defaults)
+	}
+	if v, _ := lookup(c.renderConfig())("ssh-key"); v != c.sshKey {
+		t.Fatalf("ssh-key = %q", v)
+	}
+	if strings.Contains((setupChoices{defaults: c.defaults}).renderConfig(), "github-cloud-init-repo") {
+		t.Fatalf("empty repo should be omitted")
+	}
+}
+
+func TestWriteConfigFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".passgo")
+	path, err := writeConfigFile(dir, "a=1\n", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !configFileExists([]string{t.TempDir(), dir}) {
+		t.Fatalf("expected %s to be found", path)
+	}
+	if _, err := writeConfigFile(dir, "a=2\n", false); err == nil {
+		t.Fatalf("expected existing config not to be overwritten")
+	}
+	if _, err := writeConfigFile(dir, "a=3\n", true); err != nil {
+		t.Fatal(err)
+	}
+	cur, _ := os.ReadFile(path)
+	bak, _ := os.ReadFile(path + ".bak")
+	if string(cur) != "a=3\n" || string(bak) != "a=1\n" {
+		t.Fatalf("replace: got %q, backup %q", cur, bak)
+	}
+}
+
+func TestFindSSHPublicKeys(t *testing.T) {
+	home := t.TempDir()
+	sshDir := filepath.Join(home, ".ssh")
+	if err := os.MkdirAll(sshDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"id_rsa.pub", "id_ed25519.pub", "id_rsa", "known_hosts"} {
+		if err := os.WriteFile(filepath.Join(sshDir, name), []byte("k"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{filepath.Join(sshDir, "id_ed25519.pub"), filepath.Join(sshDir, "id_rsa.pub")}
+	if got := findSSHPublicKeys(home); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
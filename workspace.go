@@ -0,0 +1,91 @@
+// workspace.go - Mounting the project workspace into a VM at launch
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// defaultProjectMountTarget is where the workspace is mounted unless
+// project-mount-target is set in .config.
+const defaultProjectMountTarget = "/home/ubuntu/src"
+
+// workspaceMarkers identify a project directory that is not a git checkout.
+var workspaceMarkers = []string{"go.mod", "package.json", "pyproject.toml", "Cargo.toml", "Makefile"}
+
+// detectWorkspace returns the project root for dir: the nearest enclosing
+// git checkout, else dir itself when it holds a project file. The home
+// directory is never treated as a workspace, so a dotfiles repo in ~ does
+// not mount all of home.
+func detectWorkspace(dir, home string) (string, bool) {
+	for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+		if d == home {
+			break
+		}
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d, true
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if filepath.Clean(dir) == home {
+		return "", false
+	}
+	for _, marker := range workspaceMarkers {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			return filepath.Clean(dir), true
+		}
+	}
+	return "", false
+}
+
+// currentWorkspace detects the workspace passgo was started in.
+func currentWorkspace() (string, bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	home, _ := os.UserHomeDir()
+	return detectWorkspace(dir, filepath.Clean(home))
+}
+
+// projectMountTarget returns the VM path the workspace is mounted at.
+func projectMountTarget(lookup func(string) (string, bool)) string {
+	if v, ok := lookup("project-mount-target"); ok && v != "" {
+		return v
+	}
+	return defaultProjectMountTarget
+}
+
+// withProjectMount adds `--mount source:target` to launch args, ahead of
+// the release, which is always last.
+func withProjectMount(args []string, source, target string) []string {
+	if source == "" {
+		return args
+	}
+	return slices.Insert(slices.Clone(args), len(args)-1, "--mount", source+":"+target)
+}
+
+// setProject records that vmName has dir mounted at target.
+func (s appState) setProject(vmName, dir, target string) {
+	meta := s.VMs[vmName]
+	meta.ProjectDir = dir
+	meta.ProjectTarget = target
+	s.VMs[vmName] = meta
+}
+
+// recordProjectMount saves the workspace mapping of a VM launched outside
+// the TUI.
+func recordProjectMount(vmName, dir, target string) error {
+	path, err := stateFilePath()
+	if err != nil {
+		return err
+	}
+	_, err = updateState(path, func(st *appState) bool {
+		st.setProject(vmName, dir, target)
+		return true
+	})
+	return err
+}
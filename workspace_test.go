@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectWorkspace(t *testing.T) {
+	home := t.TempDir()
+	repo := filepath.Join(home, "code", "app")
+	sub := filepath.Join(repo, "cmd", "tool")
+	plain := filepath.Join(home, "notes")
+	tool := filepath.Join(home, "tools", "lib")
+	for _, d := range []string{filepath.Join(repo, ".git"), sub, plain, tool} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tool, "go.mod"), []byte("module lib\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		dir, want string
+		ok        bool
+	}{
+		{sub, repo, true},
+		{repo, repo, true},
+		{tool, tool, true},
+		{plain, "", false},
+		{home, "", false},
+	}
+	for _, c := range cases {
+		got, ok := detectWorkspace(c.dir, home)
+		if got != c.want || ok != c.ok {
+			t.Errorf("detectWorkspace(%s) = %q, %v; want %q, %v", c.dir, got, ok, c.want, c.ok)
+		}
+	}
+
+	// A dotfiles checkout in home does not make home a workspace.
+	if err := os.MkdirAll(filepath.Join(home, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := detectWorkspace(plain, home); ok {
+		t.Errorf("detectWorkspace under a home repo = %q, want none", got)
+	}
+}
+
+func TestWithProjectMount(t *testing.T) {
+	args := launchVMArgs("web", "24.04", 2, 2048, 20, "", nil)
+	got := strings.Join(withProjectMount(args, "/home/me/app", "/home/ubuntu/src"), " ")
+	want := "launch --name web --cpus 2 --memory 2048M --disk 20G --mount /home/me/app:/home/ubuntu/src 24.04"
+	if got != want {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+	if strings.Join(withProjectMount(args, "", "/x"), " ") != strings.Join(args, " ") {
+		t.Error("no workspace should leave the args alone")
+	}
+	if args[len(args)-1] != "24.04" {
+		t.Error("withProjectMount must not modify its input")
+	}
+}
+
+func TestProjectMountTargetAndState(t *testing.T) {
+	none := func(string) (string, bool) { return "", false }
+	if got := projectMountTarget(none); got != defaultProjectMountTarget {
+		t.Errorf("default target = %q", got)
+	}
+	set := func(k string) (string, bool) { return "/srv/app", k == "project-mount-target" }
+	if got := projectMountTarget(set); got != "/srv/app" {
+		t.Errorf("configured target = %q", got)
+	}
+
+	st := newAppState()
+	st.setProject("web", "/home/me/app", "/home/ubuntu/src")
+	if meta := st.VMs["web"]; meta.ProjectDir != "/home/me/app" || meta.empty() {
+		t.Errorf("meta = %+v", meta)
+	}
+}
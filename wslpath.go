@@ -0,0 +1,92 @@
+// wslpath.go - Translating host paths between Windows and WSL forms
+package main
+
+import (
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// pathStyle is the form host paths must take for the multipass in use.
+type pathStyle int
+
+const (
+	pathsAsIs    pathStyle = iota
+	pathsPOSIX             // C:\Users\me → /mnt/c/Users/me
+	pathsWindows           // /mnt/c/Users/me → C:\Users\me
+)
+
+var (
+	windowsDrivePathRe = regexp.MustCompile(`^([A-Za-z]):[\\/]`)
+	wslUNCPathRe       = regexp.MustCompile(`(?i)^[\\/]{2}wsl(\$|\.localhost)[\\/][^\\/]+`)
+	wslMountPathRe     = regexp.MustCompile(`^/mnt/([a-z])(/|$)`)
+)
+
+// hostPathStyle picks the translation for runner on goos: multipass inside
+// WSL wants POSIX paths, while a Windows multipass wants Windows ones.
+func hostPathStyle(goos string, runner multipassRunner) pathStyle {
+	switch runner.(type) {
+	case wslRunner:
+		return pathsPOSIX
+	case localRunner:
+		if goos == "windows" {
+			return pathsWindows
+		}
+	}
+	return pathsAsIs
+}
+
+// currentPathStyle is hostPathStyle for this process.
+func currentPathStyle() pathStyle {
+	return hostPathStyle(runtime.GOOS, mpRunner)
+}
+
+// translateHostPath rewrites p into style. Paths already in that form, and
+// anything that is not a host path, are returned unchanged.
+func translateHostPath(p string, style pathStyle) string {
+	switch style {
+	case pathsPOSIX:
+		if m := windowsDrivePathRe.FindStringSubmatch(p); m != nil {
+			rest := strings.ReplaceAll(p[len(m[0]):], `\`, "/")
+			return strings.TrimSuffix("/mnt/"+strings.ToLower(m[1])+"/"+rest, "/")
+		}
+		if m := wslUNCPathRe.FindString(p); m != "" {
+			rest := strings.ReplaceAll(p[len(m):], `\`, "/")
+			if rest == "" {
+				return "/"
+			}
+			return rest
+		}
+	case pathsWindows:
+		if m := wslMountPathRe.FindStringSubmatch(p); m != nil {
+			rest := strings.ReplaceAll(strings.TrimPrefix(p[len(m[0]):], "/"), "/", `\`)
+			return strings.ToUpper(m[1]) + `:\` + rest
+		}
+	}
+	return p
+}
+
+// translatePathArgs translates the host paths in a multipass argument list.
+// Instance paths are written vm:path and left alone, as is everything after
+// "--" (the command run by `multipass exec`).
+func translatePathArgs(args []string, style pathStyle) []string {
+	if style == pathsAsIs {
+		return args
+	}
+	var out []string
+	for i, a := range args {
+		if a == "--" {
+			break
+		}
+		if t := translateHostPath(a, style); t != a {
+			if out == nil {
+				out = append([]string(nil), args...)
+			}
+			out[i] = t
+		}
+	}
+	if out == nil {
+		return args
+	}
+	return out
+}
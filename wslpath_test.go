@@ -0,0 +1,69 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTranslateHostPath(t *testing.T) {
+	tests := []struct {
+		in    string
+		style pathStyle
+		want  string
+	}{
+		{`C:\Users\me\src`, pathsPOSIX, "/mnt/c/Users/me/src"},
+		{`d:/data/`, pathsPOSIX, "/mnt/d/data"},
+		{`C:\`, pathsPOSIX, "/mnt/c"},
+		{`\\wsl$\Ubuntu\home\me\src`, pathsPOSIX, "/home/me/src"},
+		{`\\wsl.localhost\Ubuntu-24.04\home\me`, pathsPOSIX, "/home/me"},
+		{"/home/me/src", pathsPOSIX, "/home/me/src"},
+		{"web:/home/ubuntu", pathsPOSIX, "web:/home/ubuntu"},
+		{"/mnt/c/Users/me/src", pathsWindows, `C:\Users\me\src`},
+		{"/mnt/d", pathsWindows, `D:\`},
+		{"/mnt/data/x", pathsWindows, "/mnt/data/x"},
+		{`C:\Users\me`, pathsWindows, `C:\Users\me`},
+		{`C:\Users\me`, pathsAsIs, `C:\Users\me`},
+	}
+	for _, tt := range tests {
+		if got := translateHostPath(tt.in, tt.style); got != tt.want {
+			t.Errorf("translateHostPath(%q, %d) = %q, want %q", tt.in, tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestTranslatePathArgs(t *testing.T) {
+	args := []string{"mount", `C:\Users\me\src`, "web:/home/ubuntu/src"}
+	got := translatePathArgs(args, pathsPOSIX)
+	if want := []string{"mount", "/mnt/c/Users/me/src", "web:/home/ubuntu/src"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mount args = %v, want %v", got, want)
+	}
+	if args[1] != `C:\Users\me\src` {
+		t.Error("the caller's slice was modified")
+	}
+	exec := []string{"exec", "web", "--", "ls", `C:\x`}
+	if got := translatePathArgs(exec, pathsPOSIX); !reflect.DeepEqual(got, exec) {
+		t.Errorf("exec args changed: %v", got)
+	}
+	transfer := []string{"transfer", "web:/tmp/out.tar", "/mnt/c/Users/me/out.tar"}
+	if got := translatePathArgs(transfer, pathsWindows); got[2] != `C:\Users\me\out.tar` {
+		t.Errorf("transfer args = %v", got)
+	}
+}
+
+func TestHostPathStyle(t *testing.T) {
+	tests := []struct {
+		goos   string
+		runner multipassRunner
+		want   pathStyle
+	}{
+		{"windows", localRunner{}, pathsWindows},
+		{"windows", wslRunner{}, pathsPOSIX},
+		{"linux", localRunner{}, pathsAsIs},
+		{"darwin", sshRunner{host: "box"}, pathsAsIs},
+	}
+	for _, tt := range tests {
+		if got := hostPathStyle(tt.goos, tt.runner); got != tt.want {
+			t.Errorf("hostPathStyle(%s, %v) = %d, want %d", tt.goos, tt.runner, got, tt.want)
+		}
+	}
+}